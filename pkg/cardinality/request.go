@@ -23,11 +23,19 @@ const (
 	ActiveMethod   CountMethod = "active"
 )
 
+type SortBy string
+
+const (
+	SortBySeriesCount            SortBy = "series_count"
+	SortByNativeHistogramBuckets SortBy = "native_histogram_buckets"
+)
+
 const (
 	minLimit           = 0
 	maxLimit           = 500
 	defaultLimit       = 20
 	defaultCountMethod = InMemoryMethod
+	defaultSortBy      = SortBySeriesCount
 
 	stringParamSeparator = rune(0)
 	stringValueSeparator = rune(1)
@@ -168,6 +176,85 @@ func DecodeLabelValuesRequestFromValues(values url.Values) (*LabelValuesRequest,
 	return parsed, nil
 }
 
+type ActiveSeriesRequest struct {
+	Matchers []*labels.Matcher
+}
+
+// DecodeActiveSeriesRequest decodes the input http.Request into an ActiveSeriesRequest.
+// The input http.Request can either be a GET or POST with URL-encoded parameters.
+func DecodeActiveSeriesRequest(r *http.Request) (*ActiveSeriesRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return DecodeActiveSeriesRequestFromValues(r.Form)
+}
+
+// DecodeActiveSeriesRequestFromValues is like DecodeActiveSeriesRequest but takes url.Values in input.
+func DecodeActiveSeriesRequestFromValues(values url.Values) (*ActiveSeriesRequest, error) {
+	var (
+		parsed = &ActiveSeriesRequest{}
+		err    error
+	)
+
+	parsed.Matchers, err = extractSelector(values)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Matchers) == 0 {
+		return nil, fmt.Errorf("'selector' param is required")
+	}
+
+	return parsed, nil
+}
+
+type TopMetricsRequest struct {
+	Matchers  []*labels.Matcher
+	LabelName model.LabelName
+	SortBy    SortBy
+	Limit     int
+}
+
+// DecodeTopMetricsRequest decodes the input http.Request into a TopMetricsRequest.
+// The input http.Request can either be a GET or POST with URL-encoded parameters.
+func DecodeTopMetricsRequest(r *http.Request) (*TopMetricsRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return DecodeTopMetricsRequestFromValues(r.Form)
+}
+
+// DecodeTopMetricsRequestFromValues is like DecodeTopMetricsRequest but takes url.Values in input.
+func DecodeTopMetricsRequestFromValues(values url.Values) (*TopMetricsRequest, error) {
+	var (
+		parsed = &TopMetricsRequest{}
+		err    error
+	)
+
+	parsed.Matchers, err = extractSelector(values)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.LabelName, err = extractLabelName(values)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.SortBy, err = extractSortBy(values)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.Limit, err = extractLimit(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
 // extractSelector parses and gets selector query parameter containing a single matcher
 func extractSelector(values url.Values) (matchers []*labels.Matcher, err error) {
 	selectorParams := values["selector"]
@@ -264,3 +351,35 @@ func extractCountMethod(values url.Values) (countMethod CountMethod, err error)
 		return "", fmt.Errorf("invalid 'count_method' param '%v'. valid options are: [%s]", countMethodParams[0], strings.Join([]string{string(ActiveMethod), string(InMemoryMethod)}, ","))
 	}
 }
+
+// extractLabelName parses and validates request param `label_name` if it's defined, otherwise returns an empty label name.
+func extractLabelName(values url.Values) (model.LabelName, error) {
+	labelNameParams := values["label_name"]
+	if len(labelNameParams) == 0 {
+		return "", nil
+	}
+	if len(labelNameParams) > 1 {
+		return "", fmt.Errorf("multiple 'label_name' params are not allowed")
+	}
+	labelName := model.LabelName(labelNameParams[0])
+	if !labelName.IsValid() {
+		return "", fmt.Errorf("invalid 'label_name' param '%v'", labelNameParams[0])
+	}
+	return labelName, nil
+}
+
+// extractSortBy parses and validates request param `sort_by` if it's defined, otherwise returns default value.
+func extractSortBy(values url.Values) (SortBy, error) {
+	sortByParams := values["sort_by"]
+	if len(sortByParams) == 0 {
+		return defaultSortBy, nil
+	}
+	switch SortBy(sortByParams[0]) {
+	case SortBySeriesCount:
+		return SortBySeriesCount, nil
+	case SortByNativeHistogramBuckets:
+		return SortByNativeHistogramBuckets, nil
+	default:
+		return "", fmt.Errorf("invalid 'sort_by' param '%v'. valid options are: [%s]", sortByParams[0], strings.Join([]string{string(SortBySeriesCount), string(SortByNativeHistogramBuckets)}, ","))
+	}
+}
@@ -120,6 +120,111 @@ func TestDecodeLabelValuesRequest(t *testing.T) {
 	})
 }
 
+func TestDecodeActiveSeriesRequest(t *testing.T) {
+	var (
+		params = url.Values{
+			"selector": []string{`{second!="2",first="1"}`},
+		}
+
+		expected = &ActiveSeriesRequest{
+			Matchers: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, "first", "1"),
+				labels.MustNewMatcher(labels.MatchNotEqual, "second", "2"),
+			},
+		}
+	)
+
+	t.Run("DecodeActiveSeriesRequest() with GET request", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://localhost?"+params.Encode(), nil)
+		require.NoError(t, err)
+
+		actual, err := DecodeActiveSeriesRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeActiveSeriesRequest() with POST request", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "http://localhost/", strings.NewReader(params.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		actual, err := DecodeActiveSeriesRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeActiveSeriesRequestFromValues() without selector", func(t *testing.T) {
+		_, err := DecodeActiveSeriesRequestFromValues(url.Values{})
+		require.EqualError(t, err, "'selector' param is required")
+	})
+}
+
+func TestDecodeTopMetricsRequest(t *testing.T) {
+	var (
+		params = url.Values{
+			"selector":   []string{`{second!="2",first="1"}`},
+			"label_name": []string{"job"},
+			"sort_by":    []string{"native_histogram_buckets"},
+			"limit":      []string{"10"},
+		}
+
+		expected = &TopMetricsRequest{
+			Matchers: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, "first", "1"),
+				labels.MustNewMatcher(labels.MatchNotEqual, "second", "2"),
+			},
+			LabelName: "job",
+			SortBy:    SortByNativeHistogramBuckets,
+			Limit:     10,
+		}
+	)
+
+	t.Run("DecodeTopMetricsRequest() with GET request", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://localhost?"+params.Encode(), nil)
+		require.NoError(t, err)
+
+		actual, err := DecodeTopMetricsRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeTopMetricsRequest() with POST request", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "http://localhost/", strings.NewReader(params.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		actual, err := DecodeTopMetricsRequest(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("DecodeTopMetricsRequestFromValues() with default values", func(t *testing.T) {
+		actual, err := DecodeTopMetricsRequestFromValues(url.Values{})
+		require.NoError(t, err)
+
+		assert.Equal(t, &TopMetricsRequest{SortBy: SortBySeriesCount, Limit: 20}, actual)
+	})
+
+	t.Run("DecodeTopMetricsRequestFromValues() with invalid params", func(t *testing.T) {
+		for params, expectedErr := range map[string]string{
+			"label_name=olá":            "invalid 'label_name' param 'olá'",
+			"label_name=a&label_name=b": "multiple 'label_name' params are not allowed",
+			"sort_by=foo":               "invalid 'sort_by' param 'foo'. valid options are: [series_count,native_histogram_buckets]",
+			"limit=501":                 "'limit' param cannot be greater than '500'",
+		} {
+			values, err := url.ParseQuery(params)
+			require.NoError(t, err)
+
+			_, err = DecodeTopMetricsRequestFromValues(values)
+			require.EqualError(t, err, expectedErr)
+		}
+	})
+}
+
 func TestLabelValuesRequest_String(t *testing.T) {
 	req := &LabelValuesRequest{
 		LabelNames: []model.LabelName{"foo", "bar"},
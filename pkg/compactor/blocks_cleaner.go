@@ -43,6 +43,13 @@ type BlocksCleanerConfig struct {
 	TenantCleanupDelay         time.Duration // Delay before removing tenant deletion mark and "debug".
 	DeleteBlocksConcurrency    int
 	NoBlocksFileCleanupEnabled bool
+
+	// Directory where blocks are temporarily downloaded to apply series deletion requests.
+	SeriesDeletionDir string
+	// Min time a series deletion request stays pending, before being marked as processed.
+	SeriesDeletionMinPendingPeriod time.Duration
+	// How long processed series deletion requests are kept in the storage before being deleted.
+	SeriesDeletionProcessedRetention time.Duration
 }
 
 type BlocksCleaner struct {
@@ -59,6 +66,9 @@ type BlocksCleaner struct {
 	// Keep track of the last owned users.
 	lastOwnedUsers []string
 
+	// Keep track of the blocks checked for the pending series deletion requests.
+	seriesDeletionCheckedBlocks *seriesDeletionCheckedBlocks
+
 	// Metrics.
 	runsStarted                    prometheus.Counter
 	runsCompleted                  prometheus.Counter
@@ -68,10 +78,16 @@ type BlocksCleaner struct {
 	blocksFailedTotal              prometheus.Counter
 	blocksMarkedForDeletion        prometheus.Counter
 	partialBlocksMarkedForDeletion prometheus.Counter
-	tenantBlocks                   *prometheus.GaugeVec
-	tenantMarkedBlocks             *prometheus.GaugeVec
-	tenantPartialBlocks            *prometheus.GaugeVec
-	tenantBucketIndexLastUpdate    *prometheus.GaugeVec
+
+	seriesDeletionBlocksMarkedForDeletion prometheus.Counter
+	seriesDeletionRewrittenBlocks         prometheus.Counter
+	seriesDeletionRequestsProcessed       prometheus.Counter
+	seriesDeletionRequestsDeleted         prometheus.Counter
+
+	tenantBlocks                *prometheus.GaugeVec
+	tenantMarkedBlocks          *prometheus.GaugeVec
+	tenantPartialBlocks         *prometheus.GaugeVec
+	tenantBucketIndexLastUpdate *prometheus.GaugeVec
 }
 
 func NewBlocksCleaner(cfg BlocksCleanerConfig, bucketClient objstore.Bucket, ownUser func(userID string) (bool, error), cfgProvider ConfigProvider, logger log.Logger, reg prometheus.Registerer) *BlocksCleaner {
@@ -83,6 +99,9 @@ func NewBlocksCleaner(cfg BlocksCleanerConfig, bucketClient objstore.Bucket, own
 		cfgProvider:  cfgProvider,
 		singleFlight: concurrency.NewLimitedConcurrencySingleFlight(cfg.CleanupConcurrency),
 		logger:       log.With(logger, "component", "cleaner"),
+
+		seriesDeletionCheckedBlocks: newSeriesDeletionCheckedBlocks(),
+
 		runsStarted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_compactor_block_cleanup_started_total",
 			Help: "Total number of blocks cleanup runs started.",
@@ -117,6 +136,23 @@ func NewBlocksCleaner(cfg BlocksCleanerConfig, bucketClient objstore.Bucket, own
 			Help:        blocksMarkedForDeletionHelp,
 			ConstLabels: prometheus.Labels{"reason": "partial"},
 		}),
+		seriesDeletionBlocksMarkedForDeletion: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        blocksMarkedForDeletionName,
+			Help:        blocksMarkedForDeletionHelp,
+			ConstLabels: prometheus.Labels{"reason": "series-deletion"},
+		}),
+		seriesDeletionRewrittenBlocks: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_rewritten_blocks_total",
+			Help: "Total number of blocks rewritten to apply series deletion requests.",
+		}),
+		seriesDeletionRequestsProcessed: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_requests_processed_total",
+			Help: "Total number of series deletion requests processed.",
+		}),
+		seriesDeletionRequestsDeleted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_requests_deleted_total",
+			Help: "Total number of processed series deletion requests deleted after the retention period.",
+		}),
 
 		// The following metrics don't have the "cortex_compactor" prefix because not strictly related to
 		// the compactor. They're just tracked by the compactor because it's the most logical place where these
@@ -414,6 +450,9 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userID string, userLogger
 		// error occurs here. Errors are logged in the function.
 		retention := c.cfgProvider.CompactorBlocksRetentionPeriod(userID)
 		c.applyUserRetentionPeriod(ctx, idx, retention, userBucket, userLogger)
+
+		// Same as above, errors are logged in the function.
+		c.applyUserSeriesDeletionRequests(ctx, idx, userID, userBucket, userLogger)
 	}
 
 	// Generate an updated in-memory version of the bucket index.
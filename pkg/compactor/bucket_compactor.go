@@ -28,6 +28,7 @@ import (
 	"github.com/thanos-io/objstore"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/block"
@@ -369,6 +370,16 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 	}
 
 	blocksToUpload := convertCompactionResultToForEachJobs(compIDs, job.UseSplitting(), jobLogger)
+
+	// The TSDB compactor doesn't know about exemplars, so we carry them over from the source blocks.
+	splittingShards := uint32(0)
+	if job.UseSplitting() {
+		splittingShards = job.SplittingShards()
+	}
+	if err := writeCompactedBlocksExemplars(blocksToCompactDirs, blocksToUpload, subDir, splittingShards); err != nil {
+		return false, nil, err
+	}
+
 	err = concurrency.ForEachJob(ctx, len(blocksToUpload), c.blockSyncConcurrency, func(ctx context.Context, idx int) error {
 		blockToUpload := blocksToUpload[idx]
 
@@ -429,6 +440,78 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 	return true, compIDs, nil
 }
 
+// writeCompactedBlocksExemplars merges the exemplars of the source block directories, and writes them to the
+// compacted blocks whose time range they belong to. When splitting, the exemplars of each series are only written
+// to the block of the shard the series has been sharded into. Exemplars are streamed from the source blocks, so
+// that they're never all loaded in memory.
+func writeCompactedBlocksExemplars(sourceDirs []string, compacted []ulidWithShardIndex, subDir string, splittingShards uint32) (returnErr error) {
+	readers := make([]*block.ExemplarsReader, 0, len(sourceDirs))
+	defer func() {
+		for _, r := range readers {
+			_ = r.Close()
+		}
+	}()
+
+	for _, dir := range sourceDirs {
+		r, err := block.OpenExemplarsFile(dir)
+		if err != nil {
+			return errors.Wrapf(err, "read exemplars of block %s", dir)
+		}
+		readers = append(readers, r)
+	}
+
+	type compactedBlock struct {
+		minT, maxT int64
+		shardIndex int
+		writer     *block.ExemplarsWriter
+	}
+
+	blocks := make([]*compactedBlock, 0, len(compacted))
+	defer func() {
+		for _, b := range blocks {
+			if err := b.writer.Close(); returnErr == nil {
+				returnErr = err
+			}
+		}
+	}()
+
+	for _, c := range compacted {
+		bdir := filepath.Join(subDir, c.ulid.String())
+		meta, err := block.ReadMetaFromDir(bdir)
+		if err != nil {
+			return errors.Wrapf(err, "read meta from %s", bdir)
+		}
+
+		w, err := block.NewExemplarsWriter(bdir)
+		if err != nil {
+			return errors.Wrapf(err, "write exemplars of block %s", bdir)
+		}
+
+		// The block max time is exclusive.
+		blocks = append(blocks, &compactedBlock{minT: meta.MinTime, maxT: meta.MaxTime - 1, shardIndex: c.shardIndex, writer: w})
+	}
+
+	return block.MergeExemplarsReaders(readers, func(s mimirpb.TimeSeries) error {
+		shardIndex := -1
+		if splittingShards > 0 {
+			shardIndex = int(labels.StableHash(mimirpb.FromLabelAdaptersToLabels(s.Labels)) % uint64(splittingShards))
+		}
+
+		for _, b := range blocks {
+			if shardIndex >= 0 && b.shardIndex != shardIndex {
+				continue
+			}
+
+			for _, filtered := range block.FilterExemplars([]mimirpb.TimeSeries{s}, b.minT, b.maxT, nil) {
+				if err := b.writer.Write(filtered); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
 // verifyCompactedBlocksTimeRanges does a full run over the compacted blocks
 // and verifies that they satisfy the min/maxTime from the source blocks
 func verifyCompactedBlocksTimeRanges(compIDs []ulid.ULID, sourceBlocksMinTime, sourceBlocksMaxTime int64, subDir string) error {
@@ -96,6 +96,9 @@ type Config struct {
 	MaxCompactionTime          time.Duration           `yaml:"max_compaction_time" category:"advanced"`
 	NoBlocksFileCleanupEnabled bool                    `yaml:"no_blocks_file_cleanup_enabled" category:"experimental"`
 
+	SeriesDeletionMinPendingPeriod   time.Duration `yaml:"series_deletion_min_pending_period" category:"experimental"`
+	SeriesDeletionProcessedRetention time.Duration `yaml:"series_deletion_processed_requests_retention" category:"experimental"`
+
 	// Compactor concurrency options
 	MaxOpeningBlocksConcurrency         int `yaml:"max_opening_blocks_concurrency" category:"advanced"`          // Number of goroutines opening blocks before compaction.
 	MaxClosingBlocksConcurrency         int `yaml:"max_closing_blocks_concurrency" category:"advanced"`          // Max number of blocks that can be closed concurrently during split compaction. Note that closing of newly compacted block uses a lot of memory for writing index.
@@ -146,6 +149,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 		"If 0, blocks will be deleted straight away. Note that deleting blocks immediately can cause query failures.")
 	f.DurationVar(&cfg.TenantCleanupDelay, "compactor.tenant-cleanup-delay", 6*time.Hour, "For tenants marked for deletion, this is time between deleting of last block, and doing final cleanup (marker files, debug files) of the tenant.")
 	f.BoolVar(&cfg.NoBlocksFileCleanupEnabled, "compactor.no-blocks-file-cleanup-enabled", false, "If enabled, will delete the bucket-index, markers and debug files in the tenant bucket when there are no blocks left in the index.")
+	f.DurationVar(&cfg.SeriesDeletionMinPendingPeriod, "compactor.series-deletion-min-pending-period", time.Hour, "Min time a series deletion request stays pending before it's marked as processed, once no block containing the deleted series is left in the storage. It must be greater than the ingesters' series deletion requests sync interval, to give ingesters enough time to apply the request and ship their blocks.")
+	f.DurationVar(&cfg.SeriesDeletionProcessedRetention, "compactor.series-deletion-processed-requests-retention", 24*time.Hour, "How long processed series deletion requests are kept in the storage before being deleted. Queriers and ingesters stop applying a request once it's processed, so it only needs to be kept to report its state through the API.")
 	// compactor concurrency options
 	f.IntVar(&cfg.MaxOpeningBlocksConcurrency, "compactor.max-opening-blocks-concurrency", 1, "Number of goroutines opening blocks before compaction.")
 	f.IntVar(&cfg.MaxClosingBlocksConcurrency, "compactor.max-closing-blocks-concurrency", 1, "Max number of blocks that can be closed concurrently during split compaction. Note that closing of newly compacted block uses a lot of memory for writing index.")
@@ -487,12 +492,15 @@ func (c *MultitenantCompactor) starting(ctx context.Context) error {
 
 	// Create the blocks cleaner (service).
 	c.blocksCleaner = NewBlocksCleaner(BlocksCleanerConfig{
-		DeletionDelay:              c.compactorCfg.DeletionDelay,
-		CleanupInterval:            util.DurationWithJitter(c.compactorCfg.CleanupInterval, 0.1),
-		CleanupConcurrency:         c.compactorCfg.CleanupConcurrency,
-		TenantCleanupDelay:         c.compactorCfg.TenantCleanupDelay,
-		DeleteBlocksConcurrency:    defaultDeleteBlocksConcurrency,
-		NoBlocksFileCleanupEnabled: c.compactorCfg.NoBlocksFileCleanupEnabled,
+		DeletionDelay:                    c.compactorCfg.DeletionDelay,
+		CleanupInterval:                  util.DurationWithJitter(c.compactorCfg.CleanupInterval, 0.1),
+		CleanupConcurrency:               c.compactorCfg.CleanupConcurrency,
+		TenantCleanupDelay:               c.compactorCfg.TenantCleanupDelay,
+		DeleteBlocksConcurrency:          defaultDeleteBlocksConcurrency,
+		NoBlocksFileCleanupEnabled:       c.compactorCfg.NoBlocksFileCleanupEnabled,
+		SeriesDeletionDir:                path.Join(c.compactorCfg.DataDir, "series-deletion"),
+		SeriesDeletionMinPendingPeriod:   c.compactorCfg.SeriesDeletionMinPendingPeriod,
+		SeriesDeletionProcessedRetention: c.compactorCfg.SeriesDeletionProcessedRetention,
 	}, c.bucketClient, c.shardingStrategy.blocksCleanerOwnUser, c.cfgProvider, c.parentLogger, c.registerer)
 
 	// Start blocks cleaner asynchronously, don't wait until initial cleanup is finished.
@@ -0,0 +1,397 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/runutil"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+)
+
+// seriesDeletion is a pending series deletion request, along with its parsed selectors.
+type seriesDeletion struct {
+	req      *mimir_tsdb.SeriesDeletionRequest
+	matchers [][]*labels.Matcher
+
+	// Blocks already checked not to contain any series to delete.
+	checkedBlocks map[ulid.ULID]struct{}
+
+	// Whether the request changed while processing it and must be written back to the storage.
+	changed bool
+	// Whether any block has been rewritten.
+	rewritten bool
+	// Whether any block failed to be processed.
+	failed bool
+}
+
+// seriesDeletionCheckedBlocks keeps the blocks checked not to contain the series of each pending series deletion
+// request, so that they're not checked again at each run.
+type seriesDeletionCheckedBlocks struct {
+	mtx    sync.Mutex
+	blocks map[string]map[string]map[ulid.ULID]struct{} // Keyed by tenant and request.
+}
+
+func newSeriesDeletionCheckedBlocks() *seriesDeletionCheckedBlocks {
+	return &seriesDeletionCheckedBlocks{blocks: map[string]map[string]map[ulid.ULID]struct{}{}}
+}
+
+// forRequests returns the checked blocks of each input request of the tenant, and forgets the requests of the
+// tenant which aren't in the input anymore. The returned sets can be updated without holding any lock, given
+// the requests of a tenant are processed by a single goroutine.
+func (b *seriesDeletionCheckedBlocks) forRequests(userID string, reqs []*mimir_tsdb.SeriesDeletionRequest) []map[ulid.ULID]struct{} {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	prev := b.blocks[userID]
+	curr := make(map[string]map[ulid.ULID]struct{}, len(reqs))
+	result := make([]map[ulid.ULID]struct{}, 0, len(reqs))
+
+	for _, req := range reqs {
+		// A request submitted again after being processed has a more recent creation time.
+		key := fmt.Sprintf("%s/%d", req.RequestID, req.CreatedAt)
+		checked, ok := prev[key]
+		if !ok {
+			checked = map[ulid.ULID]struct{}{}
+		}
+		curr[key] = checked
+		result = append(result, checked)
+	}
+
+	if len(curr) == 0 {
+		delete(b.blocks, userID)
+	} else {
+		b.blocks[userID] = curr
+	}
+	return result
+}
+
+// applyUserSeriesDeletionRequests removes the series of the tenant's pending deletion requests from the blocks
+// in the storage. Each affected block is rewritten without the deleted series, and then marked for deletion.
+// A request is marked as processed once no block needs to be rewritten anymore and the request has been
+// pending for at least the configured min period, to give ingesters enough time to apply it too. Processed
+// requests are deleted after the configured retention period.
+func (c *BlocksCleaner) applyUserSeriesDeletionRequests(ctx context.Context, idx *bucketindex.Index, userID string, userBucket objstore.Bucket, userLogger log.Logger) {
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, c.bucketClient, userID)
+	if err != nil {
+		level.Warn(userLogger).Log("msg", "failed to list series deletion requests", "err", err)
+		return
+	}
+
+	var pending []*mimir_tsdb.SeriesDeletionRequest
+	for _, req := range requests {
+		if req.State == mimir_tsdb.SeriesDeletionRequestPending {
+			pending = append(pending, req)
+			continue
+		}
+
+		if req.State == mimir_tsdb.SeriesDeletionRequestProcessed && time.Since(req.GetProcessedAt()) > c.cfg.SeriesDeletionProcessedRetention {
+			if err := mimir_tsdb.DeleteSeriesDeletionRequest(ctx, c.bucketClient, userID, c.cfgProvider, req.RequestID); err != nil {
+				level.Warn(userLogger).Log("msg", "failed to delete processed series deletion request", "request_id", req.RequestID, "err", err)
+				continue
+			}
+			c.seriesDeletionRequestsDeleted.Inc()
+			level.Info(userLogger).Log("msg", "deleted processed series deletion request", "request_id", req.RequestID)
+		}
+	}
+
+	checkedBlocks := c.seriesDeletionCheckedBlocks.forRequests(userID, pending)
+
+	var deletions []*seriesDeletion
+	for i, req := range pending {
+		matchers, err := req.Matchers()
+		if err != nil {
+			level.Warn(userLogger).Log("msg", "skipped invalid series deletion request", "request_id", req.RequestID, "err", err)
+			continue
+		}
+
+		deletions = append(deletions, &seriesDeletion{req: req, matchers: matchers, checkedBlocks: checkedBlocks[i]})
+	}
+
+	if len(deletions) == 0 {
+		return
+	}
+
+	marked := make(map[ulid.ULID]struct{}, len(idx.BlockDeletionMarks))
+	for _, d := range idx.BlockDeletionMarks {
+		marked[d.ID] = struct{}{}
+	}
+
+	for _, b := range idx.Blocks {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, ok := marked[b.ID]; ok {
+			continue
+		}
+
+		// Find the requests which may affect the block.
+		var blockDeletions []*seriesDeletion
+		for _, d := range deletions {
+			if _, checked := d.checkedBlocks[b.ID]; checked {
+				continue
+			}
+			if b.Within(d.req.StartTime, d.req.EndTime) && !d.req.IsBlockRewritten(b.ID) {
+				blockDeletions = append(blockDeletions, d)
+			}
+		}
+		if len(blockDeletions) == 0 {
+			continue
+		}
+
+		newID, deleted, err := c.rewriteBlockWithSeriesDeletions(ctx, b.ID, blockDeletions, userBucket, userLogger)
+		if err != nil {
+			level.Warn(userLogger).Log("msg", "failed to apply series deletion requests to block", "block", b.ID, "err", err)
+			for _, d := range blockDeletions {
+				d.failed = true
+			}
+			continue
+		}
+		if !deleted {
+			for _, d := range blockDeletions {
+				d.checkedBlocks[b.ID] = struct{}{}
+			}
+			continue
+		}
+
+		for _, d := range blockDeletions {
+			if newID != (ulid.ULID{}) {
+				d.req.RewrittenBlocks = append(d.req.RewrittenBlocks, newID)
+			}
+			d.changed = true
+			d.rewritten = true
+		}
+
+		if err := block.MarkForDeletion(ctx, userLogger, userBucket, b.ID, "source of block rewritten by series deletion", c.seriesDeletionBlocksMarkedForDeletion); err != nil {
+			// The block will be rewritten again in the next run, but it's not an issue given the rewritten one
+			// doesn't contain the deleted series, so querying both doesn't return any deleted sample.
+			level.Warn(userLogger).Log("msg", "failed to mark block rewritten by series deletion for deletion", "block", b.ID, "err", err)
+			for _, d := range blockDeletions {
+				d.failed = true
+			}
+			continue
+		}
+
+		c.seriesDeletionRewrittenBlocks.Inc()
+		level.Info(userLogger).Log("msg", "rewritten block applying series deletion requests", "block", b.ID, "new_block", newID, "requests", len(blockDeletions))
+	}
+
+	for _, d := range deletions {
+		// We mark a request as processed only after a run which didn't find any block to rewrite,
+		// in order to also cover the blocks created by compactions running in the meanwhile.
+		if !d.rewritten && !d.failed && time.Since(d.req.GetCreatedAt()) >= c.cfg.SeriesDeletionMinPendingPeriod {
+			d.req.State = mimir_tsdb.SeriesDeletionRequestProcessed
+			d.req.ProcessedAt = time.Now().Unix()
+			d.req.RewrittenBlocks = nil
+			d.changed = true
+
+			c.seriesDeletionRequestsProcessed.Inc()
+			level.Info(userLogger).Log("msg", "series deletion request processed", "request_id", d.req.RequestID)
+		}
+
+		if !d.changed {
+			continue
+		}
+
+		if err := mimir_tsdb.WriteSeriesDeletionRequest(ctx, c.bucketClient, userID, c.cfgProvider, d.req); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to update series deletion request", "request_id", d.req.RequestID, "err", err)
+		}
+	}
+}
+
+// rewriteBlockWithSeriesDeletions applies the series deletions to the block. The block index is downloaded first,
+// and the rest of the block only if any series has to be deleted. If so, a new block without the deleted samples
+// is uploaded to the storage and the function returns its ID. The returned ID is zero if all the samples of the
+// block have been deleted.
+func (c *BlocksCleaner) rewriteBlockWithSeriesDeletions(ctx context.Context, blockID ulid.ULID, deletions []*seriesDeletion, userBucket objstore.Bucket, userLogger log.Logger) (_ ulid.ULID, deleted bool, returnErr error) {
+	if err := os.MkdirAll(c.cfg.SeriesDeletionDir, 0750); err != nil {
+		return ulid.ULID{}, false, err
+	}
+
+	dir, err := os.MkdirTemp(c.cfg.SeriesDeletionDir, "series-deletion-")
+	if err != nil {
+		return ulid.ULID{}, false, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to remove series deletion directory", "dir", dir, "err", err)
+		}
+	}()
+
+	bdir := filepath.Join(dir, blockID.String())
+	if err := downloadBlockIndex(ctx, userLogger, userBucket, blockID, bdir); err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "download index of block %s", blockID)
+	}
+
+	// Write the series to delete as tombstones of the block. Only the index is needed to find them.
+	numTombstones, err := writeSeriesDeletionTombstones(ctx, bdir, deletions, userLogger)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "delete series from block %s", blockID)
+	}
+	if numTombstones == 0 {
+		return ulid.ULID{}, false, nil
+	}
+
+	// Don't overwrite the local meta and tombstones, updated with the series to delete.
+	ignoredPaths := objstore.WithDownloadIgnoredPaths(block.MetaFilename, block.IndexFilename, tombstones.TombstonesFilename)
+	if err := objstore.DownloadDir(ctx, userLogger, userBucket, blockID.String(), blockID.String(), bdir, ignoredPaths); err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "download block %s", blockID)
+	}
+
+	meta, err := block.ReadMetaFromDir(bdir)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "read meta from %s", bdir)
+	}
+
+	b, err := tsdb.OpenBlock(userLogger, bdir, nil)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "open block %s", blockID)
+	}
+	defer runutil.CloseWithErrCapture(&returnErr, b, "series deletion block reader")
+
+	// Write a new block applying the tombstones.
+	compactor, err := tsdb.NewLeveledCompactor(ctx, nil, userLogger, []int64{meta.MaxTime - meta.MinTime}, nil, nil, true)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrap(err, "create compactor")
+	}
+
+	newID, err := compactor.Write(dir, b, meta.MinTime, meta.MaxTime, &meta.BlockMeta)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "rewrite block %s", blockID)
+	}
+
+	// All samples of the block have been deleted.
+	if newID == (ulid.ULID{}) {
+		return ulid.ULID{}, true, nil
+	}
+
+	newBdir := filepath.Join(dir, newID.String())
+	newMeta, err := block.InjectThanosMeta(userLogger, newBdir, block.ThanosMeta{
+		Labels:       meta.Thanos.Labels,
+		Downsample:   meta.Thanos.Downsample,
+		Source:       block.CompactorSeriesDeletionSource,
+		SegmentFiles: block.GetSegmentFiles(newBdir),
+	}, nil)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "failed to finalize the block %s", newBdir)
+	}
+
+	if err = os.Remove(filepath.Join(newBdir, tombstones.TombstonesFilename)); err != nil {
+		return ulid.ULID{}, false, errors.Wrap(err, "remove tombstones")
+	}
+
+	if err := block.VerifyBlock(ctx, userLogger, newBdir, newMeta.MinTime, newMeta.MaxTime, false); err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "invalid rewritten block %s", newBdir)
+	}
+
+	// Carry over the exemplars of the source block, except the deleted ones.
+	exemplars, err := block.ReadExemplarsFile(bdir)
+	if err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "read exemplars of block %s", blockID)
+	}
+	if err := block.WriteExemplarsFile(newBdir, deleteExemplars(exemplars, deletions)); err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "write exemplars of block %s", newBdir)
+	}
+
+	if err := block.Upload(ctx, userLogger, userBucket, newBdir, nil); err != nil {
+		return ulid.ULID{}, false, errors.Wrapf(err, "upload of %s failed", newID)
+	}
+
+	return newID, true, nil
+}
+
+// downloadBlockIndex downloads the meta and the index of the block to dst, along with an empty chunks directory,
+// so that the block can be opened to read its index.
+func downloadBlockIndex(ctx context.Context, logger log.Logger, bkt objstore.Bucket, id ulid.ULID, dst string) error {
+	if err := os.MkdirAll(filepath.Join(dst, block.ChunksDirname), 0750); err != nil {
+		return errors.Wrap(err, "create dir")
+	}
+
+	for _, name := range []string{block.MetaFilename, block.IndexFilename} {
+		if err := objstore.DownloadFile(ctx, logger, bkt, path.Join(id.String(), name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSeriesDeletionTombstones writes the tombstones of the series deletions to the block in dir, and returns
+// their number. The chunks of the block aren't read.
+func writeSeriesDeletionTombstones(ctx context.Context, dir string, deletions []*seriesDeletion, logger log.Logger) (_ uint64, returnErr error) {
+	b, err := tsdb.OpenBlock(logger, dir, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer runutil.CloseWithErrCapture(&returnErr, b, "series deletion block index reader")
+
+	for _, d := range deletions {
+		for _, matchers := range d.matchers {
+			if err := b.Delete(ctx, d.req.StartTime, d.req.EndTime, matchers...); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return b.Meta().Stats.NumTombstones, nil
+}
+
+// deleteExemplars returns the input series without the exemplars deleted by the series deletion requests.
+func deleteExemplars(series []mimirpb.TimeSeries, deletions []*seriesDeletion) []mimirpb.TimeSeries {
+	result := make([]mimirpb.TimeSeries, 0, len(series))
+
+	for _, s := range series {
+		lbls := mimirpb.FromLabelAdaptersToLabels(s.Labels)
+
+		exemplars := make([]mimirpb.Exemplar, 0, len(s.Exemplars))
+		for _, e := range s.Exemplars {
+			if !isExemplarDeleted(lbls, e.TimestampMs, deletions) {
+				exemplars = append(exemplars, e)
+			}
+		}
+
+		result = append(result, mimirpb.TimeSeries{Labels: s.Labels, Exemplars: exemplars})
+	}
+
+	return result
+}
+
+func isExemplarDeleted(lbls labels.Labels, ts int64, deletions []*seriesDeletion) bool {
+	for _, d := range deletions {
+		if ts < d.req.StartTime || ts > d.req.EndTime {
+			continue
+		}
+
+		for _, matchers := range d.matchers {
+			matches := true
+			for _, m := range matchers {
+				if !m.Matches(lbls.Get(m.Name)) {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+// DeleteSeries creates a request to delete the series matching the "match[]" selectors, between the "start"
+// and "end" time. If the same request already exists and is still pending, it's left untouched.
+func (c *MultitenantCompactor) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		// When Mimir is running, it uses Auth Middleware for checking X-Scope-OrgID and injecting tenant into context.
+		// Auth Middleware sends http.StatusUnauthorized if X-Scope-OrgID is missing, so we do too here, for consistency.
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	startTime, endTime := int64(math.MinInt64), util.TimeToMillis(now)
+
+	if v := r.Form.Get("start"); v != "" {
+		if startTime, err = util.ParseTime(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.Form.Get("end"); v != "" {
+		if endTime, err = util.ParseTime(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Samples ingested after the request has been created are never deleted.
+	if endTime > util.TimeToMillis(now) {
+		http.Error(w, "the end time must not be in the future", http.StatusBadRequest)
+		return
+	}
+
+	req, err := mimir_tsdb.NewSeriesDeletionRequest(r.Form["match[]"], startTime, endTime, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := mimir_tsdb.ReadSeriesDeletionRequest(ctx, c.bucketClient, userID, req.RequestID)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "failed to read series deletion request", "user", userID, "request_id", req.RequestID, "err", err)
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Re-submitting a processed request makes it pending again, in order to delete
+	// the series written to the storage in the meanwhile.
+	if existing == nil || existing.State != mimir_tsdb.SeriesDeletionRequestPending {
+		if err := mimir_tsdb.WriteSeriesDeletionRequest(ctx, c.bucketClient, userID, c.cfgProvider, req); err != nil {
+			level.Error(c.logger).Log("msg", "failed to write series deletion request", "user", userID, "request_id", req.RequestID, "err", err)
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		level.Info(c.logger).Log("msg", "series deletion request created", "user", userID, "request_id", req.RequestID, "selectors", len(req.Selectors), "start", startTime, "end", endTime)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type DeleteSeriesStatusResponse struct {
+	TenantID string                              `json:"tenant_id"`
+	Requests []*mimir_tsdb.SeriesDeletionRequest `json:"requests"`
+}
+
+// DeleteSeriesStatus lists the series deletion requests of the tenant, along with their state.
+func (c *MultitenantCompactor) DeleteSeriesStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, c.bucketClient, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if pendingOnly := r.URL.Query().Get("state") == string(mimir_tsdb.SeriesDeletionRequestPending); pendingOnly {
+		filtered := requests[:0]
+		for _, req := range requests {
+			if req.State == mimir_tsdb.SeriesDeletionRequestPending {
+				filtered = append(filtered, req)
+			}
+		}
+		requests = filtered
+	}
+
+	util.WriteJSONResponse(w, DeleteSeriesStatusResponse{
+		TenantID: userID,
+		Requests: requests,
+	})
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func TestDeleteSeries(t *testing.T) {
+	bkt := objstore.NewInMemBucket()
+	cfg := prepareConfig(t)
+	c, _, _, _, _ := prepare(t, cfg, bkt)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	future := time.Now().Add(time.Hour).Unix()
+
+	deleteSeries := func(ctx context.Context, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/tsdb/delete_series", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp := httptest.NewRecorder()
+		c.DeleteSeries(resp, req.WithContext(ctx))
+		return resp
+	}
+
+	for name, tc := range map[string]struct {
+		form         url.Values
+		expectedCode int
+	}{
+		"missing selectors": {
+			form:         url.Values{"start": {"10"}, "end": {"20"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"invalid selector": {
+			form:         url.Values{"match[]": {"up{"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"invalid start time": {
+			form:         url.Values{"match[]": {"up"}, "start": {"invalid"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"end time before start time": {
+			form:         url.Values{"match[]": {"up"}, "start": {"20"}, "end": {"10"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		"end time in the future": {
+			form:         url.Values{"match[]": {"up"}, "end": {strconv.FormatInt(future, 10)}},
+			expectedCode: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := deleteSeries(ctx, tc.form)
+			require.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+
+	// Missing tenant.
+	resp := deleteSeries(context.Background(), url.Values{"match[]": {"up"}})
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// No request has been written so far.
+	requests, err := tsdb.ListSeriesDeletionRequests(context.Background(), bkt, "fake")
+	require.NoError(t, err)
+	require.Empty(t, requests)
+
+	// Valid request.
+	resp = deleteSeries(ctx, url.Values{"match[]": {`up{job="test"}`, `down`}, "start": {"10"}, "end": {"20"}})
+	require.Equal(t, http.StatusNoContent, resp.Code)
+
+	requests, err = tsdb.ListSeriesDeletionRequests(context.Background(), bkt, "fake")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{`down`, `up{job="test"}`}, requests[0].Selectors)
+	assert.Equal(t, int64(10000), requests[0].StartTime)
+	assert.Equal(t, int64(20000), requests[0].EndTime)
+	assert.Equal(t, tsdb.SeriesDeletionRequestPending, requests[0].State)
+
+	// Submitting the same request again doesn't create a new one.
+	resp = deleteSeries(ctx, url.Values{"match[]": {`down`, `up{job="test"}`}, "start": {"10"}, "end": {"20"}})
+	require.Equal(t, http.StatusNoContent, resp.Code)
+
+	requests, err = tsdb.ListSeriesDeletionRequests(context.Background(), bkt, "fake")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+}
+
+func TestDeleteSeriesStatus(t *testing.T) {
+	const userID = "user"
+
+	bkt := objstore.NewInMemBucket()
+	cfg := prepareConfig(t)
+	c, _, _, _, _ := prepare(t, cfg, bkt)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(stopServiceFn(t, c))
+
+	pending, err := tsdb.NewSeriesDeletionRequest([]string{`up`}, 10, 20, time.Unix(1000, 0))
+	require.NoError(t, err)
+	processed, err := tsdb.NewSeriesDeletionRequest([]string{`down`}, 10, 20, time.Unix(2000, 0))
+	require.NoError(t, err)
+	processed.State = tsdb.SeriesDeletionRequestProcessed
+	processed.ProcessedAt = 3000
+
+	require.NoError(t, tsdb.WriteSeriesDeletionRequest(context.Background(), bkt, userID, nil, pending))
+	require.NoError(t, tsdb.WriteSeriesDeletionRequest(context.Background(), bkt, userID, nil, processed))
+
+	for name, tc := range map[string]struct {
+		query            string
+		expectedRequests []*tsdb.SeriesDeletionRequest
+	}{
+		"all requests": {
+			expectedRequests: []*tsdb.SeriesDeletionRequest{pending, processed},
+		},
+		"pending requests": {
+			query:            "?state=pending",
+			expectedRequests: []*tsdb.SeriesDeletionRequest{pending},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/compactor/delete_series_status"+tc.query, nil)
+			resp := httptest.NewRecorder()
+			c.DeleteSeriesStatus(resp, req.WithContext(user.InjectOrgID(context.Background(), userID)))
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			res := DeleteSeriesStatusResponse{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+			assert.Equal(t, userID, res.TenantID)
+			assert.Equal(t, tc.expectedRequests, res.Requests)
+		})
+	}
+}
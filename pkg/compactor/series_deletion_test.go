@@ -0,0 +1,321 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
+)
+
+func TestBlocksCleaner_ShouldApplySeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = block.BucketWithGlobalMarkers(bucketClient)
+
+	// Create blocks. Each block contains 5 series, with "series_id" label from 0 to 4.
+	ctx := context.Background()
+	block1 := createTSDBBlock(t, bucketClient, userID, 10, 50, 5, nil)
+	block2 := createTSDBBlock(t, bucketClient, userID, 100, 150, 5, nil)
+
+	// Create a request overlapping only the first block.
+	req, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="1"}`, `{series_id="3"}`}, 0, 60, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bucketClient, userID, newMockConfigProvider(), req))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:           time.Hour,
+		CleanupInterval:         time.Minute,
+		CleanupConcurrency:      1,
+		DeleteBlocksConcurrency: 1,
+		SeriesDeletionDir:       t.TempDir(),
+	}
+
+	logger := log.NewNopLogger()
+	reg := prometheus.NewPedanticRegistry()
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, newMockConfigProvider(), logger, reg)
+
+	// The first run creates the bucket index, while the second one applies the request.
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionRewrittenBlocks))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionBlocksMarkedForDeletion))
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionRequestsProcessed))
+
+	updated, err := mimir_tsdb.ReadSeriesDeletionRequest(ctx, bucketClient, userID, req.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, mimir_tsdb.SeriesDeletionRequestPending, updated.State)
+	require.Len(t, updated.RewrittenBlocks, 1)
+	rewrittenID := updated.RewrittenBlocks[0]
+
+	// The source block has been marked for deletion, and the rewritten one has been added to the index.
+	idx, err := bucketindex.ReadIndex(ctx, bucketClient, userID, nil, logger)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ulid.ULID{block1, block2, rewrittenID}, idx.Blocks.GetULIDs())
+	assert.ElementsMatch(t, []ulid.ULID{block1}, idx.BlockDeletionMarks.GetULIDs())
+
+	meta, err := block.DownloadMeta(ctx, logger, objstore.NewPrefixedBucket(bucketClient, userID), rewrittenID)
+	require.NoError(t, err)
+	assert.Equal(t, block.CompactorSeriesDeletionSource, meta.Thanos.Source)
+	assert.Equal(t, int64(10), meta.MinTime)
+	assert.Equal(t, int64(50), meta.MaxTime)
+	assert.Equal(t, uint64(3), meta.Stats.NumSeries)
+
+	assert.Equal(t, []string{"0", "2", "4"}, readBlockLabelValues(t, bucketClient, userID, rewrittenID, "series_id"))
+
+	// The next run doesn't find any block to rewrite, so the request is processed.
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionRewrittenBlocks))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionRequestsProcessed))
+
+	updated, err = mimir_tsdb.ReadSeriesDeletionRequest(ctx, bucketClient, userID, req.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, mimir_tsdb.SeriesDeletionRequestProcessed, updated.State)
+	assert.NotZero(t, updated.ProcessedAt)
+	assert.Empty(t, updated.RewrittenBlocks)
+}
+
+func TestBlocksCleaner_ShouldKeepSeriesDeletionRequestPendingForMinPeriod(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = block.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	createTSDBBlock(t, bucketClient, userID, 10, 50, 5, nil)
+
+	// Create a request not matching any series.
+	req, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="unknown"}`}, 0, 60, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bucketClient, userID, newMockConfigProvider(), req))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:                  time.Hour,
+		CleanupInterval:                time.Minute,
+		CleanupConcurrency:             1,
+		DeleteBlocksConcurrency:        1,
+		SeriesDeletionDir:              t.TempDir(),
+		SeriesDeletionMinPendingPeriod: time.Hour,
+	}
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, newMockConfigProvider(), log.NewNopLogger(), nil)
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionRewrittenBlocks))
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionRequestsProcessed))
+
+	updated, err := mimir_tsdb.ReadSeriesDeletionRequest(ctx, bucketClient, userID, req.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, mimir_tsdb.SeriesDeletionRequestPending, updated.State)
+}
+
+func TestBlocksCleaner_ShouldOnlyDownloadIndexOfBlocksWithoutDeletedSeries(t *testing.T) {
+	const userID = "user-1"
+
+	fsBucket, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient := &getRecordingBucket{Bucket: block.BucketWithGlobalMarkers(fsBucket)}
+
+	ctx := context.Background()
+	blockID := createTSDBBlock(t, bucketClient, userID, 10, 50, 5, nil)
+
+	// Create a request not matching any series.
+	req, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="unknown"}`}, 0, 60, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bucketClient, userID, newMockConfigProvider(), req))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:                  time.Hour,
+		CleanupInterval:                time.Minute,
+		CleanupConcurrency:             1,
+		DeleteBlocksConcurrency:        1,
+		SeriesDeletionDir:              t.TempDir(),
+		SeriesDeletionMinPendingPeriod: time.Hour,
+	}
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, newMockConfigProvider(), log.NewNopLogger(), nil)
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+
+	// Only the index of the block has been downloaded, and the chunks have not.
+	indexPath := path.Join(userID, blockID.String(), block.IndexFilename)
+	chunksPrefix := path.Join(userID, blockID.String(), block.ChunksDirname) + "/"
+	assert.Equal(t, 1, bucketClient.countGets(func(name string) bool { return name == indexPath }))
+	assert.Equal(t, 0, bucketClient.countGets(func(name string) bool { return strings.HasPrefix(name, chunksPrefix) }))
+
+	// The block isn't checked again by the next runs.
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+	assert.Equal(t, 1, bucketClient.countGets(func(name string) bool { return name == indexPath }))
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionRewrittenBlocks))
+}
+
+func TestBlocksCleaner_ShouldDeleteProcessedSeriesDeletionRequestsAfterRetention(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = block.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	createTSDBBlock(t, bucketClient, userID, 10, 50, 5, nil)
+
+	now := time.Now()
+	expired, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="1"}`}, 0, 5, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	expired.State = mimir_tsdb.SeriesDeletionRequestProcessed
+	expired.ProcessedAt = now.Add(-2 * time.Hour).Unix()
+
+	retained, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="2"}`}, 0, 5, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	retained.State = mimir_tsdb.SeriesDeletionRequestProcessed
+	retained.ProcessedAt = now.Add(-30 * time.Minute).Unix()
+
+	pending, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="3"}`}, 0, 5, now)
+	require.NoError(t, err)
+
+	for _, req := range []*mimir_tsdb.SeriesDeletionRequest{expired, retained, pending} {
+		require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bucketClient, userID, newMockConfigProvider(), req))
+	}
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:                    time.Hour,
+		CleanupInterval:                  time.Minute,
+		CleanupConcurrency:               1,
+		DeleteBlocksConcurrency:          1,
+		SeriesDeletionDir:                t.TempDir(),
+		SeriesDeletionMinPendingPeriod:   time.Hour,
+		SeriesDeletionProcessedRetention: time.Hour,
+	}
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, newMockConfigProvider(), log.NewNopLogger(), nil)
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+	require.NoError(t, cleaner.runCleanupWithErr(ctx))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionRequestsDeleted))
+
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, bucketClient, userID)
+	require.NoError(t, err)
+	requestIDs := make([]string, 0, len(requests))
+	for _, req := range requests {
+		requestIDs = append(requestIDs, req.RequestID)
+	}
+	assert.ElementsMatch(t, []string{retained.RequestID, pending.RequestID}, requestIDs)
+}
+
+// getRecordingBucket records the names of the objects read from the bucket.
+type getRecordingBucket struct {
+	objstore.Bucket
+
+	mtx  sync.Mutex
+	gets []string
+}
+
+func (b *getRecordingBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	b.mtx.Lock()
+	b.gets = append(b.gets, name)
+	b.mtx.Unlock()
+
+	return b.Bucket.Get(ctx, name)
+}
+
+func (b *getRecordingBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	b.mtx.Lock()
+	b.gets = append(b.gets, name)
+	b.mtx.Unlock()
+
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func (b *getRecordingBucket) countGets(match func(name string) bool) int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	count := 0
+	for _, name := range b.gets {
+		if match(name) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestDeleteExemplars(t *testing.T) {
+	req, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`{series_id="1"}`}, 10, 20, time.Now())
+	require.NoError(t, err)
+	matchers, err := req.Matchers()
+	require.NoError(t, err)
+
+	exemplarsSeries := func(seriesID string, timestamps ...int64) mimirpb.TimeSeries {
+		s := mimirpb.TimeSeries{Labels: []mimirpb.LabelAdapter{{Name: "series_id", Value: seriesID}}}
+		for _, ts := range timestamps {
+			s.Exemplars = append(s.Exemplars, mimirpb.Exemplar{Value: float64(ts), TimestampMs: ts})
+		}
+		return s
+	}
+
+	actual := deleteExemplars([]mimirpb.TimeSeries{
+		exemplarsSeries("0", 5, 15, 25),
+		exemplarsSeries("1", 5, 10, 15, 20, 25),
+	}, []*seriesDeletion{{req: req, matchers: matchers}})
+
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries("0", 5, 15, 25),
+		exemplarsSeries("1", 5, 25),
+	}, actual)
+}
+
+func readBlockLabelValues(t *testing.T, bkt objstore.Bucket, userID string, blockID ulid.ULID, name string) []string {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), blockID.String())
+	require.NoError(t, block.Download(ctx, log.NewNopLogger(), objstore.NewPrefixedBucket(bkt, userID), blockID, dir))
+
+	b, err := tsdb.OpenBlock(log.NewNopLogger(), dir, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, b.Close()) })
+
+	r, err := b.Index()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	values, err := r.SortedLabelValues(ctx, name)
+	require.NoError(t, err)
+
+	// Ensure all series are still readable.
+	k, v := index.AllPostingsKey()
+	p, err := r.Postings(ctx, k, v)
+	require.NoError(t, err)
+	numSeries := 0
+	for p.Next() {
+		numSeries++
+	}
+	require.NoError(t, p.Err())
+	require.Equal(t, len(values), numSeries)
+
+	return values
+}
@@ -5,6 +5,7 @@ package compactor
 import (
 	"context"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -24,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
@@ -862,6 +864,104 @@ func TestMultitenantCompactor_ShouldGuaranteeSeriesShardingConsistencyOverTheTim
 	}
 }
 
+func TestMultitenantCompactor_ShouldKeepExemplarsOfCompactedBlocks(t *testing.T) {
+	const (
+		userID     = "user-1"
+		numSeries  = 100
+		blockRange = 2 * time.Hour
+		numShards  = 2
+	)
+
+	var (
+		blockRangeMillis = blockRange.Milliseconds()
+		compactionRanges = mimir_tsdb.DurationList{blockRange}
+	)
+
+	workDir := t.TempDir()
+	storageDir := t.TempDir()
+	fetcherDir := t.TempDir()
+
+	storageCfg := mimir_tsdb.BlocksStorageConfig{}
+	flagext.DefaultValues(&storageCfg)
+	storageCfg.Bucket.Backend = bucket.Filesystem
+	storageCfg.Bucket.Filesystem.Directory = storageDir
+
+	compactorCfg := prepareConfig(t)
+	compactorCfg.DataDir = workDir
+	compactorCfg.BlockRanges = compactionRanges
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.splitAndMergeShards[userID] = numShards
+
+	logger := log.NewNopLogger()
+	reg := prometheus.NewPedanticRegistry()
+	ctx := context.Background()
+
+	bucketClient, err := bucket.NewClient(ctx, storageCfg.Bucket, "test", logger, nil)
+	require.NoError(t, err)
+
+	// Create a TSDB block in the storage, along with the exemplars of some series. One exemplar
+	// is outside the block time range, so it's expected to be dropped.
+	blockID := createTSDBBlock(t, bucketClient, userID, blockRangeMillis, 2*blockRangeMillis, numSeries, nil)
+
+	var inputExemplars []mimirpb.TimeSeries
+	for seriesID := 0; seriesID < 10; seriesID++ {
+		inputExemplars = append(inputExemplars, mimirpb.TimeSeries{
+			Labels: mimirpb.FromLabelsToLabelAdapters(labels.FromStrings("series_id", strconv.Itoa(seriesID))),
+			Exemplars: []mimirpb.Exemplar{
+				{Labels: []mimirpb.LabelAdapter{{Name: "trace_id", Value: "abc"}}, Value: 1, TimestampMs: 0},
+				{Labels: []mimirpb.LabelAdapter{{Name: "trace_id", Value: "def"}}, Value: 2, TimestampMs: blockRangeMillis + 1},
+			},
+		})
+	}
+
+	exemplarsDir := t.TempDir()
+	require.NoError(t, block.WriteExemplarsFile(exemplarsDir, inputExemplars))
+	require.NoError(t, objstore.UploadFile(ctx, logger, bucketClient, filepath.Join(exemplarsDir, block.ExemplarsFilename), path.Join(userID, blockID.String(), block.ExemplarsFilename)))
+
+	c, err := NewMultitenantCompactor(compactorCfg, storageCfg, cfgProvider, logger, reg)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), c))
+	})
+
+	// Wait until the first compaction run completed.
+	test.Poll(t, 15*time.Second, nil, func() interface{} {
+		return testutil.GatherAndCompare(reg, strings.NewReader(`
+					# HELP cortex_compactor_runs_completed_total Total number of compaction runs successfully completed.
+					# TYPE cortex_compactor_runs_completed_total counter
+					cortex_compactor_runs_completed_total 1
+				`), "cortex_compactor_runs_completed_total")
+	})
+
+	userBucket := bucket.NewUserBucketClient(userID, bucketClient, nil)
+	fetcher, err := block.NewMetaFetcher(logger, 1, userBucket, fetcherDir, reg, nil)
+	require.NoError(t, err)
+	metas, partials, err := fetcher.FetchWithoutMarkedForDeletion(ctx)
+	require.NoError(t, err)
+	require.Empty(t, partials)
+
+	actualMetas := sortMetasByMinTime(convertMetasMapToSlice(metas))
+	require.Len(t, actualMetas, numShards)
+
+	// Ensure each split block contains the exemplars of the series sharded into it.
+	var actualExemplars []mimirpb.TimeSeries
+	for idx, actualMeta := range actualMetas {
+		require.True(t, actualMeta.HasExemplars())
+
+		series, err := block.ReadExemplarsFile(filepath.Join(storageDir, userID, actualMeta.ULID.String()))
+		require.NoError(t, err)
+
+		for _, s := range series {
+			assert.Equal(t, uint64(idx), labels.StableHash(mimirpb.FromLabelAdaptersToLabels(s.Labels))%numShards)
+		}
+		actualExemplars = append(actualExemplars, series...)
+	}
+
+	assert.Equal(t, block.FilterExemplars(block.MergeExemplars(inputExemplars), blockRangeMillis, 2*blockRangeMillis-1, nil), block.MergeExemplars(actualExemplars))
+}
+
 func convertMetasMapToSlice(metas map[ulid.ULID]*block.Meta) []*block.Meta {
 	var out []*block.Meta
 	for _, m := range metas {
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+const (
+	// OverflowValue is the attribution value of the series whose value of the attribution label couldn't be tracked,
+	// because the maximum cardinality of the attribution values has been reached.
+	OverflowValue = "__overflow__"
+
+	// MissingValue is the attribution value of the series without the attribution label.
+	MissingValue = "__missing__"
+)
+
+// Tracker resolves the attribution value of the series of a single tenant, which is the value of the configured
+// attribution label. The number of distinct values tracked at the same time is capped by the max cardinality:
+// once reached, the series with a value which isn't tracked yet are attributed to OverflowValue, until some
+// tracked values are purged.
+type Tracker struct {
+	label          string
+	maxCardinality int
+
+	mtx    sync.Mutex
+	values map[string]*trackedValue
+}
+
+type trackedValue struct {
+	value    string
+	lastSeen int64 // Unix nanoseconds.
+}
+
+// NewTracker returns a Tracker attributing the series by the value of the input label, tracking up to
+// maxCardinality values at the same time. A maxCardinality of 0 means unlimited.
+func NewTracker(label string, maxCardinality int) *Tracker {
+	return &Tracker{
+		label:          label,
+		maxCardinality: maxCardinality,
+		values:         map[string]*trackedValue{},
+	}
+}
+
+// Label returns the attribution label.
+func (t *Tracker) Label() string {
+	return t.label
+}
+
+// MaxCardinality returns the maximum number of values tracked at the same time.
+func (t *Tracker) MaxCardinality() int {
+	return t.maxCardinality
+}
+
+// SameConfig returns true if the tracker attributes the series by the input label with the input max cardinality.
+func (t *Tracker) SameConfig(label string, maxCardinality int) bool {
+	return t.label == label && t.maxCardinality == maxCardinality
+}
+
+// Attribution returns the attribution value of the series with the input labels, and marks it as seen at now.
+// The returned value is never empty.
+func (t *Tracker) Attribution(lbls labels.Labels, now time.Time) string {
+	value := lbls.Get(t.label)
+	if value == "" {
+		return MissingValue
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	tv, ok := t.values[value]
+	if !ok {
+		if t.maxCardinality > 0 && len(t.values) >= t.maxCardinality {
+			return OverflowValue
+		}
+		// The labels may reference a buffer which gets reused, so the value is copied the first time it's tracked.
+		tv = &trackedValue{value: strings.Clone(value)}
+		t.values[tv.value] = tv
+	}
+	tv.lastSeen = now.UnixNano()
+	return tv.value
+}
+
+// Touch marks the input value as seen at now, if tracked.
+func (t *Tracker) Touch(value string, now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if tv, ok := t.values[value]; ok {
+		tv.lastSeen = now.UnixNano()
+	}
+}
+
+// Purge stops tracking the values which haven't been seen since keepUntil, freeing their slot for new values,
+// and returns them.
+func (t *Tracker) Purge(keepUntil time.Time) []string {
+	keepUntilNanos := keepUntil.UnixNano()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var removed []string
+	for value, tv := range t.values {
+		if tv.lastSeen < keepUntilNanos {
+			delete(t.values, value)
+			removed = append(removed, value)
+		}
+	}
+	return removed
+}
+
+// Values returns the currently tracked values.
+func (t *Tracker) Values() []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	values := make([]string, 0, len(t.values))
+	for value := range t.values {
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package costattribution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Attribution(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker("team", 2)
+
+	assert.Equal(t, "a", tracker.Attribution(labels.FromStrings("__name__", "foo", "team", "a"), now))
+	assert.Equal(t, "b", tracker.Attribution(labels.FromStrings("__name__", "foo", "team", "b"), now))
+	assert.Equal(t, MissingValue, tracker.Attribution(labels.FromStrings("__name__", "foo"), now))
+
+	// The max cardinality has been reached, so new values are attributed to the overflow value.
+	assert.Equal(t, OverflowValue, tracker.Attribution(labels.FromStrings("__name__", "foo", "team", "c"), now))
+
+	// Already tracked values are still attributed.
+	assert.Equal(t, "a", tracker.Attribution(labels.FromStrings("__name__", "bar", "team", "a"), now))
+	assert.ElementsMatch(t, []string{"a", "b"}, tracker.Values())
+}
+
+func TestTracker_Attribution_Unlimited(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker("team", 0)
+
+	for _, value := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, value, tracker.Attribution(labels.FromStrings("team", value), now))
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, tracker.Values())
+}
+
+func TestTracker_Purge(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker("team", 2)
+
+	require.Equal(t, "a", tracker.Attribution(labels.FromStrings("team", "a"), now))
+	require.Equal(t, "b", tracker.Attribution(labels.FromStrings("team", "b"), now.Add(time.Minute)))
+	require.Equal(t, OverflowValue, tracker.Attribution(labels.FromStrings("team", "c"), now.Add(time.Minute)))
+
+	// Touching a value which isn't tracked has no effect.
+	tracker.Touch("c", now.Add(2*time.Minute))
+
+	assert.Equal(t, []string{"a"}, tracker.Purge(now.Add(time.Minute)))
+	assert.Equal(t, []string{"b"}, tracker.Values())
+
+	// The purged value freed a slot for a new value.
+	assert.Equal(t, "c", tracker.Attribution(labels.FromStrings("team", "c"), now.Add(2*time.Minute)))
+
+	// Touched values are kept.
+	tracker.Touch("b", now.Add(2*time.Minute))
+	assert.Empty(t, tracker.Purge(now.Add(2*time.Minute)))
+	assert.ElementsMatch(t, []string{"b", "c"}, tracker.Values())
+}
+
+func TestTracker_SameConfig(t *testing.T) {
+	tracker := NewTracker("team", 10)
+
+	assert.True(t, tracker.SameConfig("team", 10))
+	assert.False(t, tracker.SameConfig("team", 20))
+	assert.False(t, tracker.SameConfig("namespace", 10))
+}
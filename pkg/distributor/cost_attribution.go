@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/mimir/pkg/costattribution"
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	// costAttributionPurgeInterval is how often the idle cost attribution values are purged.
+	costAttributionPurgeInterval = time.Minute
+
+	// costAttributionIdleTimeout is how long a cost attribution value is tracked after its last received sample.
+	costAttributionIdleTimeout = 20 * time.Minute
+)
+
+// costAttribution tracks the received samples per tenant and value of the tenant's cost attribution label.
+type costAttribution struct {
+	limits *validation.Overrides
+
+	mtx      sync.RWMutex
+	trackers map[string]*costattribution.Tracker
+
+	receivedSamples *prometheus.CounterVec
+}
+
+func newCostAttribution(limits *validation.Overrides, reg prometheus.Registerer) *costAttribution {
+	return &costAttribution{
+		limits:   limits,
+		trackers: map[string]*costattribution.Tracker{},
+
+		receivedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_received_attributed_samples_total",
+			Help: "The total number of received samples per user and value of the user's cost attribution label, excluding rejected and deduped samples.",
+		}, []string{"user", "attribution"}),
+	}
+}
+
+// tracker returns the cost attribution tracker of the input tenant, or nil if the cost attribution is disabled.
+// The tracker is replaced when the tenant's cost attribution config changes.
+func (c *costAttribution) tracker(userID string) *costattribution.Tracker {
+	label := c.limits.CostAttributionLabel(userID)
+	maxCardinality := c.limits.MaxCostAttributionCardinality(userID)
+
+	c.mtx.RLock()
+	t, ok := c.trackers[userID]
+	c.mtx.RUnlock()
+	if (ok && t.SameConfig(label, maxCardinality)) || (!ok && label == "") {
+		return t
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// Check again, since the tracker could have been replaced in the meantime.
+	t, ok = c.trackers[userID]
+	if ok && t.SameConfig(label, maxCardinality) {
+		return t
+	}
+	if ok {
+		c.receivedSamples.DeletePartialMatch(prometheus.Labels{"user": userID})
+		delete(c.trackers, userID)
+	}
+	if label == "" {
+		return nil
+	}
+
+	t = costattribution.NewTracker(label, maxCardinality)
+	c.trackers[userID] = t
+	return t
+}
+
+// updateReceivedSamples tracks the samples of the input series per cost attribution value.
+func (c *costAttribution) updateReceivedSamples(userID string, series []mimirpb.PreallocTimeseries, now time.Time) {
+	t := c.tracker(userID)
+	if t == nil {
+		return
+	}
+
+	samplesPerAttribution := map[string]int{}
+	for _, ts := range series {
+		attribution := t.Attribution(mimirpb.FromLabelAdaptersToLabels(ts.Labels), now)
+		samplesPerAttribution[attribution] += len(ts.Samples) + len(ts.Histograms)
+	}
+	for attribution, samples := range samplesPerAttribution {
+		if samples > 0 {
+			c.receivedSamples.WithLabelValues(userID, attribution).Add(float64(samples))
+		}
+	}
+}
+
+// purge stops tracking the cost attribution values which haven't received samples since keepUntil,
+// and removes their metrics.
+func (c *costAttribution) purge(keepUntil time.Time) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	for userID, t := range c.trackers {
+		for _, attribution := range t.Purge(keepUntil) {
+			c.receivedSamples.DeleteLabelValues(userID, attribution)
+		}
+	}
+}
+
+// removeTenant stops tracking the input tenant, and removes its metrics.
+func (c *costAttribution) removeTenant(userID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.trackers, userID)
+	c.receivedSamples.DeletePartialMatch(prometheus.Labels{"user": userID})
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestCostAttribution(t *testing.T) {
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.CostAttributionLabel = "team"
+	limits.MaxCostAttributionCardinality = 2
+
+	tenantLimits := map[string]*validation.Limits{
+		"user": &limits,
+	}
+	overrides, err := validation.NewOverrides(limits, validation.NewMockTenantLimits(tenantLimits))
+	require.NoError(t, err)
+
+	reg := prometheus.NewPedanticRegistry()
+	c := newCostAttribution(overrides, reg)
+
+	series := func(team string, samples int) mimirpb.PreallocTimeseries {
+		lbls := []mimirpb.LabelAdapter{{Name: "__name__", Value: "foo"}}
+		if team != "" {
+			lbls = append(lbls, mimirpb.LabelAdapter{Name: "team", Value: team})
+		}
+		ts := mimirpb.PreallocTimeseries{TimeSeries: &mimirpb.TimeSeries{Labels: lbls}}
+		for i := 0; i < samples; i++ {
+			ts.Samples = append(ts.Samples, mimirpb.Sample{TimestampMs: int64(i), Value: float64(i)})
+		}
+		return ts
+	}
+
+	now := time.Now()
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("a", 2), series("a", 1), series("b", 1)}, now)
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("c", 3), series("", 4)}, now.Add(time.Minute))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples per user and value of the user's cost attribution label, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{attribution="a",user="user"} 3
+		cortex_distributor_received_attributed_samples_total{attribution="b",user="user"} 1
+		cortex_distributor_received_attributed_samples_total{attribution="__overflow__",user="user"} 3
+		cortex_distributor_received_attributed_samples_total{attribution="__missing__",user="user"} 4
+	`), "cortex_distributor_received_attributed_samples_total"))
+
+	// Purging the idle values removes their metrics, and frees their slot for new values.
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("b", 1)}, now.Add(time.Minute))
+	c.purge(now.Add(time.Second))
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("c", 3)}, now.Add(time.Minute))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples per user and value of the user's cost attribution label, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{attribution="b",user="user"} 2
+		cortex_distributor_received_attributed_samples_total{attribution="c",user="user"} 3
+		cortex_distributor_received_attributed_samples_total{attribution="__overflow__",user="user"} 3
+		cortex_distributor_received_attributed_samples_total{attribution="__missing__",user="user"} 4
+	`), "cortex_distributor_received_attributed_samples_total"))
+
+	// Changing the cost attribution label replaces the tracker, and removes the metrics of the previous label.
+	limits.CostAttributionLabel = "namespace"
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("b", 1)}, now.Add(time.Minute))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_received_attributed_samples_total The total number of received samples per user and value of the user's cost attribution label, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_received_attributed_samples_total counter
+		cortex_distributor_received_attributed_samples_total{attribution="__missing__",user="user"} 1
+	`), "cortex_distributor_received_attributed_samples_total"))
+
+	// Disabling the cost attribution stops tracking the tenant.
+	limits.CostAttributionLabel = ""
+	c.updateReceivedSamples("user", []mimirpb.PreallocTimeseries{series("b", 1)}, now.Add(time.Minute))
+	assert.Nil(t, c.tracker("user"))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(``), "cortex_distributor_received_attributed_samples_total"))
+}
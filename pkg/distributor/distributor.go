@@ -99,8 +99,9 @@ type Distributor struct {
 	HATracker *haTracker
 
 	// Per-user rate limiters.
-	requestRateLimiter   *limiter.RateLimiter
-	ingestionRateLimiter *limiter.RateLimiter
+	requestRateLimiter           *limiter.RateLimiter
+	ingestionRateLimiter         *limiter.RateLimiter
+	labelSetIngestionRateLimiter *labelSetRateLimiter
 
 	// Manager for subservices (HA Tracker, distributor ring and client pool)
 	subservices        *services.Manager
@@ -109,6 +110,12 @@ type Distributor struct {
 	activeUsers  *util.ActiveUsersCleanupService
 	activeGroups *util.ActiveGroupsCleanupService
 
+	// For tracking the received samples per value of the tenants' cost attribution label.
+	costAttribution *costAttribution
+
+	// For spooling the write requests failing with a retryable error. Nil if disabled.
+	writeSpool *writeSpool
+
 	ingestionRate             *util_math.EwmaRate
 	inflightPushRequests      atomic.Int64
 	inflightPushRequestsBytes atomic.Int64
@@ -119,6 +126,7 @@ type Distributor struct {
 	receivedSamples                  *prometheus.CounterVec
 	receivedExemplars                *prometheus.CounterVec
 	receivedMetadata                 *prometheus.CounterVec
+	receivedLabelSetSamples          *prometheus.CounterVec
 	incomingRequests                 *prometheus.CounterVec
 	incomingSamples                  *prometheus.CounterVec
 	incomingExemplars                *prometheus.CounterVec
@@ -131,11 +139,15 @@ type Distributor struct {
 	latestSeenSampleTimestampPerUser *prometheus.GaugeVec
 
 	// Metrics for data rejected for hitting per-tenant limits
-	discardedSamplesTooManyHaClusters *prometheus.CounterVec
-	discardedSamplesRateLimited       *prometheus.CounterVec
-	discardedRequestsRateLimited      *prometheus.CounterVec
-	discardedExemplarsRateLimited     *prometheus.CounterVec
-	discardedMetadataRateLimited      *prometheus.CounterVec
+	discardedSamplesTooManyHaClusters   *prometheus.CounterVec
+	discardedSamplesRateLimited         *prometheus.CounterVec
+	discardedRequestsRateLimited        *prometheus.CounterVec
+	discardedExemplarsRateLimited       *prometheus.CounterVec
+	discardedMetadataRateLimited        *prometheus.CounterVec
+	discardedSamplesLabelSetRateLimited *prometheus.CounterVec
+
+	// Metrics for data which would have been rejected by per-tenant limits in dry-run mode
+	dryRunDiscardedSamplesRelabeled *prometheus.CounterVec
 
 	// Metrics for data rejected for hitting per-instance limits
 	rejectedRequests *prometheus.CounterVec
@@ -157,6 +169,8 @@ type Config struct {
 
 	HATrackerConfig HATrackerConfig `yaml:"ha_tracker"`
 
+	WriteSpool WriteSpoolConfig `yaml:"write_spool"`
+
 	MaxRecvMsgSize int           `yaml:"max_recv_msg_size" category:"advanced"`
 	RemoteTimeout  time.Duration `yaml:"remote_timeout" category:"advanced"`
 
@@ -197,6 +211,7 @@ type PushWrapper func(next PushFunc) PushFunc
 func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	cfg.PoolConfig.RegisterFlags(f)
 	cfg.HATrackerConfig.RegisterFlags(f)
+	cfg.WriteSpool.RegisterFlags(f)
 	cfg.DistributorRing.RegisterFlags(f, logger)
 
 	f.IntVar(&cfg.MaxRecvMsgSize, "distributor.max-recv-msg-size", 100<<20, "Max message size in bytes that the distributors will accept for incoming push requests to the remote write API. If exceeded, the request will be rejected.")
@@ -213,6 +228,10 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		return errInvalidTenantShardSize
 	}
 
+	if err := cfg.WriteSpool.Validate(); err != nil {
+		return err
+	}
+
 	return cfg.HATrackerConfig.Validate()
 }
 
@@ -250,6 +269,7 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		limits:                limits,
 		HATracker:             haTracker,
 		ingestionRate:         util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval),
+		costAttribution:       newCostAttribution(limits, reg),
 
 		queryDuration: instrument.NewHistogramCollector(promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "cortex_distributor_query_duration_seconds",
@@ -272,6 +292,10 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 			Name: "cortex_distributor_received_metadata_total",
 			Help: "The total number of received metadata, excluding rejected.",
 		}, []string{"user"}),
+		receivedLabelSetSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_label_set_received_samples_total",
+			Help: "The total number of received samples matching the selector of a per-label-set limit, excluding rejected and deduped samples.",
+		}, []string{"user", "label_set"}),
 		incomingRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "cortex_distributor_requests_in_total",
 			Help: "The total number of requests that have come in to the distributor, including rejected or deduped requests.",
@@ -328,11 +352,14 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 			Help: "Unix timestamp of latest received sample per user.",
 		}, []string{"user"}),
 
-		discardedSamplesTooManyHaClusters: validation.DiscardedSamplesCounter(reg, reasonTooManyHAClusters),
-		discardedSamplesRateLimited:       validation.DiscardedSamplesCounter(reg, reasonRateLimited),
-		discardedRequestsRateLimited:      validation.DiscardedRequestsCounter(reg, reasonRateLimited),
-		discardedExemplarsRateLimited:     validation.DiscardedExemplarsCounter(reg, reasonRateLimited),
-		discardedMetadataRateLimited:      validation.DiscardedMetadataCounter(reg, reasonRateLimited),
+		discardedSamplesTooManyHaClusters:   validation.DiscardedSamplesCounter(reg, reasonTooManyHAClusters),
+		discardedSamplesRateLimited:         validation.DiscardedSamplesCounter(reg, reasonRateLimited),
+		discardedRequestsRateLimited:        validation.DiscardedRequestsCounter(reg, reasonRateLimited),
+		discardedExemplarsRateLimited:       validation.DiscardedExemplarsCounter(reg, reasonRateLimited),
+		discardedMetadataRateLimited:        validation.DiscardedMetadataCounter(reg, reasonRateLimited),
+		discardedSamplesLabelSetRateLimited: validation.DiscardedSamplesCounter(reg, reasonLabelSetRateLimited),
+
+		dryRunDiscardedSamplesRelabeled: validation.DryRunDiscardedSamplesCounter(reg, reasonMetricRelabelConfigs),
 
 		rejectedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "cortex_distributor_instance_rejected_requests_total",
@@ -396,13 +423,14 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	// Create the configured ingestion rate limit strategy (local or global). In case
 	// it's an internal dependency and we can't join the distributors ring, we skip rate
 	// limiting.
-	var ingestionRateStrategy, requestRateStrategy limiter.RateLimiterStrategy
+	var ingestionRateStrategy, requestRateStrategy, labelSetIngestionRateStrategy limiter.RateLimiterStrategy
 	var distributorsLifecycler *ring.BasicLifecycler
 	var distributorsRing *ring.Ring
 
 	if !canJoinDistributorsRing {
 		requestRateStrategy = newInfiniteRateStrategy()
 		ingestionRateStrategy = newInfiniteRateStrategy()
+		labelSetIngestionRateStrategy = newInfiniteRateStrategy()
 	} else {
 		distributorsRing, distributorsLifecycler, err = newRingAndLifecycler(cfg.DistributorRing, d.healthyInstancesCount, log, reg)
 		if err != nil {
@@ -412,10 +440,12 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		subservices = append(subservices, distributorsLifecycler, distributorsRing)
 		requestRateStrategy = newGlobalRateStrategy(newRequestRateStrategy(limits), d)
 		ingestionRateStrategy = newGlobalRateStrategy(newIngestionRateStrategy(limits), d)
+		labelSetIngestionRateStrategy = newGlobalRateStrategy(newLabelSetIngestionRateStrategy(limits), d)
 	}
 
 	d.requestRateLimiter = limiter.NewRateLimiter(requestRateStrategy, 10*time.Second)
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
+	d.labelSetIngestionRateLimiter = newLabelSetRateLimiter(labelSetIngestionRateStrategy, 10*time.Second)
 	d.distributorsLifecycler = distributorsLifecycler
 	d.distributorsRing = distributorsRing
 
@@ -425,6 +455,11 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 
 	d.PushWithMiddlewares = d.wrapPushWithMiddlewares(d.push)
 
+	if cfg.WriteSpool.Enabled {
+		d.writeSpool = newWriteSpool(cfg.WriteSpool, d.replaySpooledRequest, log, reg)
+		subservices = append(subservices, d.writeSpool)
+	}
+
 	subservices = append(subservices, d.ingesterPool, d.activeUsers)
 	d.subservices, err = services.NewManager(subservices...)
 	if err != nil {
@@ -491,6 +526,9 @@ func (d *Distributor) running(ctx context.Context) error {
 	ingestionRateTicker := time.NewTicker(instanceIngestionRateTickInterval)
 	defer ingestionRateTicker.Stop()
 
+	costAttributionPurgeTicker := time.NewTicker(costAttributionPurgeInterval)
+	defer costAttributionPurgeTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -499,6 +537,9 @@ func (d *Distributor) running(ctx context.Context) error {
 		case <-ingestionRateTicker.C:
 			d.ingestionRate.Tick()
 
+		case now := <-costAttributionPurgeTicker.C:
+			d.costAttribution.purge(now.Add(-costAttributionIdleTimeout))
+
 		case err := <-d.subservicesWatcher.Chan():
 			return errors.Wrap(err, "distributor subservice failed")
 		}
@@ -509,6 +550,10 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	d.ingestersRing.CleanupShuffleShardCache(userID)
 
 	d.HATracker.cleanupHATrackerMetricsForUser(userID)
+	d.costAttribution.removeTenant(userID)
+	if d.writeSpool != nil {
+		d.writeSpool.cleanupMetricsForUser(userID)
+	}
 
 	d.receivedRequests.DeleteLabelValues(userID)
 	d.receivedSamples.DeleteLabelValues(userID)
@@ -528,6 +573,9 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	d.discardedRequestsRateLimited.DeleteLabelValues(userID)
 	d.discardedExemplarsRateLimited.DeleteLabelValues(userID)
 	d.discardedMetadataRateLimited.DeleteLabelValues(userID)
+	d.discardedSamplesLabelSetRateLimited.DeletePartialMatch(filter)
+	d.receivedLabelSetSamples.DeletePartialMatch(filter)
+	d.dryRunDiscardedSamplesRelabeled.DeletePartialMatch(filter)
 
 	d.sampleValidationMetrics.deleteUserMetrics(userID)
 	d.exemplarValidationMetrics.deleteUserMetrics(userID)
@@ -538,6 +586,8 @@ func (d *Distributor) RemoveGroupMetricsForUser(userID, group string) {
 	d.dedupedSamples.DeleteLabelValues(userID, group)
 	d.discardedSamplesTooManyHaClusters.DeleteLabelValues(userID, group)
 	d.discardedSamplesRateLimited.DeleteLabelValues(userID, group)
+	d.discardedSamplesLabelSetRateLimited.DeleteLabelValues(userID, group)
+	d.dryRunDiscardedSamplesRelabeled.DeleteLabelValues(userID, group)
 	d.sampleValidationMetrics.deleteUserMetricsForGroup(userID, group)
 }
 
@@ -777,6 +827,13 @@ func (d *Distributor) prePushRelabelMiddleware(next PushFunc) PushFunc {
 
 		var removeTsIndexes []int
 		lb := labels.NewBuilder(labels.EmptyLabels())
+		// In dry-run mode, the metric relabel configs rewrite the labels as in the enforcing mode, so that the same
+		// series are written, but the series they drop are only tracked, rather than removed.
+		dryRun := d.limits.LimitsDryRun(userID)
+		var group string
+		if dryRun {
+			group = d.activeGroups.UpdateActiveGroupTimestamp(userID, validation.GroupLabel(d.limits, userID, req.Timeseries), time.Now())
+		}
 		for tsIdx := 0; tsIdx < len(req.Timeseries); tsIdx++ {
 			ts := req.Timeseries[tsIdx]
 
@@ -784,12 +841,19 @@ func (d *Distributor) prePushRelabelMiddleware(next PushFunc) PushFunc {
 				mimirpb.FromLabelAdaptersToBuilder(ts.Labels, lb)
 				lb.Set(metaLabelTenantID, userID)
 				keep := relabel.ProcessBuilder(lb, mrc...)
-				if !keep {
+				switch {
+				case keep:
+					lb.Del(metaLabelTenantID)
+					req.Timeseries[tsIdx].SetLabels(mimirpb.FromBuilderToLabelAdapters(lb, ts.Labels))
+				case dryRun:
+					// The series is kept with its original labels, as it would have been dropped.
+					err := fmt.Errorf("received a series which would be dropped by the metric relabel configs, series: '%.200s'", formatLabelSet(ts.Labels))
+					d.dryRunDiscardedSamplesRelabeled.WithLabelValues(userID, group).Add(float64(len(ts.Samples) + len(ts.Histograms)))
+					logDryRunLimitExceeded(userID, err)
+				default:
 					removeTsIndexes = append(removeTsIndexes, tsIdx)
 					continue
 				}
-				lb.Del(metaLabelTenantID)
-				req.Timeseries[tsIdx].SetLabels(mimirpb.FromBuilderToLabelAdapters(lb, ts.Labels))
 			}
 
 			for _, labelName := range d.limits.DropLabels(userID) {
@@ -940,6 +1004,10 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 			return firstPartialErr
 		}
 
+		if err := d.checkLabelSetIngestionRate(now, userID, group, req.Timeseries, validatedSamples); err != nil {
+			return err
+		}
+
 		totalN := validatedSamples + validatedExemplars + validatedMetadata
 		if !d.ingestionRateLimiter.AllowN(now, userID, totalN) {
 			d.discardedSamplesRateLimited.WithLabelValues(userID, group).Add(float64(validatedSamples))
@@ -962,6 +1030,53 @@ func (d *Distributor) prePushValidationMiddleware(next PushFunc) PushFunc {
 	}
 }
 
+// checkLabelSetIngestionRate enforces the per-label-set ingestion rate limits of the tenant on the input series,
+// and tracks the number of received samples per label set. If any limit is exceeded, the whole request is rejected.
+func (d *Distributor) checkLabelSetIngestionRate(now time.Time, userID, group string, series []mimirpb.PreallocTimeseries, validatedSamples int) error {
+	limits := d.limits.LabelSetLimits(userID)
+	if len(limits) == 0 {
+		return nil
+	}
+
+	samplesPerLimit := make([]int, len(limits))
+	for _, ts := range series {
+		lbls := mimirpb.FromLabelAdaptersToLabels(ts.Labels)
+		for i, limit := range limits {
+			if limit.Matches(lbls) {
+				samplesPerLimit[i] += len(ts.Samples) + len(ts.Histograms)
+			}
+		}
+	}
+
+	// Check all the limits before consuming the samples from any of them, so that a request rejected because of
+	// a limit doesn't consume the samples of the other limits.
+	var (
+		rateLimited []*validation.LabelSetLimit
+		keys        []string
+		samples     []int
+	)
+	for i, limit := range limits {
+		if samplesPerLimit[i] == 0 || limit.IngestionRate <= 0 {
+			continue
+		}
+		rateLimited = append(rateLimited, limit)
+		keys = append(keys, labelSetRateLimiterKey(userID, limit.Selector))
+		samples = append(samples, samplesPerLimit[i])
+	}
+	if idx, ok := d.labelSetIngestionRateLimiter.allowAll(now, keys, samples); !ok {
+		limit := rateLimited[idx]
+		d.discardedSamplesLabelSetRateLimited.WithLabelValues(userID, group).Add(float64(validatedSamples))
+		return newLabelSetIngestionRateLimitedError(limit.Selector, limit.IngestionRate, limit.IngestionBurstSize)
+	}
+
+	for i, limit := range limits {
+		if samplesPerLimit[i] > 0 {
+			d.receivedLabelSetSamples.WithLabelValues(userID, limit.Selector).Add(float64(samplesPerLimit[i]))
+		}
+	}
+	return nil
+}
+
 // metricsMiddleware updates metrics which are expected to account for all received data,
 // including data that later gets modified or dropped.
 func (d *Distributor) metricsMiddleware(next PushFunc) PushFunc {
@@ -1268,6 +1383,44 @@ func (d *Distributor) push(ctx context.Context, pushReq *Request) error {
 		span.SetTag("organization", userID)
 	}
 
+	// we must not re-use buffers now until all DoBatch goroutines have finished,
+	// so set this flag false and pass cleanup() to DoBatch.
+	cleanupInDefer = false
+
+	if d.writeSpool == nil {
+		return d.sendToIngesters(ctx, userID, req, pushReq.CleanUp)
+	}
+
+	// The request is retained until it's known whether it has to be spooled, so buffers are
+	// released only once both DoBatch goroutines and this function are done with it.
+	var pending atomic.Int32
+	pending.Store(2)
+	cleanup := func() {
+		if pending.Dec() == 0 {
+			pushReq.CleanUp()
+		}
+	}
+	defer cleanup()
+
+	err = d.sendToIngesters(ctx, userID, req, cleanup)
+	if err == nil || !isRetryablePushError(err) {
+		return err
+	}
+	if spoolErr := d.writeSpool.spool(userID, req); spoolErr != nil {
+		level.Warn(d.log).Log("msg", "failed to spool write request", "user", userID, "err", spoolErr)
+		return err
+	}
+	return nil
+}
+
+// replaySpooledRequest pushes a write request replayed from the write spool to the ingesters.
+func (d *Distributor) replaySpooledRequest(ctx context.Context, userID string, req *mimirpb.WriteRequest) error {
+	return d.sendToIngesters(ctx, userID, req, func() {})
+}
+
+// sendToIngesters distributes the write request to the ingesters using the ring. The cleanup function
+// is called once all ingesters have been sent the request, and the request buffers can be released.
+func (d *Distributor) sendToIngesters(ctx context.Context, userID string, req *mimirpb.WriteRequest, cleanup func()) error {
 	seriesKeys := d.getTokensForSeries(userID, req.Timeseries)
 	metadataKeys := make([]uint32, 0, len(req.Metadata))
 
@@ -1294,16 +1447,12 @@ func (d *Distributor) push(ctx context.Context, pushReq *Request) error {
 	copy(keys, seriesKeys)
 	copy(keys[initialMetadataIndex:], metadataKeys)
 
-	// we must not re-use buffers now until all DoBatch goroutines have finished,
-	// so set this flag false and pass cleanup() to DoBatch.
-	cleanupInDefer = false
-
 	if d.cfg.WriteRequestsBufferPoolingEnabled {
 		slabPool := pool.NewFastReleasingSlabPool[byte](&d.writeRequestBytePool, writeRequestSlabPoolSize)
 		localCtx = ingester_client.WithSlabPool(localCtx, slabPool)
 	}
 
-	err = ring.DoBatch(ctx, ring.WriteNoExtend, subRing, keys, func(ingester ring.InstanceDesc, indexes []int) error {
+	return ring.DoBatch(ctx, ring.WriteNoExtend, subRing, keys, func(ingester ring.InstanceDesc, indexes []int) error {
 		var timeseriesCount, metadataCount int
 		for _, i := range indexes {
 			if i >= initialMetadataIndex {
@@ -1329,9 +1478,7 @@ func (d *Distributor) push(ctx context.Context, pushReq *Request) error {
 			return errors.Wrap(err, deadlineExceededWrapMessage)
 		}
 		return err
-	}, func() { pushReq.CleanUp(); cancel() })
-
-	return err
+	}, func() { cleanup(); cancel() })
 }
 
 func preallocSliceIfNeeded[T any](size int) []T {
@@ -1364,6 +1511,8 @@ func (d *Distributor) updateReceivedMetrics(req *mimirpb.WriteRequest, userID st
 	d.receivedSamples.WithLabelValues(userID).Add(float64(receivedSamples))
 	d.receivedExemplars.WithLabelValues(userID).Add(float64(receivedExemplars))
 	d.receivedMetadata.WithLabelValues(userID).Add(float64(receivedMetadata))
+
+	d.costAttribution.updateReceivedSamples(userID, req.Timeseries, time.Now())
 }
 
 func copyString(s string) string {
@@ -1580,6 +1729,208 @@ func (m *labelNamesAndValuesResponseMerger) putItemsToMap(message *ingester_clie
 	return nil
 }
 
+// ActiveSeries queries ingesters for the active series matching the matchers.
+// The series are deduplicated in memory, so the size of the distinct series is limited by
+// ActiveSeriesResultsMaxSizeBytes. The order of the returned series is not guaranteed.
+func (d *Distributor) ActiveSeries(ctx context.Context, matchers []*labels.Matcher) ([]labels.Labels, error) {
+	replicationSet, err := d.GetIngesters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchersProto, err := ingester_client.ToLabelMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+	req := &ingester_client.ActiveSeriesRequest{Matchers: matchersProto}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizeLimitBytes := d.limits.ActiveSeriesResultsMaxSizeBytes(userID)
+	merger := &activeSeriesResponseMerger{result: map[string]labels.Labels{}, sizeLimitBytes: sizeLimitBytes}
+	_, err = forReplicationSet(ctx, d, replicationSet, func(ctx context.Context, client ingester_client.IngesterClient) (ingester_client.Ingester_ActiveSeriesClient, error) {
+		stream, err := client.ActiveSeries(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.CloseSend() //nolint:errcheck
+		return nil, merger.collectResponses(stream)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merger.toSeries(), nil
+}
+
+type activeSeriesResponseMerger struct {
+	lock             sync.Mutex
+	result           map[string]labels.Labels
+	sizeLimitBytes   int
+	currentSizeBytes int
+}
+
+// toSeries returns the distinct series collected from the ingesters responses.
+func (m *activeSeriesResponseMerger) toSeries() []labels.Labels {
+	// we need to acquire the lock to prevent concurrent read/write to the map because it might be a case that some ingesters responses are
+	// still being processed if replicationSet.Do() returned execution to this method when it decided that it got enough responses from the quorum of instances.
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	series := make([]labels.Labels, 0, len(m.result))
+	for _, lbls := range m.result {
+		series = append(series, lbls)
+	}
+	return series
+}
+
+// collectResponses listens for the stream and once the message is received, puts the series to the map with distinct series.
+func (m *activeSeriesResponseMerger) collectResponses(stream ingester_client.Ingester_ActiveSeriesClient) error {
+	for {
+		message, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		err = m.putSeriesToMap(message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *activeSeriesResponseMerger) putSeriesToMap(message *ingester_client.ActiveSeriesResponse) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, metric := range message.Metric {
+		lbls := mimirpb.FromLabelAdaptersToLabelsWithCopy(metric.Labels)
+		key := lbls.String()
+		if _, exists := m.result[key]; exists {
+			continue
+		}
+		for _, l := range metric.Labels {
+			m.currentSizeBytes += len(l.Name) + len(l.Value)
+		}
+		if m.currentSizeBytes > m.sizeLimitBytes {
+			return httpgrpc.Errorf(http.StatusBadRequest, "size of distinct active series is greater than %v bytes", m.sizeLimitBytes)
+		}
+		m.result[key] = lbls
+	}
+	return nil
+}
+
+// ActiveSeriesBreakdown queries ingesters for the number of active series and native histogram buckets per metric name,
+// and per value of labelName if not empty, of the series matching the matchers.
+// The counts are adjusted to the replication factor.
+func (d *Distributor) ActiveSeriesBreakdown(ctx context.Context, matchers []*labels.Matcher, labelName string) (*ingester_client.ActiveSeriesBreakdownResponse, error) {
+	replicationSet, err := d.GetIngesters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If we have a single zone, we require all ingesters to respond.
+	if replicationSet.ZoneCount() == 1 {
+		replicationSet.MaxErrors = 0
+	}
+
+	matchersProto, err := ingester_client.ToLabelMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+	req := &ingester_client.ActiveSeriesBreakdownRequest{Matchers: matchersProto, LabelName: labelName}
+
+	breakdown := &activeSeriesBreakdownConcurrentMap{
+		breakdownByZone: map[activeSeriesBreakdownKey]*activeSeriesBreakdownByZone{},
+	}
+
+	_, err = ring.DoUntilQuorum[struct{}](ctx, replicationSet, d.queryQuorumConfig(ctx), func(ctx context.Context, desc *ring.InstanceDesc) (struct{}, error) {
+		poolClient, err := d.ingesterPool.GetClientForInstance(*desc)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		client := poolClient.(ingester_client.IngesterClient)
+
+		stream, err := client.ActiveSeriesBreakdown(ctx, req)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer func() { _ = stream.CloseSend() }()
+
+		return struct{}{}, breakdown.processActiveSeriesBreakdownMessages(desc.Zone, stream)
+	}, func(struct{}) {})
+	if err != nil {
+		return nil, err
+	}
+	return breakdown.toActiveSeriesBreakdownResponse(replicationSet.ZoneCount(), d.ingestersRing.ReplicationFactor()), nil
+}
+
+type activeSeriesBreakdownKey struct {
+	metricName, labelValue string
+}
+
+type activeSeriesBreakdownByZone struct {
+	seriesCount            map[string]uint64
+	nativeHistogramBuckets map[string]uint64
+}
+
+type activeSeriesBreakdownConcurrentMap struct {
+	// breakdownByZone stores the counts of each item for each zone.
+	breakdownByZone map[activeSeriesBreakdownKey]*activeSeriesBreakdownByZone
+	lock            sync.Mutex
+}
+
+func (bm *activeSeriesBreakdownConcurrentMap) processActiveSeriesBreakdownMessages(zone string, stream ingester_client.Ingester_ActiveSeriesBreakdownClient) error {
+	for {
+		message, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		bm.processActiveSeriesBreakdownMessage(zone, message)
+	}
+	return nil
+}
+
+// processActiveSeriesBreakdownMessage sums up the counts of each item received from the ingesters of the zone.
+func (bm *activeSeriesBreakdownConcurrentMap) processActiveSeriesBreakdownMessage(zone string, message *ingester_client.ActiveSeriesBreakdownResponse) {
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	for _, item := range message.Items {
+		key := activeSeriesBreakdownKey{metricName: item.MetricName, labelValue: item.LabelValue}
+		byZone, ok := bm.breakdownByZone[key]
+		if !ok {
+			byZone = &activeSeriesBreakdownByZone{seriesCount: map[string]uint64{}, nativeHistogramBuckets: map[string]uint64{}}
+			bm.breakdownByZone[key] = byZone
+		}
+		byZone.seriesCount[zone] += item.SeriesCount
+		byZone.nativeHistogramBuckets[zone] += item.NativeHistogramBuckets
+	}
+}
+
+// toActiveSeriesBreakdownResponse adjusts the counts to the replication factor and converts the map to `ingester_client.ActiveSeriesBreakdownResponse`.
+func (bm *activeSeriesBreakdownConcurrentMap) toActiveSeriesBreakdownResponse(zoneCount int, replicationFactor int) *ingester_client.ActiveSeriesBreakdownResponse {
+	// we need to acquire the lock to prevent concurrent read/write to the map
+	bm.lock.Lock()
+	defer bm.lock.Unlock()
+
+	items := make([]*ingester_client.ActiveSeriesBreakdownItem, 0, len(bm.breakdownByZone))
+	for key, byZone := range bm.breakdownByZone {
+		items = append(items, &ingester_client.ActiveSeriesBreakdownItem{
+			MetricName:             key.metricName,
+			LabelValue:             key.labelValue,
+			SeriesCount:            approximateFromZones(zoneCount, replicationFactor, byZone.seriesCount),
+			NativeHistogramBuckets: approximateFromZones(zoneCount, replicationFactor, byZone.nativeHistogramBuckets),
+		})
+	}
+
+	return &ingester_client.ActiveSeriesBreakdownResponse{Items: items}
+}
+
 // LabelValuesCardinality performs the following two operations in parallel:
 //   - queries ingesters for label values cardinality of a set of labelNames
 //   - queries ingesters for user stats to get the ingester's series head count
@@ -12,6 +12,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -641,6 +642,79 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushLabelSetIngestionRateLimiter(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.LabelSetLimits = []*validation.LabelSetLimit{
+		{Selector: `{__name__="foo"}`, IngestionRate: 10, IngestionBurstSize: 5},
+	}
+
+	// Start two distributors, so that each one of them gets half of the per-label-set ingestion rate.
+	distributors, _, regs := prepare(t, prepConfig{
+		numIngesters:    3,
+		happyIngesters:  3,
+		numDistributors: 2,
+		limits:          limits,
+	})
+
+	expectedErr := newLabelSetIngestionRateLimitedError(`{__name__="foo"}`, 10, 5)
+	expectedErrorDetails := &mimirpb.WriteErrorDetails{Cause: mimirpb.INGESTION_RATE_LIMITED}
+
+	response, err := distributors[0].Push(ctx, makeWriteRequest(0, 5, 0, false, false, "foo"))
+	require.NoError(t, err)
+	require.Equal(t, emptyResponse, response)
+
+	// The series matching the selector have exhausted the burst.
+	response, err = distributors[0].Push(ctx, makeWriteRequest(0, 1, 0, false, false, "foo", "bar"))
+	require.Nil(t, response)
+	checkGRPCError(t, status.New(codes.ResourceExhausted, expectedErr.Error()), expectedErrorDetails, err)
+
+	// The series which don't match the selector aren't limited.
+	response, err = distributors[0].Push(ctx, makeWriteRequest(0, 10, 0, false, false, "bar"))
+	require.NoError(t, err)
+	require.Equal(t, emptyResponse, response)
+
+	require.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="label_set_rate_limited",user="user"} 2
+
+		# HELP cortex_distributor_label_set_received_samples_total The total number of received samples matching the selector of a per-label-set limit, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_label_set_received_samples_total counter
+		cortex_distributor_label_set_received_samples_total{label_set="{__name__=\"foo\"}",user="user"} 5
+	`), "cortex_discarded_samples_total", "cortex_distributor_label_set_received_samples_total"))
+}
+
+func TestDistributor_PushLabelSetIngestionRateLimiter_RejectedRequestsDontConsumeOtherLimits(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.LabelSetLimits = []*validation.LabelSetLimit{
+		{Selector: `{__name__=~"foo.*"}`, IngestionRate: 0.01, IngestionBurstSize: 10},
+		{Selector: `{__name__=~"foo.*", app=~"small.*"}`, IngestionRate: 0.01, IngestionBurstSize: 1},
+	}
+
+	distributors, _, _ := prepare(t, prepConfig{
+		numIngesters:    3,
+		happyIngesters:  3,
+		numDistributors: 1,
+		limits:          limits,
+	})
+
+	// The request exceeds the second limit only, so it doesn't consume the samples of the first one.
+	for i := 0; i < 5; i++ {
+		_, err := distributors[0].Push(ctx, makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, model.MetricNameLabel, "foo", "app", "small", "instance", "instance_%d"), nil, nil))
+		require.ErrorContains(t, err, `{__name__=~"foo.*", app=~"small.*"}`)
+	}
+
+	response, err := distributors[0].Push(ctx, makeWriteRequest(0, 10, 0, false, false, "foo"))
+	require.NoError(t, err)
+	require.Equal(t, emptyResponse, response)
+}
+
 func TestDistributor_PushInstanceLimits(t *testing.T) {
 	type testPush struct {
 		samples       int
@@ -1073,6 +1147,37 @@ func TestDistributor_PushQuery(t *testing.T) {
 	}
 }
 
+func TestDistributor_ReadOnlyIngesters(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	ds, ingesters, _ := prepare(t, prepConfig{
+		numIngesters:      4,
+		happyIngesters:    4,
+		readOnlyIngesters: 1,
+		numDistributors:   1,
+		replicationFactor: 3,
+	})
+
+	// Writes should succeed without being sent to the read-only ingester, which is replaced
+	// by the next ingester in the ring, so that every series is still written to 3 ingesters.
+	_, err := ds[0].Push(ctx, makeWriteRequest(0, 10, 0, false, false))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, ingesters[0].countCalls("Push"))
+	assert.Len(t, ingesters[0].series(), 0)
+	for i := 1; i < len(ingesters); i++ {
+		// The push returns once the quorum is reached, so the last ingester may still be receiving it.
+		test.Poll(t, time.Second, 10, func() interface{} {
+			return len(ingesters[i].series())
+		})
+	}
+
+	// Queries should still be sent to the read-only ingester.
+	replicationSet, err := ds[0].GetIngesters(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0", "1", "2", "3"}, replicationSet.GetAddresses())
+}
+
 func TestDistributor_Push_LabelRemoval(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "user")
 
@@ -2203,6 +2308,81 @@ func TestDistributor_LabelNamesAndValuesLimitTest(t *testing.T) {
 	}
 }
 
+func TestDistributor_ActiveSeries(t *testing.T) {
+	fixtures := []struct {
+		lbls      labels.Labels
+		value     float64
+		timestamp int64
+	}{
+		{labels.FromStrings(labels.MetricName, "label_0", "status", "200"), 1, 100000},
+		{labels.FromStrings(labels.MetricName, "label_1", "status", "500"), 1, 110000},
+		{labels.FromStrings(labels.MetricName, "label_1", "status", "500"), 2, 200000},
+		{labels.FromStrings(labels.MetricName, "label_1"), 2, 200000},
+	}
+	tests := map[string]struct {
+		matchers       []*labels.Matcher
+		sizeLimitBytes int
+		expectedSeries []labels.Labels
+		expectedError  string
+	}{
+		"should return the distinct series matching the matchers": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "label_1")},
+			sizeLimitBytes: 39,
+			expectedSeries: []labels.Labels{
+				labels.FromStrings(labels.MetricName, "label_1"),
+				labels.FromStrings(labels.MetricName, "label_1", "status", "500"),
+			},
+		},
+		"should return no series if no series match the matchers": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "unknown")},
+			sizeLimitBytes: 1024,
+			expectedSeries: []labels.Labels{},
+		},
+		// The distinct series "__name__=label_1" and "__name__=label_1,status=500" are 39 bytes in total.
+		"should fail if the size limit is reached": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "label_1")},
+			sizeLimitBytes: 38,
+			expectedError:  "size of distinct active series is greater than 38 bytes",
+		},
+	}
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "active-series")
+
+			limits := validation.Limits{}
+			flagext.DefaultValues(&limits)
+			limits.ActiveSeriesResultsMaxSizeBytes = testData.sizeLimitBytes
+			ds, _, _ := prepare(t, prepConfig{
+				numIngesters:    3,
+				happyIngesters:  3,
+				numDistributors: 1,
+				limits:          &limits,
+			})
+			t.Cleanup(func() {
+				require.NoError(t, services.StopAndAwaitTerminated(ctx, ds[0]))
+			})
+
+			// Push fixtures
+			for _, series := range fixtures {
+				req := mockWriteRequest(series.lbls, series.value, series.timestamp)
+				_, err := ds[0].Push(ctx, req)
+				require.NoError(t, err)
+			}
+
+			series, err := ds[0].ActiveSeries(ctx, testData.matchers)
+			if len(testData.expectedError) > 0 {
+				resp, ok := httpgrpc.HTTPResponseFromError(err)
+				require.True(t, ok, "expected an httpgrpc error, got: %v", err)
+				require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+				require.Equal(t, testData.expectedError, string(resp.Body))
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, testData.expectedSeries, series)
+		})
+	}
+}
+
 func TestDistributor_LabelValuesForLabelName(t *testing.T) {
 	fixtures := []struct {
 		lbls      labels.Labels
@@ -2538,6 +2718,123 @@ func TestDistributor_LabelValuesCardinality(t *testing.T) {
 	}
 }
 
+func TestDistributor_ActiveSeriesBreakdown(t *testing.T) {
+	const numIngesters = 3
+	const replicationFactor = 3
+
+	ctx := user.InjectOrgID(context.Background(), "active-series-breakdown")
+
+	testHistogram := util_test.GenerateTestHistogram(1)
+	bucketsPerHistogram := uint64(len(testHistogram.PositiveBuckets) + len(testHistogram.NegativeBuckets))
+
+	pushFixtures := func(t *testing.T, d *Distributor) {
+		for _, series := range []labels.Labels{
+			labels.FromStrings(labels.MetricName, "test_1", "status", "200"),
+			labels.FromStrings(labels.MetricName, "test_1", "status", "500"),
+			labels.FromStrings(labels.MetricName, "test_2"),
+		} {
+			_, err := d.Push(ctx, mockWriteRequest(series, 1, 100000))
+			require.NoError(t, err)
+		}
+		_, err := d.Push(ctx, makeWriteRequestHistogram([]string{labels.MetricName, "test_histogram", "status", "200"}, 100000, testHistogram))
+		require.NoError(t, err)
+	}
+
+	tests := map[string]struct {
+		matchers          []*labels.Matcher
+		labelName         string
+		ingesterZones     []string
+		happyIngesters    int
+		expectedItems     []*client.ActiveSeriesBreakdownItem
+		expectedIngesters int
+	}{
+		"should break down the series by metric name": {
+			happyIngesters: numIngesters,
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "test_1", SeriesCount: 2},
+				{MetricName: "test_2", SeriesCount: 1},
+				{MetricName: "test_histogram", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+			expectedIngesters: numIngesters,
+		},
+		"should break down the series by metric name and label value": {
+			labelName:      "status",
+			happyIngesters: numIngesters,
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "test_1", LabelValue: "200", SeriesCount: 1},
+				{MetricName: "test_1", LabelValue: "500", SeriesCount: 1},
+				{MetricName: "test_2", LabelValue: "", SeriesCount: 1},
+				{MetricName: "test_histogram", LabelValue: "200", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+			expectedIngesters: numIngesters,
+		},
+		"should break down only the series matching the matchers": {
+			matchers:       []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "status", "200")},
+			labelName:      "status",
+			happyIngesters: numIngesters,
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "test_1", LabelValue: "200", SeriesCount: 1},
+				{MetricName: "test_histogram", LabelValue: "200", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+			expectedIngesters: numIngesters,
+		},
+		"should break down the series by metric name with zone-aware replication, during single zone failure": {
+			ingesterZones:  []string{"ZONE-A", "ZONE-B", "ZONE-C"},
+			happyIngesters: numIngesters - 1,
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "test_1", SeriesCount: 2},
+				{MetricName: "test_2", SeriesCount: 1},
+				{MetricName: "test_histogram", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+			expectedIngesters: numIngesters - 1,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ds, ingesters, _ := prepare(t, prepConfig{
+				numIngesters:      numIngesters,
+				happyIngesters:    testData.happyIngesters,
+				numDistributors:   1,
+				replicationFactor: replicationFactor,
+				ingesterZones:     testData.ingesterZones,
+			})
+
+			pushFixtures(t, ds[0])
+
+			// Since the Push() response is sent as soon as the quorum is reached, when we reach this point
+			// the final ingester may not have received series yet.
+			// To avoid flaky test we retry the assertions until we hit the desired state within a reasonable timeout.
+			test.Poll(t, time.Second, testData.expectedItems, func() interface{} {
+				resp, err := ds[0].ActiveSeriesBreakdown(ctx, testData.matchers, testData.labelName)
+				require.NoError(t, err)
+				slices.SortFunc(resp.Items, func(a, b *client.ActiveSeriesBreakdownItem) int {
+					if c := strings.Compare(a.MetricName, b.MetricName); c != 0 {
+						return c
+					}
+					return strings.Compare(a.LabelValue, b.LabelValue)
+				})
+				return resp.Items
+			})
+
+			// Make sure enough ingesters were queried
+			assert.GreaterOrEqual(t, countMockIngestersCalls(ingesters, "ActiveSeriesBreakdown"), testData.expectedIngesters)
+		})
+	}
+
+	t.Run("should fail if an ingester fails and there's a single zone", func(t *testing.T) {
+		ds, _, _ := prepare(t, prepConfig{
+			numIngesters:      numIngesters,
+			happyIngesters:    numIngesters - 1,
+			numDistributors:   1,
+			replicationFactor: replicationFactor,
+		})
+
+		_, err := ds[0].ActiveSeriesBreakdown(ctx, nil, "")
+		require.Error(t, err)
+	})
+}
+
 func TestDistributor_LabelValuesCardinalityLimit(t *testing.T) {
 	fixtures := []struct {
 		labels    labels.Labels
@@ -2846,6 +3143,9 @@ func TestRelabelMiddleware(t *testing.T) {
 		reqs           []*mimirpb.WriteRequest
 		expectedReqs   []*mimirpb.WriteRequest
 		expectErrs     []bool
+
+		dryRun                  bool
+		expectedDryRunDiscarded float64
 	}
 	testCases := []testCase{
 		{
@@ -2938,6 +3238,49 @@ func TestRelabelMiddleware(t *testing.T) {
 				)},
 			}},
 			expectErrs: []bool{false},
+		}, {
+			name:   "relabel rules dropping series aren't applied in dry-run mode",
+			ctx:    ctxWithUser,
+			dryRun: true,
+			relabelConfigs: []*relabel.Config{
+				{
+					SourceLabels: []model.LabelName{"label1"},
+					Action:       relabel.Drop,
+					Regex:        relabel.MustNewRegexp("value1.*"),
+				},
+			},
+			reqs:                    []*mimirpb.WriteRequest{makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value1"), nil, nil)},
+			expectedReqs:            []*mimirpb.WriteRequest{makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value1"), nil, nil)},
+			expectErrs:              []bool{false},
+			expectedDryRunDiscarded: 10,
+		}, {
+			name:   "relabel rules rewriting labels are applied in dry-run mode",
+			ctx:    ctxWithUser,
+			dryRun: true,
+			relabelConfigs: []*relabel.Config{
+				{
+					SourceLabels: []model.LabelName{"label1"},
+					Action:       relabel.DefaultRelabelConfig.Action,
+					Regex:        relabel.DefaultRelabelConfig.Regex,
+					TargetLabel:  "target",
+					Replacement:  "prefix_$1",
+				},
+				{
+					SourceLabels: []model.LabelName{"label1"},
+					Action:       relabel.Drop,
+					Regex:        relabel.MustNewRegexp("value2.*"),
+				},
+			},
+			reqs: []*mimirpb.WriteRequest{
+				makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value1"), nil, nil),
+				makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value2"), nil, nil),
+			},
+			expectedReqs: []*mimirpb.WriteRequest{
+				makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value1", "target", "prefix_value1"), nil, nil),
+				makeWriteRequestForGenerators(5, labelSetGenForStringPairs(t, "__name__", "metric1", "label1", "value2"), nil, nil),
+			},
+			expectErrs:              []bool{false, false},
+			expectedDryRunDiscarded: 10,
 		},
 	}
 
@@ -2961,6 +3304,9 @@ func TestRelabelMiddleware(t *testing.T) {
 			flagext.DefaultValues(&limits)
 			limits.MetricRelabelConfigs = tc.relabelConfigs
 			limits.DropLabels = tc.dropLabels
+			if tc.dryRun {
+				limits.LimitsEnforcementMode = validation.EnforcementModeDryRun
+			}
 			ds, _, _ := prepare(t, prepConfig{
 				numDistributors: 1,
 				limits:          &limits,
@@ -2980,6 +3326,8 @@ func TestRelabelMiddleware(t *testing.T) {
 
 			// Cleanup must have been called once per request.
 			assert.Equal(t, len(tc.reqs), cleanupCallCount)
+
+			assert.Equal(t, tc.expectedDryRunDiscarded, testutil.ToFloat64(ds[0].dryRunDiscardedSamplesRelabeled.WithLabelValues("user", "")))
 		})
 	}
 }
@@ -3006,6 +3354,7 @@ func mockWriteRequest(lbls labels.Labels, value float64, timestampMs int64) *mim
 
 type prepConfig struct {
 	numIngesters, happyIngesters       int
+	readOnlyIngesters                  int // Number of ingesters registered in the ring in read-only mode.
 	queryDelay                         time.Duration
 	pushDelay                          time.Duration
 	shuffleShardSize                   int
@@ -3022,6 +3371,7 @@ type prepConfig struct {
 	labelNamesStreamZonesResponseDelay map[string]time.Duration
 	preferStreamingChunks              bool
 	minimizeIngesterRequests           bool
+	writeSpoolDir                      string
 
 	timeOut bool
 }
@@ -3061,7 +3411,7 @@ func prepare(t *testing.T, cfg prepConfig) ([]*Distributor, []mockIngester, []*p
 	for i := range ingesters {
 		addr := fmt.Sprintf("%d", i)
 		tokens := []uint32{uint32((math.MaxUint32 / cfg.numIngesters) * i)}
-		ingesterDescs[addr] = ring.InstanceDesc{
+		desc := ring.InstanceDesc{
 			Addr:                addr,
 			Zone:                ingesters[i].zone,
 			State:               ring.ACTIVE,
@@ -3069,6 +3419,10 @@ func prepare(t *testing.T, cfg prepConfig) ([]*Distributor, []mockIngester, []*p
 			RegisteredTimestamp: time.Now().Add(-2 * time.Hour).Unix(),
 			Tokens:              tokens,
 		}
+		if i < cfg.readOnlyIngesters {
+			desc.ReadOnly = true
+		}
+		ingesterDescs[addr] = desc
 		ingestersByAddr[addr] = &ingesters[i]
 		ingesters[i].tokens = tokens
 	}
@@ -3136,6 +3490,12 @@ func prepare(t *testing.T, cfg prepConfig) ([]*Distributor, []mockIngester, []*p
 		distributorCfg.StreamingChunksPerIngesterSeriesBufferSize = 128
 		distributorCfg.MinimizeIngesterRequests = cfg.minimizeIngesterRequests
 
+		if cfg.writeSpoolDir != "" {
+			distributorCfg.WriteSpool.Enabled = true
+			distributorCfg.WriteSpool.Directory = filepath.Join(cfg.writeSpoolDir, strconv.Itoa(i))
+			distributorCfg.WriteSpool.ReplayInterval = time.Hour
+		}
+
 		cfg.limits.IngestionTenantShardSize = cfg.shuffleShardSize
 
 		if cfg.enableTracker {
@@ -3915,6 +4275,113 @@ func (s *labelNamesAndValuesMockStream) Recv() (*client.LabelNamesAndValuesRespo
 	return result, nil
 }
 
+func (i *mockIngester) ActiveSeries(_ context.Context, req *client.ActiveSeriesRequest, _ ...grpc.CallOption) (client.Ingester_ActiveSeriesClient, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("ActiveSeries")
+
+	if !i.happy {
+		return nil, errFail
+	}
+
+	matchers, err := client.FromLabelMatchers(req.GetMatchers())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &client.ActiveSeriesResponse{}
+	for _, ts := range i.timeseries {
+		if match(ts.Labels, matchers) {
+			resp.Metric = append(resp.Metric, &mimirpb.Metric{Labels: ts.Labels})
+		}
+	}
+	return &activeSeriesMockStream{responses: []*client.ActiveSeriesResponse{resp}}, nil
+}
+
+type activeSeriesMockStream struct {
+	grpc.ClientStream
+	responses []*client.ActiveSeriesResponse
+	i         int
+}
+
+func (*activeSeriesMockStream) CloseSend() error {
+	return nil
+}
+
+func (s *activeSeriesMockStream) Recv() (*client.ActiveSeriesResponse, error) {
+	if s.i >= len(s.responses) {
+		return nil, io.EOF
+	}
+	result := s.responses[s.i]
+	s.i++
+	return result, nil
+}
+
+func (i *mockIngester) ActiveSeriesBreakdown(_ context.Context, req *client.ActiveSeriesBreakdownRequest, _ ...grpc.CallOption) (client.Ingester_ActiveSeriesBreakdownClient, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("ActiveSeriesBreakdown")
+
+	if !i.happy {
+		return nil, errFail
+	}
+
+	matchers, err := client.FromLabelMatchers(req.GetMatchers())
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ metricName, labelValue string }
+	items := map[key]*client.ActiveSeriesBreakdownItem{}
+	for _, ts := range i.timeseries {
+		if !match(ts.Labels, matchers) {
+			continue
+		}
+		lbls := mimirpb.FromLabelAdaptersToLabels(ts.Labels)
+		k := key{metricName: lbls.Get(labels.MetricName)}
+		if req.LabelName != "" {
+			k.labelValue = lbls.Get(req.LabelName)
+		}
+		item, ok := items[k]
+		if !ok {
+			item = &client.ActiveSeriesBreakdownItem{MetricName: k.metricName, LabelValue: k.labelValue}
+			items[k] = item
+		}
+		item.SeriesCount++
+		if len(ts.Histograms) > 0 {
+			h := ts.Histograms[len(ts.Histograms)-1]
+			item.NativeHistogramBuckets += uint64(len(h.PositiveDeltas) + len(h.NegativeDeltas))
+		}
+	}
+
+	resp := &client.ActiveSeriesBreakdownResponse{}
+	for _, item := range items {
+		resp.Items = append(resp.Items, item)
+	}
+	return &activeSeriesBreakdownMockStream{responses: []*client.ActiveSeriesBreakdownResponse{resp}}, nil
+}
+
+type activeSeriesBreakdownMockStream struct {
+	grpc.ClientStream
+	responses []*client.ActiveSeriesBreakdownResponse
+	i         int
+}
+
+func (*activeSeriesBreakdownMockStream) CloseSend() error {
+	return nil
+}
+
+func (s *activeSeriesBreakdownMockStream) Recv() (*client.ActiveSeriesBreakdownResponse, error) {
+	if s.i >= len(s.responses) {
+		return nil, io.EOF
+	}
+	result := s.responses[s.i]
+	s.i++
+	return result, nil
+}
+
 func (i *mockIngester) LabelValuesCardinality(_ context.Context, req *client.LabelValuesCardinalityRequest, _ ...grpc.CallOption) (client.Ingester_LabelValuesCardinalityClient, error) {
 	i.Lock()
 	defer i.Unlock()
@@ -33,6 +33,10 @@ var (
 		validation.IngestionBurstSizeFlag,
 	)
 
+	labelSetIngestionRateLimitedMsgFormat = globalerror.LabelSetIngestionRateLimited.Message(
+		"the request has been rejected because the tenant exceeded the ingestion rate limit of the series matching %s, set to %v samples/s with a maximum allowed burst of %d. This limit is applied on the total number of samples received across all distributors for the series matching the selector",
+	)
+
 	requestRateLimitedMsgFormat = globalerror.RequestRateLimited.MessageWithPerTenantLimitConfig(
 		"the request has been rejected because the tenant exceeded the request rate limit, set to %v requests/s across all distributors with a maximum allowed burst of %d",
 		validation.RequestRateFlag,
@@ -134,6 +138,33 @@ func (e ingestionRateLimitedError) errorCause() mimirpb.ErrorCause {
 // Ensure that ingestionRateLimitedError implements distributorError.
 var _ distributorError = ingestionRateLimitedError{}
 
+// labelSetIngestionRateLimitedError is an error used to represent the per-label-set ingestion rate limited error.
+type labelSetIngestionRateLimitedError struct {
+	selector string
+	limit    float64
+	burst    int
+}
+
+// newLabelSetIngestionRateLimitedError creates a labelSetIngestionRateLimitedError error for the given selector.
+func newLabelSetIngestionRateLimitedError(selector string, limit float64, burst int) labelSetIngestionRateLimitedError {
+	return labelSetIngestionRateLimitedError{
+		selector: selector,
+		limit:    limit,
+		burst:    burst,
+	}
+}
+
+func (e labelSetIngestionRateLimitedError) Error() string {
+	return fmt.Sprintf(labelSetIngestionRateLimitedMsgFormat, e.selector, e.limit, e.burst)
+}
+
+func (e labelSetIngestionRateLimitedError) errorCause() mimirpb.ErrorCause {
+	return mimirpb.INGESTION_RATE_LIMITED
+}
+
+// Ensure that labelSetIngestionRateLimitedError implements distributorError.
+var _ distributorError = labelSetIngestionRateLimitedError{}
+
 // requestRateLimitedError is an error used to represent the request rate limited error.
 type requestRateLimitedError struct {
 	limit float64
@@ -78,6 +78,28 @@ func TestNewValidationError(t *testing.T) {
 	checkDistributorError(t, wrappedErr, mimirpb.BAD_DATA)
 }
 
+func TestNewLabelSetIngestionRateError(t *testing.T) {
+	selector := `{namespace="payments"}`
+	limit := 10.0
+	burst := 10
+	err := newLabelSetIngestionRateLimitedError(selector, limit, burst)
+	expectedErrorMsg := fmt.Sprintf(labelSetIngestionRateLimitedMsgFormat, selector, limit, burst)
+	assert.Error(t, err)
+	assert.EqualError(t, err, expectedErrorMsg)
+	checkDistributorError(t, err, mimirpb.INGESTION_RATE_LIMITED)
+
+	anotherErr := newLabelSetIngestionRateLimitedError(`{namespace="orders"}`, limit, burst)
+	assert.NotErrorIs(t, err, anotherErr)
+
+	assert.True(t, errors.As(err, &labelSetIngestionRateLimitedError{}))
+	assert.False(t, errors.As(err, &ingestionRateLimitedError{}))
+
+	wrappedErr := fmt.Errorf("wrapped %w", err)
+	assert.ErrorIs(t, wrappedErr, err)
+	assert.True(t, errors.As(wrappedErr, &labelSetIngestionRateLimitedError{}))
+	checkDistributorError(t, wrappedErr, mimirpb.INGESTION_RATE_LIMITED)
+}
+
 func TestNewIngestionRateError(t *testing.T) {
 	limit := 10.0
 	burst := 10
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	graphiteParseError = "graphite_parse_error"
+
+	graphitePlaintextContentType = "text/plain"
+	graphitePickleContentType    = "application/python-pickle"
+)
+
+// graphiteSample is a single datapoint received via the Graphite protocols.
+type graphiteSample struct {
+	path        string
+	value       float64
+	timestampMs int64
+}
+
+// GraphiteHandler is a http.Handler which accepts metrics in the Graphite plaintext protocol
+// or, if the request content type is application/python-pickle, in the Graphite pickle protocol.
+// Graphite metric names are converted to series using the tenant's Graphite mapping rules.
+func GraphiteHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	allowSkipLabelNameValidation bool,
+	limits *validation.Overrides,
+	reg prometheus.Registerer,
+	push PushFunc,
+) http.Handler {
+	discardedDueToGraphiteParseError := validation.DiscardedSamplesCounter(reg, graphiteParseError)
+	encodingMetrics := newPushEncodingMetrics(reg, "graphite")
+
+	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		logger := log.WithContext(ctx, log.Logger)
+
+		userID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		switch contentType {
+		case "", graphitePlaintextContentType, graphitePickleContentType:
+		default:
+			return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported content type: %s, supported: [%s, %s]", contentType, graphitePlaintextContentType, graphitePickleContentType)
+		}
+
+		body, err := readPushBody(ctx, r, maxRecvMsgSize, dst, encodingMetrics)
+		if err != nil {
+			return body, err
+		}
+
+		spanLog, ctx := spanlogger.NewWithLogger(ctx, logger, "Distributor.GraphiteHandler.decodeAndConvert")
+		defer spanLog.Span.Finish()
+
+		spanLog.SetTag("content_type", contentType)
+		spanLog.SetTag("content_encoding", r.Header.Get("Content-Encoding"))
+		spanLog.SetTag("content_length", r.ContentLength)
+
+		var (
+			samples  []graphiteSample
+			dropped  int
+			parseErr error
+		)
+		if contentType == graphitePickleContentType {
+			samples, dropped, parseErr = parseGraphitePickleFrames(body, time.Now())
+		} else {
+			samples, dropped, parseErr = parseGraphitePlaintext(body, time.Now())
+		}
+
+		var rules []*validation.GraphiteMappingRule
+		if limits != nil {
+			rules = limits.GraphiteMappingRules(userID)
+		}
+
+		metrics, droppedPaths, mappingErr := graphiteSamplesToTimeseries(samples, rules)
+		if parseErr == nil {
+			parseErr = mappingErr
+		}
+		dropped += droppedPaths
+
+		if dropped > 0 {
+			discardedDueToGraphiteParseError.WithLabelValues(userID, "").Add(float64(dropped)) // Group is empty here as metrics couldn't be parsed
+
+			errMsg := parseErr.Error()
+			if len(errMsg) > maxErrMsgLen {
+				errMsg = errMsg[:maxErrMsgLen]
+			}
+			if len(metrics) == 0 {
+				mimirpb.ReuseSlice(metrics)
+				return body, fmt.Errorf("failed to parse Graphite metrics: %s", errMsg)
+			}
+
+			level.Warn(logger).Log("msg", "Graphite parse error", "dropped", dropped, "err", errMsg)
+		}
+
+		level.Debug(spanLog).Log("msg", "Graphite to Prometheus conversion complete", "sample_count", len(samples), "series_count", len(metrics))
+
+		req.Timeseries = metrics
+		return body, nil
+	})
+}
+
+// parseGraphitePlaintext parses the Graphite plaintext protocol, where each line has the format:
+//
+//	<metric path> <metric value> [<metric timestamp>]
+//
+// Timestamps are in seconds, and missing or negative timestamps are replaced by now.
+// It returns the successfully parsed samples, along with the number of invalid lines and the first error.
+func parseGraphitePlaintext(body []byte, now time.Time) ([]graphiteSample, int, error) {
+	var (
+		samples  []graphiteSample
+		dropped  int
+		firstErr error
+	)
+
+	for lineNo, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		s, err := parseGraphiteLine(string(line), now)
+		if err != nil {
+			dropped++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %w", lineNo+1, err)
+			}
+			continue
+		}
+		samples = append(samples, s)
+	}
+
+	return samples, dropped, firstErr
+}
+
+func parseGraphiteLine(line string, now time.Time) (graphiteSample, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 || len(parts) > 3 {
+		return graphiteSample{}, fmt.Errorf("expected \"<path> <value> [<timestamp>]\", got %q", line)
+	}
+
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return graphiteSample{}, fmt.Errorf("invalid value %q", parts[1])
+	}
+
+	ts := -1.0
+	if len(parts) == 3 {
+		ts, err = strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return graphiteSample{}, fmt.Errorf("invalid timestamp %q", parts[2])
+		}
+	}
+
+	return graphiteSample{path: parts[0], value: value, timestampMs: graphiteTimestampToMillis(ts, now)}, nil
+}
+
+// graphiteTimestampToMillis converts a Graphite timestamp, in seconds, to milliseconds.
+// Negative timestamps are used by Graphite clients to mean "now".
+func graphiteTimestampToMillis(ts float64, now time.Time) int64 {
+	if ts < 0 || math.IsNaN(ts) {
+		return now.UnixMilli()
+	}
+	return int64(ts * 1000)
+}
+
+// graphiteSamplesToTimeseries converts the Graphite samples to series, mapping the Graphite
+// paths to metric names and labels with the first matching rule. It returns the series,
+// along with the number of samples which couldn't be mapped and the first error.
+func graphiteSamplesToTimeseries(samples []graphiteSample, rules []*validation.GraphiteMappingRule) ([]mimirpb.PreallocTimeseries, int, error) {
+	var (
+		builder  = newTimeseriesBuilder()
+		dropped  int
+		firstErr error
+	)
+
+	for _, s := range samples {
+		labels, err := graphitePathToLabels(s.path, rules)
+		if err != nil {
+			dropped++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		builder.add(labels, mimirpb.Sample{TimestampMs: s.timestampMs, Value: s.value})
+	}
+
+	return builder.timeseries, dropped, firstErr
+}
+
+// graphitePathToLabels converts a Graphite path, optionally including tags in the format
+// my.series;tag1=value1;tag2=value2, to labels.
+func graphitePathToLabels(path string, rules []*validation.GraphiteMappingRule) ([]mimirpb.LabelAdapter, error) {
+	name, tags, _ := strings.Cut(path, ";")
+
+	var labels []mimirpb.LabelAdapter
+	if tags != "" {
+		for _, tag := range strings.Split(tags, ";") {
+			k, v, ok := strings.Cut(tag, "=")
+			if !ok || k == "" || v == "" {
+				return nil, fmt.Errorf("invalid tag %q in Graphite metric %q", tag, path)
+			}
+			labels = setLabel(labels, sanitizeName(k, false), v)
+		}
+	}
+
+	for _, rule := range rules {
+		regex, err := rule.Regexp()
+		if err != nil {
+			return nil, err
+		}
+		match := regex.FindStringSubmatchIndex(name)
+		if match == nil {
+			continue
+		}
+
+		metricName := string(regex.ExpandString(nil, rule.Name, name, match))
+		labels = setLabel(labels, model.MetricNameLabel, sanitizeName(metricName, true))
+		for k, tmpl := range rule.Labels {
+			labels = setLabel(labels, k, string(regex.ExpandString(nil, tmpl, name, match)))
+		}
+		return labels, nil
+	}
+
+	return setLabel(labels, model.MetricNameLabel, sanitizeName(name, true)), nil
+}
+
+// setLabel sets the label with the given name, overwriting the existing one if any.
+func setLabel(labels []mimirpb.LabelAdapter, name, value string) []mimirpb.LabelAdapter {
+	for i := range labels {
+		if labels[i].Name == name {
+			labels[i].Value = value
+			return labels
+		}
+	}
+	return append(labels, mimirpb.LabelAdapter{Name: name, Value: value})
+}
@@ -0,0 +1,546 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseGraphitePickleFrames parses a sequence of Graphite pickle protocol frames, as sent by
+// carbon-relay and Graphite clients. Each frame is made of a 4 bytes big-endian payload length,
+// followed by the payload: a pickled list of (path, (timestamp, value)) tuples.
+// It returns the successfully parsed samples, along with the number of invalid frames or
+// datapoints, and the first error.
+func parseGraphitePickleFrames(body []byte, now time.Time) ([]graphiteSample, int, error) {
+	var (
+		samples  []graphiteSample
+		dropped  int
+		firstErr error
+	)
+	setErr := func(err error) {
+		dropped++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for frame := 1; len(body) > 0; frame++ {
+		if len(body) < 4 {
+			setErr(fmt.Errorf("frame %d: truncated frame header", frame))
+			break
+		}
+		size := binary.BigEndian.Uint32(body)
+		if uint64(size) > uint64(len(body)-4) {
+			setErr(fmt.Errorf("frame %d: payload length %d exceeds the remaining %d bytes", frame, size, len(body)-4))
+			break
+		}
+		payload := body[4 : 4+size]
+		body = body[4+size:]
+
+		obj, err := unpickle(payload)
+		if err != nil {
+			setErr(fmt.Errorf("frame %d: %w", frame, err))
+			continue
+		}
+
+		list, ok := obj.(*pickleList)
+		if !ok {
+			setErr(fmt.Errorf("frame %d: expected a list of datapoints, got %T", frame, obj))
+			continue
+		}
+
+		for _, item := range list.items {
+			s, err := graphitePickleDatapoint(item, now)
+			if err != nil {
+				setErr(fmt.Errorf("frame %d: %w", frame, err))
+				continue
+			}
+			samples = append(samples, s)
+		}
+	}
+
+	return samples, dropped, firstErr
+}
+
+// graphitePickleDatapoint converts a (path, (timestamp, value)) tuple to a sample.
+func graphitePickleDatapoint(item interface{}, now time.Time) (graphiteSample, error) {
+	outer, ok := item.(pickleTuple)
+	if !ok || len(outer) != 2 {
+		return graphiteSample{}, fmt.Errorf("expected a (path, (timestamp, value)) datapoint, got %v", item)
+	}
+	path, ok := outer[0].(string)
+	if !ok || path == "" {
+		return graphiteSample{}, fmt.Errorf("invalid datapoint path %v", outer[0])
+	}
+	inner, ok := outer[1].(pickleTuple)
+	if !ok || len(inner) != 2 {
+		return graphiteSample{}, fmt.Errorf("expected a (timestamp, value) tuple for %q, got %v", path, outer[1])
+	}
+	ts, err := pickleNumberToFloat(inner[0])
+	if err != nil {
+		return graphiteSample{}, fmt.Errorf("invalid timestamp for %q: %w", path, err)
+	}
+	value, err := pickleNumberToFloat(inner[1])
+	if err != nil {
+		return graphiteSample{}, fmt.Errorf("invalid value for %q: %w", path, err)
+	}
+
+	return graphiteSample{path: path, value: value, timestampMs: graphiteTimestampToMillis(ts, now)}, nil
+}
+
+func pickleNumberToFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, nil
+	case string:
+		// Some clients send numbers as strings, which carbon accepts too.
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("expected a number, got %v", v)
+	}
+}
+
+// pickleList is a Python list. It's a pointer type because lists are mutable and can be
+// referenced from the memo while being appended to.
+type pickleList struct {
+	items []interface{}
+}
+
+// pickleTuple is a Python tuple.
+type pickleTuple []interface{}
+
+// pickleMark is the marker pushed to the stack by the MARK opcode.
+type pickleMark struct{}
+
+var errPickleStackUnderflow = errors.New("pickle stack underflow")
+
+// unpickle decodes a pickle stream, supporting the opcodes needed to decode plain data
+// (lists, tuples, strings and numbers) up to protocol 5. Opcodes which create or call
+// arbitrary Python objects are not supported, so decoding untrusted input is safe.
+func unpickle(data []byte) (interface{}, error) {
+	u := unpickler{data: data, memo: map[int]interface{}{}}
+	return u.run()
+}
+
+type unpickler struct {
+	data  []byte
+	pos   int
+	stack []interface{}
+	memo  map[int]interface{}
+}
+
+func (u *unpickler) run() (interface{}, error) {
+	for {
+		op, err := u.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case 0x80: // PROTO
+			if _, err = u.read(1); err != nil {
+				return nil, err
+			}
+		case 0x95: // FRAME
+			if _, err = u.read(8); err != nil {
+				return nil, err
+			}
+		case '.': // STOP
+			return u.pop()
+
+		case '(': // MARK
+			u.push(pickleMark{})
+		case ']': // EMPTY_LIST
+			u.push(&pickleList{})
+		case ')': // EMPTY_TUPLE
+			u.push(pickleTuple{})
+		case 'l': // LIST
+			items, err := u.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pickleList{items: items})
+		case 't': // TUPLE
+			items, err := u.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(pickleTuple(items))
+		case 0x85, 0x86, 0x87: // TUPLE1, TUPLE2, TUPLE3
+			n := int(op-0x85) + 1
+			if len(u.stack) < n {
+				return nil, errPickleStackUnderflow
+			}
+			items := make(pickleTuple, n)
+			copy(items, u.stack[len(u.stack)-n:])
+			u.stack = u.stack[:len(u.stack)-n]
+			u.push(items)
+		case 'a': // APPEND
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			list, err := u.topList()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, v)
+		case 'e': // APPENDS
+			items, err := u.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			list, err := u.topList()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, items...)
+
+		case 'N': // NONE
+			u.push(nil)
+		case 0x88: // NEWTRUE
+			u.push(true)
+		case 0x89: // NEWFALSE
+			u.push(false)
+
+		case 'I': // INT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			switch line {
+			case "01":
+				u.push(true)
+			case "00":
+				u.push(false)
+			default:
+				v, err := strconv.ParseInt(line, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid INT opcode argument %q", line)
+				}
+				u.push(v)
+			}
+		case 'L': // LONG
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, ok := new(big.Int).SetString(strings.TrimSuffix(line, "L"), 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid LONG opcode argument %q", line)
+			}
+			u.pushBigInt(v)
+		case 'J': // BININT
+			b, err := u.read(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(int32(binary.LittleEndian.Uint32(b))))
+		case 'K': // BININT1
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(b[0]))
+		case 'M': // BININT2
+			b, err := u.read(2)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(binary.LittleEndian.Uint16(b)))
+		case 0x8a, 0x8b: // LONG1, LONG4
+			var n int
+			if op == 0x8a {
+				b, err := u.read(1)
+				if err != nil {
+					return nil, err
+				}
+				n = int(b[0])
+			} else {
+				if n, err = u.readLength4(); err != nil {
+					return nil, err
+				}
+			}
+			b, err := u.read(n)
+			if err != nil {
+				return nil, err
+			}
+			u.pushBigInt(decodePickleLong(b))
+		case 'F': // FLOAT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseFloat(line, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid FLOAT opcode argument %q", line)
+			}
+			u.push(v)
+		case 'G': // BINFLOAT
+			b, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+
+		case 'S': // STRING
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if len(line) < 2 || (line[0] != '\'' && line[0] != '"') || line[len(line)-1] != line[0] {
+				return nil, fmt.Errorf("invalid STRING opcode argument %q", line)
+			}
+			u.push(line[1 : len(line)-1])
+		case 'V': // UNICODE
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			u.push(line)
+		case 'T', 'X', 'B': // BINSTRING, BINUNICODE, BINBYTES
+			n, err := u.readLength4()
+			if err != nil {
+				return nil, err
+			}
+			if err = u.pushString(n); err != nil {
+				return nil, err
+			}
+		case 'U', 0x8c, 'C': // SHORT_BINSTRING, SHORT_BINUNICODE, SHORT_BINBYTES
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			if err = u.pushString(int(b[0])); err != nil {
+				return nil, err
+			}
+		case 0x8d, 0x8e: // BINUNICODE8, BINBYTES8
+			b, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			n := binary.LittleEndian.Uint64(b)
+			if n > uint64(len(u.data)) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if err = u.pushString(int(n)); err != nil {
+				return nil, err
+			}
+
+		case 'p': // PUT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid PUT opcode argument %q", line)
+			}
+			if err = u.memoize(idx); err != nil {
+				return nil, err
+			}
+		case 'q': // BINPUT
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			if err = u.memoize(int(b[0])); err != nil {
+				return nil, err
+			}
+		case 'r': // LONG_BINPUT
+			idx, err := u.readLength4()
+			if err != nil {
+				return nil, err
+			}
+			if err = u.memoize(idx); err != nil {
+				return nil, err
+			}
+		case 0x94: // MEMOIZE
+			if err = u.memoize(len(u.memo)); err != nil {
+				return nil, err
+			}
+		case 'g': // GET
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GET opcode argument %q", line)
+			}
+			if err = u.pushMemo(idx); err != nil {
+				return nil, err
+			}
+		case 'h': // BINGET
+			b, err := u.read(1)
+			if err != nil {
+				return nil, err
+			}
+			if err = u.pushMemo(int(b[0])); err != nil {
+				return nil, err
+			}
+		case 'j': // LONG_BINGET
+			idx, err := u.readLength4()
+			if err != nil {
+				return nil, err
+			}
+			if err = u.pushMemo(idx); err != nil {
+				return nil, err
+			}
+
+		case '0': // POP
+			if _, err = u.pop(); err != nil {
+				return nil, err
+			}
+		case '1': // POP_MARK
+			if _, err = u.popToMark(); err != nil {
+				return nil, err
+			}
+		case '2': // DUP
+			if len(u.stack) == 0 {
+				return nil, errPickleStackUnderflow
+			}
+			u.push(u.stack[len(u.stack)-1])
+
+		default:
+			return nil, fmt.Errorf("unsupported pickle opcode 0x%02x at offset %d", op, u.pos-1)
+		}
+	}
+}
+
+func (u *unpickler) readByte() (byte, error) {
+	if u.pos >= len(u.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	u.pos++
+	return u.data[u.pos-1], nil
+}
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || n > len(u.data)-u.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	u.pos += n
+	return u.data[u.pos-n : u.pos], nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	idx := bytes.IndexByte(u.data[u.pos:], '\n')
+	if idx < 0 {
+		return "", io.ErrUnexpectedEOF
+	}
+	line := string(u.data[u.pos : u.pos+idx])
+	u.pos += idx + 1
+	return line, nil
+}
+
+func (u *unpickler) readLength4() (int, error) {
+	b, err := u.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (u *unpickler) push(v interface{}) {
+	u.stack = append(u.stack, v)
+}
+
+func (u *unpickler) pushString(n int) error {
+	b, err := u.read(n)
+	if err != nil {
+		return err
+	}
+	u.push(string(b))
+	return nil
+}
+
+// pushBigInt pushes v as an int64 if it fits, otherwise as a *big.Int.
+func (u *unpickler) pushBigInt(v *big.Int) {
+	if v.IsInt64() {
+		u.push(v.Int64())
+		return
+	}
+	u.push(v)
+}
+
+func (u *unpickler) pop() (interface{}, error) {
+	if len(u.stack) == 0 {
+		return nil, errPickleStackUnderflow
+	}
+	v := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	return v, nil
+}
+
+// popToMark pops all the items up to the topmost mark, and the mark itself.
+func (u *unpickler) popToMark() ([]interface{}, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(pickleMark); ok {
+			items := make([]interface{}, len(u.stack)-i-1)
+			copy(items, u.stack[i+1:])
+			u.stack = u.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, errors.New("pickle mark not found")
+}
+
+func (u *unpickler) topList() (*pickleList, error) {
+	if len(u.stack) == 0 {
+		return nil, errPickleStackUnderflow
+	}
+	list, ok := u.stack[len(u.stack)-1].(*pickleList)
+	if !ok {
+		return nil, fmt.Errorf("expected a list to append to, got %T", u.stack[len(u.stack)-1])
+	}
+	return list, nil
+}
+
+func (u *unpickler) memoize(idx int) error {
+	if len(u.stack) == 0 {
+		return errPickleStackUnderflow
+	}
+	u.memo[idx] = u.stack[len(u.stack)-1]
+	return nil
+}
+
+func (u *unpickler) pushMemo(idx int) error {
+	v, ok := u.memo[idx]
+	if !ok {
+		return fmt.Errorf("pickle memo key %d not found", idx)
+	}
+	u.push(v)
+	return nil
+}
+
+// decodePickleLong decodes a little-endian two's complement integer, as encoded by LONG1 and LONG4.
+func decodePickleLong(b []byte) *big.Int {
+	if len(b) == 0 {
+		return new(big.Int)
+	}
+
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return v
+}
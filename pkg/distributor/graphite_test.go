@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// Generated with pickle.dumps([('servers.a.cpu', (1700000000, 1.5)), ('servers.b.cpu', (1700000010.5, 2))], protocol=N).
+var (
+	graphitePickleProtocol0 = []byte("(lp0\n(Vservers.a.cpu\np1\n(I1700000000\nF1.5\ntp2\ntp3\na(Vservers.b.cpu\np4\n(F1700000010.5\nI2\ntp5\ntp6\na.")
+	graphitePickleProtocol2 = []byte("\x80\x02]q\x00(X\r\x00\x00\x00servers.a.cpuq\x01J\x00\xf1SeG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\r\x00\x00\x00servers.b.cpuq\x04GA\xd9T\xfcB\xa0\x00\x00K\x02\x86q\x05\x86q\x06e.")
+)
+
+func TestParseGraphitePlaintext(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+
+	samples, dropped, err := parseGraphitePlaintext([]byte(strings.Join([]string{
+		"servers.a.cpu 1.5 1700000000",
+		"",
+		"servers.a.mem 10 -1",
+		"servers.a.disk 3",
+		"servers.a.net abc 1700000000",
+		"servers.a.load",
+	}, "\n")), now)
+
+	assert.Equal(t, []graphiteSample{
+		{path: "servers.a.cpu", value: 1.5, timestampMs: 1700000000000},
+		{path: "servers.a.mem", value: 10, timestampMs: now.UnixMilli()},
+		{path: "servers.a.disk", value: 3, timestampMs: now.UnixMilli()},
+	}, samples)
+	assert.Equal(t, 2, dropped)
+	assert.EqualError(t, err, `line 5: invalid value "abc"`)
+}
+
+func TestParseGraphitePickleFrames(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+	expected := []graphiteSample{
+		{path: "servers.a.cpu", value: 1.5, timestampMs: 1700000000000},
+		{path: "servers.b.cpu", value: 2, timestampMs: 1700000010500},
+	}
+
+	t.Run("protocol 0", func(t *testing.T) {
+		samples, dropped, err := parseGraphitePickleFrames(graphitePickleFrame(graphitePickleProtocol0), now)
+		require.NoError(t, err)
+		assert.Zero(t, dropped)
+		assert.Equal(t, expected, samples)
+	})
+
+	t.Run("protocol 2", func(t *testing.T) {
+		samples, dropped, err := parseGraphitePickleFrames(graphitePickleFrame(graphitePickleProtocol2), now)
+		require.NoError(t, err)
+		assert.Zero(t, dropped)
+		assert.Equal(t, expected, samples)
+	})
+
+	t.Run("multiple frames with an invalid one", func(t *testing.T) {
+		body := append(graphitePickleFrame(graphitePickleProtocol2), graphitePickleFrame([]byte("cos\nsystem\n."))...)
+		body = append(body, graphitePickleFrame(graphitePickleProtocol0)...)
+
+		samples, dropped, err := parseGraphitePickleFrames(body, now)
+		assert.Equal(t, append(expected, expected...), samples)
+		assert.Equal(t, 1, dropped)
+		assert.EqualError(t, err, "frame 2: unsupported pickle opcode 0x63 at offset 0")
+	})
+
+	t.Run("truncated frame", func(t *testing.T) {
+		body := graphitePickleFrame(graphitePickleProtocol2)
+		samples, dropped, err := parseGraphitePickleFrames(body[:len(body)-1], now)
+		assert.Empty(t, samples)
+		assert.Equal(t, 1, dropped)
+		assert.ErrorContains(t, err, "frame 1: payload length")
+	})
+}
+
+func TestGraphitePathToLabels(t *testing.T) {
+	rules := []*validation.GraphiteMappingRule{
+		{
+			Match:  "servers.*.cpu.*",
+			Name:   "server_cpu_${2}_seconds",
+			Labels: map[string]string{"host": "$1"},
+		},
+		{
+			Match:     `^app\.(\w+)\.requests\.(\d{3})$`,
+			MatchType: validation.GraphiteMatchTypeRegex,
+			Name:      "${1}_requests_total",
+			Labels:    map[string]string{"code": "$2"},
+		},
+	}
+
+	tests := map[string]struct {
+		path        string
+		expected    []mimirpb.LabelAdapter
+		expectedErr string
+	}{
+		"glob rule": {
+			path:     "servers.host-1.cpu.user",
+			expected: []mimirpb.LabelAdapter{{Name: "__name__", Value: "server_cpu_user_seconds"}, {Name: "host", Value: "host-1"}},
+		},
+		"glob wildcards don't match multiple nodes": {
+			path:     "servers.dc1.host-1.cpu.user",
+			expected: []mimirpb.LabelAdapter{{Name: "__name__", Value: "servers_dc1_host_1_cpu_user"}},
+		},
+		"regex rule": {
+			path:     "app.checkout.requests.500",
+			expected: []mimirpb.LabelAdapter{{Name: "__name__", Value: "checkout_requests_total"}, {Name: "code", Value: "500"}},
+		},
+		"no matching rule": {
+			path:     "app.checkout.latency",
+			expected: []mimirpb.LabelAdapter{{Name: "__name__", Value: "app_checkout_latency"}},
+		},
+		"tagged metric": {
+			path:     "app.checkout.latency;env=prod;dc=eu-1",
+			expected: []mimirpb.LabelAdapter{{Name: "env", Value: "prod"}, {Name: "dc", Value: "eu-1"}, {Name: "__name__", Value: "app_checkout_latency"}},
+		},
+		"invalid tag": {
+			path:        "app.checkout.latency;env",
+			expectedErr: `invalid tag "env" in Graphite metric "app.checkout.latency;env"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			labels, err := graphitePathToLabels(tc.path, rules)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, labels)
+		})
+	}
+}
+
+func TestGraphiteHandler(t *testing.T) {
+	const tenantID = "test"
+
+	limits := validation.Limits{}
+	limits.GraphiteMappingRules = []*validation.GraphiteMappingRule{
+		{Match: "servers.*.cpu", Name: "cpu_usage", Labels: map[string]string{"server": "$1"}},
+	}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		contentType       string
+		body              []byte
+		expectedCode      int
+		expectedSeries    []mimirpb.TimeSeries
+		expectedDiscarded string
+	}{
+		"plaintext": {
+			body:         []byte("servers.a.cpu 1 1700000000\nservers.a.cpu 2 1700000010\nservers.a.mem 3 1700000000\ninvalid\n"),
+			expectedCode: http.StatusOK,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "cpu_usage"}, {Name: "server", Value: "a"}},
+					Samples: []mimirpb.Sample{{TimestampMs: 1700000000000, Value: 1}, {TimestampMs: 1700000010000, Value: 2}},
+				},
+				{
+					Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "servers_a_mem"}},
+					Samples: []mimirpb.Sample{{TimestampMs: 1700000000000, Value: 3}},
+				},
+			},
+			expectedDiscarded: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="graphite_parse_error",user="test"} 1
+			`,
+		},
+		"pickle": {
+			contentType:  graphitePickleContentType,
+			body:         graphitePickleFrame(graphitePickleProtocol2),
+			expectedCode: http.StatusOK,
+			expectedSeries: []mimirpb.TimeSeries{
+				{
+					Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "cpu_usage"}, {Name: "server", Value: "a"}},
+					Samples: []mimirpb.Sample{{TimestampMs: 1700000000000, Value: 1.5}},
+				},
+				{
+					Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "cpu_usage"}, {Name: "server", Value: "b"}},
+					Samples: []mimirpb.Sample{{TimestampMs: 1700000010500, Value: 2}},
+				},
+			},
+		},
+		"no valid line": {
+			body:         []byte("invalid\n"),
+			expectedCode: http.StatusBadRequest,
+			expectedDiscarded: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="graphite_parse_error",user="test"} 1
+			`,
+		},
+		"unsupported content type": {
+			contentType:  "application/json",
+			body:         []byte("{}"),
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "http://localhost/api/v1/push/graphite", bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			req = req.WithContext(user.InjectOrgID(req.Context(), tenantID))
+
+			reg := prometheus.NewPedanticRegistry()
+			handler := GraphiteHandler(100000, nil, false, overrides, reg, func(ctx context.Context, pushReq *Request) error {
+				defer pushReq.CleanUp()
+				request, err := pushReq.WriteRequest()
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, tc.expectedSeries, preallocTimeseriesToTimeseries(request.Timeseries))
+				return nil
+			})
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(tc.expectedDiscarded), "cortex_discarded_samples_total"))
+		})
+	}
+}
+
+func graphitePickleFrame(payload []byte) []byte {
+	frame := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	return append(frame, payload...)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/grafana/dskit/kv"
 	"github.com/grafana/dskit/kv/codec"
+	"github.com/grafana/dskit/kv/memberlist"
 	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -32,7 +33,6 @@ import (
 var (
 	errNegativeUpdateTimeoutJitterMax = errors.New("HA tracker max update timeout jitter shouldn't be negative")
 	errInvalidFailoverTimeout         = "HA Tracker failover timeout (%v) must be at least 1s greater than update timeout - max jitter (%v)"
-	errMemberlistUnsupported          = errors.New("memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes")
 )
 
 type haTrackerLimits interface {
@@ -51,6 +51,73 @@ func NewReplicaDesc() *ReplicaDesc {
 	return &ReplicaDesc{}
 }
 
+// Merge implements memberlist.Mergeable. The merged value is the newest one, comparing in order the election
+// timestamp, the received timestamp, the deletion timestamp and finally the replica name. Comparing the election
+// timestamp first guarantees that all distributors converge to the latest elected replica, even if a distributor
+// which hasn't received the latest election yet keeps updating the timestamp of the previously elected replica.
+func (d *ReplicaDesc) Merge(other memberlist.Mergeable, _ bool) (memberlist.Mergeable, error) {
+	if other == nil {
+		return nil, nil
+	}
+
+	otherDesc, ok := other.(*ReplicaDesc)
+	if !ok {
+		return nil, fmt.Errorf("expected *distributor.ReplicaDesc, got %T", other)
+	}
+	if otherDesc == nil || !otherDesc.isNewerThan(d) {
+		return nil, nil
+	}
+
+	*d = *otherDesc
+	return d.Clone(), nil
+}
+
+// isNewerThan returns true if d must replace other when merging the two values.
+func (d *ReplicaDesc) isNewerThan(other *ReplicaDesc) bool {
+	if d.ElectedAt != other.ElectedAt {
+		return d.ElectedAt > other.ElectedAt
+	}
+	if d.ReceivedAt != other.ReceivedAt {
+		return d.ReceivedAt > other.ReceivedAt
+	}
+	if d.DeletedAt != other.DeletedAt {
+		return d.DeletedAt > other.DeletedAt
+	}
+	return d.Replica > other.Replica
+}
+
+// MergeContent implements memberlist.Mergeable.
+func (d *ReplicaDesc) MergeContent() []string {
+	if d.Replica == "" {
+		return nil
+	}
+	return []string{d.Replica}
+}
+
+// RemoveTombstones implements memberlist.Mergeable. A replica marked for deletion before limit, or any replica
+// marked for deletion if limit is zero, is removed by resetting the descriptor. The empty descriptor is never
+// merged over other values and is handled by the HA tracker like a deleted replica.
+func (d *ReplicaDesc) RemoveTombstones(limit time.Time) (total, removed int) {
+	if d.DeletedAt == 0 {
+		return 0, 0
+	}
+	if limit.IsZero() || timestamp.Time(d.DeletedAt).Before(limit) {
+		*d = ReplicaDesc{}
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// isDeleted returns true if the replica has been marked for deletion, or its deletion mark has been removed.
+func (d *ReplicaDesc) isDeleted() bool {
+	return d.DeletedAt > 0 || d.Replica == ""
+}
+
+// Clone implements memberlist.Mergeable.
+func (d *ReplicaDesc) Clone() memberlist.Mergeable {
+	return proto.Clone(d).(*ReplicaDesc)
+}
+
 // HATrackerConfig contains the configuration require to
 // create a HA Tracker.
 type HATrackerConfig struct {
@@ -66,7 +133,7 @@ type HATrackerConfig struct {
 	// more than this duration
 	FailoverTimeout time.Duration `yaml:"ha_tracker_failover_timeout" category:"advanced"`
 
-	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the ring. Please be aware that memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes."`
+	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the ring. When using memberlist, the elected replica is eventually consistent across distributors, and a distributor only fails over to another replica if it hasn't received samples from the elected replica for the failover timeout."`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -94,10 +161,6 @@ func (cfg *HATrackerConfig) Validate() error {
 		return fmt.Errorf(errInvalidFailoverTimeout, cfg.FailoverTimeout, minFailureTimeout)
 	}
 
-	if cfg.KVStore.Store == "memberlist" {
-		return errMemberlistUnsupported
-	}
-
 	return nil
 }
 
@@ -237,7 +300,7 @@ func (h *haTracker) loop(ctx context.Context) error {
 		user := segments[0]
 		cluster := segments[1]
 
-		if replica.DeletedAt > 0 {
+		if replica.isDeleted() {
 			h.electedReplicaChanges.DeleteLabelValues(user, cluster)
 			h.electedReplicaTimestamp.DeleteLabelValues(user, cluster)
 
@@ -353,11 +416,17 @@ func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 			continue
 		}
 
-		if desc.DeletedAt > 0 {
+		if desc.isDeleted() {
 			if timestamp.Time(desc.DeletedAt).After(deadline) {
 				continue
 			}
 
+			// Memberlist doesn't support deleting keys: the deletion mark is removed by memberlist itself once
+			// older than the left ingesters timeout, leaving an empty value under the key.
+			if h.cfg.KVStore.Store == "memberlist" {
+				continue
+			}
+
 			// We're blindly deleting a key here. It may happen that value was updated since we have read it few lines above,
 			// in which case Distributors will have updated value in memory, but Delete will remove it from KV store anyway.
 			// That's not great, but should not be a problem. If KV store sends Watch notification for Delete, distributors will
@@ -381,7 +450,7 @@ func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 		if desc.DeletedAt == 0 && timestamp.Time(desc.ReceivedAt).Before(deadline) {
 			err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
 				d, ok := in.(*ReplicaDesc)
-				if !ok || d == nil || d.DeletedAt > 0 || !timestamp.Time(desc.ReceivedAt).Before(deadline) {
+				if !ok || d == nil || d.isDeleted() || !timestamp.Time(desc.ReceivedAt).Before(deadline) {
 					return nil, false, nil
 				}
 
@@ -473,14 +542,24 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 	key := fmt.Sprintf("%s/%s", userID, cluster)
 	var desc *ReplicaDesc
 	err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+		electedAt := timestamp.FromTime(now)
+
 		var ok bool
-		if desc, ok = in.(*ReplicaDesc); ok && desc.DeletedAt == 0 {
+		if desc, ok = in.(*ReplicaDesc); ok && !desc.isDeleted() {
 			// If the entry in KVStore is up-to-date, just stop the loop.
 			if h.withinUpdateTimeout(now, desc.ReceivedAt) ||
 				// If our replica is different, wait until the failover time.
-				desc.Replica != replica && now.Sub(timestamp.Time(desc.ReceivedAt)) < h.cfg.FailoverTimeout {
+				desc.Replica != replica && now.Sub(timestamp.Time(desc.ReceivedAt)) < h.cfg.FailoverTimeout ||
+				// If our replica is different, but we've recently received samples from the elected replica,
+				// the entry in KVStore is stale and we must not fail over.
+				desc.Replica != replica && h.electedSeenRecently(userID, cluster, desc.Replica, now) {
 				return nil, false, nil
 			}
+
+			// Keep the election timestamp if the elected replica doesn't change.
+			if desc.Replica == replica && desc.ElectedAt > 0 {
+				electedAt = desc.ElectedAt
+			}
 		}
 
 		// Attempt to update KVStore to our timestamp and replica.
@@ -488,6 +567,7 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 			Replica:    replica,
 			ReceivedAt: timestamp.FromTime(now),
 			DeletedAt:  0,
+			ElectedAt:  electedAt,
 		}
 		return desc, true, nil
 	})
@@ -503,6 +583,17 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 	return err
 }
 
+// electedSeenRecently returns true if this distributor has received samples from the input elected replica
+// within the failover timeout. Since this is a local information, it protects from failing over when the
+// KV store is eventually consistent and the updates from other distributors haven't been received yet.
+func (h *haTracker) electedSeenRecently(userID, cluster, elected string, now time.Time) bool {
+	h.electedLock.RLock()
+	defer h.electedLock.RUnlock()
+
+	entry := h.clusters[userID][cluster]
+	return entry != nil && entry.elected.Replica == elected && now.Sub(timestamp.Time(entry.electedLastSeenTimestamp)) < h.cfg.FailoverTimeout
+}
+
 func findHALabels(replicaLabel, clusterLabel string, labels []mimirpb.LabelAdapter) (string, string) {
 	var cluster, replica string
 	var pair mimirpb.LabelAdapter
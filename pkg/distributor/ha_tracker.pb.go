@@ -34,6 +34,10 @@ type ReplicaDesc struct {
 	// already remove entry from memory. Actual deletion from KV store does *not* trigger
 	// "watch" notification with a key for all KV stores.
 	DeletedAt int64 `protobuf:"varint,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// Unix timestamp in milliseconds when the replica has been elected. It's preserved while the
+	// elected replica doesn't change, and it's used to converge to the latest election when the
+	// KV store is eventually consistent, like memberlist.
+	ElectedAt int64 `protobuf:"varint,4,opt,name=elected_at,json=electedAt,proto3" json:"elected_at,omitempty"`
 }
 
 func (m *ReplicaDesc) Reset()      { *m = ReplicaDesc{} }
@@ -89,6 +93,13 @@ func (m *ReplicaDesc) GetDeletedAt() int64 {
 	return 0
 }
 
+func (m *ReplicaDesc) GetElectedAt() int64 {
+	if m != nil {
+		return m.ElectedAt
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ReplicaDesc)(nil), "distributor.ReplicaDesc")
 }
@@ -96,21 +107,22 @@ func init() {
 func init() { proto.RegisterFile("ha_tracker.proto", fileDescriptor_86f0e7bcf71d860b) }
 
 var fileDescriptor_86f0e7bcf71d860b = []byte{
-	// 224 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0xc8, 0x48, 0x8c, 0x2f,
-	0x29, 0x4a, 0x4c, 0xce, 0x4e, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x4e, 0xc9,
-	0x2c, 0x2e, 0x29, 0xca, 0x4c, 0x2a, 0x2d, 0xc9, 0x2f, 0x92, 0xd2, 0x4d, 0xcf, 0x2c, 0xc9, 0x28,
-	0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0x4f, 0xcf, 0x4f, 0xcf, 0xd7, 0x07, 0xab, 0x49, 0x2a, 0x4d,
-	0x03, 0xf3, 0xc0, 0x1c, 0x30, 0x0b, 0xa2, 0x57, 0x29, 0x9d, 0x8b, 0x3b, 0x28, 0xb5, 0x20, 0x27,
-	0x33, 0x39, 0xd1, 0x25, 0xb5, 0x38, 0x59, 0x48, 0x82, 0x8b, 0xbd, 0x08, 0xc2, 0x95, 0x60, 0x54,
-	0x60, 0xd4, 0xe0, 0x0c, 0x82, 0x71, 0x85, 0xe4, 0xb9, 0xb8, 0x8b, 0x52, 0x93, 0x53, 0x33, 0xcb,
-	0x52, 0x53, 0xe2, 0x13, 0x4b, 0x24, 0x98, 0x14, 0x18, 0x35, 0x98, 0x83, 0xb8, 0x60, 0x42, 0x8e,
-	0x25, 0x42, 0xb2, 0x5c, 0x5c, 0x29, 0xa9, 0x39, 0xa9, 0x25, 0x10, 0x79, 0x66, 0xb0, 0x3c, 0x27,
-	0x54, 0xc4, 0xb1, 0xc4, 0xc9, 0xe4, 0xc2, 0x43, 0x39, 0x86, 0x1b, 0x0f, 0xe5, 0x18, 0x3e, 0x3c,
-	0x94, 0x63, 0x6c, 0x78, 0x24, 0xc7, 0xb8, 0xe2, 0x91, 0x1c, 0xe3, 0x89, 0x47, 0x72, 0x8c, 0x17,
-	0x1e, 0xc9, 0x31, 0x3e, 0x78, 0x24, 0xc7, 0xf8, 0xe2, 0x91, 0x1c, 0xc3, 0x87, 0x47, 0x72, 0x8c,
-	0x13, 0x1e, 0xcb, 0x31, 0x5c, 0x78, 0x2c, 0xc7, 0x70, 0xe3, 0xb1, 0x1c, 0x43, 0x12, 0x1b, 0xd8,
-	0x95, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0xb3, 0xd1, 0xdd, 0x8d, 0xf5, 0x00, 0x00, 0x00,
+	// 229 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x34, 0x8f, 0xb1, 0x4e, 0xc3, 0x30,
+	0x10, 0x40, 0x7d, 0x14, 0x81, 0xea, 0x2c, 0x28, 0x53, 0x84, 0xc4, 0x51, 0x31, 0x75, 0xa1, 0x1d,
+	0xe0, 0x07, 0x8a, 0xf8, 0x82, 0xfc, 0x40, 0x65, 0x3b, 0x47, 0x6a, 0x11, 0xe4, 0xca, 0xbd, 0x30,
+	0x33, 0x31, 0xf3, 0x19, 0x7c, 0x0a, 0x63, 0xc6, 0x8e, 0xc4, 0x59, 0x18, 0xfb, 0x09, 0x48, 0x76,
+	0xb2, 0xdd, 0x7b, 0xef, 0x6e, 0x38, 0x79, 0xb5, 0x53, 0x5b, 0xf6, 0xca, 0xbc, 0x92, 0x5f, 0xed,
+	0xbd, 0x63, 0x97, 0x67, 0x95, 0x3d, 0xb0, 0xb7, 0xba, 0x65, 0xe7, 0xaf, 0xef, 0x6b, 0xcb, 0xbb,
+	0x56, 0xaf, 0x8c, 0x7b, 0x5b, 0xd7, 0xae, 0x76, 0xeb, 0xb8, 0xa3, 0xdb, 0x97, 0x48, 0x11, 0xe2,
+	0x94, 0x6e, 0xef, 0x3e, 0x41, 0x66, 0x25, 0xed, 0x1b, 0x6b, 0xd4, 0x33, 0x1d, 0x4c, 0x5e, 0xc8,
+	0x4b, 0x9f, 0xb0, 0x80, 0x05, 0x2c, 0xe7, 0xe5, 0x84, 0xf9, 0xad, 0xcc, 0x3c, 0x19, 0xb2, 0xef,
+	0x54, 0x6d, 0x15, 0x17, 0x67, 0x0b, 0x58, 0xce, 0x4a, 0x39, 0xa9, 0x0d, 0xe7, 0x37, 0x52, 0x56,
+	0xd4, 0x10, 0xa7, 0x3e, 0x8b, 0x7d, 0x3e, 0x9a, 0x94, 0xa9, 0x21, 0x33, 0xe6, 0xf3, 0x94, 0x47,
+	0xb3, 0xe1, 0xa7, 0xc7, 0xae, 0x47, 0x71, 0xec, 0x51, 0x9c, 0x7a, 0x84, 0x8f, 0x80, 0xf0, 0x1d,
+	0x10, 0x7e, 0x02, 0x42, 0x17, 0x10, 0x7e, 0x03, 0xc2, 0x5f, 0x40, 0x71, 0x0a, 0x08, 0x5f, 0x03,
+	0x8a, 0x6e, 0x40, 0x71, 0x1c, 0x50, 0xe8, 0x8b, 0xf8, 0xc5, 0xc3, 0xff, 0x00, 0x4f, 0x78, 0x36,
+	0x17, 0x15, 0x01, 0x00, 0x00,
 }
 
 func (this *ReplicaDesc) Equal(that interface{}) bool {
@@ -141,17 +153,21 @@ func (this *ReplicaDesc) Equal(that interface{}) bool {
 	if this.DeletedAt != that1.DeletedAt {
 		return false
 	}
+	if this.ElectedAt != that1.ElectedAt {
+		return false
+	}
 	return true
 }
 func (this *ReplicaDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&distributor.ReplicaDesc{")
 	s = append(s, "Replica: "+fmt.Sprintf("%#v", this.Replica)+",\n")
 	s = append(s, "ReceivedAt: "+fmt.Sprintf("%#v", this.ReceivedAt)+",\n")
 	s = append(s, "DeletedAt: "+fmt.Sprintf("%#v", this.DeletedAt)+",\n")
+	s = append(s, "ElectedAt: "+fmt.Sprintf("%#v", this.ElectedAt)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -183,6 +199,11 @@ func (m *ReplicaDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ElectedAt != 0 {
+		i = encodeVarintHaTracker(dAtA, i, uint64(m.ElectedAt))
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.DeletedAt != 0 {
 		i = encodeVarintHaTracker(dAtA, i, uint64(m.DeletedAt))
 		i--
@@ -230,6 +251,9 @@ func (m *ReplicaDesc) Size() (n int) {
 	if m.DeletedAt != 0 {
 		n += 1 + sovHaTracker(uint64(m.DeletedAt))
 	}
+	if m.ElectedAt != 0 {
+		n += 1 + sovHaTracker(uint64(m.ElectedAt))
+	}
 	return n
 }
 
@@ -247,6 +271,7 @@ func (this *ReplicaDesc) String() string {
 		`Replica:` + fmt.Sprintf("%v", this.Replica) + `,`,
 		`ReceivedAt:` + fmt.Sprintf("%v", this.ReceivedAt) + `,`,
 		`DeletedAt:` + fmt.Sprintf("%v", this.DeletedAt) + `,`,
+		`ElectedAt:` + fmt.Sprintf("%v", this.ElectedAt) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -358,6 +383,25 @@ func (m *ReplicaDesc) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ElectedAt", wireType)
+			}
+			m.ElectedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHaTracker
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ElectedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHaTracker(dAtA[iNdEx:])
@@ -7,12 +7,15 @@ package distributor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/kv"
 	"github.com/grafana/dskit/kv/consul"
@@ -115,7 +118,7 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 			}(),
 			expectedErr: nil,
 		},
-		"should fail if KV backend is set to memberlist": {
+		"should pass if KV backend is set to memberlist": {
 			cfg: func() HATrackerConfig {
 				cfg := HATrackerConfig{}
 				flagext.DefaultValues(&cfg)
@@ -123,7 +126,7 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 
 				return cfg
 			}(),
-			expectedErr: errMemberlistUnsupported,
+			expectedErr: nil,
 		},
 	}
 
@@ -792,6 +795,57 @@ func TestCheckReplicaCleanup(t *testing.T) {
 	))
 }
 
+func TestCheckReplicaCleanup_RemovedTombstones(t *testing.T) {
+	replica := "r1"
+	cluster := "c1"
+	userID := "user"
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	reg := prometheus.NewPedanticRegistry()
+
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	mock := kv.PrefixClient(kvStore, "prefix")
+	c, err := newHATracker(HATrackerConfig{
+		EnableHATracker:        true,
+		KVStore:                kv.Config{Store: "memberlist", Mock: mock},
+		UpdateTimeout:          1 * time.Second,
+		UpdateTimeoutJitterMax: 0,
+		FailoverTimeout:        time.Second,
+	}, trackerLimits{maxClusters: 100}, reg, util_log.Logger)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	now := time.Now()
+	require.NoError(t, c.checkReplica(context.Background(), userID, cluster, replica, now))
+	checkUserClusters(t, time.Second, c, userID, 1)
+
+	// Mark the replica for deletion.
+	c.cleanupOldReplicas(ctx, now.Add(1*time.Second))
+	checkUserClusters(t, time.Second, c, userID, 0)
+
+	// Simulate memberlist removing the tombstone, which leaves an empty value under the key.
+	require.NoError(t, mock.CAS(ctx, fmt.Sprintf("%s/%s", userID, cluster), func(in interface{}) (interface{}, bool, error) {
+		desc := in.(*ReplicaDesc)
+		_, removed := desc.RemoveTombstones(time.Now().Add(time.Second))
+		require.Equal(t, 1, removed)
+		return desc, true, nil
+	}))
+	checkUserClusters(t, time.Second, c, userID, 0)
+
+	// The empty value isn't marked for deletion again.
+	c.cleanupOldReplicas(ctx, time.Now().Add(5*time.Second))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.replicasMarkedForDeletion))
+
+	// A replica is elected again once the cluster sends samples.
+	now = time.Now()
+	require.NoError(t, c.checkReplica(context.Background(), userID, cluster, replica, now))
+	checkReplicaTimestamp(t, time.Second, c, userID, cluster, replica, now)
+	checkUserClusters(t, time.Second, c, userID, 1)
+}
+
 func checkUserClusters(t *testing.T, duration time.Duration, c *haTracker, user string, expectedClusters int) {
 	t.Helper()
 	test.Poll(t, duration, nil, func() interface{} {
@@ -870,3 +924,366 @@ func getSumOfHistogramSampleCount(families []*dto.MetricFamily, metricName strin
 
 	return sum
 }
+
+func TestReplicaDesc_Merge(t *testing.T) {
+	tests := map[string]struct {
+		local, incoming *ReplicaDesc
+		expected        *ReplicaDesc
+		expectedChange  bool
+	}{
+		"should keep local value if incoming value is the same": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			expected:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			expectedChange: false,
+		},
+		"should update the received timestamp of the same elected replica": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			expected:       &ReplicaDesc{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			expectedChange: true,
+		},
+		"should ignore an older received timestamp of the same elected replica": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			expected:       &ReplicaDesc{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			expectedChange: false,
+		},
+		"should converge to the latest elected replica": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 15, ElectedAt: 15},
+			expected:       &ReplicaDesc{Replica: "r2", ReceivedAt: 15, ElectedAt: 15},
+			expectedChange: true,
+		},
+		"should not revert to a previously elected replica with a more recent received timestamp": {
+			local:          &ReplicaDesc{Replica: "r2", ReceivedAt: 15, ElectedAt: 15},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 30, ElectedAt: 5},
+			expected:       &ReplicaDesc{Replica: "r2", ReceivedAt: 15, ElectedAt: 15},
+			expectedChange: false,
+		},
+		"should mark the replica for deletion": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: 50},
+			expected:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: 50},
+			expectedChange: true,
+		},
+		"should elect a replica again after it has been marked for deletion": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: 50},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 60, ElectedAt: 60},
+			expected:       &ReplicaDesc{Replica: "r2", ReceivedAt: 60, ElectedAt: 60},
+			expectedChange: true,
+		},
+		"should deterministically pick a replica if elected at the same time": {
+			local:          &ReplicaDesc{Replica: "r2", ReceivedAt: 10, ElectedAt: 10},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 10},
+			expected:       &ReplicaDesc{Replica: "r2", ReceivedAt: 10, ElectedAt: 10},
+			expectedChange: false,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			local := proto.Clone(testData.local).(*ReplicaDesc)
+			change, err := local.Merge(proto.Clone(testData.incoming).(*ReplicaDesc), false)
+			require.NoError(t, err)
+
+			assert.Equal(t, testData.expected, local)
+			if testData.expectedChange {
+				assert.Equal(t, testData.expected, change)
+			} else {
+				assert.Nil(t, change)
+			}
+		})
+	}
+
+	t.Run("should be commutative, associative and idempotent", func(t *testing.T) {
+		descs := []*ReplicaDesc{
+			{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			{Replica: "r1", ReceivedAt: 20, ElectedAt: 5},
+			{Replica: "r1", ReceivedAt: 20, ElectedAt: 5, DeletedAt: 30},
+			{Replica: "r2", ReceivedAt: 15, ElectedAt: 15},
+			{Replica: "r3", ReceivedAt: 15, ElectedAt: 15},
+			{Replica: "r1", ReceivedAt: 40, ElectedAt: 40},
+		}
+		merge := func(values ...*ReplicaDesc) *ReplicaDesc {
+			result := proto.Clone(values[0]).(*ReplicaDesc)
+			for _, v := range values[1:] {
+				_, err := result.Merge(proto.Clone(v).(*ReplicaDesc), false)
+				require.NoError(t, err)
+			}
+			return result
+		}
+
+		for _, a := range descs {
+			assert.Equal(t, a, merge(a, a))
+			for _, b := range descs {
+				assert.Equal(t, merge(a, b), merge(b, a))
+				for _, c := range descs {
+					assert.Equal(t, merge(merge(a, b), c), merge(a, merge(b, c)))
+				}
+			}
+		}
+	})
+
+	t.Run("should fail merging a different type", func(t *testing.T) {
+		_, err := (&ReplicaDesc{}).Merge(&ring.Desc{}, false)
+		require.Error(t, err)
+	})
+}
+
+func TestReplicaDesc_RemoveTombstones(t *testing.T) {
+	now := time.Now()
+	deletedAt := timestamp.FromTime(now.Add(-time.Hour))
+
+	tests := map[string]struct {
+		desc            *ReplicaDesc
+		limit           time.Time
+		expected        *ReplicaDesc
+		expectedTotal   int
+		expectedRemoved int
+	}{
+		"should keep a replica not marked for deletion": {
+			desc:     &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+			limit:    now,
+			expected: &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5},
+		},
+		"should keep a replica marked for deletion after the limit": {
+			desc:          &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: deletedAt},
+			limit:         now.Add(-2 * time.Hour),
+			expected:      &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: deletedAt},
+			expectedTotal: 1,
+		},
+		"should remove a replica marked for deletion before the limit": {
+			desc:            &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: deletedAt},
+			limit:           now,
+			expected:        &ReplicaDesc{},
+			expectedRemoved: 1,
+		},
+		"should remove a replica marked for deletion if the limit is zero": {
+			desc:            &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5, DeletedAt: deletedAt},
+			expected:        &ReplicaDesc{},
+			expectedRemoved: 1,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			total, removed := testData.desc.RemoveTombstones(testData.limit)
+			assert.Equal(t, testData.expectedTotal, total)
+			assert.Equal(t, testData.expectedRemoved, removed)
+			assert.Equal(t, testData.expected, testData.desc)
+			assert.Equal(t, testData.expected.Replica == "" || testData.expected.DeletedAt > 0, testData.desc.isDeleted())
+		})
+	}
+
+	t.Run("should not merge a removed replica over other values", func(t *testing.T) {
+		removed := &ReplicaDesc{}
+		local := &ReplicaDesc{Replica: "r1", ReceivedAt: 10, ElectedAt: 5}
+		change, err := local.Merge(removed, false)
+		require.NoError(t, err)
+		assert.Nil(t, change)
+		assert.Empty(t, removed.MergeContent())
+	})
+}
+
+func TestHATracker_EventuallyConsistentKVStore(t *testing.T) {
+	const (
+		userID  = "user"
+		cluster = "cluster"
+	)
+
+	cfg := HATrackerConfig{
+		EnableHATracker:        true,
+		UpdateTimeout:          10 * time.Second,
+		UpdateTimeoutJitterMax: 0,
+		FailoverTimeout:        30 * time.Second,
+	}
+
+	// Each tracker has its own view of the KV store, and updates are propagated only when gossiping.
+	kv1, kv2 := newGossipKVClient(), newGossipKVClient()
+	gossip := func() {
+		kv1.gossipTo(kv2)
+		kv2.gossipTo(kv1)
+	}
+
+	newTracker := func(client *gossipKVClient) *haTracker {
+		trackerCfg := cfg
+		trackerCfg.KVStore = kv.Config{Mock: client}
+		tr, err := newHATracker(trackerCfg, trackerLimits{maxClusters: 100}, nil, log.NewNopLogger())
+		require.NoError(t, err)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), tr))
+		t.Cleanup(func() {
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tr))
+		})
+		client.waitWatchers(t, 1)
+		return tr
+	}
+	t1, t2 := newTracker(kv1), newTracker(kv2)
+
+	now := time.Now()
+
+	// The two trackers elect a different replica, because the updates haven't been propagated yet.
+	require.NoError(t, t1.checkReplica(context.Background(), userID, cluster, "r1", now))
+	require.NoError(t, t2.checkReplica(context.Background(), userID, cluster, "r2", now.Add(10*time.Millisecond)))
+
+	// The first tracker keeps updating the timestamp of its elected replica.
+	require.NoError(t, t1.checkReplica(context.Background(), userID, cluster, "r1", now.Add(11*time.Second)))
+	t1.updateKVStoreAll(context.Background(), now.Add(11*time.Second))
+	checkReplicaTimestamp(t, time.Second, t1, userID, cluster, "r1", now.Add(11*time.Second))
+
+	// Once the updates are propagated, both trackers converge to the latest election, even if the previously
+	// elected replica has a more recent timestamp.
+	gossip()
+	checkReplicaTimestamp(t, time.Second, t1, userID, cluster, "r2", now.Add(10*time.Millisecond))
+	checkReplicaTimestamp(t, time.Second, t2, userID, cluster, "r2", now.Add(10*time.Millisecond))
+
+	for _, tr := range []*haTracker{t1, t2} {
+		require.NoError(t, tr.checkReplica(context.Background(), userID, cluster, "r2", now.Add(20*time.Second)))
+		require.Error(t, tr.checkReplica(context.Background(), userID, cluster, "r1", now.Add(20*time.Second)))
+	}
+
+	// The first tracker updates the timestamp of the elected replica, but the update is delayed and the second
+	// tracker sees a timestamp older than the failover timeout. The second tracker doesn't fail over, because it
+	// has recently received samples from the elected replica.
+	t1.updateKVStoreAll(context.Background(), now.Add(20*time.Second))
+	checkReplicaTimestamp(t, time.Second, t1, userID, cluster, "r2", now.Add(20*time.Second))
+
+	require.Error(t, t2.checkReplica(context.Background(), userID, cluster, "r1", now.Add(40*time.Second)))
+	require.NoError(t, t2.updateKVStore(context.Background(), userID, cluster, "r1", now.Add(40*time.Second)))
+	checkReplicaTimestamp(t, time.Second, t2, userID, cluster, "r2", now.Add(10*time.Millisecond))
+
+	// The second tracker fails over once it hasn't received samples from the elected replica for the failover timeout.
+	require.Error(t, t2.checkReplica(context.Background(), userID, cluster, "r1", now.Add(51*time.Second)))
+	t2.updateKVStoreAll(context.Background(), now.Add(51*time.Second))
+	checkReplicaTimestamp(t, time.Second, t2, userID, cluster, "r1", now.Add(51*time.Second))
+
+	// Once the updates are propagated, the first tracker converges to the new election too.
+	gossip()
+	checkReplicaTimestamp(t, time.Second, t1, userID, cluster, "r1", now.Add(51*time.Second))
+	require.NoError(t, t1.checkReplica(context.Background(), userID, cluster, "r1", now.Add(52*time.Second)))
+	require.Error(t, t1.checkReplica(context.Background(), userID, cluster, "r2", now.Add(52*time.Second)))
+}
+
+// gossipKVClient is a kv.Client simulating an eventually consistent KV store like memberlist: each client has
+// its own copy of the values, CAS merges the updated value into the local copy, and the updates are propagated
+// to another client only when gossipTo() is called.
+type gossipKVClient struct {
+	mtx      sync.Mutex
+	values   map[string]*ReplicaDesc
+	watchers []chan string
+}
+
+func newGossipKVClient() *gossipKVClient {
+	return &gossipKVClient{values: map[string]*ReplicaDesc{}}
+}
+
+func (c *gossipKVClient) List(_ context.Context, prefix string) ([]string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var keys []string
+	for key := range c.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *gossipKVClient) Get(_ context.Context, key string) (interface{}, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if desc, ok := c.values[key]; ok {
+		return desc.Clone(), nil
+	}
+	return nil, nil
+}
+
+func (c *gossipKVClient) Delete(context.Context, string) error {
+	return errors.New("delete is not supported")
+}
+
+func (c *gossipKVClient) CAS(ctx context.Context, key string, f func(in interface{}) (out interface{}, retry bool, err error)) error {
+	in, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	out, _, err := f(in)
+	if err != nil || out == nil {
+		return err
+	}
+	c.merge(key, out.(*ReplicaDesc))
+	return nil
+}
+
+func (c *gossipKVClient) WatchKey(ctx context.Context, key string, f func(interface{}) bool) {
+	c.WatchPrefix(ctx, key, func(k string, value interface{}) bool {
+		if k != key {
+			return true
+		}
+		return f(value)
+	})
+}
+
+func (c *gossipKVClient) WatchPrefix(ctx context.Context, prefix string, f func(string, interface{}) bool) {
+	ch := make(chan string, 1000)
+	c.mtx.Lock()
+	c.watchers = append(c.watchers, ch)
+	c.mtx.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-ch:
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			value, _ := c.Get(ctx, key)
+			if value != nil && !f(key, value) {
+				return
+			}
+		}
+	}
+}
+
+func (c *gossipKVClient) merge(key string, desc *ReplicaDesc) {
+	c.mtx.Lock()
+	changed := true
+	if curr, ok := c.values[key]; ok {
+		change, _ := curr.Merge(desc, false)
+		changed = change != nil
+	} else {
+		c.values[key] = desc
+	}
+	watchers := c.watchers
+	c.mtx.Unlock()
+
+	if changed {
+		for _, ch := range watchers {
+			ch <- key
+		}
+	}
+}
+
+func (c *gossipKVClient) gossipTo(other *gossipKVClient) {
+	c.mtx.Lock()
+	values := make(map[string]*ReplicaDesc, len(c.values))
+	for key, desc := range c.values {
+		values[key] = desc.Clone().(*ReplicaDesc)
+	}
+	c.mtx.Unlock()
+
+	for key, desc := range values {
+		other.merge(key, desc)
+	}
+}
+
+func (c *gossipKVClient) waitWatchers(t *testing.T, expected int) {
+	test.Poll(t, time.Second, expected, func() interface{} {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		return len(c.watchers)
+	})
+}
@@ -0,0 +1,402 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	influxParseError = "influx_parse_error"
+
+	// influxDefaultFieldKey is the field key which, when used, doesn't get appended to the metric name.
+	influxDefaultFieldKey = "value"
+)
+
+// InfluxHandler is a http.Handler which accepts InfluxDB line protocol writes, as sent
+// to the InfluxDB v1 /write and v2 /api/v2/write endpoints.
+func InfluxHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	allowSkipLabelNameValidation bool,
+	limits *validation.Overrides,
+	reg prometheus.Registerer,
+	push PushFunc,
+) http.Handler {
+	discardedDueToInfluxParseError := validation.DiscardedSamplesCounter(reg, influxParseError)
+	encodingMetrics := newPushEncodingMetrics(reg, "influx")
+
+	h := handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		logger := log.WithContext(ctx, log.Logger)
+
+		precision := r.URL.Query().Get("precision")
+		toMillis, err := influxPrecisionToMillis(precision)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+
+		body, err := readPushBody(ctx, r, maxRecvMsgSize, dst, encodingMetrics)
+		if err != nil {
+			return body, err
+		}
+
+		spanLog, ctx := spanlogger.NewWithLogger(ctx, logger, "Distributor.InfluxHandler.decodeAndConvert")
+		defer spanLog.Span.Finish()
+
+		spanLog.SetTag("content_encoding", r.Header.Get("Content-Encoding"))
+		spanLog.SetTag("content_length", r.ContentLength)
+		spanLog.SetTag("precision", precision)
+
+		metrics, err := influxLinesToTimeseries(ctx, discardedDueToInfluxParseError, logger, body, toMillis, time.Now())
+		if err != nil {
+			return body, err
+		}
+
+		level.Debug(spanLog).Log("msg", "InfluxDB line protocol to Prometheus conversion complete", "series_count", len(metrics))
+
+		req.Timeseries = metrics
+		return body, nil
+	})
+
+	// InfluxDB clients expect a 204 No Content response on a successful write.
+	return noContentOnSuccess(h)
+}
+
+// influxPrecisionToMillis returns a function converting timestamps in the given InfluxDB
+// precision to milliseconds. Both the v1 (n, u, ms, s, m, h) and v2 (ns, us, ms, s) precisions
+// are supported. Timestamps default to nanoseconds.
+func influxPrecisionToMillis(precision string) (func(int64) int64, error) {
+	switch precision {
+	case "", "n", "ns":
+		return func(ts int64) int64 { return ts / int64(time.Millisecond) }, nil
+	case "u", "us":
+		return func(ts int64) int64 { return ts / int64(time.Millisecond/time.Microsecond) }, nil
+	case "ms":
+		return func(ts int64) int64 { return ts }, nil
+	case "s":
+		return func(ts int64) int64 { return ts * int64(time.Second/time.Millisecond) }, nil
+	case "m":
+		return func(ts int64) int64 { return ts * int64(time.Minute/time.Millisecond) }, nil
+	case "h":
+		return func(ts int64) int64 { return ts * int64(time.Hour/time.Millisecond) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported precision: %q", precision)
+	}
+}
+
+// influxLinesToTimeseries converts the InfluxDB line protocol body to Mimir timeseries.
+// Each field of a point becomes a sample of the series named <measurement>_<field key>
+// (or just <measurement> when the field key is "value"), labelled with the point tags.
+// Lines which can't be parsed are discarded and their samples, one per field, are tracked in
+// discardedDueToParseError, unless none of the lines could be parsed, in which case an error is returned.
+func influxLinesToTimeseries(ctx context.Context, discardedDueToParseError *prometheus.CounterVec, logger kitlog.Logger, body []byte, toMillis func(int64) int64, now time.Time) ([]mimirpb.PreallocTimeseries, error) {
+	var (
+		parsed         int
+		dropped        int
+		droppedSamples int
+		firstErr       error
+		builder   = newTimeseriesBuilder()
+		nowMillis = now.UnixMilli()
+	)
+
+	for lineNo, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		p, err := parseInfluxLine(string(line))
+		if err != nil {
+			dropped++
+			droppedSamples += countInfluxLineFields(string(line))
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %w", lineNo+1, err)
+			}
+			continue
+		}
+		parsed++
+
+		ts := nowMillis
+		if p.hasTimestamp {
+			ts = toMillis(p.timestamp)
+		}
+
+		for _, f := range p.fields {
+			if f.isString {
+				// Prometheus has no support for string values.
+				continue
+			}
+
+			builder.add(influxPointLabels(p, f.key), mimirpb.Sample{TimestampMs: ts, Value: f.value})
+		}
+	}
+
+	if dropped > 0 {
+		userID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		discardedDueToParseError.WithLabelValues(userID, "").Add(float64(droppedSamples)) // Group is empty here as metrics couldn't be parsed
+
+		parseErr := firstErr.Error()
+		if len(parseErr) > maxErrMsgLen {
+			parseErr = parseErr[:maxErrMsgLen]
+		}
+
+		if parsed == 0 {
+			mimirpb.ReuseSlice(builder.timeseries)
+			return nil, fmt.Errorf("failed to parse InfluxDB line protocol: %s", parseErr)
+		}
+
+		level.Warn(logger).Log("msg", "InfluxDB line protocol parse error", "dropped_lines", dropped, "dropped_samples", droppedSamples, "err", parseErr)
+	}
+
+	return builder.timeseries, nil
+}
+
+func influxPointLabels(p influxPoint, fieldKey string) []mimirpb.LabelAdapter {
+	name := p.measurement
+	if fieldKey != influxDefaultFieldKey {
+		name = name + "_" + fieldKey
+	}
+
+	labels := make([]mimirpb.LabelAdapter, 0, len(p.tags)+1)
+	labels = append(labels, mimirpb.LabelAdapter{Name: model.MetricNameLabel, Value: sanitizeName(name, true)})
+	for _, t := range p.tags {
+		labels = append(labels, mimirpb.LabelAdapter{Name: sanitizeName(t.key, false), Value: t.value})
+	}
+	return labels
+}
+
+// countInfluxLineFields returns the number of fields of a line which couldn't be parsed, that is the number of
+// samples it would have been converted to. It counts the unescaped and unquoted commas in the fields section of
+// the line, and returns at least 1 so that each dropped line is accounted for.
+func countInfluxLineFields(line string) int {
+	var (
+		section  int // 0 is the measurement and tags, 1 the fields and 2 the timestamp.
+		fields   = 1
+		inQuotes bool
+	)
+
+	for pos := 0; pos < len(line); pos++ {
+		switch c := line[pos]; {
+		case c == '\\':
+			pos++
+		case c == '"' && section == 1:
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == ' ':
+			section++
+			pos = skipInfluxSpaces(line, pos) - 1
+		case c == ',' && section == 1:
+			fields++
+		}
+	}
+
+	return fields
+}
+
+type influxTag struct {
+	key, value string
+}
+
+type influxField struct {
+	key      string
+	value    float64
+	isString bool
+}
+
+type influxPoint struct {
+	measurement  string
+	tags         []influxTag
+	fields       []influxField
+	timestamp    int64
+	hasTimestamp bool
+}
+
+// parseInfluxLine parses a single line of the InfluxDB line protocol:
+//
+//	<measurement>[,<tag_key>=<tag_value>...] <field_key>=<field_value>[,<field_key>=<field_value>...] [<timestamp>]
+func parseInfluxLine(line string) (influxPoint, error) {
+	var (
+		p   influxPoint
+		pos int
+	)
+
+	p.measurement, pos = scanInfluxToken(line, pos, ", ")
+	if p.measurement == "" {
+		return p, fmt.Errorf("missing measurement")
+	}
+
+	for pos < len(line) && line[pos] == ',' {
+		var t influxTag
+		t.key, pos = scanInfluxToken(line, pos+1, ",= ")
+		if pos >= len(line) || line[pos] != '=' || t.key == "" {
+			return p, fmt.Errorf("invalid tag in measurement %q", p.measurement)
+		}
+		t.value, pos = scanInfluxToken(line, pos+1, ", ")
+		if t.value == "" {
+			return p, fmt.Errorf("missing value for tag %q", t.key)
+		}
+		p.tags = append(p.tags, t)
+	}
+
+	pos = skipInfluxSpaces(line, pos)
+	if pos >= len(line) {
+		return p, fmt.Errorf("missing fields in measurement %q", p.measurement)
+	}
+
+	for {
+		var (
+			f   influxField
+			raw string
+			err error
+		)
+		f.key, pos = scanInfluxToken(line, pos, ",= ")
+		if pos >= len(line) || line[pos] != '=' || f.key == "" {
+			return p, fmt.Errorf("invalid field in measurement %q", p.measurement)
+		}
+		pos++
+
+		if pos < len(line) && line[pos] == '"' {
+			pos, err = skipInfluxQuotedString(line, pos)
+			if err != nil {
+				return p, fmt.Errorf("field %q: %w", f.key, err)
+			}
+			f.isString = true
+		} else {
+			raw, pos = scanInfluxToken(line, pos, ", ")
+			f.value, err = parseInfluxFieldValue(raw)
+			if err != nil {
+				return p, fmt.Errorf("field %q: %w", f.key, err)
+			}
+		}
+		p.fields = append(p.fields, f)
+
+		if pos >= len(line) || line[pos] != ',' {
+			break
+		}
+		pos++
+	}
+
+	pos = skipInfluxSpaces(line, pos)
+	if pos < len(line) {
+		ts, err := strconv.ParseInt(line[pos:], 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid timestamp %q", line[pos:])
+		}
+		p.timestamp = ts
+		p.hasTimestamp = true
+	}
+
+	return p, nil
+}
+
+// scanInfluxToken reads from line starting at pos until the first unescaped
+// character in stopChars, and returns the unescaped token and the stop position.
+func scanInfluxToken(line string, pos int, stopChars string) (string, int) {
+	start := pos
+	escaped := false
+	for ; pos < len(line); pos++ {
+		c := line[pos]
+		if c == '\\' && pos+1 < len(line) {
+			escaped = true
+			pos++
+			continue
+		}
+		if strings.IndexByte(stopChars, c) >= 0 {
+			break
+		}
+	}
+
+	if !escaped {
+		return line[start:pos], pos
+	}
+
+	sb := strings.Builder{}
+	sb.Grow(pos - start)
+	for i := start; i < pos; i++ {
+		if line[i] == '\\' && i+1 < pos && isInfluxEscapable(line[i+1]) {
+			i++
+		}
+		sb.WriteByte(line[i])
+	}
+	return sb.String(), pos
+}
+
+func isInfluxEscapable(c byte) bool {
+	return c == ',' || c == '=' || c == ' ' || c == '\\' || c == '"'
+}
+
+func skipInfluxSpaces(line string, pos int) int {
+	for pos < len(line) && line[pos] == ' ' {
+		pos++
+	}
+	return pos
+}
+
+// skipInfluxQuotedString skips the double-quoted string starting at pos and returns the position after the closing quote.
+func skipInfluxQuotedString(line string, pos int) (int, error) {
+	for pos++; pos < len(line); pos++ {
+		switch line[pos] {
+		case '\\':
+			pos++
+		case '"':
+			return pos + 1, nil
+		}
+	}
+	return pos, fmt.Errorf("unterminated string value")
+}
+
+func parseInfluxFieldValue(raw string) (float64, error) {
+	if raw == "" {
+		return 0, fmt.Errorf("missing value")
+	}
+
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch raw[len(raw)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer value %q", raw)
+		}
+		return float64(v), nil
+	case 'u':
+		v, err := strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned integer value %q", raw)
+		}
+		return float64(v), nil
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float value %q", raw)
+	}
+	return v, nil
+}
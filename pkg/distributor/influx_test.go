@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestParseInfluxLine(t *testing.T) {
+	tests := map[string]struct {
+		line        string
+		expected    influxPoint
+		expectedErr string
+	}{
+		"measurement with a single field": {
+			line:     "cpu value=1.5",
+			expected: influxPoint{measurement: "cpu", fields: []influxField{{key: "value", value: 1.5}}},
+		},
+		"tags, multiple fields and timestamp": {
+			line: "cpu,host=a,region=eu usage_idle=90,usage_user=10i 1700000000000000000",
+			expected: influxPoint{
+				measurement:  "cpu",
+				tags:         []influxTag{{key: "host", value: "a"}, {key: "region", value: "eu"}},
+				fields:       []influxField{{key: "usage_idle", value: 90}, {key: "usage_user", value: 10}},
+				timestamp:    1700000000000000000,
+				hasTimestamp: true,
+			},
+		},
+		"escaped characters": {
+			line: `disk\ io,path=/var\,lib,my\=tag=a\ b bytes\ read=5u`,
+			expected: influxPoint{
+				measurement: "disk io",
+				tags:        []influxTag{{key: "path", value: "/var,lib"}, {key: "my=tag", value: "a b"}},
+				fields:      []influxField{{key: "bytes read", value: 5}},
+			},
+		},
+		"boolean and string fields": {
+			line: `service up=true,down=F,msg="hello, \"world\"" 10`,
+			expected: influxPoint{
+				measurement:  "service",
+				fields:       []influxField{{key: "up", value: 1}, {key: "down", value: 0}, {key: "msg", isString: true}},
+				timestamp:    10,
+				hasTimestamp: true,
+			},
+		},
+		"missing fields": {
+			line:        "cpu,host=a",
+			expectedErr: `missing fields in measurement "cpu"`,
+		},
+		"missing tag value": {
+			line:        "cpu,host= value=1",
+			expectedErr: `missing value for tag "host"`,
+		},
+		"invalid field value": {
+			line:        "cpu value=abc",
+			expectedErr: `field "value": invalid float value "abc"`,
+		},
+		"invalid integer value": {
+			line:        "cpu value=1.5i",
+			expectedErr: `field "value": invalid integer value "1.5i"`,
+		},
+		"unterminated string": {
+			line:        `cpu msg="abc`,
+			expectedErr: `field "msg": unterminated string value`,
+		},
+		"invalid timestamp": {
+			line:        "cpu value=1 now",
+			expectedErr: `invalid timestamp "now"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := parseInfluxLine(tc.line)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestInfluxLinesToTimeseries(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "test")
+	now := time.Unix(1700000000, 0)
+	toMillis, err := influxPrecisionToMillis("s")
+	require.NoError(t, err)
+
+	t.Run("points are converted and grouped by series", func(t *testing.T) {
+		reg := prometheus.NewPedanticRegistry()
+		body := strings.Join([]string{
+			"# comment",
+			"cpu,region=eu,host=a value=1,usage=5 1700000001",
+			"",
+			"cpu,host=a,region=eu value=2 1700000002",
+			`cpu,host=b msg="ignored"`,
+			"mem,host=a used=3i",
+		}, "\n")
+
+		series, err := influxLinesToTimeseries(ctx, newInfluxParseErrorCounter(reg), log.NewNopLogger(), []byte(body), toMillis, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []mimirpb.TimeSeries{
+			{
+				Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "cpu"}, {Name: "host", Value: "a"}, {Name: "region", Value: "eu"}},
+				Samples: []mimirpb.Sample{{TimestampMs: 1700000001000, Value: 1}, {TimestampMs: 1700000002000, Value: 2}},
+			},
+			{
+				Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "cpu_usage"}, {Name: "host", Value: "a"}, {Name: "region", Value: "eu"}},
+				Samples: []mimirpb.Sample{{TimestampMs: 1700000001000, Value: 5}},
+			},
+			{
+				Labels:  []mimirpb.LabelAdapter{{Name: "__name__", Value: "mem_used"}, {Name: "host", Value: "a"}},
+				Samples: []mimirpb.Sample{{TimestampMs: now.UnixMilli(), Value: 3}},
+			},
+		}, preallocTimeseriesToTimeseries(series))
+
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(""), "cortex_discarded_samples_total"))
+	})
+
+	t.Run("invalid lines are discarded", func(t *testing.T) {
+		reg := prometheus.NewPedanticRegistry()
+		body := "cpu value=1 1700000001\ncpu value=\ncpu\ncpu,host=a\\,b msg=\"a,b\",usage=1,idle= 1700000001"
+
+		series, err := influxLinesToTimeseries(ctx, newInfluxParseErrorCounter(reg), log.NewNopLogger(), []byte(body), toMillis, now)
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+			# TYPE cortex_discarded_samples_total counter
+			cortex_discarded_samples_total{group="",reason="influx_parse_error",user="test"} 5
+		`), "cortex_discarded_samples_total"))
+	})
+
+	t.Run("an error is returned if no line can be parsed", func(t *testing.T) {
+		reg := prometheus.NewPedanticRegistry()
+
+		_, err := influxLinesToTimeseries(ctx, newInfluxParseErrorCounter(reg), log.NewNopLogger(), []byte("cpu value=x"), toMillis, now)
+		require.EqualError(t, err, `failed to parse InfluxDB line protocol: line 1: field "value": invalid float value "x"`)
+	})
+}
+
+func TestInfluxHandler(t *testing.T) {
+	const body = "cpu,host=a value=1 1700000000000"
+
+	tests := map[string]struct {
+		url            string
+		body           string
+		gzip           bool
+		expectedCode   int
+		expectedSeries int
+		expectedTs     int64
+	}{
+		"v1 write with precision": {
+			url:            "http://localhost/api/v1/push/influx/write?db=telegraf&precision=ms",
+			body:           body,
+			expectedCode:   http.StatusNoContent,
+			expectedSeries: 1,
+			expectedTs:     1700000000000,
+		},
+		"v2 write with gzip": {
+			url:            "http://localhost/api/v1/push/influx/api/v2/write?org=o&bucket=b&precision=us",
+			body:           body,
+			gzip:           true,
+			expectedCode:   http.StatusNoContent,
+			expectedSeries: 1,
+			expectedTs:     1700000000,
+		},
+		"unsupported precision": {
+			url:          "http://localhost/api/v1/push/influx/write?precision=d",
+			body:         body,
+			expectedCode: http.StatusBadRequest,
+		},
+		"invalid body": {
+			url:          "http://localhost/api/v1/push/influx/write",
+			body:         "cpu",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var reqBody bytes.Buffer
+			if tc.gzip {
+				gz := gzip.NewWriter(&reqBody)
+				_, err := gz.Write([]byte(tc.body))
+				require.NoError(t, err)
+				require.NoError(t, gz.Close())
+			} else {
+				reqBody.WriteString(tc.body)
+			}
+
+			req, err := http.NewRequest("POST", tc.url, &reqBody)
+			require.NoError(t, err)
+			if tc.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+
+			pushed := 0
+			handler := InfluxHandler(100000, nil, false, nil, prometheus.NewPedanticRegistry(), func(ctx context.Context, pushReq *Request) error {
+				defer pushReq.CleanUp()
+				request, err := pushReq.WriteRequest()
+				if err != nil {
+					return err
+				}
+				require.Len(t, request.Timeseries, tc.expectedSeries)
+				assert.Equal(t, tc.expectedTs, request.Timeseries[0].Samples[0].TimestampMs)
+				pushed++
+				return nil
+			})
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			if tc.expectedCode == http.StatusNoContent {
+				assert.Equal(t, 1, pushed)
+			}
+		})
+	}
+}
+
+func newInfluxParseErrorCounter(reg prometheus.Registerer) *prometheus.CounterVec {
+	return validation.DiscardedSamplesCounter(reg, influxParseError)
+}
+
+func preallocTimeseriesToTimeseries(series []mimirpb.PreallocTimeseries) []mimirpb.TimeSeries {
+	out := make([]mimirpb.TimeSeries, 0, len(series))
+	for _, s := range series {
+		out = append(out, mimirpb.TimeSeries{Labels: s.Labels, Samples: s.Samples})
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/limiter"
+	"golang.org/x/time/rate"
+)
+
+// labelSetRateLimiter is the rate limiter of the per-label-set ingestion rate limits, keyed by labelSetRateLimiterKey.
+// Unlike limiter.RateLimiter, it consumes the tokens of multiple limiters only if all of them allow it, so that the
+// tokens of a limit aren't consumed by a request rejected because of another limit.
+type labelSetRateLimiter struct {
+	strategy      limiter.RateLimiterStrategy
+	recheckPeriod time.Duration
+
+	// mtx guards the limiters, and serializes allowAll so that the tokens can't be consumed between the check
+	// and the consumption.
+	mtx      sync.Mutex
+	limiters map[string]*labelSetLimiter
+}
+
+type labelSetLimiter struct {
+	limiter   *rate.Limiter
+	recheckAt time.Time
+}
+
+func newLabelSetRateLimiter(strategy limiter.RateLimiterStrategy, recheckPeriod time.Duration) *labelSetRateLimiter {
+	return &labelSetRateLimiter{
+		strategy:      strategy,
+		recheckPeriod: recheckPeriod,
+		limiters:      map[string]*labelSetLimiter{},
+	}
+}
+
+// allowAll consumes tokens[i] tokens from the limiter of keys[i], for each i, if all the limiters have enough tokens.
+// Otherwise, it doesn't consume any token, and returns the index of the first limiter without enough tokens.
+func (l *labelSetRateLimiter) allowAll(now time.Time, keys []string, tokens []int) (int, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	limiters := make([]*rate.Limiter, len(keys))
+	for i, key := range keys {
+		limiters[i] = l.getLimiterLocked(now, key)
+		if limiters[i].Limit() == rate.Inf {
+			continue
+		}
+		if float64(tokens[i]) > limiters[i].TokensAt(now) {
+			return i, false
+		}
+	}
+
+	for i, lim := range limiters {
+		lim.AllowN(now, tokens[i])
+	}
+	return -1, true
+}
+
+// getLimiterLocked returns the limiter of key, updating its limit and burst every recheckPeriod. Must be called
+// with mtx held.
+func (l *labelSetRateLimiter) getLimiterLocked(now time.Time, key string) *rate.Limiter {
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &labelSetLimiter{
+			limiter:   rate.NewLimiter(rate.Limit(l.strategy.Limit(key)), l.strategy.Burst(key)),
+			recheckAt: now.Add(l.recheckPeriod),
+		}
+		l.limiters[key] = entry
+		return entry.limiter
+	}
+
+	if !now.Before(entry.recheckAt) {
+		if limit := rate.Limit(l.strategy.Limit(key)); entry.limiter.Limit() != limit {
+			entry.limiter.SetLimitAt(now, limit)
+		}
+		if burst := l.strategy.Burst(key); entry.limiter.Burst() != burst {
+			entry.limiter.SetBurstAt(now, burst)
+		}
+		entry.recheckAt = now.Add(l.recheckPeriod)
+	}
+	return entry.limiter
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type staticRateStrategy map[string]float64
+
+func (s staticRateStrategy) Limit(key string) float64 {
+	if limit, ok := s[key]; ok {
+		return limit
+	}
+	return float64(rate.Inf)
+}
+
+func (s staticRateStrategy) Burst(key string) int {
+	return int(s[key])
+}
+
+func TestLabelSetRateLimiter_AllowAll(t *testing.T) {
+	now := time.Now()
+	l := newLabelSetRateLimiter(staticRateStrategy{"a": 10, "b": 5}, time.Minute)
+
+	// The tokens of "a" aren't consumed when "b" doesn't allow the request.
+	idx, ok := l.allowAll(now, []string{"a", "b"}, []int{8, 6})
+	require.False(t, ok)
+	require.Equal(t, 1, idx)
+
+	idx, ok = l.allowAll(now, []string{"a", "b"}, []int{8, 5})
+	require.True(t, ok)
+	require.Equal(t, -1, idx)
+
+	// Both limiters have been consumed.
+	idx, ok = l.allowAll(now, []string{"a"}, []int{3})
+	require.False(t, ok)
+	require.Equal(t, 0, idx)
+
+	idx, ok = l.allowAll(now, []string{"b"}, []int{1})
+	require.False(t, ok)
+	require.Equal(t, 0, idx)
+
+	// Unlimited keys always allow the request.
+	_, ok = l.allowAll(now, []string{"a", "unlimited"}, []int{2, 1000})
+	require.True(t, ok)
+
+	// The tokens are refilled over time.
+	_, ok = l.allowAll(now.Add(time.Second), []string{"a", "b"}, []int{10, 5})
+	require.True(t, ok)
+}
@@ -3,10 +3,8 @@
 package distributor
 
 import (
-	"compress/gzip"
 	"context"
 	"errors"
-	"io"
 	"net/http"
 	"time"
 
@@ -26,7 +24,6 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
-	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/log"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
 	"github.com/grafana/mimir/pkg/util/validation"
@@ -50,6 +47,12 @@ func OTLPHandler(
 	push PushFunc,
 ) http.Handler {
 	discardedDueToOtelParseError := validation.DiscardedSamplesCounter(reg, otelParseError)
+	encodingMetrics := newPushEncodingMetrics(reg, "otlp")
+
+	var deltaConverter *otelDeltaConverter
+	if limits != nil {
+		deltaConverter = newOTelDeltaConverter(limits, reg)
+	}
 
 	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
 		var decoderFunc func(buf []byte) (pmetricotlp.ExportRequest, error)
@@ -74,43 +77,8 @@ func OTLPHandler(
 			return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported content type: %s, supported: [%s, %s]", contentType, jsonContentType, pbContentType)
 		}
 
-		if r.ContentLength > int64(maxRecvMsgSize) {
-			return nil, httpgrpc.Errorf(http.StatusRequestEntityTooLarge, distributorMaxWriteMessageSizeErr{actual: int(r.ContentLength), limit: maxRecvMsgSize}.Error())
-		}
-
-		reader := r.Body
-		// Handle compression.
-		contentEncoding := r.Header.Get("Content-Encoding")
-		switch contentEncoding {
-		case "gzip":
-			gr, err := gzip.NewReader(reader)
-			if err != nil {
-				return nil, err
-			}
-			reader = gr
-
-		case "":
-			// No compression.
-
-		default:
-			return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported compression: %s. Only \"gzip\" or no compression supported", contentEncoding)
-		}
-
-		// Protect against a large input.
-		reader = http.MaxBytesReader(nil, reader, int64(maxRecvMsgSize))
-
-		body, err := io.ReadAll(reader)
+		body, err := readPushBody(ctx, r, maxRecvMsgSize, dst, encodingMetrics)
 		if err != nil {
-			r.Body.Close()
-
-			if util.IsRequestBodyTooLarge(err) {
-				return body, httpgrpc.Errorf(http.StatusRequestEntityTooLarge, distributorMaxWriteMessageSizeErr{actual: -1, limit: maxRecvMsgSize}.Error())
-			}
-
-			return body, err
-		}
-
-		if err = r.Body.Close(); err != nil {
 			return body, err
 		}
 
@@ -118,7 +86,7 @@ func OTLPHandler(
 		defer log.Span.Finish()
 
 		log.SetTag("content_type", contentType)
-		log.SetTag("content_encoding", contentEncoding)
+		log.SetTag("content_encoding", r.Header.Get("Content-Encoding"))
 		log.SetTag("content_length", r.ContentLength)
 
 		otlpReq, err := decoderFunc(body)
@@ -128,7 +96,21 @@ func OTLPHandler(
 
 		level.Debug(log).Log("msg", "decoding complete, starting conversion")
 
-		metrics, err := otelMetricsToTimeseries(ctx, discardedDueToOtelParseError, logger, otlpReq.Metrics())
+		var settings prometheusremotewrite.Settings
+		if limits != nil {
+			userID, err := tenant.TenantID(ctx)
+			if err != nil {
+				return body, err
+			}
+			otelPromoteResourceAttributes(otlpReq.Metrics(), limits.OTelPromoteResourceAttributes(userID))
+			settings.DisableTargetInfo = limits.OTelDisableTargetInfo(userID)
+
+			if limits.OTelConvertDeltaToCumulative(userID) {
+				deltaConverter.convert(userID, otlpReq.Metrics(), time.Now())
+			}
+		}
+
+		metrics, err := otelMetricsToTimeseries(ctx, discardedDueToOtelParseError, logger, otlpReq.Metrics(), settings)
 		if err != nil {
 			return body, err
 		}
@@ -216,8 +198,73 @@ func otelMetricsToMetadata(md pmetric.Metrics) []*mimirpb.MetricMetadata {
 
 }
 
-func otelMetricsToTimeseries(ctx context.Context, discardedDueToOtelParseError *prometheus.CounterVec, logger kitlog.Logger, md pmetric.Metrics) ([]mimirpb.PreallocTimeseries, error) {
-	tsMap, errs := prometheusremotewrite.FromMetrics(md, prometheusremotewrite.Settings{})
+// otelPromoteResourceAttributes copies the listed resource attributes to the attributes of all the data points
+// of the resource, so that they're converted to labels of the series. Data point attributes take precedence.
+func otelPromoteResourceAttributes(md pmetric.Metrics, promote []string) {
+	if len(promote) == 0 {
+		return
+	}
+
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		resourceMetrics := resourceMetricsSlice.At(i)
+		resourceAttrs := resourceMetrics.Resource().Attributes()
+
+		promoted := pcommon.NewMap()
+		for _, name := range promote {
+			if value, ok := resourceAttrs.Get(name); ok {
+				value.CopyTo(promoted.PutEmpty(name))
+			}
+		}
+		if promoted.Len() == 0 {
+			continue
+		}
+
+		scopeMetricsSlice := resourceMetrics.ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			metricSlice := scopeMetricsSlice.At(j).Metrics()
+			for k := 0; k < metricSlice.Len(); k++ {
+				forEachOtelDataPointAttributes(metricSlice.At(k), func(attrs pcommon.Map) {
+					promoted.Range(func(name string, value pcommon.Value) bool {
+						if _, exists := attrs.Get(name); !exists {
+							value.CopyTo(attrs.PutEmpty(name))
+						}
+						return true
+					})
+				})
+			}
+		}
+	}
+}
+
+// forEachOtelDataPointAttributes calls f with the attributes of each data point of the metric.
+func forEachOtelDataPointAttributes(metric pmetric.Metric, f func(attrs pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		for i := 0; i < metric.Gauge().DataPoints().Len(); i++ {
+			f(metric.Gauge().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		for i := 0; i < metric.Sum().DataPoints().Len(); i++ {
+			f(metric.Sum().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		for i := 0; i < metric.Histogram().DataPoints().Len(); i++ {
+			f(metric.Histogram().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		for i := 0; i < metric.ExponentialHistogram().DataPoints().Len(); i++ {
+			f(metric.ExponentialHistogram().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		for i := 0; i < metric.Summary().DataPoints().Len(); i++ {
+			f(metric.Summary().DataPoints().At(i).Attributes())
+		}
+	}
+}
+
+func otelMetricsToTimeseries(ctx context.Context, discardedDueToOtelParseError *prometheus.CounterVec, logger kitlog.Logger, md pmetric.Metrics, settings prometheusremotewrite.Settings) ([]mimirpb.PreallocTimeseries, error) {
+	tsMap, errs := prometheusremotewrite.FromMetrics(md, settings)
 
 	if errs != nil {
 		userID, err := tenant.TenantID(ctx)
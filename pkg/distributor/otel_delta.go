@@ -0,0 +1,449 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	otelDeltaOutOfOrder  = "otlp_delta_out_of_order"
+	otelDeltaSeriesLimit = "otlp_delta_series_limit"
+
+	// otelDeltaPruneInterval is how often the idle series are removed from the delta converter.
+	otelDeltaPruneInterval = time.Minute
+)
+
+// otelDeltaConverter converts OTLP sums and histograms with delta temporality to cumulative temporality,
+// keeping the running totals of each series in memory.
+type otelDeltaConverter struct {
+	limits *validation.Overrides
+
+	mtx       sync.Mutex
+	tenants   map[string]*otelDeltaTenant
+	lastPrune time.Time
+
+	trackedSeries            prometheus.Gauge
+	discardedOutOfOrder      *prometheus.CounterVec
+	discardedOverSeriesLimit *prometheus.CounterVec
+}
+
+type otelDeltaTenant struct {
+	mtx    sync.Mutex
+	series map[uint64]*otelDeltaSeries
+}
+
+// otelDeltaSeries holds the running totals of a delta series.
+type otelDeltaSeries struct {
+	metricType pmetric.MetricType
+	lastSeen   time.Time
+	start      pcommon.Timestamp
+	timestamp  pcommon.Timestamp
+
+	// Sums.
+	isDouble    bool
+	intValue    int64
+	doubleValue float64
+
+	// Histograms and exponential histograms.
+	count          uint64
+	sum            float64
+	hasMin, hasMax bool
+	min, max       float64
+
+	// Histograms.
+	bounds  []float64
+	buckets []uint64
+
+	// Exponential histograms.
+	scale              int32
+	zeroCount          uint64
+	positive, negative otelExpBuckets
+}
+
+// otelExpBuckets are the buckets of an exponential histogram, at a given scale.
+type otelExpBuckets struct {
+	offset int32
+	counts []uint64
+}
+
+func newOTelDeltaConverter(limits *validation.Overrides, reg prometheus.Registerer) *otelDeltaConverter {
+	return &otelDeltaConverter{
+		limits:  limits,
+		tenants: map[string]*otelDeltaTenant{},
+		trackedSeries: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_distributor_otlp_delta_series",
+			Help: "Number of OTLP delta series whose running totals are tracked for the conversion to cumulative temporality.",
+		}),
+		discardedOutOfOrder:      validation.DiscardedSamplesCounter(reg, otelDeltaOutOfOrder),
+		discardedOverSeriesLimit: validation.DiscardedSamplesCounter(reg, otelDeltaSeriesLimit),
+	}
+}
+
+// convert converts in place the delta sums and histograms of md to cumulative temporality. Data points older than
+// the last converted data point of the same series, and data points of new series exceeding the limit, are dropped.
+func (c *otelDeltaConverter) convert(userID string, md pmetric.Metrics, now time.Time) {
+	c.pruneIfNeeded(now)
+
+	t := c.tenant(userID)
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	maxSeries := c.limits.OTelDeltaToCumulativeMaxSeries(userID)
+	outOfOrder, overLimit := 0, 0
+
+	// lookup returns the running totals of the series, or false if the series can't be tracked.
+	lookup := func(hash uint64, metricType pmetric.MetricType) (*otelDeltaSeries, bool) {
+		s, ok := t.series[hash]
+		if ok && s.metricType == metricType {
+			s.lastSeen = now
+			return s, true
+		}
+		if !ok && maxSeries > 0 && len(t.series) >= maxSeries {
+			overLimit++
+			return nil, false
+		}
+		if !ok {
+			c.trackedSeries.Inc()
+		}
+		s = &otelDeltaSeries{metricType: metricType, lastSeen: now}
+		t.series[hash] = s
+		return s, true
+	}
+
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		resourceMetrics := resourceMetricsSlice.At(i)
+		resourceAttrs := resourceMetrics.Resource().Attributes()
+
+		scopeMetricsSlice := resourceMetrics.ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			scopeMetrics := scopeMetricsSlice.At(j)
+
+			metricSlice := scopeMetrics.Metrics()
+			for k := 0; k < metricSlice.Len(); k++ {
+				metric := metricSlice.At(k)
+				seriesHash := func(attrs pcommon.Map) uint64 {
+					return otelDeltaSeriesHash(resourceAttrs, scopeMetrics.Scope(), metric, attrs)
+				}
+
+				switch metric.Type() {
+				case pmetric.MetricTypeSum:
+					if metric.Sum().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						continue
+					}
+					metric.Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+						s, ok := lookup(seriesHash(dp.Attributes()), metric.Type())
+						if !ok {
+							return true
+						}
+						if !s.accumulateNumber(dp) {
+							outOfOrder++
+							return true
+						}
+						return false
+					})
+					metric.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+				case pmetric.MetricTypeHistogram:
+					if metric.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						continue
+					}
+					metric.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+						s, ok := lookup(seriesHash(dp.Attributes()), metric.Type())
+						if !ok {
+							return true
+						}
+						if !s.accumulateHistogram(dp) {
+							outOfOrder++
+							return true
+						}
+						return false
+					})
+					metric.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+				case pmetric.MetricTypeExponentialHistogram:
+					if metric.ExponentialHistogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						continue
+					}
+					metric.ExponentialHistogram().DataPoints().RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool {
+						s, ok := lookup(seriesHash(dp.Attributes()), metric.Type())
+						if !ok {
+							return true
+						}
+						if !s.accumulateExponentialHistogram(dp) {
+							outOfOrder++
+							return true
+						}
+						return false
+					})
+					metric.ExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				}
+			}
+		}
+	}
+
+	if outOfOrder > 0 {
+		c.discardedOutOfOrder.WithLabelValues(userID, "").Add(float64(outOfOrder))
+	}
+	if overLimit > 0 {
+		c.discardedOverSeriesLimit.WithLabelValues(userID, "").Add(float64(overLimit))
+	}
+}
+
+func (c *otelDeltaConverter) tenant(userID string) *otelDeltaTenant {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[userID]
+	if !ok {
+		t = &otelDeltaTenant{series: map[uint64]*otelDeltaSeries{}}
+		c.tenants[userID] = t
+	}
+	return t
+}
+
+// pruneIfNeeded removes the series which haven't received data points within the tenant's idle timeout.
+func (c *otelDeltaConverter) pruneIfNeeded(now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if now.Sub(c.lastPrune) < otelDeltaPruneInterval {
+		return
+	}
+	c.lastPrune = now
+
+	for userID, t := range c.tenants {
+		deadline := now.Add(-c.limits.OTelDeltaToCumulativeIdleTimeout(userID))
+
+		t.mtx.Lock()
+		for hash, s := range t.series {
+			if s.lastSeen.Before(deadline) {
+				delete(t.series, hash)
+				c.trackedSeries.Dec()
+			}
+		}
+		t.mtx.Unlock()
+	}
+}
+
+// accumulateNumber adds the delta data point to the running total, and replaces its value with the running total.
+// Returns false if the data point isn't newer than the last data point of the series.
+func (s *otelDeltaSeries) accumulateNumber(dp pmetric.NumberDataPoint) bool {
+	if accepted, _ := s.accept(dp.StartTimestamp(), dp.Timestamp(), false); !accepted {
+		return false
+	}
+
+	switch {
+	case dp.ValueType() == pmetric.NumberDataPointValueTypeInt && !s.isDouble:
+		s.intValue += dp.IntValue()
+		dp.SetIntValue(s.intValue)
+	default:
+		if !s.isDouble {
+			s.isDouble = true
+			s.doubleValue = float64(s.intValue)
+		}
+		if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+			s.doubleValue += float64(dp.IntValue())
+		} else {
+			s.doubleValue += dp.DoubleValue()
+		}
+		dp.SetDoubleValue(s.doubleValue)
+	}
+	dp.SetStartTimestamp(s.start)
+	return true
+}
+
+// accumulateHistogram adds the delta data point to the running totals, and replaces its values with the running totals.
+// A change of the bucket boundaries restarts the series from the data point.
+// Returns false if the data point isn't newer than the last data point of the series.
+func (s *otelDeltaSeries) accumulateHistogram(dp pmetric.HistogramDataPoint) bool {
+	bounds := dp.ExplicitBounds().AsRaw()
+	reset := s.timestamp != 0 && (!slices.Equal(s.bounds, bounds) || len(s.buckets) != dp.BucketCounts().Len())
+	accepted, restarted := s.accept(dp.StartTimestamp(), dp.Timestamp(), reset)
+	if !accepted {
+		return false
+	}
+	if restarted {
+		s.bounds = bounds
+		s.buckets = make([]uint64, dp.BucketCounts().Len())
+	}
+
+	for i := 0; i < dp.BucketCounts().Len(); i++ {
+		s.buckets[i] += dp.BucketCounts().At(i)
+	}
+	s.accumulateHistogramStats(dp.Count(), dp.Sum(), dp.HasMin(), dp.Min(), dp.HasMax(), dp.Max())
+
+	dp.SetStartTimestamp(s.start)
+	dp.BucketCounts().FromRaw(s.buckets)
+	dp.SetCount(s.count)
+	dp.SetSum(s.sum)
+	s.setHistogramMinMax(dp.SetMin, dp.RemoveMin, dp.SetMax, dp.RemoveMax)
+	return true
+}
+
+// accumulateExponentialHistogram adds the delta data point to the running totals, and replaces its values with the
+// running totals. Buckets are merged at the lowest scale of the two.
+// Returns false if the data point isn't newer than the last data point of the series.
+func (s *otelDeltaSeries) accumulateExponentialHistogram(dp pmetric.ExponentialHistogramDataPoint) bool {
+	accepted, restarted := s.accept(dp.StartTimestamp(), dp.Timestamp(), false)
+	if !accepted {
+		return false
+	}
+	if restarted {
+		s.scale = dp.Scale()
+	}
+
+	positive := otelExpBuckets{offset: dp.Positive().Offset(), counts: dp.Positive().BucketCounts().AsRaw()}
+	negative := otelExpBuckets{offset: dp.Negative().Offset(), counts: dp.Negative().BucketCounts().AsRaw()}
+	if dp.Scale() < s.scale {
+		s.positive.downscale(s.scale - dp.Scale())
+		s.negative.downscale(s.scale - dp.Scale())
+		s.scale = dp.Scale()
+	} else {
+		positive.downscale(dp.Scale() - s.scale)
+		negative.downscale(dp.Scale() - s.scale)
+	}
+	s.positive.add(positive)
+	s.negative.add(negative)
+	s.zeroCount += dp.ZeroCount()
+	s.accumulateHistogramStats(dp.Count(), dp.Sum(), dp.HasMin(), dp.Min(), dp.HasMax(), dp.Max())
+
+	dp.SetStartTimestamp(s.start)
+	dp.SetScale(s.scale)
+	dp.SetZeroCount(s.zeroCount)
+	dp.Positive().SetOffset(s.positive.offset)
+	dp.Positive().BucketCounts().FromRaw(s.positive.counts)
+	dp.Negative().SetOffset(s.negative.offset)
+	dp.Negative().BucketCounts().FromRaw(s.negative.counts)
+	dp.SetCount(s.count)
+	dp.SetSum(s.sum)
+	s.setHistogramMinMax(dp.SetMin, dp.RemoveMin, dp.SetMax, dp.RemoveMax)
+	return true
+}
+
+// accept checks whether a data point with the given timestamps can be accumulated, and updates the series
+// timestamps. When reset is true, or the series has no data point yet, the running totals restart from the
+// data point and restarted is true.
+func (s *otelDeltaSeries) accept(start, timestamp pcommon.Timestamp, reset bool) (accepted, restarted bool) {
+	if s.timestamp != 0 && timestamp <= s.timestamp {
+		return false, false
+	}
+	if s.timestamp == 0 || reset {
+		*s = otelDeltaSeries{metricType: s.metricType, lastSeen: s.lastSeen, start: start}
+		if start == 0 {
+			s.start = timestamp
+		}
+		restarted = true
+	}
+	s.timestamp = timestamp
+	return true, restarted
+}
+
+func (s *otelDeltaSeries) accumulateHistogramStats(count uint64, sum float64, hasMin bool, minValue float64, hasMax bool, maxValue float64) {
+	s.count += count
+	s.sum += sum
+	if hasMin && (!s.hasMin || minValue < s.min) {
+		s.min, s.hasMin = minValue, true
+	}
+	if hasMax && (!s.hasMax || maxValue > s.max) {
+		s.max, s.hasMax = maxValue, true
+	}
+}
+
+func (s *otelDeltaSeries) setHistogramMinMax(setMin func(float64), removeMin func(), setMax func(float64), removeMax func()) {
+	if s.hasMin {
+		setMin(s.min)
+	} else {
+		removeMin()
+	}
+	if s.hasMax {
+		setMax(s.max)
+	} else {
+		removeMax()
+	}
+}
+
+// downscale reduces the scale of the buckets by the given number of steps, merging the buckets.
+func (b *otelExpBuckets) downscale(by int32) {
+	if by <= 0 || len(b.counts) == 0 {
+		return
+	}
+
+	first := b.offset >> by
+	last := (b.offset + int32(len(b.counts)) - 1) >> by
+	counts := make([]uint64, last-first+1)
+	for i, c := range b.counts {
+		counts[((b.offset+int32(i))>>by)-first] += c
+	}
+	b.offset, b.counts = first, counts
+}
+
+// add adds the counts of other buckets, at the same scale.
+func (b *otelExpBuckets) add(other otelExpBuckets) {
+	if len(other.counts) == 0 {
+		return
+	}
+	if len(b.counts) == 0 {
+		b.offset, b.counts = other.offset, slices.Clone(other.counts)
+		return
+	}
+
+	first := min(b.offset, other.offset)
+	last := max(b.offset+int32(len(b.counts)), other.offset+int32(len(other.counts)))
+	counts := make([]uint64, last-first)
+	for i, c := range b.counts {
+		counts[b.offset-first+int32(i)] += c
+	}
+	for i, c := range other.counts {
+		counts[other.offset-first+int32(i)] += c
+	}
+	b.offset, b.counts = first, counts
+}
+
+// otelDeltaSeriesHash returns the hash identifying the series of a data point.
+func otelDeltaSeriesHash(resourceAttrs pcommon.Map, scope pcommon.InstrumentationScope, metric pmetric.Metric, attrs pcommon.Map) uint64 {
+	h := xxhash.New()
+	writeOtelAttributesHash(h, resourceAttrs)
+	_, _ = h.WriteString(scope.Name())
+	_, _ = h.Write(otelHashSep)
+	_, _ = h.WriteString(scope.Version())
+	_, _ = h.Write(otelHashSep)
+	_, _ = h.WriteString(metric.Name())
+	_, _ = h.Write(otelHashSep)
+	_, _ = h.WriteString(metric.Unit())
+	_, _ = h.Write(otelHashSep)
+	writeOtelAttributesHash(h, attrs)
+	return h.Sum64()
+}
+
+var otelHashSep = []byte{'\xff'}
+
+func writeOtelAttributesHash(h *xxhash.Digest, attrs pcommon.Map) {
+	names := make([]string, 0, attrs.Len())
+	attrs.Range(func(name string, _ pcommon.Value) bool {
+		names = append(names, name)
+		return true
+	})
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, _ := attrs.Get(name)
+		_, _ = h.WriteString(name)
+		_, _ = h.Write(otelHashSep)
+		_, _ = h.WriteString(value.AsString())
+		_, _ = h.Write(otelHashSep)
+	}
+	_, _ = h.Write(otelHashSep)
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestOTelDeltaConverter_Sum(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	c := newOTelDeltaConverter(otelDeltaTestOverrides(t, 2), reg)
+	now := time.Now()
+
+	md := otelDeltaSums(map[string][]int64{"a": {1, 2}, "b": {3}}, 1)
+	c.convert("user", md, now)
+	assert.Equal(t, map[string][]int64{"a": {1, 3}, "b": {3}}, otelSumValues(t, md))
+
+	// The first data point of "a" is out of order, "c" exceeds the series limit.
+	md = otelDeltaSums(map[string][]int64{"a": {5, 10}, "c": {1}}, 2)
+	c.convert("user", md, now)
+	assert.Equal(t, map[string][]int64{"a": {13}}, otelSumValues(t, md))
+
+	dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0)
+	assert.Equal(t, pcommon.Timestamp(time.Second), dp.StartTimestamp(), "the start of the first delta is the start of the cumulative series")
+
+	// Other tenants have their own series.
+	md = otelDeltaSums(map[string][]int64{"a": {1}}, 1)
+	c.convert("other", md, now)
+	assert.Equal(t, map[string][]int64{"a": {1}}, otelSumValues(t, md))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="otlp_delta_out_of_order",user="user"} 1
+		cortex_discarded_samples_total{group="",reason="otlp_delta_series_limit",user="user"} 1
+		# HELP cortex_distributor_otlp_delta_series Number of OTLP delta series whose running totals are tracked for the conversion to cumulative temporality.
+		# TYPE cortex_distributor_otlp_delta_series gauge
+		cortex_distributor_otlp_delta_series 3
+	`), "cortex_discarded_samples_total", "cortex_distributor_otlp_delta_series"))
+
+	// Idle series are removed, and restart from zero.
+	md = otelDeltaSums(map[string][]int64{"a": {1}}, 3)
+	c.convert("user", md, now.Add(time.Hour))
+	assert.Equal(t, map[string][]int64{"a": {1}}, otelSumValues(t, md))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.trackedSeries))
+}
+
+func TestOTelDeltaConverter_Histogram(t *testing.T) {
+	c := newOTelDeltaConverter(otelDeltaTestOverrides(t, 0), nil)
+	now := time.Now()
+
+	histogram := func(ts int, bounds []float64, buckets []uint64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("duration")
+		metric.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		dp := metric.Histogram().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(pcommon.Timestamp(ts-1) * pcommon.Timestamp(time.Second))
+		dp.SetTimestamp(pcommon.Timestamp(ts) * pcommon.Timestamp(time.Second))
+		dp.ExplicitBounds().FromRaw(bounds)
+		dp.BucketCounts().FromRaw(buckets)
+		var count uint64
+		for _, b := range buckets {
+			count += b
+		}
+		dp.SetCount(count)
+		dp.SetSum(float64(ts))
+		dp.SetMin(float64(ts))
+		return md
+	}
+	converted := func(md pmetric.Metrics) pmetric.HistogramDataPoint {
+		metric := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, metric.Histogram().AggregationTemporality())
+		return metric.Histogram().DataPoints().At(0)
+	}
+
+	md := histogram(1, []float64{1, 2}, []uint64{1, 0, 1})
+	c.convert("user", md, now)
+	md = histogram(2, []float64{1, 2}, []uint64{0, 2, 1})
+	c.convert("user", md, now)
+	dp := converted(md)
+	assert.Equal(t, []uint64{1, 2, 2}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(5), dp.Count())
+	assert.Equal(t, 3.0, dp.Sum())
+	assert.Equal(t, 1.0, dp.Min())
+	assert.Equal(t, pcommon.Timestamp(time.Second), dp.StartTimestamp(), "a missing start timestamp defaults to the timestamp")
+
+	// Changing the bucket boundaries restarts the series.
+	md = histogram(3, []float64{5}, []uint64{1, 1})
+	c.convert("user", md, now)
+	dp = converted(md)
+	assert.Equal(t, []uint64{1, 1}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(2), dp.Count())
+	assert.Equal(t, pcommon.Timestamp(2*time.Second), dp.StartTimestamp())
+}
+
+func TestOTelDeltaConverter_ExponentialHistogram(t *testing.T) {
+	c := newOTelDeltaConverter(otelDeltaTestOverrides(t, 0), nil)
+	now := time.Now()
+
+	histogram := func(ts int, scale, offset int32, buckets []uint64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("duration")
+		metric.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		dp := metric.ExponentialHistogram().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(ts) * pcommon.Timestamp(time.Second))
+		dp.SetScale(scale)
+		dp.SetZeroCount(1)
+		dp.Positive().SetOffset(offset)
+		dp.Positive().BucketCounts().FromRaw(buckets)
+		return md
+	}
+
+	c.convert("user", histogram(1, 1, -1, []uint64{1, 2, 3}), now)
+	md := histogram(2, 0, 2, []uint64{4})
+	c.convert("user", md, now)
+
+	dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, int32(0), dp.Scale())
+	assert.Equal(t, uint64(2), dp.ZeroCount())
+	// Indexes -1, 0 and 1 at scale 1 are indexes -1, 0 and 0 at scale 0.
+	assert.Equal(t, int32(-1), dp.Positive().Offset())
+	assert.Equal(t, []uint64{1, 5, 0, 4}, dp.Positive().BucketCounts().AsRaw())
+}
+
+func TestOTelExpBuckets(t *testing.T) {
+	b := otelExpBuckets{offset: -3, counts: []uint64{1, 2, 3, 4, 5}}
+	b.downscale(1)
+	assert.Equal(t, otelExpBuckets{offset: -2, counts: []uint64{1, 5, 9}}, b)
+
+	b.add(otelExpBuckets{offset: 2, counts: []uint64{1}})
+	assert.Equal(t, otelExpBuckets{offset: -2, counts: []uint64{1, 5, 9, 0, 1}}, b)
+
+	b.add(otelExpBuckets{offset: -4, counts: []uint64{1, 1, 1}})
+	assert.Equal(t, otelExpBuckets{offset: -4, counts: []uint64{1, 1, 2, 5, 9, 0, 1}}, b)
+}
+
+func TestHandler_otlpDeltaToCumulative(t *testing.T) {
+	for enabled, expectedCode := range map[bool]int{false: http.StatusBadRequest, true: http.StatusOK} {
+		limits := validation.Limits{}
+		flagext.DefaultValues(&limits)
+		limits.OTelConvertDeltaToCumulative = enabled
+		overrides, err := validation.NewOverrides(limits, nil)
+		require.NoError(t, err)
+
+		var samples [][]mimirpb.Sample
+		handler := OTLPHandler(100000, nil, false, false, overrides, nil, func(ctx context.Context, pushReq *Request) error {
+			defer pushReq.CleanUp()
+			request, err := pushReq.WriteRequest()
+			if err != nil {
+				return err
+			}
+			for _, ts := range request.Timeseries {
+				samples = append(samples, ts.Samples)
+			}
+			return nil
+		})
+
+		for i := 1; i <= 2; i++ {
+			md := otelDeltaSums(map[string][]int64{"a": {2}}, i)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(md), false))
+			assert.Equal(t, expectedCode, resp.Code)
+		}
+
+		if enabled {
+			assert.Equal(t, [][]mimirpb.Sample{{{TimestampMs: 2000, Value: 2}}, {{TimestampMs: 3000, Value: 4}}}, samples)
+		} else {
+			assert.Empty(t, samples)
+		}
+	}
+}
+
+func otelDeltaTestOverrides(t *testing.T, maxSeries int) *validation.Overrides {
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.OTelDeltaToCumulativeMaxSeries = maxSeries
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+	return overrides
+}
+
+// otelDeltaSums returns a delta sum per series, with the values as data points one second apart, starting at the
+// given second.
+func otelDeltaSums(series map[string][]int64, start int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		values := series[name]
+		metric := metrics.AppendEmpty()
+		metric.SetName("requests")
+		metric.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		metric.Sum().SetIsMonotonic(true)
+		for i, v := range values {
+			dp := metric.Sum().DataPoints().AppendEmpty()
+			dp.Attributes().PutStr("series", name)
+			dp.SetStartTimestamp(pcommon.Timestamp(start+i) * pcommon.Timestamp(time.Second))
+			dp.SetTimestamp(pcommon.Timestamp(start+i+1) * pcommon.Timestamp(time.Second))
+			dp.SetIntValue(v)
+		}
+	}
+	return md
+}
+
+func otelSumValues(t *testing.T, md pmetric.Metrics) map[string][]int64 {
+	values := map[string][]int64{}
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		sum := metrics.At(i).Sum()
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, sum.AggregationTemporality())
+		for j := 0; j < sum.DataPoints().Len(); j++ {
+			dp := sum.DataPoints().At(j)
+			name, _ := dp.Attributes().Get("series")
+			values[name.Str()] = append(values[name.Str()], dp.IntValue())
+		}
+	}
+	return values
+}
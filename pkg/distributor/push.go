@@ -6,16 +6,26 @@
 package distributor
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/middleware"
 	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/exp/slices"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util"
@@ -45,20 +55,120 @@ const (
 )
 
 // Handler is a http.Handler which accepts WriteRequests.
+// Both Prometheus remote-write 1.0 and 2.0 requests are supported: the message is negotiated with the
+// proto parameter of the request content type, defaulting to remote-write 1.0 when it's missing.
 func Handler(
 	maxRecvMsgSize int,
 	sourceIPs *middleware.SourceIPExtractor,
 	allowSkipLabelNameValidation bool,
 	limits *validation.Overrides,
+	reg prometheus.Registerer,
 	push PushFunc,
 ) http.Handler {
-	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
-		res, err := util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRecvMsgSize, dst, req, util.RawSnappy)
-		if errors.Is(err, util.MsgSizeTooLargeErr{}) {
-			err = distributorMaxWriteMessageSizeErr{actual: int(r.ContentLength), limit: maxRecvMsgSize}
+	encodingMetrics := newPushEncodingMetrics(reg, "prometheus")
+
+	h := handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, limits, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		// Remote-write requests are compressed with snappy, unless a different encoding is explicitly set.
+		compression, encoding := util.RawSnappy, "snappy"
+		switch contentEncoding := r.Header.Get("Content-Encoding"); contentEncoding {
+		case "", "snappy":
+		case "gzip":
+			compression, encoding = util.Gzip, contentEncoding
+		case "zstd":
+			compression, encoding = util.Zstd, contentEncoding
+		default:
+			return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported compression: %s. Only \"snappy\", \"gzip\" or \"zstd\" compression supported", contentEncoding)
+		}
+
+		var msg proto.Message = req
+
+		stats, isRemoteWriteV2 := ctx.Value(remoteWriteV2StatsContextKey{}).(*remoteWriteV2Stats)
+		if isRemoteWriteV2 {
+			reqV2 := &mimirpb.PreallocWriteRequestV2{PreallocWriteRequest: req}
+			if userID, err := tenant.TenantID(ctx); err == nil && limits != nil {
+				// Zero samples at the created timestamp of a series are ingested only when they're within the
+				// out-of-order time window, where the ingesters drop the duplicates sent with following requests.
+				reqV2.CreatedTimestampZeroSampleWindowMs = time.Duration(limits.OutOfOrderTimeWindow(userID)).Milliseconds()
+			}
+			defer func() {
+				stats.samples, stats.histograms, stats.exemplars = reqV2.Samples, reqV2.Histograms, reqV2.Exemplars
+			}()
+			msg = reqV2
+		}
+
+		body, receivedBytes := countRequestBody(r.Body)
+		res, err := util.ParseProtoReader(ctx, body, int(r.ContentLength), maxRecvMsgSize, dst, msg, compression)
+		var tooLargeErr util.MsgSizeTooLargeErr
+		if errors.As(err, &tooLargeErr) {
+			actual := int(r.ContentLength)
+			if tooLargeErr.Actual < 0 {
+				// The decompressed body is too large, and its size is unknown.
+				actual = -1
+			}
+			err = distributorMaxWriteMessageSizeErr{actual: actual, limit: maxRecvMsgSize}
+		}
+		if err == nil {
+			encodingMetrics.observe(encoding, receivedBytes(), len(res))
 		}
 		return res, err
 	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protoMessage, err := remoteWriteProtoMessage(r.Header.Get("Content-Type"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		if protoMessage == mimirpb.RemoteWriteV1ProtoMessage {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		stats := &remoteWriteV2Stats{}
+		tw := &noContentOnSuccessWriter{ResponseWriter: w}
+		h.ServeHTTP(tw, r.WithContext(context.WithValue(r.Context(), remoteWriteV2StatsContextKey{}, stats)))
+		if !tw.wroteHeader {
+			// The request succeeded: report what has been written and respond with 204 No Content,
+			// as required by the remote-write 2.0 specification.
+			w.Header().Set(remoteWriteSamplesWrittenHeader, strconv.Itoa(stats.samples))
+			w.Header().Set(remoteWriteHistogramsWrittenHeader, strconv.Itoa(stats.histograms))
+			w.Header().Set(remoteWriteExemplarsWrittenHeader, strconv.Itoa(stats.exemplars))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+const (
+	remoteWriteProtoContentType        = "application/x-protobuf"
+	remoteWriteSamplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	remoteWriteHistogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	remoteWriteExemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
+)
+
+// remoteWriteV2StatsContextKey is the context key of the remoteWriteV2Stats of a remote-write 2.0 request.
+type remoteWriteV2StatsContextKey struct{}
+
+// remoteWriteV2Stats holds the number of samples, histograms and exemplars decoded from a remote-write 2.0 request.
+type remoteWriteV2Stats struct {
+	samples, histograms, exemplars int
+}
+
+// remoteWriteProtoMessage returns the remote-write protobuf message negotiated with the request content type.
+// Requests which are not explicitly remote-write 2.0 are handled as remote-write 1.0, for backwards compatibility.
+func remoteWriteProtoMessage(contentType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != remoteWriteProtoContentType {
+		return mimirpb.RemoteWriteV1ProtoMessage, nil
+	}
+
+	switch params["proto"] {
+	case "", mimirpb.RemoteWriteV1ProtoMessage:
+		return mimirpb.RemoteWriteV1ProtoMessage, nil
+	case mimirpb.RemoteWriteV2ProtoMessage:
+		return mimirpb.RemoteWriteV2ProtoMessage, nil
+	default:
+		return "", fmt.Errorf("unsupported remote-write protobuf message %q, supported: [%s, %s]", params["proto"], mimirpb.RemoteWriteV1ProtoMessage, mimirpb.RemoteWriteV2ProtoMessage)
+	}
 }
 
 type distributorMaxWriteMessageSizeErr struct {
@@ -145,6 +255,196 @@ func handler(
 	})
 }
 
+// readPushBody reads the whole body of a push request, optionally compressed with gzip or zstd, and fails
+// if either the compressed or the decompressed body is larger than maxRecvMsgSize.
+// The body is decompressed into dst, if it's large enough.
+func readPushBody(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, encodingMetrics *pushEncodingMetrics) ([]byte, error) {
+	compression, encoding := util.NoCompression, "none"
+	switch contentEncoding := r.Header.Get("Content-Encoding"); contentEncoding {
+	case "":
+	case "gzip":
+		compression, encoding = util.Gzip, contentEncoding
+	case "zstd":
+		compression, encoding = util.Zstd, contentEncoding
+	default:
+		return nil, httpgrpc.Errorf(http.StatusUnsupportedMediaType, "unsupported compression: %s. Only \"gzip\", \"zstd\" or no compression supported", contentEncoding)
+	}
+
+	reader, receivedBytes := countRequestBody(r.Body)
+	body, err := util.DecompressRequest(ctx, reader, int(r.ContentLength), maxRecvMsgSize, dst, compression)
+	if err != nil {
+		r.Body.Close()
+
+		var tooLargeErr util.MsgSizeTooLargeErr
+		if errors.As(err, &tooLargeErr) {
+			return body, httpgrpc.Errorf(http.StatusRequestEntityTooLarge, distributorMaxWriteMessageSizeErr{actual: tooLargeErr.Actual, limit: maxRecvMsgSize}.Error())
+		}
+
+		return body, err
+	}
+
+	encodingMetrics.observe(encoding, receivedBytes(), len(body))
+	return body, r.Body.Close()
+}
+
+// pushEncodingMetrics tracks the push requests received by a handler, and the size of their bodies before
+// and after the decompression, by content encoding.
+type pushEncodingMetrics struct {
+	requests          *prometheus.CounterVec
+	receivedBytes     *prometheus.CounterVec
+	decompressedBytes *prometheus.CounterVec
+}
+
+func newPushEncodingMetrics(reg prometheus.Registerer, handler string) *pushEncodingMetrics {
+	constLabels := prometheus.Labels{"handler": handler}
+	return &pushEncodingMetrics{
+		requests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name:        "cortex_distributor_push_requests_by_encoding_total",
+			Help:        "Number of push requests whose body has been successfully read, by content encoding.",
+			ConstLabels: constLabels,
+		}, []string{"encoding"}),
+		receivedBytes: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name:        "cortex_distributor_push_received_bytes_total",
+			Help:        "Total size of the push request bodies as received, before decompression, by content encoding.",
+			ConstLabels: constLabels,
+		}, []string{"encoding"}),
+		decompressedBytes: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name:        "cortex_distributor_push_decompressed_bytes_total",
+			Help:        "Total size of the push request bodies after decompression, by content encoding.",
+			ConstLabels: constLabels,
+		}, []string{"encoding"}),
+	}
+}
+
+func (m *pushEncodingMetrics) observe(encoding string, receivedBytes, decompressedBytes int) {
+	m.requests.WithLabelValues(encoding).Inc()
+	m.receivedBytes.WithLabelValues(encoding).Add(float64(receivedBytes))
+	m.decompressedBytes.WithLabelValues(encoding).Add(float64(decompressedBytes))
+}
+
+// countingReader counts the bytes read from the wrapped io.Reader.
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += n
+	return n, err
+}
+
+// countRequestBody returns a reader of the request body, along with a function returning the number of bytes
+// read from it. Bodies backed by a *bytes.Buffer, like the ones of requests received via httpgrpc, are returned
+// as is, so that they can still be read without copying.
+func countRequestBody(body io.Reader) (io.Reader, func() int) {
+	if b, ok := body.(interface{ BytesBuffer() *bytes.Buffer }); ok && b != nil {
+		size := b.BytesBuffer().Len()
+		return body, func() int { return size }
+	}
+
+	c := &countingReader{Reader: body}
+	return c, func() int { return c.n }
+}
+
+// noContentOnSuccessWriter tracks whether a status code has been written to the wrapped http.ResponseWriter.
+type noContentOnSuccessWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *noContentOnSuccessWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *noContentOnSuccessWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// noContentOnSuccess wraps h to respond with 204 No Content, instead of 200 OK, when h succeeds without writing a response.
+func noContentOnSuccess(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nw := &noContentOnSuccessWriter{ResponseWriter: w}
+		h.ServeHTTP(nw, r)
+		if !nw.wroteHeader {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// sanitizeName replaces all characters which are not valid in a Prometheus metric name
+// (or label name, if isMetricName is false) with an underscore.
+func sanitizeName(name string, isMetricName bool) string {
+	valid := func(i int, b byte) bool {
+		return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b == '_' || (isMetricName && b == ':') || (b >= '0' && b <= '9' && i > 0)
+	}
+
+	needsSanitizing := false
+	for i := 0; i < len(name); i++ {
+		if !valid(i, name[i]) {
+			needsSanitizing = true
+			break
+		}
+	}
+	if !needsSanitizing {
+		return name
+	}
+
+	sb := strings.Builder{}
+	sb.Grow(len(name) + 1)
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		sb.WriteByte('_')
+	}
+	for i := 0; i < len(name); i++ {
+		if b := name[i]; valid(i, b) || (b >= '0' && b <= '9') {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// timeseriesBuilder groups samples converted from other ingestion protocols into series.
+type timeseriesBuilder struct {
+	seriesIdx  map[string]int
+	timeseries []mimirpb.PreallocTimeseries
+	keyBuf     strings.Builder
+}
+
+func newTimeseriesBuilder() *timeseriesBuilder {
+	return &timeseriesBuilder{
+		seriesIdx:  map[string]int{},
+		timeseries: mimirpb.PreallocTimeseriesSliceFromPool(),
+	}
+}
+
+// add appends the sample to the series identified by labels. The labels get sorted by name.
+func (b *timeseriesBuilder) add(labels []mimirpb.LabelAdapter, sample mimirpb.Sample) {
+	slices.SortFunc(labels, func(a, b mimirpb.LabelAdapter) int { return strings.Compare(a.Name, b.Name) })
+
+	b.keyBuf.Reset()
+	for _, l := range labels {
+		b.keyBuf.WriteString(l.Name)
+		b.keyBuf.WriteByte('\xff')
+		b.keyBuf.WriteString(l.Value)
+		b.keyBuf.WriteByte('\xff')
+	}
+	key := b.keyBuf.String()
+
+	idx, ok := b.seriesIdx[key]
+	if !ok {
+		series := mimirpb.TimeseriesFromPool()
+		series.Labels = labels
+		b.timeseries = append(b.timeseries, mimirpb.PreallocTimeseries{TimeSeries: series})
+		idx = len(b.timeseries) - 1
+		b.seriesIdx[key] = idx
+	}
+	b.timeseries[idx].Samples = append(b.timeseries[idx].Samples, sample)
+}
+
 // toHTTPStatus converts the given error into an appropriate HTTP status corresponding
 // to that error, if the error is one of the errors from this package. Otherwise, an
 // http.StatusInternalServerError is returned.
@@ -12,16 +12,23 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/snappy"
+	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/middleware"
 	"github.com/grafana/dskit/tenant"
 	"github.com/grafana/dskit/user"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage/remote"
@@ -31,6 +38,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util/log"
@@ -41,11 +49,77 @@ import (
 func TestHandler_remoteWrite(t *testing.T) {
 	req := createRequest(t, createPrometheusRemoteWriteProtobuf(t))
 	resp := httptest.NewRecorder()
-	handler := Handler(100000, nil, false, nil, verifyWritePushFunc(t, mimirpb.API))
+	handler := Handler(100000, nil, false, nil, nil, verifyWritePushFunc(t, mimirpb.API))
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, 200, resp.Code)
 }
 
+func TestHandler_remoteWriteV2(t *testing.T) {
+	tests := map[string]struct {
+		contentType        string
+		expectedCode       int
+		expectedRemoteV2   bool
+		expectedSamplesHdr string
+	}{
+		"remote-write 2.0": {
+			contentType:        "application/x-protobuf;proto=io.prometheus.write.v2.Request",
+			expectedCode:       http.StatusNoContent,
+			expectedRemoteV2:   true,
+			expectedSamplesHdr: "1",
+		},
+		"unsupported protobuf message": {
+			contentType:  "application/x-protobuf;proto=io.prometheus.write.v3.Request",
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := createRequest(t, createPrometheusRemoteWriteV2Protobuf())
+			req.Header.Set("Content-Type", tc.contentType)
+			resp := httptest.NewRecorder()
+
+			handler := Handler(100000, nil, false, nil, nil, func(ctx context.Context, pushReq *Request) error {
+				defer pushReq.CleanUp()
+				request, err := pushReq.WriteRequest()
+				if err != nil {
+					return err
+				}
+				require.Len(t, request.Timeseries, 1)
+				assert.Equal(t, []mimirpb.LabelAdapter{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "bar"}}, request.Timeseries[0].Labels)
+				assert.Equal(t, []mimirpb.Sample{{TimestampMs: 1000, Value: 1}}, request.Timeseries[0].Samples)
+				assert.Equal(t, []*mimirpb.MetricMetadata{{Type: mimirpb.COUNTER, MetricFamilyName: "foo", Help: "bar"}}, request.Metadata)
+				return nil
+			})
+			handler.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.Equal(t, tc.expectedSamplesHdr, resp.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+			if tc.expectedRemoteV2 {
+				assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Histograms-Written"))
+				assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+			}
+		})
+	}
+}
+
+func TestRemoteWriteProtoMessage(t *testing.T) {
+	for contentType, expected := range map[string]string{
+		"":                       mimirpb.RemoteWriteV1ProtoMessage,
+		"application/x-protobuf": mimirpb.RemoteWriteV1ProtoMessage,
+		"application/x-protobuf;proto=prometheus.WriteRequest":         mimirpb.RemoteWriteV1ProtoMessage,
+		"application/x-protobuf; proto=io.prometheus.write.v2.Request": mimirpb.RemoteWriteV2ProtoMessage,
+		"text/plain;proto=io.prometheus.write.v2.Request":              mimirpb.RemoteWriteV1ProtoMessage,
+	} {
+		actual, err := remoteWriteProtoMessage(contentType)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, contentType)
+	}
+
+	_, err := remoteWriteProtoMessage("application/x-protobuf;proto=unknown")
+	assert.EqualError(t, err, `unsupported remote-write protobuf message "unknown", supported: [prometheus.WriteRequest, io.prometheus.write.v2.Request]`)
+}
+
 func TestOtelMetricsToMetadata(t *testing.T) {
 	otelMetrics := pmetric.NewMetrics()
 	rs := otelMetrics.ResourceMetrics().AppendEmpty()
@@ -212,7 +286,7 @@ func TestHandlerOTLPPush(t *testing.T) {
 				return err
 			},
 			responseCode: http.StatusUnsupportedMediaType,
-			errMessage:   "Only \"gzip\" or no compression supported",
+			errMessage:   "Only \"gzip\", \"zstd\" or no compression supported",
 		},
 		{
 			name:       "Write histograms",
@@ -398,6 +472,81 @@ func TestHandler_otlpDroppedMetricsPanic2(t *testing.T) {
 	assert.Equal(t, 200, resp.Code)
 }
 
+func TestHandler_otlpResourceAttributes(t *testing.T) {
+	md := pmetric.NewMetrics()
+	resource := md.ResourceMetrics().AppendEmpty()
+	resource.Resource().Attributes().PutStr("service.name", "checkout")
+	resource.Resource().Attributes().PutStr("k8s.namespace.name", "prod")
+	resource.Resource().Attributes().PutStr("region", "us-central1")
+
+	metric := resource.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("foo")
+	metric.SetEmptyGauge()
+	datapoint := metric.Gauge().DataPoints().AppendEmpty()
+	datapoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+	datapoint.SetDoubleValue(1)
+	datapoint.Attributes().PutStr("region", "eu-west1")
+
+	tests := map[string]struct {
+		promote           []string
+		disableTargetInfo bool
+		expectedLabels    [][]mimirpb.LabelAdapter
+	}{
+		"defaults": {
+			expectedLabels: [][]mimirpb.LabelAdapter{
+				{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "checkout"}, {Name: "region", Value: "eu-west1"}},
+				{{Name: "__name__", Value: "target_info"}, {Name: "job", Value: "checkout"}, {Name: "k8s_namespace_name", Value: "prod"}, {Name: "region", Value: "us-central1"}},
+			},
+		},
+		"promoted resource attributes, data point attributes take precedence": {
+			promote: []string{"k8s.namespace.name", "region", "missing"},
+			expectedLabels: [][]mimirpb.LabelAdapter{
+				{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "checkout"}, {Name: "k8s_namespace_name", Value: "prod"}, {Name: "region", Value: "eu-west1"}},
+				{{Name: "__name__", Value: "target_info"}, {Name: "job", Value: "checkout"}, {Name: "k8s_namespace_name", Value: "prod"}, {Name: "region", Value: "us-central1"}},
+			},
+		},
+		"target_info disabled": {
+			disableTargetInfo: true,
+			expectedLabels: [][]mimirpb.LabelAdapter{
+				{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "checkout"}, {Name: "region", Value: "eu-west1"}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limits := validation.Limits{}
+			flagext.DefaultValues(&limits)
+			limits.OTelPromoteResourceAttributes = tc.promote
+			limits.OTelDisableTargetInfo = tc.disableTargetInfo
+			overrides, err := validation.NewOverrides(limits, nil)
+			require.NoError(t, err)
+
+			// Each request gets its own copy, because the promotion modifies the metrics in place.
+			metrics := pmetric.NewMetrics()
+			md.CopyTo(metrics)
+
+			req := createOTLPRequest(t, pmetricotlp.NewExportRequestFromMetrics(metrics), false)
+			resp := httptest.NewRecorder()
+			handler := OTLPHandler(100000, nil, false, false, overrides, nil, func(ctx context.Context, pushReq *Request) error {
+				defer pushReq.CleanUp()
+				request, err := pushReq.WriteRequest()
+				require.NoError(t, err)
+
+				var actual [][]mimirpb.LabelAdapter
+				for _, ts := range request.Timeseries {
+					actual = append(actual, ts.Labels)
+				}
+				sort.Slice(actual, func(i, j int) bool { return actual[i][0].Value < actual[j][0].Value })
+				assert.Equal(t, tc.expectedLabels, actual)
+				return nil
+			})
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+		})
+	}
+}
+
 func TestHandler_otlpWriteRequestTooBigWithCompression(t *testing.T) {
 
 	// createOTLPRequest will create a request which is BIGGER with compression (37 vs 58 bytes).
@@ -415,19 +564,139 @@ func TestHandler_otlpWriteRequestTooBigWithCompression(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handler := OTLPHandler(140, nil, false, true, nil, nil, readBodyPushFunc(t))
+	handler := OTLPHandler(1000, nil, false, true, nil, nil, readBodyPushFunc(t))
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
 	body, err := io.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.Contains(t, string(body), "the incoming push request has been rejected because its message size is larger than the allowed limit of 140 bytes (err-mimir-distributor-max-write-message-size). To adjust the related limit, configure -distributor.max-recv-msg-size, or contact your service administrator.")
+	assert.Contains(t, string(body), "the incoming push request has been rejected because its message size is larger than the allowed limit of 1000 bytes (err-mimir-distributor-max-write-message-size). To adjust the related limit, configure -distributor.max-recv-msg-size, or contact your service administrator.")
+}
+
+func TestHandler_pushCompression(t *testing.T) {
+	remoteWriteBody := createPrometheusRemoteWriteProtobuf(t)
+	otlpBody, err := TimeseriesToOTLPRequest([]prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "foo"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+	}}, []mimirpb.MetricMetadata{{Help: "foo"}}).MarshalProto()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		otlp             bool
+		encoding         string
+		body             []byte
+		maxMsgSize       int
+		expectedCode     int
+		expectedEncoding string
+	}{
+		"remote write without content encoding": {
+			body:             snappy.Encode(nil, remoteWriteBody),
+			expectedCode:     http.StatusOK,
+			expectedEncoding: "snappy",
+		},
+		"remote write with gzip": {
+			encoding:         "gzip",
+			body:             compressWithGzip(t, remoteWriteBody),
+			expectedCode:     http.StatusOK,
+			expectedEncoding: "gzip",
+		},
+		"remote write with zstd": {
+			encoding:         "zstd",
+			body:             compressWithZstd(t, remoteWriteBody),
+			expectedCode:     http.StatusOK,
+			expectedEncoding: "zstd",
+		},
+		"remote write with zstd decompressed size too large": {
+			encoding:     "zstd",
+			body:         compressWithZstd(t, make([]byte, 10000)),
+			maxMsgSize:   1000,
+			expectedCode: http.StatusBadRequest,
+		},
+		"remote write with unsupported encoding": {
+			encoding:     "br",
+			body:         remoteWriteBody,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		"OTLP with zstd": {
+			otlp:             true,
+			encoding:         "zstd",
+			body:             compressWithZstd(t, otlpBody),
+			expectedCode:     http.StatusOK,
+			expectedEncoding: "zstd",
+		},
+		"OTLP with zstd decompressed size too large": {
+			otlp:         true,
+			encoding:     "zstd",
+			body:         compressWithZstd(t, make([]byte, 10000)),
+			maxMsgSize:   1000,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.maxMsgSize == 0 {
+				tc.maxMsgSize = 100000
+			}
+
+			req, err := http.NewRequest("POST", "http://localhost/", bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			if tc.encoding != "" {
+				req.Header.Set("Content-Encoding", tc.encoding)
+			}
+			req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+
+			reg := prometheus.NewPedanticRegistry()
+			handlerName := "prometheus"
+			var handler http.Handler
+			if tc.otlp {
+				handlerName = "otlp"
+				handler = OTLPHandler(tc.maxMsgSize, nil, false, false, nil, reg, readBodyPushFunc(t))
+			} else {
+				handler = Handler(tc.maxMsgSize, nil, false, nil, reg, readBodyPushFunc(t))
+			}
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, tc.expectedCode, resp.Code, resp.Body.String())
+
+			expectedMetrics := ""
+			if tc.expectedEncoding != "" {
+				expectedMetrics = fmt.Sprintf(`
+					# HELP cortex_distributor_push_requests_by_encoding_total Number of push requests whose body has been successfully read, by content encoding.
+					# TYPE cortex_distributor_push_requests_by_encoding_total counter
+					cortex_distributor_push_requests_by_encoding_total{encoding="%[1]s",handler="%[2]s"} 1
+					# HELP cortex_distributor_push_received_bytes_total Total size of the push request bodies as received, before decompression, by content encoding.
+					# TYPE cortex_distributor_push_received_bytes_total counter
+					cortex_distributor_push_received_bytes_total{encoding="%[1]s",handler="%[2]s"} %[3]d
+				`, tc.expectedEncoding, handlerName, len(tc.body))
+			}
+			assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expectedMetrics), "cortex_distributor_push_requests_by_encoding_total", "cortex_distributor_push_received_bytes_total"))
+		})
+	}
+}
+
+func compressWithGzip(t testing.TB, data []byte) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	_, err := gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return b.Bytes()
+}
+
+func compressWithZstd(t testing.TB, data []byte) []byte {
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer enc.Close()
+	return enc.EncodeAll(data, nil)
 }
 
 func TestHandler_mimirWriteRequest(t *testing.T) {
 	req := createRequest(t, createMimirWriteRequestProtobuf(t, false))
 	resp := httptest.NewRecorder()
 	sourceIPs, _ := middleware.NewSourceIPs("SomeField", "(.*)")
-	handler := Handler(100000, sourceIPs, false, nil, verifyWritePushFunc(t, mimirpb.RULE))
+	handler := Handler(100000, sourceIPs, false, nil, nil, verifyWritePushFunc(t, mimirpb.RULE))
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, 200, resp.Code)
 }
@@ -436,7 +705,7 @@ func TestHandler_contextCanceledRequest(t *testing.T) {
 	req := createRequest(t, createMimirWriteRequestProtobuf(t, false))
 	resp := httptest.NewRecorder()
 	sourceIPs, _ := middleware.NewSourceIPs("SomeField", "(.*)")
-	handler := Handler(100000, sourceIPs, false, nil, func(_ context.Context, req *Request) error {
+	handler := Handler(100000, sourceIPs, false, nil, nil, func(_ context.Context, req *Request) error {
 		defer req.CleanUp()
 		return fmt.Errorf("the request failed: %w", context.Canceled)
 	})
@@ -545,7 +814,7 @@ func TestHandler_EnsureSkipLabelNameValidationBehaviour(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			resp := httptest.NewRecorder()
-			handler := Handler(100000, nil, tc.allowSkipLabelNameValidation, nil, tc.verifyReqHandler)
+			handler := Handler(100000, nil, tc.allowSkipLabelNameValidation, nil, nil, tc.verifyReqHandler)
 			if !tc.includeAllowSkiplabelNameValidationHeader {
 				tc.req.Header.Set(SkipLabelNameValidationHeader, "true")
 			}
@@ -645,6 +914,36 @@ func createPrometheusRemoteWriteProtobuf(t testing.TB) []byte {
 	return inputBytes
 }
 
+// createPrometheusRemoteWriteV2Protobuf encodes an io.prometheus.write.v2.Request with a single counter series.
+func createPrometheusRemoteWriteV2Protobuf() []byte {
+	appendMessage := func(b []byte, num protowire.Number, msg []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, msg)
+	}
+
+	var refs, sample, metadata, series, req []byte
+	for _, ref := range []uint64{1, 2, 3, 4} {
+		refs = protowire.AppendVarint(refs, ref)
+	}
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(1))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, 1000)
+	metadata = protowire.AppendTag(metadata, 1, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, uint64(mimirpb.COUNTER))
+	metadata = protowire.AppendTag(metadata, 3, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, 4)
+
+	series = appendMessage(series, 1, refs)
+	series = appendMessage(series, 2, sample)
+	series = appendMessage(series, 5, metadata)
+
+	for _, symbol := range []string{"", "__name__", "foo", "job", "bar"} {
+		req = appendMessage(req, 4, []byte(symbol))
+	}
+	return appendMessage(req, 5, series)
+}
+
 func createMimirWriteRequestProtobuf(t *testing.T, skipLabelNameValidation bool) []byte {
 	t.Helper()
 	h := remote.HistogramToHistogramProto(1337, test.GenerateTestHistogram(1))
@@ -702,7 +1001,7 @@ func BenchmarkPushHandler(b *testing.B) {
 		pushReq.CleanUp()
 		return nil
 	}
-	handler := Handler(100000, nil, false, nil, pushFunc)
+	handler := Handler(100000, nil, false, nil, nil, pushFunc)
 	b.ResetTimer()
 	for iter := 0; iter < b.N; iter++ {
 		req.Body = bufCloser{Buffer: buf} // reset Body so it can be read each time round the loop
@@ -720,6 +1019,14 @@ type bufCloser struct {
 func (bufCloser) Close() error                 { return nil }
 func (n bufCloser) BytesBuffer() *bytes.Buffer { return n.Buffer }
 
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "cpu_usage", sanitizeName("cpu_usage", true))
+	assert.Equal(t, "cpu:usage", sanitizeName("cpu:usage", true))
+	assert.Equal(t, "cpu_usage", sanitizeName("cpu:usage", false))
+	assert.Equal(t, "disk_io_bytes", sanitizeName("disk io.bytes", true))
+	assert.Equal(t, "_1m_load", sanitizeName("1m-load", true))
+}
+
 func TestNewDistributorMaxWriteMessageSizeErr(t *testing.T) {
 	err := distributorMaxWriteMessageSizeErr{actual: 100, limit: 50}
 	msg := `the incoming push request has been rejected because its message size of 100 bytes is larger than the allowed limit of 50 bytes (err-mimir-distributor-max-write-message-size). To adjust the related limit, configure -distributor.max-recv-msg-size, or contact your service administrator.`
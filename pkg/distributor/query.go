@@ -33,6 +33,8 @@ import (
 var (
 	// readNoExtend is a ring.Operation that only selects instances marked as ring.ACTIVE.
 	// This should mirror the operation used when choosing ingesters to write series to (ring.WriteNoExtend).
+	// Unlike ring.WriteNoExtend, it also selects the ACTIVE instances in read-only mode, which don't
+	// receive writes anymore but still hold series that may not have been shipped to the long-term storage yet.
 	readNoExtend = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
 )
 
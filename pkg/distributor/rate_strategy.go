@@ -7,6 +7,7 @@ package distributor
 
 import (
 	"math"
+	"strings"
 
 	"github.com/grafana/dskit/limiter"
 	"golang.org/x/time/rate"
@@ -94,6 +95,55 @@ func (s *ingestionRateStrategy) Burst(tenantID string) int {
 	return s.limits.IngestionBurstSize(tenantID)
 }
 
+// labelSetIngestionRateStrategy is the strategy of the per-label-set ingestion rate limits. The rate limiter
+// is keyed by labelSetRateLimiterKey, since each tenant can have multiple per-label-set limits.
+type labelSetIngestionRateStrategy struct {
+	limits *validation.Overrides
+}
+
+func newLabelSetIngestionRateStrategy(limits *validation.Overrides) limiter.RateLimiterStrategy {
+	return &labelSetIngestionRateStrategy{
+		limits: limits,
+	}
+}
+
+func (s *labelSetIngestionRateStrategy) Limit(key string) float64 {
+	if limit := s.labelSetLimit(key); limit != nil && limit.IngestionRate > 0 {
+		return limit.IngestionRate
+	}
+	return float64(rate.Inf)
+}
+
+func (s *labelSetIngestionRateStrategy) Burst(key string) int {
+	if limit := s.labelSetLimit(key); limit != nil && limit.IngestionRate > 0 {
+		return limit.IngestionBurstSize
+	}
+	// Burst is ignored when limit = rate.Inf
+	return 0
+}
+
+func (s *labelSetIngestionRateStrategy) labelSetLimit(key string) *validation.LabelSetLimit {
+	userID, selector, ok := strings.Cut(key, labelSetRateLimiterKeySeparator)
+	if !ok {
+		return nil
+	}
+	for _, limit := range s.limits.LabelSetLimits(userID) {
+		if limit.Selector == selector {
+			return limit
+		}
+	}
+	return nil
+}
+
+// labelSetRateLimiterKeySeparator can't be part of a tenant ID, so it's safe to use it to separate
+// the tenant ID from the selector.
+const labelSetRateLimiterKeySeparator = "\xff"
+
+// labelSetRateLimiterKey returns the key of the per-label-set ingestion rate limiter for the given tenant and selector.
+func labelSetRateLimiterKey(userID, selector string) string {
+	return userID + labelSetRateLimiterKeySeparator + selector
+}
+
 type infiniteStrategy struct{}
 
 func newInfiniteRateStrategy() limiter.RateLimiterStrategy {
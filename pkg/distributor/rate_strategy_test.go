@@ -33,6 +33,28 @@ func TestIngestionRateStrategy(t *testing.T) {
 		assert.Equal(t, strategy.Burst("test"), 10000)
 	})
 
+	t.Run("per-label-set rate limiter should share the limit of the matching selector across the number of distributors", func(t *testing.T) {
+		overrides, err := validation.NewOverrides(validation.Limits{
+			LabelSetLimits: []*validation.LabelSetLimit{
+				{Selector: `{namespace="payments"}`, IngestionRate: 1000, IngestionBurstSize: 10000},
+				{Selector: `{namespace="orders"}`, MaxSeries: 100},
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		mockRing := newReadLifecyclerMock()
+		mockRing.On("HealthyInstancesCount").Return(2)
+
+		strategy := newGlobalRateStrategy(newLabelSetIngestionRateStrategy(overrides), mockRing)
+		assert.Equal(t, float64(500), strategy.Limit(labelSetRateLimiterKey("test", `{namespace="payments"}`)))
+		assert.Equal(t, 10000, strategy.Burst(labelSetRateLimiterKey("test", `{namespace="payments"}`)))
+
+		// Selectors without an ingestion rate, or not configured, are unlimited.
+		assert.Equal(t, float64(rate.Inf), strategy.Limit(labelSetRateLimiterKey("test", `{namespace="orders"}`)))
+		assert.Equal(t, float64(rate.Inf), strategy.Limit(labelSetRateLimiterKey("test", `{namespace="unknown"}`)))
+		assert.Equal(t, float64(rate.Inf), strategy.Limit("test"))
+	})
+
 	t.Run("infinite rate limiter should return unlimited settings", func(t *testing.T) {
 		strategy := newInfiniteRateStrategy()
 
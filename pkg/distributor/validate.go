@@ -12,12 +12,14 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util/extract"
 	"github.com/grafana/mimir/pkg/util/globalerror"
+	util_log "github.com/grafana/mimir/pkg/util/log"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
 
@@ -25,6 +27,9 @@ const (
 	// The combined length of the label names and values of an Exemplar's LabelSet MUST NOT exceed 128 UTF-8 characters
 	// https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#exemplars
 	ExemplarMaxLabelSetLength = 128
+
+	// dryRunLogSampleFreq is the frequency of the logged limit violations in dry-run mode.
+	dryRunLogSampleFreq = 100
 )
 
 var (
@@ -55,9 +60,19 @@ var (
 	// Declared here to avoid duplication in ingester and distributor.
 	reasonRateLimited = "rate_limited" // same for request and ingestion which are separate errors, so not using metricReasonFromErrorID with global error
 
+	// reasonLabelSetRateLimited is the reason for discarding samples exceeding a per-label-set ingestion rate limit.
+	reasonLabelSetRateLimited = "label_set_rate_limited"
+
 	// reasonTooManyHAClusters is one of the reasons for discarding samples.
 	reasonTooManyHAClusters = "too_many_ha_clusters"
 
+	// reasonMetricRelabelConfigs is the reason for the series dropped by the metric relabel configs, only tracked in
+	// dry-run mode.
+	reasonMetricRelabelConfigs = "metric_relabel_configs"
+
+	// dryRunLogSampler samples the logged limit violations in dry-run mode.
+	dryRunLogSampler = util_log.NewSampler(dryRunLogSampleFreq)
+
 	labelNameTooLongMsgFormat = globalerror.SeriesLabelNameTooLong.MessageWithPerTenantLimitConfig(
 		"received a series whose label name length exceeds the limit, label: '%.200s' series: '%.200s'",
 		validation.MaxLabelNameLengthFlag,
@@ -107,6 +122,7 @@ var (
 type sampleValidationConfig interface {
 	CreationGracePeriod(userID string) time.Duration
 	MaxNativeHistogramBuckets(userID string) int
+	LimitsDryRun(userID string) bool
 }
 
 // sampleValidationMetrics is a collection of metrics used during sample validation.
@@ -120,6 +136,12 @@ type sampleValidationMetrics struct {
 	maxNativeHistogramBuckets *prometheus.CounterVec
 	duplicateLabelNames       *prometheus.CounterVec
 	tooFarInFuture            *prometheus.CounterVec
+
+	// Samples which would have been discarded if the limits weren't in dry-run mode.
+	dryRunMaxLabelNamesPerSeries    *prometheus.CounterVec
+	dryRunLabelNameTooLong          *prometheus.CounterVec
+	dryRunLabelValueTooLong         *prometheus.CounterVec
+	dryRunMaxNativeHistogramBuckets *prometheus.CounterVec
 }
 
 func (m *sampleValidationMetrics) deleteUserMetrics(userID string) {
@@ -133,6 +155,10 @@ func (m *sampleValidationMetrics) deleteUserMetrics(userID string) {
 	m.maxNativeHistogramBuckets.DeletePartialMatch(filter)
 	m.duplicateLabelNames.DeletePartialMatch(filter)
 	m.tooFarInFuture.DeletePartialMatch(filter)
+	m.dryRunMaxLabelNamesPerSeries.DeletePartialMatch(filter)
+	m.dryRunLabelNameTooLong.DeletePartialMatch(filter)
+	m.dryRunLabelValueTooLong.DeletePartialMatch(filter)
+	m.dryRunMaxNativeHistogramBuckets.DeletePartialMatch(filter)
 }
 
 func (m *sampleValidationMetrics) deleteUserMetricsForGroup(userID, group string) {
@@ -145,6 +171,10 @@ func (m *sampleValidationMetrics) deleteUserMetricsForGroup(userID, group string
 	m.maxNativeHistogramBuckets.DeleteLabelValues(userID, group)
 	m.duplicateLabelNames.DeleteLabelValues(userID, group)
 	m.tooFarInFuture.DeleteLabelValues(userID, group)
+	m.dryRunMaxLabelNamesPerSeries.DeleteLabelValues(userID, group)
+	m.dryRunLabelNameTooLong.DeleteLabelValues(userID, group)
+	m.dryRunLabelValueTooLong.DeleteLabelValues(userID, group)
+	m.dryRunMaxNativeHistogramBuckets.DeleteLabelValues(userID, group)
 }
 
 func newSampleValidationMetrics(r prometheus.Registerer) *sampleValidationMetrics {
@@ -158,6 +188,11 @@ func newSampleValidationMetrics(r prometheus.Registerer) *sampleValidationMetric
 		maxNativeHistogramBuckets: validation.DiscardedSamplesCounter(r, reasonMaxNativeHistogramBuckets),
 		duplicateLabelNames:       validation.DiscardedSamplesCounter(r, reasonDuplicateLabelNames),
 		tooFarInFuture:            validation.DiscardedSamplesCounter(r, reasonTooFarInFuture),
+
+		dryRunMaxLabelNamesPerSeries:    validation.DryRunDiscardedSamplesCounter(r, reasonMaxLabelNamesPerSeries),
+		dryRunLabelNameTooLong:          validation.DryRunDiscardedSamplesCounter(r, reasonLabelNameTooLong),
+		dryRunLabelValueTooLong:         validation.DryRunDiscardedSamplesCounter(r, reasonLabelValueTooLong),
+		dryRunMaxNativeHistogramBuckets: validation.DryRunDiscardedSamplesCounter(r, reasonMaxNativeHistogramBuckets),
 	}
 }
 
@@ -222,8 +257,8 @@ func validateSampleHistogram(m *sampleValidationMetrics, now model.Time, cfg sam
 			bucketCount = len(s.GetNegativeDeltas()) + len(s.GetPositiveDeltas())
 		}
 		if bucketCount > bucketLimit {
-			m.maxNativeHistogramBuckets.WithLabelValues(userID, group).Inc()
-			return fmt.Errorf(maxNativeHistogramBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), bucketCount, bucketLimit)
+			err := fmt.Errorf(maxNativeHistogramBucketsMsgFormat, s.Timestamp, mimirpb.FromLabelAdaptersToLabels(ls).String(), bucketCount, bucketLimit)
+			return limitExceeded(cfg.LimitsDryRun(userID), userID, err, m.maxNativeHistogramBuckets, m.dryRunMaxNativeHistogramBuckets, userID, group)
 		}
 	}
 
@@ -292,6 +327,7 @@ type labelValidationConfig interface {
 	MaxLabelNamesPerSeries(userID string) int
 	MaxLabelNameLength(userID string) int
 	MaxLabelValueLength(userID string) int
+	LimitsDryRun(userID string) bool
 }
 
 // validateLabels returns an err if the labels are invalid.
@@ -308,27 +344,43 @@ func validateLabels(m *sampleValidationMetrics, cfg labelValidationConfig, userI
 		return fmt.Errorf(invalidMetricNameMsgFormat, unsafeMetricName)
 	}
 
+	dryRun := cfg.LimitsDryRun(userID)
+
 	numLabelNames := len(ls)
 	if numLabelNames > cfg.MaxLabelNamesPerSeries(userID) {
-		m.maxLabelNamesPerSeries.WithLabelValues(userID, group).Inc()
 		metric, ellipsis := getMetricAndEllipsis(ls)
-		return fmt.Errorf(tooManyLabelsMsgFormat, len(ls), cfg.MaxLabelNamesPerSeries(userID), metric, ellipsis)
+		err := fmt.Errorf(tooManyLabelsMsgFormat, len(ls), cfg.MaxLabelNamesPerSeries(userID), metric, ellipsis)
+		if err := limitExceeded(dryRun, userID, err, m.maxLabelNamesPerSeries, m.dryRunMaxLabelNamesPerSeries, userID, group); err != nil {
+			return err
+		}
 	}
 
 	maxLabelNameLength := cfg.MaxLabelNameLength(userID)
 	maxLabelValueLength := cfg.MaxLabelValueLength(userID)
 	lastLabelName := ""
+	// In dry-run mode, each length limit is tracked once per series.
+	labelNameTooLong, labelValueTooLong := false, false
 	for _, l := range ls {
 		if !skipLabelNameValidation && !model.LabelName(l.Name).IsValid() {
 			m.invalidLabel.WithLabelValues(userID, group).Inc()
 			return fmt.Errorf(invalidLabelMsgFormat, l.Name, formatLabelSet(ls))
-		} else if len(l.Name) > maxLabelNameLength {
-			m.labelNameTooLong.WithLabelValues(userID, group).Inc()
-			return fmt.Errorf(labelNameTooLongMsgFormat, l.Name, formatLabelSet(ls))
-		} else if len(l.Value) > maxLabelValueLength {
-			m.labelValueTooLong.WithLabelValues(userID, group).Inc()
-			return fmt.Errorf(labelValueTooLongMsgFormat, l.Value, formatLabelSet(ls))
-		} else if lastLabelName == l.Name {
+		}
+		if len(l.Name) > maxLabelNameLength && !labelNameTooLong {
+			labelNameTooLong = true
+			err := fmt.Errorf(labelNameTooLongMsgFormat, l.Name, formatLabelSet(ls))
+			if err := limitExceeded(dryRun, userID, err, m.labelNameTooLong, m.dryRunLabelNameTooLong, userID, group); err != nil {
+				return err
+			}
+		}
+		if len(l.Value) > maxLabelValueLength && !labelValueTooLong {
+			labelValueTooLong = true
+			err := fmt.Errorf(labelValueTooLongMsgFormat, l.Value, formatLabelSet(ls))
+			if err := limitExceeded(dryRun, userID, err, m.labelValueTooLong, m.dryRunLabelValueTooLong, userID, group); err != nil {
+				return err
+			}
+		}
+
+		if lastLabelName == l.Name {
 			m.duplicateLabelNames.WithLabelValues(userID, group).Inc()
 			return fmt.Errorf(duplicateLabelMsgFormat, l.Name, formatLabelSet(ls))
 		}
@@ -338,17 +390,44 @@ func validateLabels(m *sampleValidationMetrics, cfg labelValidationConfig, userI
 	return nil
 }
 
+// limitExceeded tracks a series, sample or metadata exceeding a per-tenant limit. When the limits are enforced,
+// it increments the discarded counter and returns err. In dry-run mode, it increments the dry-run counter,
+// logs err if sampled, and returns nil so that the data is ingested.
+func limitExceeded(dryRun bool, userID string, err error, discarded, dryRunDiscarded *prometheus.CounterVec, labelValues ...string) error {
+	if !dryRun {
+		discarded.WithLabelValues(labelValues...).Inc()
+		return err
+	}
+
+	dryRunDiscarded.WithLabelValues(labelValues...).Inc()
+	logDryRunLimitExceeded(userID, err)
+	return nil
+}
+
+// logDryRunLimitExceeded logs err, caused by data exceeding a per-tenant limit in dry-run mode, if sampled.
+func logDryRunLimitExceeded(userID string, err error) {
+	if dryRunLogSampler.Sample() {
+		level.Warn(util_log.Logger).Log("msg", "limit exceeded in dry-run mode, the data has been ingested", "user", userID, "err", err, "sampled", fmt.Sprintf("1/%d", dryRunLogSampleFreq))
+	}
+}
+
 // metadataValidationMetrics is a collection of metrics used by metadata validation.
 type metadataValidationMetrics struct {
 	missingMetricName *prometheus.CounterVec
 	metricNameTooLong *prometheus.CounterVec
 	unitTooLong       *prometheus.CounterVec
+
+	// Metadata which would have been discarded if the limits weren't in dry-run mode.
+	dryRunMetricNameTooLong *prometheus.CounterVec
+	dryRunUnitTooLong       *prometheus.CounterVec
 }
 
 func (m *metadataValidationMetrics) deleteUserMetrics(userID string) {
 	m.missingMetricName.DeleteLabelValues(userID)
 	m.metricNameTooLong.DeleteLabelValues(userID)
 	m.unitTooLong.DeleteLabelValues(userID)
+	m.dryRunMetricNameTooLong.DeleteLabelValues(userID)
+	m.dryRunUnitTooLong.DeleteLabelValues(userID)
 }
 
 func newMetadataValidationMetrics(r prometheus.Registerer) *metadataValidationMetrics {
@@ -356,6 +435,9 @@ func newMetadataValidationMetrics(r prometheus.Registerer) *metadataValidationMe
 		missingMetricName: validation.DiscardedMetadataCounter(r, reasonMissingMetricName),
 		metricNameTooLong: validation.DiscardedMetadataCounter(r, reasonMetadataMetricNameTooLong),
 		unitTooLong:       validation.DiscardedMetadataCounter(r, reasonMetadataUnitTooLong),
+
+		dryRunMetricNameTooLong: validation.DryRunDiscardedMetadataCounter(r, reasonMetadataMetricNameTooLong),
+		dryRunUnitTooLong:       validation.DryRunDiscardedMetadataCounter(r, reasonMetadataUnitTooLong),
 	}
 }
 
@@ -363,6 +445,7 @@ func newMetadataValidationMetrics(r prometheus.Registerer) *metadataValidationMe
 type metadataValidationConfig interface {
 	EnforceMetadataMetricName(userID string) bool
 	MaxMetadataLength(userID string) int
+	LimitsDryRun(userID string) bool
 }
 
 // cleanAndValidateMetadata returns an err if a metric metadata is invalid.
@@ -385,16 +468,15 @@ func cleanAndValidateMetadata(m *metadataValidationMetrics, cfg metadataValidati
 		metadata.Help = metadata.Help[:newlen]
 	}
 
-	var err error
 	if len(metadata.GetMetricFamilyName()) > maxMetadataValueLength {
-		m.metricNameTooLong.WithLabelValues(userID).Inc()
-		err = fmt.Errorf(metadataMetricNameTooLongMsgFormat, "", metadata.GetMetricFamilyName())
+		err := fmt.Errorf(metadataMetricNameTooLongMsgFormat, "", metadata.GetMetricFamilyName())
+		return limitExceeded(cfg.LimitsDryRun(userID), userID, err, m.metricNameTooLong, m.dryRunMetricNameTooLong, userID)
 	} else if len(metadata.Unit) > maxMetadataValueLength {
-		m.unitTooLong.WithLabelValues(userID).Inc()
-		err = fmt.Errorf(metadataUnitTooLongMsgFormat, metadata.GetUnit(), metadata.GetMetricFamilyName())
+		err := fmt.Errorf(metadataUnitTooLongMsgFormat, metadata.GetUnit(), metadata.GetMetricFamilyName())
+		return limitExceeded(cfg.LimitsDryRun(userID), userID, err, m.unitTooLong, m.dryRunUnitTooLong, userID)
 	}
 
-	return err
+	return nil
 }
 
 // formatLabelSet formats label adapters as a metric name with labels, while preserving
@@ -27,6 +27,7 @@ type validateLabelsCfg struct {
 	maxLabelNamesPerSeries int
 	maxLabelNameLength     int
 	maxLabelValueLength    int
+	dryRun                 bool
 }
 
 func (v validateLabelsCfg) MaxLabelNamesPerSeries(_ string) int {
@@ -41,9 +42,14 @@ func (v validateLabelsCfg) MaxLabelValueLength(_ string) int {
 	return v.maxLabelValueLength
 }
 
+func (v validateLabelsCfg) LimitsDryRun(_ string) bool {
+	return v.dryRun
+}
+
 type validateMetadataCfg struct {
 	enforceMetadataMetricName bool
 	maxMetadataLength         int
+	dryRun                    bool
 }
 
 func (vm validateMetadataCfg) EnforceMetadataMetricName(_ string) bool {
@@ -54,6 +60,10 @@ func (vm validateMetadataCfg) MaxMetadataLength(_ string) int {
 	return vm.maxMetadataLength
 }
 
+func (vm validateMetadataCfg) LimitsDryRun(_ string) bool {
+	return vm.dryRun
+}
+
 func TestValidateLabels(t *testing.T) {
 	reg := prometheus.NewPedanticRegistry()
 	s := newSampleValidationMetrics(reg)
@@ -383,8 +393,57 @@ func TestValidateLabelDuplication(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestValidateLimitsDryRun(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	sampleMetrics := newSampleValidationMetrics(reg)
+	metadataMetrics := newMetadataValidationMetrics(reg)
+	userID := "testUser"
+
+	labelsCfg := validateLabelsCfg{maxLabelNamesPerSeries: 2, maxLabelNameLength: 10, maxLabelValueLength: 10, dryRun: true}
+
+	// Limits violations are only tracked.
+	assert.NoError(t, validateLabels(sampleMetrics, labelsCfg, userID, "custom label", []mimirpb.LabelAdapter{
+		{Name: model.MetricNameLabel, Value: "foo"},
+		{Name: "much_longer_name", Value: "much_longer_value"},
+		{Name: "other_longer_name", Value: "bar"},
+	}, false))
+
+	histogram := mimirpb.Histogram{PositiveSpans: []mimirpb.BucketSpan{{Offset: 0, Length: 2}}, PositiveDeltas: []int64{1, 1}}
+	assert.NoError(t, validateSampleHistogram(sampleMetrics, model.Now(), sampleValidationCfg{maxNativeHistogramBuckets: 1, dryRun: true}, userID, "custom label", []mimirpb.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}, histogram))
+
+	metadata := &mimirpb.MetricMetadata{MetricFamilyName: "go_goroutines_and_routines", Type: mimirpb.COUNTER}
+	assert.NoError(t, cleanAndValidateMetadata(metadataMetrics, validateMetadataCfg{maxMetadataLength: 10, dryRun: true}, userID, metadata))
+
+	// Invalid series are still rejected.
+	assert.Equal(t, fmt.Errorf(duplicateLabelMsgFormat, "a", `foo{a="a", a="a"}`), validateLabels(sampleMetrics, labelsCfg, userID, "custom label", []mimirpb.LabelAdapter{
+		{Name: model.MetricNameLabel, Value: "foo"},
+		{Name: "a", Value: "a"},
+		{Name: "a", Value: "a"},
+	}, false))
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+			# TYPE cortex_discarded_samples_total counter
+			cortex_discarded_samples_total{group="custom label",reason="duplicate_label_names",user="testUser"} 1
+			# HELP cortex_dry_run_discarded_metadata_total The total number of metadata that would have been discarded, if the limits weren't in dry-run mode.
+			# TYPE cortex_dry_run_discarded_metadata_total counter
+			cortex_dry_run_discarded_metadata_total{reason="metric_name_too_long",user="testUser"} 1
+			# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+			# TYPE cortex_dry_run_discarded_samples_total counter
+			cortex_dry_run_discarded_samples_total{group="custom label",reason="label_name_too_long",user="testUser"} 1
+			cortex_dry_run_discarded_samples_total{group="custom label",reason="label_value_too_long",user="testUser"} 1
+			cortex_dry_run_discarded_samples_total{group="custom label",reason="max_label_names_per_series",user="testUser"} 2
+			cortex_dry_run_discarded_samples_total{group="custom label",reason="max_native_histogram_buckets",user="testUser"} 1
+	`), "cortex_discarded_samples_total", "cortex_discarded_metadata_total", "cortex_dry_run_discarded_samples_total", "cortex_dry_run_discarded_metadata_total"))
+
+	sampleMetrics.deleteUserMetrics(userID)
+	metadataMetrics.deleteUserMetrics(userID)
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(""), "cortex_dry_run_discarded_samples_total", "cortex_dry_run_discarded_metadata_total"))
+}
+
 type sampleValidationCfg struct {
 	maxNativeHistogramBuckets int
+	dryRun                    bool
 }
 
 func (c sampleValidationCfg) CreationGracePeriod(_ string) time.Duration {
@@ -395,6 +454,10 @@ func (c sampleValidationCfg) MaxNativeHistogramBuckets(_ string) int {
 	return c.maxNativeHistogramBuckets
 }
 
+func (c sampleValidationCfg) LimitsDryRun(_ string) bool {
+	return c.dryRun
+}
+
 func TestMaxNativeHistorgramBuckets(t *testing.T) {
 	// All will have 2 buckets, one negative and one positive
 	testCases := map[string]mimirpb.Histogram{
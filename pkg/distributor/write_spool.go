@@ -0,0 +1,476 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/failsafe-go/failsafe-go/circuitbreaker"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/atomicfs"
+	"github.com/grafana/mimir/pkg/util/globalerror"
+)
+
+const (
+	writeSpoolFileExtension    = ".req"
+	writeSpoolTmpFileExtension = ".tmp"
+
+	// Reasons for dropping spooled requests.
+	writeSpoolReasonTenantFull = "tenant_full"
+	writeSpoolReasonTooOld     = "too_old"
+	writeSpoolReasonCorrupted  = "corrupted"
+	writeSpoolReasonRejected   = "rejected"
+)
+
+var (
+	errWriteSpoolTenantFull = errors.New("the write spool of the tenant is full")
+	errInvalidSpoolDir      = errors.New("the write spool directory must be set when the write spool is enabled")
+	errInvalidSpoolMaxSize  = errors.New("the write spool max tenant size must be greater than 0")
+	errInvalidSpoolMaxAge   = errors.New("the write spool max age must be greater than 0")
+)
+
+// WriteSpoolConfig configures the distributor write spool.
+type WriteSpoolConfig struct {
+	Enabled            bool          `yaml:"enabled" category:"experimental"`
+	Directory          string        `yaml:"directory" category:"experimental"`
+	MaxTenantSizeBytes int64         `yaml:"max_tenant_size_bytes" category:"experimental"`
+	MaxAge             time.Duration `yaml:"max_age" category:"experimental"`
+	ReplayInterval     time.Duration `yaml:"replay_interval" category:"experimental"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet.
+func (cfg *WriteSpoolConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.write-spool.enabled", false, "Enable the write spool. The write requests failing with a transient error, like when the ingesters are unavailable, are persisted to the local disk and accepted, and then replayed to the ingesters in the background. The replayed samples are older than the samples received in the meantime, so they're rejected as out-of-order unless the tenant's out-of-order time window covers the max age.")
+	f.StringVar(&cfg.Directory, "distributor.write-spool.directory", "./write-spool/", "Directory where the write requests are spooled. The directory should be persisted across restarts, so that the spooled write requests are replayed after a restart.")
+	f.Int64Var(&cfg.MaxTenantSizeBytes, "distributor.write-spool.max-tenant-size-bytes", 512*1024*1024, "Maximum size in bytes of the spooled write requests per tenant. The write requests exceeding the limit aren't spooled, and fail with the original error.")
+	f.DurationVar(&cfg.MaxAge, "distributor.write-spool.max-age", time.Hour, "Maximum age of a spooled write request. The write requests which haven't been replayed within this time are dropped.")
+	f.DurationVar(&cfg.ReplayInterval, "distributor.write-spool.replay-interval", 10*time.Second, "How frequently the spooled write requests are replayed to the ingesters.")
+}
+
+// Validate the config and returns an error on failure.
+func (cfg *WriteSpoolConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Directory == "" {
+		return errInvalidSpoolDir
+	}
+	if cfg.MaxTenantSizeBytes <= 0 {
+		return errInvalidSpoolMaxSize
+	}
+	if cfg.MaxAge <= 0 {
+		return errInvalidSpoolMaxAge
+	}
+	return nil
+}
+
+// replayFunc pushes a spooled write request to the ingesters.
+type replayFunc func(ctx context.Context, userID string, req *mimirpb.WriteRequest) error
+
+// writeSpool persists the write requests which can't be pushed to the ingesters to the local disk,
+// and replays them to the ingesters in the background. Each request is stored in its own file, in
+// a directory per tenant, and requests are replayed in the order they've been spooled.
+type writeSpool struct {
+	services.Service
+
+	cfg    WriteSpoolConfig
+	replay replayFunc
+	logger log.Logger
+
+	mtx     sync.Mutex
+	tenants map[string]*writeSpoolTenant
+	seq     uint64
+
+	spooledRequests  *prometheus.CounterVec
+	replayedRequests *prometheus.CounterVec
+	droppedRequests  *prometheus.CounterVec
+	pendingRequests  *prometheus.GaugeVec
+	pendingBytes     *prometheus.GaugeVec
+}
+
+type writeSpoolTenant struct {
+	entries  []writeSpoolEntry // Sorted from the oldest to the newest.
+	size     int64
+	reserved int64 // Size of the requests being written to the spool.
+}
+
+type writeSpoolEntry struct {
+	path    string
+	size    int64
+	created time.Time
+}
+
+func newWriteSpool(cfg WriteSpoolConfig, replay replayFunc, logger log.Logger, reg prometheus.Registerer) *writeSpool {
+	s := &writeSpool{
+		cfg:     cfg,
+		replay:  replay,
+		logger:  logger,
+		tenants: map[string]*writeSpoolTenant{},
+
+		spooledRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_spool_spooled_requests_total",
+			Help: "The total number of write requests persisted to the write spool.",
+		}, []string{"user"}),
+		replayedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_spool_replayed_requests_total",
+			Help: "The total number of spooled write requests successfully replayed to the ingesters.",
+		}, []string{"user"}),
+		droppedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_spool_dropped_requests_total",
+			Help: "The total number of write requests dropped by the write spool, either because they couldn't be spooled or replayed.",
+		}, []string{"user", "reason"}),
+		pendingRequests: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_distributor_write_spool_requests",
+			Help: "The number of write requests in the write spool, waiting to be replayed.",
+		}, []string{"user"}),
+		pendingBytes: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_distributor_write_spool_size_bytes",
+			Help: "The size in bytes of the write requests in the write spool, waiting to be replayed.",
+		}, []string{"user"}),
+	}
+
+	s.Service = services.NewTimerService(cfg.ReplayInterval, s.starting, s.iteration, nil)
+	return s
+}
+
+// starting loads the write requests spooled before the last shutdown.
+func (s *writeSpool) starting(_ context.Context) error {
+	if err := os.MkdirAll(s.cfg.Directory, 0o750); err != nil {
+		return errors.Wrap(err, "failed to create the write spool directory")
+	}
+
+	tenantDirs, err := os.ReadDir(s.cfg.Directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the write spool directory")
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, tenantDir := range tenantDirs {
+		if !tenantDir.IsDir() {
+			continue
+		}
+		userID := tenantDir.Name()
+
+		files, err := os.ReadDir(filepath.Join(s.cfg.Directory, userID))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the write spool directory of tenant %s", userID)
+		}
+
+		for _, file := range files {
+			path := filepath.Join(s.cfg.Directory, userID, file.Name())
+
+			// Temporary files are left behind by a spooling interrupted by a crash.
+			if strings.HasSuffix(file.Name(), writeSpoolTmpFileExtension) {
+				if err := os.Remove(path); err != nil {
+					level.Warn(s.logger).Log("msg", "failed to remove temporary write spool file", "path", path, "err", err)
+				}
+				continue
+			}
+
+			created, ok := parseWriteSpoolFileName(file.Name())
+			if !ok {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				return errors.Wrapf(err, "failed to read the write spool file %s", path)
+			}
+			s.addLocked(userID, writeSpoolEntry{path: path, size: info.Size(), created: created})
+		}
+
+		if t, ok := s.tenants[userID]; ok {
+			sort.Slice(t.entries, func(i, j int) bool {
+				return t.entries[i].path < t.entries[j].path
+			})
+			level.Info(s.logger).Log("msg", "loaded spooled write requests", "user", userID, "requests", len(t.entries), "bytes", t.size)
+		}
+	}
+
+	return nil
+}
+
+func (s *writeSpool) iteration(ctx context.Context) error {
+	for _, userID := range s.tenantIDs() {
+		s.replayTenant(ctx, userID, time.Now())
+	}
+	return nil
+}
+
+// spool persists the write request of the tenant. It returns an error if the request couldn't be spooled.
+func (s *writeSpool) spool(userID string, req *mimirpb.WriteRequest) error {
+	data, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Only the space is reserved under the lock, while the file is written outside of it, so that the
+	// disk I/O of a tenant doesn't block the spooling and replaying of the other tenants.
+	now, path, ok := s.reserve(userID, int64(len(data)))
+	if !ok {
+		s.droppedRequests.WithLabelValues(userID, writeSpoolReasonTenantFull).Inc()
+		return errWriteSpoolTenantFull
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err == nil {
+		err = atomicfs.CreateFileAndMove(path+writeSpoolTmpFileExtension, path, bytes.NewReader(data))
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.releaseLocked(userID, int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	s.addLocked(userID, writeSpoolEntry{path: path, size: int64(len(data)), created: now})
+	s.spooledRequests.WithLabelValues(userID).Inc()
+	return nil
+}
+
+// reserve reserves size bytes in the write spool of the tenant, and returns the creation time and the path of the
+// file to write the request to. It returns false if the tenant doesn't have enough space left.
+func (s *writeSpool) reserve(userID string, size int64) (time.Time, string, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, ok := s.tenants[userID]
+	if !ok {
+		t = &writeSpoolTenant{}
+		s.tenants[userID] = t
+	}
+	if t.size+t.reserved+size > s.cfg.MaxTenantSizeBytes {
+		s.deleteIfEmptyLocked(userID, t)
+		return time.Time{}, "", false
+	}
+	t.reserved += size
+
+	// The file names are sorted by creation time, and the sequence number makes them unique within this process.
+	now := time.Now()
+	s.seq++
+	name := fmt.Sprintf("%020d-%010d%s", now.UnixNano(), s.seq, writeSpoolFileExtension)
+	return now, filepath.Join(s.cfg.Directory, userID, name), true
+}
+
+// releaseLocked releases size bytes reserved in the write spool of the tenant. Must be called with mtx held.
+func (s *writeSpool) releaseLocked(userID string, size int64) {
+	t, ok := s.tenants[userID]
+	if !ok {
+		return
+	}
+	t.reserved -= size
+	s.deleteIfEmptyLocked(userID, t)
+}
+
+// deleteIfEmptyLocked stops tracking the tenant if it has neither spooled nor reserved requests. Must be called
+// with mtx held.
+func (s *writeSpool) deleteIfEmptyLocked(userID string, t *writeSpoolTenant) {
+	if len(t.entries) > 0 || t.reserved > 0 {
+		return
+	}
+	delete(s.tenants, userID)
+	s.pendingRequests.DeleteLabelValues(userID)
+	s.pendingBytes.DeleteLabelValues(userID)
+}
+
+// replayTenant replays the spooled write requests of the tenant, from the oldest, until all of them are replayed,
+// or a request fails with a retryable error, like when the ingesters are still unavailable. The ring health isn't
+// checked upfront: the oldest request is the probe, and replaying stops at its first retryable failure.
+//
+// The ingesters accept the replayed samples only if they're within the tenant's out-of-order time window, because
+// newer samples of the same series have likely been ingested while the requests were spooled. The rejected
+// requests are dropped with the "rejected" reason, except the ones rejected as duplicate or out-of-order samples:
+// a request is spooled even if it's been written to some of the ingesters, which reject it when it's replayed, so
+// such requests are considered already written.
+func (s *writeSpool) replayTenant(ctx context.Context, userID string, now time.Time) {
+	for ctx.Err() == nil {
+		entry, ok := s.oldest(userID)
+		if !ok {
+			return
+		}
+
+		if now.Sub(entry.created) > s.cfg.MaxAge {
+			s.remove(userID, entry, writeSpoolReasonTooOld)
+			continue
+		}
+
+		data, err := os.ReadFile(entry.path)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to read spooled write request", "user", userID, "path", entry.path, "err", err)
+			s.remove(userID, entry, writeSpoolReasonCorrupted)
+			continue
+		}
+
+		req := &mimirpb.WriteRequest{}
+		if err := req.Unmarshal(data); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to unmarshal spooled write request", "user", userID, "path", entry.path, "err", err)
+			s.remove(userID, entry, writeSpoolReasonCorrupted)
+			continue
+		}
+
+		err = s.replay(user.InjectOrgID(ctx, userID), userID, req)
+		if err != nil && isRetryablePushError(err) {
+			level.Debug(s.logger).Log("msg", "failed to replay spooled write request, will retry later", "user", userID, "err", err)
+			return
+		}
+		if err != nil && isAlreadyWrittenPushError(err) {
+			level.Debug(s.logger).Log("msg", "spooled write request already written to the ingesters", "user", userID, "err", err)
+			err = nil
+		}
+		if err != nil {
+			// The request has been rejected by the ingesters, and replaying it again wouldn't succeed.
+			level.Warn(s.logger).Log("msg", "spooled write request rejected by the ingesters", "user", userID, "err", err)
+			s.remove(userID, entry, writeSpoolReasonRejected)
+			continue
+		}
+
+		s.remove(userID, entry, "")
+		s.replayedRequests.WithLabelValues(userID).Inc()
+	}
+}
+
+func (s *writeSpool) tenantIDs() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	userIDs := make([]string, 0, len(s.tenants))
+	for userID := range s.tenants {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs
+}
+
+func (s *writeSpool) oldest(userID string) (writeSpoolEntry, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, ok := s.tenants[userID]
+	if !ok || len(t.entries) == 0 {
+		return writeSpoolEntry{}, false
+	}
+	return t.entries[0], true
+}
+
+// addLocked tracks a spooled write request. Must be called with mtx held.
+func (s *writeSpool) addLocked(userID string, entry writeSpoolEntry) {
+	t, ok := s.tenants[userID]
+	if !ok {
+		t = &writeSpoolTenant{}
+		s.tenants[userID] = t
+	}
+	// Concurrent requests may be written in a different order than the one they've been named in.
+	i := sort.Search(len(t.entries), func(i int) bool { return t.entries[i].path > entry.path })
+	t.entries = slices.Insert(t.entries, i, entry)
+	t.size += entry.size
+
+	s.pendingRequests.WithLabelValues(userID).Set(float64(len(t.entries)))
+	s.pendingBytes.WithLabelValues(userID).Set(float64(t.size))
+}
+
+// remove deletes the input spooled write request of the tenant. If reason is not empty, the request is tracked
+// as dropped.
+func (s *writeSpool) remove(userID string, entry writeSpoolEntry, reason string) {
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		level.Warn(s.logger).Log("msg", "failed to remove spooled write request", "user", userID, "path", entry.path, "err", err)
+	}
+	if reason != "" {
+		s.droppedRequests.WithLabelValues(userID, reason).Inc()
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, ok := s.tenants[userID]
+	if !ok {
+		return
+	}
+	// The entry is usually the oldest one, unless an older request has finished spooling in the meantime.
+	i := slices.IndexFunc(t.entries, func(e writeSpoolEntry) bool { return e.path == entry.path })
+	if i < 0 {
+		return
+	}
+	t.entries = slices.Delete(t.entries, i, i+1)
+	t.size -= entry.size
+
+	s.pendingRequests.WithLabelValues(userID).Set(float64(len(t.entries)))
+	s.pendingBytes.WithLabelValues(userID).Set(float64(t.size))
+	s.deleteIfEmptyLocked(userID, t)
+}
+
+// cleanupMetricsForUser removes the counters of the tenant. The gauges are kept until the spooled requests
+// of the tenant are replayed.
+func (s *writeSpool) cleanupMetricsForUser(userID string) {
+	s.spooledRequests.DeleteLabelValues(userID)
+	s.replayedRequests.DeleteLabelValues(userID)
+	s.droppedRequests.DeletePartialMatch(prometheus.Labels{"user": userID})
+}
+
+// parseWriteSpoolFileName returns the creation time of the spooled write request stored in the input file.
+func parseWriteSpoolFileName(name string) (time.Time, bool) {
+	if !strings.HasSuffix(name, writeSpoolFileExtension) {
+		return time.Time{}, false
+	}
+	nanos, _, ok := strings.Cut(strings.TrimSuffix(name, writeSpoolFileExtension), "-")
+	if !ok {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
+
+// isAlreadyWrittenPushError returns whether err is the rejection of samples which have already been written,
+// like when a request is replayed to the ingesters which accepted it before it was spooled.
+func isAlreadyWrittenPushError(err error) bool {
+	var pushErr ingesterPushError
+	if !errors.As(err, &pushErr) || pushErr.errorCause() != mimirpb.BAD_DATA {
+		return false
+	}
+	return globalerror.SampleDuplicateTimestamp.IsInMessage(pushErr.message) || globalerror.SampleOutOfOrder.IsInMessage(pushErr.message)
+}
+
+// isRetryablePushError returns true if the input error, returned by pushing a write request to the ingesters,
+// is known to be transient, like when the ingesters are unavailable. Unknown errors aren't retryable, because
+// replaying a request which can never succeed would hold the spool of the tenant until the max age.
+func isRetryablePushError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, circuitbreaker.ErrCircuitBreakerOpen) ||
+		errors.Is(err, ring.ErrTooManyUnhealthyInstances) ||
+		errors.Is(err, ring.ErrEmptyRing) {
+		return true
+	}
+
+	var distributorErr distributorError
+	if errors.As(err, &distributorErr) {
+		switch distributorErr.errorCause() {
+		case mimirpb.SERVICE_UNAVAILABLE, mimirpb.TSDB_UNAVAILABLE, mimirpb.INSTANCE_LIMIT:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,426 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/failsafe-go/failsafe-go/circuitbreaker"
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/globalerror"
+)
+
+func TestWriteSpoolConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         func(*WriteSpoolConfig)
+		expectedErr error
+	}{
+		"should pass with default config": {
+			cfg: func(*WriteSpoolConfig) {},
+		},
+		"should pass if enabled with default config": {
+			cfg: func(cfg *WriteSpoolConfig) {
+				cfg.Enabled = true
+			},
+		},
+		"should fail if enabled without directory": {
+			cfg: func(cfg *WriteSpoolConfig) {
+				cfg.Enabled = true
+				cfg.Directory = ""
+			},
+			expectedErr: errInvalidSpoolDir,
+		},
+		"should fail if enabled with invalid max tenant size": {
+			cfg: func(cfg *WriteSpoolConfig) {
+				cfg.Enabled = true
+				cfg.MaxTenantSizeBytes = 0
+			},
+			expectedErr: errInvalidSpoolMaxSize,
+		},
+		"should fail if enabled with invalid max age": {
+			cfg: func(cfg *WriteSpoolConfig) {
+				cfg.Enabled = true
+				cfg.MaxAge = 0
+			},
+			expectedErr: errInvalidSpoolMaxAge,
+		},
+		"should pass if disabled with invalid config": {
+			cfg: func(cfg *WriteSpoolConfig) {
+				cfg.Directory = ""
+				cfg.MaxAge = 0
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := WriteSpoolConfig{}
+			flagext.DefaultValues(&cfg)
+			testData.cfg(&cfg)
+
+			assert.Equal(t, testData.expectedErr, cfg.Validate())
+		})
+	}
+}
+
+func TestWriteSpool(t *testing.T) {
+	var (
+		replayErr error
+		replayed  []string
+	)
+	replay := func(ctx context.Context, userID string, req *mimirpb.WriteRequest) error {
+		orgID, err := tenant.TenantID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, userID, orgID)
+
+		if replayErr != nil {
+			return replayErr
+		}
+		for _, ts := range req.Timeseries {
+			replayed = append(replayed, mimirpb.FromLabelAdaptersToLabels(ts.Labels).Get(labels.MetricName))
+		}
+		return nil
+	}
+
+	req := func(metric string) *mimirpb.WriteRequest {
+		return mockWriteRequest(labels.FromStrings(labels.MetricName, metric), 1, 1000)
+	}
+	reqSize := int64(req("series_0").Size())
+
+	cfg := WriteSpoolConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.Directory = t.TempDir()
+	cfg.MaxTenantSizeBytes = 3 * reqSize
+
+	reg := prometheus.NewPedanticRegistry()
+	s := newWriteSpool(cfg, replay, log.NewNopLogger(), reg)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), s))
+	})
+
+	// Spool requests until the tenant is full.
+	require.NoError(t, s.spool("user-1", req("series_0")))
+	require.NoError(t, s.spool("user-1", req("series_1")))
+	require.NoError(t, s.spool("user-1", req("series_2")))
+	require.ErrorIs(t, s.spool("user-1", req("series_3")), errWriteSpoolTenantFull)
+	require.NoError(t, s.spool("user-2", req("series_4")))
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(fmt.Sprintf(`
+		# HELP cortex_distributor_write_spool_dropped_requests_total The total number of write requests dropped by the write spool, either because they couldn't be spooled or replayed.
+		# TYPE cortex_distributor_write_spool_dropped_requests_total counter
+		cortex_distributor_write_spool_dropped_requests_total{reason="tenant_full",user="user-1"} 1
+
+		# HELP cortex_distributor_write_spool_requests The number of write requests in the write spool, waiting to be replayed.
+		# TYPE cortex_distributor_write_spool_requests gauge
+		cortex_distributor_write_spool_requests{user="user-1"} 3
+		cortex_distributor_write_spool_requests{user="user-2"} 1
+
+		# HELP cortex_distributor_write_spool_size_bytes The size in bytes of the write requests in the write spool, waiting to be replayed.
+		# TYPE cortex_distributor_write_spool_size_bytes gauge
+		cortex_distributor_write_spool_size_bytes{user="user-1"} %d
+		cortex_distributor_write_spool_size_bytes{user="user-2"} %d
+
+		# HELP cortex_distributor_write_spool_spooled_requests_total The total number of write requests persisted to the write spool.
+		# TYPE cortex_distributor_write_spool_spooled_requests_total counter
+		cortex_distributor_write_spool_spooled_requests_total{user="user-1"} 3
+		cortex_distributor_write_spool_spooled_requests_total{user="user-2"} 1
+	`, 3*reqSize, reqSize))))
+
+	// A retryable error keeps the requests in the spool.
+	replayErr = ring.ErrTooManyUnhealthyInstances
+	s.replayTenant(context.Background(), "user-1", time.Now())
+	require.Empty(t, replayed)
+	require.Equal(t, 3, len(s.tenants["user-1"].entries))
+
+	// A non-retryable error drops the requests.
+	replayErr = newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, "bad data", mimirpb.BAD_DATA))
+	s.replayTenant(context.Background(), "user-2", time.Now())
+	require.Empty(t, replayed)
+	require.NotContains(t, s.tenants, "user-2")
+
+	// The requests rejected because they've already been written are removed without being tracked as dropped.
+	require.NoError(t, s.spool("user-3", req("series_6")))
+	replayErr = newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, globalerror.SampleDuplicateTimestamp.Message("duplicate sample"), mimirpb.BAD_DATA))
+	s.replayTenant(context.Background(), "user-3", time.Now())
+	require.Empty(t, replayed)
+	require.NotContains(t, s.tenants, "user-3")
+
+	// Once the ingesters are healthy, the requests are replayed in order.
+	replayErr = nil
+	s.replayTenant(context.Background(), "user-1", time.Now())
+	require.Equal(t, []string{"series_0", "series_1", "series_2"}, replayed)
+	require.Empty(t, s.tenants)
+
+	files, err := filepath.Glob(filepath.Join(cfg.Directory, "*", "*"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_write_spool_dropped_requests_total The total number of write requests dropped by the write spool, either because they couldn't be spooled or replayed.
+		# TYPE cortex_distributor_write_spool_dropped_requests_total counter
+		cortex_distributor_write_spool_dropped_requests_total{reason="rejected",user="user-2"} 1
+		cortex_distributor_write_spool_dropped_requests_total{reason="tenant_full",user="user-1"} 1
+
+		# HELP cortex_distributor_write_spool_replayed_requests_total The total number of spooled write requests successfully replayed to the ingesters.
+		# TYPE cortex_distributor_write_spool_replayed_requests_total counter
+		cortex_distributor_write_spool_replayed_requests_total{user="user-1"} 3
+		cortex_distributor_write_spool_replayed_requests_total{user="user-3"} 1
+	`), "cortex_distributor_write_spool_dropped_requests_total", "cortex_distributor_write_spool_replayed_requests_total", "cortex_distributor_write_spool_requests", "cortex_distributor_write_spool_size_bytes"))
+
+	// Requests older than the max age are dropped.
+	require.NoError(t, s.spool("user-1", req("series_5")))
+	s.replayTenant(context.Background(), "user-1", time.Now().Add(cfg.MaxAge+time.Minute))
+	require.Equal(t, []string{"series_0", "series_1", "series_2"}, replayed)
+	require.Empty(t, s.tenants)
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.droppedRequests.WithLabelValues("user-1", writeSpoolReasonTooOld)))
+
+	// Cleaning up the tenant metrics removes the counters.
+	s.cleanupMetricsForUser("user-1")
+	s.cleanupMetricsForUser("user-2")
+	s.cleanupMetricsForUser("user-3")
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(""), "cortex_distributor_write_spool_dropped_requests_total", "cortex_distributor_write_spool_replayed_requests_total", "cortex_distributor_write_spool_spooled_requests_total"))
+}
+
+func TestWriteSpool_ConcurrentSpooling(t *testing.T) {
+	const numRequests = 20
+
+	req := mockWriteRequest(labels.FromStrings(labels.MetricName, "series"), 1, 1000)
+
+	cfg := WriteSpoolConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.Directory = t.TempDir()
+	cfg.MaxTenantSizeBytes = 5 * int64(req.Size())
+
+	s := newWriteSpool(cfg, nil, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), s))
+	})
+
+	var (
+		wg      sync.WaitGroup
+		spooled atomic.Int32
+	)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.spool("user-1", req); err == nil {
+				spooled.Inc()
+			} else {
+				assert.ErrorIs(t, err, errWriteSpoolTenantFull)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The reservations are released, and the tenant size limit is honored.
+	require.Equal(t, int32(5), spooled.Load())
+	require.Len(t, s.tenants["user-1"].entries, 5)
+	require.Equal(t, cfg.MaxTenantSizeBytes, s.tenants["user-1"].size)
+	require.Zero(t, s.tenants["user-1"].reserved)
+	require.True(t, slices.IsSortedFunc(s.tenants["user-1"].entries, func(a, b writeSpoolEntry) int {
+		return strings.Compare(a.path, b.path)
+	}))
+}
+
+func TestWriteSpool_ShouldLoadSpooledRequestsOnStartup(t *testing.T) {
+	cfg := WriteSpoolConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.Directory = t.TempDir()
+
+	var replayed []string
+	replay := func(_ context.Context, _ string, req *mimirpb.WriteRequest) error {
+		for _, ts := range req.Timeseries {
+			replayed = append(replayed, mimirpb.FromLabelAdaptersToLabels(ts.Labels).Get(labels.MetricName))
+		}
+		return nil
+	}
+
+	// Spool some requests, and stop the spool before they're replayed.
+	s := newWriteSpool(cfg, replay, log.NewNopLogger(), nil)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.spool("user-1", mockWriteRequest(labels.FromStrings(labels.MetricName, fmt.Sprintf("series_%d", i)), 1, 1000)))
+	}
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), s))
+
+	// Simulate a leftover temporary file and a corrupted request.
+	tmpFile := filepath.Join(cfg.Directory, "user-1", "leftover"+writeSpoolFileExtension+writeSpoolTmpFileExtension)
+	require.NoError(t, os.WriteFile(tmpFile, []byte("tmp"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Directory, "user-1", fmt.Sprintf("%020d-%010d%s", time.Now().UnixNano(), 0, writeSpoolFileExtension)), []byte("corrupted"), 0o600))
+
+	reg := prometheus.NewPedanticRegistry()
+	s = newWriteSpool(cfg, replay, log.NewNopLogger(), reg)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), s))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), s))
+	})
+
+	require.NoFileExists(t, tmpFile)
+	assert.Equal(t, float64(4), testutil.ToFloat64(s.pendingRequests.WithLabelValues("user-1")))
+
+	s.replayTenant(context.Background(), "user-1", time.Now())
+	require.Equal(t, []string{"series_0", "series_1", "series_2"}, replayed)
+	assert.Equal(t, float64(3), testutil.ToFloat64(s.replayedRequests.WithLabelValues("user-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.droppedRequests.WithLabelValues("user-1", writeSpoolReasonCorrupted)))
+}
+
+func TestDistributor_PushWithWriteSpool(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	distributors, _, regs := prepare(t, prepConfig{
+		numIngesters:      3,
+		happyIngesters:    3,
+		timeOut:           true,
+		numDistributors:   1,
+		replicationFactor: 3,
+		writeSpoolDir:     t.TempDir(),
+	})
+
+	// The request is accepted, even if the ingesters are unavailable, because it's spooled.
+	response, err := distributors[0].Push(ctx, makeWriteRequest(0, 5, 0, false, false, "foo"))
+	require.NoError(t, err)
+	assert.Equal(t, emptyResponse, response)
+
+	// Non-retryable errors aren't spooled.
+	_, err = distributors[0].Push(ctx, mockWriteRequest(labels.FromStrings(labels.MetricName, "foo", "invalid-label", "bar"), 1, 1000))
+	require.Error(t, err)
+
+	require.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(`
+		# HELP cortex_distributor_write_spool_requests The number of write requests in the write spool, waiting to be replayed.
+		# TYPE cortex_distributor_write_spool_requests gauge
+		cortex_distributor_write_spool_requests{user="user"} 1
+
+		# HELP cortex_distributor_write_spool_spooled_requests_total The total number of write requests persisted to the write spool.
+		# TYPE cortex_distributor_write_spool_spooled_requests_total counter
+		cortex_distributor_write_spool_spooled_requests_total{user="user"} 1
+	`), "cortex_distributor_write_spool_requests", "cortex_distributor_write_spool_spooled_requests_total"))
+
+	// The request stays in the spool while the ingesters are unavailable.
+	distributors[0].writeSpool.replayTenant(ctx, "user", time.Now())
+	assert.Equal(t, float64(1), testutil.ToFloat64(distributors[0].writeSpool.pendingRequests.WithLabelValues("user")))
+}
+
+func TestIsRetryablePushError(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"generic error": {
+			err:       errors.New("failed"),
+			retryable: false,
+		},
+		"context canceled": {
+			err:       fmt.Errorf("failed: %w", context.Canceled),
+			retryable: false,
+		},
+		"deadline exceeded": {
+			err:       fmt.Errorf("%s: %w", deadlineExceededWrapMessage, context.DeadlineExceeded),
+			retryable: true,
+		},
+		"circuit breaker open": {
+			err:       fmt.Errorf("%s: %w", failedPushingToIngesterMessage, circuitbreaker.ErrCircuitBreakerOpen),
+			retryable: true,
+		},
+		"too many unhealthy instances": {
+			err:       ring.ErrTooManyUnhealthyInstances,
+			retryable: true,
+		},
+		"ingester push error with unknown cause": {
+			err:       newIngesterPushError(createStatusWithDetails(t, codes.Unavailable, "unavailable", mimirpb.UNKNOWN_CAUSE)),
+			retryable: false,
+		},
+		"ingester push error with service unavailable cause": {
+			err:       newIngesterPushError(createStatusWithDetails(t, codes.Unavailable, "unavailable", mimirpb.SERVICE_UNAVAILABLE)),
+			retryable: true,
+		},
+		"ingester push error with instance limit cause": {
+			err:       newIngesterPushError(createStatusWithDetails(t, codes.Unavailable, "limit reached", mimirpb.INSTANCE_LIMIT)),
+			retryable: true,
+		},
+		"ingester push error with TSDB unavailable cause": {
+			err:       newIngesterPushError(createStatusWithDetails(t, codes.Internal, "tsdb unavailable", mimirpb.TSDB_UNAVAILABLE)),
+			retryable: true,
+		},
+		"ingester push error with bad data cause": {
+			err:       newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, "bad data", mimirpb.BAD_DATA)),
+			retryable: false,
+		},
+		"ingestion rate limited error": {
+			err:       newIngestionRateLimitedError(10, 10),
+			retryable: false,
+		},
+		"replicas did not match error": {
+			err:       newReplicasDidNotMatchError("a", "b"),
+			retryable: false,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.retryable, isRetryablePushError(testData.err))
+		})
+	}
+}
+
+func TestIsAlreadyWrittenPushError(t *testing.T) {
+	tests := map[string]struct {
+		err            error
+		alreadyWritten bool
+	}{
+		"generic error": {
+			err:            errors.New(globalerror.SampleOutOfOrder.Message("out of order")),
+			alreadyWritten: false,
+		},
+		"duplicate sample": {
+			err:            newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, globalerror.SampleDuplicateTimestamp.Message("duplicate sample"), mimirpb.BAD_DATA)),
+			alreadyWritten: true,
+		},
+		"out-of-order sample": {
+			err:            fmt.Errorf("wrapped: %w", newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, globalerror.SampleOutOfOrder.Message("out of order"), mimirpb.BAD_DATA))),
+			alreadyWritten: true,
+		},
+		"sample too old": {
+			err:            newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, globalerror.SampleTimestampTooOld.Message("too old"), mimirpb.BAD_DATA)),
+			alreadyWritten: false,
+		},
+		"other bad data": {
+			err:            newIngesterPushError(createStatusWithDetails(t, codes.FailedPrecondition, "bad data", mimirpb.BAD_DATA)),
+			alreadyWritten: false,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.alreadyWritten, isAlreadyWrittenPushError(testData.err))
+		})
+	}
+}
@@ -85,11 +85,23 @@ func (c *cardinalityEstimation) Do(ctx context.Context, request Request) (Respon
 		spanLog.LogFields(otlog.Bool("estimate available", false))
 	}
 
+	plan := queryPlanFromContext(ctx)
+	if estimateAvailable {
+		plan.addStep("cardinality_estimation", "estimated the query cardinality to %d series", estimatedCardinality)
+	} else {
+		plan.addStep("cardinality_estimation", "no cardinality estimate is available for the query")
+	}
+
 	res, err := c.next.Do(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	// The query hasn't been executed if only its plan has been requested, so there's no actual cardinality.
+	if plan != nil {
+		return res, nil
+	}
+
 	statistics := stats.FromContext(ctx)
 	actualCardinality := statistics.GetFetchedSeriesCount()
 	spanLog.LogFields(otlog.Uint64("actual cardinality", actualCardinality))
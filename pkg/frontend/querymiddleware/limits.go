@@ -99,6 +99,73 @@ type Limits interface {
 	BlockedQueries(userID string) []*validation.BlockedQuery
 }
 
+// The functions below resolve the limits of the tenants of a query to the values enforced by the middlewares.
+// They're also used to report the limits in the query plan, so that it matches what the middlewares do.
+
+func maxQueryLookbackForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxQueryLookback)
+}
+
+func compactorBlocksRetentionPeriodForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.CompactorBlocksRetentionPeriod)
+}
+
+func creationGracePeriodForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.LargestPositiveNonZeroDurationPerTenant(tenantIDs, l.CreationGracePeriod)
+}
+
+func maxTotalQueryLengthForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxTotalQueryLength)
+}
+
+func maxQueryExpressionSizeBytesForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, l.MaxQueryExpressionSizeBytes)
+}
+
+func maxQueryParallelismForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveIntPerTenant(tenantIDs, l.MaxQueryParallelism)
+}
+
+func maxCacheFreshnessForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.MaxDurationPerTenant(tenantIDs, l.MaxCacheFreshness)
+}
+
+func resultsCacheTTLForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.ResultsCacheTTL)
+}
+
+func resultsCacheTTLForOutOfOrderTimeWindowForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.ResultsCacheTTLForOutOfOrderTimeWindow)
+}
+
+func outOfOrderTimeWindowForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.MaxDurationPerTenant(tenantIDs, l.OutOfOrderTimeWindow)
+}
+
+func resultsCacheForUnalignedQueryEnabledForTenants(tenantIDs []string, l Limits) bool {
+	return validation.AllTrueBooleansPerTenant(tenantIDs, l.ResultsCacheForUnalignedQueryEnabled)
+}
+
+func queryShardingTotalShardsForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveIntPerTenant(tenantIDs, l.QueryShardingTotalShards)
+}
+
+func queryShardingMaxShardedQueriesForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveIntPerTenant(tenantIDs, l.QueryShardingMaxShardedQueries)
+}
+
+func queryShardingMaxRegexpSizeBytesForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, l.QueryShardingMaxRegexpSizeBytes)
+}
+
+func splitInstantQueriesByIntervalForTenants(tenantIDs []string, l Limits) time.Duration {
+	return validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.SplitInstantQueriesByInterval)
+}
+
+func compactorSplitAndMergeShardsForTenants(tenantIDs []string, l Limits) int {
+	return validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, l.CompactorSplitAndMergeShards)
+}
+
 type limitsMiddleware struct {
 	Limits
 	next   Handler
@@ -126,8 +193,8 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 	}
 
 	// Clamp the time range based on the max query lookback and block retention period.
-	blocksRetentionPeriod := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.CompactorBlocksRetentionPeriod)
-	maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxQueryLookback)
+	blocksRetentionPeriod := compactorBlocksRetentionPeriodForTenants(tenantIDs, l)
+	maxQueryLookback := maxQueryLookbackForTenants(tenantIDs, l)
 	maxLookback := util_math.Min(blocksRetentionPeriod, maxQueryLookback)
 	if maxLookback > 0 {
 		minStartTime := util.TimeToMillis(time.Now().Add(-maxLookback))
@@ -142,6 +209,7 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 				"maxQueryLookback", maxQueryLookback,
 				"blocksRetentionPeriod", blocksRetentionPeriod)
 
+			queryPlanFromContext(ctx).addStep("limits", "the query isn't executed because its time range is before the max query lookback or the blocks retention period")
 			return newEmptyPrometheusResponse(), nil
 		}
 
@@ -154,12 +222,13 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 				"maxQueryLookback", maxQueryLookback,
 				"blocksRetentionPeriod", blocksRetentionPeriod)
 
+			queryPlanFromContext(ctx).addStep("limits", "moved the start time to %s because of the max query lookback or the blocks retention period", formatQueryPlanTime(minStartTime))
 			r = r.WithStartEnd(minStartTime, r.GetEnd())
 		}
 	}
 
 	// Enforce the max end time.
-	creationGracePeriod := validation.LargestPositiveNonZeroDurationPerTenant(tenantIDs, l.CreationGracePeriod)
+	creationGracePeriod := creationGracePeriodForTenants(tenantIDs, l)
 	maxEndTime := util.TimeToMillis(time.Now().Add(creationGracePeriod))
 	if r.GetEnd() > maxEndTime {
 		// Replace the end time in the request.
@@ -169,11 +238,12 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 			"updated", util.FormatTimeMillis(maxEndTime),
 			"creationGracePeriod", creationGracePeriod)
 
+		queryPlanFromContext(ctx).addStep("limits", "moved the end time to %s because of the creation grace period", formatQueryPlanTime(maxEndTime))
 		r = r.WithStartEnd(r.GetStart(), maxEndTime)
 	}
 
 	// Enforce max query size, in bytes.
-	if maxQuerySize := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, l.MaxQueryExpressionSizeBytes); maxQuerySize > 0 {
+	if maxQuerySize := maxQueryExpressionSizeBytesForTenants(tenantIDs, l); maxQuerySize > 0 {
 		querySize := len(r.GetQuery())
 		if querySize > maxQuerySize {
 			return nil, apierror.New(apierror.TypeBadData, validation.NewMaxQueryExpressionSizeBytesError(querySize, maxQuerySize).Error())
@@ -181,7 +251,7 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 	}
 
 	// Enforce the max query length.
-	if maxQueryLength := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxTotalQueryLength); maxQueryLength > 0 {
+	if maxQueryLength := maxTotalQueryLengthForTenants(tenantIDs, l); maxQueryLength > 0 {
 		queryLen := timestamp.Time(r.GetEnd()).Sub(timestamp.Time(r.GetStart()))
 		if queryLen > maxQueryLength {
 			return nil, apierror.New(apierror.TypeBadData, validation.NewMaxTotalQueryLengthError(queryLen, maxQueryLength).Error())
@@ -229,8 +299,24 @@ func (rt limitedParallelismRoundTripper) RoundTrip(r *http.Request) (*http.Respo
 		return nil, apierror.New(apierror.TypeBadData, err.Error())
 	}
 
+	// Build the query plan, rather than executing the query, if requested. The sub-requests aren't sent
+	// to the queriers, so the middlewares get empty responses.
+	if plan := queryPlanFromContext(ctx); plan != nil {
+		plan.init(request, tenantIDs, rt.limits)
+		_, err := rt.middleware.Wrap(
+			HandlerFunc(func(_ context.Context, r Request) (Response, error) {
+				plan.addDownstreamQuery(r)
+				return newEmptyPrometheusResponse(), nil
+			})).Do(ctx, request)
+		if err != nil {
+			plan.setError(err)
+		}
+
+		return plan.encode()
+	}
+
 	// Limit the amount of parallel sub-requests according to the MaxQueryParallelism tenant setting.
-	parallelism := validation.SmallestPositiveIntPerTenant(tenantIDs, rt.limits.MaxQueryParallelism)
+	parallelism := maxQueryParallelismForTenants(tenantIDs, rt.limits)
 	sem := semaphore.NewWeighted(int64(parallelism))
 
 	// Wraps middlewares with a final handler, which will receive sub-requests in
@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	apierror "github.com/grafana/mimir/pkg/api/error"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+const (
+	queryPlanPathSuffix = "/api/v1/query_plan"
+
+	// explainParam is the name of the query and query_range parameter to get the query plan
+	// instead of executing the query.
+	explainParam = "explain"
+
+	queryPlanTypeRange   = "range"
+	queryPlanTypeInstant = "instant"
+
+	queryPlanCacheHit        = "hit"
+	queryPlanCachePartialHit = "partial hit"
+	queryPlanCacheMiss       = "miss"
+)
+
+type queryPlanContextKey int
+
+const queryPlanKey queryPlanContextKey = 0
+
+// queryPlan describes how the query-frontend would run a query: it's built by the middlewares while the
+// query flows through them, without executing it against the queriers. It's safe for concurrent use, and
+// its methods can be called on a nil plan, which is the case of the queries being executed.
+type queryPlan struct {
+	mtx sync.Mutex
+
+	Query string `json:"query"`
+	Type  string `json:"type"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Step  string `json:"step,omitempty"`
+
+	// Limits are the per-tenant limits enforced by the query-frontend on the query, excluding the disabled ones.
+	Limits map[string]string `json:"limits"`
+
+	// Steps are the decisions taken by the middlewares, in the order they're taken.
+	Steps []queryPlanStep `json:"steps"`
+
+	// CacheLookups are the results cache lookups, one per query resulting from the split by interval.
+	CacheLookups []queryPlanCacheLookup `json:"cacheLookups,omitempty"`
+
+	// ShardedQueries are the queries rewritten by query sharding.
+	ShardedQueries []queryPlanShardedQuery `json:"shardedQueries,omitempty"`
+
+	// DownstreamQueries are the queries which would be sent to the queriers.
+	DownstreamQueries []queryPlanQuery `json:"downstreamQueries"`
+
+	// Error is the error the query would fail with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+type queryPlanStep struct {
+	Middleware  string `json:"middleware"`
+	Description string `json:"description"`
+}
+
+type queryPlanQuery struct {
+	Query string `json:"query"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Step  string `json:"step,omitempty"`
+}
+
+type queryPlanCacheLookup struct {
+	queryPlanQuery
+	Result string `json:"result"`
+}
+
+type queryPlanShardedQuery struct {
+	queryPlanQuery
+	TotalShards    int `json:"totalShards"`
+	ShardedQueries int `json:"shardedQueries"`
+}
+
+type queryPlanResponse struct {
+	Status string     `json:"status"`
+	Data   *queryPlan `json:"data"`
+}
+
+// contextWithQueryPlan returns a context which makes the query-frontend build the plan of the query,
+// rather than executing it.
+func contextWithQueryPlan(ctx context.Context, plan *queryPlan) context.Context {
+	return context.WithValue(ctx, queryPlanKey, plan)
+}
+
+// queryPlanFromContext returns the plan to build for the query, or nil if the query should be executed.
+func queryPlanFromContext(ctx context.Context) *queryPlan {
+	plan, _ := ctx.Value(queryPlanKey).(*queryPlan)
+	return plan
+}
+
+func isQueryPlan(path string) bool {
+	return strings.HasSuffix(path, queryPlanPathSuffix)
+}
+
+// newQueryPlanRequest returns whether r asks for the query plan and, if so, the request to run through the
+// query or query_range middlewares to build it. Requests to the query plan API are range queries if they
+// have the step parameter, or instant queries otherwise. The explain parameter of the query and query_range
+// requests is only looked up in the URL, so that the body of the queries being executed isn't parsed here.
+func newQueryPlanRequest(r *http.Request) (*http.Request, bool, error) {
+	if isRangeQuery(r.URL.Path) || isInstantQuery(r.URL.Path) {
+		if explain, _ := strconv.ParseBool(r.URL.Query().Get(explainParam)); !explain {
+			return nil, false, nil
+		}
+
+		return r.Clone(contextWithQueryPlan(r.Context(), &queryPlan{})), true, nil
+	}
+
+	if !isQueryPlan(r.URL.Path) {
+		return nil, false, nil
+	}
+
+	// The parameters of POST requests may be in the body too.
+	values := r.URL.Query()
+	if r.Method == http.MethodPost {
+		var err error
+		if values, err = util.ParseRequestFormWithoutConsumingBody(r); err != nil {
+			return nil, false, apierror.New(apierror.TypeBadData, err.Error())
+		}
+	}
+
+	planReq := r.Clone(contextWithQueryPlan(r.Context(), &queryPlan{}))
+	prefix := strings.TrimSuffix(r.URL.Path, queryPlanPathSuffix)
+	if values.Has("step") {
+		planReq.URL.Path = prefix + queryRangePathSuffix
+	} else {
+		planReq.URL.Path = prefix + instantQueryPathSuffix
+	}
+
+	return planReq, true, nil
+}
+
+// queryPlanLimits are the limits reported in the query plan, resolved by the same functions the middlewares use.
+var queryPlanLimits = []struct {
+	name  string
+	value func(tenantIDs []string, limits Limits) string
+}{
+	{"max_query_lookback", queryPlanDuration(maxQueryLookbackForTenants)},
+	{"compactor_blocks_retention_period", queryPlanDuration(compactorBlocksRetentionPeriodForTenants)},
+	{"creation_grace_period", queryPlanDuration(creationGracePeriodForTenants)},
+	{"max_total_query_length", queryPlanDuration(maxTotalQueryLengthForTenants)},
+	{"max_query_expression_size_bytes", queryPlanInt(maxQueryExpressionSizeBytesForTenants)},
+	{"max_query_parallelism", queryPlanInt(maxQueryParallelismForTenants)},
+	{"max_cache_freshness", queryPlanDuration(maxCacheFreshnessForTenants)},
+	{"results_cache_ttl", queryPlanDuration(resultsCacheTTLForTenants)},
+	{"results_cache_ttl_for_out_of_order_time_window", queryPlanDuration(resultsCacheTTLForOutOfOrderTimeWindowForTenants)},
+	{"out_of_order_time_window", queryPlanDuration(outOfOrderTimeWindowForTenants)},
+	{"cache_unaligned_requests", queryPlanBool(resultsCacheForUnalignedQueryEnabledForTenants)},
+	{"query_sharding_total_shards", queryPlanInt(queryShardingTotalShardsForTenants)},
+	{"query_sharding_max_sharded_queries", queryPlanInt(queryShardingMaxShardedQueriesForTenants)},
+	{"query_sharding_max_regexp_size_bytes", queryPlanInt(queryShardingMaxRegexpSizeBytesForTenants)},
+	{"split_instant_queries_by_interval", queryPlanDuration(splitInstantQueriesByIntervalForTenants)},
+	{"compactor_split_and_merge_shards", queryPlanInt(compactorSplitAndMergeShardsForTenants)},
+}
+
+// The functions below format the limits for the query plan, returning an empty string for the disabled ones.
+
+func queryPlanDuration(f func([]string, Limits) time.Duration) func([]string, Limits) string {
+	return func(tenantIDs []string, limits Limits) string {
+		if d := f(tenantIDs, limits); d > 0 {
+			return model.Duration(d).String()
+		}
+		return ""
+	}
+}
+
+func queryPlanInt(f func([]string, Limits) int) func([]string, Limits) string {
+	return func(tenantIDs []string, limits Limits) string {
+		if v := f(tenantIDs, limits); v > 0 {
+			return strconv.Itoa(v)
+		}
+		return ""
+	}
+}
+
+func queryPlanBool(f func([]string, Limits) bool) func([]string, Limits) string {
+	return func(tenantIDs []string, limits Limits) string {
+		if f(tenantIDs, limits) {
+			return "true"
+		}
+		return ""
+	}
+}
+
+// init sets the query and the limits of the plan.
+func (p *queryPlan) init(r Request, tenantIDs []string, limits Limits) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	q := newQueryPlanQuery(r)
+	p.Query, p.Start, p.End, p.Step = q.Query, q.Start, q.End, q.Step
+	p.Type = queryPlanTypeInstant
+	if _, ok := r.(*PrometheusRangeQueryRequest); ok {
+		p.Type = queryPlanTypeRange
+	}
+
+	p.Limits = map[string]string{}
+	for _, l := range queryPlanLimits {
+		if v := l.value(tenantIDs, limits); v != "" {
+			p.Limits[l.name] = v
+		}
+	}
+}
+
+// addStep records a decision taken by the middleware.
+func (p *queryPlan) addStep(middleware, format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.Steps = append(p.Steps, queryPlanStep{Middleware: middleware, Description: fmt.Sprintf(format, args...)})
+}
+
+// addCacheLookup records the result of the results cache lookup for the request.
+func (p *queryPlan) addCacheLookup(r Request, result string) {
+	if p == nil {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.CacheLookups = append(p.CacheLookups, queryPlanCacheLookup{queryPlanQuery: newQueryPlanQuery(r), Result: result})
+}
+
+// addShardedQuery records that the request has been rewritten by query sharding.
+func (p *queryPlan) addShardedQuery(r Request, totalShards, shardedQueries int) {
+	if p == nil {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.ShardedQueries = append(p.ShardedQueries, queryPlanShardedQuery{queryPlanQuery: newQueryPlanQuery(r), TotalShards: totalShards, ShardedQueries: shardedQueries})
+}
+
+// addDownstreamQuery records the request which would be sent to the queriers.
+func (p *queryPlan) addDownstreamQuery(r Request) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.DownstreamQueries = append(p.DownstreamQueries, newQueryPlanQuery(r))
+}
+
+// setError records the error the query would fail with.
+func (p *queryPlan) setError(err error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.Error = err.Error()
+}
+
+// encode returns the plan as a Prometheus API response.
+func (p *queryPlan) encode() (*http.Response, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.DownstreamQueries == nil {
+		p.DownstreamQueries = []queryPlanQuery{}
+	}
+
+	b, err := json.Marshal(queryPlanResponse{Status: statusSuccess, Data: p})
+	if err != nil {
+		return nil, apierror.Newf(apierror.TypeInternal, "error encoding query plan: %v", err)
+	}
+
+	return &http.Response{
+		Header: http.Header{
+			"Content-Type": []string{jsonMimeType},
+		},
+		Body:          io.NopCloser(bytes.NewBuffer(b)),
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(b)),
+	}, nil
+}
+
+func newQueryPlanQuery(r Request) queryPlanQuery {
+	q := queryPlanQuery{
+		Query: r.GetQuery(),
+		Start: formatQueryPlanTime(r.GetStart()),
+		End:   formatQueryPlanTime(r.GetEnd()),
+	}
+	if _, ok := r.(*PrometheusRangeQueryRequest); ok {
+		q.Step = model.Duration(time.Duration(r.GetStep()) * time.Millisecond).String()
+	}
+	return q
+}
+
+func formatQueryPlanTime(ms int64) string {
+	return util.TimeFromMillis(ms).UTC().Format(time.RFC3339Nano)
+}
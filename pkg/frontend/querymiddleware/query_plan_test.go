@@ -0,0 +1,351 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/cache"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPlan(t *testing.T) {
+	tests := map[string]struct {
+		method                string
+		path                  string
+		params                url.Values
+		expectedType          string
+		expectedSteps         []queryPlanStep
+		expectedShardedCount  int
+		expectedDownstreamLen int
+		expectedError         string
+	}{
+		"range query split by interval and sharded": {
+			path: "/api/v1/query_plan",
+			params: url.Values{
+				"query": []string{`sum(rate(metric[1m]))`},
+				"start": []string{"2021-01-01T00:00:30Z"},
+				"end":   []string{"2021-01-03T00:00:00Z"},
+				"step":  []string{"60"},
+			},
+			expectedType: queryPlanTypeRange,
+			expectedSteps: []queryPlanStep{
+				{Middleware: "step_align", Description: "aligned the time range to the step: start 2021-01-01T00:00:00Z, end 2021-01-03T00:00:00Z"},
+				{Middleware: "split_by_interval_and_results_cache", Description: "split the query by 1d into 2 queries"},
+				{Middleware: "split_by_interval_and_results_cache", Description: "the results cache isn't used for the query"},
+			},
+			expectedShardedCount:  2,
+			expectedDownstreamLen: 2 * 4,
+		},
+		"range query with the parameters in the body": {
+			method: http.MethodPost,
+			path:   "/api/v1/query_plan",
+			params: url.Values{
+				"query": []string{`sum(rate(metric[1m]))`},
+				"start": []string{"2021-01-01T00:00:00Z"},
+				"end":   []string{"2021-01-01T12:00:00Z"},
+				"step":  []string{"60"},
+			},
+			expectedType: queryPlanTypeRange,
+			expectedSteps: []queryPlanStep{
+				{Middleware: "split_by_interval_and_results_cache", Description: "the results cache isn't used for the query"},
+			},
+			expectedShardedCount:  1,
+			expectedDownstreamLen: 4,
+		},
+		"range query with the explain parameter": {
+			path: "/api/v1/query_range",
+			params: url.Values{
+				"query":   []string{`sum(rate(metric[1m]))`},
+				"start":   []string{"2021-01-01T00:00:00Z"},
+				"end":     []string{"2021-01-01T12:00:00Z"},
+				"step":    []string{"60"},
+				"explain": []string{"true"},
+			},
+			expectedType: queryPlanTypeRange,
+			expectedSteps: []queryPlanStep{
+				{Middleware: "split_by_interval_and_results_cache", Description: "the results cache isn't used for the query"},
+			},
+			expectedShardedCount:  1,
+			expectedDownstreamLen: 4,
+		},
+		"instant query split by interval": {
+			path: "/api/v1/query_plan",
+			params: url.Values{
+				"query": []string{`sum(rate(metric[3h]))`},
+				"time":  []string{"2021-01-01T00:00:00Z"},
+			},
+			expectedType: queryPlanTypeInstant,
+			expectedSteps: []queryPlanStep{
+				{Middleware: "split_instant_query_by_interval", Description: `split the query by 1h into 3 queries: sum(sum(__embedded_queries__{__queries__="{\"Concat\":[\"sum(increase(metric[1h] offset 2h))\",\"sum(increase(metric[1h] offset 1h))\",\"sum(increase(metric[1h]))\"]}"}) / 10800)`},
+			},
+			expectedShardedCount:  3,
+			expectedDownstreamLen: 3 * 4,
+		},
+		"instant query not shardable": {
+			path: "/api/v1/query_plan",
+			params: url.Values{
+				"query": []string{`metric`},
+				"time":  []string{"2021-01-01T00:00:00Z"},
+			},
+			expectedType: queryPlanTypeInstant,
+			expectedSteps: []queryPlanStep{
+				{Middleware: "split_instant_query_by_interval", Description: "the query isn't split by 1h: non-splittable"},
+				{Middleware: "querysharding", Description: "the query isn't sharded: it isn't shardable"},
+			},
+			expectedDownstreamLen: 1,
+		},
+		"query exceeding the limits": {
+			path: "/api/v1/query_plan",
+			params: url.Values{
+				"query": []string{`sum(rate(metric[1m]))`},
+				"start": []string{"2020-01-01T00:00:00Z"},
+				"end":   []string{"2021-01-01T00:00:00Z"},
+				"step":  []string{"3600"},
+			},
+			expectedType:  queryPlanTypeRange,
+			expectedError: "the total query time range exceeds the limit",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw, err := NewTripperware(
+				Config{
+					AlignQueriesWithStep:   true,
+					SplitQueriesByInterval: 24 * time.Hour,
+					ShardedQueries:         true,
+				},
+				log.NewNopLogger(),
+				mockLimits{totalShards: 4, splitInstantQueriesInterval: time.Hour, maxTotalQueryLength: 30 * 24 * time.Hour},
+				newTestPrometheusCodec(),
+				nil,
+				promql.EngineOpts{
+					Logger:     log.NewNopLogger(),
+					MaxSamples: 1000,
+					Timeout:    time.Minute,
+				},
+				prometheus.NewPedanticRegistry(),
+			)
+			require.NoError(t, err)
+
+			// The queries must not be executed.
+			tripper := tw(RoundTripFunc(func(*http.Request) (*http.Response, error) {
+				require.Fail(t, "unexpected downstream request")
+				return nil, nil
+			}))
+
+			method := testData.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			plan := doQueryPlanRequest(t, tripper, method, testData.path, testData.params)
+			require.Equal(t, testData.expectedType, plan.Type)
+			require.Equal(t, testData.params.Get("query"), plan.Query)
+			require.Equal(t, map[string]string{
+				"max_query_parallelism":             "14",
+				"max_total_query_length":            "30d",
+				"query_sharding_total_shards":       "4",
+				"split_instant_queries_by_interval": "1h",
+			}, plan.Limits)
+			require.Equal(t, testData.expectedSteps, plan.Steps)
+			require.Len(t, plan.ShardedQueries, testData.expectedShardedCount)
+			for _, q := range plan.ShardedQueries {
+				require.Equal(t, 4, q.TotalShards)
+				require.Equal(t, 4, q.ShardedQueries)
+			}
+			require.Len(t, plan.DownstreamQueries, testData.expectedDownstreamLen)
+			require.Contains(t, plan.Error, testData.expectedError)
+		})
+	}
+}
+
+func TestQueryPlan_ResultsCache(t *testing.T) {
+	const (
+		day   = 24 * time.Hour
+		query = `sum(rate(metric[1m]))`
+	)
+
+	cacheBackend := cache.NewInstrumentedMockCache()
+	mw := newSplitAndCacheMiddleware(
+		true,
+		true,
+		day,
+		mockLimits{resultsCacheTTL: time.Hour},
+		newTestPrometheusCodec(),
+		cacheBackend,
+		ConstSplitter(day),
+		PrometheusResponseExtractor{},
+		resultsCacheAlwaysEnabled,
+		log.NewNopLogger(),
+		prometheus.NewPedanticRegistry(),
+	)
+
+	downstream := HandlerFunc(func(context.Context, Request) (Response, error) {
+		return newEmptyPrometheusResponse(), nil
+	})
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &PrometheusRangeQueryRequest{
+		Path:  "/api/v1/query_range",
+		Start: start.UnixMilli(),
+		End:   start.Add(day - time.Minute).UnixMilli(),
+		Step:  time.Minute.Milliseconds(),
+		Query: query,
+	}
+
+	// Execute the query on the first day, to populate the results cache.
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	_, err := mw.Wrap(downstream).Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, cacheBackend.CountStoreCalls())
+
+	// Plan the query on the first two days and a half: only the first day is cached.
+	plan := &queryPlan{}
+	_, err = mw.Wrap(HandlerFunc(func(_ context.Context, r Request) (Response, error) {
+		plan.addDownstreamQuery(r)
+		return newEmptyPrometheusResponse(), nil
+	})).Do(contextWithQueryPlan(ctx, plan), req.WithStartEnd(start.UnixMilli(), start.Add(2*day+12*time.Hour).UnixMilli()))
+	require.NoError(t, err)
+
+	results := make([]string, 0, len(plan.CacheLookups))
+	for _, lookup := range plan.CacheLookups {
+		results = append(results, lookup.Result)
+	}
+	require.Equal(t, []string{queryPlanCacheHit, queryPlanCacheMiss, queryPlanCacheMiss}, results)
+	require.Len(t, plan.DownstreamQueries, 2)
+
+	// The results cache isn't updated when planning the query.
+	require.Equal(t, 1, cacheBackend.CountStoreCalls())
+
+	// The plan reports the TTL enforced by the middleware.
+	ttl, _, _ := mw.Wrap(downstream).(*splitAndCacheMiddleware).getCacheOptions([]string{"user-1"})
+	plan.init(req, []string{"user-1"}, mockLimits{resultsCacheTTL: time.Hour})
+	require.Equal(t, model.Duration(ttl).String(), plan.Limits["results_cache_ttl"])
+}
+
+func TestNewQueryPlanRequest(t *testing.T) {
+	tests := map[string]struct {
+		method       string
+		path         string
+		urlParams    url.Values
+		bodyParams   url.Values
+		expectedPlan bool
+		expectedPath string
+	}{
+		"query with the explain parameter in the URL": {
+			method:       http.MethodGet,
+			path:         "/prometheus/api/v1/query_range",
+			urlParams:    url.Values{"query": []string{"up"}, "step": []string{"60"}, "explain": []string{"true"}},
+			expectedPlan: true,
+			expectedPath: "/prometheus/api/v1/query_range",
+		},
+		"query without the explain parameter": {
+			method:    http.MethodGet,
+			path:      "/prometheus/api/v1/query",
+			urlParams: url.Values{"query": []string{"up"}, "explain": []string{"false"}},
+		},
+		"query with the explain parameter in the body": {
+			method:     http.MethodPost,
+			path:       "/prometheus/api/v1/query",
+			bodyParams: url.Values{"query": []string{"up"}, "explain": []string{"true"}},
+		},
+		"query plan of a range query in the URL": {
+			method:       http.MethodGet,
+			path:         "/prometheus/api/v1/query_plan",
+			urlParams:    url.Values{"query": []string{"up"}, "step": []string{"60"}},
+			expectedPlan: true,
+			expectedPath: "/prometheus/api/v1/query_range",
+		},
+		"query plan of a range query in the body": {
+			method:       http.MethodPost,
+			path:         "/prometheus/api/v1/query_plan",
+			bodyParams:   url.Values{"query": []string{"up"}, "step": []string{"60"}},
+			expectedPlan: true,
+			expectedPath: "/prometheus/api/v1/query_range",
+		},
+		"query plan of an instant query": {
+			method:       http.MethodGet,
+			path:         "/prometheus/api/v1/query_plan",
+			urlParams:    url.Values{"query": []string{"up"}},
+			expectedPlan: true,
+			expectedPath: "/prometheus/api/v1/query",
+		},
+		"other API": {
+			method:    http.MethodGet,
+			path:      "/prometheus/api/v1/labels",
+			urlParams: url.Values{"explain": []string{"true"}},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := testData.bodyParams.Encode()
+			req, err := http.NewRequest(testData.method, testData.path+"?"+testData.urlParams.Encode(), strings.NewReader(body))
+			require.NoError(t, err)
+			if testData.bodyParams != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+
+			planReq, ok, err := newQueryPlanRequest(req)
+			require.NoError(t, err)
+			require.Equal(t, testData.expectedPlan, ok)
+
+			// The body must be left for the query to be forwarded.
+			actualBody, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Equal(t, body, string(actualBody))
+
+			if !testData.expectedPlan {
+				return
+			}
+			require.Equal(t, testData.expectedPath, planReq.URL.Path)
+			require.NotNil(t, queryPlanFromContext(planReq.Context()))
+		})
+	}
+}
+
+// doQueryPlanRequest sends the params in the URL of GET requests, or in the body of POST requests.
+func doQueryPlanRequest(t *testing.T, tripper http.RoundTripper, method, path string, params url.Values) *queryPlan {
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequest(method, path, strings.NewReader(params.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req, err = http.NewRequest(method, path+"?"+params.Encode(), http.NoBody)
+		require.NoError(t, err)
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	req = req.WithContext(ctx)
+	require.NoError(t, user.InjectOrgIDIntoHTTPRequest(ctx, req))
+
+	resp, err := tripper.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	res := struct {
+		Status string     `json:"status"`
+		Data   *queryPlan `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(body, &res))
+	require.Equal(t, statusSuccess, res.Status)
+	return res.Data
+}
@@ -30,7 +30,6 @@ import (
 	"github.com/grafana/mimir/pkg/util"
 	util_math "github.com/grafana/mimir/pkg/util/math"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
-	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 const shardingTimeout = 10 * time.Second
@@ -114,6 +113,7 @@ func (s *querySharding) Do(ctx context.Context, r Request) (Response, error) {
 	totalShards := s.getShardsForQuery(ctx, tenantIDs, r, queryExpr, log)
 	if totalShards <= 1 {
 		level.Debug(log).Log("msg", "query sharding is disabled for this query or tenant")
+		queryPlanFromContext(ctx).addStep("querysharding", "the query isn't sharded: query sharding is disabled for the query or tenant")
 		return s.next.Do(ctx, r)
 	}
 
@@ -131,6 +131,12 @@ func (s *querySharding) Do(ctx context.Context, r Request) (Response, error) {
 			level.Debug(log).Log("msg", "query is not supported for being rewritten into a shardable query", "query", r.GetQuery())
 		}
 
+		if err != nil {
+			queryPlanFromContext(ctx).addStep("querysharding", "the query isn't sharded: failed to rewrite it into a shardable query: %v", err)
+		} else {
+			queryPlanFromContext(ctx).addStep("querysharding", "the query isn't sharded: it isn't shardable")
+		}
+
 		return s.next.Do(ctx, r)
 	}
 
@@ -144,6 +150,7 @@ func (s *querySharding) Do(ctx context.Context, r Request) (Response, error) {
 	// Update query stats.
 	queryStats := stats.FromContext(ctx)
 	queryStats.AddShardedQueries(uint32(shardingStats.GetShardedQueries()))
+	queryPlanFromContext(ctx).addShardedQuery(r, totalShards, shardingStats.GetShardedQueries())
 
 	r = r.WithQuery(shardedQuery)
 	shardedQueryable := newShardedQueryable(r, s.next)
@@ -274,13 +281,13 @@ func (s *querySharding) getShardsForQuery(ctx context.Context, tenantIDs []strin
 	}
 
 	// Check the default number of shards configured for the given tenant.
-	totalShards := validation.SmallestPositiveIntPerTenant(tenantIDs, s.limit.QueryShardingTotalShards)
+	totalShards := queryShardingTotalShardsForTenants(tenantIDs, s.limit)
 	if totalShards <= 1 {
 		return 1
 	}
 
 	// Ensure there's no regexp matcher longer than the configured limit.
-	maxRegexpSizeBytes := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, s.limit.QueryShardingMaxRegexpSizeBytes)
+	maxRegexpSizeBytes := queryShardingMaxRegexpSizeBytesForTenants(tenantIDs, s.limit)
 	if maxRegexpSizeBytes > 0 {
 		if longest := longestRegexpMatcherBytes(queryExpr); longest > maxRegexpSizeBytes {
 			level.Debug(spanLog).Log(
@@ -298,7 +305,7 @@ func (s *querySharding) getShardsForQuery(ctx context.Context, tenantIDs []strin
 		totalShards = int(r.GetOptions().TotalShards)
 	}
 
-	maxShardedQueries := validation.SmallestPositiveIntPerTenant(tenantIDs, s.limit.QueryShardingMaxShardedQueries)
+	maxShardedQueries := queryShardingMaxShardedQueriesForTenants(tenantIDs, s.limit)
 	hints := r.GetHints()
 
 	if v, ok := hints.GetCardinalityEstimate().(*Hints_EstimatedSeriesCount); ok && s.maxSeriesPerShard > 0 {
@@ -362,7 +369,7 @@ func (s *querySharding) getShardsForQuery(ctx context.Context, tenantIDs []strin
 	//
 	// (Optimization is only activated when given *block* was sharded with correct compactor shards,
 	// but we can only adjust totalShards "globally", ie. for all queried blocks.)
-	compactorShardCount := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, s.limit.CompactorSplitAndMergeShards)
+	compactorShardCount := compactorSplitAndMergeShardsForTenants(tenantIDs, s.limit)
 	if compactorShardCount > 1 {
 		prevTotalShards := totalShards
 
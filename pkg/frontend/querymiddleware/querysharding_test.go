@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/dskit/cache"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/user"
 	"github.com/grafana/regexp"
@@ -42,6 +43,7 @@ import (
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/globalerror"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
 
@@ -1741,6 +1743,110 @@ func TestQuerySharding_Warnings(t *testing.T) {
 	}
 }
 
+func TestQuerySharding_PartialResponse(t *testing.T) {
+	numSeries := 10
+	endTime := 100
+	storageSeries := make([]*promql.StorageSeries, 0, numSeries)
+	floats := make([]promql.FPoint, 0, endTime)
+	for i := 0; i < endTime; i++ {
+		floats = append(floats, promql.FPoint{
+			T: int64(i * 1000),
+			F: float64(i),
+		})
+	}
+	for i := 0; i < numSeries; i++ {
+		storageSeries = append(storageSeries, promql.NewStorageSeries(promql.Series{
+			Metric: labels.FromStrings("__name__", "test_float", "series", fmt.Sprint(i)),
+			Floats: floats,
+		}))
+	}
+
+	partialResponseWarning := globalerror.StoreGatewayPartialResponse.Message("some blocks couldn't be queried")
+	engine := newEngine()
+	downstream := &downstreamHandler{
+		engine:    engine,
+		queryable: storageSeriesQueryable(storageSeries),
+	}
+
+	// The downstream returns a partial response for the first of the shards, and for the non sharded queries.
+	var downstreamReqsMx sync.Mutex
+	var downstreamReqs int
+	partialDownstream := HandlerFunc(func(ctx context.Context, r Request) (Response, error) {
+		downstreamReqsMx.Lock()
+		downstreamReqs++
+		downstreamReqsMx.Unlock()
+
+		res, err := downstream.Do(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(r.GetQuery(), sharding.FormatShardIDLabelValue(0, 2)) || !strings.Contains(r.GetQuery(), sharding.ShardLabel) {
+			res.(*PrometheusResponse).Warnings = append(res.(*PrometheusResponse).Warnings, partialResponseWarning)
+		}
+		return res, nil
+	})
+
+	t.Run("sharded range query", func(t *testing.T) {
+		downstreamReqs = 0
+		cacheBackend := cache.NewInstrumentedMockCache()
+		reg := prometheus.NewPedanticRegistry()
+		limits := mockLimits{totalShards: 2, resultsCacheTTL: resultsCacheTTL, resultsCacheOutOfOrderWindowTTL: resultsCacheLowerTTL}
+		splitware := newSplitAndCacheMiddleware(
+			true,
+			true,
+			24*time.Hour,
+			limits,
+			newTestPrometheusCodec(),
+			cacheBackend,
+			ConstSplitter(day),
+			PrometheusResponseExtractor{},
+			resultsCacheAlwaysEnabled,
+			log.NewNopLogger(),
+			reg,
+		)
+		shardingware := newQueryShardingMiddleware(log.NewNopLogger(), engine, limits, 0, reg)
+		handler := splitware.Wrap(shardingware.Wrap(partialDownstream))
+
+		req := &PrometheusRangeQueryRequest{
+			Path:  "/query_range",
+			Start: 0,
+			End:   int64(endTime * 1000),
+			Step:  (20 * time.Second).Milliseconds(),
+			Query: `sum(test_float)`,
+		}
+		ctx := user.InjectOrgID(context.Background(), "test")
+
+		for i := 1; i <= 2; i++ {
+			res, err := handler.Do(ctx, req)
+			require.NoError(t, err)
+			require.NotEmpty(t, res.(*PrometheusResponse).Data.Result)
+			require.Equal(t, []string{partialResponseWarning}, res.(*PrometheusResponse).Warnings)
+
+			// The partial response isn't cached, so each request runs the 2 shards again.
+			require.Equal(t, 2*i, downstreamReqs)
+			require.Zero(t, cacheBackend.CountStoreCalls())
+		}
+	})
+
+	t.Run("split instant query", func(t *testing.T) {
+		downstreamReqs = 0
+		splitware := newSplitInstantQueryByIntervalMiddleware(mockLimits{splitInstantQueriesInterval: 20 * time.Second}, log.NewNopLogger(), engine, nil)
+
+		req := &PrometheusInstantQueryRequest{
+			Path:  "/query",
+			Time:  int64(endTime * 1000),
+			Query: `sum_over_time(test_float[1m])`,
+		}
+		res, err := splitware.Wrap(partialDownstream).Do(user.InjectOrgID(context.Background(), "test"), req)
+		require.NoError(t, err)
+		require.NotEmpty(t, res.(*PrometheusResponse).Data.Result)
+		require.Equal(t, []string{partialResponseWarning}, res.(*PrometheusResponse).Warnings)
+
+		// Ensure the query has actually been split.
+		require.Greater(t, downstreamReqs, 1)
+	})
+}
+
 func BenchmarkQuerySharding(b *testing.B) {
 	var shards []int
 
@@ -32,6 +32,7 @@ import (
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/globalerror"
 	"github.com/grafana/mimir/pkg/util/math"
 )
 
@@ -238,6 +239,17 @@ func isResponseCachable(r Response, logger log.Logger) bool {
 		}
 	}
 
+	// Partial responses, returned by the queriers when some blocks couldn't be fetched from the store-gateways,
+	// must not be cached, otherwise the missing data would be served from the cache until the entry expires.
+	if promRes, ok := r.(*PrometheusResponse); ok {
+		for _, w := range promRes.Warnings {
+			if globalerror.StoreGatewayPartialResponse.IsInMessage(w) {
+				level.Debug(logger).Log("msg", "response is a partial response, not caching the response")
+				return false
+			}
+		}
+	}
+
 	return true
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/globalerror"
 )
 
 func TestResultsCacheConfig_Validate(t *testing.T) {
@@ -340,6 +341,20 @@ func TestIsResponseCachable(t *testing.T) {
 			}),
 			expected: false,
 		},
+		{
+			name: "response with warnings",
+			response: Response(&PrometheusResponse{
+				Warnings: []string{"PromQL info: metric might not be a counter, name does not end in _total/_sum/_count/_bucket"},
+			}),
+			expected: true,
+		},
+		{
+			name: "partial response",
+			response: Response(&PrometheusResponse{
+				Warnings: []string{globalerror.StoreGatewayPartialResponse.Message("partial response")},
+			}),
+			expected: false,
+		},
 		{
 			name:     "broken response",
 			response: Response(&PrometheusResponse{}),
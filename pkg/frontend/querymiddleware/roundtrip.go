@@ -315,6 +315,14 @@ func newQueryTripperware(
 		}
 
 		return RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			planReq, ok, err := newQueryPlanRequest(r)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				r = planReq
+			}
+
 			switch {
 			case isRangeQuery(r.URL.Path):
 				return queryrange.RoundTrip(r)
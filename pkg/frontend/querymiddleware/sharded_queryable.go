@@ -104,9 +104,14 @@ func (q *shardedQuerier) Select(ctx context.Context, _ bool, hints *storage.Sele
 }
 
 // handleEmbeddedQueries concurrently executes the provided queries through the downstream handler.
-// The returned storage.SeriesSet contains sorted series.
+// The returned storage.SeriesSet contains sorted series, and the warnings of the embedded queries
+// responses as annotations, so that they're returned in the response of the outer query.
 func (q *shardedQuerier) handleEmbeddedQueries(ctx context.Context, queries []string, hints *storage.SelectHints) storage.SeriesSet {
-	streams := make([][]SampleStream, len(queries))
+	var (
+		streams    = make([][]SampleStream, len(queries))
+		warningsMx sync.Mutex
+		warnings   annotations.Annotations
+	)
 
 	// Concurrently run each query. It breaks and cancels each worker context on first error.
 	err := concurrency.ForEachJob(ctx, len(queries), len(queries), func(ctx context.Context, idx int) error {
@@ -121,7 +126,16 @@ func (q *shardedQuerier) handleEmbeddedQueries(ctx context.Context, queries []st
 		}
 		streams[idx] = resStreams // No mutex is needed since each job writes its own index. This is like writing separate variables.
 
-		q.responseHeaders.mergeHeaders(resp.(*PrometheusResponse).Headers)
+		promRes := resp.(*PrometheusResponse)
+		q.responseHeaders.mergeHeaders(promRes.Headers)
+
+		if len(promRes.Warnings) > 0 {
+			warningsMx.Lock()
+			for _, w := range promRes.Warnings {
+				warnings.Add(errors.New(w))
+			}
+			warningsMx.Unlock()
+		}
 		return nil
 	})
 
@@ -129,7 +143,7 @@ func (q *shardedQuerier) handleEmbeddedQueries(ctx context.Context, queries []st
 		return storage.ErrSeriesSet(err)
 	}
 
-	return newSeriesSetFromEmbeddedQueriesResults(streams, hints)
+	return series.NewSeriesSetWithWarnings(newSeriesSetFromEmbeddedQueriesResults(streams, hints), warnings)
 }
 
 // LabelValues implements storage.LabelQuerier.
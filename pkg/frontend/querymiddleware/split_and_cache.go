@@ -27,7 +27,6 @@ import (
 	apierror "github.com/grafana/mimir/pkg/api/error"
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
-	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 const (
@@ -147,10 +146,15 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 		return nil, err
 	}
 
+	plan := queryPlanFromContext(ctx)
+	if len(splitReqs) > 1 {
+		plan.addStep("split_by_interval_and_results_cache", "split the query by %s into %d queries", model.Duration(s.splitInterval), len(splitReqs))
+	}
+
 	isCacheEnabled := s.cacheEnabled && (s.shouldCacheReq == nil || s.shouldCacheReq(req))
-	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, s.limits.MaxCacheFreshness)
+	maxCacheFreshness := maxCacheFreshnessForTenants(tenantIDs, s.limits)
 	maxCacheTime := int64(model.Now().Add(-maxCacheFreshness))
-	cacheUnalignedRequests := validation.AllTrueBooleansPerTenant(tenantIDs, s.limits.ResultsCacheForUnalignedQueryEnabled)
+	cacheUnalignedRequests := resultsCacheForUnalignedQueryEnabledForTenants(tenantIDs, s.limits)
 
 	// Lookup the results cache.
 	if isCacheEnabled {
@@ -165,6 +169,7 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 			if cachable, reason := isRequestCachable(splitReq.orig, maxCacheTime, cacheUnalignedRequests, s.logger); !cachable {
 				splitReq.downstreamRequests = []Request{splitReq.orig}
 				s.metrics.queryResultCacheSkippedCount.WithLabelValues(reason).Inc()
+				plan.addCacheLookup(splitReq.orig, "not cachable: "+reason)
 				continue
 			}
 
@@ -180,6 +185,7 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 			if len(extents) == 0 {
 				// We just need to run the request as is because no part of it has been cached yet.
 				lookupReqs[lookupIdx].downstreamRequests = []Request{lookupReqs[lookupIdx].orig}
+				plan.addCacheLookup(lookupReqs[lookupIdx].orig, queryPlanCacheMiss)
 				continue
 			}
 
@@ -198,15 +204,18 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 				}
 
 				lookupReqs[lookupIdx].cachedResponses = []Response{response}
+				plan.addCacheLookup(lookupReqs[lookupIdx].orig, queryPlanCacheHit)
 				continue
 			}
 
 			lookupReqs[lookupIdx].downstreamRequests = requests
 			lookupReqs[lookupIdx].cachedResponses = responses
 			lookupReqs[lookupIdx].cachedExtents = extents
+			plan.addCacheLookup(lookupReqs[lookupIdx].orig, queryPlanCachePartialHit)
 		}
 	} else {
 		// Cache is disabled. We've just to execute the original request.
+		plan.addStep("split_by_interval_and_results_cache", "the results cache isn't used for the query")
 		for _, splitReq := range splitReqs {
 			splitReq.downstreamRequests = []Request{splitReq.orig}
 		}
@@ -234,8 +243,9 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 		}
 	}
 
-	// Store the updated response in the results cache.
-	if isCacheEnabled && len(execReqs) > 0 {
+	// Store the updated response in the results cache, unless the query hasn't been executed
+	// because only its plan has been requested.
+	if isCacheEnabled && len(execReqs) > 0 && plan == nil {
 		for _, splitReq := range splitReqs {
 			// If there are no downstream requests it means the response was entirely picked up from the cache
 			// so there's no need to store it again in the cache (because nothing has changed).
@@ -409,9 +419,9 @@ func (s *splitAndCacheMiddleware) fetchCacheExtents(ctx context.Context, now tim
 }
 
 func (s *splitAndCacheMiddleware) getCacheOptions(tenantIDs []string) (ttl, ttlInOOO, oooWindow time.Duration) {
-	ttl = validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, s.limits.ResultsCacheTTL)
-	ttlInOOO = validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, s.limits.ResultsCacheTTLForOutOfOrderTimeWindow)
-	oooWindow = validation.MaxDurationPerTenant(tenantIDs, s.limits.OutOfOrderTimeWindow)
+	ttl = resultsCacheTTLForTenants(tenantIDs, s.limits)
+	ttlInOOO = resultsCacheTTLForOutOfOrderTimeWindowForTenants(tenantIDs, s.limits)
+	oooWindow = outOfOrderTimeWindowForTenants(tenantIDs, s.limits)
 	return
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/prometheus/prometheus/promql/parser"
 
@@ -20,7 +21,6 @@ import (
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/storage/lazyquery"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
-	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 const (
@@ -141,26 +141,32 @@ func (s *splitInstantQueryByIntervalMiddleware) Do(ctx context.Context, req Requ
 			level.Error(spanLog).Log("msg", "failed to map the input query, falling back to try executing without splitting", "err", err)
 		}
 		s.metrics.splittingSkipped.WithLabelValues(skippedReasonMappingFailed).Inc()
+		queryPlanFromContext(ctx).addStep("split_instant_query_by_interval", "the query isn't split by %s: %s", model.Duration(splitInterval), skippedReasonMappingFailed)
 		return s.next.Do(ctx, req)
 	}
 
 	if mapperStats.GetSplitQueries() == 0 {
 		// the query cannot be split, so continue
 		level.Debug(spanLog).Log("msg", "input query resulted in a no operation, falling back to try executing without splitting")
-		switch mapperStats.GetSkippedReason() {
+		skippedReason := mapperStats.GetSkippedReason()
+		switch skippedReason {
 		case astmapper.SkippedReasonSmallInterval:
 			s.metrics.splittingSkipped.WithLabelValues(string(astmapper.SkippedReasonSmallInterval)).Inc()
 		case astmapper.SkippedReasonSubquery:
 			s.metrics.splittingSkipped.WithLabelValues(string(astmapper.SkippedReasonSubquery)).Inc()
 		default:
 			// If there are no split queries, the default skipped reason case is a non-splittable query
+			skippedReason = astmapper.SkippedReasonNonSplittable
 			s.metrics.splittingSkipped.WithLabelValues(string(astmapper.SkippedReasonNonSplittable)).Inc()
 		}
+		queryPlanFromContext(ctx).addStep("split_instant_query_by_interval", "the query isn't split by %s: %s", model.Duration(splitInterval), skippedReason)
 		return s.next.Do(ctx, req)
 	}
 
 	level.Debug(spanLog).Log("msg", "instant query has been split by interval", "rewritten", instantSplitQuery, "split_queries", mapperStats.GetSplitQueries())
 
+	queryPlanFromContext(ctx).addStep("split_instant_query_by_interval", "split the query by %s into %d queries: %s", model.Duration(splitInterval), mapperStats.GetSplitQueries(), instantSplitQuery)
+
 	// Update query stats.
 	queryStats := stats.FromContext(ctx)
 	queryStats.AddSplitQueries(uint32(mapperStats.GetSplitQueries()))
@@ -207,7 +213,7 @@ func (s *splitInstantQueryByIntervalMiddleware) getSplitIntervalForQuery(tenants
 		return 0
 	}
 
-	splitInterval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantsIds, s.limits.SplitInstantQueriesByInterval)
+	splitInterval := splitInstantQueriesByIntervalForTenants(tenantsIds, s.limits)
 	if splitInterval <= 0 {
 		return 0
 	}
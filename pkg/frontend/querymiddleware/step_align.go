@@ -16,6 +16,9 @@ func newStepAlignMiddleware() Middleware {
 		return HandlerFunc(func(ctx context.Context, r Request) (Response, error) {
 			start := (r.GetStart() / r.GetStep()) * r.GetStep()
 			end := (r.GetEnd() / r.GetStep()) * r.GetStep()
+			if start != r.GetStart() || end != r.GetEnd() {
+				queryPlanFromContext(ctx).addStep("step_align", "aligned the time range to the step: start %s, end %s", formatQueryPlanTime(start), formatQueryPlanTime(end))
+			}
 			return next.Do(ctx, r.WithStartEnd(start, end))
 		})
 	})
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+
+	"github.com/grafana/mimir/pkg/ingester/activeseries"
+	"github.com/grafana/mimir/pkg/ingester/client"
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+// activeSeriesTargetSizeBytes is the maximum allowed size in bytes for an active series response message.
+// We arbitrarily set it to 1mb to avoid reaching the actual gRPC default limit (4mb).
+const activeSeriesTargetSizeBytes = 1 * 1024 * 1024
+
+// ActiveSeries streams the labels of the active series matching the request matchers.
+func (i *Ingester) ActiveSeries(req *client.ActiveSeriesRequest, srv client.Ingester_ActiveSeriesServer) error {
+	if err := i.checkRunning(); err != nil {
+		return err
+	}
+	if err := i.checkReadOverloaded(); err != nil {
+		return err
+	}
+
+	userID, err := tenant.TenantID(srv.Context())
+	if err != nil {
+		return err
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		return nil
+	}
+	idx, err := db.Head().Index()
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	matchers, err := client.FromLabelMatchers(req.GetMatchers())
+	if err != nil {
+		return err
+	}
+
+	ctx := srv.Context()
+	postings, err := tsdb.PostingsForMatchers(ctx, idx, matchers...)
+	if err != nil {
+		return err
+	}
+
+	return activeSeries(idx, activeseries.NewPostings(db.activeSeries, postings), activeSeriesTargetSizeBytes, srv)
+}
+
+// activeSeries streams the labels of the series in postings.
+// Messages are immediately sent as soon they reach message size threshold defined in `messageSizeThreshold` param.
+func activeSeries(idx tsdb.IndexReader, postings *activeseries.Postings, messageSizeThreshold int, srv client.Ingester_ActiveSeriesServer) error {
+	ctx := srv.Context()
+
+	var (
+		builder           labels.ScratchBuilder
+		response          client.ActiveSeriesResponse
+		responseSizeBytes int
+		seriesCount       int
+	)
+
+	for postings.Next() {
+		seriesCount++
+		if seriesCount%checkContextErrorSeriesCount == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if err := idx.Series(postings.At(), &builder, nil); err != nil {
+			// Series may have been removed from the head in the meanwhile.
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+
+		metric := &mimirpb.Metric{Labels: mimirpb.FromLabelsToLabelAdapters(builder.Labels())}
+		response.Metric = append(response.Metric, metric)
+		responseSizeBytes += metric.Size()
+
+		if responseSizeBytes >= messageSizeThreshold {
+			if err := client.SendActiveSeriesResponse(srv, &response); err != nil {
+				return err
+			}
+			response.Metric = response.Metric[:0]
+			responseSizeBytes = 0
+		}
+	}
+	if err := postings.Err(); err != nil {
+		return err
+	}
+
+	// Send the last message if there is some data that was not sent.
+	if len(response.Metric) > 0 {
+		return client.SendActiveSeriesResponse(srv, &response)
+	}
+	return nil
+}
+
+// ActiveSeriesBreakdown streams the number of active series and native histogram buckets per metric name,
+// and per value of the requested label if any, of the active series matching the request matchers.
+func (i *Ingester) ActiveSeriesBreakdown(req *client.ActiveSeriesBreakdownRequest, srv client.Ingester_ActiveSeriesBreakdownServer) error {
+	if err := i.checkRunning(); err != nil {
+		return err
+	}
+	if err := i.checkReadOverloaded(); err != nil {
+		return err
+	}
+
+	userID, err := tenant.TenantID(srv.Context())
+	if err != nil {
+		return err
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		return nil
+	}
+	idx, err := db.Head().Index()
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	matchers, err := client.FromLabelMatchers(req.GetMatchers())
+	if err != nil {
+		return err
+	}
+	// Only the series with a metric name can be broken down by metric name.
+	matchers = append(matchers, labels.MustNewMatcher(labels.MatchNotEqual, labels.MetricName, ""))
+
+	ctx := srv.Context()
+	postings, err := tsdb.PostingsForMatchers(ctx, idx, matchers...)
+	if err != nil {
+		return err
+	}
+
+	return activeSeriesBreakdown(idx, db.activeSeries, activeseries.NewPostings(db.activeSeries, postings), req.GetLabelName(), activeSeriesTargetSizeBytes, srv)
+}
+
+type activeSeriesBreakdownKey struct {
+	metricName, labelValue string
+}
+
+// activeSeriesBreakdown aggregates the series in postings by metric name and labelName value, and streams the result.
+// Messages are immediately sent as soon they reach message size threshold defined in `messageSizeThreshold` param.
+func activeSeriesBreakdown(idx tsdb.IndexReader, active *activeseries.ActiveSeries, postings *activeseries.Postings, labelName string, messageSizeThreshold int, srv client.Ingester_ActiveSeriesBreakdownServer) error {
+	ctx := srv.Context()
+
+	var (
+		builder     labels.ScratchBuilder
+		seriesCount int
+		breakdown   = map[activeSeriesBreakdownKey]*client.ActiveSeriesBreakdownItem{}
+	)
+
+	for postings.Next() {
+		seriesCount++
+		if seriesCount%checkContextErrorSeriesCount == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		ref := postings.At()
+		if err := idx.Series(ref, &builder, nil); err != nil {
+			// Series may have been removed from the head in the meanwhile.
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+
+		lbls := builder.Labels()
+		key := activeSeriesBreakdownKey{metricName: lbls.Get(labels.MetricName)}
+		if labelName != "" {
+			key.labelValue = lbls.Get(labelName)
+		}
+
+		item, ok := breakdown[key]
+		if !ok {
+			item = &client.ActiveSeriesBreakdownItem{MetricName: key.metricName, LabelValue: key.labelValue}
+			breakdown[key] = item
+		}
+		item.SeriesCount++
+		if buckets, ok := active.NativeHistogramBuckets(ref); ok {
+			item.NativeHistogramBuckets += uint64(buckets)
+		}
+	}
+	if err := postings.Err(); err != nil {
+		return err
+	}
+
+	response := client.ActiveSeriesBreakdownResponse{}
+	responseSizeBytes := 0
+	for _, item := range breakdown {
+		response.Items = append(response.Items, item)
+		responseSizeBytes += item.Size()
+
+		if responseSizeBytes >= messageSizeThreshold {
+			if err := client.SendActiveSeriesBreakdownResponse(srv, &response); err != nil {
+				return err
+			}
+			response.Items = response.Items[:0]
+			responseSizeBytes = 0
+		}
+	}
+
+	// Send the last message if there is some data that was not sent.
+	if len(response.Items) > 0 {
+		return client.SendActiveSeriesBreakdownResponse(srv, &response)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/mimir/pkg/ingester/client"
+	"github.com/grafana/mimir/pkg/mimirpb"
+	util_test "github.com/grafana/mimir/pkg/util/test"
+)
+
+func TestIngester_ActiveSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cfg := defaultIngesterTestConfig(t)
+	i := requireActiveIngesterWithBlocksStorage(t, cfg, registry)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	// Push a series, and make it inactive by purging the active series in the future.
+	require.NoError(t, pushSeriesToIngester(ctx, t, i, []series{
+		{lbls: labels.FromStrings(labels.MetricName, "metric_0", "status", "500"), value: 1, timestamp: 100000},
+	}))
+	i.getTSDB("test").activeSeries.Purge(time.Now().Add(cfg.ActiveSeriesMetrics.IdleTimeout + time.Second))
+
+	require.NoError(t, pushSeriesToIngester(ctx, t, i, []series{
+		{lbls: labels.FromStrings(labels.MetricName, "metric_0", "status", "200"), value: 1, timestamp: 110000},
+		{lbls: labels.FromStrings(labels.MetricName, "metric_1", "env", "prod"), value: 1, timestamp: 110000},
+	}))
+
+	tests := map[string]struct {
+		matchers       []*client.LabelMatcher
+		expectedSeries []labels.Labels
+	}{
+		"should return only the active series": {
+			matchers: []*client.LabelMatcher{{Type: client.REGEX_MATCH, Name: labels.MetricName, Value: ".+"}},
+			expectedSeries: []labels.Labels{
+				labels.FromStrings(labels.MetricName, "metric_0", "status", "200"),
+				labels.FromStrings(labels.MetricName, "metric_1", "env", "prod"),
+			},
+		},
+		"should return only the active series matching the matchers": {
+			matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "metric_0"}},
+			expectedSeries: []labels.Labels{
+				labels.FromStrings(labels.MetricName, "metric_0", "status", "200"),
+			},
+		},
+		"should return no series if the matching series are not active": {
+			matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: "status", Value: "500"}},
+		},
+	}
+
+	for tName, tc := range tests {
+		t.Run(tName, func(t *testing.T) {
+			s := &mockActiveSeriesServer{context: ctx}
+			require.NoError(t, i.ActiveSeries(&client.ActiveSeriesRequest{Matchers: tc.matchers}, s))
+
+			if len(tc.expectedSeries) == 0 {
+				require.Len(t, s.SentResponses, 0)
+				return
+			}
+			require.Len(t, s.SentResponses, 1)
+			require.ElementsMatch(t, tc.expectedSeries, s.series())
+		})
+	}
+
+	t.Run("limited due to resource utilization", func(t *testing.T) {
+		origLimiter := i.utilizationBasedLimiter
+		t.Cleanup(func() {
+			i.utilizationBasedLimiter = origLimiter
+		})
+		i.utilizationBasedLimiter = &fakeUtilizationBasedLimiter{limitingReason: "cpu"}
+
+		err := i.ActiveSeries(&client.ActiveSeriesRequest{}, nil)
+		stat, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusServiceUnavailable, int(stat.Code()))
+		require.Equal(t, tooBusyErrorMsg, stat.Message())
+		verifyUtilizationLimitedRequestsMetric(t, registry)
+	})
+}
+
+func TestIngester_ActiveSeries_SentInBatches(t *testing.T) {
+	const labelValueSize = 1024
+	const seriesCount = 3 * activeSeriesTargetSizeBytes / labelValueSize
+
+	in := prepareHealthyIngester(t)
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	writeReq := &mimirpb.WriteRequest{Source: mimirpb.API}
+	for i := 0; i < seriesCount; i++ {
+		writeReq.Timeseries = append(writeReq.Timeseries, mimirpb.PreallocTimeseries{TimeSeries: &mimirpb.TimeSeries{
+			Labels:  mimirpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "metric", "value", fmt.Sprintf("%0*d", labelValueSize, i))),
+			Samples: []mimirpb.Sample{{TimestampMs: 1_000, Value: 1}},
+		}})
+	}
+	_, err := in.Push(ctx, writeReq)
+	require.NoError(t, err)
+
+	s := &mockActiveSeriesServer{context: ctx}
+	req := &client.ActiveSeriesRequest{Matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "metric"}}}
+	require.NoError(t, in.ActiveSeries(req, s))
+
+	// Each series is a bit bigger than labelValueSize, so the series are sent in more than 3 messages.
+	require.Len(t, s.SentResponses, 4)
+	for _, resp := range s.SentResponses {
+		require.Less(t, resp.Size(), 2*activeSeriesTargetSizeBytes)
+	}
+	require.Len(t, s.series(), seriesCount)
+}
+
+func TestIngester_ActiveSeriesBreakdown(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cfg := defaultIngesterTestConfig(t)
+	i := requireActiveIngesterWithBlocksStorage(t, cfg, registry)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	// Push a series, and make it inactive by purging the active series in the future.
+	require.NoError(t, pushSeriesToIngester(ctx, t, i, []series{
+		{lbls: labels.FromStrings(labels.MetricName, "metric_0", "status", "500"), value: 1, timestamp: 100000},
+	}))
+	i.getTSDB("test").activeSeries.Purge(time.Now().Add(cfg.ActiveSeriesMetrics.IdleTimeout + time.Second))
+
+	require.NoError(t, pushSeriesToIngester(ctx, t, i, []series{
+		{lbls: labels.FromStrings(labels.MetricName, "metric_0", "status", "200"), value: 1, timestamp: 110000},
+		{lbls: labels.FromStrings(labels.MetricName, "metric_0", "status", "400"), value: 1, timestamp: 110000},
+		{lbls: labels.FromStrings(labels.MetricName, "metric_1", "env", "prod"), value: 1, timestamp: 110000},
+	}))
+	_, err := i.Push(ctx, mimirpb.NewWriteRequest(nil, mimirpb.API).AddHistogramSeries(
+		[][]mimirpb.LabelAdapter{
+			mimirpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "histogram", "status", "200")),
+			mimirpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "histogram", "status", "500")),
+		},
+		[]mimirpb.Histogram{
+			mimirpb.FromHistogramToHistogramProto(110000, util_test.GenerateTestHistogram(1)),
+			mimirpb.FromHistogramToHistogramProto(110000, util_test.GenerateTestHistogram(2)),
+		}, nil))
+	require.NoError(t, err)
+
+	_, _, totalBuckets := i.getTSDB("test").activeSeries.Active()
+	require.Greater(t, totalBuckets, 0)
+	bucketsPerHistogram := uint64(totalBuckets / 2)
+
+	tests := map[string]struct {
+		req           *client.ActiveSeriesBreakdownRequest
+		expectedItems []*client.ActiveSeriesBreakdownItem
+	}{
+		"should break down the active series by metric name": {
+			req: &client.ActiveSeriesBreakdownRequest{},
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "metric_0", SeriesCount: 2},
+				{MetricName: "metric_1", SeriesCount: 1},
+				{MetricName: "histogram", SeriesCount: 2, NativeHistogramBuckets: 2 * bucketsPerHistogram},
+			},
+		},
+		"should break down the active series by metric name and label value": {
+			req: &client.ActiveSeriesBreakdownRequest{LabelName: "status"},
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "metric_0", LabelValue: "200", SeriesCount: 1},
+				{MetricName: "metric_0", LabelValue: "400", SeriesCount: 1},
+				{MetricName: "metric_1", LabelValue: "", SeriesCount: 1},
+				{MetricName: "histogram", LabelValue: "200", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+				{MetricName: "histogram", LabelValue: "500", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+		},
+		"should break down only the active series matching the matchers": {
+			req: &client.ActiveSeriesBreakdownRequest{
+				Matchers:  []*client.LabelMatcher{{Type: client.EQUAL, Name: "status", Value: "500"}},
+				LabelName: "status",
+			},
+			expectedItems: []*client.ActiveSeriesBreakdownItem{
+				{MetricName: "histogram", LabelValue: "500", SeriesCount: 1, NativeHistogramBuckets: bucketsPerHistogram},
+			},
+		},
+		"should return no items if the matching series are not active": {
+			req: &client.ActiveSeriesBreakdownRequest{
+				Matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "unknown"}},
+			},
+		},
+	}
+
+	for tName, tc := range tests {
+		t.Run(tName, func(t *testing.T) {
+			s := &mockActiveSeriesBreakdownServer{context: ctx}
+			require.NoError(t, i.ActiveSeriesBreakdown(tc.req, s))
+
+			if len(tc.expectedItems) == 0 {
+				require.Len(t, s.SentResponses, 0)
+				return
+			}
+			require.Len(t, s.SentResponses, 1)
+			require.ElementsMatch(t, tc.expectedItems, s.SentResponses[0].Items)
+		})
+	}
+
+	t.Run("limited due to resource utilization", func(t *testing.T) {
+		origLimiter := i.utilizationBasedLimiter
+		t.Cleanup(func() {
+			i.utilizationBasedLimiter = origLimiter
+		})
+		i.utilizationBasedLimiter = &fakeUtilizationBasedLimiter{limitingReason: "cpu"}
+
+		err := i.ActiveSeriesBreakdown(&client.ActiveSeriesBreakdownRequest{}, nil)
+		stat, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusServiceUnavailable, int(stat.Code()))
+		require.Equal(t, tooBusyErrorMsg, stat.Message())
+		verifyUtilizationLimitedRequestsMetric(t, registry)
+	})
+}
+
+type mockActiveSeriesServer struct {
+	client.Ingester_ActiveSeriesServer
+	SentResponses []client.ActiveSeriesResponse
+	context       context.Context
+}
+
+func (m *mockActiveSeriesServer) Send(resp *client.ActiveSeriesResponse) error {
+	// The response is reused by the sender, so we need to copy it.
+	metrics := make([]*mimirpb.Metric, len(resp.Metric))
+	for i, metric := range resp.Metric {
+		metrics[i] = &mimirpb.Metric{Labels: mimirpb.FromLabelsToLabelAdapters(mimirpb.FromLabelAdaptersToLabelsWithCopy(metric.Labels))}
+	}
+	m.SentResponses = append(m.SentResponses, client.ActiveSeriesResponse{Metric: metrics})
+	return nil
+}
+
+func (m *mockActiveSeriesServer) Context() context.Context {
+	return m.context
+}
+
+func (m *mockActiveSeriesServer) series() []labels.Labels {
+	var result []labels.Labels
+	for _, resp := range m.SentResponses {
+		for _, metric := range resp.Metric {
+			result = append(result, mimirpb.FromLabelAdaptersToLabels(metric.Labels))
+		}
+	}
+	return result
+}
+
+type mockActiveSeriesBreakdownServer struct {
+	client.Ingester_ActiveSeriesBreakdownServer
+	SentResponses []client.ActiveSeriesBreakdownResponse
+	context       context.Context
+}
+
+func (m *mockActiveSeriesBreakdownServer) Send(resp *client.ActiveSeriesBreakdownResponse) error {
+	// The response is reused by the sender, so we need to copy it.
+	items := make([]*client.ActiveSeriesBreakdownItem, len(resp.Items))
+	for i, item := range resp.Items {
+		itemCopy := *item
+		items[i] = &itemCopy
+	}
+	m.SentResponses = append(m.SentResponses, client.ActiveSeriesBreakdownResponse{Items: items})
+	return nil
+}
+
+func (m *mockActiveSeriesBreakdownServer) Context() context.Context {
+	return m.context
+}
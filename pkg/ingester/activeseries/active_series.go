@@ -16,6 +16,8 @@ import (
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/util/zeropool"
 	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/costattribution"
 )
 
 const (
@@ -42,9 +44,10 @@ type ActiveSeries struct {
 	stripes [numStripes]seriesStripe
 	deleted deletedSeries
 
-	// matchersMutex protects matchers and lastMatchersUpdate.
+	// matchersMutex protects matchers, costAttribution and lastMatchersUpdate.
 	matchersMutex      sync.RWMutex
 	matchers           *Matchers
+	costAttribution    *costattribution.Tracker
 	lastMatchersUpdate time.Time
 
 	// The duration after which series become inactive.
@@ -54,7 +57,8 @@ type ActiveSeries struct {
 
 // seriesStripe holds a subset of the series timestamps for a single tenant.
 type seriesStripe struct {
-	matchers *Matchers
+	matchers        *Matchers
+	costAttribution *costattribution.Tracker
 
 	deleted *deletedSeries
 
@@ -65,12 +69,13 @@ type seriesStripe struct {
 
 	mu                                   sync.RWMutex
 	refs                                 map[storage.SeriesRef]seriesEntry
-	active                               uint32   // Number of active entries in this stripe. Only decreased during purge or clear.
-	activeMatching                       []uint32 // Number of active entries in this stripe matching each matcher of the configured Matchers.
-	activeNativeHistograms               uint32   // Number of active entries (only native histograms) in this stripe. Only decreased during purge or clear.
-	activeMatchingNativeHistograms       []uint32 // Number of active entries (only native histograms) in this stripe matching each matcher of the configured Matchers.
-	activeNativeHistogramBuckets         uint32   // Number of buckets in active native histogram entries in this stripe. Only decreased during purge or clear.
-	activeMatchingNativeHistogramBuckets []uint32 // Number of buckets in active native histogram entries in this stripe matching each matcher of the configured Matchers.
+	active                               uint32            // Number of active entries in this stripe. Only decreased during purge or clear.
+	activeMatching                       []uint32          // Number of active entries in this stripe matching each matcher of the configured Matchers.
+	activeNativeHistograms               uint32            // Number of active entries (only native histograms) in this stripe. Only decreased during purge or clear.
+	activeMatchingNativeHistograms       []uint32          // Number of active entries (only native histograms) in this stripe matching each matcher of the configured Matchers.
+	activeNativeHistogramBuckets         uint32            // Number of buckets in active native histogram entries in this stripe. Only decreased during purge or clear.
+	activeMatchingNativeHistogramBuckets []uint32          // Number of buckets in active native histogram entries in this stripe matching each matcher of the configured Matchers.
+	activeByAttribution                  map[string]uint32 // Number of active entries in this stripe per cost attribution value. Nil if cost attribution is disabled.
 }
 
 // seriesEntry holds a timestamp for single series.
@@ -78,16 +83,19 @@ type seriesEntry struct {
 	nanos                     *atomic.Int64        // Unix timestamp in nanoseconds. Needs to be a pointer because we don't store pointers to entries in the stripe.
 	matches                   preAllocDynamicSlice //  Index of the matcher matching
 	numNativeHistogramBuckets int                  // Number of buckets in native histogram series, -1 if not a native histogram.
+	attribution               string               // Cost attribution value, empty if cost attribution is disabled.
 
 	deleted bool // This series was marked as deleted, so before purging we need to remove the refence to it from the deletedSeries.
 }
 
-func NewActiveSeries(asm *Matchers, timeout time.Duration) *ActiveSeries {
-	c := &ActiveSeries{matchers: asm, timeout: timeout}
+// NewActiveSeries returns an ActiveSeries tracking the series matching the input matchers, and, if cat is not nil,
+// the series per cost attribution value.
+func NewActiveSeries(asm *Matchers, timeout time.Duration, cat *costattribution.Tracker) *ActiveSeries {
+	c := &ActiveSeries{matchers: asm, costAttribution: cat, timeout: timeout}
 
 	// Stripes are pre-allocated so that we only read on them and no lock is required.
 	for i := 0; i < numStripes; i++ {
-		c.stripes[i].reinitialize(asm, &c.deleted)
+		c.stripes[i].reinitialize(asm, cat, &c.deleted)
 	}
 
 	return c
@@ -104,12 +112,32 @@ func (c *ActiveSeries) ReloadMatchers(asm *Matchers, now time.Time) {
 	defer c.matchersMutex.Unlock()
 
 	for i := 0; i < numStripes; i++ {
-		c.stripes[i].reinitialize(asm, &c.deleted)
+		c.stripes[i].reinitialize(asm, c.costAttribution, &c.deleted)
 	}
 	c.matchers = asm
 	c.lastMatchersUpdate = now
 }
 
+// ReloadCostAttribution replaces the cost attribution tracker, which can be nil to disable cost attribution.
+// Like ReloadMatchers, it resets the tracked series.
+func (c *ActiveSeries) ReloadCostAttribution(cat *costattribution.Tracker, now time.Time) {
+	c.matchersMutex.Lock()
+	defer c.matchersMutex.Unlock()
+
+	for i := 0; i < numStripes; i++ {
+		c.stripes[i].reinitialize(c.matchers, cat, &c.deleted)
+	}
+	c.costAttribution = cat
+	c.lastMatchersUpdate = now
+}
+
+// CurrentCostAttribution returns the cost attribution tracker, or nil if cost attribution is disabled.
+func (c *ActiveSeries) CurrentCostAttribution() *costattribution.Tracker {
+	c.matchersMutex.RLock()
+	defer c.matchersMutex.RUnlock()
+	return c.costAttribution
+}
+
 func (c *ActiveSeries) CurrentConfig() CustomTrackersConfig {
 	c.matchersMutex.RLock()
 	defer c.matchersMutex.RUnlock()
@@ -168,6 +196,13 @@ func (c *ActiveSeries) ContainsRef(ref storage.SeriesRef) bool {
 	return c.stripes[stripeID].containsRef(ref)
 }
 
+// NativeHistogramBuckets returns the number of buckets of the series with the input ref,
+// and false if the series is not an active native histogram series.
+func (c *ActiveSeries) NativeHistogramBuckets(ref storage.SeriesRef) (int, bool) {
+	stripeID := ref % numStripes
+	return c.stripes[stripeID].nativeHistogramBuckets(ref)
+}
+
 // Active returns the total numbers of active series, active native
 // histogram series, and buckets of those native histogram series.
 // This method does not purge expired entries, so Purge should be
@@ -182,6 +217,18 @@ func (c *ActiveSeries) Active() (total, totalNativeHistograms, totalNativeHistog
 	return
 }
 
+// ActiveSince returns the number of series which have been updated at or after the input time.
+// Series that have been inactive for longer than the configured timeout may have already been purged,
+// so the input time should not be older than the configured timeout.
+func (c *ActiveSeries) ActiveSince(since time.Time) int {
+	sinceNanos := since.UnixNano()
+	total := 0
+	for s := 0; s < numStripes; s++ {
+		total += c.stripes[s].activeSince(sinceNanos)
+	}
+	return total
+}
+
 // ActiveWithMatchers returns the total number of active series, as well as a
 // slice of active series matching each one of the custom trackers provided (in
 // the same order as custom trackers are defined), and then the same thing for
@@ -205,6 +252,23 @@ func (c *ActiveSeries) ActiveWithMatchers() (total int, totalMatching []int, tot
 	return
 }
 
+// ActiveByAttribution returns the number of active series per cost attribution value, or nil if cost attribution
+// is disabled. This method does not purge expired entries, so Purge should be called periodically.
+func (c *ActiveSeries) ActiveByAttribution() map[string]int {
+	c.matchersMutex.RLock()
+	defer c.matchersMutex.RUnlock()
+
+	if c.costAttribution == nil {
+		return nil
+	}
+
+	active := map[string]int{}
+	for s := 0; s < numStripes; s++ {
+		c.stripes[s].updateActiveByAttribution(active)
+	}
+	return active
+}
+
 func (s *seriesStripe) containsRef(ref storage.SeriesRef) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -213,6 +277,17 @@ func (s *seriesStripe) containsRef(ref storage.SeriesRef) bool {
 	return ok
 }
 
+func (s *seriesStripe) nativeHistogramBuckets(ref storage.SeriesRef) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.refs[ref]
+	if !ok || entry.numNativeHistogramBuckets < 0 {
+		return 0, false
+	}
+	return entry.numNativeHistogramBuckets, true
+}
+
 func (s *seriesStripe) markDeleted(ref storage.SeriesRef, lbls labels.Labels) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -238,6 +313,30 @@ func (s *seriesStripe) getTotal() (uint32, uint32, uint32) {
 	return s.active, s.activeNativeHistograms, s.activeNativeHistogramBuckets
 }
 
+// activeSince returns the number of series in the stripe which have been updated at or after the input time in nanoseconds.
+func (s *seriesStripe) activeSince(sinceNanos int64) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := 0
+	for _, entry := range s.refs {
+		if entry.nanos.Load() >= sinceNanos {
+			active++
+		}
+	}
+	return active
+}
+
+// updateActiveByAttribution adds the number of active series per cost attribution value in the stripe to the input map.
+func (s *seriesStripe) updateActiveByAttribution(active map[string]int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for attribution, a := range s.activeByAttribution {
+		active[attribution] += int(a)
+	}
+}
+
 // getTotalAndUpdateMatching will return the total active series in the stripe and also update the slice provided
 // with each matcher's total, and will also do the same for total active series that are native histograms
 // as well as the total number of buckets in active native histogram series
@@ -262,10 +361,16 @@ func (s *seriesStripe) getTotalAndUpdateMatching(matching []int, matchingNativeH
 func (s *seriesStripe) updateSeriesTimestamp(now time.Time, series labels.Labels, ref storage.SeriesRef, numNativeHistogramBuckets int) bool {
 	nowNanos := now.UnixNano()
 
-	e, needsUpdating := s.findEntryForSeries(ref, numNativeHistogramBuckets)
+	e, needsUpdating, cat := s.findEntryForSeries(ref, numNativeHistogramBuckets)
 	created := false
 	if e == nil || needsUpdating {
-		e, created = s.findAndUpdateOrCreateEntryForSeries(ref, series, nowNanos, numNativeHistogramBuckets)
+		// The attribution of a new series is resolved before taking the stripe write lock, because
+		// the tracker has its own per-tenant lock.
+		attribution := ""
+		if e == nil && cat != nil {
+			attribution = cat.Attribution(series, now)
+		}
+		e, created = s.findAndUpdateOrCreateEntryForSeries(ref, series, nowNanos, numNativeHistogramBuckets, cat, attribution)
 	}
 
 	entryTimeSet := created
@@ -288,14 +393,18 @@ func (s *seriesStripe) updateSeriesTimestamp(now time.Time, series labels.Labels
 	return created
 }
 
-func (s *seriesStripe) findEntryForSeries(ref storage.SeriesRef, numNativeHistogramBuckets int) (*atomic.Int64, bool) {
+// findEntryForSeries returns the timestamp of the series entry, if any, whether its number of native histogram buckets
+// needs updating, and the cost attribution tracker of the stripe.
+func (s *seriesStripe) findEntryForSeries(ref storage.SeriesRef, numNativeHistogramBuckets int) (*atomic.Int64, bool, *costattribution.Tracker) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	entry := s.refs[ref]
-	return entry.nanos, entry.numNativeHistogramBuckets != numNativeHistogramBuckets
+	return entry.nanos, entry.numNativeHistogramBuckets != numNativeHistogramBuckets, s.costAttribution
 }
 
-func (s *seriesStripe) findAndUpdateOrCreateEntryForSeries(ref storage.SeriesRef, series labels.Labels, nowNanos int64, numNativeHistogramBuckets int) (entryTime *atomic.Int64, created bool) {
+// findAndUpdateOrCreateEntryForSeries creates the series entry if it doesn't exist, or updates its number of native
+// histogram buckets otherwise. The attribution is the value resolved by cat for the series, if the entry didn't exist.
+func (s *seriesStripe) findAndUpdateOrCreateEntryForSeries(ref storage.SeriesRef, series labels.Labels, nowNanos int64, numNativeHistogramBuckets int, cat *costattribution.Tracker, attribution string) (entryTime *atomic.Int64, created bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -360,6 +469,17 @@ func (s *seriesStripe) findAndUpdateOrCreateEntryForSeries(ref storage.SeriesRef
 		matches:                   matches,
 		numNativeHistogramBuckets: numNativeHistogramBuckets,
 	}
+	if s.costAttribution != nil {
+		// The attribution value of a series doesn't change until the series is purged, even if its value
+		// was attributed to the overflow value and there's room for new values later on.
+		e.attribution = attribution
+		if cat != s.costAttribution || attribution == "" {
+			// The tracker has been replaced since the attribution was resolved, which is rare enough
+			// to resolve it again under the lock.
+			e.attribution = s.costAttribution.Attribution(series, time.Unix(0, nowNanos))
+		}
+		s.activeByAttribution[e.attribution]++
+	}
 
 	s.refs[ref] = e
 	return e.nanos, true
@@ -380,10 +500,13 @@ func (s *seriesStripe) clear() {
 		s.activeMatchingNativeHistograms[i] = 0
 		s.activeMatchingNativeHistogramBuckets[i] = 0
 	}
+	if s.activeByAttribution != nil {
+		s.activeByAttribution = map[string]uint32{}
+	}
 }
 
-// Reinitialize assigns new matchers and corresponding size activeMatching slices.
-func (s *seriesStripe) reinitialize(asm *Matchers, deleted *deletedSeries) {
+// Reinitialize assigns new matchers and corresponding size activeMatching slices, and the cost attribution tracker.
+func (s *seriesStripe) reinitialize(asm *Matchers, cat *costattribution.Tracker, deleted *deletedSeries) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -397,6 +520,11 @@ func (s *seriesStripe) reinitialize(asm *Matchers, deleted *deletedSeries) {
 	s.activeMatching = resizeAndClear(len(asm.MatcherNames()), s.activeMatching)
 	s.activeMatchingNativeHistograms = resizeAndClear(len(asm.MatcherNames()), s.activeMatchingNativeHistograms)
 	s.activeMatchingNativeHistogramBuckets = resizeAndClear(len(asm.MatcherNames()), s.activeMatchingNativeHistogramBuckets)
+	s.costAttribution = cat
+	s.activeByAttribution = nil
+	if cat != nil {
+		s.activeByAttribution = map[string]uint32{}
+	}
 }
 
 func (s *seriesStripe) purge(keepUntil time.Time) {
@@ -415,6 +543,9 @@ func (s *seriesStripe) purge(keepUntil time.Time) {
 	s.activeMatching = resizeAndClear(len(s.activeMatching), s.activeMatching)
 	s.activeMatchingNativeHistograms = resizeAndClear(len(s.activeMatchingNativeHistograms), s.activeMatchingNativeHistograms)
 	s.activeMatchingNativeHistogramBuckets = resizeAndClear(len(s.activeMatchingNativeHistogramBuckets), s.activeMatchingNativeHistogramBuckets)
+	if s.activeByAttribution != nil {
+		clear(s.activeByAttribution)
+	}
 
 	oldest := int64(math.MaxInt64)
 	for ref, entry := range s.refs {
@@ -441,6 +572,9 @@ func (s *seriesStripe) purge(keepUntil time.Time) {
 				s.activeMatchingNativeHistogramBuckets[match] += uint32(entry.numNativeHistogramBuckets)
 			}
 		}
+		if s.activeByAttribution != nil {
+			s.activeByAttribution[entry.attribution]++
+		}
 		if ts < oldest {
 			oldest = ts
 		}
@@ -483,6 +617,13 @@ func (s *seriesStripe) remove(ref storage.SeriesRef) {
 			s.activeMatchingNativeHistogramBuckets[match] -= uint32(entry.numNativeHistogramBuckets)
 		}
 	}
+	if s.activeByAttribution != nil {
+		if s.activeByAttribution[entry.attribution] <= 1 {
+			delete(s.activeByAttribution, entry.attribution)
+		} else {
+			s.activeByAttribution[entry.attribution]--
+		}
+	}
 
 	s.deleted.purge(ref)
 	delete(s.refs, ref)
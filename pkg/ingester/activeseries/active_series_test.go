@@ -18,6 +18,8 @@ import (
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/costattribution"
 )
 
 const DefaultTimeout = 5 * time.Minute
@@ -29,7 +31,7 @@ func TestActiveSeries_UpdateSeries_NoMatchers(t *testing.T) {
 	ref4, ls4 := storage.SeriesRef(4), labels.FromStrings("a", "4")
 	ref5 := storage.SeriesRef(5) // will be used for ls1 again.
 
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 	valid := c.Purge(time.Now())
 	assert.True(t, valid)
 	allActive, activeMatching, allActiveHistograms, activeMatchingHistograms, allActiveBuckets, activeMatchingBuckets := c.ActiveWithMatchers()
@@ -194,7 +196,7 @@ func TestActiveSeries_ContainsRef(t *testing.T) {
 	for ttl := 1; ttl <= len(series); ttl++ {
 		t.Run(fmt.Sprintf("ttl: %d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
-			c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+			c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 			// Update each series with a different timestamp according to each index
 			for i := 0; i < len(series); i++ {
@@ -219,6 +221,51 @@ func TestActiveSeries_ContainsRef(t *testing.T) {
 	}
 }
 
+func TestActiveSeries_NativeHistogramBuckets(t *testing.T) {
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
+	now := time.Now()
+
+	c.UpdateSeries(labels.FromStrings("a", "float"), 1, now, -1)
+	c.UpdateSeries(labels.FromStrings("a", "histogram"), 2, now, 10)
+	c.UpdateSeries(labels.FromStrings("a", "histogram"), 2, now, 20)
+
+	buckets, ok := c.NativeHistogramBuckets(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, buckets)
+
+	buckets, ok = c.NativeHistogramBuckets(2)
+	assert.True(t, ok)
+	assert.Equal(t, 20, buckets)
+
+	// Unknown series.
+	_, ok = c.NativeHistogramBuckets(3)
+	assert.False(t, ok)
+
+	// Purged series.
+	c.Purge(now.Add(DefaultTimeout + time.Second))
+	_, ok = c.NativeHistogramBuckets(2)
+	assert.False(t, ok)
+}
+
+func TestActiveSeries_ActiveSince(t *testing.T) {
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
+	now := time.Now()
+
+	c.UpdateSeries(labels.FromStrings("a", "1"), 1, now.Add(-3*time.Minute), -1)
+	c.UpdateSeries(labels.FromStrings("a", "2"), 2, now.Add(-2*time.Minute), -1)
+	c.UpdateSeries(labels.FromStrings("a", "3"), 3, now.Add(-time.Minute), 10)
+
+	assert.Equal(t, 3, c.ActiveSince(now.Add(-3*time.Minute)))
+	assert.Equal(t, 2, c.ActiveSince(now.Add(-2*time.Minute)))
+	assert.Equal(t, 1, c.ActiveSince(now.Add(-90*time.Second)))
+	assert.Equal(t, 0, c.ActiveSince(now))
+
+	// Series updated again are active since their last update.
+	c.UpdateSeries(labels.FromStrings("a", "1"), 1, now, -1)
+	assert.Equal(t, 2, c.ActiveSince(now.Add(-90*time.Second)))
+	assert.Equal(t, 1, c.ActiveSince(now))
+}
+
 func TestActiveSeries_UpdateSeries_WithMatchers(t *testing.T) {
 	ref1, ls1 := storage.SeriesRef(1), labels.FromStrings("a", "1")
 	ref2, ls2 := storage.SeriesRef(2), labels.FromStrings("a", "2")
@@ -229,7 +276,7 @@ func TestActiveSeries_UpdateSeries_WithMatchers(t *testing.T) {
 
 	asm := NewMatchers(mustNewCustomTrackersConfigFromMap(t, map[string]string{"foo": `{a=~"2|3|4"}`}))
 
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(time.Now())
 	assert.True(t, valid)
 	allActive, activeMatching, allActiveHistograms, activeMatchingHistograms, allActiveBuckets, activeMatchingBuckets := c.ActiveWithMatchers()
@@ -455,7 +502,7 @@ func TestActiveSeries_ShouldCorrectlyHandleHashCollisions(t *testing.T) {
 	ls1, ls2 := labelsWithHashCollision()
 	ref1, ref2 := storage.SeriesRef(1), storage.SeriesRef(2)
 
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 	c.UpdateSeries(ls1, ref1, time.Now(), -1)
 	c.UpdateSeries(ls2, ref2, time.Now(), -1)
 
@@ -483,7 +530,7 @@ func TestActiveSeries_Purge_NoMatchers(t *testing.T) {
 	for ttl := 1; ttl <= len(series); ttl++ {
 		t.Run(fmt.Sprintf("ttl: %d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
-			c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+			c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 			for i := 0; i < len(series); i++ {
 				c.UpdateSeries(series[i], refs[i], time.Unix(int64(i), 0), -1)
@@ -529,7 +576,7 @@ func TestActiveSeries_Purge_WithMatchers(t *testing.T) {
 		t.Run(fmt.Sprintf("ttl=%d", ttl), func(t *testing.T) {
 			mockedTime := time.Unix(int64(ttl), 0)
 
-			c := NewActiveSeries(asm, 5*time.Minute)
+			c := NewActiveSeries(asm, 5*time.Minute, nil)
 
 			exp := len(series) - ttl
 			expMatchingSeries := 0
@@ -561,7 +608,7 @@ func TestActiveSeries_PurgeOpt(t *testing.T) {
 	ref1, ref2 := storage.SeriesRef(1), storage.SeriesRef(2)
 
 	currentTime := time.Now()
-	c := NewActiveSeries(&Matchers{}, 59*time.Second)
+	c := NewActiveSeries(&Matchers{}, 59*time.Second, nil)
 
 	c.UpdateSeries(ls1, ref1, currentTime.Add(-2*time.Minute), -1)
 	c.UpdateSeries(ls2, ref2, currentTime, -1)
@@ -588,6 +635,42 @@ func TestActiveSeries_PurgeOpt(t *testing.T) {
 	assert.Equal(t, 1, allActive)
 }
 
+func TestActiveSeries_CostAttribution(t *testing.T) {
+	ref1, ls1 := storage.SeriesRef(1), labels.FromStrings("a", "1", "team", "a")
+	ref2, ls2 := storage.SeriesRef(2), labels.FromStrings("a", "2", "team", "a")
+	ref3, ls3 := storage.SeriesRef(3), labels.FromStrings("a", "3", "team", "b")
+	ref4, ls4 := storage.SeriesRef(4), labels.FromStrings("a", "4", "team", "c")
+	ref5, ls5 := storage.SeriesRef(5), labels.FromStrings("a", "5")
+
+	currentTime := time.Now()
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, costattribution.NewTracker("team", 2))
+	assert.Empty(t, c.ActiveByAttribution())
+
+	c.UpdateSeries(ls1, ref1, currentTime, -1)
+	c.UpdateSeries(ls2, ref2, currentTime, -1)
+	c.UpdateSeries(ls3, ref3, currentTime.Add(time.Minute), -1)
+	c.UpdateSeries(ls4, ref4, currentTime.Add(time.Minute), -1)
+	c.UpdateSeries(ls5, ref5, currentTime.Add(time.Minute), -1)
+	assert.True(t, c.Purge(currentTime))
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, costattribution.OverflowValue: 1, costattribution.MissingValue: 1}, c.ActiveByAttribution())
+
+	// Removing a series decreases the count of its attribution value.
+	c.PostDeletion(map[chunks.HeadSeriesRef]labels.Labels{chunks.HeadSeriesRef(ref3): ls3})
+	c.UpdateSeries(ls3, storage.SeriesRef(6), currentTime.Add(time.Minute), -1)
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, costattribution.OverflowValue: 1, costattribution.MissingValue: 1}, c.ActiveByAttribution())
+
+	// Purging the idle series removes them from their attribution value.
+	assert.True(t, c.Purge(currentTime.Add(DefaultTimeout+time.Second)))
+	assert.Equal(t, map[string]int{"b": 1, costattribution.OverflowValue: 1, costattribution.MissingValue: 1}, c.ActiveByAttribution())
+
+	// Disabling the cost attribution resets the tracked series.
+	c.ReloadCostAttribution(nil, currentTime)
+	assert.Nil(t, c.CurrentCostAttribution())
+	assert.Nil(t, c.ActiveByAttribution())
+	total, _, _ := c.Active()
+	assert.Equal(t, 0, total)
+}
+
 func TestActiveSeries_ReloadSeriesMatchers(t *testing.T) {
 	ref1, ls1 := storage.SeriesRef(1), labels.FromStrings("a", "1")
 	ref2, ls2 := storage.SeriesRef(2), labels.FromStrings("a", "2")
@@ -597,7 +680,7 @@ func TestActiveSeries_ReloadSeriesMatchers(t *testing.T) {
 	asm := NewMatchers(mustNewCustomTrackersConfigFromMap(t, map[string]string{"foo": `{a=~.*}`}))
 
 	currentTime := time.Now()
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
@@ -663,7 +746,7 @@ func TestActiveSeries_ReloadSeriesMatchers_LessMatchers(t *testing.T) {
 	}))
 
 	currentTime := time.Now()
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
 	allActive, activeMatching, _, _, _, _ := c.ActiveWithMatchers()
@@ -702,7 +785,7 @@ func TestActiveSeries_ReloadSeriesMatchers_SameSizeNewLabels(t *testing.T) {
 
 	currentTime := time.Now()
 
-	c := NewActiveSeries(asm, DefaultTimeout)
+	c := NewActiveSeries(asm, DefaultTimeout, nil)
 	valid := c.Purge(currentTime)
 	assert.True(t, valid)
 	allActive, activeMatching, _, _, _, _ := c.ActiveWithMatchers()
@@ -755,7 +838,7 @@ func benchmarkActiveSeriesUpdateSeriesConcurrency(b *testing.B, numSeries, numGo
 	var (
 		// Run the active series tracker with an active timeout = 0 so that the Purge() will always
 		// purge the series.
-		c           = NewActiveSeries(&Matchers{}, 0)
+		c           = NewActiveSeries(&Matchers{}, 0, nil)
 		updateGroup = &sync.WaitGroup{}
 		purgeGroup  = &sync.WaitGroup{}
 		start       = make(chan struct{})
@@ -893,7 +976,7 @@ func BenchmarkActiveSeries_UpdateSeries(b *testing.B) {
 
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				c := NewActiveSeries(asm, DefaultTimeout)
+				c := NewActiveSeries(asm, DefaultTimeout, nil)
 				for round := 0; round <= tt.nRounds; round++ {
 					for ix := 0; ix < tt.nSeries; ix++ {
 						c.UpdateSeries(series[ix], refs[ix], time.Unix(0, now), -1)
@@ -918,7 +1001,7 @@ func benchmarkPurge(b *testing.B, twice bool) {
 	const numExpiresSeries = numSeries / 25
 
 	currentTime := time.Now()
-	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout, nil)
 
 	series := [numSeries]labels.Labels{}
 	refs := [numSeries]storage.SeriesRef{}
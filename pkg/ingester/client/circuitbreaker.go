@@ -36,6 +36,8 @@ var (
 		"/cortex.Ingester/MetricsMetadata":         {},
 		"/cortex.Ingester/LabelNamesAndValues":     {},
 		"/cortex.Ingester/LabelValuesCardinality":  {},
+		"/cortex.Ingester/ActiveSeries":            {},
+		"/cortex.Ingester/ActiveSeriesBreakdown":   {},
 	}
 )
 
@@ -103,7 +103,7 @@ var ReadRequest_ResponseType_value = map[string]int32{
 }
 
 func (ReadRequest_ResponseType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{6, 0}
+	return fileDescriptor_60f6df4f3586b478, []int{11, 0}
 }
 
 type StreamChunk_Encoding int32
@@ -130,7 +130,7 @@ var StreamChunk_Encoding_value = map[string]int32{
 }
 
 func (StreamChunk_Encoding) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{10, 0}
+	return fileDescriptor_60f6df4f3586b478, []int{15, 0}
 }
 
 type LabelNamesAndValuesRequest struct {
@@ -423,6 +423,255 @@ func (m *LabelValueSeriesCount) GetLabelValueSeries() map[string]uint64 {
 	return nil
 }
 
+type ActiveSeriesRequest struct {
+	Matchers []*LabelMatcher `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers,omitempty"`
+}
+
+func (m *ActiveSeriesRequest) Reset()      { *m = ActiveSeriesRequest{} }
+func (*ActiveSeriesRequest) ProtoMessage() {}
+func (*ActiveSeriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{6}
+}
+func (m *ActiveSeriesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesRequest.Merge(m, src)
+}
+func (m *ActiveSeriesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesRequest proto.InternalMessageInfo
+
+func (m *ActiveSeriesRequest) GetMatchers() []*LabelMatcher {
+	if m != nil {
+		return m.Matchers
+	}
+	return nil
+}
+
+type ActiveSeriesResponse struct {
+	Metric []*mimirpb.Metric `protobuf:"bytes,1,rep,name=metric,proto3" json:"metric,omitempty"`
+}
+
+func (m *ActiveSeriesResponse) Reset()      { *m = ActiveSeriesResponse{} }
+func (*ActiveSeriesResponse) ProtoMessage() {}
+func (*ActiveSeriesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{7}
+}
+func (m *ActiveSeriesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesResponse.Merge(m, src)
+}
+func (m *ActiveSeriesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesResponse proto.InternalMessageInfo
+
+func (m *ActiveSeriesResponse) GetMetric() []*mimirpb.Metric {
+	if m != nil {
+		return m.Metric
+	}
+	return nil
+}
+
+type ActiveSeriesBreakdownRequest struct {
+	Matchers []*LabelMatcher `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers,omitempty"`
+	// Optional label name to break down the active series of each metric by.
+	LabelName string `protobuf:"bytes,2,opt,name=label_name,json=labelName,proto3" json:"label_name,omitempty"`
+}
+
+func (m *ActiveSeriesBreakdownRequest) Reset()      { *m = ActiveSeriesBreakdownRequest{} }
+func (*ActiveSeriesBreakdownRequest) ProtoMessage() {}
+func (*ActiveSeriesBreakdownRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{8}
+}
+func (m *ActiveSeriesBreakdownRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesBreakdownRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesBreakdownRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesBreakdownRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesBreakdownRequest.Merge(m, src)
+}
+func (m *ActiveSeriesBreakdownRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesBreakdownRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesBreakdownRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesBreakdownRequest proto.InternalMessageInfo
+
+func (m *ActiveSeriesBreakdownRequest) GetMatchers() []*LabelMatcher {
+	if m != nil {
+		return m.Matchers
+	}
+	return nil
+}
+
+func (m *ActiveSeriesBreakdownRequest) GetLabelName() string {
+	if m != nil {
+		return m.LabelName
+	}
+	return ""
+}
+
+type ActiveSeriesBreakdownResponse struct {
+	Items []*ActiveSeriesBreakdownItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (m *ActiveSeriesBreakdownResponse) Reset()      { *m = ActiveSeriesBreakdownResponse{} }
+func (*ActiveSeriesBreakdownResponse) ProtoMessage() {}
+func (*ActiveSeriesBreakdownResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{9}
+}
+func (m *ActiveSeriesBreakdownResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesBreakdownResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesBreakdownResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesBreakdownResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesBreakdownResponse.Merge(m, src)
+}
+func (m *ActiveSeriesBreakdownResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesBreakdownResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesBreakdownResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesBreakdownResponse proto.InternalMessageInfo
+
+func (m *ActiveSeriesBreakdownResponse) GetItems() []*ActiveSeriesBreakdownItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type ActiveSeriesBreakdownItem struct {
+	MetricName string `protobuf:"bytes,1,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	// Empty if no label name is requested, or if the series don't have the requested label.
+	LabelValue             string `protobuf:"bytes,2,opt,name=label_value,json=labelValue,proto3" json:"label_value,omitempty"`
+	SeriesCount            uint64 `protobuf:"varint,3,opt,name=series_count,json=seriesCount,proto3" json:"series_count,omitempty"`
+	NativeHistogramBuckets uint64 `protobuf:"varint,4,opt,name=native_histogram_buckets,json=nativeHistogramBuckets,proto3" json:"native_histogram_buckets,omitempty"`
+}
+
+func (m *ActiveSeriesBreakdownItem) Reset()      { *m = ActiveSeriesBreakdownItem{} }
+func (*ActiveSeriesBreakdownItem) ProtoMessage() {}
+func (*ActiveSeriesBreakdownItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{10}
+}
+func (m *ActiveSeriesBreakdownItem) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesBreakdownItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesBreakdownItem.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesBreakdownItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesBreakdownItem.Merge(m, src)
+}
+func (m *ActiveSeriesBreakdownItem) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesBreakdownItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesBreakdownItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesBreakdownItem proto.InternalMessageInfo
+
+func (m *ActiveSeriesBreakdownItem) GetMetricName() string {
+	if m != nil {
+		return m.MetricName
+	}
+	return ""
+}
+
+func (m *ActiveSeriesBreakdownItem) GetLabelValue() string {
+	if m != nil {
+		return m.LabelValue
+	}
+	return ""
+}
+
+func (m *ActiveSeriesBreakdownItem) GetSeriesCount() uint64 {
+	if m != nil {
+		return m.SeriesCount
+	}
+	return 0
+}
+
+func (m *ActiveSeriesBreakdownItem) GetNativeHistogramBuckets() uint64 {
+	if m != nil {
+		return m.NativeHistogramBuckets
+	}
+	return 0
+}
+
 type ReadRequest struct {
 	Queries               []*QueryRequest            `protobuf:"bytes,1,rep,name=queries,proto3" json:"queries,omitempty"`
 	AcceptedResponseTypes []ReadRequest_ResponseType `protobuf:"varint,2,rep,packed,name=accepted_response_types,json=acceptedResponseTypes,proto3,enum=cortex.ReadRequest_ResponseType" json:"accepted_response_types,omitempty"`
@@ -431,7 +680,7 @@ type ReadRequest struct {
 func (m *ReadRequest) Reset()      { *m = ReadRequest{} }
 func (*ReadRequest) ProtoMessage() {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{6}
+	return fileDescriptor_60f6df4f3586b478, []int{11}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -481,7 +730,7 @@ type ReadResponse struct {
 func (m *ReadResponse) Reset()      { *m = ReadResponse{} }
 func (*ReadResponse) ProtoMessage() {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{7}
+	return fileDescriptor_60f6df4f3586b478, []int{12}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -525,7 +774,7 @@ type StreamReadResponse struct {
 func (m *StreamReadResponse) Reset()      { *m = StreamReadResponse{} }
 func (*StreamReadResponse) ProtoMessage() {}
 func (*StreamReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{8}
+	return fileDescriptor_60f6df4f3586b478, []int{13}
 }
 func (m *StreamReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -576,7 +825,7 @@ type StreamChunkedSeries struct {
 func (m *StreamChunkedSeries) Reset()      { *m = StreamChunkedSeries{} }
 func (*StreamChunkedSeries) ProtoMessage() {}
 func (*StreamChunkedSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{9}
+	return fileDescriptor_60f6df4f3586b478, []int{14}
 }
 func (m *StreamChunkedSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -622,7 +871,7 @@ type StreamChunk struct {
 func (m *StreamChunk) Reset()      { *m = StreamChunk{} }
 func (*StreamChunk) ProtoMessage() {}
 func (*StreamChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{10}
+	return fileDescriptor_60f6df4f3586b478, []int{15}
 }
 func (m *StreamChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -683,7 +932,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{11}
+	return fileDescriptor_60f6df4f3586b478, []int{16}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -749,7 +998,7 @@ type ExemplarQueryRequest struct {
 func (m *ExemplarQueryRequest) Reset()      { *m = ExemplarQueryRequest{} }
 func (*ExemplarQueryRequest) ProtoMessage() {}
 func (*ExemplarQueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{12}
+	return fileDescriptor_60f6df4f3586b478, []int{17}
 }
 func (m *ExemplarQueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -806,7 +1055,7 @@ type QueryResponse struct {
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{13}
+	return fileDescriptor_60f6df4f3586b478, []int{18}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -861,7 +1110,7 @@ type QueryStreamResponse struct {
 func (m *QueryStreamResponse) Reset()      { *m = QueryStreamResponse{} }
 func (*QueryStreamResponse) ProtoMessage() {}
 func (*QueryStreamResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{14}
+	return fileDescriptor_60f6df4f3586b478, []int{19}
 }
 func (m *QueryStreamResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -933,7 +1182,7 @@ type QueryStreamSeries struct {
 func (m *QueryStreamSeries) Reset()      { *m = QueryStreamSeries{} }
 func (*QueryStreamSeries) ProtoMessage() {}
 func (*QueryStreamSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{15}
+	return fileDescriptor_60f6df4f3586b478, []int{20}
 }
 func (m *QueryStreamSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -977,7 +1226,7 @@ type QueryStreamSeriesChunks struct {
 func (m *QueryStreamSeriesChunks) Reset()      { *m = QueryStreamSeriesChunks{} }
 func (*QueryStreamSeriesChunks) ProtoMessage() {}
 func (*QueryStreamSeriesChunks) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{16}
+	return fileDescriptor_60f6df4f3586b478, []int{21}
 }
 func (m *QueryStreamSeriesChunks) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1027,7 +1276,7 @@ type ExemplarQueryResponse struct {
 func (m *ExemplarQueryResponse) Reset()      { *m = ExemplarQueryResponse{} }
 func (*ExemplarQueryResponse) ProtoMessage() {}
 func (*ExemplarQueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{17}
+	return fileDescriptor_60f6df4f3586b478, []int{22}
 }
 func (m *ExemplarQueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1073,7 +1322,7 @@ type LabelValuesRequest struct {
 func (m *LabelValuesRequest) Reset()      { *m = LabelValuesRequest{} }
 func (*LabelValuesRequest) ProtoMessage() {}
 func (*LabelValuesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{18}
+	return fileDescriptor_60f6df4f3586b478, []int{23}
 }
 func (m *LabelValuesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1137,7 +1386,7 @@ type LabelValuesResponse struct {
 func (m *LabelValuesResponse) Reset()      { *m = LabelValuesResponse{} }
 func (*LabelValuesResponse) ProtoMessage() {}
 func (*LabelValuesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{19}
+	return fileDescriptor_60f6df4f3586b478, []int{24}
 }
 func (m *LabelValuesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1182,7 +1431,7 @@ type LabelNamesRequest struct {
 func (m *LabelNamesRequest) Reset()      { *m = LabelNamesRequest{} }
 func (*LabelNamesRequest) ProtoMessage() {}
 func (*LabelNamesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{20}
+	return fileDescriptor_60f6df4f3586b478, []int{25}
 }
 func (m *LabelNamesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1239,7 +1488,7 @@ type LabelNamesResponse struct {
 func (m *LabelNamesResponse) Reset()      { *m = LabelNamesResponse{} }
 func (*LabelNamesResponse) ProtoMessage() {}
 func (*LabelNamesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{21}
+	return fileDescriptor_60f6df4f3586b478, []int{26}
 }
 func (m *LabelNamesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1282,7 +1531,7 @@ type UserStatsRequest struct {
 func (m *UserStatsRequest) Reset()      { *m = UserStatsRequest{} }
 func (*UserStatsRequest) ProtoMessage() {}
 func (*UserStatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{22}
+	return fileDescriptor_60f6df4f3586b478, []int{27}
 }
 func (m *UserStatsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1328,7 +1577,7 @@ type UserStatsResponse struct {
 func (m *UserStatsResponse) Reset()      { *m = UserStatsResponse{} }
 func (*UserStatsResponse) ProtoMessage() {}
 func (*UserStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{23}
+	return fileDescriptor_60f6df4f3586b478, []int{28}
 }
 func (m *UserStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1393,7 +1642,7 @@ type UserIDStatsResponse struct {
 func (m *UserIDStatsResponse) Reset()      { *m = UserIDStatsResponse{} }
 func (*UserIDStatsResponse) ProtoMessage() {}
 func (*UserIDStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{24}
+	return fileDescriptor_60f6df4f3586b478, []int{29}
 }
 func (m *UserIDStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1443,7 +1692,7 @@ type UsersStatsResponse struct {
 func (m *UsersStatsResponse) Reset()      { *m = UsersStatsResponse{} }
 func (*UsersStatsResponse) ProtoMessage() {}
 func (*UsersStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{25}
+	return fileDescriptor_60f6df4f3586b478, []int{30}
 }
 func (m *UsersStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1488,7 +1737,7 @@ type MetricsForLabelMatchersRequest struct {
 func (m *MetricsForLabelMatchersRequest) Reset()      { *m = MetricsForLabelMatchersRequest{} }
 func (*MetricsForLabelMatchersRequest) ProtoMessage() {}
 func (*MetricsForLabelMatchersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{26}
+	return fileDescriptor_60f6df4f3586b478, []int{31}
 }
 func (m *MetricsForLabelMatchersRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1545,7 +1794,7 @@ type MetricsForLabelMatchersResponse struct {
 func (m *MetricsForLabelMatchersResponse) Reset()      { *m = MetricsForLabelMatchersResponse{} }
 func (*MetricsForLabelMatchersResponse) ProtoMessage() {}
 func (*MetricsForLabelMatchersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{27}
+	return fileDescriptor_60f6df4f3586b478, []int{32}
 }
 func (m *MetricsForLabelMatchersResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1590,7 +1839,7 @@ type MetricsMetadataRequest struct {
 func (m *MetricsMetadataRequest) Reset()      { *m = MetricsMetadataRequest{} }
 func (*MetricsMetadataRequest) ProtoMessage() {}
 func (*MetricsMetadataRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{28}
+	return fileDescriptor_60f6df4f3586b478, []int{33}
 }
 func (m *MetricsMetadataRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1647,7 +1896,7 @@ type MetricsMetadataResponse struct {
 func (m *MetricsMetadataResponse) Reset()      { *m = MetricsMetadataResponse{} }
 func (*MetricsMetadataResponse) ProtoMessage() {}
 func (*MetricsMetadataResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{29}
+	return fileDescriptor_60f6df4f3586b478, []int{34}
 }
 func (m *MetricsMetadataResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1693,7 +1942,7 @@ type TimeSeriesChunk struct {
 func (m *TimeSeriesChunk) Reset()      { *m = TimeSeriesChunk{} }
 func (*TimeSeriesChunk) ProtoMessage() {}
 func (*TimeSeriesChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{30}
+	return fileDescriptor_60f6df4f3586b478, []int{35}
 }
 func (m *TimeSeriesChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1753,7 +2002,7 @@ type Chunk struct {
 func (m *Chunk) Reset()      { *m = Chunk{} }
 func (*Chunk) ProtoMessage() {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{31}
+	return fileDescriptor_60f6df4f3586b478, []int{36}
 }
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1810,7 +2059,7 @@ type LabelMatchers struct {
 func (m *LabelMatchers) Reset()      { *m = LabelMatchers{} }
 func (*LabelMatchers) ProtoMessage() {}
 func (*LabelMatchers) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{32}
+	return fileDescriptor_60f6df4f3586b478, []int{37}
 }
 func (m *LabelMatchers) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1855,7 +2104,7 @@ type LabelMatcher struct {
 func (m *LabelMatcher) Reset()      { *m = LabelMatcher{} }
 func (*LabelMatcher) ProtoMessage() {}
 func (*LabelMatcher) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{33}
+	return fileDescriptor_60f6df4f3586b478, []int{38}
 }
 func (m *LabelMatcher) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1915,7 +2164,7 @@ type TimeSeriesFile struct {
 func (m *TimeSeriesFile) Reset()      { *m = TimeSeriesFile{} }
 func (*TimeSeriesFile) ProtoMessage() {}
 func (*TimeSeriesFile) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{34}
+	return fileDescriptor_60f6df4f3586b478, []int{39}
 }
 func (m *TimeSeriesFile) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1984,6 +2233,11 @@ func init() {
 	proto.RegisterType((*LabelValuesCardinalityResponse)(nil), "cortex.LabelValuesCardinalityResponse")
 	proto.RegisterType((*LabelValueSeriesCount)(nil), "cortex.LabelValueSeriesCount")
 	proto.RegisterMapType((map[string]uint64)(nil), "cortex.LabelValueSeriesCount.LabelValueSeriesEntry")
+	proto.RegisterType((*ActiveSeriesRequest)(nil), "cortex.ActiveSeriesRequest")
+	proto.RegisterType((*ActiveSeriesResponse)(nil), "cortex.ActiveSeriesResponse")
+	proto.RegisterType((*ActiveSeriesBreakdownRequest)(nil), "cortex.ActiveSeriesBreakdownRequest")
+	proto.RegisterType((*ActiveSeriesBreakdownResponse)(nil), "cortex.ActiveSeriesBreakdownResponse")
+	proto.RegisterType((*ActiveSeriesBreakdownItem)(nil), "cortex.ActiveSeriesBreakdownItem")
 	proto.RegisterType((*ReadRequest)(nil), "cortex.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "cortex.ReadResponse")
 	proto.RegisterType((*StreamReadResponse)(nil), "cortex.StreamReadResponse")
@@ -2018,130 +2272,140 @@ func init() {
 func init() { proto.RegisterFile("ingester.proto", fileDescriptor_60f6df4f3586b478) }
 
 var fileDescriptor_60f6df4f3586b478 = []byte{
-	// 1960 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xcf, 0x6f, 0x1b, 0xc7,
-	0xf5, 0xe7, 0xf0, 0x97, 0xc4, 0x47, 0x8a, 0x5a, 0x0d, 0x2d, 0x93, 0xa1, 0xbf, 0xa6, 0x94, 0xfd,
-	0xc2, 0x29, 0x9b, 0x26, 0x94, 0x7f, 0xb5, 0x70, 0x82, 0x14, 0x01, 0x25, 0xd1, 0x16, 0x6d, 0x93,
-	0x54, 0x96, 0x54, 0xe2, 0x16, 0x08, 0x16, 0x4b, 0x72, 0x24, 0x2d, 0xcc, 0x5d, 0x32, 0xbb, 0xc3,
-	0x42, 0xca, 0xa9, 0x40, 0xef, 0x45, 0x6f, 0xbd, 0x14, 0x05, 0x7a, 0x2b, 0x7a, 0x2a, 0x7a, 0xe9,
-	0xad, 0xe7, 0x5c, 0x02, 0xf8, 0x18, 0xf4, 0x60, 0xd4, 0x72, 0x0f, 0xed, 0x2d, 0x40, 0xff, 0x81,
-	0x62, 0x67, 0x66, 0x7f, 0x72, 0x65, 0x29, 0x45, 0xec, 0x13, 0x39, 0xef, 0xbd, 0xf9, 0xcc, 0x7b,
-	0x6f, 0xde, 0xaf, 0x1d, 0x28, 0xea, 0xe6, 0x11, 0xb1, 0x29, 0xb1, 0x1a, 0x33, 0x6b, 0x4a, 0xa7,
-	0x38, 0x3b, 0x9a, 0x5a, 0x94, 0x9c, 0x54, 0xdf, 0x3f, 0xd2, 0xe9, 0xf1, 0x7c, 0xd8, 0x18, 0x4d,
-	0x8d, 0xad, 0xa3, 0xe9, 0xd1, 0x74, 0x8b, 0xb1, 0x87, 0xf3, 0x43, 0xb6, 0x62, 0x0b, 0xf6, 0x8f,
-	0x6f, 0xab, 0xde, 0x0c, 0x8a, 0x5b, 0xda, 0xa1, 0x66, 0x6a, 0x5b, 0x86, 0x6e, 0xe8, 0xd6, 0xd6,
-	0xec, 0xe9, 0x11, 0xff, 0x37, 0x1b, 0xf2, 0x5f, 0xbe, 0x43, 0xee, 0x42, 0xf5, 0xb1, 0x36, 0x24,
-	0x93, 0xae, 0x66, 0x10, 0xbb, 0x69, 0x8e, 0x3f, 0xd5, 0x26, 0x73, 0x62, 0x2b, 0xe4, 0x8b, 0x39,
-	0xb1, 0x29, 0xbe, 0x09, 0xcb, 0x86, 0x46, 0x47, 0xc7, 0xc4, 0xb2, 0x2b, 0x68, 0x33, 0x55, 0xcf,
-	0xdf, 0xbe, 0xd2, 0xe0, 0x9a, 0x35, 0xd8, 0xae, 0x0e, 0x67, 0x2a, 0x9e, 0x94, 0xbc, 0x07, 0xd7,
-	0x62, 0xf1, 0xec, 0xd9, 0xd4, 0xb4, 0x09, 0xfe, 0x21, 0x64, 0x74, 0x4a, 0x0c, 0x17, 0xad, 0x14,
-	0x42, 0x13, 0xb2, 0x5c, 0x42, 0xde, 0x85, 0x7c, 0x80, 0x8a, 0xaf, 0x03, 0x4c, 0x9c, 0xa5, 0x6a,
-	0x6a, 0x06, 0xa9, 0xa0, 0x4d, 0x54, 0xcf, 0x29, 0xb9, 0x89, 0x7b, 0x14, 0xbe, 0x0a, 0xd9, 0x5f,
-	0x30, 0xc1, 0x4a, 0x72, 0x33, 0x55, 0xcf, 0x29, 0x62, 0x25, 0xff, 0x09, 0xc1, 0xf5, 0x00, 0xcc,
-	0x8e, 0x66, 0x8d, 0x75, 0x53, 0x9b, 0xe8, 0xf4, 0xd4, 0xb5, 0x71, 0x03, 0xf2, 0x3e, 0x30, 0x57,
-	0x2c, 0xa7, 0x80, 0x87, 0x6c, 0x87, 0x9c, 0x90, 0xbc, 0x8c, 0x13, 0xf0, 0x4f, 0xa0, 0x30, 0x9a,
-	0xce, 0x4d, 0xaa, 0x1a, 0x84, 0x1e, 0x4f, 0xc7, 0x95, 0xd4, 0x26, 0xaa, 0x17, 0x7d, 0x63, 0x77,
-	0x1c, 0x5e, 0x87, 0xb1, 0x94, 0xfc, 0xc8, 0x5f, 0xc8, 0x07, 0x50, 0x3b, 0x4f, 0x57, 0xe1, 0xbf,
-	0x3b, 0x61, 0xff, 0x5d, 0x5f, 0xf4, 0x5f, 0x9f, 0x58, 0x3a, 0xb1, 0xd9, 0x11, 0xae, 0x27, 0x9f,
-	0x23, 0x58, 0x8f, 0x15, 0xb8, 0xc8, 0xa9, 0x1a, 0x60, 0xce, 0x66, 0xce, 0x54, 0x6d, 0xb6, 0x53,
-	0xf8, 0xe0, 0xce, 0x2b, 0x8f, 0x5e, 0xa0, 0xb6, 0x4c, 0x6a, 0x9d, 0x2a, 0xd2, 0x24, 0x42, 0xae,
-	0xee, 0x2c, 0xaa, 0xc6, 0x44, 0xb1, 0x04, 0xa9, 0xa7, 0xe4, 0x54, 0xe8, 0xe4, 0xfc, 0xc5, 0x57,
-	0x20, 0xc3, 0xf4, 0xa8, 0x24, 0x37, 0x51, 0x3d, 0xad, 0xf0, 0xc5, 0x87, 0xc9, 0x7b, 0x48, 0xfe,
-	0x1a, 0x41, 0x5e, 0x21, 0xda, 0xd8, 0xbd, 0xd2, 0x06, 0x2c, 0x7d, 0x31, 0xe7, 0xca, 0x46, 0xa2,
-	0xf6, 0x93, 0x39, 0xb1, 0xdc, 0x9b, 0x57, 0x5c, 0x21, 0xfc, 0x04, 0xca, 0xda, 0x68, 0x44, 0x66,
-	0x94, 0x8c, 0x55, 0x4b, 0xb8, 0x5a, 0xa5, 0xa7, 0x33, 0x61, 0x6c, 0xf1, 0xf6, 0xa6, 0xbb, 0x3f,
-	0x70, 0x4a, 0xc3, 0xbd, 0x94, 0xc1, 0xe9, 0x8c, 0x28, 0xeb, 0x2e, 0x40, 0x90, 0x6a, 0xcb, 0x77,
-	0xa1, 0x10, 0x24, 0xe0, 0x3c, 0x2c, 0xf5, 0x9b, 0x9d, 0xfd, 0xc7, 0xad, 0xbe, 0x94, 0xc0, 0x65,
-	0x28, 0xf5, 0x07, 0x4a, 0xab, 0xd9, 0x69, 0xed, 0xaa, 0x4f, 0x7a, 0x8a, 0xba, 0xb3, 0x77, 0xd0,
-	0x7d, 0xd4, 0x97, 0x90, 0xfc, 0xb1, 0xb3, 0x4b, 0xf3, 0xa0, 0xf0, 0x16, 0x2c, 0x59, 0xc4, 0x9e,
-	0x4f, 0xa8, 0x6b, 0xcf, 0x7a, 0xc4, 0x1e, 0x2e, 0xa7, 0xb8, 0x52, 0xf2, 0x29, 0xe0, 0x3e, 0xb5,
-	0x88, 0x66, 0x84, 0x60, 0xb6, 0xa1, 0x38, 0x3a, 0x9e, 0x9b, 0x4f, 0xc9, 0xd8, 0xbd, 0x4a, 0x8e,
-	0x76, 0xcd, 0x45, 0xe3, 0x7b, 0x76, 0xb8, 0x0c, 0xbf, 0x0c, 0x65, 0x65, 0x14, 0x5c, 0x3a, 0xd9,
-	0xe2, 0x78, 0xed, 0x54, 0xd5, 0xcd, 0x31, 0x39, 0x61, 0x57, 0x91, 0x52, 0x80, 0x91, 0xda, 0x0e,
-	0x45, 0xfe, 0x33, 0x82, 0x52, 0x0c, 0x0e, 0x3e, 0x84, 0x2c, 0xbb, 0xfc, 0x68, 0xea, 0xcf, 0x86,
-	0x3c, 0x56, 0xf6, 0x35, 0xdd, 0xda, 0xfe, 0xe0, 0xab, 0xe7, 0x1b, 0x89, 0xbf, 0x3f, 0xdf, 0xb8,
-	0x75, 0x99, 0x3a, 0xc6, 0xf7, 0x35, 0xc7, 0xda, 0x8c, 0x12, 0x4b, 0x11, 0xe8, 0xf8, 0x16, 0x64,
-	0x99, 0xc6, 0x6e, 0x9c, 0x96, 0x62, 0x8c, 0xdb, 0x4e, 0x3b, 0xe7, 0x28, 0x42, 0x50, 0xfe, 0x6d,
-	0x12, 0xf2, 0x01, 0x2e, 0xae, 0x41, 0xde, 0xd0, 0x4d, 0x95, 0xea, 0x06, 0x51, 0x59, 0xaa, 0x39,
-	0x36, 0xe6, 0x0c, 0xdd, 0x1c, 0xe8, 0x06, 0xe9, 0xd8, 0x8c, 0xaf, 0x9d, 0x78, 0xfc, 0xa4, 0xe0,
-	0x6b, 0x27, 0x82, 0x7f, 0x13, 0xd2, 0x4e, 0xf0, 0x88, 0xb4, 0xff, 0xbf, 0x18, 0x05, 0x1a, 0x2d,
-	0x73, 0x34, 0x1d, 0xeb, 0xe6, 0x91, 0xc2, 0x24, 0xf1, 0x3e, 0xa4, 0xc7, 0x1a, 0xd5, 0x2a, 0xe9,
-	0x4d, 0x54, 0x2f, 0x6c, 0x7f, 0x24, 0xbc, 0x70, 0xf7, 0x52, 0x5e, 0x38, 0x30, 0x6d, 0xed, 0x90,
-	0x6c, 0x9f, 0x52, 0xd2, 0x9f, 0xe8, 0x23, 0xa2, 0x30, 0x24, 0x79, 0x17, 0x96, 0xdd, 0x33, 0x9c,
-	0xa0, 0x3b, 0xe8, 0x3e, 0xea, 0xf6, 0x3e, 0xeb, 0x4a, 0x09, 0xbc, 0x04, 0xa9, 0x27, 0x3d, 0x45,
-	0x42, 0x78, 0x05, 0x72, 0x7b, 0xed, 0xfe, 0xa0, 0xf7, 0x40, 0x69, 0x76, 0xa4, 0x24, 0x2e, 0xc1,
-	0xea, 0xfd, 0xc7, 0xbd, 0xe6, 0x40, 0xf5, 0x89, 0x29, 0xf9, 0x9f, 0x08, 0x0a, 0xc1, 0x94, 0xc1,
-	0xef, 0x01, 0xb6, 0xa9, 0x66, 0x51, 0x66, 0xbc, 0x4d, 0x35, 0x63, 0xe6, 0x7b, 0x48, 0x62, 0x9c,
-	0x81, 0xcb, 0xe8, 0xd8, 0xb8, 0x0e, 0x12, 0x31, 0xc7, 0x61, 0x59, 0xee, 0xad, 0x22, 0x31, 0xc7,
-	0x41, 0xc9, 0x60, 0x8d, 0x4d, 0x5d, 0xaa, 0xc6, 0xfe, 0x14, 0xae, 0xd9, 0xcc, 0xa1, 0xba, 0x79,
-	0xa4, 0xf2, 0x8b, 0x54, 0x87, 0x0e, 0x53, 0xb5, 0xf5, 0x2f, 0x49, 0x65, 0xcc, 0x6a, 0x44, 0xc5,
-	0x13, 0x61, 0x6e, 0xb7, 0xb7, 0x1d, 0x81, 0xbe, 0xfe, 0x25, 0x79, 0x98, 0x5e, 0x4e, 0x4b, 0x19,
-	0x25, 0x73, 0xac, 0x9b, 0xd4, 0x96, 0xff, 0x80, 0xe0, 0x4a, 0xeb, 0x84, 0x18, 0xb3, 0x89, 0x66,
-	0xbd, 0x11, 0x73, 0x6f, 0x2d, 0x98, 0xbb, 0x1e, 0x67, 0xae, 0x1d, 0x68, 0xac, 0x8f, 0x60, 0x25,
-	0x94, 0xec, 0xf8, 0x43, 0x00, 0x76, 0x52, 0x5c, 0x9d, 0x9b, 0x0d, 0x1b, 0xce, 0x71, 0x3c, 0xf5,
-	0x44, 0xb4, 0x07, 0xa4, 0xe5, 0xff, 0x24, 0xa1, 0xc4, 0xd0, 0xdc, 0x2a, 0x21, 0x30, 0x3f, 0x86,
-	0x3c, 0x77, 0x65, 0x10, 0xb4, 0xec, 0xaa, 0xe6, 0x43, 0x06, 0xb3, 0x28, 0xb8, 0x23, 0xa2, 0x54,
-	0xf2, 0xbb, 0x28, 0x85, 0x1f, 0x82, 0xe4, 0xdf, 0xa8, 0x40, 0xe0, 0xce, 0x79, 0x2b, 0x54, 0xee,
-	0xb8, 0xce, 0x21, 0x98, 0x55, 0x6f, 0xa3, 0xa8, 0x36, 0x77, 0xa1, 0xac, 0xdb, 0xaa, 0x73, 0x1b,
-	0xd3, 0x43, 0x81, 0xa5, 0x72, 0x19, 0x96, 0x63, 0xcb, 0x4a, 0x49, 0xb7, 0x5b, 0xe6, 0xb8, 0x77,
-	0xc8, 0xe5, 0x39, 0x24, 0xfe, 0x1c, 0xca, 0x51, 0x0d, 0x44, 0x68, 0x55, 0x32, 0x4c, 0x91, 0x8d,
-	0x73, 0x15, 0x11, 0xf1, 0xc5, 0xd5, 0x59, 0x8f, 0xa8, 0xc3, 0x99, 0xf2, 0xef, 0x10, 0xac, 0x2d,
-	0x6c, 0x7c, 0x63, 0x85, 0x71, 0x43, 0xdc, 0xad, 0xca, 0x26, 0x0e, 0xb7, 0x72, 0x33, 0x12, 0x6b,
-	0xd9, 0xb2, 0x0e, 0xe5, 0x73, 0xcc, 0xc2, 0x6f, 0x43, 0x41, 0xb8, 0x83, 0x97, 0x7d, 0xc4, 0xb2,
-	0x2b, 0xcf, 0x69, 0xac, 0xee, 0xe3, 0x1f, 0x45, 0xea, 0xee, 0x8a, 0x37, 0xed, 0xc4, 0x54, 0xdc,
-	0x3e, 0xac, 0x47, 0xf2, 0xed, 0x7b, 0x08, 0xea, 0xbf, 0x21, 0xc0, 0xc1, 0x39, 0x52, 0xe4, 0xf0,
-	0x05, 0x33, 0x4e, 0x7c, 0x8a, 0x27, 0xbf, 0x43, 0x8a, 0xa7, 0x2e, 0x4c, 0x71, 0x27, 0xe4, 0x2e,
-	0x91, 0xe2, 0xf7, 0xa0, 0x14, 0xd2, 0x5f, 0xf8, 0xe4, 0x6d, 0x28, 0x04, 0xa6, 0x30, 0x77, 0x42,
-	0xcd, 0xfb, 0xa3, 0x94, 0x2d, 0xff, 0x1e, 0xc1, 0x9a, 0x3f, 0x76, 0xbf, 0xd9, 0xea, 0x75, 0x29,
-	0xd3, 0x7e, 0x2c, 0xae, 0x46, 0xe8, 0x27, 0x2c, 0xbb, 0x68, 0xf4, 0x96, 0x1f, 0x82, 0x74, 0x60,
-	0x13, 0xab, 0x4f, 0x35, 0xea, 0x59, 0x15, 0x1d, 0xae, 0xd1, 0x25, 0x87, 0xeb, 0xbf, 0x22, 0x58,
-	0x0b, 0x80, 0x09, 0x15, 0x6e, 0xb8, 0x9f, 0x5e, 0xfa, 0xd4, 0x54, 0x2d, 0x8d, 0xf2, 0x08, 0x41,
-	0xca, 0x8a, 0x47, 0x55, 0x34, 0x4a, 0x9c, 0x20, 0x32, 0xe7, 0x86, 0x3f, 0x01, 0x3b, 0xe1, 0x9f,
-	0x33, 0xe7, 0x6e, 0x0e, 0xbf, 0x07, 0x58, 0x9b, 0xe9, 0x6a, 0x04, 0x29, 0xc5, 0x90, 0x24, 0x6d,
-	0xa6, 0xb7, 0x43, 0x60, 0x0d, 0x28, 0x59, 0xf3, 0x09, 0x89, 0x8a, 0xa7, 0x99, 0xf8, 0x9a, 0xc3,
-	0x0a, 0xc9, 0xcb, 0x9f, 0x43, 0xc9, 0x51, 0xbc, 0xbd, 0x1b, 0x56, 0xbd, 0x0c, 0x4b, 0x73, 0x9b,
-	0x58, 0xaa, 0x3e, 0x16, 0x51, 0x9d, 0x75, 0x96, 0xed, 0x31, 0x7e, 0x5f, 0x4c, 0x13, 0x49, 0x76,
-	0x37, 0x5e, 0xf1, 0x5c, 0x30, 0x5e, 0x8c, 0x0a, 0x0f, 0x00, 0x3b, 0x2c, 0x3b, 0x8c, 0x7e, 0x0b,
-	0x32, 0xb6, 0x43, 0x88, 0xce, 0x88, 0x31, 0x9a, 0x28, 0x5c, 0x52, 0xfe, 0x0b, 0x82, 0x5a, 0x87,
-	0x50, 0x4b, 0x1f, 0xd9, 0xf7, 0xa7, 0x56, 0x38, 0x14, 0x5e, 0x73, 0x48, 0xde, 0x83, 0x82, 0x1b,
-	0x6b, 0xaa, 0x4d, 0xe8, 0xab, 0x9b, 0x6a, 0xde, 0x15, 0xed, 0x13, 0x2a, 0x3f, 0x82, 0x8d, 0x73,
-	0x75, 0x16, 0xae, 0xa8, 0x43, 0xd6, 0x60, 0x22, 0xc2, 0x17, 0x92, 0x5f, 0x90, 0xf8, 0x56, 0x45,
-	0xf0, 0xe5, 0x19, 0x5c, 0x15, 0x60, 0x1d, 0x42, 0x35, 0xc7, 0xbb, 0xae, 0xe1, 0x57, 0x20, 0x33,
-	0xd1, 0x0d, 0x9d, 0x32, 0x5b, 0xd7, 0x14, 0xbe, 0x70, 0x0c, 0x64, 0x7f, 0xd4, 0x19, 0xb1, 0x54,
-	0x71, 0x46, 0x92, 0x09, 0x14, 0x19, 0x7d, 0x9f, 0x58, 0x1c, 0xcf, 0xf9, 0xbe, 0x15, 0xfc, 0x14,
-	0xbf, 0x6b, 0x71, 0x62, 0x0f, 0xca, 0x0b, 0x27, 0x0a, 0xb5, 0xef, 0xc2, 0xb2, 0x21, 0x68, 0x42,
-	0xf1, 0x4a, 0x54, 0x71, 0x6f, 0x8f, 0x27, 0x29, 0xff, 0x1b, 0xc1, 0x6a, 0xa4, 0xd1, 0x3b, 0x6a,
-	0x1e, 0x5a, 0x53, 0x43, 0x75, 0x1f, 0x29, 0xfc, 0x90, 0x2b, 0x3a, 0xf4, 0xb6, 0x20, 0xb7, 0xc7,
-	0xc1, 0x98, 0x4c, 0x86, 0x62, 0xd2, 0xef, 0x72, 0xa9, 0xd7, 0xda, 0xe5, 0xfc, 0x36, 0x94, 0xbe,
-	0xb8, 0x0d, 0x7d, 0x8d, 0x20, 0xc3, 0x2d, 0x7c, 0x5d, 0x71, 0x59, 0x85, 0x65, 0x22, 0xc6, 0x70,
-	0x76, 0x71, 0x19, 0xc5, 0x5b, 0xbf, 0x86, 0xa1, 0xbf, 0x09, 0x2b, 0xa1, 0x08, 0xfe, 0x1f, 0xde,
-	0x6f, 0x54, 0x28, 0x04, 0x39, 0xf8, 0x86, 0xf8, 0x96, 0xe1, 0x55, 0x76, 0xcd, 0xdd, 0xcd, 0xd8,
-	0xec, 0xc3, 0x97, 0x7f, 0xc0, 0x60, 0x48, 0xb3, 0xf6, 0xca, 0x2f, 0x9d, 0xfd, 0xf7, 0xbf, 0xd7,
-	0x79, 0xc4, 0xf2, 0x85, 0xfc, 0x2b, 0x04, 0x45, 0x3f, 0xbe, 0xee, 0xeb, 0x13, 0xf2, 0x7d, 0x84,
-	0x57, 0x15, 0x96, 0x0f, 0xf5, 0x09, 0x61, 0x3a, 0xf0, 0xe3, 0xbc, 0xb5, 0xa3, 0x9b, 0xef, 0x67,
-	0xee, 0xa9, 0x77, 0xeb, 0x90, 0x0f, 0x34, 0x0a, 0xe7, 0x5b, 0xa8, 0xdd, 0x55, 0x3b, 0xad, 0x4e,
-	0x4f, 0xf9, 0x99, 0x94, 0xc0, 0x00, 0xd9, 0xe6, 0xce, 0xa0, 0xfd, 0x69, 0x4b, 0x42, 0xef, 0x3e,
-	0x84, 0x9c, 0x67, 0x2c, 0xce, 0x41, 0xa6, 0xf5, 0xc9, 0x41, 0xf3, 0xb1, 0x94, 0x70, 0xb6, 0x74,
-	0x7b, 0x03, 0x95, 0x2f, 0x11, 0x5e, 0x85, 0xbc, 0xd2, 0x7a, 0xd0, 0x7a, 0xa2, 0x76, 0x9a, 0x83,
-	0x9d, 0x3d, 0x29, 0x89, 0x31, 0x14, 0x39, 0xa1, 0xdb, 0x13, 0xb4, 0xd4, 0xed, 0x5f, 0x2f, 0xc1,
-	0xb2, 0x6b, 0x0d, 0xfe, 0x00, 0xd2, 0xfb, 0x73, 0xfb, 0x18, 0x5f, 0xf5, 0x33, 0xe1, 0x33, 0x4b,
-	0xa7, 0x44, 0x54, 0x8c, 0x6a, 0x79, 0x81, 0xce, 0xf3, 0x5a, 0x4e, 0xe0, 0x5d, 0xc8, 0x07, 0x26,
-	0x35, 0x1c, 0xfb, 0xba, 0x51, 0xbd, 0x16, 0x33, 0xab, 0xfa, 0x18, 0x37, 0x11, 0xee, 0x41, 0x91,
-	0xb1, 0xdc, 0x49, 0xcc, 0xc6, 0xde, 0xa7, 0x6a, 0xdc, 0xc7, 0x50, 0xf5, 0xfa, 0x39, 0x5c, 0x4f,
-	0xad, 0xbd, 0xf0, 0x8b, 0x5d, 0x35, 0xee, 0x71, 0x2f, 0xaa, 0x5c, 0xcc, 0xc0, 0x23, 0x27, 0x70,
-	0x0b, 0xc0, 0x1f, 0x17, 0xf0, 0x5b, 0x21, 0xe1, 0xe0, 0x88, 0x53, 0xad, 0xc6, 0xb1, 0x3c, 0x98,
-	0x6d, 0xc8, 0x79, 0x4d, 0x0f, 0x57, 0x62, 0xfa, 0x20, 0x07, 0x39, 0xbf, 0x43, 0xca, 0x09, 0x7c,
-	0x1f, 0x0a, 0xcd, 0xc9, 0xe4, 0x32, 0x30, 0xd5, 0x20, 0xc7, 0x8e, 0xe2, 0x4c, 0xbc, 0x42, 0x1d,
-	0xed, 0x33, 0xf8, 0x1d, 0x2f, 0xab, 0x5e, 0xd9, 0x3c, 0xab, 0x3f, 0xb8, 0x50, 0xce, 0x3b, 0x6d,
-	0x00, 0xab, 0x91, 0xb6, 0x80, 0x6b, 0x91, 0xdd, 0x91, 0x0e, 0x55, 0xdd, 0x38, 0x97, 0xef, 0xa1,
-	0x0e, 0xc5, 0x80, 0x1a, 0x7e, 0xdc, 0xc5, 0xf2, 0xe2, 0x25, 0x44, 0x5f, 0x92, 0xab, 0xff, 0xff,
-	0x4a, 0x99, 0x40, 0x54, 0x3e, 0x85, 0xab, 0xf1, 0x6f, 0xa0, 0xf8, 0x46, 0x4c, 0xcc, 0x2c, 0xbe,
-	0xe7, 0x56, 0xdf, 0xb9, 0x48, 0xcc, 0x3f, 0x6c, 0xfb, 0xa3, 0x67, 0x2f, 0x6a, 0x89, 0x6f, 0x5e,
-	0xd4, 0x12, 0xdf, 0xbe, 0xa8, 0xa1, 0x5f, 0x9e, 0xd5, 0xd0, 0x1f, 0xcf, 0x6a, 0xe8, 0xab, 0xb3,
-	0x1a, 0x7a, 0x76, 0x56, 0x43, 0xff, 0x38, 0xab, 0xa1, 0x7f, 0x9d, 0xd5, 0x12, 0xdf, 0x9e, 0xd5,
-	0xd0, 0x6f, 0x5e, 0xd6, 0x12, 0xcf, 0x5e, 0xd6, 0x12, 0xdf, 0xbc, 0xac, 0x25, 0x7e, 0x9e, 0x1d,
-	0x4d, 0x74, 0x62, 0xd2, 0x61, 0x96, 0x3d, 0xa1, 0xdf, 0xf9, 0x6f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0xae, 0xae, 0xb4, 0x3c, 0xbd, 0x17, 0x00, 0x00,
+	// 2123 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x59, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xe7, 0xf2, 0x43, 0x12, 0x1f, 0x29, 0x8a, 0x1a, 0x4a, 0x22, 0x4d, 0x59, 0x94, 0xbc, 0xad,
+	0x53, 0x35, 0x4d, 0x28, 0x7f, 0xb5, 0x75, 0x82, 0x14, 0x29, 0x29, 0xd1, 0x16, 0x6d, 0x53, 0x54,
+	0x96, 0x54, 0xa2, 0x16, 0x08, 0x16, 0x4b, 0xee, 0x48, 0x5a, 0x88, 0xbb, 0x64, 0x76, 0x87, 0xa9,
+	0x94, 0x53, 0x81, 0xfe, 0x03, 0xbd, 0xf5, 0x52, 0x14, 0xe8, 0xad, 0xe8, 0xa9, 0xe8, 0xa5, 0x40,
+	0x0f, 0xbd, 0x36, 0x97, 0x00, 0x3e, 0x06, 0x3d, 0x18, 0xb5, 0xdc, 0x43, 0x7b, 0x0b, 0xd0, 0x7f,
+	0x20, 0xd8, 0x99, 0xd9, 0x4f, 0x2d, 0x25, 0xd9, 0x88, 0x7d, 0xe2, 0xce, 0x7b, 0x6f, 0x7e, 0xf3,
+	0xde, 0x9b, 0xf7, 0x31, 0x33, 0x84, 0x9c, 0x66, 0x1c, 0x62, 0x8b, 0x60, 0xb3, 0x3a, 0x32, 0x87,
+	0x64, 0x88, 0xa6, 0xfa, 0x43, 0x93, 0xe0, 0x93, 0xf2, 0xbb, 0x87, 0x1a, 0x39, 0x1a, 0xf7, 0xaa,
+	0xfd, 0xa1, 0xbe, 0x71, 0x38, 0x3c, 0x1c, 0x6e, 0x50, 0x76, 0x6f, 0x7c, 0x40, 0x47, 0x74, 0x40,
+	0xbf, 0xd8, 0xb4, 0xf2, 0x2d, 0xbf, 0xb8, 0xa9, 0x1c, 0x28, 0x86, 0xb2, 0xa1, 0x6b, 0xba, 0x66,
+	0x6e, 0x8c, 0x8e, 0x0f, 0xd9, 0xd7, 0xa8, 0xc7, 0x7e, 0xd9, 0x0c, 0x71, 0x07, 0xca, 0x4f, 0x94,
+	0x1e, 0x1e, 0xec, 0x28, 0x3a, 0xb6, 0x6a, 0x86, 0xfa, 0xb1, 0x32, 0x18, 0x63, 0x4b, 0xc2, 0x9f,
+	0x8d, 0xb1, 0x45, 0xd0, 0x2d, 0x98, 0xd1, 0x15, 0xd2, 0x3f, 0xc2, 0xa6, 0x55, 0x12, 0xd6, 0x12,
+	0xeb, 0x99, 0x3b, 0x0b, 0x55, 0xa6, 0x59, 0x95, 0xce, 0x6a, 0x31, 0xa6, 0xe4, 0x4a, 0x89, 0xdb,
+	0xb0, 0x1c, 0x89, 0x67, 0x8d, 0x86, 0x86, 0x85, 0xd1, 0x0f, 0x21, 0xa5, 0x11, 0xac, 0x3b, 0x68,
+	0x85, 0x00, 0x1a, 0x97, 0x65, 0x12, 0xe2, 0x16, 0x64, 0x7c, 0x54, 0xb4, 0x02, 0x30, 0xb0, 0x87,
+	0xb2, 0xa1, 0xe8, 0xb8, 0x24, 0xac, 0x09, 0xeb, 0x69, 0x29, 0x3d, 0x70, 0x96, 0x42, 0x4b, 0x30,
+	0xf5, 0x39, 0x15, 0x2c, 0xc5, 0xd7, 0x12, 0xeb, 0x69, 0x89, 0x8f, 0xc4, 0x3f, 0x0b, 0xb0, 0xe2,
+	0x83, 0xd9, 0x54, 0x4c, 0x55, 0x33, 0x94, 0x81, 0x46, 0x4e, 0x1d, 0x1b, 0x57, 0x21, 0xe3, 0x01,
+	0x33, 0xc5, 0xd2, 0x12, 0xb8, 0xc8, 0x56, 0xc0, 0x09, 0xf1, 0xab, 0x38, 0x01, 0xfd, 0x04, 0xb2,
+	0xfd, 0xe1, 0xd8, 0x20, 0xb2, 0x8e, 0xc9, 0xd1, 0x50, 0x2d, 0x25, 0xd6, 0x84, 0xf5, 0x9c, 0x67,
+	0xec, 0xa6, 0xcd, 0x6b, 0x51, 0x96, 0x94, 0xe9, 0x7b, 0x03, 0x71, 0x0f, 0x2a, 0x93, 0x74, 0xe5,
+	0xfe, 0xbb, 0x1b, 0xf4, 0xdf, 0xca, 0x79, 0xff, 0x75, 0xb0, 0xa9, 0x61, 0x8b, 0x2e, 0xe1, 0x78,
+	0xf2, 0x99, 0x00, 0x8b, 0x91, 0x02, 0x97, 0x39, 0x55, 0x01, 0xc4, 0xd8, 0xd4, 0x99, 0xb2, 0x45,
+	0x67, 0x72, 0x1f, 0xdc, 0xbd, 0x70, 0xe9, 0x73, 0xd4, 0x86, 0x41, 0xcc, 0x53, 0x29, 0x3f, 0x08,
+	0x91, 0xcb, 0x9b, 0xb0, 0x18, 0x29, 0x8a, 0xf2, 0x90, 0x38, 0xc6, 0xa7, 0x5c, 0x27, 0xfb, 0x13,
+	0x2d, 0x40, 0x8a, 0xea, 0x51, 0x8a, 0xaf, 0x09, 0xeb, 0x49, 0x89, 0x0d, 0xde, 0x8f, 0xdf, 0x17,
+	0xc4, 0x87, 0x50, 0xa8, 0xf5, 0x89, 0xf6, 0x39, 0x07, 0x78, 0xf5, 0xe8, 0xfd, 0x39, 0x2c, 0x04,
+	0x81, 0xb8, 0xdb, 0xd7, 0x61, 0x4a, 0xc7, 0xc4, 0xd4, 0xfa, 0x1c, 0x27, 0xcf, 0x71, 0x46, 0xbd,
+	0x6a, 0x8b, 0xd2, 0x25, 0xce, 0x17, 0x87, 0x70, 0xdd, 0x8f, 0x50, 0x37, 0xb1, 0x72, 0xac, 0x0e,
+	0x7f, 0x65, 0xbc, 0xb2, 0x4e, 0xa1, 0x3d, 0x8a, 0x87, 0xf6, 0x48, 0xdc, 0x87, 0x95, 0x09, 0x0b,
+	0x72, 0xdd, 0x7f, 0x1a, 0x0c, 0x99, 0x1b, 0xce, 0x72, 0x91, 0xb3, 0x9a, 0x04, 0xeb, 0x4e, 0xd8,
+	0xfc, 0x5d, 0x80, 0x6b, 0x13, 0x85, 0xec, 0xb4, 0x61, 0x26, 0xfb, 0x63, 0x07, 0x18, 0x89, 0x06,
+	0x8f, 0x9b, 0x57, 0xde, 0xa6, 0x39, 0x79, 0x45, 0x37, 0x1b, 0xdd, 0x80, 0x2c, 0x8b, 0x28, 0x99,
+	0xe6, 0x00, 0xcd, 0x92, 0xa4, 0x94, 0xb1, 0x7c, 0xf1, 0x79, 0x1f, 0x4a, 0x86, 0x62, 0x6b, 0x20,
+	0x1f, 0x69, 0x16, 0x19, 0x1e, 0x9a, 0x8a, 0x2e, 0xf7, 0xc6, 0xfd, 0x63, 0x4c, 0xac, 0x52, 0x92,
+	0x8a, 0x2f, 0x31, 0xfe, 0xb6, 0xc3, 0xae, 0x33, 0xae, 0xf8, 0x95, 0x00, 0x19, 0x09, 0x2b, 0xaa,
+	0xe3, 0xf7, 0x2a, 0x4c, 0x7f, 0x36, 0x66, 0xf1, 0x1b, 0x72, 0xfb, 0x47, 0x63, 0x6c, 0x3a, 0xc5,
+	0x40, 0x72, 0x84, 0xd0, 0x3e, 0x14, 0x95, 0x7e, 0x1f, 0x8f, 0x08, 0x56, 0x65, 0x93, 0xbb, 0x52,
+	0x26, 0xa7, 0x23, 0x1e, 0xff, 0xb9, 0x3b, 0x6b, 0xce, 0x7c, 0xdf, 0x2a, 0x55, 0xc7, 0xe9, 0xdd,
+	0xd3, 0x11, 0x96, 0x16, 0x1d, 0x00, 0x3f, 0xd5, 0x12, 0xef, 0x41, 0xd6, 0x4f, 0x40, 0x19, 0x98,
+	0xee, 0xd4, 0x5a, 0xbb, 0x4f, 0x1a, 0x9d, 0x7c, 0x0c, 0x15, 0xa1, 0xd0, 0xe9, 0x4a, 0x8d, 0x5a,
+	0xab, 0xb1, 0x25, 0xef, 0xb7, 0x25, 0x79, 0x73, 0x7b, 0x6f, 0xe7, 0x71, 0x27, 0x2f, 0x88, 0x1f,
+	0x42, 0x96, 0x2d, 0xc4, 0x77, 0x75, 0x03, 0xa6, 0x4d, 0x6c, 0x8d, 0x07, 0xc4, 0xb1, 0x67, 0x31,
+	0x64, 0x0f, 0x93, 0x93, 0x1c, 0x29, 0xf1, 0x14, 0x50, 0x87, 0x98, 0x58, 0xd1, 0x03, 0x30, 0x75,
+	0xc8, 0xf5, 0x8f, 0xc6, 0xc6, 0x31, 0x56, 0x9d, 0xec, 0x66, 0x68, 0xcb, 0x0e, 0x1a, 0x9b, 0xb3,
+	0xc9, 0x64, 0x78, 0x56, 0xcc, 0xf6, 0xfd, 0x43, 0x7b, 0xa3, 0x6d, 0xaf, 0x9d, 0xca, 0x9a, 0xa1,
+	0xe2, 0x13, 0xba, 0xd1, 0x09, 0x09, 0x28, 0xa9, 0x69, 0x53, 0xc4, 0xbf, 0x08, 0x50, 0x88, 0xc0,
+	0x41, 0x07, 0x30, 0x45, 0xc3, 0x21, 0xdc, 0x0d, 0x46, 0x3d, 0x96, 0x0b, 0xbb, 0x8a, 0x66, 0xd6,
+	0xdf, 0xfb, 0xf2, 0xd9, 0x6a, 0xec, 0x5f, 0xcf, 0x56, 0x6f, 0x5f, 0xa5, 0xb5, 0xb1, 0x79, 0x35,
+	0x55, 0x19, 0x11, 0x6c, 0x4a, 0x1c, 0x1d, 0xdd, 0x86, 0x29, 0xaa, 0xb1, 0x53, 0xba, 0x0a, 0x11,
+	0xc6, 0xd5, 0x93, 0xf6, 0x3a, 0x12, 0x17, 0x14, 0x7f, 0x17, 0x87, 0x8c, 0x8f, 0x8b, 0x2a, 0x90,
+	0xd1, 0x35, 0x43, 0x26, 0x9a, 0x8e, 0x65, 0x9a, 0x4a, 0xb6, 0x8d, 0x69, 0x5d, 0x33, 0xba, 0x9a,
+	0x8e, 0x5b, 0x16, 0xe5, 0x2b, 0x27, 0x2e, 0x3f, 0xce, 0xf9, 0xca, 0x09, 0xe7, 0xdf, 0x82, 0xa4,
+	0x1d, 0x3c, 0xbc, 0x13, 0x5c, 0x8f, 0x50, 0xa0, 0xda, 0x30, 0xfa, 0x43, 0x55, 0x33, 0x0e, 0x25,
+	0x2a, 0x89, 0x76, 0x21, 0xa9, 0x2a, 0x44, 0xa1, 0x61, 0x9e, 0xad, 0x7f, 0xc0, 0xbd, 0x70, 0xef,
+	0x4a, 0x5e, 0xd8, 0x33, 0x2c, 0xe5, 0x00, 0xd7, 0x4f, 0x09, 0xee, 0x0c, 0xb4, 0x3e, 0x96, 0x28,
+	0x92, 0xb8, 0x05, 0x33, 0xce, 0x1a, 0x76, 0xd0, 0xed, 0xed, 0x3c, 0xde, 0x69, 0x7f, 0xb2, 0x93,
+	0x8f, 0xa1, 0x69, 0x48, 0xec, 0xb7, 0xa5, 0xbc, 0x80, 0x66, 0x21, 0xbd, 0xdd, 0xec, 0x74, 0xdb,
+	0x0f, 0xa5, 0x5a, 0x2b, 0x1f, 0x47, 0x05, 0x98, 0x7b, 0xf0, 0xa4, 0x5d, 0xeb, 0xca, 0x1e, 0x31,
+	0x21, 0xfe, 0x47, 0x80, 0xac, 0x3f, 0x65, 0xd0, 0x3b, 0x80, 0x2c, 0xa2, 0x98, 0x84, 0x1a, 0x6f,
+	0x11, 0x45, 0x1f, 0x79, 0x1e, 0xca, 0x53, 0x4e, 0xd7, 0x61, 0xb4, 0x2c, 0xb4, 0x0e, 0x79, 0x6c,
+	0xa8, 0x41, 0x59, 0xe6, 0xad, 0x1c, 0x36, 0x54, 0xbf, 0xa4, 0xbf, 0x52, 0x26, 0xae, 0x54, 0x29,
+	0x7f, 0x06, 0xcb, 0x16, 0x75, 0xa8, 0x66, 0x1c, 0xca, 0x6c, 0x23, 0xe5, 0x9e, 0xcd, 0x94, 0x2d,
+	0xed, 0x0b, 0x5c, 0x52, 0x69, 0xc1, 0x28, 0xb9, 0x22, 0xd4, 0xed, 0x56, 0xdd, 0x16, 0xe8, 0x68,
+	0x5f, 0xe0, 0x47, 0xc9, 0x99, 0x64, 0x3e, 0x25, 0xa5, 0x8e, 0x34, 0x83, 0x58, 0xe2, 0x1f, 0x05,
+	0x58, 0x68, 0x9c, 0x60, 0x7d, 0x34, 0x50, 0xcc, 0x37, 0x62, 0xee, 0xed, 0x73, 0xe6, 0x2e, 0x46,
+	0x99, 0x6b, 0xf9, 0xba, 0xd5, 0x63, 0x98, 0x0d, 0x24, 0x3b, 0x7a, 0x1f, 0x80, 0xae, 0x14, 0x55,
+	0xe7, 0x46, 0xbd, 0xaa, 0xbd, 0x1c, 0x2f, 0xe5, 0x2c, 0xda, 0x7d, 0xd2, 0xe2, 0xff, 0xe3, 0x50,
+	0xa0, 0x68, 0x4e, 0x95, 0xe0, 0x98, 0x1f, 0x42, 0x86, 0xb9, 0xd2, 0x0f, 0x5a, 0x74, 0x54, 0xf3,
+	0x20, 0xfd, 0x59, 0xe4, 0x9f, 0x11, 0x52, 0x2a, 0xfe, 0x32, 0x4a, 0xa1, 0x47, 0x90, 0xf7, 0x76,
+	0x94, 0x23, 0x30, 0xe7, 0x5c, 0x0b, 0x94, 0x3b, 0xa6, 0x73, 0x00, 0x66, 0xce, 0x9d, 0xc8, 0xc8,
+	0xe8, 0x1e, 0x14, 0x35, 0x4b, 0xb6, 0x77, 0x63, 0x78, 0xc0, 0xb1, 0x64, 0x26, 0x43, 0x73, 0x6c,
+	0x46, 0x2a, 0x68, 0x56, 0xc3, 0x50, 0xdb, 0x07, 0x4c, 0x9e, 0x41, 0xa2, 0x4f, 0xa1, 0x18, 0xd6,
+	0x80, 0x87, 0x56, 0x29, 0x45, 0x15, 0x59, 0x9d, 0xa8, 0x08, 0x8f, 0x2f, 0xa6, 0xce, 0x62, 0x48,
+	0x1d, 0xc6, 0x14, 0x7f, 0x2f, 0xc0, 0xfc, 0xb9, 0x89, 0x6f, 0xac, 0x30, 0xae, 0xf2, 0xbd, 0xe5,
+	0x0d, 0x98, 0x57, 0x6e, 0x4a, 0xa2, 0xfd, 0x57, 0xd4, 0xa0, 0x38, 0xc1, 0x2c, 0x5f, 0xf7, 0x66,
+	0x65, 0x5f, 0xf0, 0x77, 0x6f, 0x5a, 0xf7, 0xd1, 0x8f, 0x42, 0x75, 0x77, 0xd6, 0x3d, 0x00, 0x47,
+	0x54, 0xdc, 0x0e, 0x2c, 0x86, 0xf2, 0xed, 0x3b, 0x08, 0xea, 0x7f, 0x08, 0x80, 0xfc, 0x57, 0x0b,
+	0x9e, 0xc3, 0x97, 0x1c, 0x7b, 0xa3, 0x53, 0x3c, 0xfe, 0x12, 0x29, 0x9e, 0xb8, 0x34, 0xc5, 0xed,
+	0x90, 0xbb, 0x42, 0x8a, 0xdf, 0x87, 0x42, 0x40, 0x7f, 0xee, 0x93, 0x1b, 0x90, 0xf5, 0x9d, 0xad,
+	0x9c, 0x4b, 0x4b, 0xc6, 0x3b, 0x5c, 0x59, 0xe2, 0x1f, 0x04, 0x98, 0xf7, 0x6e, 0x62, 0x6f, 0xb6,
+	0x7a, 0x5d, 0xc9, 0xb4, 0x1f, 0x03, 0xf2, 0xeb, 0xc7, 0x2d, 0xbb, 0xec, 0x36, 0x26, 0x3e, 0x82,
+	0xfc, 0x9e, 0x85, 0xcd, 0x0e, 0x51, 0x88, 0x6b, 0x55, 0xf8, 0xbe, 0x25, 0x5c, 0xf1, 0xbe, 0xf5,
+	0x37, 0x01, 0xe6, 0x7d, 0x60, 0x5c, 0x85, 0x9b, 0xce, 0x6d, 0x5c, 0x1b, 0x1a, 0xb2, 0xa9, 0x10,
+	0x16, 0x21, 0x82, 0x34, 0xeb, 0x52, 0x25, 0x85, 0x60, 0x3b, 0x88, 0x8c, 0xb1, 0xee, 0x5d, 0x8a,
+	0xec, 0xf0, 0x4f, 0x1b, 0x63, 0x27, 0x87, 0xdf, 0x01, 0xa4, 0x8c, 0x34, 0x39, 0x84, 0x94, 0xa0,
+	0x48, 0x79, 0x65, 0xa4, 0x35, 0x03, 0x60, 0x55, 0x28, 0x98, 0xe3, 0x01, 0x0e, 0x8b, 0x27, 0xa9,
+	0xf8, 0xbc, 0xcd, 0x0a, 0xc8, 0x8b, 0x9f, 0x42, 0xc1, 0x56, 0xbc, 0xb9, 0x15, 0x54, 0xbd, 0x08,
+	0xd3, 0x63, 0x0b, 0x9b, 0xb2, 0xa6, 0xf2, 0xa8, 0x9e, 0xb2, 0x87, 0x4d, 0x15, 0xbd, 0xcb, 0x4f,
+	0x13, 0xf1, 0x35, 0xc1, 0x5f, 0x3c, 0xcf, 0x19, 0xcf, 0x8f, 0x0a, 0x0f, 0x01, 0xd9, 0x2c, 0x2b,
+	0x88, 0x7e, 0x1b, 0x52, 0x96, 0x4d, 0x08, 0x9f, 0x11, 0x23, 0x34, 0x91, 0x98, 0xa4, 0xf8, 0x57,
+	0x01, 0x2a, 0xec, 0x86, 0x64, 0x3d, 0x18, 0x9a, 0xc1, 0x50, 0x78, 0xcd, 0x21, 0x79, 0x1f, 0xb2,
+	0x4e, 0xac, 0xc9, 0x16, 0x26, 0x17, 0x37, 0xd5, 0x8c, 0x23, 0xda, 0xc1, 0x44, 0x7c, 0x0c, 0xab,
+	0x13, 0x75, 0x7e, 0xe9, 0x0b, 0xe1, 0x08, 0x96, 0x38, 0x58, 0x0b, 0x13, 0xc5, 0xf6, 0xae, 0x63,
+	0xf8, 0x02, 0xa4, 0x06, 0x9a, 0xae, 0x11, 0x6a, 0xeb, 0xbc, 0xc4, 0x06, 0xb6, 0x81, 0xf4, 0x43,
+	0x1e, 0x61, 0x53, 0xe6, 0x6b, 0xc4, 0xa9, 0x40, 0x8e, 0xd2, 0x77, 0xb1, 0xc9, 0xf0, 0xec, 0x27,
+	0x0f, 0xce, 0x4f, 0xb0, 0xbd, 0xe6, 0x2b, 0xb6, 0xa1, 0x78, 0x6e, 0x45, 0xae, 0xf6, 0x3d, 0x98,
+	0xd1, 0x39, 0x8d, 0x2b, 0x5e, 0x0a, 0x2b, 0xee, 0xce, 0x71, 0x25, 0xc5, 0xff, 0x09, 0x30, 0x17,
+	0x6a, 0xf4, 0xb6, 0x9a, 0x07, 0xe6, 0x50, 0x97, 0x9d, 0x77, 0x2b, 0x2f, 0xe4, 0x72, 0x36, 0xbd,
+	0xc9, 0xc9, 0x4d, 0xd5, 0x1f, 0x93, 0xf1, 0x40, 0x4c, 0x7a, 0x5d, 0x2e, 0xf1, 0x5a, 0xbb, 0x9c,
+	0xd7, 0x86, 0x92, 0x97, 0xb7, 0xa1, 0xaf, 0x04, 0x48, 0x31, 0x0b, 0x5f, 0x57, 0x5c, 0x96, 0x61,
+	0x06, 0xf3, 0x63, 0x38, 0xdd, 0xb8, 0x94, 0xe4, 0x8e, 0x5f, 0xc3, 0xa1, 0xbf, 0x06, 0xb3, 0x81,
+	0x08, 0x7e, 0x85, 0x47, 0x11, 0x19, 0xb2, 0x7e, 0x0e, 0xba, 0xc9, 0xef, 0x32, 0xac, 0xca, 0xce,
+	0x3b, 0xb3, 0x29, 0x9b, 0x5e, 0x7c, 0x29, 0x1b, 0x21, 0x48, 0xfa, 0x5e, 0x2c, 0xe8, 0xb7, 0xf7,
+	0x84, 0xc3, 0x22, 0x96, 0x0d, 0xc4, 0xdf, 0x08, 0x90, 0xf3, 0xe2, 0xeb, 0x81, 0x36, 0xc0, 0xdf,
+	0x45, 0x78, 0x95, 0x61, 0xe6, 0x40, 0x1b, 0x60, 0xaa, 0x03, 0x5b, 0xce, 0x1d, 0xdb, 0xba, 0x79,
+	0x7e, 0x66, 0x9e, 0x7a, 0x7b, 0x1d, 0x32, 0xbe, 0x46, 0x61, 0xdf, 0x85, 0x9a, 0x3b, 0x72, 0xab,
+	0xd1, 0x6a, 0x4b, 0xbf, 0xc8, 0xc7, 0x10, 0xc0, 0x54, 0x6d, 0xb3, 0xdb, 0xfc, 0xb8, 0x91, 0x17,
+	0xde, 0x7e, 0x04, 0x69, 0xd7, 0x58, 0x94, 0x86, 0x54, 0xe3, 0xa3, 0xbd, 0xda, 0x93, 0x7c, 0xcc,
+	0x9e, 0xb2, 0xd3, 0xee, 0xca, 0x6c, 0x28, 0xa0, 0x39, 0xc8, 0x48, 0x8d, 0x87, 0x8d, 0x7d, 0xb9,
+	0x55, 0xeb, 0x6e, 0x6e, 0xe7, 0xe3, 0x08, 0x41, 0x8e, 0x11, 0x76, 0xda, 0x9c, 0x96, 0xb8, 0xf3,
+	0xcf, 0x19, 0x98, 0x71, 0xac, 0x41, 0xef, 0x41, 0x72, 0x77, 0x6c, 0x1d, 0xa1, 0x25, 0x2f, 0x13,
+	0x3e, 0x31, 0x35, 0x82, 0x79, 0xc5, 0x28, 0x17, 0xcf, 0xd1, 0x59, 0x5e, 0x8b, 0x31, 0xb4, 0x05,
+	0x19, 0xdf, 0x49, 0x0d, 0x45, 0xbe, 0x6e, 0x94, 0x97, 0x23, 0xce, 0xaa, 0x1e, 0xc6, 0x2d, 0x01,
+	0xb5, 0x21, 0x47, 0x59, 0xce, 0x49, 0xcc, 0x42, 0xee, 0x55, 0x35, 0xea, 0x32, 0x54, 0x5e, 0x99,
+	0xc0, 0x75, 0xd5, 0xda, 0x0e, 0x3e, 0xe2, 0x96, 0xa3, 0xde, 0x7b, 0xc3, 0xca, 0x45, 0x1c, 0x78,
+	0xc4, 0x18, 0x6a, 0x00, 0x78, 0xc7, 0x05, 0x74, 0x2d, 0x20, 0xec, 0x3f, 0xe2, 0x94, 0xcb, 0x51,
+	0x2c, 0x17, 0xa6, 0x0e, 0x69, 0xb7, 0xe9, 0xa1, 0x52, 0x44, 0x1f, 0x64, 0x20, 0x93, 0x3b, 0xa4,
+	0x18, 0x43, 0x0f, 0x20, 0x5b, 0x1b, 0x0c, 0xae, 0x02, 0x53, 0xf6, 0x73, 0xac, 0x30, 0xce, 0x00,
+	0x8a, 0x13, 0xfa, 0x0c, 0x7a, 0xcb, 0xcd, 0xaa, 0x0b, 0x9b, 0x67, 0xf9, 0x07, 0x97, 0xca, 0xb9,
+	0xab, 0x75, 0x61, 0x2e, 0xd4, 0x16, 0x50, 0x25, 0x34, 0x3b, 0xd4, 0xa1, 0xca, 0xab, 0x13, 0xf9,
+	0x2e, 0x6a, 0x0f, 0x0a, 0x9e, 0x9f, 0xdd, 0xf7, 0x7e, 0x24, 0x9e, 0xdf, 0x84, 0xf0, 0x9f, 0x0b,
+	0xe5, 0xef, 0x5d, 0x28, 0xe3, 0x8b, 0xca, 0x63, 0x58, 0x8a, 0x7e, 0x16, 0x47, 0x37, 0x23, 0x62,
+	0xe6, 0xfc, 0x13, 0x7f, 0xf9, 0xad, 0xcb, 0xc4, 0x7c, 0x8b, 0xb5, 0x20, 0xeb, 0x7f, 0xf4, 0x44,
+	0xcb, 0x51, 0xef, 0xa5, 0x0e, 0xf0, 0xf5, 0x68, 0xa6, 0x0f, 0xee, 0x08, 0x16, 0x23, 0xdf, 0x50,
+	0xd1, 0xf7, 0x2f, 0x7c, 0x87, 0x75, 0x16, 0xb8, 0x79, 0x89, 0x94, 0xb7, 0x52, 0xfd, 0x83, 0xa7,
+	0xcf, 0x2b, 0xb1, 0xaf, 0x9f, 0x57, 0x62, 0xdf, 0x3c, 0xaf, 0x08, 0xbf, 0x3e, 0xab, 0x08, 0x7f,
+	0x3a, 0xab, 0x08, 0x5f, 0x9e, 0x55, 0x84, 0xa7, 0x67, 0x15, 0xe1, 0xdf, 0x67, 0x15, 0xe1, 0xbf,
+	0x67, 0x95, 0xd8, 0x37, 0x67, 0x15, 0xe1, 0xb7, 0x2f, 0x2a, 0xb1, 0xa7, 0x2f, 0x2a, 0xb1, 0xaf,
+	0x5f, 0x54, 0x62, 0xbf, 0x9c, 0xea, 0x0f, 0x34, 0x6c, 0x90, 0xde, 0x14, 0xfd, 0x3b, 0xe8, 0xee,
+	0xb7, 0x03, 0x00, 0xcb, 0x5f, 0x56, 0x7d, 0x89, 0x1a, 0x00, 0x00,
 }
 
 func (x CountMethod) String() string {
@@ -2363,14 +2627,14 @@ func (this *LabelValueSeriesCount) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *ReadRequest) Equal(that interface{}) bool {
+func (this *ActiveSeriesRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*ReadRequest)
+	that1, ok := that.(*ActiveSeriesRequest)
 	if !ok {
-		that2, ok := that.(ReadRequest)
+		that2, ok := that.(ActiveSeriesRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -2382,32 +2646,24 @@ func (this *ReadRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if len(this.Queries) != len(that1.Queries) {
-		return false
-	}
-	for i := range this.Queries {
-		if !this.Queries[i].Equal(that1.Queries[i]) {
-			return false
-		}
-	}
-	if len(this.AcceptedResponseTypes) != len(that1.AcceptedResponseTypes) {
+	if len(this.Matchers) != len(that1.Matchers) {
 		return false
 	}
-	for i := range this.AcceptedResponseTypes {
-		if this.AcceptedResponseTypes[i] != that1.AcceptedResponseTypes[i] {
+	for i := range this.Matchers {
+		if !this.Matchers[i].Equal(that1.Matchers[i]) {
 			return false
 		}
 	}
 	return true
 }
-func (this *ReadResponse) Equal(that interface{}) bool {
+func (this *ActiveSeriesResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*ReadResponse)
+	that1, ok := that.(*ActiveSeriesResponse)
 	if !ok {
-		that2, ok := that.(ReadResponse)
+		that2, ok := that.(ActiveSeriesResponse)
 		if ok {
 			that1 = &that2
 		} else {
@@ -2419,24 +2675,24 @@ func (this *ReadResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if len(this.Results) != len(that1.Results) {
+	if len(this.Metric) != len(that1.Metric) {
 		return false
 	}
-	for i := range this.Results {
-		if !this.Results[i].Equal(that1.Results[i]) {
+	for i := range this.Metric {
+		if !this.Metric[i].Equal(that1.Metric[i]) {
 			return false
 		}
 	}
 	return true
 }
-func (this *StreamReadResponse) Equal(that interface{}) bool {
+func (this *ActiveSeriesBreakdownRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*StreamReadResponse)
+	that1, ok := that.(*ActiveSeriesBreakdownRequest)
 	if !ok {
-		that2, ok := that.(StreamReadResponse)
+		that2, ok := that.(ActiveSeriesBreakdownRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -2448,27 +2704,187 @@ func (this *StreamReadResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if len(this.ChunkedSeries) != len(that1.ChunkedSeries) {
+	if len(this.Matchers) != len(that1.Matchers) {
 		return false
 	}
-	for i := range this.ChunkedSeries {
-		if !this.ChunkedSeries[i].Equal(that1.ChunkedSeries[i]) {
+	for i := range this.Matchers {
+		if !this.Matchers[i].Equal(that1.Matchers[i]) {
 			return false
 		}
 	}
-	if this.QueryIndex != that1.QueryIndex {
+	if this.LabelName != that1.LabelName {
 		return false
 	}
 	return true
 }
-func (this *StreamChunkedSeries) Equal(that interface{}) bool {
+func (this *ActiveSeriesBreakdownResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*StreamChunkedSeries)
+	that1, ok := that.(*ActiveSeriesBreakdownResponse)
 	if !ok {
-		that2, ok := that.(StreamChunkedSeries)
+		that2, ok := that.(ActiveSeriesBreakdownResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Items) != len(that1.Items) {
+		return false
+	}
+	for i := range this.Items {
+		if !this.Items[i].Equal(that1.Items[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ActiveSeriesBreakdownItem) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ActiveSeriesBreakdownItem)
+	if !ok {
+		that2, ok := that.(ActiveSeriesBreakdownItem)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.MetricName != that1.MetricName {
+		return false
+	}
+	if this.LabelValue != that1.LabelValue {
+		return false
+	}
+	if this.SeriesCount != that1.SeriesCount {
+		return false
+	}
+	if this.NativeHistogramBuckets != that1.NativeHistogramBuckets {
+		return false
+	}
+	return true
+}
+func (this *ReadRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReadRequest)
+	if !ok {
+		that2, ok := that.(ReadRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Queries) != len(that1.Queries) {
+		return false
+	}
+	for i := range this.Queries {
+		if !this.Queries[i].Equal(that1.Queries[i]) {
+			return false
+		}
+	}
+	if len(this.AcceptedResponseTypes) != len(that1.AcceptedResponseTypes) {
+		return false
+	}
+	for i := range this.AcceptedResponseTypes {
+		if this.AcceptedResponseTypes[i] != that1.AcceptedResponseTypes[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReadResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReadResponse)
+	if !ok {
+		that2, ok := that.(ReadResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Results) != len(that1.Results) {
+		return false
+	}
+	for i := range this.Results {
+		if !this.Results[i].Equal(that1.Results[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *StreamReadResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*StreamReadResponse)
+	if !ok {
+		that2, ok := that.(StreamReadResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.ChunkedSeries) != len(that1.ChunkedSeries) {
+		return false
+	}
+	for i := range this.ChunkedSeries {
+		if !this.ChunkedSeries[i].Equal(that1.ChunkedSeries[i]) {
+			return false
+		}
+	}
+	if this.QueryIndex != that1.QueryIndex {
+		return false
+	}
+	return true
+}
+func (this *StreamChunkedSeries) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*StreamChunkedSeries)
+	if !ok {
+		that2, ok := that.(StreamChunkedSeries)
 		if ok {
 			that1 = &that2
 		} else {
@@ -3391,6 +3807,68 @@ func (this *LabelValueSeriesCount) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *ActiveSeriesRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ActiveSeriesRequest{")
+	if this.Matchers != nil {
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ActiveSeriesResponse{")
+	if this.Metric != nil {
+		s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesBreakdownRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.ActiveSeriesBreakdownRequest{")
+	if this.Matchers != nil {
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	}
+	s = append(s, "LabelName: "+fmt.Sprintf("%#v", this.LabelName)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesBreakdownResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ActiveSeriesBreakdownResponse{")
+	if this.Items != nil {
+		s = append(s, "Items: "+fmt.Sprintf("%#v", this.Items)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesBreakdownItem) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&client.ActiveSeriesBreakdownItem{")
+	s = append(s, "MetricName: "+fmt.Sprintf("%#v", this.MetricName)+",\n")
+	s = append(s, "LabelValue: "+fmt.Sprintf("%#v", this.LabelValue)+",\n")
+	s = append(s, "SeriesCount: "+fmt.Sprintf("%#v", this.SeriesCount)+",\n")
+	s = append(s, "NativeHistogramBuckets: "+fmt.Sprintf("%#v", this.NativeHistogramBuckets)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *ReadRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -3839,6 +4317,13 @@ type IngesterClient interface {
 	// that match the matchers.
 	// The listing order of the labels is not guaranteed.
 	LabelValuesCardinality(ctx context.Context, in *LabelValuesCardinalityRequest, opts ...grpc.CallOption) (Ingester_LabelValuesCardinalityClient, error)
+	// ActiveSeries returns the active series that match the matchers.
+	// The listing order of the series is not guaranteed.
+	ActiveSeries(ctx context.Context, in *ActiveSeriesRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesClient, error)
+	// ActiveSeriesBreakdown returns the number of active series and native histogram buckets
+	// per metric name, and optionally per value of another label, of the series that match the matchers.
+	// The listing order of the items is not guaranteed.
+	ActiveSeriesBreakdown(ctx context.Context, in *ActiveSeriesBreakdownRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesBreakdownClient, error)
 }
 
 type ingesterClient struct {
@@ -4017,6 +4502,70 @@ func (x *ingesterLabelValuesCardinalityClient) Recv() (*LabelValuesCardinalityRe
 	return m, nil
 }
 
+func (c *ingesterClient) ActiveSeries(ctx context.Context, in *ActiveSeriesRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[3], "/cortex.Ingester/ActiveSeries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterActiveSeriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ingester_ActiveSeriesClient interface {
+	Recv() (*ActiveSeriesResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterActiveSeriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterActiveSeriesClient) Recv() (*ActiveSeriesResponse, error) {
+	m := new(ActiveSeriesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ingesterClient) ActiveSeriesBreakdown(ctx context.Context, in *ActiveSeriesBreakdownRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesBreakdownClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[4], "/cortex.Ingester/ActiveSeriesBreakdown", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterActiveSeriesBreakdownClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ingester_ActiveSeriesBreakdownClient interface {
+	Recv() (*ActiveSeriesBreakdownResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterActiveSeriesBreakdownClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterActiveSeriesBreakdownClient) Recv() (*ActiveSeriesBreakdownResponse, error) {
+	m := new(ActiveSeriesBreakdownResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // IngesterServer is the server API for Ingester service.
 type IngesterServer interface {
 	Push(context.Context, *mimirpb.WriteRequest) (*mimirpb.WriteResponse, error)
@@ -4035,6 +4584,13 @@ type IngesterServer interface {
 	// that match the matchers.
 	// The listing order of the labels is not guaranteed.
 	LabelValuesCardinality(*LabelValuesCardinalityRequest, Ingester_LabelValuesCardinalityServer) error
+	// ActiveSeries returns the active series that match the matchers.
+	// The listing order of the series is not guaranteed.
+	ActiveSeries(*ActiveSeriesRequest, Ingester_ActiveSeriesServer) error
+	// ActiveSeriesBreakdown returns the number of active series and native histogram buckets
+	// per metric name, and optionally per value of another label, of the series that match the matchers.
+	// The listing order of the items is not guaranteed.
+	ActiveSeriesBreakdown(*ActiveSeriesBreakdownRequest, Ingester_ActiveSeriesBreakdownServer) error
 }
 
 // UnimplementedIngesterServer can be embedded to have forward compatible implementations.
@@ -4074,6 +4630,12 @@ func (*UnimplementedIngesterServer) LabelNamesAndValues(req *LabelNamesAndValues
 func (*UnimplementedIngesterServer) LabelValuesCardinality(req *LabelValuesCardinalityRequest, srv Ingester_LabelValuesCardinalityServer) error {
 	return status.Errorf(codes.Unimplemented, "method LabelValuesCardinality not implemented")
 }
+func (*UnimplementedIngesterServer) ActiveSeries(req *ActiveSeriesRequest, srv Ingester_ActiveSeriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ActiveSeries not implemented")
+}
+func (*UnimplementedIngesterServer) ActiveSeriesBreakdown(req *ActiveSeriesBreakdownRequest, srv Ingester_ActiveSeriesBreakdownServer) error {
+	return status.Errorf(codes.Unimplemented, "method ActiveSeriesBreakdown not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -4286,11 +4848,53 @@ func (x *ingesterLabelValuesCardinalityServer) Send(m *LabelValuesCardinalityRes
 	return x.ServerStream.SendMsg(m)
 }
 
-var _Ingester_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "cortex.Ingester",
-	HandlerType: (*IngesterServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
+func _Ingester_ActiveSeries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ActiveSeriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IngesterServer).ActiveSeries(m, &ingesterActiveSeriesServer{stream})
+}
+
+type Ingester_ActiveSeriesServer interface {
+	Send(*ActiveSeriesResponse) error
+	grpc.ServerStream
+}
+
+type ingesterActiveSeriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterActiveSeriesServer) Send(m *ActiveSeriesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Ingester_ActiveSeriesBreakdown_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ActiveSeriesBreakdownRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IngesterServer).ActiveSeriesBreakdown(m, &ingesterActiveSeriesBreakdownServer{stream})
+}
+
+type Ingester_ActiveSeriesBreakdownServer interface {
+	Send(*ActiveSeriesBreakdownResponse) error
+	grpc.ServerStream
+}
+
+type ingesterActiveSeriesBreakdownServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterActiveSeriesBreakdownServer) Send(m *ActiveSeriesBreakdownResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Ingester_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "cortex.Ingester",
+	HandlerType: (*IngesterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
 			MethodName: "Push",
 			Handler:    _Ingester_Push_Handler,
 		},
@@ -4339,6 +4943,16 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Ingester_LabelValuesCardinality_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ActiveSeries",
+			Handler:       _Ingester_ActiveSeries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ActiveSeriesBreakdown",
+			Handler:       _Ingester_ActiveSeriesBreakdown_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "ingester.proto",
 }
@@ -4591,6 +5205,208 @@ func (m *LabelValueSeriesCount) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *ActiveSeriesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Matchers) > 0 {
+		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Matchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Metric) > 0 {
+		for iNdEx := len(m.Metric) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metric[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesBreakdownRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesBreakdownRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesBreakdownRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.LabelName) > 0 {
+		i -= len(m.LabelName)
+		copy(dAtA[i:], m.LabelName)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.LabelName)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Matchers) > 0 {
+		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Matchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesBreakdownResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesBreakdownResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesBreakdownResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Items) > 0 {
+		for iNdEx := len(m.Items) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Items[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesBreakdownItem) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesBreakdownItem) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesBreakdownItem) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.NativeHistogramBuckets != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NativeHistogramBuckets))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.SeriesCount != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.SeriesCount))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.LabelValue) > 0 {
+		i -= len(m.LabelValue)
+		copy(dAtA[i:], m.LabelValue)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.LabelValue)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.MetricName) > 0 {
+		i -= len(m.MetricName)
+		copy(dAtA[i:], m.MetricName)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.MetricName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ReadRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -6010,36 +6826,29 @@ func (m *LabelValueSeriesCount) Size() (n int) {
 	return n
 }
 
-func (m *ReadRequest) Size() (n int) {
+func (m *ActiveSeriesRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.Queries) > 0 {
-		for _, e := range m.Queries {
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
 			l = e.Size()
 			n += 1 + l + sovIngester(uint64(l))
 		}
 	}
-	if len(m.AcceptedResponseTypes) > 0 {
-		l = 0
-		for _, e := range m.AcceptedResponseTypes {
-			l += sovIngester(uint64(e))
-		}
-		n += 1 + sovIngester(uint64(l)) + l
-	}
 	return n
 }
 
-func (m *ReadResponse) Size() (n int) {
+func (m *ActiveSeriesResponse) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.Results) > 0 {
-		for _, e := range m.Results {
+	if len(m.Metric) > 0 {
+		for _, e := range m.Metric {
 			l = e.Size()
 			n += 1 + l + sovIngester(uint64(l))
 		}
@@ -6047,38 +6856,33 @@ func (m *ReadResponse) Size() (n int) {
 	return n
 }
 
-func (m *StreamReadResponse) Size() (n int) {
+func (m *ActiveSeriesBreakdownRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.ChunkedSeries) > 0 {
-		for _, e := range m.ChunkedSeries {
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
 			l = e.Size()
 			n += 1 + l + sovIngester(uint64(l))
 		}
 	}
-	if m.QueryIndex != 0 {
-		n += 1 + sovIngester(uint64(m.QueryIndex))
+	l = len(m.LabelName)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
 	}
 	return n
 }
 
-func (m *StreamChunkedSeries) Size() (n int) {
+func (m *ActiveSeriesBreakdownResponse) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.Labels) > 0 {
-		for _, e := range m.Labels {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
-		}
-	}
-	if len(m.Chunks) > 0 {
-		for _, e := range m.Chunks {
+	if len(m.Items) > 0 {
+		for _, e := range m.Items {
 			l = e.Size()
 			n += 1 + l + sovIngester(uint64(l))
 		}
@@ -6086,15 +6890,114 @@ func (m *StreamChunkedSeries) Size() (n int) {
 	return n
 }
 
-func (m *StreamChunk) Size() (n int) {
+func (m *ActiveSeriesBreakdownItem) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.MinTimeMs != 0 {
-		n += 1 + sovIngester(uint64(m.MinTimeMs))
-	}
+	l = len(m.MetricName)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	l = len(m.LabelValue)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	if m.SeriesCount != 0 {
+		n += 1 + sovIngester(uint64(m.SeriesCount))
+	}
+	if m.NativeHistogramBuckets != 0 {
+		n += 1 + sovIngester(uint64(m.NativeHistogramBuckets))
+	}
+	return n
+}
+
+func (m *ReadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Queries) > 0 {
+		for _, e := range m.Queries {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.AcceptedResponseTypes) > 0 {
+		l = 0
+		for _, e := range m.AcceptedResponseTypes {
+			l += sovIngester(uint64(e))
+		}
+		n += 1 + sovIngester(uint64(l)) + l
+	}
+	return n
+}
+
+func (m *ReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *StreamReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.ChunkedSeries) > 0 {
+		for _, e := range m.ChunkedSeries {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if m.QueryIndex != 0 {
+		n += 1 + sovIngester(uint64(m.QueryIndex))
+	}
+	return n
+}
+
+func (m *StreamChunkedSeries) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Labels) > 0 {
+		for _, e := range m.Labels {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.Chunks) > 0 {
+		for _, e := range m.Chunks {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *StreamChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MinTimeMs != 0 {
+		n += 1 + sovIngester(uint64(m.MinTimeMs))
+	}
 	if m.MaxTimeMs != 0 {
 		n += 1 + sovIngester(uint64(m.MaxTimeMs))
 	}
@@ -6669,6 +7572,80 @@ func (this *LabelValueSeriesCount) String() string {
 	}, "")
 	return s
 }
+func (this *ActiveSeriesRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMatchers := "[]*LabelMatcher{"
+	for _, f := range this.Matchers {
+		repeatedStringForMatchers += strings.Replace(f.String(), "LabelMatcher", "LabelMatcher", 1) + ","
+	}
+	repeatedStringForMatchers += "}"
+	s := strings.Join([]string{`&ActiveSeriesRequest{`,
+		`Matchers:` + repeatedStringForMatchers + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetric := "[]*Metric{"
+	for _, f := range this.Metric {
+		repeatedStringForMetric += strings.Replace(fmt.Sprintf("%v", f), "Metric", "mimirpb.Metric", 1) + ","
+	}
+	repeatedStringForMetric += "}"
+	s := strings.Join([]string{`&ActiveSeriesResponse{`,
+		`Metric:` + repeatedStringForMetric + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesBreakdownRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMatchers := "[]*LabelMatcher{"
+	for _, f := range this.Matchers {
+		repeatedStringForMatchers += strings.Replace(f.String(), "LabelMatcher", "LabelMatcher", 1) + ","
+	}
+	repeatedStringForMatchers += "}"
+	s := strings.Join([]string{`&ActiveSeriesBreakdownRequest{`,
+		`Matchers:` + repeatedStringForMatchers + `,`,
+		`LabelName:` + fmt.Sprintf("%v", this.LabelName) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesBreakdownResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForItems := "[]*ActiveSeriesBreakdownItem{"
+	for _, f := range this.Items {
+		repeatedStringForItems += strings.Replace(f.String(), "ActiveSeriesBreakdownItem", "ActiveSeriesBreakdownItem", 1) + ","
+	}
+	repeatedStringForItems += "}"
+	s := strings.Join([]string{`&ActiveSeriesBreakdownResponse{`,
+		`Items:` + repeatedStringForItems + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesBreakdownItem) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ActiveSeriesBreakdownItem{`,
+		`MetricName:` + fmt.Sprintf("%v", this.MetricName) + `,`,
+		`LabelValue:` + fmt.Sprintf("%v", this.LabelValue) + `,`,
+		`SeriesCount:` + fmt.Sprintf("%v", this.SeriesCount) + `,`,
+		`NativeHistogramBuckets:` + fmt.Sprintf("%v", this.NativeHistogramBuckets) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *ReadRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -7817,6 +8794,541 @@ func (m *LabelValueSeriesCount) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ActiveSeriesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = append(m.Matchers, &LabelMatcher{})
+			if err := m.Matchers[len(m.Matchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = append(m.Metric, &mimirpb.Metric{})
+			if err := m.Metric[len(m.Metric)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesBreakdownRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = append(m.Matchers, &LabelMatcher{})
+			if err := m.Matchers[len(m.Matchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LabelName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LabelName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesBreakdownResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Items", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Items = append(m.Items, &ActiveSeriesBreakdownItem{})
+			if err := m.Items[len(m.Items)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesBreakdownItem) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownItem: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesBreakdownItem: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LabelValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LabelValue = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesCount", wireType)
+			}
+			m.SeriesCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SeriesCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NativeHistogramBuckets", wireType)
+			}
+			m.NativeHistogramBuckets = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NativeHistogramBuckets |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ReadRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -76,3 +76,13 @@ func (m *IngesterServerMock) LabelValuesCardinality(req *LabelValuesCardinalityR
 	args := m.Called(req, srv)
 	return args.Error(0)
 }
+
+func (m *IngesterServerMock) ActiveSeries(req *ActiveSeriesRequest, srv Ingester_ActiveSeriesServer) error {
+	args := m.Called(req, srv)
+	return args.Error(0)
+}
+
+func (m *IngesterServerMock) ActiveSeriesBreakdown(req *ActiveSeriesBreakdownRequest, srv Ingester_ActiveSeriesBreakdownServer) error {
+	args := m.Called(req, srv)
+	return args.Error(0)
+}
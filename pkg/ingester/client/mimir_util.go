@@ -33,6 +33,22 @@ func SendLabelValuesCardinalityResponse(s Ingester_LabelValuesCardinalityServer,
 	})
 }
 
+// SendActiveSeriesResponse wraps the stream's Send() checking if the context is done
+// before calling Send().
+func SendActiveSeriesResponse(s Ingester_ActiveSeriesServer, response *ActiveSeriesResponse) error {
+	return sendWithContextErrChecking(s.Context(), func() error {
+		return s.Send(response)
+	})
+}
+
+// SendActiveSeriesBreakdownResponse wraps the stream's Send() checking if the context is done
+// before calling Send().
+func SendActiveSeriesBreakdownResponse(s Ingester_ActiveSeriesBreakdownServer, response *ActiveSeriesBreakdownResponse) error {
+	return sendWithContextErrChecking(s.Context(), func() error {
+		return s.Send(response)
+	})
+}
+
 func sendWithContextErrChecking(ctx context.Context, send func() error) error {
 	// If the context has been canceled or its deadline exceeded, we should return it
 	// instead of the cryptic error the Send() will return.
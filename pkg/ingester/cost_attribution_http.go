@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"net/http"
+
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/mimir/pkg/util"
+)
+
+// costAttributionResponse is the response of the cost attribution endpoint.
+type costAttributionResponse struct {
+	// Label is the cost attribution label of the tenant.
+	Label string `json:"label"`
+	// MaxCardinality is the maximum number of values of the label tracked at the same time.
+	MaxCardinality int `json:"max_cardinality"`
+	// ActiveSeries is the number of active series in this ingester per value of the label.
+	ActiveSeries map[string]int `json:"active_series"`
+}
+
+// CostAttributionHandler returns the number of active series in this ingester per value of the cost attribution
+// label of the tenant.
+func (i *Ingester) CostAttributionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !i.cfg.ActiveSeriesMetrics.Enabled {
+		http.Error(w, "active series tracking is disabled", http.StatusNotFound)
+		return
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		http.Error(w, "TSDB not found for tenant "+userID, http.StatusNotFound)
+		return
+	}
+
+	cat := db.activeSeries.CurrentCostAttribution()
+	if cat == nil {
+		http.Error(w, "cost attribution is disabled for tenant "+userID, http.StatusNotFound)
+		return
+	}
+
+	util.WriteJSONResponse(w, costAttributionResponse{
+		Label:          cat.Label(),
+		MaxCardinality: cat.MaxCardinality(),
+		ActiveSeries:   db.activeSeries.ActiveByAttribution(),
+	})
+}
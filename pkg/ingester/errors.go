@@ -228,6 +228,10 @@ func newSampleDuplicateTimestampError(timestamp model.Time, labels []mimirpb.Lab
 	return newSampleError(globalerror.SampleDuplicateTimestamp, "the sample has been rejected because another sample with the same timestamp, but a different value, has already been ingested", timestamp, labels)
 }
 
+func newSampleIntervalTooShortError(timestamp model.Time, labels []mimirpb.LabelAdapter, minInterval time.Duration) sampleError {
+	return newSampleError(globalerror.SampleIntervalTooShort, fmt.Sprintf("the sample has been rejected because it is closer than the minimum sample interval of %s to the previous sample of the same series", model.Duration(minInterval).String()), timestamp, labels)
+}
+
 // exemplarError is an ingesterError indicating a problem with an exemplar.
 type exemplarError struct {
 	errID          globalerror.ID
@@ -346,6 +350,37 @@ var _ ingesterError = perUserSeriesLimitReachedError{}
 // Ensure that perUserSeriesLimitReachedError is an softError.
 var _ softError = perUserSeriesLimitReachedError{}
 
+// perUserHeadMemoryLimitReachedError is an ingesterError indicating that a per-user head memory limit has been reached.
+type perUserHeadMemoryLimitReachedError struct {
+	limit int64
+}
+
+// newPerUserHeadMemoryLimitReachedError creates a new perUserHeadMemoryLimitReachedError indicating that a per-user head memory limit has been reached.
+func newPerUserHeadMemoryLimitReachedError(limit int64) perUserHeadMemoryLimitReachedError {
+	return perUserHeadMemoryLimitReachedError{
+		limit: limit,
+	}
+}
+
+func (e perUserHeadMemoryLimitReachedError) Error() string {
+	return globalerror.MaxHeadMemoryPerUser.MessageWithPerTenantLimitConfig(
+		fmt.Sprintf("per-user TSDB head memory limit of %d bytes exceeded, new series can't be created", e.limit),
+		validation.MaxHeadMemoryBytesPerTenantFlag,
+	)
+}
+
+func (e perUserHeadMemoryLimitReachedError) errorCause() mimirpb.ErrorCause {
+	return mimirpb.BAD_DATA
+}
+
+func (e perUserHeadMemoryLimitReachedError) soft() {}
+
+// Ensure that perUserHeadMemoryLimitReachedError is an ingesterError.
+var _ ingesterError = perUserHeadMemoryLimitReachedError{}
+
+// Ensure that perUserHeadMemoryLimitReachedError is an softError.
+var _ softError = perUserHeadMemoryLimitReachedError{}
+
 // perUserMetadataLimitReachedError is an ingesterError indicating that a per-user metadata limit has been reached.
 type perUserMetadataLimitReachedError struct {
 	limit int
@@ -377,6 +412,44 @@ var _ ingesterError = perUserMetadataLimitReachedError{}
 // Ensure that perUserMetadataLimitReachedError is an softError.
 var _ softError = perUserMetadataLimitReachedError{}
 
+// perLabelSetSeriesLimitReachedError is an ingesterError indicating that a per-label-set series limit has been reached.
+type perLabelSetSeriesLimitReachedError struct {
+	selector string
+	limit    int
+	series   string
+}
+
+// newPerLabelSetSeriesLimitReachedError creates a new perLabelSetSeriesLimitReachedError indicating that the series limit
+// of the label set matching selector has been reached.
+func newPerLabelSetSeriesLimitReachedError(selector string, limit int, labels labels.Labels) perLabelSetSeriesLimitReachedError {
+	return perLabelSetSeriesLimitReachedError{
+		selector: selector,
+		limit:    limit,
+		series:   labels.String(),
+	}
+}
+
+func (e perLabelSetSeriesLimitReachedError) Error() string {
+	return fmt.Sprintf("%s This is for series %s",
+		globalerror.MaxSeriesPerLabelSet.Message(
+			fmt.Sprintf("per-label-set series limit of %d exceeded for the series matching %s", e.limit, e.selector),
+		),
+		e.series,
+	)
+}
+
+func (e perLabelSetSeriesLimitReachedError) errorCause() mimirpb.ErrorCause {
+	return mimirpb.BAD_DATA
+}
+
+func (e perLabelSetSeriesLimitReachedError) soft() {}
+
+// Ensure that perLabelSetSeriesLimitReachedError is an ingesterError.
+var _ ingesterError = perLabelSetSeriesLimitReachedError{}
+
+// Ensure that perLabelSetSeriesLimitReachedError is an softError.
+var _ softError = perLabelSetSeriesLimitReachedError{}
+
 // perMetricSeriesLimitReachedError is an ingesterError indicating that a per-metric series limit has been reached.
 type perMetricSeriesLimitReachedError struct {
 	limit  int
@@ -518,6 +591,9 @@ type ingesterErrSamplers struct {
 	maxMetadataPerMetricLimitExceeded *log.Sampler
 	maxSeriesPerUserLimitExceeded     *log.Sampler
 	maxMetadataPerUserLimitExceeded   *log.Sampler
+	maxSeriesPerLabelSetLimitExceeded *log.Sampler
+	sampleIntervalTooShort            *log.Sampler
+	maxHeadMemoryPerUserLimitExceeded *log.Sampler
 }
 
 func newIngesterErrSamplers(freq int64) ingesterErrSamplers {
@@ -531,6 +607,9 @@ func newIngesterErrSamplers(freq int64) ingesterErrSamplers {
 		log.NewSampler(freq),
 		log.NewSampler(freq),
 		log.NewSampler(freq),
+		log.NewSampler(freq),
+		log.NewSampler(freq),
+		log.NewSampler(freq),
 	}
 }
 
@@ -90,6 +90,22 @@ func TestNewPerUserSeriesLimitError(t *testing.T) {
 	checkIngesterError(t, wrappedErr, mimirpb.BAD_DATA, true)
 }
 
+func TestNewPerUserHeadMemoryLimitError(t *testing.T) {
+	limit := int64(1024)
+	err := newPerUserHeadMemoryLimitReachedError(limit)
+	expectedErrMsg := globalerror.MaxHeadMemoryPerUser.MessageWithPerTenantLimitConfig(
+		fmt.Sprintf("per-user TSDB head memory limit of %d bytes exceeded, new series can't be created", limit),
+		validation.MaxHeadMemoryBytesPerTenantFlag,
+	)
+	require.Equal(t, expectedErrMsg, err.Error())
+	checkIngesterError(t, err, mimirpb.BAD_DATA, true)
+
+	wrappedErr := wrapOrAnnotateWithUser(err, userID)
+	require.ErrorIs(t, wrappedErr, err)
+	require.ErrorAs(t, wrappedErr, &perUserHeadMemoryLimitReachedError{})
+	checkIngesterError(t, wrappedErr, mimirpb.BAD_DATA, true)
+}
+
 func TestNewPerUserMetadataLimitError(t *testing.T) {
 	limit := 100
 	err := newPerUserMetadataLimitReachedError(limit)
@@ -106,6 +122,23 @@ func TestNewPerUserMetadataLimitError(t *testing.T) {
 	checkIngesterError(t, wrappedErr, mimirpb.BAD_DATA, true)
 }
 
+func TestNewPerLabelSetSeriesLimitError(t *testing.T) {
+	limit := 100
+	selector := `{namespace="payments"}`
+	labels := mimirpb.FromLabelAdaptersToLabels(
+		[]mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testmetric"}, {Name: "namespace", Value: "payments"}},
+	)
+	err := newPerLabelSetSeriesLimitReachedError(selector, limit, labels)
+	expectedErrMsg := fmt.Sprintf("per-label-set series limit of %d exceeded for the series matching %s (err-mimir-max-series-per-label-set) This is for series %s", limit, selector, labels.String())
+	require.Equal(t, expectedErrMsg, err.Error())
+	checkIngesterError(t, err, mimirpb.BAD_DATA, true)
+
+	wrappedErr := wrapOrAnnotateWithUser(err, userID)
+	require.ErrorIs(t, wrappedErr, err)
+	require.ErrorAs(t, wrappedErr, &perLabelSetSeriesLimitReachedError{})
+	checkIngesterError(t, wrappedErr, mimirpb.BAD_DATA, true)
+}
+
 func TestNewPerMetricSeriesLimitError(t *testing.T) {
 	limit := 100
 	labels := mimirpb.FromLabelAdaptersToLabels(
@@ -176,6 +209,10 @@ func TestNewSampleError(t *testing.T) {
 			err:         newSampleDuplicateTimestampError(timestamp, seriesLabels),
 			expectedMsg: `the sample has been rejected because another sample with the same timestamp, but a different value, has already been ingested (err-mimir-sample-duplicate-timestamp). The affected sample has timestamp 1970-01-19T05:30:43.969Z and is from series {__name__="test"}`,
 		},
+		"newSampleIntervalTooShortError": {
+			err:         newSampleIntervalTooShortError(timestamp, seriesLabels, 15*time.Second),
+			expectedMsg: `the sample has been rejected because it is closer than the minimum sample interval of 15s to the previous sample of the same series (err-mimir-sample-interval-too-short). The affected sample has timestamp 1970-01-19T05:30:43.969Z and is from series {__name__="test"}`,
+		},
 	}
 
 	for testName, tc := range tests {
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+)
+
+// The following sizes are rough estimates of the memory used by the TSDB head data structures,
+// and are used to estimate the memory used by the TSDB head of each tenant.
+const (
+	// estimatedSeriesBytes is the memory of a series, excluding its labels and chunks, including
+	// the references held by the head series map.
+	estimatedSeriesBytes = 256
+
+	// estimatedLabelStringOverheadBytes is the memory overhead of each label name and value,
+	// in addition to the length of the string.
+	estimatedLabelStringOverheadBytes = 2
+
+	// estimatedPostingBytes is the memory of a series reference in a postings list. Each series is
+	// referenced by the postings list of each of its labels, plus the list of all postings.
+	estimatedPostingBytes = 8
+
+	// estimatedHeadChunkBytes is the memory of the chunk a series is currently appending to,
+	// which is kept in memory until it's full.
+	estimatedHeadChunkBytes = 256
+
+	// estimatedMmappedChunkBytes is the memory of the reference to a full chunk, which is memory-mapped
+	// from disk and isn't accounted for in the ingester memory.
+	estimatedMmappedChunkBytes = 32
+)
+
+// headMemoryStats is the estimated memory, in bytes, used by the TSDB head of a tenant.
+type headMemoryStats struct {
+	SeriesBytes   int64
+	LabelsBytes   int64
+	PostingsBytes int64
+	ChunksBytes   int64
+}
+
+// TotalBytes returns the estimated memory, in bytes, used by the TSDB head.
+func (s headMemoryStats) TotalBytes() int64 {
+	return s.SeriesBytes + s.LabelsBytes + s.PostingsBytes + s.ChunksBytes
+}
+
+// estimateHeadMemory estimates the memory used by the TSDB head. It iterates over all the
+// in-memory series, so it's expensive for tenants with a large number of series.
+func (u *userTSDB) estimateHeadMemory(ctx context.Context) (headMemoryStats, error) {
+	if u.db == nil {
+		return headMemoryStats{}, errors.New("TSDB is not open")
+	}
+
+	idx, err := u.Head().Index()
+	if err != nil {
+		return headMemoryStats{}, err
+	}
+	defer idx.Close()
+
+	name, value := index.AllPostingsKey()
+	postings, err := idx.Postings(ctx, name, value)
+	if err != nil {
+		return headMemoryStats{}, err
+	}
+
+	var (
+		stats   headMemoryStats
+		builder labels.ScratchBuilder
+		chks    []chunks.Meta
+		count   int
+	)
+
+	for postings.Next() {
+		count++
+		if count%checkContextErrorSeriesCount == 0 {
+			if err := ctx.Err(); err != nil {
+				return headMemoryStats{}, err
+			}
+		}
+
+		if err := idx.Series(postings.At(), &builder, &chks); err != nil {
+			// Series may have been removed from the head in the meanwhile.
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return headMemoryStats{}, err
+		}
+
+		stats.SeriesBytes += estimatedSeriesBytes
+		stats.PostingsBytes += estimatedPostingBytes
+
+		builder.Labels().Range(func(l labels.Label) {
+			stats.LabelsBytes += int64(len(l.Name) + len(l.Value) + 2*estimatedLabelStringOverheadBytes)
+			stats.PostingsBytes += estimatedPostingBytes
+		})
+
+		if len(chks) > 0 {
+			stats.ChunksBytes += estimatedHeadChunkBytes + int64(len(chks)-1)*estimatedMmappedChunkBytes
+		}
+	}
+
+	return stats, postings.Err()
+}
+
+// updateHeadMemoryEstimates updates the estimated memory used by the TSDB head of each tenant,
+// which is used to enforce the per-tenant head memory limit. The memory is estimated only for the
+// tenants with the limit enabled, because the estimate iterates over all their in-memory series.
+func (i *Ingester) updateHeadMemoryEstimates(ctx context.Context) {
+	for _, userID := range i.getTSDBUsers() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		userDB := i.getTSDB(userID)
+		if userDB == nil {
+			continue
+		}
+
+		if i.limits.MaxHeadMemoryBytesPerTenant(userID) <= 0 {
+			// Drop the previous estimate, if any, in case the limit has just been disabled.
+			if userDB.headMemory.Swap(nil) != nil {
+				i.metrics.headMemoryEstimatedBytes.DeleteLabelValues(userID)
+			}
+			continue
+		}
+
+		stats, err := userDB.estimateHeadMemory(ctx)
+		if err != nil {
+			level.Warn(i.logger).Log("msg", "failed to estimate the TSDB head memory", "user", userID, "err", err)
+			continue
+		}
+
+		userDB.headMemory.Store(&stats)
+		i.metrics.headMemoryEstimatedBytes.WithLabelValues(userID).Set(float64(stats.TotalBytes()))
+	}
+}
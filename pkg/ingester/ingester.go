@@ -51,6 +51,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 
+	"github.com/grafana/mimir/pkg/costattribution"
 	"github.com/grafana/mimir/pkg/ingester/activeseries"
 	"github.com/grafana/mimir/pkg/ingester/client"
 	"github.com/grafana/mimir/pkg/mimirpb"
@@ -83,6 +84,9 @@ const (
 	// How frequently update the usage statistics.
 	usageStatsUpdateInterval = usagestats.DefaultReportSendInterval / 10
 
+	// How frequently update the estimated TSDB head memory of the tenants with the head memory limit enabled.
+	headMemoryEstimateUpdateInterval = 30 * time.Second
+
 	// IngesterRingKey is the key under which we store the ingesters ring in the KVStore.
 	IngesterRingKey = "ring"
 
@@ -94,13 +98,16 @@ const (
 	instanceIngestionRateTickInterval = time.Second
 
 	// Reasons for discarding samples
-	reasonSampleOutOfOrder     = "sample-out-of-order"
-	reasonSampleTooOld         = "sample-too-old"
-	reasonSampleTooFarInFuture = "sample-too-far-in-future"
-	reasonNewValueForTimestamp = "new-value-for-timestamp"
-	reasonSampleOutOfBounds    = "sample-out-of-bounds"
-	reasonPerUserSeriesLimit   = "per_user_series_limit"
-	reasonPerMetricSeriesLimit = "per_metric_series_limit"
+	reasonSampleOutOfOrder       = "sample-out-of-order"
+	reasonSampleTooOld           = "sample-too-old"
+	reasonSampleTooFarInFuture   = "sample-too-far-in-future"
+	reasonNewValueForTimestamp   = "new-value-for-timestamp"
+	reasonSampleOutOfBounds      = "sample-out-of-bounds"
+	reasonPerUserSeriesLimit     = "per_user_series_limit"
+	reasonPerMetricSeriesLimit   = "per_metric_series_limit"
+	reasonPerLabelSetSeriesLimit = "per_label_set_series_limit"
+	reasonSampleIntervalTooShort = "sample-interval-too-short"
+	reasonPerUserHeadMemoryLimit = "per_user_head_memory_limit"
 
 	replicationFactorStatsName             = "ingester_replication_factor"
 	ringStoreStatsName                     = "ingester_ring_store"
@@ -183,6 +190,12 @@ type Config struct {
 	ChunksQueryIgnoreCancellation bool `yaml:"chunks_query_ignore_cancellation" category:"experimental"`
 
 	ReturnOnlyGRPCErrors bool `yaml:"return_only_grpc_errors" json:"return_only_grpc_errors" category:"experimental"`
+
+	SeriesDeletionRequestsSyncInterval time.Duration `yaml:"series_deletion_requests_sync_interval" category:"experimental"`
+
+	MetadataSnapshotInterval time.Duration `yaml:"metadata_snapshot_interval" category:"experimental"`
+
+	QueryStreamCacheMaxSizeBytes int `yaml:"query_stream_cache_max_size_bytes" category:"experimental"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -203,13 +216,20 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	f.Int64Var(&cfg.ErrorSampleRate, "ingester.error-sample-rate", 0, "Each error will be logged once in this many times. Use 0 to log all of them.")
 	f.BoolVar(&cfg.ChunksQueryIgnoreCancellation, "ingester.chunks-query-ignore-cancellation", false, "Ignore cancellation when querying chunks.")
 	f.BoolVar(&cfg.ReturnOnlyGRPCErrors, "ingester.return-only-grpc-errors", false, "When enabled only gRPC errors will be returned by the ingester.")
+	f.DurationVar(&cfg.SeriesDeletionRequestsSyncInterval, "ingester.series-deletion-requests-sync-interval", 0, "How frequently the series deletion requests are read from the storage and applied to the in-memory series of each tenant. 0 to disable.")
+	f.DurationVar(&cfg.MetadataSnapshotInterval, "ingester.metadata-snapshot-interval", 0, "How frequently the in-memory metric metadata is written to a snapshot file in the TSDB directory. The snapshot is also written on shutdown, and restored when the ingester starts. 0 to disable.")
+	f.IntVar(&cfg.QueryStreamCacheMaxSizeBytes, "ingester.query-stream-cache-max-size-bytes", 0, "Max size, in bytes, of the cache of the chunks queried from the part of the TSDB which can't receive new samples anymore. The cached chunks of a tenant are invalidated when its TSDB head is compacted or its series are deleted. The cache is not used for tenants with out-of-order ingestion enabled. 0 to disable.")
 }
 
-func (cfg *Config) Validate() error {
+func (cfg *Config) Validate(limits validation.Limits) error {
 	if cfg.ErrorSampleRate < 0 {
 		return fmt.Errorf("error sample rate cannot be a negative number")
 	}
 
+	if limits.StaleSeriesEvictionIdleTimeout > 0 && time.Duration(limits.StaleSeriesEvictionIdleTimeout) > cfg.ActiveSeriesMetrics.IdleTimeout {
+		return fmt.Errorf("-%s (%s) must be lower than or equal to -%s (%s)", validation.StaleSeriesEvictionIdleTimeoutFlag, limits.StaleSeriesEvictionIdleTimeout, activeseries.IdleTimeoutFlag, cfg.ActiveSeriesMetrics.IdleTimeout)
+	}
+
 	return cfg.IngesterRing.Validate()
 }
 
@@ -272,6 +292,9 @@ type Ingester struct {
 	// Maps the per-block series ID with its labels hash.
 	seriesHashCache *hashcache.SeriesHashCache
 
+	// Cache of the chunks queried from the immutable part of the TSDBs. Nil if disabled.
+	queryStreamCache *queryStreamCache
+
 	// Timeout chosen for idle compactions.
 	compactionIdleTimeout time.Duration
 
@@ -350,6 +373,10 @@ func New(cfg Config, limits *validation.Overrides, activeGroupsCleanupService *u
 	i.metrics = newIngesterMetrics(registerer, cfg.ActiveSeriesMetrics.Enabled, i.getInstanceLimits, i.ingestionRate, &i.inflightPushRequests)
 	i.activeGroups = activeGroupsCleanupService
 
+	if cfg.QueryStreamCacheMaxSizeBytes > 0 {
+		i.queryStreamCache = newQueryStreamCache(cfg.QueryStreamCacheMaxSizeBytes, registerer)
+	}
+
 	if registerer != nil {
 		promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
 			Name: "cortex_ingester_oldest_unshipped_block_timestamp_seconds",
@@ -437,6 +464,13 @@ func (i *Ingester) starting(ctx context.Context) error {
 		return errors.Wrap(err, "opening existing TSDBs")
 	}
 
+	if i.cfg.MetadataSnapshotInterval > 0 {
+		// Metadata is best effort, so a failure to restore it should not prevent the ingester from starting.
+		if err := i.restoreMetadataSnapshot(); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to restore metric metadata snapshot", "err", err)
+		}
+	}
+
 	// Important: we want to keep lifecycler running until we ask it to stop, so we need to give it independent context
 	if err := i.lifecycler.StartAsync(context.Background()); err != nil {
 		return errors.Wrap(err, "failed to start lifecycler")
@@ -469,6 +503,16 @@ func (i *Ingester) starting(ctx context.Context) error {
 		servs = append(servs, i.utilizationBasedLimiter)
 	}
 
+	if i.cfg.SeriesDeletionRequestsSyncInterval > 0 {
+		seriesDeletionService := services.NewTimerService(i.cfg.SeriesDeletionRequestsSyncInterval, nil, i.applySeriesDeletionRequests, nil)
+		servs = append(servs, seriesDeletionService)
+	}
+
+	if i.cfg.MetadataSnapshotInterval > 0 {
+		metadataSnapshotService := services.NewTimerService(i.cfg.MetadataSnapshotInterval, nil, i.snapshotMetadata, nil)
+		servs = append(servs, metadataSnapshotService)
+	}
+
 	shutdownMarkerPath := shutdownmarker.GetPath(i.cfg.BlocksStorageConfig.TSDB.Dir)
 	shutdownMarkerFound, err := shutdownmarker.Exists(shutdownMarkerPath)
 	if err != nil {
@@ -480,6 +524,25 @@ func (i *Ingester) starting(ctx context.Context) error {
 		i.setPrepareShutdown()
 	}
 
+	readOnlyMarkerPath := getReadOnlyMarkerPath(i.cfg.BlocksStorageConfig.TSDB.Dir)
+	readOnlyMarkerFound, err := shutdownmarker.Exists(readOnlyMarkerPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to check ingester read-only marker")
+	}
+
+	if readOnlyMarkerFound {
+		level.Info(i.logger).Log("msg", "detected existing read-only marker, switching to read-only mode", "path", readOnlyMarkerPath)
+		if err := i.setReadOnly(ctx, true); err != nil {
+			return errors.Wrap(err, "failed to switch the ingester to read-only mode")
+		}
+	} else if i.lifecycler.GetReadOnlyState() {
+		// The ring still flags the ingester as read-only, but the marker has been removed.
+		level.Info(i.logger).Log("msg", "read-only marker not found, switching out of read-only mode", "path", readOnlyMarkerPath)
+		if err := i.setReadOnly(ctx, false); err != nil {
+			return errors.Wrap(err, "failed to switch the ingester out of read-only mode")
+		}
+	}
+
 	i.subservices, err = services.NewManager(servs...)
 	if err == nil {
 		err = services.StartManagerAndAwaitHealthy(ctx, i.subservices)
@@ -514,6 +577,11 @@ func (i *Ingester) stopping(_ error) error {
 		level.Warn(i.logger).Log("msg", "failed to remove shutdown marker", "path", shutdownMarkerPath, "err", err)
 	}
 
+	// Write the latest metric metadata, so that it's restored when the ingester starts again.
+	if i.cfg.MetadataSnapshotInterval > 0 {
+		_ = i.snapshotMetadata(context.Background())
+	}
+
 	if !i.cfg.BlocksStorageConfig.TSDB.KeepUserTSDBOpenOnShutdown {
 		i.closeAllTSDB()
 	}
@@ -538,6 +606,22 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 		}
 	}()
 
+	// Launch a dedicated goroutine to estimate the TSDB head memory, because it iterates over all
+	// the in-memory series of the tenants and could delay the other updates.
+	go func() {
+		headMemoryEstimateTicker := time.NewTicker(headMemoryEstimateUpdateInterval)
+		defer headMemoryEstimateTicker.Stop()
+
+		for {
+			select {
+			case <-headMemoryEstimateTicker.C:
+				i.updateHeadMemoryEstimates(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	rateUpdateTicker := time.NewTicker(i.cfg.RateUpdatePeriod)
 	defer rateUpdateTicker.Stop()
 
@@ -577,6 +661,7 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 
 		case <-tsdbUpdateTicker.C:
 			i.applyTSDBSettings()
+			i.updateLabelSetSeries()
 
 		case <-activeSeriesTickerChan:
 			i.updateActiveSeries(time.Now())
@@ -597,6 +682,24 @@ func (i *Ingester) replaceMatchers(asm *activeseries.Matchers, userDB *userTSDB,
 	userDB.activeSeries.ReloadMatchers(asm, now)
 }
 
+// updateLabelSetSeries updates the number of in-memory series per label set of the per-label-set limits.
+func (i *Ingester) updateLabelSetSeries() {
+	for _, userID := range i.getTSDBUsers() {
+		userDB := i.getTSDB(userID)
+		if userDB == nil {
+			continue
+		}
+
+		seriesPerLabelSet, removed := userDB.seriesInLabelSet.seriesPerLabelSet()
+		for _, selector := range removed {
+			i.metrics.labelSetSeries.DeleteLabelValues(userID, selector)
+		}
+		for selector, series := range seriesPerLabelSet {
+			i.metrics.labelSetSeries.WithLabelValues(userID, selector).Set(float64(series))
+		}
+	}
+}
+
 func (i *Ingester) updateActiveSeries(now time.Time) {
 	for _, userID := range i.getTSDBUsers() {
 		userDB := i.getTSDB(userID)
@@ -608,6 +711,9 @@ func (i *Ingester) updateActiveSeries(now time.Time) {
 		if newMatchersConfig.String() != userDB.activeSeries.CurrentConfig().String() {
 			i.replaceMatchers(activeseries.NewMatchers(newMatchersConfig), userDB, now)
 		}
+		if !i.isCurrentCostAttribution(userID, userDB.activeSeries.CurrentCostAttribution()) {
+			i.replaceCostAttribution(i.newCostAttributionTracker(userID), userDB, now)
+		}
 		valid := userDB.activeSeries.Purge(now)
 		if !valid {
 			// Active series config has been reloaded, exposing loading metric until MetricsIdleTimeout passes.
@@ -649,6 +755,58 @@ func (i *Ingester) updateActiveSeries(now time.Time) {
 					i.metrics.activeNativeHistogramBucketsCustomTrackersPerUser.DeleteLabelValues(userID, name)
 				}
 			}
+
+			i.updateActiveSeriesPerAttribution(userID, userDB, now)
+		}
+	}
+}
+
+// newCostAttributionTracker returns the cost attribution tracker of the active series of the input user,
+// or nil if the cost attribution is disabled.
+func (i *Ingester) newCostAttributionTracker(userID string) *costattribution.Tracker {
+	label := i.limits.CostAttributionLabel(userID)
+	if label == "" {
+		return nil
+	}
+	return costattribution.NewTracker(label, i.limits.MaxCostAttributionCardinality(userID))
+}
+
+// isCurrentCostAttribution returns true if the input tracker matches the current cost attribution config of the user.
+func (i *Ingester) isCurrentCostAttribution(userID string, cat *costattribution.Tracker) bool {
+	label := i.limits.CostAttributionLabel(userID)
+	if cat == nil {
+		return label == ""
+	}
+	return cat.SameConfig(label, i.limits.MaxCostAttributionCardinality(userID))
+}
+
+func (i *Ingester) replaceCostAttribution(cat *costattribution.Tracker, userDB *userTSDB, now time.Time) {
+	i.metrics.deletePerUserCustomTrackerMetrics(userDB.userID, userDB.activeSeries.CurrentMatcherNames())
+	userDB.activeSeries.ReloadCostAttribution(cat, now)
+}
+
+// updateActiveSeriesPerAttribution updates the active series per cost attribution value of the input user,
+// and stops tracking the attribution values without active series.
+func (i *Ingester) updateActiveSeriesPerAttribution(userID string, userDB *userTSDB, now time.Time) {
+	cat := userDB.activeSeries.CurrentCostAttribution()
+	if cat == nil {
+		return
+	}
+
+	activePerAttribution := userDB.activeSeries.ActiveByAttribution()
+	for attribution := range activePerAttribution {
+		cat.Touch(attribution, now)
+	}
+	for _, attribution := range cat.Purge(now.Add(-i.cfg.ActiveSeriesMetrics.IdleTimeout)) {
+		i.metrics.activeSeriesPerAttribution.DeleteLabelValues(userID, attribution)
+	}
+
+	// The overflow and missing values are never tracked by the tracker, so they're checked explicitly.
+	for _, attribution := range append(cat.Values(), costattribution.OverflowValue, costattribution.MissingValue) {
+		if active := activePerAttribution[attribution]; active > 0 {
+			i.metrics.activeSeriesPerAttribution.WithLabelValues(userID, attribution).Set(float64(active))
+		} else {
+			i.metrics.activeSeriesPerAttribution.DeleteLabelValues(userID, attribution)
 		}
 	}
 }
@@ -701,6 +859,7 @@ func (i *Ingester) updateUsageStats() {
 // applyTSDBSettings goes through all tenants and applies
 // * The current max-exemplars setting. If it changed, tsdb will resize the buffer; if it didn't change tsdb will return quickly.
 // * The current out-of-order time window. If it changes from 0 to >0, then a new Write-Behind-Log gets created for that tenant.
+// * Whether the native histograms, and the out-of-order native histograms, are ingested.
 func (i *Ingester) applyTSDBSettings() {
 	for _, userID := range i.getTSDBUsers() {
 		globalValue := i.limits.MaxGlobalExemplarsPerUser(userID)
@@ -738,6 +897,16 @@ func (i *Ingester) applyTSDBSettings() {
 		} else {
 			db.db.DisableNativeHistograms()
 		}
+		setOOONativeHistogramsIngestion(db.db, i.limits.OOONativeHistogramsIngestionEnabled(userID))
+	}
+}
+
+// setOOONativeHistogramsIngestion enables or disables the ingestion of out-of-order native histograms in the TSDB.
+func setOOONativeHistogramsIngestion(db *tsdb.DB, enabled bool) {
+	if enabled {
+		db.EnableOOONativeHistograms()
+	} else {
+		db.DisableOOONativeHistograms()
 	}
 }
 
@@ -748,17 +917,23 @@ type extendedAppender interface {
 }
 
 type pushStats struct {
-	succeededSamplesCount     int
-	failedSamplesCount        int
-	succeededExemplarsCount   int
-	failedExemplarsCount      int
-	sampleOutOfBoundsCount    int
-	sampleOutOfOrderCount     int
-	sampleTooOldCount         int
-	sampleTooFarInFutureCount int
-	newValueForTimestampCount int
-	perUserSeriesLimitCount   int
-	perMetricSeriesLimitCount int
+	succeededSamplesCount       int
+	failedSamplesCount          int
+	succeededExemplarsCount     int
+	failedExemplarsCount        int
+	sampleOutOfBoundsCount      int
+	sampleOutOfOrderCount       int
+	sampleTooOldCount           int
+	sampleTooFarInFutureCount   int
+	newValueForTimestampCount   int
+	perUserSeriesLimitCount     int
+	perMetricSeriesLimitCount   int
+	perLabelSetSeriesLimitCount int
+	sampleIntervalTooShortCount int
+	perUserHeadMemoryLimitCount int
+
+	// Samples which would have been discarded if the limits weren't in dry-run mode.
+	dryRunSampleIntervalTooShortCount int
 }
 
 // StartPushRequest checks if ingester can start push request, and increments relevant counters.
@@ -885,7 +1060,10 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 
 	minAppendTime, minAppendTimeAvailable := db.Head().AppendableMinValidTime()
 
-	err = i.pushSamplesToAppender(userID, req.Timeseries, app, startAppend, &stats, updateFirstPartial, activeSeries, i.limits.OutOfOrderTimeWindow(userID), minAppendTimeAvailable, minAppendTime)
+	// The sample intervals are tracked only once the appender has been successfully committed.
+	sampleIntervals := newPendingSampleIntervals(db.sampleIntervals)
+
+	err = i.pushSamplesToAppender(userID, req.Timeseries, app, startAppend, &stats, updateFirstPartial, activeSeries, sampleIntervals, i.limits.OutOfOrderTimeWindow(userID), minAppendTimeAvailable, minAppendTime)
 	if err != nil {
 		if err := app.Rollback(); err != nil {
 			level.Warn(i.logger).Log("msg", "failed to rollback appender on error", "user", userID, "err", err)
@@ -909,6 +1087,7 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 	if err := app.Commit(); err != nil {
 		return wrapOrAnnotateWithUser(err, userID)
 	}
+	sampleIntervals.commit()
 
 	commitDuration := time.Since(startCommit)
 	i.metrics.appenderCommitDuration.Observe(commitDuration.Seconds())
@@ -962,6 +1141,18 @@ func (i *Ingester) updateMetricsFromPushStats(userID string, group string, stats
 	if stats.perMetricSeriesLimitCount > 0 {
 		discarded.perMetricSeriesLimit.WithLabelValues(userID, group).Add(float64(stats.perMetricSeriesLimitCount))
 	}
+	if stats.perLabelSetSeriesLimitCount > 0 {
+		discarded.perLabelSetSeriesLimit.WithLabelValues(userID, group).Add(float64(stats.perLabelSetSeriesLimitCount))
+	}
+	if stats.sampleIntervalTooShortCount > 0 {
+		discarded.sampleIntervalTooShort.WithLabelValues(userID, group).Add(float64(stats.sampleIntervalTooShortCount))
+	}
+	if stats.perUserHeadMemoryLimitCount > 0 {
+		discarded.perUserHeadMemoryLimit.WithLabelValues(userID, group).Add(float64(stats.perUserHeadMemoryLimitCount))
+	}
+	if stats.dryRunSampleIntervalTooShortCount > 0 {
+		discarded.dryRunSampleIntervalTooShort.WithLabelValues(userID, group).Add(float64(stats.dryRunSampleIntervalTooShortCount))
+	}
 	if stats.succeededSamplesCount > 0 {
 		i.ingestionRate.Add(int64(stats.succeededSamplesCount))
 
@@ -978,7 +1169,7 @@ func (i *Ingester) updateMetricsFromPushStats(userID string, group string, stats
 // must be of type softError.
 func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.PreallocTimeseries, app extendedAppender, startAppend time.Time,
 	stats *pushStats, updateFirstPartial func(sampler *util_log.Sampler, errFn softErrorFunction), activeSeries *activeseries.ActiveSeries,
-	outOfOrderWindow time.Duration, minAppendTimeAvailable bool, minAppendTime int64) error {
+	sampleIntervals *pendingSampleIntervals, outOfOrderWindow time.Duration, minAppendTimeAvailable bool, minAppendTime int64) error {
 
 	// Return true if handled as soft error, and we can ingest more series.
 	handleAppendError := func(err error, timestamp int64, labels []mimirpb.LabelAdapter) bool {
@@ -1025,6 +1216,13 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 			})
 			return true
 
+		case globalerror.SampleIntervalTooShort:
+			stats.sampleIntervalTooShortCount++
+			updateFirstPartial(i.errorSamplers.sampleIntervalTooShort, func() softError {
+				return newSampleIntervalTooShortError(model.Time(timestamp), labels, i.limits.MinSampleInterval(userID))
+			})
+			return true
+
 		case globalerror.MaxSeriesPerUser:
 			stats.perUserSeriesLimitCount++
 			updateFirstPartial(i.errorSamplers.maxSeriesPerUserLimitExceeded, func() softError {
@@ -1038,6 +1236,22 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 				return newPerMetricSeriesLimitReachedError(i.limiter.limits.MaxGlobalSeriesPerMetric(userID), mimirpb.FromLabelAdaptersToLabelsWithCopy(labels))
 			})
 			return true
+
+		case globalerror.MaxHeadMemoryPerUser:
+			stats.perUserHeadMemoryLimitCount++
+			updateFirstPartial(i.errorSamplers.maxHeadMemoryPerUserLimitExceeded, func() softError {
+				return newPerUserHeadMemoryLimitReachedError(i.limits.MaxHeadMemoryBytesPerTenant(userID))
+			})
+			return true
+		}
+
+		var labelSetErr labelSetSeriesLimitError
+		if errors.As(err, &labelSetErr) {
+			stats.perLabelSetSeriesLimitCount++
+			updateFirstPartial(i.errorSamplers.maxSeriesPerLabelSetLimitExceeded, func() softError {
+				return newPerLabelSetSeriesLimitReachedError(labelSetErr.limit.Selector, labelSetErr.limit.MaxSeries, mimirpb.FromLabelAdaptersToLabelsWithCopy(labels))
+			})
+			return true
 		}
 		return false
 	}
@@ -1046,7 +1260,26 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 	var (
 		nativeHistogramsIngestionEnabled = i.limits.NativeHistogramsIngestionEnabled(userID)
 		maxTimestampMs                   = startAppend.Add(i.limits.CreationGracePeriod(userID)).UnixMilli()
+		minSampleIntervalMs              = i.limits.MinSampleInterval(userID).Milliseconds()
+		limitsDryRun                     = i.limits.LimitsDryRun(userID)
 	)
+	if minSampleIntervalMs <= 0 {
+		sampleIntervals = nil
+	}
+
+	// Return true if the sample must be discarded because it's too close to the previous sample of the same series.
+	isSampleIntervalTooShort := func(ref storage.SeriesRef, timestamp int64, labels []mimirpb.LabelAdapter) bool {
+		if sampleIntervals == nil || ref == 0 || !sampleIntervals.isTooClose(ref, timestamp, minSampleIntervalMs) {
+			return false
+		}
+		if limitsDryRun {
+			stats.dryRunSampleIntervalTooShortCount++
+			logDryRunLimitExceeded(i.logger, i.errorSamplers.sampleIntervalTooShort, userID, newSampleIntervalTooShortError(model.Time(timestamp), labels, i.limits.MinSampleInterval(userID)))
+			return false
+		}
+		handleAppendError(globalerror.SampleIntervalTooShort, timestamp, labels)
+		return true
+	}
 
 	var builder labels.ScratchBuilder
 	var nonCopiedLabels labels.Labels
@@ -1116,10 +1349,17 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 				continue
 			}
 
+			if isSampleIntervalTooShort(ref, s.TimestampMs, ts.Labels) {
+				continue
+			}
+
 			// If the cached reference exists, we try to use it.
 			if ref != 0 {
 				if _, err = app.Append(ref, copiedLabels, s.TimestampMs, s.Value); err == nil {
 					stats.succeededSamplesCount++
+					if sampleIntervals != nil {
+						sampleIntervals.appended(ref, s.TimestampMs)
+					}
 					continue
 				}
 			} else {
@@ -1129,6 +1369,9 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 				// Retain the reference in case there are multiple samples for the series.
 				if ref, err = app.Append(0, copiedLabels, s.TimestampMs, s.Value); err == nil {
 					stats.succeededSamplesCount++
+					if sampleIntervals != nil {
+						sampleIntervals.appended(ref, s.TimestampMs)
+					}
 					continue
 				}
 			}
@@ -1156,6 +1399,10 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 					continue
 				}
 
+				if isSampleIntervalTooShort(ref, h.Timestamp, ts.Labels) {
+					continue
+				}
+
 				if h.IsFloatHistogram() {
 					fh = mimirpb.FromFloatHistogramProtoToFloatHistogram(&h)
 				} else {
@@ -1166,6 +1413,9 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 				if ref != 0 {
 					if _, err = app.AppendHistogram(ref, copiedLabels, h.Timestamp, ih, fh); err == nil {
 						stats.succeededSamplesCount++
+						if sampleIntervals != nil {
+							sampleIntervals.appended(ref, h.Timestamp)
+						}
 						continue
 					}
 				} else {
@@ -1175,6 +1425,9 @@ func (i *Ingester) pushSamplesToAppender(userID string, timeseries []mimirpb.Pre
 					// Retain the reference in case there are multiple samples for the series.
 					if ref, err = app.AppendHistogram(0, copiedLabels, h.Timestamp, ih, fh); err == nil {
 						stats.succeededSamplesCount++
+						if sampleIntervals != nil {
+							sampleIntervals.appended(ref, h.Timestamp)
+						}
 						continue
 					}
 				}
@@ -1810,15 +2063,48 @@ func (i *Ingester) executeSamplesQuery(ctx context.Context, db *userTSDB, from,
 	return numSeries, numSamples, nil
 }
 
-// executeChunksQuery streams metrics from a TSDB. This implements the client.IngesterServer interface
-func (i *Ingester) executeChunksQuery(ctx context.Context, db *userTSDB, from, through int64, matchers []*labels.Matcher, shard *sharding.ShardSelector, stream client.Ingester_QueryStreamServer) (numSeries, numSamples int, _ error) {
-	var q storage.ChunkQuerier
-	var err error
+// createChunkQuerier returns a querier for the chunks of the TSDB in [from, through]. If the query stream cache is
+// enabled, the chunks of the part of the time range which can't receive new samples anymore are cached.
+func (i *Ingester) createChunkQuerier(db *userTSDB, from, through int64) (storage.ChunkQuerier, error) {
 	if i.limits.OutOfOrderTimeWindow(db.userID) > 0 {
-		q, err = db.UnorderedChunkQuerier(from, through)
-	} else {
-		q, err = db.ChunkQuerier(from, through)
+		return db.UnorderedChunkQuerier(from, through)
+	}
+	if i.queryStreamCache == nil || from < 0 {
+		return db.ChunkQuerier(from, through)
+	}
+
+	minValidTime, ok := db.Head().AppendableMinValidTime()
+	if !ok {
+		return db.ChunkQuerier(from, through)
+	}
+
+	// Both the start and the end of the cached part are aligned, so that the same entry is used by
+	// the queries repeated over time, while the head's appendable min valid time moves forward.
+	cachedFrom := from - from%queryStreamCacheAlignmentMs
+	liveFrom := min(minValidTime-minValidTime%queryStreamCacheAlignmentMs, through+1)
+	if liveFrom <= from {
+		return db.ChunkQuerier(from, through)
+	}
+
+	q, err := db.ChunkQuerier(liveFrom, through)
+	if err != nil {
+		return nil, err
 	}
+
+	return &cachingChunkQuerier{
+		ChunkQuerier: q,
+		cache:        i.queryStreamCache,
+		db:           db,
+		cachedFrom:   cachedFrom,
+		from:         from,
+		liveFrom:     liveFrom,
+		through:      through,
+	}, nil
+}
+
+// executeChunksQuery streams metrics from a TSDB. This implements the client.IngesterServer interface
+func (i *Ingester) executeChunksQuery(ctx context.Context, db *userTSDB, from, through int64, matchers []*labels.Matcher, shard *sharding.ShardSelector, stream client.Ingester_QueryStreamServer) (numSeries, numSamples int, _ error) {
+	q, err := i.createChunkQuerier(db, from, through)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -1911,13 +2197,7 @@ func (i *Ingester) executeChunksQuery(ctx context.Context, db *userTSDB, from, t
 }
 
 func (i *Ingester) executeStreamingQuery(ctx context.Context, db *userTSDB, from, through int64, matchers []*labels.Matcher, shard *sharding.ShardSelector, stream client.Ingester_QueryStreamServer, batchSize uint64, spanlog *spanlogger.SpanLogger) (numSeries, numSamples int, _ error) {
-	var q storage.ChunkQuerier
-	var err error
-	if i.limits.OutOfOrderTimeWindow(db.userID) > 0 {
-		q, err = db.UnorderedChunkQuerier(from, through)
-	} else {
-		q, err = db.ChunkQuerier(from, through)
-	}
+	q, err := i.createChunkQuerier(db, from, through)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -2213,15 +2493,21 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 
 	userDB := &userTSDB{
 		userID:              userID,
-		activeSeries:        activeseries.NewActiveSeries(activeseries.NewMatchers(matchersConfig), i.cfg.ActiveSeriesMetrics.IdleTimeout),
+		activeSeries:        activeseries.NewActiveSeries(activeseries.NewMatchers(matchersConfig), i.cfg.ActiveSeriesMetrics.IdleTimeout, i.newCostAttributionTracker(userID)),
 		seriesInMetric:      newMetricCounter(i.limiter, i.cfg.getIgnoreSeriesLimitForMetricNamesMap()),
+		sampleIntervals:     newSampleIntervalTracker(),
 		ingestedAPISamples:  util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		ingestedRuleSamples: util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		instanceLimitsFn:    i.getInstanceLimits,
 		instanceSeriesCount: &i.seriesCount,
 		instanceErrors:      i.metrics.rejected,
+		activeGroups:        i.activeGroups,
+		discarded:           i.metrics.discarded,
+		errorSamplers:       i.errorSamplers,
+		logger:              userLogger,
 		blockMinRetention:   i.cfg.BlocksStorageConfig.TSDB.Retention,
 	}
+	userDB.seriesInLabelSet = newLabelSetCounter(i.limits, userID, userDB.countHeadSeries)
 
 	maxExemplars := i.limiter.convertGlobalToLocalLimit(userID, i.limits.MaxGlobalExemplarsPerUser(userID))
 	oooTW := i.limits.OutOfOrderTimeWindow(userID)
@@ -2262,6 +2548,7 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 		return nil, errors.Wrapf(err, "failed to open TSDB: %s", udir)
 	}
 	db.DisableCompactions() // we will compact on our own schedule
+	setOOONativeHistogramsIngestion(db, i.limits.OOONativeHistogramsIngestionEnabled(userID))
 
 	// Run compaction before using this TSDB. If there is data in head that needs to be put into blocks,
 	// this will actually create the blocks. If there is no data (empty TSDB), this is a no-op, although
@@ -2296,6 +2583,11 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 
 	// Create a new shipper for this database
 	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		var exemplarsToShip storage.ExemplarQueryable
+		if i.cfg.BlocksStorageConfig.TSDB.ExemplarsInBlocksEnabled {
+			exemplarsToShip = userDB
+		}
+
 		userDB.shipper = newShipper(
 			userLogger,
 			i.limits,
@@ -2304,6 +2596,7 @@ func (i *Ingester) createTSDB(userID string, walReplayConcurrency int) (*userTSD
 			udir,
 			bucket.NewUserBucketClient(userID, i.bucket, i.limits),
 			block.ReceiveSource,
+			exemplarsToShip,
 		)
 
 		// Initialise the shipper blocks cache.
@@ -2664,6 +2957,9 @@ func (i *Ingester) compactionLoop(ctx context.Context) error {
 			// Check if any TSDB Head should be compacted to reduce the number of in-memory series.
 			i.compactBlocksToReduceInMemorySeries(ctx, time.Now())
 
+			// Check if any TSDB Head should be compacted to evict the stale series.
+			i.compactBlocksToEvictStaleSeries(ctx, time.Now())
+
 			// Run it at a regular (configured) interval after the first compaction.
 			if !tickerRunOnce {
 				ticker.Reset(i.cfg.BlocksStorageConfig.TSDB.HeadCompactionInterval)
@@ -2709,6 +3005,8 @@ func (i *Ingester) compactBlocks(ctx context.Context, force bool, forcedCompacti
 			return nil
 		}
 
+		headMinTime, numBlocks := h.MinTime(), len(userDB.Blocks())
+
 		var err error
 
 		i.metrics.compactionsTriggered.Inc()
@@ -2738,6 +3036,11 @@ func (i *Ingester) compactBlocks(ctx context.Context, force bool, forcedCompacti
 			level.Debug(i.logger).Log("msg", "TSDB blocks compaction completed successfully", "user", userID, "compactReason", reason)
 		}
 
+		// The cached chunks may have been moved to a block, or removed by the retention.
+		if i.queryStreamCache != nil && (h.MinTime() != headMinTime || len(userDB.Blocks()) != numBlocks) {
+			i.queryStreamCache.invalidateUser(userID)
+		}
+
 		return nil
 	})
 }
@@ -2846,6 +3149,63 @@ func filterUsersToCompactToReduceInMemorySeries(numMemorySeries, earlyCompaction
 	return usersToCompact
 }
 
+// compactBlocksToEvictStaleSeries compacts the TSDB head of the tenants having enough series which haven't received
+// any sample for longer than the configured stale series eviction idle timeout, so that these series are evicted from
+// the memory and stop counting towards the in-memory series limits. The whole TSDB head is compacted up until the idle
+// timeout ago, so each eviction also persists the older samples of the active series into a block: for this reason,
+// the TSDB head of a tenant is compacted to evict the stale series at most once every configured minimum interval.
+func (i *Ingester) compactBlocksToEvictStaleSeries(ctx context.Context, now time.Time) {
+	// Skip if the series activity is not tracked.
+	if !i.cfg.ActiveSeriesMetrics.Enabled {
+		return
+	}
+
+	for _, userID := range i.getTSDBUsers() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		idleTimeout := i.limits.StaleSeriesEvictionIdleTimeout(userID)
+		if idleTimeout <= 0 {
+			continue
+		}
+
+		// The active series tracker forgets the series idle for longer than its own idle timeout,
+		// so it can't tell how many series are stale over a longer idle timeout.
+		if idleTimeout > i.cfg.ActiveSeriesMetrics.IdleTimeout {
+			level.Warn(i.logger).Log("msg", "skipped the stale series eviction because the idle timeout is greater than the active series idle timeout", "user", userID, "idle_timeout", idleTimeout, "active_series_idle_timeout", i.cfg.ActiveSeriesMetrics.IdleTimeout)
+			continue
+		}
+
+		db := i.getTSDB(userID)
+		if db == nil {
+			continue
+		}
+
+		if now.Sub(time.Unix(db.lastStaleSeriesEviction.Load(), 0)) < i.limits.StaleSeriesEvictionMinInterval(userID) {
+			continue
+		}
+
+		userMemorySeries := db.Head().NumSeries()
+		if userMemorySeries == 0 {
+			continue
+		}
+
+		// The series which haven't been updated since "now - idle timeout" are stale, and they're dropped from
+		// the TSDB head if we compact the head up until "now - idle timeout".
+		staleSeries := util_math.Max(0, int64(userMemorySeries)-int64(db.activeSeries.ActiveSince(now.Add(-idleTimeout))))
+		if staleSeries == 0 || staleSeries*100 < int64(i.limits.StaleSeriesEvictionMinPercentage(userID))*int64(userMemorySeries) {
+			continue
+		}
+
+		level.Info(i.logger).Log("msg", "running TSDB head compaction to evict stale series", "user", userID, "in_memory_series", userMemorySeries, "stale_series", staleSeries, "idle_timeout", idleTimeout)
+		forcedCompactionMaxTime := now.Add(-idleTimeout).UnixMilli()
+		db.lastStaleSeriesEviction.Store(now.Unix())
+		i.compactBlocks(ctx, true, forcedCompactionMaxTime, util.NewAllowedTenants([]string{userID}, nil))
+		level.Info(i.logger).Log("msg", "run TSDB head compaction to evict stale series", "user", userID, "before_in_memory_series", userMemorySeries, "after_in_memory_series", db.Head().NumSeries())
+	}
+}
+
 func (i *Ingester) closeAndDeleteIdleUserTSDBs(ctx context.Context) error {
 	for _, userID := range i.getTSDBUsers() {
 		if ctx.Err() != nil {
@@ -2923,6 +3283,9 @@ func (i *Ingester) closeAndDeleteUserTSDBIfIdle(userID string) tsdbCloseCheckRes
 	i.tsdbMetrics.removeRegistryForUser(userID)
 
 	i.deleteUserMetadata(userID)
+	if i.queryStreamCache != nil {
+		i.queryStreamCache.invalidateUser(userID)
+	}
 	i.metrics.deletePerUserMetrics(userID)
 	i.metrics.deletePerUserCustomTrackerMetrics(userID, userDB.activeSeries.CurrentMatcherNames())
 
@@ -3252,7 +3615,7 @@ func (i *Ingester) getOrCreateUserMetadata(userID string) *userMetricsMetadata {
 	// Ensure it was not created between switching locks.
 	userMetadata, ok := i.usersMetadata[userID]
 	if !ok {
-		userMetadata = newMetadataMap(i.limiter, i.metrics, i.errorSamplers, userID)
+		userMetadata = newMetadataMap(i.limiter, i.metrics, i.errorSamplers, i.logger, userID)
 		i.usersMetadata[userID] = userMetadata
 	}
 	return userMetadata
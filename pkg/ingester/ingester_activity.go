@@ -130,6 +130,24 @@ func (i *ActivityTrackerWrapper) LabelValuesCardinality(request *client.LabelVal
 	return i.ing.LabelValuesCardinality(request, server)
 }
 
+func (i *ActivityTrackerWrapper) ActiveSeries(request *client.ActiveSeriesRequest, server client.Ingester_ActiveSeriesServer) error {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(server.Context(), "Ingester/ActiveSeries", request)
+	})
+	defer i.tracker.Delete(ix)
+
+	return i.ing.ActiveSeries(request, server)
+}
+
+func (i *ActivityTrackerWrapper) ActiveSeriesBreakdown(request *client.ActiveSeriesBreakdownRequest, server client.Ingester_ActiveSeriesBreakdownServer) error {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(server.Context(), "Ingester/ActiveSeriesBreakdown", request)
+	})
+	defer i.tracker.Delete(ix)
+
+	return i.ing.ActiveSeriesBreakdown(request, server)
+}
+
 func (i *ActivityTrackerWrapper) FlushHandler(w http.ResponseWriter, r *http.Request) {
 	ix := i.tracker.Insert(func() string {
 		return requestActivity(r.Context(), "Ingester/FlushHandler", nil)
@@ -148,6 +166,15 @@ func (i *ActivityTrackerWrapper) PrepareShutdownHandler(w http.ResponseWriter, r
 	i.ing.PrepareShutdownHandler(w, r)
 }
 
+func (i *ActivityTrackerWrapper) ReadOnlyHandler(w http.ResponseWriter, r *http.Request) {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(r.Context(), "Ingester/ReadOnlyHandler", nil)
+	})
+	defer i.tracker.Delete(ix)
+
+	i.ing.ReadOnlyHandler(w, r)
+}
+
 func (i *ActivityTrackerWrapper) ShutdownHandler(w http.ResponseWriter, r *http.Request) {
 	ix := i.tracker.Insert(func() string {
 		return requestActivity(r.Context(), "Ingester/ShutdownHandler", nil)
@@ -166,6 +193,15 @@ func (i *ActivityTrackerWrapper) UserRegistryHandler(writer http.ResponseWriter,
 	i.ing.UserRegistryHandler(writer, request)
 }
 
+func (i *ActivityTrackerWrapper) CostAttributionHandler(w http.ResponseWriter, r *http.Request) {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(r.Context(), "Ingester/CostAttributionHandler", nil)
+	})
+	defer i.tracker.Delete(ix)
+
+	i.ing.CostAttributionHandler(w, r)
+}
+
 func (i *ActivityTrackerWrapper) TenantsHandler(w http.ResponseWriter, r *http.Request) {
 	ix := i.tracker.Insert(func() string {
 		return requestActivity(r.Context(), "Ingester/TenantsHandler", nil)
@@ -448,6 +448,97 @@ func TestIngester_compactBlocksToReduceInMemorySeries_ShouldFailIngestingSamples
 	assert.NoError(t, pushSeriesToIngester(ctxWithUser, t, ingester, []series{{labels.FromStrings(labels.MetricName, "metric_1"), 3.0, startTime.Add(30 * time.Minute).UnixMilli()}}))
 }
 
+func TestIngester_compactBlocksToEvictStaleSeries(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		ctxWithUser = user.InjectOrgID(ctx, userID)
+	)
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.ActiveSeriesMetrics.Enabled = true
+	cfg.ActiveSeriesMetrics.IdleTimeout = 20 * time.Minute
+	cfg.BlocksStorageConfig.TSDB.HeadCompactionInterval = time.Hour // Do not trigger it during the test, so that we trigger it manually.
+
+	limits := defaultLimitsTestConfig()
+	limits.StaleSeriesEvictionIdleTimeout = model.Duration(15 * time.Minute)
+	limits.StaleSeriesEvictionMinPercentage = 50
+	limits.StaleSeriesEvictionMinInterval = model.Duration(time.Hour)
+
+	ingester, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, ingester))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, ingester))
+	})
+
+	// Wait until it's ACTIVE.
+	test.Poll(t, time.Second, ring.ACTIVE, func() interface{} {
+		return ingester.lifecycler.GetState()
+	})
+
+	startTime, err := time.Parse(time.RFC3339, "2023-06-24T00:00:00Z")
+	require.NoError(t, err)
+	now := startTime.Add(30 * time.Minute)
+
+	userBlocksDir := filepath.Join(ingester.cfg.BlocksStorageConfig.TSDB.Dir, userID)
+
+	// Push 10 series.
+	for seriesID := 0; seriesID < 10; seriesID++ {
+		require.NoError(t, pushSeriesToIngester(ctxWithUser, t, ingester, []series{
+			{labels.FromStrings(labels.MetricName, fmt.Sprintf("metric_%d", seriesID)), 1.0, startTime.UnixMilli()},
+		}))
+	}
+
+	// The series have just been pushed, so none of them is stale.
+	ingester.compactBlocksToEvictStaleSeries(ctx, time.Now())
+	require.Len(t, listBlocksInDir(t, userBlocksDir), 0)
+
+	// Use a trick to track all series we've written so far as stale.
+	db := ingester.getTSDB(userID)
+	db.activeSeries.Purge(time.Now().Add(30 * time.Minute))
+
+	// Push 20 more series.
+	for seriesID := 10; seriesID < 30; seriesID++ {
+		require.NoError(t, pushSeriesToIngester(ctxWithUser, t, ingester, []series{
+			{labels.FromStrings(labels.MetricName, fmt.Sprintf("metric_%d", seriesID)), 2.0, startTime.Add(20 * time.Minute).UnixMilli()},
+		}))
+	}
+
+	// Only 33% of series are stale, so we expect the eviction to not trigger yet.
+	ingester.compactBlocksToEvictStaleSeries(ctx, now)
+	require.Len(t, listBlocksInDir(t, userBlocksDir), 0)
+	require.Equal(t, uint64(30), db.Head().NumSeries())
+
+	// Track all series as stale. Now we expect the eviction to trigger, compacting the TSDB head up until
+	// "now - stale series eviction idle timeout".
+	db.activeSeries.Purge(time.Now().Add(30 * time.Minute))
+
+	ingester.compactBlocksToEvictStaleSeries(ctx, now)
+	require.Len(t, listBlocksInDir(t, userBlocksDir), 1)
+
+	newBlockDir := filepath.Join(userBlocksDir, listBlocksInDir(t, userBlocksDir)[0].String())
+	assert.Equal(t, model.Matrix{
+		{
+			Metric: map[model.LabelName]model.LabelValue{model.MetricNameLabel: "metric_0"},
+			Values: []model.SamplePair{{Timestamp: model.Time(startTime.UnixMilli()), Value: 1.0}},
+		},
+	}, readMetricSamplesFromBlockDir(t, newBlockDir, "metric_0"))
+	assert.Empty(t, readMetricSamplesFromBlockDir(t, newBlockDir, "metric_10"))
+
+	// The series without any sample after "now - stale series eviction idle timeout" have been evicted from the TSDB head.
+	assert.Equal(t, uint64(20), db.Head().NumSeries())
+
+	// The eviction doesn't run again until the min interval has elapsed since the previous one,
+	// even if the idle timeout has.
+	ingester.compactBlocksToEvictStaleSeries(ctx, now.Add(30*time.Minute))
+	require.Len(t, listBlocksInDir(t, userBlocksDir), 1)
+	assert.Equal(t, uint64(20), db.Head().NumSeries())
+
+	ingester.compactBlocksToEvictStaleSeries(ctx, now.Add(time.Hour))
+	require.Len(t, listBlocksInDir(t, userBlocksDir), 2)
+	assert.Equal(t, uint64(0), db.Head().NumSeries())
+}
+
 func TestIngester_compactBlocksToReduceInMemorySeries_Concurrency(t *testing.T) {
 	util_test.VerifyNoLeak(t)
 
@@ -3249,6 +3249,80 @@ func TestIngester_QueryStream(t *testing.T) {
 	})
 }
 
+func TestIngester_QueryStream_Cache(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.QueryStreamCacheMaxSizeBytes = 1024 * 1024
+
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlocksStorage(t, cfg, registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	// Push 3h of samples, so that the first 2h can't receive new samples anymore.
+	ctx := user.InjectOrgID(context.Background(), userID)
+	series := &mimirpb.TimeSeries{Labels: []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test"}}}
+	for ts := int64(0); ts <= 3*time.Hour.Milliseconds(); ts += time.Minute.Milliseconds() {
+		series.Samples = append(series.Samples, mimirpb.Sample{TimestampMs: ts, Value: float64(ts)})
+	}
+
+	expected := model.Matrix{{Metric: model.Metric{labels.MetricName: "test"}}}
+	for _, s := range series.Samples {
+		expected[0].Values = append(expected[0].Values, model.SamplePair{Timestamp: model.Time(s.TimestampMs), Value: model.SampleValue(s.Value)})
+	}
+
+	// Push another series with samples only in the first 5m.
+	oldSeries := &mimirpb.TimeSeries{Labels: []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test_old"}}}
+	for ts := int64(0); ts < 5*time.Minute.Milliseconds(); ts += time.Minute.Milliseconds() {
+		oldSeries.Samples = append(oldSeries.Samples, mimirpb.Sample{TimestampMs: ts, Value: float64(ts)})
+	}
+
+	_, err = ing.Push(ctx, &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{{TimeSeries: series}, {TimeSeries: oldSeries}}})
+	require.NoError(t, err)
+
+	query := func() {
+		res, _, err := runTestQueryTimes(ctx, t, ing, labels.MatchEqual, labels.MetricName, "test", 0, model.Time(3*time.Hour.Milliseconds()))
+		require.NoError(t, err)
+		require.Equal(t, expected, res)
+	}
+
+	// The first query populates the cache, and the second one hits it.
+	query()
+	query()
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_ingester_query_stream_cache_requests_total Total number of queries looked up in the query stream cache.
+		# TYPE cortex_ingester_query_stream_cache_requests_total counter
+		cortex_ingester_query_stream_cache_requests_total 2
+		# HELP cortex_ingester_query_stream_cache_hits_total Total number of queries whose immutable part has been found in the query stream cache.
+		# TYPE cortex_ingester_query_stream_cache_hits_total counter
+		cortex_ingester_query_stream_cache_hits_total 1
+	`), "cortex_ingester_query_stream_cache_requests_total", "cortex_ingester_query_stream_cache_hits_total"))
+
+	// The cached time range starts before the queried one, because it's aligned down, but the cached
+	// result is trimmed to the queried time range: the series whose samples all precede it are not returned.
+	for n := 0; n < 2; n++ {
+		res, _, err := runTestQueryTimes(ctx, t, ing, labels.MatchRegexp, labels.MetricName, "test.*", model.Time(7*time.Minute.Milliseconds()), model.Time(3*time.Hour.Milliseconds()))
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, model.Metric{labels.MetricName: "test"}, res[0].Metric)
+	}
+
+	// The head compaction invalidates the cached entries.
+	ing.compactBlocks(context.Background(), true, math.MaxInt64, nil)
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_ingester_query_stream_cache_size_bytes Estimated size, in bytes, of the query stream cache.
+		# TYPE cortex_ingester_query_stream_cache_size_bytes gauge
+		cortex_ingester_query_stream_cache_size_bytes 0
+	`), "cortex_ingester_query_stream_cache_size_bytes"))
+
+	// The data is queried from the compacted blocks.
+	query()
+}
+
 func TestIngester_QueryStream_TimeseriesWithManySamples(t *testing.T) {
 	// Create ingester.
 	cfg := defaultIngesterTestConfig(t)
@@ -6426,6 +6500,53 @@ func TestIngesterPurgeMetadata(t *testing.T) {
 	}
 }
 
+func TestIngester_MetadataSnapshot(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MetadataSnapshotInterval = time.Hour
+	dataDir := t.TempDir()
+
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), dataDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	test.Poll(t, 100*time.Millisecond, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userIDs, testData := pushTestMetadata(t, ing, 10, 3)
+
+	// The snapshot is written on shutdown.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+
+	// Restart the ingester on the same data directory, and check the metadata has been restored.
+	ing, err = prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), dataDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		resp, err := ing.MetricsMetadata(ctx, client.DefaultMetricsMetadataRequest())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, testData[userID], resp.GetMetadata())
+	}
+
+	// Metadata last seen before the retain period is not restored.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+
+	cfg.MetadataRetainPeriod = time.Nanosecond
+	ing, err = prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), dataDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+		resp, err := ing.MetricsMetadata(ctx, client.DefaultMetricsMetadataRequest())
+		require.NoError(t, err)
+		assert.Empty(t, resp.GetMetadata())
+	}
+}
+
 func TestIngesterMetadataMetrics(t *testing.T) {
 	reg := prometheus.NewPedanticRegistry()
 	cfg := defaultIngesterTestConfig(t)
@@ -6779,6 +6900,463 @@ func TestIngesterMetricLimitExceeded(t *testing.T) {
 	testLimits()
 }
 
+func TestIngesterLabelSetSeriesLimit(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.LabelSetLimits = []*validation.LabelSetLimit{{Selector: `{namespace="payments"}`, MaxSeries: 1}}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	cfg := defaultIngesterTestConfig(t)
+	// Set RF=1 here to ensure the series limit is actually set to the configured value.
+	cfg.IngesterRing.ReplicationFactor = 1
+	cfg.ReturnOnlyGRPCErrors = true
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlockStorageAndOverrides(t, cfg, overrides, "", "", registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userID := "1"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	payments1 := []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testmetric"}, {Name: "namespace", Value: "payments"}, {Name: "pod", Value: "1"}}
+	payments2 := []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testmetric"}, {Name: "namespace", Value: "payments"}, {Name: "pod", Value: "2"}}
+	billing := []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "testmetric"}, {Name: "namespace", Value: "billing"}, {Name: "pod", Value: "1"}}
+
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{payments1}, []mimirpb.Sample{{Value: 1}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+
+	// The second series of the label set is rejected, while the series of other label sets are ingested.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([][]mimirpb.LabelAdapter{payments2, billing}, []mimirpb.Sample{{Value: 2}, {Value: 3}}, nil, nil, mimirpb.API))
+	expectedErr := newErrorWithStatus(wrapOrAnnotateWithUser(newPerLabelSetSeriesLimitReachedError(`{namespace="payments"}`, 1, mimirpb.FromLabelAdaptersToLabels(payments2)), userID), codes.FailedPrecondition)
+	checkErrorWithStatus(t, err, expectedErr)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, mimirpb.FromLabelAdaptersToMetric(billing), res[0].Metric)
+	assert.Equal(t, mimirpb.FromLabelAdaptersToMetric(payments1), res[1].Metric)
+
+	ing.updateLabelSetSeries()
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{group="",reason="per_label_set_series_limit",user="1"} 1
+		# HELP cortex_ingester_label_set_series Number of in-memory series per user matching the selector of a per-label-set limit.
+		# TYPE cortex_ingester_label_set_series gauge
+		cortex_ingester_label_set_series{label_set="{namespace=\"payments\"}",user="1"} 1
+	`), "cortex_discarded_samples_total", "cortex_ingester_label_set_series"))
+}
+
+func TestIngesterCostAttribution(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.CostAttributionLabel = "team"
+	limits.MaxCostAttributionCardinality = 1
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.ActiveSeriesMetrics.Enabled = true
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlockStorageAndOverrides(t, cfg, overrides, "", "", registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userID := "1"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	series := [][]mimirpb.LabelAdapter{
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "team", Value: "a"}, {Name: "pod", Value: "1"}},
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "team", Value: "a"}, {Name: "pod", Value: "2"}},
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "team", Value: "b"}, {Name: "pod", Value: "1"}},
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "pod", Value: "1"}},
+	}
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest(series, []mimirpb.Sample{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+
+	// The max cardinality is 1, so the series of the second team are attributed to the overflow value.
+	ing.updateActiveSeries(time.Now())
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_ingester_attributed_active_series Number of currently active series per user and value of the user's cost attribution label.
+		# TYPE cortex_ingester_attributed_active_series gauge
+		cortex_ingester_attributed_active_series{attribution="a",user="1"} 2
+		cortex_ingester_attributed_active_series{attribution="__overflow__",user="1"} 1
+		cortex_ingester_attributed_active_series{attribution="__missing__",user="1"} 1
+	`), "cortex_ingester_attributed_active_series"))
+
+	rec := httptest.NewRecorder()
+	ing.CostAttributionHandler(rec, httptest.NewRequest("GET", "/ingester/cost_attribution", nil).WithContext(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"label":"team","max_cardinality":1,"active_series":{"a":2,"__overflow__":1,"__missing__":1}}`, rec.Body.String())
+
+	// Requests of tenants without the cost attribution enabled are rejected.
+	rec = httptest.NewRecorder()
+	ing.CostAttributionHandler(rec, httptest.NewRequest("GET", "/ingester/cost_attribution", nil).WithContext(user.InjectOrgID(context.Background(), "unknown")))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestIngesterLimitsDryRun(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.LimitsEnforcementMode = validation.EnforcementModeDryRun
+	limits.MaxGlobalSeriesPerUser = 2
+	limits.MaxGlobalSeriesPerMetric = 1
+	limits.MaxGlobalMetricsWithMetadataPerUser = 1
+
+	cfg := defaultIngesterTestConfig(t)
+	// Set RF=1 here to ensure the series and metadata limits are actually set to the configured values.
+	cfg.IngesterRing.ReplicationFactor = 1
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userID := "1"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	series := [][]mimirpb.LabelAdapter{
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "foo", Value: "bar"}},
+		{{Name: labels.MetricName, Value: "testmetric"}, {Name: "foo", Value: "biz"}},
+		{{Name: labels.MetricName, Value: "othermetric"}},
+	}
+	metadata := []*mimirpb.MetricMetadata{
+		{MetricFamilyName: "testmetric", Help: "a help for testmetric", Type: mimirpb.COUNTER},
+		{MetricFamilyName: "othermetric", Help: "a help for othermetric", Type: mimirpb.COUNTER},
+	}
+
+	// All the series and metadata are ingested, even if they exceed the limits.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest(series, []mimirpb.Sample{{Value: 1}, {Value: 2}, {Value: 3}}, nil, metadata, mimirpb.API))
+	require.NoError(t, err)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, model.MetricNameLabel, ".+")
+	require.NoError(t, err)
+	assert.Len(t, res, 3)
+
+	m, err := ing.MetricsMetadata(ctx, client.DefaultMetricsMetadataRequest())
+	require.NoError(t, err)
+	assert.Len(t, m.Metadata, 2)
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		# HELP cortex_dry_run_discarded_metadata_total The total number of metadata that would have been discarded, if the limits weren't in dry-run mode.
+		# TYPE cortex_dry_run_discarded_metadata_total counter
+		cortex_dry_run_discarded_metadata_total{reason="per_user_metadata_limit",user="1"} 1
+		# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+		# TYPE cortex_dry_run_discarded_samples_total counter
+		cortex_dry_run_discarded_samples_total{group="",reason="per_metric_series_limit",user="1"} 1
+		cortex_dry_run_discarded_samples_total{group="",reason="per_user_series_limit",user="1"} 1
+	`), "cortex_discarded_samples_total", "cortex_dry_run_discarded_samples_total", "cortex_dry_run_discarded_metadata_total"))
+}
+
+func TestIngester_MinSampleInterval(t *testing.T) {
+	tests := map[string]struct {
+		enforcementMode string
+		expectedSamples []model.SamplePair
+		expectedMetrics string
+	}{
+		"enforced": {
+			enforcementMode: validation.EnforcementModeEnforce,
+			expectedSamples: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 11000, Value: 3}, {Timestamp: 21000, Value: 4}},
+			expectedMetrics: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="sample-interval-too-short",user="1"} 2
+				# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+				# TYPE cortex_dry_run_discarded_samples_total counter
+			`,
+		},
+		"dry-run": {
+			enforcementMode: validation.EnforcementModeDryRun,
+			expectedSamples: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 5000, Value: 2}, {Timestamp: 11000, Value: 3}, {Timestamp: 14000, Value: 5}, {Timestamp: 21000, Value: 4}},
+			expectedMetrics: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+				# TYPE cortex_dry_run_discarded_samples_total counter
+				cortex_dry_run_discarded_samples_total{group="",reason="sample-interval-too-short",user="1"} 4
+			`,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			limits := defaultLimitsTestConfig()
+			limits.LimitsEnforcementMode = testData.enforcementMode
+			limits.MinSampleInterval = model.Duration(10 * time.Second)
+
+			registry := prometheus.NewRegistry()
+			ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, "", registry)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+			defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+			test.Poll(t, time.Second, 1, func() interface{} {
+				return ing.lifecycler.HealthyInstancesCount()
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			push := func(samples ...mimirpb.Sample) error {
+				series := []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test"}}
+				req := &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{
+					{TimeSeries: &mimirpb.TimeSeries{Labels: series, Samples: samples}},
+				}}
+				_, err := ing.Push(ctx, req)
+				return err
+			}
+
+			// The first sample of a series is always accepted.
+			require.NoError(t, push(mimirpb.Sample{TimestampMs: 1000, Value: 1}))
+
+			// The second sample is too close to the first one, while the third one is far enough from the first one.
+			err = push(mimirpb.Sample{TimestampMs: 5000, Value: 2}, mimirpb.Sample{TimestampMs: 11000, Value: 3})
+			if testData.enforcementMode == validation.EnforcementModeDryRun {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "err-mimir-sample-interval-too-short")
+			}
+
+			// The interval is computed from the last accepted sample.
+			err = push(mimirpb.Sample{TimestampMs: 14000, Value: 5}, mimirpb.Sample{TimestampMs: 21000, Value: 4})
+			if testData.enforcementMode == validation.EnforcementModeDryRun {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+
+			res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, labels.MetricName, "test")
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, testData.expectedSamples, res[0].Values)
+
+			assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(testData.expectedMetrics), "cortex_discarded_samples_total", "cortex_dry_run_discarded_samples_total"))
+		})
+	}
+}
+
+func TestIngester_ApplySeriesDeletionRequests(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	for _, metric := range []string{"test_1", "test_2"} {
+		req := &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{{TimeSeries: &mimirpb.TimeSeries{
+			Labels:  []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: metric}},
+			Samples: []mimirpb.Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}, {TimestampMs: 3000, Value: 3}},
+		}}}}
+		_, err := ing.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// Write a request deleting a sample of the first series.
+	req, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`test_1`}, 1500, 2500, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(context.Background(), ing.bucket, "1", nil, req))
+
+	require.NoError(t, ing.applySeriesDeletionRequests(context.Background()))
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchRegexp, labels.MetricName, "test_.*")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 3000, Value: 3}}, res[0].Values)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}, {Timestamp: 3000, Value: 3}}, res[1].Values)
+
+	// Already applied requests are skipped.
+	require.NoError(t, ing.applySeriesDeletionRequests(context.Background()))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_ingester_series_deletion_requests_applied_total Total number of series deletion requests applied to the TSDB of a tenant.
+		# TYPE cortex_ingester_series_deletion_requests_applied_total counter
+		cortex_ingester_series_deletion_requests_applied_total 1
+		# HELP cortex_ingester_series_deletion_requests_apply_failures_total Total number of series deletion requests failed to be applied to the TSDB of a tenant.
+		# TYPE cortex_ingester_series_deletion_requests_apply_failures_total counter
+		cortex_ingester_series_deletion_requests_apply_failures_total 0
+	`), "cortex_ingester_series_deletion_requests_applied_total", "cortex_ingester_series_deletion_requests_apply_failures_total"))
+}
+
+func TestUserTSDB_EstimateHeadMemory(t *testing.T) {
+	ing, err := prepareIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	req := &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{
+		{TimeSeries: &mimirpb.TimeSeries{
+			Labels:  []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test_1"}, {Name: "job", Value: "a"}},
+			Samples: []mimirpb.Sample{{TimestampMs: 1000, Value: 1}},
+		}},
+		{TimeSeries: &mimirpb.TimeSeries{
+			Labels:  []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test_22"}, {Name: "job", Value: "b"}},
+			Samples: []mimirpb.Sample{{TimestampMs: 1000, Value: 1}},
+		}},
+	}}
+	_, err = ing.Push(ctx, req)
+	require.NoError(t, err)
+
+	stats, err := ing.getTSDB("1").estimateHeadMemory(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, headMemoryStats{
+		SeriesBytes: 2 * estimatedSeriesBytes,
+		// Label names and values: "__name__", "test_1", "job", "a" and "__name__", "test_22", "job", "b".
+		LabelsBytes:   18 + 19 + 4*2*estimatedLabelStringOverheadBytes,
+		PostingsBytes: 2 * 3 * estimatedPostingBytes,
+		ChunksBytes:   2 * estimatedHeadChunkBytes,
+	}, stats)
+	assert.Equal(t, stats.SeriesBytes+stats.LabelsBytes+stats.PostingsBytes+stats.ChunksBytes, stats.TotalBytes())
+}
+
+func TestIngester_updateHeadMemoryEstimates(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	tenantLimits := map[string]*validation.Limits{}
+	overrides, err := validation.NewOverrides(limits, validation.NewMockTenantLimits(tenantLimits))
+	require.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlockStorageAndOverrides(t, defaultIngesterTestConfig(t), overrides, "", "", registry)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	for _, userID := range []string{"1", "2"} {
+		req := &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{
+			{TimeSeries: &mimirpb.TimeSeries{
+				Labels:  []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test"}},
+				Samples: []mimirpb.Sample{{TimestampMs: 1000, Value: 1}},
+			}},
+		}}
+		_, err = ing.Push(user.InjectOrgID(context.Background(), userID), req)
+		require.NoError(t, err)
+	}
+
+	// The head memory is estimated only for the tenants with the head memory limit enabled.
+	tenantLimitsWithHeadMemory := limits
+	tenantLimitsWithHeadMemory.MaxHeadMemoryBytesPerTenant = 1 << 30
+	tenantLimits["1"] = &tenantLimitsWithHeadMemory
+
+	ing.updateHeadMemoryEstimates(context.Background())
+	require.NotNil(t, ing.getTSDB("1").headMemory.Load())
+	require.Nil(t, ing.getTSDB("2").headMemory.Load())
+
+	metricNames := []string{"cortex_ingester_tsdb_head_estimated_memory_bytes"}
+	expectedBytes := ing.getTSDB("1").headMemoryBytes()
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(fmt.Sprintf(`
+		# HELP cortex_ingester_tsdb_head_estimated_memory_bytes Estimated memory, in bytes, used by the TSDB head per user.
+		# TYPE cortex_ingester_tsdb_head_estimated_memory_bytes gauge
+		cortex_ingester_tsdb_head_estimated_memory_bytes{user="1"} %d
+	`, expectedBytes)), metricNames...))
+
+	// The estimate is dropped once the limit is disabled.
+	delete(tenantLimits, "1")
+	ing.updateHeadMemoryEstimates(context.Background())
+	require.Nil(t, ing.getTSDB("1").headMemory.Load())
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(""), metricNames...))
+}
+
+func TestIngester_MaxHeadMemoryBytesPerTenant(t *testing.T) {
+	tests := map[string]struct {
+		enforcementMode string
+		expectedSeries  int
+		expectedMetrics string
+	}{
+		"enforced": {
+			enforcementMode: validation.EnforcementModeEnforce,
+			expectedSeries:  1,
+			expectedMetrics: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				cortex_discarded_samples_total{group="",reason="per_user_head_memory_limit",user="1"} 1
+				# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+				# TYPE cortex_dry_run_discarded_samples_total counter
+			`,
+		},
+		"dry-run": {
+			enforcementMode: validation.EnforcementModeDryRun,
+			expectedSeries:  2,
+			expectedMetrics: `
+				# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+				# TYPE cortex_discarded_samples_total counter
+				# HELP cortex_dry_run_discarded_samples_total The total number of samples that would have been discarded, if the limits weren't in dry-run mode.
+				# TYPE cortex_dry_run_discarded_samples_total counter
+				cortex_dry_run_discarded_samples_total{group="",reason="per_user_head_memory_limit",user="1"} 1
+			`,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			limits := defaultLimitsTestConfig()
+			limits.LimitsEnforcementMode = testData.enforcementMode
+			limits.MaxHeadMemoryBytesPerTenant = 1
+
+			registry := prometheus.NewRegistry()
+			ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, "", registry)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+			defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+			test.Poll(t, time.Second, 1, func() interface{} {
+				return ing.lifecycler.HealthyInstancesCount()
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			push := func(metric string, ts int64) error {
+				req := &mimirpb.WriteRequest{Source: mimirpb.API, Timeseries: []mimirpb.PreallocTimeseries{
+					{TimeSeries: &mimirpb.TimeSeries{
+						Labels:  []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: metric}},
+						Samples: []mimirpb.Sample{{TimestampMs: ts, Value: 1}},
+					}},
+				}}
+				_, err := ing.Push(ctx, req)
+				return err
+			}
+
+			// The limit is enforced only once the head memory has been estimated.
+			require.NoError(t, push("test_1", 1000))
+			ing.updateHeadMemoryEstimates(context.Background())
+
+			// New series are rejected, while samples for the existing series are still accepted.
+			err = push("test_2", 1000)
+			if testData.enforcementMode == validation.EnforcementModeDryRun {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "err-mimir-max-head-memory-per-user")
+			}
+			require.NoError(t, push("test_1", 2000))
+
+			assert.Equal(t, testData.expectedSeries, int(ing.getTSDB("1").Head().NumSeries()))
+			assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(testData.expectedMetrics), "cortex_discarded_samples_total", "cortex_dry_run_discarded_samples_total"))
+		})
+	}
+}
+
 // Construct a set of realistic-looking samples, all with slightly different label sets
 func benchmarkData(nSeries int) (allLabels [][]mimirpb.LabelAdapter, allSamples []mimirpb.Sample) {
 	// Real example from Kubernetes' embedded cAdvisor metrics, lightly obfuscated.
@@ -7821,6 +8399,114 @@ func Test_Ingester_OutOfOrder(t *testing.T) {
 	assert.Equal(t, int64(30*60), usagestats.GetInt(maxOutOfOrderTimeWindowSecondsStatName).Value())
 }
 
+// Test_Ingester_OutOfOrder_NativeHistograms tests the ingestion of out-of-order native histograms, and their
+// replay from the WBL once the ingester restarts.
+func Test_Ingester_OutOfOrder_NativeHistograms(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.TSDBConfigUpdatePeriod = 1 * time.Second
+
+	l := defaultLimitsTestConfig()
+	l.NativeHistogramsIngestionEnabled = true
+	l.OutOfOrderTimeWindow = model.Duration(30 * time.Minute)
+
+	tenantOverride := new(TenantLimitsMock)
+	tenantOverride.On("ByUserID", "test").Return(nil)
+	override, err := validation.NewOverrides(l, tenantOverride)
+	require.NoError(t, err)
+
+	setOOONativeHistogramsEnabled := func(enabled bool) {
+		tenantLimits := l
+		tenantLimits.OOONativeHistogramsIngestionEnabled = enabled
+		tenantOverride.ExpectedCalls = nil
+		tenantOverride.On("ByUserID", "test").Return(&tenantLimits)
+		// TSDB config is updated every second.
+		<-time.After(1500 * time.Millisecond)
+	}
+
+	dataDir, bucketDir := t.TempDir(), t.TempDir()
+	startIngester := func() *Ingester {
+		i, err := prepareIngesterWithBlockStorageAndOverrides(t, cfg, override, dataDir, bucketDir, nil)
+		require.NoError(t, err)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+
+		// Wait until it's healthy
+		test.Poll(t, 1*time.Second, 1, func() interface{} {
+			return i.lifecycler.HealthyInstancesCount()
+		})
+		return i
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	series := []mimirpb.LabelAdapter{{Name: labels.MetricName, Value: "test_histogram"}}
+
+	pushHistograms := func(i *Ingester, start, end int64, errorContains string) {
+		req := mimirpb.NewWriteRequest(nil, mimirpb.API)
+		for ts := start; ts <= end; ts++ {
+			req.AddHistogramSeries([][]mimirpb.LabelAdapter{series}, []mimirpb.Histogram{
+				mimirpb.FromHistogramToHistogramProto(ts*time.Minute.Milliseconds(), util_test.GenerateTestHistogram(int(ts))),
+			}, nil)
+		}
+
+		_, err := i.Push(ctx, req)
+		if errorContains != "" {
+			require.ErrorContains(t, err, errorContains)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	verifyHistograms := func(i *Ingester, start, end int64) {
+		s := stream{ctx: ctx}
+		require.NoError(t, i.QueryStream(&client.QueryRequest{
+			StartTimestampMs: math.MinInt64,
+			EndTimestampMs:   math.MaxInt64,
+			Matchers:         []*client.LabelMatcher{{Type: client.EQUAL, Name: model.MetricNameLabel, Value: "test_histogram"}},
+		}, &s))
+
+		res, err := client.StreamsToMatrix(model.Earliest, model.Latest, s.responses)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		var expected, actual []model.Time
+		for ts := start; ts <= end; ts++ {
+			expected = append(expected, model.Time(ts*time.Minute.Milliseconds()))
+		}
+		for _, h := range res[0].Histograms {
+			actual = append(actual, h.Timestamp)
+		}
+		assert.Equal(t, expected, actual)
+	}
+
+	i := startIngester()
+	t.Cleanup(func() { _ = services.StopAndAwaitTerminated(context.Background(), i) })
+
+	pushHistograms(i, 100, 100, "")
+
+	// Out-of-order native histograms aren't enabled, so they're rejected even if the out-of-order time window is set.
+	pushHistograms(i, 90, 99, "out-of-order samples are not allowed")
+	verifyHistograms(i, 100, 100)
+
+	// Once enabled, the out-of-order native histograms within the time window are ingested.
+	setOOONativeHistogramsEnabled(true)
+	pushHistograms(i, 90, 99, "")
+	verifyHistograms(i, 90, 100)
+
+	pushHistograms(i, 60, 69, "this sample is beyond the out-of-order time window")
+	verifyHistograms(i, 90, 100)
+
+	// The out-of-order native histograms are replayed from the WBL when the ingester restarts.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), i))
+	i = startIngester()
+	verifyHistograms(i, 90, 100)
+
+	// The out-of-order native histograms are compacted into blocks, and merged with the in-order ones at query time.
+	pushHistograms(i, 101, 110, "")
+	pushHistograms(i, 80, 89, "")
+	i.compactBlocks(context.Background(), true, math.MaxInt64, nil)
+	require.Equal(t, uint64(0), i.getTSDB("test").Head().NumSeries())
+	verifyHistograms(i, 80, 110)
+}
+
 // Test_Ingester_OutOfOrder_CompactHead tests that the OOO head is compacted
 // when the compaction is forced or when the TSDB is idle.
 func Test_Ingester_OutOfOrder_CompactHead(t *testing.T) {
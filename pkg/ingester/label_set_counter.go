@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// labelSetCounter tracks the number of in-memory series of a tenant matching the selector of each per-label-set limit.
+// The count of a selector is initialized from the TSDB head the first time it's needed, so that the limits can be
+// changed at runtime.
+type labelSetCounter struct {
+	limits  *validation.Overrides
+	userID  string
+	countFn func(matchers []*labels.Matcher) (int, error)
+
+	mtx    sync.Mutex
+	series map[string]int // Keyed by selector.
+}
+
+// newLabelSetCounter returns a labelSetCounter counting the series of userID. countFn is used to count
+// the in-memory series matching a selector when it's tracked for the first time.
+func newLabelSetCounter(limits *validation.Overrides, userID string, countFn func(matchers []*labels.Matcher) (int, error)) *labelSetCounter {
+	return &labelSetCounter{
+		limits:  limits,
+		userID:  userID,
+		countFn: countFn,
+		series:  map[string]int{},
+	}
+}
+
+// exceededLimit returns the first per-label-set limit which would be exceeded by adding the series with the input
+// labels, or nil if the series can be added.
+func (c *labelSetCounter) exceededLimit(limiter *Limiter, metric labels.Labels) *validation.LabelSetLimit {
+	limits := c.limits.LabelSetLimits(c.userID)
+	if len(limits) == 0 {
+		return nil
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, limit := range limits {
+		if limit.MaxSeries <= 0 || !limit.Matches(metric) {
+			continue
+		}
+		count, ok := c.countLocked(limit)
+		if ok && !limiter.IsWithinMaxSeriesPerLabelSet(c.userID, limit, count) {
+			return limit
+		}
+	}
+	return nil
+}
+
+// increaseSeries increases the series count of the tracked selectors matching the input labels.
+func (c *labelSetCounter) increaseSeries(metric labels.Labels) {
+	c.updateSeries(metric, 1)
+}
+
+// decreaseSeries decreases the series count of the tracked selectors matching the input labels.
+func (c *labelSetCounter) decreaseSeries(metric labels.Labels) {
+	c.updateSeries(metric, -1)
+}
+
+func (c *labelSetCounter) updateSeries(metric labels.Labels, delta int) {
+	limits := c.limits.LabelSetLimits(c.userID)
+	if len(limits) == 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, limit := range limits {
+		// Selectors which aren't tracked yet are counted from the TSDB head when needed.
+		if _, ok := c.series[limit.Selector]; ok && limit.Matches(metric) {
+			c.series[limit.Selector] += delta
+		}
+	}
+}
+
+// seriesPerLabelSet returns the number of series per selector of the currently configured per-label-set limits.
+// It stops tracking the selectors which are no longer configured, and returns them as removed.
+func (c *labelSetCounter) seriesPerLabelSet() (series map[string]int, removed []string) {
+	limits := c.limits.LabelSetLimits(c.userID)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	series = make(map[string]int, len(limits))
+	for _, limit := range limits {
+		if count, ok := c.countLocked(limit); ok {
+			series[limit.Selector] = count
+		}
+	}
+	for selector := range c.series {
+		if _, ok := series[selector]; !ok {
+			delete(c.series, selector)
+			removed = append(removed, selector)
+		}
+	}
+	return series, removed
+}
+
+// countLocked returns the number of series matching the selector of limit, initializing it from the TSDB head
+// if not tracked yet. It returns false if the count can't be initialized. Must be called with mtx held.
+func (c *labelSetCounter) countLocked(limit *validation.LabelSetLimit) (int, bool) {
+	if count, ok := c.series[limit.Selector]; ok {
+		return count, true
+	}
+
+	matchers, err := limit.Matchers()
+	if err != nil {
+		return 0, false
+	}
+	count, err := c.countFn(matchers)
+	if err != nil {
+		return 0, false
+	}
+	c.series[limit.Selector] = count
+	return count, true
+}
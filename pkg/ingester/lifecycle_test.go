@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +21,8 @@ import (
 	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/test"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -130,6 +133,83 @@ func TestIngester_ShutdownHandler(t *testing.T) {
 	}
 }
 
+func TestIngester_ReadOnlyHandler(t *testing.T) {
+	config := defaultIngesterTestConfig(t)
+	limits := defaultLimitsTestConfig()
+	config.IngesterRing.UnregisterOnShutdown = false
+	dataDir := t.TempDir()
+
+	startIngester := func(t *testing.T) (*Ingester, *prometheus.Registry) {
+		reg := prometheus.NewPedanticRegistry()
+		ing, err := prepareIngesterWithBlocksStorageAndLimits(t, config, limits, dataDir, reg)
+		require.NoError(t, err)
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+		return ing, reg
+	}
+
+	callHandler := func(t *testing.T, ing *Ingester, method string, expectedCode int) string {
+		recorder := httptest.NewRecorder()
+		ing.ReadOnlyHandler(recorder, httptest.NewRequest(method, "/ingester/read-only", nil))
+		require.Equal(t, expectedCode, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	readOnlyRequestedMetric := func(value int) string {
+		return fmt.Sprintf(`
+			# HELP cortex_ingester_read_only_requested If the ingester has been requested to switch to read-only mode via endpoint or marker file.
+			# TYPE cortex_ingester_read_only_requested gauge
+			cortex_ingester_read_only_requested %d
+		`, value)
+	}
+
+	ing, reg := startIngester(t)
+	test.Poll(t, time.Second, ring.ACTIVE, func() interface{} {
+		return instanceState(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey)
+	})
+	require.Equal(t, "unset\n", callHandler(t, ing, http.MethodGet, http.StatusOK))
+
+	// Switch the ingester to read-only mode. The ingester keeps its state in the ring.
+	callHandler(t, ing, http.MethodPost, http.StatusNoContent)
+	require.Equal(t, "set\n", callHandler(t, ing, http.MethodGet, http.StatusOK))
+	test.Poll(t, time.Second, true, func() interface{} {
+		return instanceReadOnly(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey)
+	})
+	require.Equal(t, ring.ACTIVE, instanceState(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey))
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(readOnlyRequestedMetric(1)), "cortex_ingester_read_only_requested"))
+
+	// The read-only mode should be re-applied when the ingester restarts.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	ing, reg = startIngester(t)
+	require.Equal(t, "set\n", callHandler(t, ing, http.MethodGet, http.StatusOK))
+	require.True(t, instanceReadOnly(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey))
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(readOnlyRequestedMetric(1)), "cortex_ingester_read_only_requested"))
+
+	// Disabling the read-only mode takes effect right away.
+	callHandler(t, ing, http.MethodDelete, http.StatusNoContent)
+	require.Equal(t, "unset\n", callHandler(t, ing, http.MethodGet, http.StatusOK))
+	test.Poll(t, time.Second, false, func() interface{} {
+		return instanceReadOnly(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey)
+	})
+	require.Equal(t, ring.ACTIVE, instanceState(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey))
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(readOnlyRequestedMetric(0)), "cortex_ingester_read_only_requested"))
+
+	// The read-only mode can be enabled again.
+	callHandler(t, ing, http.MethodPost, http.StatusNoContent)
+	test.Poll(t, time.Second, true, func() interface{} {
+		return instanceReadOnly(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey)
+	})
+	callHandler(t, ing, http.MethodDelete, http.StatusNoContent)
+
+	// The ingester should not be read-only after a restart once the read-only mode has been disabled.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	ing, _ = startIngester(t)
+	require.False(t, instanceReadOnly(config.IngesterRing.KVStore.Mock, "localhost", IngesterRingKey))
+
+	// If the ingester isn't "running", requests to the read-only endpoint should fail.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	callHandler(t, ing, http.MethodPost, http.StatusServiceUnavailable)
+}
+
 // numTokens determines the number of tokens owned by the specified
 // address
 func numTokens(c kv.Client, name, ringKey string) int {
@@ -142,3 +222,24 @@ func numTokens(c kv.Client, name, ringKey string) int {
 	rd := ringDesc.(*ring.Desc)
 	return len(rd.Ingesters[name].Tokens)
 }
+
+// instanceReadOnly returns whether the specified instance is flagged as read-only in the ring.
+func instanceReadOnly(c kv.Client, name, ringKey string) bool {
+	ringDesc, err := c.Get(context.Background(), ringKey)
+	if ringDesc == nil || err != nil {
+		return false
+	}
+	return ringDesc.(*ring.Desc).Ingesters[name].ReadOnly
+}
+
+// instanceState returns the state of the specified instance in the ring.
+func instanceState(c kv.Client, name, ringKey string) ring.InstanceState {
+	ringDesc, err := c.Get(context.Background(), ringKey)
+	// The ringDesc may be null if the lifecycler hasn't stored the ring
+	// to the KVStore yet.
+	if ringDesc == nil || err != nil {
+		return ring.PENDING
+	}
+	rd := ringDesc.(*ring.Desc)
+	return rd.Ingesters[name].State
+}
@@ -8,7 +8,11 @@ package ingester
 import (
 	"math"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
 	"github.com/grafana/mimir/pkg/util"
+	util_log "github.com/grafana/mimir/pkg/util/log"
 	util_math "github.com/grafana/mimir/pkg/util/math"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
@@ -66,6 +70,13 @@ func (l *Limiter) IsWithinMaxSeriesPerUser(userID string, series int) bool {
 	return series < actualLimit
 }
 
+// IsWithinMaxSeriesPerLabelSet returns true if the series limit of the label set has not been reached compared to the
+// current number of series matching its selector in input; otherwise returns false.
+func (l *Limiter) IsWithinMaxSeriesPerLabelSet(userID string, limit *validation.LabelSetLimit, series int) bool {
+	actualLimit := l.convertGlobalToLocalLimitOrUnlimited(userID, func(string) int { return limit.MaxSeries })
+	return series < actualLimit
+}
+
 // IsWithinMaxMetricsWithMetadataPerUser returns true if limit has not been reached compared to the current
 // number of metrics with metadata in input; otherwise returns false.
 func (l *Limiter) IsWithinMaxMetricsWithMetadataPerUser(userID string, metrics int) bool {
@@ -149,3 +160,11 @@ func (l *Limiter) getZonesCount() int {
 	}
 	return 1
 }
+
+// logDryRunLimitExceeded logs err, caused by data exceeding a per-tenant limit in dry-run mode, if sampled.
+func logDryRunLimitExceeded(logger log.Logger, sampler *util_log.Sampler, userID string, err error) {
+	if sampler != nil && !sampler.Sample() {
+		return
+	}
+	level.Warn(logger).Log("msg", "limit exceeded in dry-run mode, the data has been ingested", "user", userID, "err", sampler.WrapError(err))
+}
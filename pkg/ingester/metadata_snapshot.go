@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/atomicfs"
+)
+
+const metadataSnapshotFilename = "metadata-snapshot.json"
+
+// getMetadataSnapshotPath returns the absolute path of the metric metadata snapshot file.
+func getMetadataSnapshotPath(dirPath string) string {
+	return filepath.Join(dirPath, metadataSnapshotFilename)
+}
+
+// metadataSnapshot is the on-disk representation of the metric metadata held by the ingester.
+type metadataSnapshot struct {
+	Tenants map[string][]metadataSnapshotEntry `json:"tenants"`
+}
+
+type metadataSnapshotEntry struct {
+	Type             mimirpb.MetricMetadata_MetricType `json:"type"`
+	MetricFamilyName string                            `json:"metric_family_name"`
+	Help             string                            `json:"help,omitempty"`
+	Unit             string                            `json:"unit,omitempty"`
+	// LastSeen is the timestamp, in milliseconds, of the last time the metadata was pushed to the ingester.
+	LastSeen int64 `json:"last_seen"`
+}
+
+// snapshotMetadata periodically writes the metric metadata snapshot. It never returns an error,
+// otherwise the service would stop.
+func (i *Ingester) snapshotMetadata(_ context.Context) error {
+	if err := i.writeMetadataSnapshot(); err != nil {
+		i.metrics.metadataSnapshotFailures.Inc()
+		level.Warn(i.logger).Log("msg", "failed to write metric metadata snapshot", "err", err)
+	}
+	return nil
+}
+
+// writeMetadataSnapshot writes the metric metadata of all tenants to a file in the TSDB directory,
+// so that it can be restored when the ingester restarts.
+func (i *Ingester) writeMetadataSnapshot() error {
+	snapshot := metadataSnapshot{Tenants: map[string][]metadataSnapshotEntry{}}
+
+	for _, userID := range i.getUsersWithMetadata() {
+		userMetadata := i.getUserMetadata(userID)
+		if userMetadata == nil {
+			continue
+		}
+
+		lastSeen := userMetadata.lastSeen()
+		if len(lastSeen) == 0 {
+			continue
+		}
+
+		entries := make([]metadataSnapshotEntry, 0, len(lastSeen))
+		for m, t := range lastSeen {
+			entries = append(entries, metadataSnapshotEntry{
+				Type:             m.Type,
+				MetricFamilyName: m.MetricFamilyName,
+				Help:             m.Help,
+				Unit:             m.Unit,
+				LastSeen:         t.UnixMilli(),
+			})
+		}
+		snapshot.Tenants[userID] = entries
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.Wrap(err, "marshal metric metadata snapshot")
+	}
+
+	return atomicfs.CreateFile(getMetadataSnapshotPath(i.cfg.BlocksStorageConfig.TSDB.Dir), bytes.NewReader(data))
+}
+
+// restoreMetadataSnapshot loads the metric metadata from the snapshot file, if any. The metadata which
+// was last seen before the retain period is skipped, as it would be purged anyway.
+func (i *Ingester) restoreMetadataSnapshot() error {
+	snapshotPath := getMetadataSnapshotPath(i.cfg.BlocksStorageConfig.TSDB.Dir)
+
+	data, err := os.ReadFile(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "read metric metadata snapshot")
+	}
+
+	var snapshot metadataSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return errors.Wrap(err, "unmarshal metric metadata snapshot")
+	}
+
+	deadline := time.Now().Add(-i.cfg.MetadataRetainPeriod)
+	restored := 0
+
+	for userID, entries := range snapshot.Tenants {
+		var userMetadata *userMetricsMetadata
+
+		for _, e := range entries {
+			lastSeen := time.UnixMilli(e.LastSeen)
+			if lastSeen.Before(deadline) {
+				continue
+			}
+
+			if userMetadata == nil {
+				userMetadata = i.getOrCreateUserMetadata(userID)
+			}
+
+			m := &mimirpb.MetricMetadata{Type: e.Type, MetricFamilyName: e.MetricFamilyName, Help: e.Help, Unit: e.Unit}
+			if err := userMetadata.addAt(m.MetricFamilyName, m, lastSeen); err != nil {
+				// The limits may have been lowered in the meanwhile.
+				continue
+			}
+			restored++
+		}
+	}
+
+	level.Info(i.logger).Log("msg", "restored metric metadata snapshot", "path", snapshotPath, "metadata", restored)
+	return nil
+}
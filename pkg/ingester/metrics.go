@@ -43,6 +43,7 @@ type ingesterMetrics struct {
 	activeSeriesCustomTrackersPerUserNativeHistograms *prometheus.GaugeVec
 	activeNativeHistogramBucketsPerUser               *prometheus.GaugeVec
 	activeNativeHistogramBucketsCustomTrackersPerUser *prometheus.GaugeVec
+	activeSeriesPerAttribution                        *prometheus.GaugeVec
 
 	// Global limit metrics
 	maxUsersGauge           prometheus.GaugeFunc
@@ -66,13 +67,32 @@ type ingesterMetrics struct {
 	discarded *discardedMetrics
 	rejected  *prometheus.CounterVec
 
+	// Per-label-set limits usage.
+	labelSetSeries *prometheus.GaugeVec
+
+	// Estimated memory used by the TSDB head per user.
+	headMemoryEstimatedBytes *prometheus.GaugeVec
+
 	// Discarded metadata
 	discardedMetadataPerUserMetadataLimit   *prometheus.CounterVec
 	discardedMetadataPerMetricMetadataLimit *prometheus.CounterVec
 
+	// Metadata which would have been discarded if the limits weren't in dry-run mode.
+	dryRunDiscardedMetadataPerUserMetadataLimit   *prometheus.CounterVec
+	dryRunDiscardedMetadataPerMetricMetadataLimit *prometheus.CounterVec
+
 	// Shutdown marker for ingester scale down
 	shutdownMarker prometheus.Gauge
 
+	// Read-only marker for ingester scale down
+	readOnlyMarker prometheus.Gauge
+
+	// Series deletion requests applied to the TSDBs.
+	seriesDeletionRequestsApplied       prometheus.Counter
+	seriesDeletionRequestsApplyFailures prometheus.Counter
+
+	metadataSnapshotFailures prometheus.Counter
+
 	// Count number of requests rejected due to utilization based limiting.
 	utilizationLimitedRequests *prometheus.CounterVec
 }
@@ -294,6 +314,12 @@ func newIngesterMetrics(
 			Help: "Number of currently active native histogram buckets matching a pre-configured label matchers per user.",
 		}, []string{"user", "name"}),
 
+		// Not registered automatically, but only if activeSeriesEnabled is true.
+		activeSeriesPerAttribution: promauto.With(activeSeriesReg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_attributed_active_series",
+			Help: "Number of currently active series per user and value of the user's cost attribution label.",
+		}, []string{"user", "attribution"}),
+
 		compactionsTriggered: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_ingester_tsdb_compactions_triggered_total",
 			Help: "Total number of triggered compactions.",
@@ -322,6 +348,14 @@ func newIngesterMetrics(
 		}),
 
 		discarded: newDiscardedMetrics(r),
+		labelSetSeries: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_label_set_series",
+			Help: "Number of in-memory series per user matching the selector of a per-label-set limit.",
+		}, []string{"user", "label_set"}),
+		headMemoryEstimatedBytes: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_ingester_tsdb_head_estimated_memory_bytes",
+			Help: "Estimated memory, in bytes, used by the TSDB head per user.",
+		}, []string{"user"}),
 		rejected: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
 			Name: "cortex_ingester_instance_rejected_requests_total",
 			Help: "Requests rejected for hitting per-instance limits",
@@ -330,10 +364,32 @@ func newIngesterMetrics(
 		discardedMetadataPerUserMetadataLimit:   validation.DiscardedMetadataCounter(r, perUserMetadataLimit),
 		discardedMetadataPerMetricMetadataLimit: validation.DiscardedMetadataCounter(r, perMetricMetadataLimit),
 
+		dryRunDiscardedMetadataPerUserMetadataLimit:   validation.DryRunDiscardedMetadataCounter(r, perUserMetadataLimit),
+		dryRunDiscardedMetadataPerMetricMetadataLimit: validation.DryRunDiscardedMetadataCounter(r, perMetricMetadataLimit),
+
 		shutdownMarker: promauto.With(r).NewGauge(prometheus.GaugeOpts{
 			Name: "cortex_ingester_prepare_shutdown_requested",
 			Help: "If the ingester has been requested to prepare for shutdown via endpoint or marker file.",
 		}),
+
+		readOnlyMarker: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_ingester_read_only_requested",
+			Help: "If the ingester has been requested to switch to read-only mode via endpoint or marker file.",
+		}),
+
+		seriesDeletionRequestsApplied: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_series_deletion_requests_applied_total",
+			Help: "Total number of series deletion requests applied to the TSDB of a tenant.",
+		}),
+		seriesDeletionRequestsApplyFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_series_deletion_requests_apply_failures_total",
+			Help: "Total number of series deletion requests failed to be applied to the TSDB of a tenant.",
+		}),
+
+		metadataSnapshotFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_metadata_snapshot_failures_total",
+			Help: "Total number of failures writing the metric metadata snapshot to disk.",
+		}),
 	}
 
 	// Initialize expected rejected request labels
@@ -353,9 +409,13 @@ func (m *ingesterMetrics) deletePerUserMetrics(userID string) {
 
 	filter := prometheus.Labels{"user": userID}
 	m.discarded.DeletePartialMatch(filter)
+	m.labelSetSeries.DeletePartialMatch(filter)
+	m.headMemoryEstimatedBytes.DeleteLabelValues(userID)
 
 	m.discardedMetadataPerUserMetadataLimit.DeleteLabelValues(userID)
 	m.discardedMetadataPerMetricMetadataLimit.DeleteLabelValues(userID)
+	m.dryRunDiscardedMetadataPerUserMetadataLimit.DeleteLabelValues(userID)
+	m.dryRunDiscardedMetadataPerMetricMetadataLimit.DeleteLabelValues(userID)
 }
 
 func (m *ingesterMetrics) deletePerGroupMetricsForUser(userID, group string) {
@@ -372,27 +432,48 @@ func (m *ingesterMetrics) deletePerUserCustomTrackerMetrics(userID string, custo
 		m.activeSeriesCustomTrackersPerUserNativeHistograms.DeleteLabelValues(userID, name)
 		m.activeNativeHistogramBucketsCustomTrackersPerUser.DeleteLabelValues(userID, name)
 	}
+	m.activeSeriesPerAttribution.DeletePartialMatch(prometheus.Labels{"user": userID})
 }
 
 type discardedMetrics struct {
-	sampleOutOfBounds    *prometheus.CounterVec
-	sampleOutOfOrder     *prometheus.CounterVec
-	sampleTooOld         *prometheus.CounterVec
-	sampleTooFarInFuture *prometheus.CounterVec
-	newValueForTimestamp *prometheus.CounterVec
-	perUserSeriesLimit   *prometheus.CounterVec
-	perMetricSeriesLimit *prometheus.CounterVec
+	sampleOutOfBounds      *prometheus.CounterVec
+	sampleOutOfOrder       *prometheus.CounterVec
+	sampleTooOld           *prometheus.CounterVec
+	sampleTooFarInFuture   *prometheus.CounterVec
+	newValueForTimestamp   *prometheus.CounterVec
+	perUserSeriesLimit     *prometheus.CounterVec
+	perMetricSeriesLimit   *prometheus.CounterVec
+	perLabelSetSeriesLimit *prometheus.CounterVec
+	sampleIntervalTooShort *prometheus.CounterVec
+	perUserHeadMemoryLimit *prometheus.CounterVec
+
+	// Series which would have been discarded if the limits weren't in dry-run mode. Only the sample creating the
+	// series is counted, because the following samples are appended to the created series.
+	dryRunPerUserSeriesLimit     *prometheus.CounterVec
+	dryRunPerMetricSeriesLimit   *prometheus.CounterVec
+	dryRunPerLabelSetSeriesLimit *prometheus.CounterVec
+	dryRunSampleIntervalTooShort *prometheus.CounterVec
+	dryRunPerUserHeadMemoryLimit *prometheus.CounterVec
 }
 
 func newDiscardedMetrics(r prometheus.Registerer) *discardedMetrics {
 	return &discardedMetrics{
-		sampleOutOfBounds:    validation.DiscardedSamplesCounter(r, reasonSampleOutOfBounds),
-		sampleOutOfOrder:     validation.DiscardedSamplesCounter(r, reasonSampleOutOfOrder),
-		sampleTooOld:         validation.DiscardedSamplesCounter(r, reasonSampleTooOld),
-		sampleTooFarInFuture: validation.DiscardedSamplesCounter(r, reasonSampleTooFarInFuture),
-		newValueForTimestamp: validation.DiscardedSamplesCounter(r, reasonNewValueForTimestamp),
-		perUserSeriesLimit:   validation.DiscardedSamplesCounter(r, reasonPerUserSeriesLimit),
-		perMetricSeriesLimit: validation.DiscardedSamplesCounter(r, reasonPerMetricSeriesLimit),
+		sampleOutOfBounds:      validation.DiscardedSamplesCounter(r, reasonSampleOutOfBounds),
+		sampleOutOfOrder:       validation.DiscardedSamplesCounter(r, reasonSampleOutOfOrder),
+		sampleTooOld:           validation.DiscardedSamplesCounter(r, reasonSampleTooOld),
+		sampleTooFarInFuture:   validation.DiscardedSamplesCounter(r, reasonSampleTooFarInFuture),
+		newValueForTimestamp:   validation.DiscardedSamplesCounter(r, reasonNewValueForTimestamp),
+		perUserSeriesLimit:     validation.DiscardedSamplesCounter(r, reasonPerUserSeriesLimit),
+		perMetricSeriesLimit:   validation.DiscardedSamplesCounter(r, reasonPerMetricSeriesLimit),
+		perLabelSetSeriesLimit: validation.DiscardedSamplesCounter(r, reasonPerLabelSetSeriesLimit),
+		sampleIntervalTooShort: validation.DiscardedSamplesCounter(r, reasonSampleIntervalTooShort),
+		perUserHeadMemoryLimit: validation.DiscardedSamplesCounter(r, reasonPerUserHeadMemoryLimit),
+
+		dryRunPerUserSeriesLimit:     validation.DryRunDiscardedSamplesCounter(r, reasonPerUserSeriesLimit),
+		dryRunPerMetricSeriesLimit:   validation.DryRunDiscardedSamplesCounter(r, reasonPerMetricSeriesLimit),
+		dryRunPerLabelSetSeriesLimit: validation.DryRunDiscardedSamplesCounter(r, reasonPerLabelSetSeriesLimit),
+		dryRunSampleIntervalTooShort: validation.DryRunDiscardedSamplesCounter(r, reasonSampleIntervalTooShort),
+		dryRunPerUserHeadMemoryLimit: validation.DryRunDiscardedSamplesCounter(r, reasonPerUserHeadMemoryLimit),
 	}
 }
 
@@ -404,6 +485,14 @@ func (m *discardedMetrics) DeletePartialMatch(filter prometheus.Labels) {
 	m.newValueForTimestamp.DeletePartialMatch(filter)
 	m.perUserSeriesLimit.DeletePartialMatch(filter)
 	m.perMetricSeriesLimit.DeletePartialMatch(filter)
+	m.perLabelSetSeriesLimit.DeletePartialMatch(filter)
+	m.sampleIntervalTooShort.DeletePartialMatch(filter)
+	m.perUserHeadMemoryLimit.DeletePartialMatch(filter)
+	m.dryRunPerUserSeriesLimit.DeletePartialMatch(filter)
+	m.dryRunPerMetricSeriesLimit.DeletePartialMatch(filter)
+	m.dryRunPerLabelSetSeriesLimit.DeletePartialMatch(filter)
+	m.dryRunSampleIntervalTooShort.DeletePartialMatch(filter)
+	m.dryRunPerUserHeadMemoryLimit.DeletePartialMatch(filter)
 }
 
 func (m *discardedMetrics) DeleteLabelValues(userID string, group string) {
@@ -414,6 +503,14 @@ func (m *discardedMetrics) DeleteLabelValues(userID string, group string) {
 	m.newValueForTimestamp.DeleteLabelValues(userID, group)
 	m.perUserSeriesLimit.DeleteLabelValues(userID, group)
 	m.perMetricSeriesLimit.DeleteLabelValues(userID, group)
+	m.perLabelSetSeriesLimit.DeleteLabelValues(userID, group)
+	m.sampleIntervalTooShort.DeleteLabelValues(userID, group)
+	m.perUserHeadMemoryLimit.DeleteLabelValues(userID, group)
+	m.dryRunPerUserSeriesLimit.DeleteLabelValues(userID, group)
+	m.dryRunPerMetricSeriesLimit.DeleteLabelValues(userID, group)
+	m.dryRunPerLabelSetSeriesLimit.DeleteLabelValues(userID, group)
+	m.dryRunSampleIntervalTooShort.DeleteLabelValues(userID, group)
+	m.dryRunPerUserHeadMemoryLimit.DeleteLabelValues(userID, group)
 }
 
 // TSDB metrics collector. Each tenant has its own registry, that TSDB code uses.
@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/util/annotations"
+)
+
+const (
+	// queryStreamCacheAlignmentMs is the alignment of the time range cached for a query. The start of the
+	// cached range is aligned down, so that queries repeated with a slightly different start time, like the
+	// ones of a dashboard refreshing periodically, share the same cache entry.
+	queryStreamCacheAlignmentMs = 10 * 60 * 1000
+
+	// queryStreamCacheEntryOverheadBytes is the estimated memory overhead of each cached series and chunk.
+	queryStreamCacheEntryOverheadBytes = 64
+)
+
+// queryStreamCache caches the chunks returned by the TSDB for the part of the queries time range which
+// can't receive new samples anymore: the samples older than the TSDB head's appendable min valid time
+// can't be appended (unless the out-of-order ingestion is enabled), so the chunks of this part of the
+// time range only change when the head is compacted or the series are deleted, at which point the
+// cached entries of the tenant are invalidated. The entries are evicted in LRU order once the cache
+// reaches its max size.
+type queryStreamCache struct {
+	maxSizeBytes int
+
+	mtx       sync.Mutex
+	sizeBytes int
+	lru       *list.List                          // Most recently used entries at the front.
+	entries   map[string]*list.Element            // By key.
+	users     map[string]map[string]*list.Element // By user and key.
+
+	// generations is incremented each time the entries of a user are invalidated, so that the
+	// chunks queried before the invalidation are not cached after it.
+	generations map[string]uint64
+
+	requests prometheus.Counter
+	hits     prometheus.Counter
+	size     prometheus.Gauge
+}
+
+type queryStreamCacheEntry struct {
+	userID    string
+	key       string
+	series    []queryStreamCacheSeries
+	sizeBytes int
+}
+
+type queryStreamCacheSeries struct {
+	labels labels.Labels
+	chunks []chunks.Meta
+}
+
+func newQueryStreamCache(maxSizeBytes int, reg prometheus.Registerer) *queryStreamCache {
+	return &queryStreamCache{
+		maxSizeBytes: maxSizeBytes,
+		lru:          list.New(),
+		entries:      map[string]*list.Element{},
+		users:        map[string]map[string]*list.Element{},
+		generations:  map[string]uint64{},
+
+		requests: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_query_stream_cache_requests_total",
+			Help: "Total number of queries looked up in the query stream cache.",
+		}),
+		hits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_query_stream_cache_hits_total",
+			Help: "Total number of queries whose immutable part has been found in the query stream cache.",
+		}),
+		size: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_ingester_query_stream_cache_size_bytes",
+			Help: "Estimated size, in bytes, of the query stream cache.",
+		}),
+	}
+}
+
+// get returns the cached series for the key, if any. It also returns the current generation of the user's
+// entries, which must be passed to set when caching the series queried after a cache miss.
+func (c *queryStreamCache) get(userID, key string) ([]queryStreamCacheSeries, uint64, bool) {
+	c.requests.Inc()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, c.generations[userID], false
+	}
+	c.lru.MoveToFront(elem)
+	c.hits.Inc()
+	return elem.Value.(*queryStreamCacheEntry).series, c.generations[userID], true
+}
+
+// set caches the series for the key, unless the user's entries have been invalidated since the generation was read.
+func (c *queryStreamCache) set(userID, key string, generation uint64, series []queryStreamCacheSeries) {
+	entry := &queryStreamCacheEntry{userID: userID, key: key, series: series, sizeBytes: len(key)}
+	for _, s := range series {
+		entry.sizeBytes += queryStreamCacheEntryOverheadBytes
+		s.labels.Range(func(l labels.Label) {
+			entry.sizeBytes += len(l.Name) + len(l.Value)
+		})
+		for _, chk := range s.chunks {
+			entry.sizeBytes += queryStreamCacheEntryOverheadBytes + len(chk.Chunk.Bytes())
+		}
+	}
+
+	// Don't let a single query evict the whole cache.
+	if entry.sizeBytes > c.maxSizeBytes/2 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.generations[userID] != generation {
+		return
+	}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	elem := c.lru.PushFront(entry)
+	c.entries[key] = elem
+	if c.users[userID] == nil {
+		c.users[userID] = map[string]*list.Element{}
+	}
+	c.users[userID][key] = elem
+	c.sizeBytes += entry.sizeBytes
+
+	for c.sizeBytes > c.maxSizeBytes {
+		c.removeLocked(c.lru.Back())
+	}
+	c.size.Set(float64(c.sizeBytes))
+}
+
+// invalidateUser removes all the cached entries of the user.
+func (c *queryStreamCache) invalidateUser(userID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.generations[userID]++
+	for _, elem := range c.users[userID] {
+		c.removeLocked(elem)
+	}
+	c.size.Set(float64(c.sizeBytes))
+}
+
+func (c *queryStreamCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*queryStreamCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	delete(c.users[entry.userID], entry.key)
+	if len(c.users[entry.userID]) == 0 {
+		delete(c.users, entry.userID)
+	}
+	c.sizeBytes -= entry.sizeBytes
+}
+
+// queryStreamCacheKey returns the key of the cached chunks of the series matching matchers, in the shard
+// specified in hints if any, in the time range [from, through].
+func queryStreamCacheKey(userID string, from, through int64, hints *storage.SelectHints, matchers []*labels.Matcher) string {
+	b := strings.Builder{}
+	b.WriteString(userID)
+	b.WriteString(fmt.Sprintf(":%d:%d", from, through))
+	if hints != nil && hints.ShardCount > 0 {
+		b.WriteString(fmt.Sprintf(":%d_of_%d", hints.ShardIndex, hints.ShardCount))
+	}
+	for _, m := range matchers {
+		b.WriteByte(':')
+		b.WriteString(m.String())
+	}
+	return b.String()
+}
+
+// cachingChunkQuerier is a storage.ChunkQuerier which returns the chunks of the immutable part of the time range,
+// [from, liveFrom), from the query stream cache and only queries the TSDB for the remaining [liveFrom, through].
+// The chunks overlapping liveFrom are returned by both, so they're merged to not return duplicated samples.
+//
+// The cached time range starts at cachedFrom, which is from aligned down to queryStreamCacheAlignmentMs,
+// so the cached chunks are trimmed to the ones overlapping [from, liveFrom) when they're read.
+type cachingChunkQuerier struct {
+	storage.ChunkQuerier // Live querier, covering [liveFrom, through].
+
+	cache      *queryStreamCache
+	db         *userTSDB
+	cachedFrom int64
+	from       int64
+	liveFrom   int64
+	through    int64
+}
+
+func (q *cachingChunkQuerier) Select(ctx context.Context, _ bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.ChunkSeriesSet {
+	cachedSet, err := q.selectCached(ctx, hints, matchers)
+	if err != nil {
+		return storage.ErrChunkSeriesSet(err)
+	}
+	if q.liveFrom > q.through {
+		return cachedSet
+	}
+
+	liveHints := &storage.SelectHints{}
+	if hints != nil {
+		*liveHints = *hints
+	}
+	liveHints.Start = q.liveFrom
+	liveHints.End = q.through
+
+	// Series must be sorted to be merged.
+	liveSet := q.ChunkQuerier.Select(ctx, true, liveHints, matchers...)
+	return storage.NewMergeChunkSeriesSet([]storage.ChunkSeriesSet{cachedSet, liveSet}, storage.NewCompactingChunkSeriesMerger(storage.ChainedSeriesMerge))
+}
+
+// selectCached returns the sorted series, with their chunks, in the immutable part of the time range.
+func (q *cachingChunkQuerier) selectCached(ctx context.Context, hints *storage.SelectHints, matchers []*labels.Matcher) (storage.ChunkSeriesSet, error) {
+	cachedThrough := q.liveFrom - 1
+	key := queryStreamCacheKey(q.db.userID, q.cachedFrom, cachedThrough, hints, matchers)
+
+	series, generation, ok := q.cache.get(q.db.userID, key)
+	if !ok {
+		var err error
+		if series, err = q.queryCached(ctx, cachedThrough, hints, matchers); err != nil {
+			return nil, err
+		}
+		q.cache.set(q.db.userID, key, generation, series)
+	}
+
+	return &queryStreamCacheSeriesSet{series: series, minTime: q.from, idx: -1}, nil
+}
+
+// queryCached queries the TSDB for the chunks in [cachedFrom, cachedThrough], and copies them so that they can be cached.
+func (q *cachingChunkQuerier) queryCached(ctx context.Context, cachedThrough int64, hints *storage.SelectHints, matchers []*labels.Matcher) ([]queryStreamCacheSeries, error) {
+	cq, err := q.db.ChunkQuerier(q.cachedFrom, cachedThrough)
+	if err != nil {
+		return nil, err
+	}
+	defer cq.Close()
+
+	cachedHints := &storage.SelectHints{}
+	if hints != nil {
+		*cachedHints = *hints
+	}
+	cachedHints.Start = q.cachedFrom
+	cachedHints.End = cachedThrough
+
+	var (
+		result []queryStreamCacheSeries
+		it     chunks.Iterator
+	)
+
+	ss := cq.Select(ctx, true, cachedHints, matchers...)
+	for ss.Next() {
+		s := ss.At()
+		cs := queryStreamCacheSeries{labels: s.Labels().Copy()}
+
+		it = s.Iterator(it)
+		for it.Next() {
+			meta := it.At()
+			if meta.Chunk == nil {
+				return nil, errors.Errorf("unfilled chunk returned from TSDB chunk querier")
+			}
+
+			// The chunk data may reference memory which is released once the querier is closed.
+			chk, err := chunkenc.FromData(meta.Chunk.Encoding(), append([]byte(nil), meta.Chunk.Bytes()...))
+			if err != nil {
+				return nil, err
+			}
+			cs.chunks = append(cs.chunks, chunks.Meta{MinTime: meta.MinTime, MaxTime: meta.MaxTime, Chunk: chk})
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+
+		result = append(result, cs)
+	}
+
+	return result, ss.Err()
+}
+
+// queryStreamCacheSeriesSet is a storage.ChunkSeriesSet over cached series. It only returns the chunks ending
+// at or after minTime, and skips the series without any of them.
+type queryStreamCacheSeriesSet struct {
+	series  []queryStreamCacheSeries
+	minTime int64
+	idx     int
+
+	curr []chunks.Meta // Chunks of the current series ending at or after minTime.
+}
+
+func (s *queryStreamCacheSeriesSet) Next() bool {
+	for s.idx++; s.idx < len(s.series); s.idx++ {
+		chks := s.series[s.idx].chunks
+
+		s.curr = s.curr[:0]
+		for _, chk := range chks {
+			if chk.MaxTime >= s.minTime {
+				s.curr = append(s.curr, chk)
+			}
+		}
+		if len(s.curr) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *queryStreamCacheSeriesSet) At() storage.ChunkSeries {
+	// The current chunks are copied, because their slice is reused by Next.
+	chks := append([]chunks.Meta(nil), s.curr...)
+	return &storage.ChunkSeriesEntry{
+		Lset: s.series[s.idx].labels,
+		ChunkIteratorFn: func(chunks.Iterator) chunks.Iterator {
+			return storage.NewListChunkSeriesIterator(chks...)
+		},
+		ChunkCountFn: func() (int, error) {
+			return len(chks), nil
+		},
+	}
+}
+
+func (s *queryStreamCacheSeriesSet) Err() error { return nil }
+
+func (s *queryStreamCacheSeriesSet) Warnings() annotations.Annotations { return nil }
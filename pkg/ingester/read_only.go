@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/shutdownmarker"
+)
+
+const readOnlyMarkerFilename = "read-only-requested.txt"
+
+// getReadOnlyMarkerPath returns the absolute path of the read-only marker file.
+func getReadOnlyMarkerPath(dirPath string) string {
+	return filepath.Join(dirPath, readOnlyMarkerFilename)
+}
+
+// ReadOnlyHandler inspects or changes the read-only mode of the ingester. When in read-only mode,
+// the ingester is flagged as read-only in the ring: distributors stop sending writes to it, while
+// queriers keep querying it. TSDB head compaction and blocks shipping continue as usual, so once
+// all in-memory series have been shipped to the long-term storage the ingester can be safely scaled down.
+//
+// It also creates a file on disk which is used to re-apply the read-only mode if the
+// ingester restarts before being permanently shutdown.
+//
+// * `GET` shows the status of this configuration
+// * `POST` enables this configuration
+// * `DELETE` disables this configuration
+func (i *Ingester) ReadOnlyHandler(w http.ResponseWriter, r *http.Request) {
+	// Don't allow callers to change the read-only configuration while we're in the middle
+	// of starting or shutting down.
+	if i.State() != services.Running {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	readOnlyMarkerPath := getReadOnlyMarkerPath(i.cfg.BlocksStorageConfig.TSDB.Dir)
+	switch r.Method {
+	case http.MethodGet:
+		exists, err := shutdownmarker.Exists(readOnlyMarkerPath)
+		if err != nil {
+			level.Error(i.logger).Log("msg", "unable to check for read-only marker file", "path", readOnlyMarkerPath, "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if exists {
+			util.WriteTextResponse(w, "set\n")
+		} else {
+			util.WriteTextResponse(w, "unset\n")
+		}
+	case http.MethodPost:
+		if err := shutdownmarker.Create(readOnlyMarkerPath); err != nil {
+			level.Error(i.logger).Log("msg", "unable to create read-only marker file", "path", readOnlyMarkerPath, "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		level.Info(i.logger).Log("msg", "created read-only marker file", "path", readOnlyMarkerPath)
+
+		if err := i.setReadOnly(r.Context(), true); err != nil {
+			level.Error(i.logger).Log("msg", "unable to switch the ingester to read-only mode", "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if err := shutdownmarker.Remove(readOnlyMarkerPath); err != nil {
+			level.Error(i.logger).Log("msg", "unable to remove read-only marker file", "path", readOnlyMarkerPath, "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		level.Info(i.logger).Log("msg", "removed read-only marker file", "path", readOnlyMarkerPath)
+
+		if err := i.setReadOnly(r.Context(), false); err != nil {
+			level.Error(i.logger).Log("msg", "unable to switch the ingester out of read-only mode", "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// setReadOnly switches the ingester in or out of read-only mode, by flagging it as read-only in the ring.
+// Read-only instances keep their state in the ring, but they're left out of the write operations
+// (ring.Write and ring.WriteNoExtend), and replaced by the next instance in the ring, while they're
+// still included in the read operations. Unlike the instance state, the read-only flag can be changed
+// at any time and both ways.
+func (i *Ingester) setReadOnly(ctx context.Context, readOnly bool) error {
+	if err := i.lifecycler.ChangeReadOnlyState(ctx, readOnly); err != nil {
+		return errors.Wrap(err, "failed to change the read-only mode of the ingester in the ring")
+	}
+
+	if readOnly {
+		i.metrics.readOnlyMarker.Set(1)
+		level.Info(i.logger).Log("msg", "ingester switched to read-only mode")
+	} else {
+		i.metrics.readOnlyMarker.Set(0)
+		level.Info(i.logger).Log("msg", "ingester switched out of read-only mode")
+	}
+	return nil
+}
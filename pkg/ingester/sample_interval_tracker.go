@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+)
+
+const numSampleIntervalTrackerShards = 128
+
+type sampleIntervalTrackerShard struct {
+	mtx sync.Mutex
+	m   map[storage.SeriesRef]int64
+}
+
+// sampleIntervalTracker tracks the timestamp of the last sample appended to each in-memory series of a tenant,
+// in order to enforce the minimum interval between two samples of the same series.
+type sampleIntervalTracker struct {
+	shards []sampleIntervalTrackerShard
+}
+
+func newSampleIntervalTracker() *sampleIntervalTracker {
+	shards := make([]sampleIntervalTrackerShard, 0, numSampleIntervalTrackerShards)
+	for i := 0; i < numSampleIntervalTrackerShards; i++ {
+		shards = append(shards, sampleIntervalTrackerShard{
+			m: map[storage.SeriesRef]int64{},
+		})
+	}
+	return &sampleIntervalTracker{shards: shards}
+}
+
+func (t *sampleIntervalTracker) getShard(ref storage.SeriesRef) *sampleIntervalTrackerShard {
+	return &t.shards[uint64(ref)%numSampleIntervalTrackerShards]
+}
+
+// isTooClose returns true if the input timestamp is more recent than the last sample appended to the series,
+// but closer than minInterval to it. Samples with the same or an older timestamp are left to the TSDB to handle.
+func (t *sampleIntervalTracker) isTooClose(ref storage.SeriesRef, timestamp, minInterval int64) bool {
+	last, ok := t.last(ref)
+	return isSampleIntervalTooClose(last, ok, timestamp, minInterval)
+}
+
+// last returns the timestamp of the last sample appended to the series, if any.
+func (t *sampleIntervalTracker) last(ref storage.SeriesRef) (int64, bool) {
+	shard := t.getShard(ref)
+	shard.mtx.Lock()
+	defer shard.mtx.Unlock()
+
+	last, ok := shard.m[ref]
+	return last, ok
+}
+
+// appended tracks a sample appended to the series.
+func (t *sampleIntervalTracker) appended(ref storage.SeriesRef, timestamp int64) {
+	shard := t.getShard(ref)
+	shard.mtx.Lock()
+	defer shard.mtx.Unlock()
+
+	if last, ok := shard.m[ref]; !ok || timestamp > last {
+		shard.m[ref] = timestamp
+	}
+}
+
+// deleted stops tracking the series removed from the TSDB head.
+func (t *sampleIntervalTracker) deleted(series map[chunks.HeadSeriesRef]labels.Labels) {
+	for ref := range series {
+		shard := t.getShard(storage.SeriesRef(ref))
+		shard.mtx.Lock()
+		delete(shard.m, storage.SeriesRef(ref))
+		shard.mtx.Unlock()
+	}
+}
+
+func isSampleIntervalTooClose(last int64, ok bool, timestamp, minInterval int64) bool {
+	return ok && timestamp > last && timestamp-last < minInterval
+}
+
+// pendingSampleIntervals collects the samples appended to a TSDB appender, and applies them to the
+// sampleIntervalTracker only once the appender has been successfully committed. Samples appended
+// earlier in the same appender are taken into account when checking the interval.
+type pendingSampleIntervals struct {
+	tracker *sampleIntervalTracker
+	pending map[storage.SeriesRef]int64
+}
+
+func newPendingSampleIntervals(tracker *sampleIntervalTracker) *pendingSampleIntervals {
+	return &pendingSampleIntervals{tracker: tracker}
+}
+
+// isTooClose is like sampleIntervalTracker.isTooClose, but also considers the samples pending commit.
+func (p *pendingSampleIntervals) isTooClose(ref storage.SeriesRef, timestamp, minInterval int64) bool {
+	last, ok := p.tracker.last(ref)
+	if pending, pendingOK := p.pending[ref]; pendingOK && (!ok || pending > last) {
+		last, ok = pending, true
+	}
+	return isSampleIntervalTooClose(last, ok, timestamp, minInterval)
+}
+
+// appended tracks a sample appended to the series but not committed yet.
+func (p *pendingSampleIntervals) appended(ref storage.SeriesRef, timestamp int64) {
+	if p.pending == nil {
+		p.pending = map[storage.SeriesRef]int64{}
+	}
+	if last, ok := p.pending[ref]; !ok || timestamp > last {
+		p.pending[ref] = timestamp
+	}
+}
+
+// commit applies the pending samples to the tracker. It must be called only after the appender
+// has been successfully committed.
+func (p *pendingSampleIntervals) commit() {
+	for ref, timestamp := range p.pending {
+		p.tracker.appended(ref, timestamp)
+	}
+	p.pending = nil
+}
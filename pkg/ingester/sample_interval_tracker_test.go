@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleIntervalTracker(t *testing.T) {
+	tracker := newSampleIntervalTracker()
+
+	// The first sample of a series is never too close.
+	assert.False(t, tracker.isTooClose(1, 1000, 10000))
+
+	tracker.appended(1, 1000)
+	assert.True(t, tracker.isTooClose(1, 5000, 10000))
+	assert.False(t, tracker.isTooClose(1, 11000, 10000))
+	assert.False(t, tracker.isTooClose(1, 1000, 10000), "samples with the same timestamp are left to the TSDB")
+	assert.False(t, tracker.isTooClose(1, 500, 10000), "older samples are left to the TSDB")
+	assert.False(t, tracker.isTooClose(2, 5000, 10000))
+
+	// Older samples don't move the tracked timestamp back.
+	tracker.appended(1, 500)
+	assert.True(t, tracker.isTooClose(1, 5000, 10000))
+
+	tracker.deleted(map[chunks.HeadSeriesRef]labels.Labels{1: labels.EmptyLabels()})
+	assert.False(t, tracker.isTooClose(1, 5000, 10000))
+}
+
+func TestPendingSampleIntervals(t *testing.T) {
+	tracker := newSampleIntervalTracker()
+	tracker.appended(1, 1000)
+
+	pending := newPendingSampleIntervals(tracker)
+	assert.True(t, pending.isTooClose(1, 5000, 10000))
+
+	pending.appended(1, 11000)
+	pending.appended(2, 1000)
+
+	// The samples pending commit are considered by the pending intervals, but not by the tracker.
+	assert.True(t, pending.isTooClose(1, 14000, 10000))
+	assert.True(t, pending.isTooClose(2, 5000, 10000))
+	assert.False(t, tracker.isTooClose(1, 14000, 10000))
+	assert.False(t, tracker.isTooClose(2, 5000, 10000))
+
+	pending.commit()
+	assert.True(t, tracker.isTooClose(1, 14000, 10000))
+	assert.True(t, tracker.isTooClose(2, 5000, 10000))
+
+	// Discarding the pending intervals, e.g. because the commit failed, leaves the tracker untouched.
+	discarded := newPendingSampleIntervals(tracker)
+	discarded.appended(3, 1000)
+	assert.True(t, discarded.isTooClose(3, 5000, 10000))
+	assert.False(t, tracker.isTooClose(3, 5000, 10000))
+}
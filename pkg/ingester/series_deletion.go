@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+// applySeriesDeletionRequests reads the series deletion requests of each tenant from the storage and
+// applies the new ones to the tenant's TSDB, as tombstones. The deleted samples are excluded from the
+// query results, and from the blocks compacted from the TSDB Head and shipped to the storage.
+func (i *Ingester) applySeriesDeletionRequests(ctx context.Context) error {
+	// Number of concurrent workers is limited in order to avoid to concurrently sync a lot
+	// of tenants in a large cluster.
+	_ = concurrency.ForEachUser(ctx, i.getTSDBUsers(), i.cfg.BlocksStorageConfig.TSDB.ShipConcurrency, func(ctx context.Context, userID string) error {
+		userDB := i.getTSDB(userID)
+		if userDB == nil {
+			return nil
+		}
+
+		requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, i.bucket, userID)
+		if err != nil {
+			level.Warn(i.logger).Log("msg", "failed to list series deletion requests", "user", userID, "err", err)
+			return nil
+		}
+
+		// Forget the requests deleted from the storage once processed.
+		existing := make(map[string]struct{}, len(requests))
+		for _, req := range requests {
+			existing[req.RequestID] = struct{}{}
+		}
+		for requestID := range userDB.appliedSeriesDeletionRequests {
+			if _, ok := existing[requestID]; !ok {
+				delete(userDB.appliedSeriesDeletionRequests, requestID)
+			}
+		}
+
+		var pending []*mimir_tsdb.SeriesDeletionRequest
+		for _, req := range requests {
+			// A request re-submitted after being processed has a more recent creation time.
+			if createdAt, ok := userDB.appliedSeriesDeletionRequests[req.RequestID]; !ok || createdAt != req.CreatedAt {
+				pending = append(pending, req)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		// Make sure the TSDB state is active, in order to avoid any race condition with closing idle TSDBs.
+		// If the shipping is in progress, the requests will be applied at the next sync.
+		if ok, s := userDB.changeState(active, activeShipping); !ok {
+			level.Info(i.logger).Log("msg", "series deletion requests sync skipped because the TSDB is not active", "user", userID, "state", s.String())
+			return nil
+		}
+		defer userDB.changeState(activeShipping, active)
+
+		for _, req := range pending {
+			if err := applySeriesDeletionRequest(ctx, userDB, req); err != nil {
+				i.metrics.seriesDeletionRequestsApplyFailures.Inc()
+				level.Warn(i.logger).Log("msg", "failed to apply series deletion request", "user", userID, "request_id", req.RequestID, "err", err)
+				continue
+			}
+
+			if userDB.appliedSeriesDeletionRequests == nil {
+				userDB.appliedSeriesDeletionRequests = map[string]int64{}
+			}
+			userDB.appliedSeriesDeletionRequests[req.RequestID] = req.CreatedAt
+
+			if i.queryStreamCache != nil {
+				i.queryStreamCache.invalidateUser(userID)
+			}
+
+			i.metrics.seriesDeletionRequestsApplied.Inc()
+			level.Info(i.logger).Log("msg", "applied series deletion request", "user", userID, "request_id", req.RequestID)
+		}
+
+		return nil
+	})
+
+	// Never return an error, otherwise the service would stop.
+	return nil
+}
+
+func applySeriesDeletionRequest(ctx context.Context, userDB *userTSDB, req *mimir_tsdb.SeriesDeletionRequest) error {
+	matchers, err := req.Matchers()
+	if err != nil {
+		return err
+	}
+
+	for _, ms := range matchers {
+		if err := userDB.db.Delete(ctx, req.StartTime, req.EndTime, ms...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
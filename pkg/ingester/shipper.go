@@ -22,9 +22,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/fileutil"
 	"github.com/thanos-io/objstore"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/block"
 )
@@ -69,6 +72,9 @@ type shipper struct {
 	metrics     *shipperMetrics
 	bucket      objstore.Bucket
 	source      block.SourceType
+
+	// Optional. If set, the exemplars of the block time range are persisted into the block before uploading it.
+	exemplars storage.ExemplarQueryable
 }
 
 // newShipper creates a new uploader that detects new TSDB blocks in dir and uploads them to
@@ -82,6 +88,7 @@ func newShipper(
 	dir string,
 	bucket objstore.Bucket,
 	source block.SourceType,
+	exemplars storage.ExemplarQueryable,
 ) *shipper {
 	if logger == nil {
 		logger = log.NewNopLogger()
@@ -95,6 +102,7 @@ func newShipper(
 		bucket:      bucket,
 		metrics:     metrics,
 		source:      source,
+		exemplars:   exemplars,
 	}
 }
 
@@ -197,10 +205,45 @@ func (s *shipper) upload(ctx context.Context, meta *block.Meta) error {
 		meta.Thanos.Labels[mimir_tsdb.OutOfOrderExternalLabel] = mimir_tsdb.OutOfOrderExternalLabelValue
 	}
 
+	if s.exemplars != nil {
+		if err := s.writeExemplars(ctx, blockDir, meta); err != nil {
+			return errors.Wrap(err, "write exemplars")
+		}
+	}
+
 	// Upload block with custom metadata.
 	return block.Upload(ctx, s.logger, s.bucket, blockDir, meta)
 }
 
+// writeExemplars writes the exemplars of the block time range to the exemplars file in the block directory,
+// unless the file has already been written by a previous upload attempt.
+func (s *shipper) writeExemplars(ctx context.Context, blockDir string, meta *block.Meta) error {
+	if _, err := os.Stat(filepath.Join(blockDir, block.ExemplarsFilename)); err == nil {
+		return nil
+	}
+
+	q, err := s.exemplars.ExemplarQuerier(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The block max time is exclusive, while the exemplar querier time range is inclusive.
+	results, err := q.Select(meta.MinTime, meta.MaxTime-1, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, model.MetricNameLabel, ".+")})
+	if err != nil {
+		return err
+	}
+
+	series := make([]mimirpb.TimeSeries, 0, len(results))
+	for _, r := range results {
+		series = append(series, mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(r.SeriesLabels),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(r.Exemplars),
+		})
+	}
+
+	return block.WriteExemplarsFile(blockDir, series)
+}
+
 // blockMetasFromOldest returns the block meta of each block found in dir
 // sorted by minTime asc.
 func (s *shipper) blockMetasFromOldest() (metas []*block.Meta, _ error) {
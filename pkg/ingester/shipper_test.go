@@ -20,10 +20,13 @@ import (
 	"github.com/grafana/dskit/concurrency"
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/bucket/filesystem"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/block"
@@ -51,7 +54,7 @@ func TestShipper(t *testing.T) {
 	logger := log.NewLogfmtLogger(logs)
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 	t.Run("no shipper file yet", func(t *testing.T) {
 		// No shipper file = nothing is reported as shipped.
@@ -193,7 +196,7 @@ func TestShipper_DeceivingUploadErrors(t *testing.T) {
 	logger := log.NewLogfmtLogger(os.Stderr)
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+	s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 	// Create and upload a block
 	id1 := ulid.MustNew(1, nil)
@@ -222,6 +225,64 @@ func TestShipper_DeceivingUploadErrors(t *testing.T) {
 	require.Equal(t, 1, uploaded)
 }
 
+func TestShipper_ShouldPersistExemplars(t *testing.T) {
+	blocksDir := t.TempDir()
+	bkt := objstore.NewInMemBucket()
+
+	exemplars, err := tsdb.NewCircularExemplarStorage(10, tsdb.NewExemplarMetrics(nil))
+	require.NoError(t, err)
+
+	// Add exemplars both inside and outside the block time range.
+	for _, ts := range []int64{500, 1000, 1500, 2000} {
+		require.NoError(t, exemplars.AddExemplar(labels.FromStrings("__name__", "up"), exemplar.Exemplar{
+			Labels: labels.FromStrings("trace_id", "abc"),
+			Value:  float64(ts),
+			Ts:     ts,
+			HasTs:  true,
+		}))
+	}
+
+	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	s := newShipper(log.NewNopLogger(), overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, exemplars)
+
+	id := ulid.MustNew(1, nil)
+	createBlock(t, blocksDir, id, block.Meta{
+		BlockMeta: tsdb.BlockMeta{
+			ULID:    id,
+			MinTime: 1000,
+			MaxTime: 2000,
+			Version: 1,
+			Stats: tsdb.BlockStats{
+				NumSamples: 100, // Shipper checks if number of samples is greater than 0.
+			},
+		},
+	})
+
+	uploaded, err := s.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, uploaded)
+
+	meta, err := block.DownloadMeta(context.Background(), log.NewNopLogger(), bkt, id)
+	require.NoError(t, err)
+	require.True(t, meta.HasExemplars())
+
+	r, err := bkt.Get(context.Background(), path.Join(id.String(), block.ExemplarsFilename))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	series, err := block.ReadExemplars(r)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Equal(t, labels.FromStrings("__name__", "up"), mimirpb.FromLabelAdaptersToLabels(series[0].Labels))
+
+	var timestamps []int64
+	for _, e := range series[0].Exemplars {
+		timestamps = append(timestamps, e.TimestampMs)
+	}
+	require.Equal(t, []int64{1000, 1500}, timestamps)
+}
+
 func TestIterBlockMetas(t *testing.T) {
 	dir := t.TempDir()
 
@@ -259,7 +320,7 @@ func TestIterBlockMetas(t *testing.T) {
 	}.WriteToDir(log.NewNopLogger(), path.Join(dir, id3.String())))
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	shipper := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, nil, block.TestSource)
+	shipper := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, nil, block.TestSource, nil)
 	metas, err := shipper.blockMetasFromOldest()
 	require.NoError(t, err)
 	require.Equal(t, sort.SliceIsSorted(metas, func(i, j int) bool {
@@ -273,7 +334,7 @@ func TestShipperAddsSegmentFiles(t *testing.T) {
 	inmemory := objstore.NewInMemBucket()
 	overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
-	s := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, inmemory, block.TestSource)
+	s := newShipper(nil, overrides, "", newShipperMetrics(nil), dir, inmemory, block.TestSource, nil)
 
 	id := ulid.MustNew(1, nil)
 	blockDir := path.Join(dir, id.String())
@@ -417,7 +478,7 @@ func TestShipper_AddOOOLabel(t *testing.T) {
 			}
 			overrides, err := validation.NewOverrides(defaultLimitsTestConfig(), validation.NewMockTenantLimits(tenantLimits))
 			require.NoError(t, err)
-			s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource)
+			s := newShipper(logger, overrides, "", newShipperMetrics(nil), blocksDir, bkt, block.TestSource, nil)
 
 			createBlock(t, blocksDir, tc.meta.ULID, tc.meta)
 
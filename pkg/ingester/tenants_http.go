@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/prometheus/tsdb"
 	"golang.org/x/exp/slices"
@@ -23,10 +24,11 @@ type tenantsPageContent struct {
 }
 
 type tenantStats struct {
-	Tenant  string
-	Blocks  int
-	MinTime string
-	MaxTime string
+	Tenant     string
+	Blocks     int
+	MinTime    string
+	MaxTime    string
+	HeadMemory string
 
 	Warning string
 }
@@ -50,6 +52,16 @@ type tenantTSDBHeadPageContent struct {
 	AppendableMinValidTime string
 	MinOOOTime             string
 	MaxOOOTime             string
+	Memory                 *tenantTSDBHeadMemoryPageContent
+}
+
+type tenantTSDBHeadMemoryPageContent struct {
+	Total    string
+	Limit    string
+	Series   string
+	Labels   string
+	Postings string
+	Chunks   string
 }
 
 type tenantTSDBBlockPageContent struct {
@@ -85,6 +97,9 @@ func (i *Ingester) TenantsHandler(w http.ResponseWriter, req *http.Request) {
 		s.MinTime = formatMillisTime(db.Head().MinTime())
 		maxMillis := db.Head().MaxTime()
 		s.MaxTime = formatMillisTime(maxMillis)
+		if stats := db.headMemory.Load(); stats != nil {
+			s.HeadMemory = formatBytes(stats.TotalBytes())
+		}
 
 		if maxMillis-nowMillis > i.limits.CreationGracePeriod(t).Milliseconds() {
 			s.Warning = "TSDB Head max timestamp too far in the future"
@@ -133,6 +148,20 @@ func (i *Ingester) TenantTSDBHandler(w http.ResponseWriter, req *http.Request) {
 		c.Head.AppendableMinValidTime = formatMillisTime(m)
 	}
 
+	if stats := db.headMemory.Load(); stats != nil {
+		c.Head.Memory = &tenantTSDBHeadMemoryPageContent{
+			Total:    formatBytes(stats.TotalBytes()),
+			Limit:    "unlimited",
+			Series:   formatBytes(stats.SeriesBytes),
+			Labels:   formatBytes(stats.LabelsBytes),
+			Postings: formatBytes(stats.PostingsBytes),
+			Chunks:   formatBytes(stats.ChunksBytes),
+		}
+		if limit := i.limits.MaxHeadMemoryBytesPerTenant(tenant); limit > 0 {
+			c.Head.Memory.Limit = formatBytes(limit)
+		}
+	}
+
 	shipped := db.getCachedShippedBlocks()
 
 	blocks := db.db.Blocks()
@@ -170,6 +199,10 @@ func formatMillisTime(t int64) string {
 	return fmt.Sprintf("%s (%d)", formatTime(time.UnixMilli(t)), t)
 }
 
+func formatBytes(b int64) string {
+	return fmt.Sprintf("%s (%d)", humanize.IBytes(uint64(b)), b)
+}
+
 func formatTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
@@ -19,9 +19,11 @@ import (
 func TestIngester_TenantsHandlers(t *testing.T) {
 	ctx := context.Background()
 	cfg := defaultIngesterTestConfig(t)
+	limits := defaultLimitsTestConfig()
+	limits.MaxHeadMemoryBytesPerTenant = 1 << 30
 
 	// Create ingester
-	i, err := prepareIngesterWithBlocksStorage(t, cfg, nil)
+	i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", nil)
 	require.NoError(t, err)
 
 	require.NoError(t, services.StartAndAwaitRunning(ctx, i))
@@ -33,6 +35,7 @@ func TestIngester_TenantsHandlers(t *testing.T) {
 	})
 
 	pushSingleSampleAtTime(t, i, time.Now().UnixMilli())
+	i.updateHeadMemoryEstimates(ctx)
 
 	t.Run("tenants list", func(t *testing.T) {
 		rec := httptest.NewRecorder()
@@ -44,6 +47,7 @@ func TestIngester_TenantsHandlers(t *testing.T) {
 		require.Equal(t, http.StatusOK, rec.Code)
 		// Check if link to user's TSDB was generated
 		require.Contains(t, rec.Body.String(), fmt.Sprintf(`<a href="tsdb/%s">%s</a>`, userID, userID))
+		require.NotContains(t, rec.Body.String(), "N/A")
 	})
 
 	t.Run("tenant TSDB for valid tenant", func(t *testing.T) {
@@ -56,6 +60,8 @@ func TestIngester_TenantsHandlers(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, rec.Code)
 		require.Contains(t, rec.Body.String(), "<li>Number of series: 1</li>")
+		require.Contains(t, rec.Body.String(), "<li>Estimated Memory: ")
+		require.NotContains(t, rec.Body.String(), "<li>Estimated Memory: N/A</li>")
 	})
 
 	t.Run("tenant TSDB for unknown tenant", func(t *testing.T) {
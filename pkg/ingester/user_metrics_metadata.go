@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/mimir/pkg/ingester/client"
@@ -27,19 +28,26 @@ type userMetricsMetadata struct {
 	metricToMetadata map[string]metricMetadataSet
 
 	errorSamplers ingesterErrSamplers
+	logger        log.Logger
 }
 
-func newMetadataMap(l *Limiter, m *ingesterMetrics, errorSamplers ingesterErrSamplers, userID string) *userMetricsMetadata {
+func newMetadataMap(l *Limiter, m *ingesterMetrics, errorSamplers ingesterErrSamplers, logger log.Logger, userID string) *userMetricsMetadata {
 	return &userMetricsMetadata{
 		metricToMetadata: map[string]metricMetadataSet{},
 		limiter:          l,
 		metrics:          m,
 		errorSamplers:    errorSamplers,
+		logger:           logger,
 		userID:           userID,
 	}
 }
 
 func (mm *userMetricsMetadata) add(metric string, metadata *mimirpb.MetricMetadata) error {
+	return mm.addAt(metric, metadata, time.Now())
+}
+
+// addAt adds the metadata of metric as if it was last seen at lastSeen.
+func (mm *userMetricsMetadata) addAt(metric string, metadata *mimirpb.MetricMetadata, lastSeen time.Time) error {
 	mm.mtx.Lock()
 	defer mm.mtx.Unlock()
 
@@ -50,16 +58,26 @@ func (mm *userMetricsMetadata) add(metric string, metadata *mimirpb.MetricMetada
 	if !ok {
 		// Verify that the user can create more metric metadata given we don't have a set for that metric name.
 		if !mm.limiter.IsWithinMaxMetricsWithMetadataPerUser(mm.userID, len(mm.metricToMetadata)) {
-			mm.metrics.discardedMetadataPerUserMetadataLimit.WithLabelValues(mm.userID).Inc()
-			return mm.errorSamplers.maxMetadataPerUserLimitExceeded.WrapError(newPerUserMetadataLimitReachedError(mm.limiter.limits.MaxGlobalMetricsWithMetadataPerUser(mm.userID)))
+			err := newPerUserMetadataLimitReachedError(mm.limiter.limits.MaxGlobalMetricsWithMetadataPerUser(mm.userID))
+			if !mm.limiter.limits.LimitsDryRun(mm.userID) {
+				mm.metrics.discardedMetadataPerUserMetadataLimit.WithLabelValues(mm.userID).Inc()
+				return mm.errorSamplers.maxMetadataPerUserLimitExceeded.WrapError(err)
+			}
+			mm.metrics.dryRunDiscardedMetadataPerUserMetadataLimit.WithLabelValues(mm.userID).Inc()
+			logDryRunLimitExceeded(mm.logger, mm.errorSamplers.maxMetadataPerUserLimitExceeded, mm.userID, err)
 		}
 		set = metricMetadataSet{}
 		mm.metricToMetadata[metric] = set
 	}
 
 	if !mm.limiter.IsWithinMaxMetadataPerMetric(mm.userID, len(set)) {
-		mm.metrics.discardedMetadataPerMetricMetadataLimit.WithLabelValues(mm.userID).Inc()
-		return mm.errorSamplers.maxMetadataPerMetricLimitExceeded.WrapError(newPerMetricMetadataLimitReachedError(mm.limiter.limits.MaxGlobalMetadataPerMetric(mm.userID), labels.FromStrings(labels.MetricName, metric)))
+		err := newPerMetricMetadataLimitReachedError(mm.limiter.limits.MaxGlobalMetadataPerMetric(mm.userID), labels.FromStrings(labels.MetricName, metric))
+		if !mm.limiter.limits.LimitsDryRun(mm.userID) {
+			mm.metrics.discardedMetadataPerMetricMetadataLimit.WithLabelValues(mm.userID).Inc()
+			return mm.errorSamplers.maxMetadataPerMetricLimitExceeded.WrapError(err)
+		}
+		mm.metrics.dryRunDiscardedMetadataPerMetricMetadataLimit.WithLabelValues(mm.userID).Inc()
+		logDryRunLimitExceeded(mm.logger, mm.errorSamplers.maxMetadataPerMetricLimitExceeded, mm.userID, err)
 	}
 
 	// if we have seen this metadata before, it is a no-op and we don't need to change our metrics.
@@ -69,10 +87,24 @@ func (mm *userMetricsMetadata) add(metric string, metadata *mimirpb.MetricMetada
 		mm.metrics.memMetadataCreatedTotal.WithLabelValues(mm.userID).Inc()
 	}
 
-	mm.metricToMetadata[metric][*metadata] = time.Now()
+	mm.metricToMetadata[metric][*metadata] = lastSeen
 	return nil
 }
 
+// lastSeen returns a copy of all the metadata held for the tenant, along with the time each metadata was last seen.
+func (mm *userMetricsMetadata) lastSeen() map[mimirpb.MetricMetadata]time.Time {
+	mm.mtx.RLock()
+	defer mm.mtx.RUnlock()
+
+	r := map[mimirpb.MetricMetadata]time.Time{}
+	for _, set := range mm.metricToMetadata {
+		for m, t := range set {
+			r[m] = t
+		}
+	}
+	return r
+}
+
 // If deadline is zero, all metadata is purged.
 func (mm *userMetricsMetadata) purge(deadline time.Time) {
 	mm.mtx.Lock()
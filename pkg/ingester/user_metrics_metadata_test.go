@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -93,7 +94,7 @@ func TestUserMetricsMetadata(t *testing.T) {
 				nil,
 			)
 
-			mm := newMetadataMap(limiter, metrics, errorSamplers, "test")
+			mm := newMetadataMap(limiter, metrics, errorSamplers, log.NewNopLogger(), "test")
 
 			// Attempt to add all metadata
 			for _, i := range testData.inputMetadata {
@@ -149,7 +150,7 @@ func TestUserMetricsMetadataRequest(t *testing.T) {
 		nil,
 	)
 
-	mm := newMetadataMap(limiter, metrics, newIngesterErrSamplers(0), "test")
+	mm := newMetadataMap(limiter, metrics, newIngesterErrSamplers(0), log.NewNopLogger(), "test")
 
 	inputMetadata := []mimirpb.MetricMetadata{
 		{Type: mimirpb.COUNTER, MetricFamilyName: "test_metric_1", Help: "foo"},
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/oklog/ulid"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,9 +23,12 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/grafana/mimir/pkg/ingester/activeseries"
+	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/extract"
 	"github.com/grafana/mimir/pkg/util/globalerror"
+	util_log "github.com/grafana/mimir/pkg/util/log"
 	util_math "github.com/grafana/mimir/pkg/util/math"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 type tsdbState int
@@ -87,12 +91,30 @@ type userTSDB struct {
 	userID         string
 	activeSeries   *activeseries.ActiveSeries
 	seriesInMetric *metricCounter
-	limiter        *Limiter
+	// Number of series per label set, used to enforce the per-label-set series limits.
+	seriesInLabelSet *labelSetCounter
+	// Timestamp of the last sample per series, used to enforce the min sample interval.
+	sampleIntervals *sampleIntervalTracker
+	// Estimated memory used by the TSDB head, used to enforce the per-tenant head memory limit.
+	// Periodically updated, nil until the first estimate.
+	headMemory atomic.Pointer[headMemoryStats]
+
+	// Creation time of the series deletion requests applied to the TSDB, by request ID.
+	// Only accessed by the series deletion requests sync.
+	appliedSeriesDeletionRequests map[string]int64
+
+	limiter *Limiter
 
 	instanceSeriesCount *atomic.Int64 // Shared across all userTSDB instances created by ingester.
 	instanceLimitsFn    func() *InstanceLimits
 	instanceErrors      *prometheus.CounterVec
 
+	// Used to track the series exceeding the series limits when the limits are in dry-run mode.
+	activeGroups  *util.ActiveGroupsCleanupService
+	discarded     *discardedMetrics
+	errorSamplers ingesterErrSamplers
+	logger        log.Logger
+
 	stateMtx                                     sync.RWMutex
 	state                                        tsdbState
 	inFlightAppends                              sync.WaitGroup // Increased with stateMtx read lock held.
@@ -112,6 +134,9 @@ type userTSDB struct {
 	// Unix timestamp of last deletion mark check.
 	lastDeletionMarkCheck atomic.Int64
 
+	// Unix timestamp of the last TSDB head compaction run to evict the stale series.
+	lastStaleSeriesEviction atomic.Int64
+
 	// for statistics
 	ingestedAPISamples  *util_math.EwmaRate
 	ingestedRuleSamples *util_math.EwmaRate
@@ -273,9 +298,24 @@ func (u *userTSDB) PreCreation(metric labels.Labels) error {
 		}
 	}
 
+	dryRun := u.limiter.limits.LimitsDryRun(u.userID)
+
 	// Total series limit.
 	if !u.limiter.IsWithinMaxSeriesPerUser(u.userID, int(u.Head().NumSeries())) {
-		return globalerror.MaxSeriesPerUser
+		if !dryRun {
+			return globalerror.MaxSeriesPerUser
+		}
+		u.trackDryRunSeriesLimitExceeded(metric, u.discarded.dryRunPerUserSeriesLimit, u.errorSamplers.maxSeriesPerUserLimitExceeded,
+			newPerUserSeriesLimitReachedError(u.limiter.limits.MaxGlobalSeriesPerUser(u.userID)))
+	}
+
+	// Head memory limit.
+	if limit := u.limiter.limits.MaxHeadMemoryBytesPerTenant(u.userID); limit > 0 && u.headMemoryBytes() >= limit {
+		if !dryRun {
+			return globalerror.MaxHeadMemoryPerUser
+		}
+		u.trackDryRunSeriesLimitExceeded(metric, u.discarded.dryRunPerUserHeadMemoryLimit, u.errorSamplers.maxHeadMemoryPerUserLimitExceeded,
+			newPerUserHeadMemoryLimitReachedError(limit))
 	}
 
 	// Series per metric name limit.
@@ -284,12 +324,77 @@ func (u *userTSDB) PreCreation(metric labels.Labels) error {
 		return err
 	}
 	if !u.seriesInMetric.canAddSeriesFor(u.userID, metricName) {
-		return globalerror.MaxSeriesPerMetric
+		if !dryRun {
+			return globalerror.MaxSeriesPerMetric
+		}
+		u.trackDryRunSeriesLimitExceeded(metric, u.discarded.dryRunPerMetricSeriesLimit, u.errorSamplers.maxSeriesPerMetricLimitExceeded,
+			newPerMetricSeriesLimitReachedError(u.limiter.limits.MaxGlobalSeriesPerMetric(u.userID), metric))
+	}
+
+	// Series per label set limits.
+	if limit := u.seriesInLabelSet.exceededLimit(u.limiter, metric); limit != nil {
+		if !dryRun {
+			return labelSetSeriesLimitError{limit: limit}
+		}
+		u.trackDryRunSeriesLimitExceeded(metric, u.discarded.dryRunPerLabelSetSeriesLimit, u.errorSamplers.maxSeriesPerLabelSetLimitExceeded,
+			newPerLabelSetSeriesLimitReachedError(limit.Selector, limit.MaxSeries, metric))
 	}
 
 	return nil
 }
 
+// labelSetSeriesLimitError is returned by PreCreation when a series exceeds a per-label-set series limit.
+// It doesn't retain the series labels, because they may reference the push request buffer.
+type labelSetSeriesLimitError struct {
+	limit *validation.LabelSetLimit
+}
+
+func (e labelSetSeriesLimitError) Error() string {
+	return string(globalerror.MaxSeriesPerLabelSet)
+}
+
+// headMemoryBytes returns the last estimate of the memory used by the TSDB head, or 0 if not estimated yet.
+func (u *userTSDB) headMemoryBytes() int64 {
+	if stats := u.headMemory.Load(); stats != nil {
+		return stats.TotalBytes()
+	}
+	return 0
+}
+
+// countHeadSeries returns the number of series in the TSDB head matching the input matchers.
+func (u *userTSDB) countHeadSeries(matchers []*labels.Matcher) (int, error) {
+	if u.db == nil {
+		return 0, errors.New("TSDB is not open")
+	}
+
+	idx, err := u.Head().Index()
+	if err != nil {
+		return 0, err
+	}
+	defer idx.Close()
+
+	postings, err := tsdb.PostingsForMatchers(context.Background(), idx, matchers...)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for postings.Next() {
+		count++
+	}
+	return count, postings.Err()
+}
+
+// trackDryRunSeriesLimitExceeded tracks a series which is created, even if it exceeds a series limit,
+// because the limits are in dry-run mode.
+func (u *userTSDB) trackDryRunSeriesLimitExceeded(metric labels.Labels, counter *prometheus.CounterVec, sampler *util_log.Sampler, err error) {
+	group := ""
+	if groupLabel := u.limiter.limits.SeparateMetricsGroupLabel(u.userID); groupLabel != "" {
+		group = u.activeGroups.UpdateActiveGroupTimestamp(u.userID, metric.Get(groupLabel), time.Now())
+	}
+	counter.WithLabelValues(u.userID, group).Inc()
+	logDryRunLimitExceeded(u.logger, sampler, u.userID, err)
+}
+
 func (u *userTSDB) PostCreation(metric labels.Labels) {
 	u.instanceSeriesCount.Inc()
 
@@ -299,6 +404,7 @@ func (u *userTSDB) PostCreation(metric labels.Labels) {
 		return
 	}
 	u.seriesInMetric.increaseSeriesForMetric(metricName)
+	u.seriesInLabelSet.increaseSeries(metric)
 }
 
 func (u *userTSDB) PostDeletion(metrics map[chunks.HeadSeriesRef]labels.Labels) {
@@ -311,9 +417,11 @@ func (u *userTSDB) PostDeletion(metrics map[chunks.HeadSeriesRef]labels.Labels)
 			continue
 		}
 		u.seriesInMetric.decreaseSeriesForMetric(metricName)
+		u.seriesInLabelSet.decreaseSeries(lbls)
 	}
 
 	u.activeSeries.PostDeletion(metrics)
+	u.sampleIntervals.deleted(metrics)
 }
 
 // blocksToDelete filters the input blocks and returns the blocks which are safe to be deleted from the ingester.
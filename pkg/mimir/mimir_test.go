@@ -409,6 +409,26 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name: "should fail if the stale series eviction idle timeout is greater than the active series idle timeout",
+			getTestConfig: func() *Config {
+				cfg := newDefaultConfig()
+				cfg.LimitsConfig.StaleSeriesEvictionIdleTimeout = model.Duration(cfg.Ingester.ActiveSeriesMetrics.IdleTimeout + time.Minute)
+
+				return cfg
+			},
+			expectAnyError: true,
+		},
+		{
+			name: "should pass if the stale series eviction idle timeout is equal to the active series idle timeout",
+			getTestConfig: func() *Config {
+				cfg := newDefaultConfig()
+				cfg.LimitsConfig.StaleSeriesEvictionIdleTimeout = model.Duration(cfg.Ingester.ActiveSeriesMetrics.IdleTimeout)
+
+				return cfg
+			},
+			expectedError: nil,
+		},
 		{
 			name: "should fail if querier timeout is bigger than http server timeout",
 			getTestConfig: func() *Config {
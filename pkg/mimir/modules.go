@@ -813,15 +813,15 @@ func (t *Mimir) initRuler() (serv services.Service, err error) {
 
 			federatedQueryable = tenantfederation.NewQueryable(queryable, bypassForSingleQuerier, t.Cfg.TenantFederation.MaxConcurrent, rulerRegisterer, util_log.Logger)
 
-			regularQueryFunc := rules.EngineQueryFunc(eng, queryable)
-			federatedQueryFunc := rules.EngineQueryFunc(eng, federatedQueryable)
+			regularQueryFunc := ruler.EngineQueryFunc(eng, queryable)
+			federatedQueryFunc := ruler.EngineQueryFunc(eng, federatedQueryable)
 
 			embeddedQueryable = federatedQueryable
 			queryFunc = ruler.TenantFederationQueryFunc(regularQueryFunc, federatedQueryFunc)
 
 		} else {
 			embeddedQueryable = queryable
-			queryFunc = rules.EngineQueryFunc(eng, queryable)
+			queryFunc = ruler.EngineQueryFunc(eng, queryable)
 		}
 	}
 	managerFactory := ruler.DefaultTenantManagerFactory(
@@ -927,7 +927,7 @@ func (t *Mimir) initStoreGateway() (serv services.Service, err error) {
 
 func (t *Mimir) initMemberlistKV() (services.Service, error) {
 	// Append to the list of codecs instead of overwriting the value to allow third parties to inject their own codecs.
-	t.Cfg.MemberlistKV.Codecs = append(t.Cfg.MemberlistKV.Codecs, ring.GetCodec())
+	t.Cfg.MemberlistKV.Codecs = append(t.Cfg.MemberlistKV.Codecs, ring.GetCodec(), distributor.GetReplicaDescCodec())
 
 	dnsProviderReg := prometheus.WrapRegistererWithPrefix(
 		"cortex_",
@@ -942,6 +942,7 @@ func (t *Mimir) initMemberlistKV() (services.Service, error) {
 
 	// Update the config.
 	t.Cfg.Distributor.DistributorRing.Common.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+	t.Cfg.Distributor.HATrackerConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ingester.IngesterRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.StoreGateway.ShardingRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Compactor.ShardingRing.Common.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
@@ -0,0 +1,380 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// RemoteWriteV1ProtoMessage is the fully qualified name of the Prometheus remote-write 1.0 message.
+	RemoteWriteV1ProtoMessage = "prometheus.WriteRequest"
+	// RemoteWriteV2ProtoMessage is the fully qualified name of the Prometheus remote-write 2.0 message.
+	RemoteWriteV2ProtoMessage = "io.prometheus.write.v2.Request"
+)
+
+// Field numbers of the io.prometheus.write.v2 messages.
+const (
+	rw2RequestSymbolsField    = 4
+	rw2RequestTimeseriesField = 5
+
+	rw2SeriesLabelsRefsField       = 1
+	rw2SeriesSamplesField          = 2
+	rw2SeriesHistogramsField       = 3
+	rw2SeriesExemplarsField        = 4
+	rw2SeriesMetadataField         = 5
+	rw2SeriesCreatedTimestampField = 6
+
+	rw2ExemplarLabelsRefsField = 1
+	rw2ExemplarValueField      = 2
+	rw2ExemplarTimestampField  = 3
+
+	rw2MetadataTypeField    = 1
+	rw2MetadataHelpRefField = 3
+	rw2MetadataUnitRefField = 4
+
+	// rw2CustomBucketsSchema is the schema of native histograms with custom buckets, which are not supported yet.
+	rw2CustomBucketsSchema = -53
+)
+
+// PreallocWriteRequestV2 decodes a Prometheus remote-write 2.0 request (io.prometheus.write.v2.Request)
+// into the wrapped PreallocWriteRequest, resolving the label, help and unit references against the
+// request's symbols table. The resolved strings reference the unmarshalled buffer instead of being
+// copied, so the buffer must not be reused until the request has been cleaned up.
+type PreallocWriteRequestV2 struct {
+	*PreallocWriteRequest
+
+	// CreatedTimestampZeroSampleWindowMs enables the ingestion of a zero sample at the created timestamp
+	// of a series, when the created timestamp is at most this many milliseconds older than the first
+	// sample of the series in the request. Zero disables it.
+	CreatedTimestampZeroSampleWindowMs int64
+
+	// Number of samples, histograms and exemplars decoded from the request, not including created timestamp zero samples.
+	Samples, Histograms, Exemplars int
+}
+
+// Unmarshal implements proto.Unmarshaler.
+func (p *PreallocWriteRequestV2) Unmarshal(dAtA []byte) error {
+	symbols, err := unmarshalRW2Symbols(dAtA)
+	if err != nil {
+		return err
+	}
+
+	p.Timeseries = PreallocTimeseriesSliceFromPool()
+	metadataIdx := map[string]struct{}{}
+
+	return rangeRW2Fields(dAtA, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != rw2RequestTimeseriesField {
+			return nil
+		}
+		if typ != protowire.BytesType {
+			return fmt.Errorf("proto: wrong wireType = %d for field Timeseries", typ)
+		}
+
+		series := TimeseriesFromPool()
+		p.Timeseries = append(p.Timeseries, PreallocTimeseries{TimeSeries: series})
+
+		metadata, createdTs, err := p.unmarshalTimeseries(value, symbols, series)
+		if err != nil {
+			return err
+		}
+		p.injectCreatedTimestampZeroSample(series, createdTs)
+
+		if metadata == nil {
+			return nil
+		}
+		for _, l := range series.Labels {
+			if l.Name != labels.MetricName {
+				continue
+			}
+			if _, ok := metadataIdx[l.Value]; !ok {
+				metadataIdx[l.Value] = struct{}{}
+				metadata.MetricFamilyName = l.Value
+				p.Metadata = append(p.Metadata, metadata)
+			}
+			break
+		}
+		return nil
+	})
+}
+
+func (p *PreallocWriteRequestV2) unmarshalTimeseries(dAtA []byte, symbols []string, series *TimeSeries) (*MetricMetadata, int64, error) {
+	var (
+		metadata  *MetricMetadata
+		createdTs int64
+	)
+
+	err := rangeRW2Fields(dAtA, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case rw2SeriesLabelsRefsField:
+			refs, err := unmarshalRW2Refs(typ, value, nil)
+			if err != nil {
+				return err
+			}
+			lbls, err := resolveRW2Labels(refs, symbols, series.Labels)
+			if err != nil {
+				return err
+			}
+			series.Labels = lbls
+
+		case rw2SeriesSamplesField:
+			var s Sample
+			if err := unmarshalRW2Message(typ, value, s.Unmarshal); err != nil {
+				return err
+			}
+			series.Samples = append(series.Samples, s)
+			p.Samples++
+
+		case rw2SeriesHistogramsField:
+			var h Histogram
+			if err := unmarshalRW2Message(typ, value, h.Unmarshal); err != nil {
+				return err
+			}
+			if h.Schema == rw2CustomBucketsSchema {
+				return errors.New("native histograms with custom buckets are not supported")
+			}
+			series.Histograms = append(series.Histograms, h)
+			p.Histograms++
+
+		case rw2SeriesExemplarsField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exemplars", typ)
+			}
+			e, err := unmarshalRW2Exemplar(value, symbols)
+			if err != nil {
+				return err
+			}
+			series.Exemplars = append(series.Exemplars, e)
+			p.Exemplars++
+
+		case rw2SeriesMetadataField:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", typ)
+			}
+			m, err := unmarshalRW2Metadata(value, symbols)
+			if err != nil {
+				return err
+			}
+			metadata = m
+
+		case rw2SeriesCreatedTimestampField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedTimestamp", typ)
+			}
+			v, n := protowire.ConsumeVarint(value)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			createdTs = int64(v)
+		}
+		return nil
+	})
+	return metadata, createdTs, err
+}
+
+// injectCreatedTimestampZeroSample prepends a zero sample (or histogram) at the created timestamp of the series,
+// to mark the start of a counter or histogram, if the created timestamp is within the configured window.
+func (p *PreallocWriteRequestV2) injectCreatedTimestampZeroSample(series *TimeSeries, createdTs int64) {
+	if createdTs <= 0 || p.CreatedTimestampZeroSampleWindowMs <= 0 {
+		return
+	}
+	inWindow := func(firstTs int64) bool {
+		return createdTs < firstTs && firstTs-createdTs <= p.CreatedTimestampZeroSampleWindowMs
+	}
+
+	if len(series.Samples) > 0 && inWindow(series.Samples[0].TimestampMs) {
+		series.Samples = append(series.Samples, Sample{})
+		copy(series.Samples[1:], series.Samples)
+		series.Samples[0] = Sample{TimestampMs: createdTs}
+	}
+
+	if len(series.Histograms) > 0 && inWindow(series.Histograms[0].Timestamp) {
+		first := series.Histograms[0]
+		var zero Histogram
+		if first.IsFloatHistogram() {
+			zero = FromFloatHistogramToHistogramProto(createdTs, &histogram.FloatHistogram{Schema: first.Schema, ZeroThreshold: first.ZeroThreshold})
+		} else {
+			zero = FromHistogramToHistogramProto(createdTs, &histogram.Histogram{Schema: first.Schema, ZeroThreshold: first.ZeroThreshold})
+		}
+		series.Histograms = append(series.Histograms, Histogram{})
+		copy(series.Histograms[1:], series.Histograms)
+		series.Histograms[0] = zero
+	}
+}
+
+func unmarshalRW2Symbols(dAtA []byte) ([]string, error) {
+	var symbols []string
+	err := rangeRW2Fields(dAtA, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != rw2RequestSymbolsField {
+			return nil
+		}
+		if typ != protowire.BytesType {
+			return fmt.Errorf("proto: wrong wireType = %d for field Symbols", typ)
+		}
+		symbols = append(symbols, yoloString(value))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) > 0 && symbols[0] != "" {
+		return nil, errors.New("the first symbol of a remote-write 2.0 request must be an empty string")
+	}
+	return symbols, nil
+}
+
+func unmarshalRW2Exemplar(dAtA []byte, symbols []string) (Exemplar, error) {
+	var e Exemplar
+	err := rangeRW2Fields(dAtA, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case rw2ExemplarLabelsRefsField:
+			refs, err := unmarshalRW2Refs(typ, value, nil)
+			if err != nil {
+				return err
+			}
+			e.Labels, err = resolveRW2Labels(refs, symbols, e.Labels)
+			return err
+		case rw2ExemplarValueField:
+			if typ != protowire.Fixed64Type {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", typ)
+			}
+			v, n := protowire.ConsumeFixed64(value)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			e.Value = math.Float64frombits(v)
+		case rw2ExemplarTimestampField:
+			if typ != protowire.VarintType {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", typ)
+			}
+			v, n := protowire.ConsumeVarint(value)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			e.TimestampMs = int64(v)
+		}
+		return nil
+	})
+	return e, err
+}
+
+func unmarshalRW2Metadata(dAtA []byte, symbols []string) (*MetricMetadata, error) {
+	var m MetricMetadata
+	err := rangeRW2Fields(dAtA, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != rw2MetadataTypeField && num != rw2MetadataHelpRefField && num != rw2MetadataUnitRefField {
+			return nil
+		}
+		if typ != protowire.VarintType {
+			return fmt.Errorf("proto: wrong wireType = %d for metadata field %d", typ, num)
+		}
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		switch num {
+		case rw2MetadataTypeField:
+			// The metric types of remote-write 2.0 have the same values as MetricMetadata_MetricType.
+			m.Type = MetricMetadata_MetricType(v)
+		case rw2MetadataHelpRefField:
+			if v >= uint64(len(symbols)) {
+				return fmt.Errorf("help reference %d is out of the symbols table range", v)
+			}
+			m.Help = symbols[v]
+		case rw2MetadataUnitRefField:
+			if v >= uint64(len(symbols)) {
+				return fmt.Errorf("unit reference %d is out of the symbols table range", v)
+			}
+			m.Unit = symbols[v]
+		}
+		return nil
+	})
+	if err != nil || (m.Type == UNKNOWN && m.Help == "" && m.Unit == "") {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// unmarshalRW2Refs appends the symbol references, either packed or not, to dst.
+func unmarshalRW2Refs(typ protowire.Type, value []byte, dst []uint32) ([]uint32, error) {
+	switch typ {
+	case protowire.VarintType:
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		return append(dst, uint32(v)), nil
+	case protowire.BytesType:
+		for len(value) > 0 {
+			v, n := protowire.ConsumeVarint(value)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			dst = append(dst, uint32(v))
+			value = value[n:]
+		}
+		return dst, nil
+	default:
+		return nil, fmt.Errorf("proto: wrong wireType = %d for field LabelsRefs", typ)
+	}
+}
+
+// resolveRW2Labels appends the labels referenced by pairs of name and value references to dst.
+func resolveRW2Labels(refs []uint32, symbols []string, dst []LabelAdapter) ([]LabelAdapter, error) {
+	if len(refs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of label references: %d", len(refs))
+	}
+	for i := 0; i < len(refs); i += 2 {
+		nameRef, valueRef := refs[i], refs[i+1]
+		if int(nameRef) >= len(symbols) || int(valueRef) >= len(symbols) {
+			return nil, fmt.Errorf("label reference %d=%d is out of the symbols table range", nameRef, valueRef)
+		}
+		dst = append(dst, LabelAdapter{Name: symbols[nameRef], Value: symbols[valueRef]})
+	}
+	return dst, nil
+}
+
+// unmarshalRW2Message unmarshals an embedded message whose wire format is shared with remote-write 1.0.
+func unmarshalRW2Message(typ protowire.Type, value []byte, unmarshal func([]byte) error) error {
+	if typ != protowire.BytesType {
+		return fmt.Errorf("proto: wrong wireType = %d for an embedded message", typ)
+	}
+	return unmarshal(value)
+}
+
+// rangeRW2Fields calls f for each field of the message. For length-delimited fields the value is the content
+// of the field, while for the other types it's the raw encoded value.
+func rangeRW2Fields(dAtA []byte, f func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		value := dAtA[:n]
+		if typ == protowire.BytesType {
+			var m int
+			value, m = protowire.ConsumeBytes(value)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+		}
+		dAtA = dAtA[n:]
+
+		if err := f(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/grafana/mimir/pkg/util/test"
+)
+
+func TestPreallocWriteRequestV2_Unmarshal(t *testing.T) {
+	symbols := []string{"", "__name__", "http_requests_total", "job", "api", "trace_id", "abc", "Total HTTP requests.", "requests"}
+	histogram := FromFloatHistogramToHistogramProto(1100, test.GenerateTestFloatHistogram(1))
+
+	series := rw2Series{
+		labelsRefs: []uint32{1, 2, 3, 4},
+		samples:    []Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}},
+		histograms: []Histogram{histogram},
+		exemplars:  []rw2Exemplar{{labelsRefs: []uint32{5, 6}, value: 1.5, timestamp: 1000}},
+		metadata:   &rw2Metadata{metricType: uint64(COUNTER), helpRef: 7, unitRef: 8},
+		createdTs:  500,
+	}
+	// A series of the same metric family, whose metadata shouldn't be duplicated.
+	other := rw2Series{
+		labelsRefs: []uint32{1, 2, 3, 2},
+		samples:    []Sample{{TimestampMs: 1000, Value: 3}},
+		metadata:   &rw2Metadata{metricType: uint64(COUNTER), helpRef: 7, unitRef: 8},
+	}
+	// Timeseries are encoded before the symbols table, which is valid protobuf.
+	body := encodeRW2Request(symbols, series, other)
+
+	t.Run("without created timestamp zero samples", func(t *testing.T) {
+		req := &PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+		require.NoError(t, req.Unmarshal(body))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Len(t, req.Timeseries, 2)
+		assert.Equal(t, []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "api"}}, req.Timeseries[0].Labels)
+		assert.Equal(t, series.samples, req.Timeseries[0].Samples)
+		assert.Equal(t, []Histogram{histogram}, req.Timeseries[0].Histograms)
+		assert.Equal(t, []Exemplar{{Labels: []LabelAdapter{{Name: "trace_id", Value: "abc"}}, Value: 1.5, TimestampMs: 1000}}, req.Timeseries[0].Exemplars)
+		assert.Equal(t, []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "http_requests_total"}}, req.Timeseries[1].Labels)
+		assert.Equal(t, []*MetricMetadata{{Type: COUNTER, MetricFamilyName: "http_requests_total", Help: "Total HTTP requests.", Unit: "requests"}}, req.Metadata)
+
+		assert.Equal(t, 3, req.Samples)
+		assert.Equal(t, 1, req.Histograms)
+		assert.Equal(t, 1, req.Exemplars)
+	})
+
+	t.Run("with created timestamp zero samples", func(t *testing.T) {
+		req := &PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}, CreatedTimestampZeroSampleWindowMs: 1000}
+		require.NoError(t, req.Unmarshal(body))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		require.Len(t, req.Timeseries, 2)
+		assert.Equal(t, []Sample{{TimestampMs: 500, Value: 0}, {TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}}, req.Timeseries[0].Samples)
+		require.Len(t, req.Timeseries[0].Histograms, 2)
+		assert.Equal(t, int64(500), req.Timeseries[0].Histograms[0].Timestamp)
+		assert.True(t, req.Timeseries[0].Histograms[0].IsFloatHistogram())
+		assert.Zero(t, req.Timeseries[0].Histograms[0].GetCountFloat())
+		assert.Equal(t, histogram.Schema, req.Timeseries[0].Histograms[0].Schema)
+		assert.Equal(t, 3, req.Samples)
+	})
+
+	t.Run("created timestamp outside of the window", func(t *testing.T) {
+		req := &PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}, CreatedTimestampZeroSampleWindowMs: 100}
+		require.NoError(t, req.Unmarshal(body))
+		t.Cleanup(func() { ReuseSlice(req.Timeseries) })
+
+		assert.Equal(t, series.samples, req.Timeseries[0].Samples)
+	})
+}
+
+func TestPreallocWriteRequestV2_UnmarshalInvalid(t *testing.T) {
+	tests := map[string]struct {
+		body        []byte
+		expectedErr string
+	}{
+		"first symbol is not empty": {
+			body:        encodeRW2Request([]string{"__name__", "up"}),
+			expectedErr: "the first symbol of a remote-write 2.0 request must be an empty string",
+		},
+		"odd number of label references": {
+			body:        encodeRW2Request([]string{"", "__name__", "up"}, rw2Series{labelsRefs: []uint32{1, 2, 1}}),
+			expectedErr: "odd number of label references: 3",
+		},
+		"label reference out of range": {
+			body:        encodeRW2Request([]string{"", "__name__", "up"}, rw2Series{labelsRefs: []uint32{1, 3}}),
+			expectedErr: "label reference 1=3 is out of the symbols table range",
+		},
+		"help reference out of range": {
+			body:        encodeRW2Request([]string{"", "__name__", "up"}, rw2Series{labelsRefs: []uint32{1, 2}, metadata: &rw2Metadata{helpRef: 10}}),
+			expectedErr: "help reference 10 is out of the symbols table range",
+		},
+		"custom buckets histogram": {
+			body:        encodeRW2Request([]string{"", "__name__", "up"}, rw2Series{labelsRefs: []uint32{1, 2}, histograms: []Histogram{{Schema: -53}}}),
+			expectedErr: "native histograms with custom buckets are not supported",
+		},
+		"truncated message": {
+			body:        encodeRW2Request([]string{"", "__name__", "up"}, rw2Series{labelsRefs: []uint32{1, 2}})[:5],
+			expectedErr: "unexpected EOF",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+			assert.ErrorContains(t, req.Unmarshal(tc.body), tc.expectedErr)
+		})
+	}
+}
+
+type rw2Series struct {
+	labelsRefs []uint32
+	samples    []Sample
+	histograms []Histogram
+	exemplars  []rw2Exemplar
+	metadata   *rw2Metadata
+	createdTs  int64
+}
+
+type rw2Exemplar struct {
+	labelsRefs []uint32
+	value      float64
+	timestamp  int64
+}
+
+type rw2Metadata struct {
+	metricType, helpRef, unitRef uint64
+}
+
+// encodeRW2Request encodes an io.prometheus.write.v2.Request, with the timeseries before the symbols.
+func encodeRW2Request(symbols []string, series ...rw2Series) []byte {
+	var b []byte
+	for _, s := range series {
+		var sb []byte
+		sb = appendRW2Refs(sb, rw2SeriesLabelsRefsField, s.labelsRefs)
+		for _, sample := range s.samples {
+			m, _ := sample.Marshal()
+			sb = protowire.AppendTag(sb, rw2SeriesSamplesField, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, m)
+		}
+		for _, h := range s.histograms {
+			m, _ := h.Marshal()
+			sb = protowire.AppendTag(sb, rw2SeriesHistogramsField, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, m)
+		}
+		for _, e := range s.exemplars {
+			var eb []byte
+			eb = appendRW2Refs(eb, rw2ExemplarLabelsRefsField, e.labelsRefs)
+			eb = protowire.AppendTag(eb, rw2ExemplarValueField, protowire.Fixed64Type)
+			eb = protowire.AppendFixed64(eb, math.Float64bits(e.value))
+			eb = protowire.AppendTag(eb, rw2ExemplarTimestampField, protowire.VarintType)
+			eb = protowire.AppendVarint(eb, uint64(e.timestamp))
+			sb = protowire.AppendTag(sb, rw2SeriesExemplarsField, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, eb)
+		}
+		if s.metadata != nil {
+			var mb []byte
+			mb = protowire.AppendTag(mb, rw2MetadataTypeField, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, s.metadata.metricType)
+			mb = protowire.AppendTag(mb, rw2MetadataHelpRefField, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, s.metadata.helpRef)
+			mb = protowire.AppendTag(mb, rw2MetadataUnitRefField, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, s.metadata.unitRef)
+			sb = protowire.AppendTag(sb, rw2SeriesMetadataField, protowire.BytesType)
+			sb = protowire.AppendBytes(sb, mb)
+		}
+		if s.createdTs != 0 {
+			sb = protowire.AppendTag(sb, rw2SeriesCreatedTimestampField, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(s.createdTs))
+		}
+		b = protowire.AppendTag(b, rw2RequestTimeseriesField, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	for _, s := range symbols {
+		b = protowire.AppendTag(b, rw2RequestSymbolsField, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+func appendRW2Refs(b []byte, num protowire.Number, refs []uint32) []byte {
+	var packed []byte
+	for _, r := range refs {
+		packed = protowire.AppendVarint(packed, uint64(r))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
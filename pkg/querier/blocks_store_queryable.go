@@ -6,6 +6,7 @@
 package querier
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/util/annotations"
@@ -34,6 +36,7 @@ import (
 	grpc_metadata "google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	"github.com/grafana/mimir/pkg/storage/series"
@@ -96,6 +99,7 @@ type BlocksStoreLimits interface {
 	MaxLabelsQueryLength(userID string) time.Duration
 	MaxChunksPerQuery(userID string) int
 	StoreGatewayTenantShardSize(userID string) int
+	StoreGatewayPartialResponseEnabled(userID string) bool
 }
 
 type blocksStoreQueryableMetrics struct {
@@ -105,6 +109,7 @@ type blocksStoreQueryableMetrics struct {
 	blocksFound                                       prometheus.Counter
 	blocksQueried                                     prometheus.Counter
 	blocksWithCompactorShardButIncompatibleQueryShard prometheus.Counter
+	partialResponses                                  prometheus.Counter
 }
 
 func newBlocksStoreQueryableMetrics(reg prometheus.Registerer) *blocksStoreQueryableMetrics {
@@ -134,6 +139,10 @@ func newBlocksStoreQueryableMetrics(reg prometheus.Registerer) *blocksStoreQuery
 			Name: "cortex_querier_blocks_with_compactor_shard_but_incompatible_query_shard_total",
 			Help: "Blocks that couldn't be checked for query and compactor sharding optimization due to incompatible shard counts.",
 		}),
+		partialResponses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_querier_storegateway_partial_responses_total",
+			Help: "Number of queries which returned partial results because some blocks couldn't be fetched from the store-gateways.",
+		}),
 	}
 }
 
@@ -151,6 +160,9 @@ type BlocksStoreQueryable struct {
 	limits                   BlocksStoreLimits
 	streamingChunksBatchSize uint64
 
+	// Optional. If set, the samples deleted by series deletion requests are filtered out.
+	seriesDeletions *seriesDeletionRequestsLoader
+
 	// Subservices manager.
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
@@ -268,7 +280,16 @@ func NewBlocksStoreQueryableFromConfig(querierCfg Config, gatewayCfg storegatewa
 		streamingBufferSize = 0
 	}
 
-	return NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg.QueryStoreAfter, streamingBufferSize, logger, reg)
+	q, err := NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg.QueryStoreAfter, streamingBufferSize, logger, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	if querierCfg.SeriesDeletionRequestsSyncInterval > 0 {
+		q.seriesDeletions = newSeriesDeletionRequestsLoader(bucketClient, querierCfg.SeriesDeletionRequestsSyncInterval, logger)
+	}
+
+	return q, nil
 }
 
 func (q *BlocksStoreQueryable) starting(ctx context.Context) error {
@@ -302,6 +323,22 @@ func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error
 		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
 	}
 
+	return q.newQuerier(mint, maxt), nil
+}
+
+// ExemplarQuerier returns a new storage.ExemplarQuerier, querying the exemplars stored in the blocks.
+func (q *BlocksStoreQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	if s := q.State(); s != services.Running {
+		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	return &blocksStoreExemplarQuerier{
+		ctx:       ctx,
+		queryable: q,
+	}, nil
+}
+
+func (q *BlocksStoreQueryable) newQuerier(mint, maxt int64) *blocksStoreQuerier {
 	return &blocksStoreQuerier{
 		minT:                     mint,
 		maxT:                     maxt,
@@ -311,9 +348,10 @@ func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error
 		limits:                   q.limits,
 		streamingChunksBatchSize: q.streamingChunksBatchSize,
 		consistency:              q.consistency,
+		seriesDeletions:          q.seriesDeletions,
 		logger:                   q.logger,
 		queryStoreAfter:          q.queryStoreAfter,
-	}, nil
+	}
 }
 
 type blocksStoreQuerier struct {
@@ -324,6 +362,7 @@ type blocksStoreQuerier struct {
 	consistency              *BlocksConsistencyChecker
 	limits                   BlocksStoreLimits
 	streamingChunksBatchSize uint64
+	seriesDeletions          *seriesDeletionRequestsLoader
 	logger                   log.Logger
 
 	// If set, the querier manipulates the max time to not be greater than
@@ -331,6 +370,21 @@ type blocksStoreQuerier struct {
 	queryStoreAfter time.Duration
 }
 
+type blocksStoreExemplarQuerier struct {
+	ctx       context.Context
+	queryable *BlocksStoreQueryable
+}
+
+// Select implements storage.ExemplarQuerier interface.
+func (q *blocksStoreExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	series, err := q.queryable.newQuerier(start, end).Exemplars(q.ctx, matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromExemplarsTimeSeriesToQueryResults(series), nil
+}
+
 // Select implements storage.Querier interface.
 // The bool passed is ignored because the series is always sorted.
 func (q *blocksStoreQuerier) Select(ctx context.Context, _ bool, sp *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
@@ -380,11 +434,26 @@ func (q *blocksStoreQuerier) LabelNames(ctx context.Context, matchers ...*labels
 		return queriedBlocks, nil
 	}
 
-	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, queryF); err != nil {
+	partialResponseWarnings, err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, q.limits.StoreGatewayPartialResponseEnabled(tenantID), queryF)
+	if err != nil {
+		return nil, nil, err
+	}
+	resWarnings.Merge(partialResponseWarnings)
+
+	names := util.MergeSlices(resNameSets...)
+
+	// Filter out the label names only the series deleted by series deletion requests have.
+	deleted, raw, err := q.deletedSeriesForLabels(ctx, tenantID, minT, maxT, matchers)
+	if err != nil {
 		return nil, nil, err
 	}
+	if len(deleted) > 0 {
+		if names, err = removeLabelNamesOfDeletedSeries(ctx, raw, minT, maxT, names, matchers, deleted); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	return util.MergeSlices(resNameSets...), resWarnings, nil
+	return names, resWarnings, nil
 }
 
 func (q *blocksStoreQuerier) LabelValues(ctx context.Context, name string, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
@@ -424,17 +493,73 @@ func (q *blocksStoreQuerier) LabelValues(ctx context.Context, name string, match
 		return queriedBlocks, nil
 	}
 
-	if err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, queryF); err != nil {
+	partialResponseWarnings, err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, q.limits.StoreGatewayPartialResponseEnabled(tenantID), queryF)
+	if err != nil {
+		return nil, nil, err
+	}
+	resWarnings.Merge(partialResponseWarnings)
+
+	values := util.MergeSlices(resValueSets...)
+
+	// Filter out the label values only the series deleted by series deletion requests have.
+	deleted, raw, err := q.deletedSeriesForLabels(ctx, tenantID, minT, maxT, matchers)
+	if err != nil {
 		return nil, nil, err
 	}
+	if len(deleted) > 0 {
+		if values, err = removeLabelValuesOfDeletedSeries(ctx, raw, minT, maxT, name, values, matchers, deleted); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	return util.MergeSlices(resValueSets...), resWarnings, nil
+	return values, resWarnings, nil
 }
 
 func (q *blocksStoreQuerier) Close() error {
 	return nil
 }
 
+// Exemplars returns the exemplars stored in the blocks for the input series selectors.
+func (q *blocksStoreQuerier) Exemplars(ctx context.Context, matchers ...[]*labels.Matcher) ([]mimirpb.TimeSeries, error) {
+	spanLog, ctx := spanlogger.NewWithLogger(ctx, q.logger, "blocksStoreQuerier.Exemplars")
+	defer spanLog.Span.Finish()
+
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	minT, maxT := q.minT, q.maxT
+
+	level.Debug(spanLog).Log("start", util.TimeFromMillis(minT).UTC().String(), "end",
+		util.TimeFromMillis(maxT).UTC().String(), "matchers", util.MultiMatchersStringer(matchers))
+
+	convertedMatchers := make([]storepb.ExemplarMatchers, 0, len(matchers))
+	for _, m := range matchers {
+		convertedMatchers = append(convertedMatchers, storepb.ExemplarMatchers{Matchers: convertMatchersToLabelMatcher(m)})
+	}
+
+	var resSets [][]mimirpb.TimeSeries
+
+	queryF := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error) {
+		sets, queriedBlocks, err := q.fetchExemplarsFromStore(ctx, clients, minT, maxT, tenantID, convertedMatchers)
+		if err != nil {
+			return nil, err
+		}
+
+		resSets = append(resSets, sets...)
+
+		return queriedBlocks, nil
+	}
+
+	// Partial responses are not allowed, because the exemplars API has no way to report them with a warning.
+	if _, err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, nil, false, queryF); err != nil {
+		return nil, err
+	}
+
+	return block.MergeExemplars(resSets...), nil
+}
+
 func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.SelectHints, tenantID string, matchers ...*labels.Matcher) storage.SeriesSet {
 	spanLog, ctx := spanlogger.NewWithLogger(ctx, q.logger, "blocksStoreQuerier.selectSorted")
 	defer spanLog.Span.Finish()
@@ -469,10 +594,11 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		return queriedBlocks, nil
 	}
 
-	err = q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, shard, queryF)
+	partialResponseWarnings, err := q.queryWithConsistencyCheck(ctx, spanLog, minT, maxT, tenantID, shard, q.limits.StoreGatewayPartialResponseEnabled(tenantID), queryF)
 	if err != nil {
 		return storage.ErrSeriesSet(err)
 	}
+	resWarnings.Merge(partialResponseWarnings)
 
 	if len(streamStarters) > 0 {
 		level.Debug(spanLog).Log("msg", "starting streaming")
@@ -500,22 +626,80 @@ func (q *blocksStoreQuerier) selectSorted(ctx context.Context, sp *storage.Selec
 		storage.EmptySeriesSet()
 	}
 
-	return series.NewSeriesSetWithWarnings(
-		storage.NewMergeSeriesSet(resSeriesSets, storage.ChainedSeriesMerge),
-		resWarnings)
+	var resSeriesSet storage.SeriesSet = storage.NewMergeSeriesSet(resSeriesSets, storage.ChainedSeriesMerge)
+
+	// Filter out the samples deleted by series deletion requests, which may not have been removed from the blocks yet.
+	resSeriesSet, err = q.filterSeriesDeletions(ctx, sp, tenantID, minT, maxT, matchers, resSeriesSet)
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+
+	return series.NewSeriesSetWithWarnings(resSeriesSet, resWarnings)
+}
+
+// filterSeriesDeletions filters out the samples deleted by the tenant's series deletion requests from the input
+// set, and the series whose samples are all deleted.
+func (q *blocksStoreQuerier) filterSeriesDeletions(ctx context.Context, sp *storage.SelectHints, tenantID string, minT, maxT int64, matchers []*labels.Matcher, set storage.SeriesSet) (storage.SeriesSet, error) {
+	if q.seriesDeletions == nil {
+		return set, nil
+	}
+
+	deletions, err := q.seriesDeletions.getDeletions(ctx, tenantID, minT, maxT)
+	if err != nil || len(deletions) == 0 {
+		return set, err
+	}
+
+	if sp == nil || sp.Func != "series" {
+		return newSeriesDeletionSeriesSet(set, deletions), nil
+	}
+
+	// The series are fetched without samples, so the deleted series are found querying their samples.
+	deleted, err := findDeletedSeries(ctx, q.withoutSeriesDeletions(), minT, maxT, matchers, deletions)
+	if err != nil || len(deleted) == 0 {
+		return set, err
+	}
+	return &deletedSeriesFilterSeriesSet{SeriesSet: set, deleted: deleted}, nil
+}
+
+// deletedSeriesForLabels returns the series matching the input matchers whose samples within [minT, maxT] are
+// all deleted by the tenant's series deletion requests, and the querier to use to filter out their labels.
+func (q *blocksStoreQuerier) deletedSeriesForLabels(ctx context.Context, tenantID string, minT, maxT int64, matchers []*labels.Matcher) (map[string]labels.Labels, *blocksStoreQuerier, error) {
+	if q.seriesDeletions == nil {
+		return nil, nil, nil
+	}
+
+	deletions, err := q.seriesDeletions.getDeletions(ctx, tenantID, minT, maxT)
+	if err != nil || len(deletions) == 0 {
+		return nil, nil, err
+	}
+
+	raw := q.withoutSeriesDeletions()
+	raw.minT = minT
+	deleted, err := findDeletedSeries(ctx, raw, minT, maxT, matchers, deletions)
+	return deleted, raw, err
+}
+
+// withoutSeriesDeletions returns a copy of the querier which doesn't filter out the series deletions.
+func (q *blocksStoreQuerier) withoutSeriesDeletions() *blocksStoreQuerier {
+	raw := *q
+	raw.seriesDeletions = nil
+	return &raw
 }
 
 type queryFunc func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error)
 
+// queryWithConsistencyCheck runs queryF until all the expected blocks have been queried, retrying the missing
+// blocks on other store-gateways. If some blocks are still missing after all retries, it fails or, if
+// allowPartialResponse is true, it returns a warning listing the time ranges of the missing blocks.
 func (q *blocksStoreQuerier) queryWithConsistencyCheck(
-	ctx context.Context, logger log.Logger, minT, maxT int64, tenantID string, shard *sharding.ShardSelector, queryF queryFunc,
-) error {
+	ctx context.Context, logger log.Logger, minT, maxT int64, tenantID string, shard *sharding.ShardSelector, allowPartialResponse bool, queryF queryFunc,
+) (annotations.Annotations, error) {
 	now := time.Now()
 
 	if !ShouldQueryBlockStore(q.queryStoreAfter, now, minT) {
 		q.metrics.storesHit.Observe(0)
 		level.Debug(logger).Log("msg", "not querying block store; query time range begins after the query-store-after limit")
-		return nil
+		return nil, nil
 	}
 
 	maxT = clampMaxTime(logger, maxT, now.UnixMilli(), -q.queryStoreAfter, "query store after")
@@ -523,13 +707,13 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 	// Find the list of blocks we need to query given the time range.
 	knownBlocks, knownDeletionMarks, err := q.finder.GetBlocks(ctx, tenantID, minT, maxT)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(knownBlocks) == 0 {
 		q.metrics.storesHit.Observe(0)
 		level.Debug(logger).Log("msg", "no blocks found")
-		return nil
+		return nil, nil
 	}
 
 	q.metrics.blocksFound.Add(float64(len(knownBlocks)))
@@ -564,13 +748,14 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 		clients, err := q.stores.GetClientsFor(tenantID, remainingBlocks, attemptedBlocks)
 		if err != nil {
 			// If it's a retry and we get an error, it means there are no more store-gateways left
-			// from which running another attempt, so we're just stopping retrying.
-			if attempt > 1 {
+			// from which running another attempt, so we're just stopping retrying. The same applies
+			// to the first attempt when partial responses are allowed, as no block can be fetched.
+			if attempt > 1 || allowPartialResponse {
 				level.Warn(logger).Log("msg", "unable to get store-gateway clients while retrying to fetch missing blocks", "err", err)
 				break
 			}
 
-			return err
+			return nil, err
 		}
 		level.Debug(logger).Log("msg", "found store-gateway instances to query", "num instances", len(clients), "attempt", attempt)
 
@@ -578,7 +763,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 		// are only meant to cover missing blocks.
 		queriedBlocks, err := queryF(clients, minT, maxT)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		level.Debug(logger).Log("msg", "received series from all store-gateways", "queried blocks", strings.Join(convertULIDsToString(queriedBlocks), " "))
 
@@ -599,7 +784,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 			q.metrics.storesHit.Observe(float64(len(touchedStores)))
 			q.metrics.refetches.Observe(float64(attempt - 1))
 
-			return nil
+			return nil, nil
 		}
 
 		level.Debug(logger).Log("msg", "consistency check failed", "attempt", attempt, "missing blocks", strings.Join(convertULIDsToString(missingBlocks), " "))
@@ -609,14 +794,61 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(
 	}
 
 	// We've not been able to query all expected blocks after all retries.
+	if allowPartialResponse {
+		level.Warn(util_log.WithContext(ctx, logger)).Log("msg", "failed consistency check, returning a partial response", "missing blocks", strings.Join(convertULIDsToString(remainingBlocks), " "))
+		q.metrics.partialResponses.Inc()
+
+		var warnings annotations.Annotations
+		warnings.Add(newStoreGatewayPartialResponseWarning(knownBlocks, remainingBlocks))
+		return warnings, nil
+	}
+
 	level.Warn(util_log.WithContext(ctx, logger)).Log("msg", "failed consistency check", "err", err)
-	return newStoreConsistencyCheckFailedError(remainingBlocks)
+	return nil, newStoreConsistencyCheckFailedError(remainingBlocks)
 }
 
 func newStoreConsistencyCheckFailedError(remainingBlocks []ulid.ULID) error {
 	return fmt.Errorf("%v. The failed blocks are: %s", globalerror.StoreConsistencyCheckFailed.Message("failed to fetch some blocks"), strings.Join(convertULIDsToString(remainingBlocks), " "))
 }
 
+// newStoreGatewayPartialResponseWarning returns the warning of a partial response, listing the time ranges
+// of the missing blocks. The overlapping or adjacent time ranges are merged.
+func newStoreGatewayPartialResponseWarning(knownBlocks bucketindex.Blocks, missingBlocks []ulid.ULID) error {
+	missing := make(map[ulid.ULID]struct{}, len(missingBlocks))
+	for _, id := range missingBlocks {
+		missing[id] = struct{}{}
+	}
+
+	var ranges [][2]int64
+	for _, b := range knownBlocks {
+		if _, ok := missing[b.ID]; ok {
+			ranges = append(ranges, [2]int64{b.MinTime, b.MaxTime})
+		}
+	}
+	slices.SortFunc(ranges, func(a, b [2]int64) int {
+		return cmp.Compare(a[0], b[0])
+	})
+
+	var merged [][2]int64
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r[0] <= merged[last][1] {
+			merged[last][1] = max(merged[last][1], r[1])
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	formatted := make([]string, 0, len(merged))
+	for _, r := range merged {
+		formatted = append(formatted, fmt.Sprintf("%s to %s",
+			util.TimeFromMillis(r[0]).UTC().Format(time.RFC3339),
+			util.TimeFromMillis(r[1]).UTC().Format(time.RFC3339)))
+	}
+
+	return errors.New(globalerror.StoreGatewayPartialResponse.Message(
+		"partial response: failed to fetch some blocks from the store-gateways, the results are missing the samples in the time ranges " + strings.Join(formatted, ", ")))
+}
+
 // filterBlocksByShard removes blocks that can be safely ignored when using query sharding.
 // We know that block can be safely ignored, if it was compacted using split-and-merge
 // compactor, and it has a valid compactor shard ID. We exploit the fact that split-and-merge
@@ -1020,6 +1252,84 @@ func (q *blocksStoreQuerier) fetchLabelNamesFromStore(
 	return nameSets, warnings, queriedBlocks, nil
 }
 
+func (q *blocksStoreQuerier) fetchExemplarsFromStore(
+	ctx context.Context,
+	clients map[BlocksStoreClient][]ulid.ULID,
+	minT int64,
+	maxT int64,
+	tenantID string,
+	matchers []storepb.ExemplarMatchers,
+) ([][]mimirpb.TimeSeries, []ulid.ULID, error) {
+	var (
+		reqCtx        = grpc_metadata.AppendToOutgoingContext(ctx, storegateway.GrpcContextMetadataTenantID, tenantID)
+		g, gCtx       = errgroup.WithContext(reqCtx)
+		mtx           = sync.Mutex{}
+		sets          = [][]mimirpb.TimeSeries{}
+		queriedBlocks = []ulid.ULID(nil)
+		spanLog       = spanlogger.FromContext(ctx, q.logger)
+	)
+
+	// Concurrently fetch exemplars from all clients.
+	for c, blockIDs := range clients {
+		// Change variables scope since it will be used in a goroutine.
+		c := c
+		blockIDs := blockIDs
+
+		g.Go(func() error {
+			req, err := createExemplarsRequest(minT, maxT, blockIDs, matchers)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create exemplars request")
+			}
+
+			exemplarsResp, err := c.Exemplars(gCtx, req)
+			if err != nil {
+				if shouldStopQueryFunc(err) {
+					return err
+				}
+
+				level.Warn(spanLog).Log("msg", "failed to fetch exemplars", "remote", c.RemoteAddress(), "err", err)
+				return nil
+			}
+
+			myQueriedBlocks := []ulid.ULID(nil)
+			if exemplarsResp.Hints != nil {
+				hints := hintspb.ExemplarsResponseHints{}
+				if err := types.UnmarshalAny(exemplarsResp.Hints, &hints); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal exemplars hints from %s", c.RemoteAddress())
+				}
+
+				ids, err := convertBlockHintsToULIDs(hints.QueriedBlocks)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse queried block IDs from received hints")
+				}
+
+				myQueriedBlocks = ids
+			}
+
+			level.Debug(spanLog).Log("msg", "received exemplars from store-gateway",
+				"instance", c,
+				"num series", len(exemplarsResp.Timeseries),
+				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
+				"queried blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+
+			// Store the result.
+			mtx.Lock()
+			sets = append(sets, exemplarsResp.Timeseries)
+			queriedBlocks = append(queriedBlocks, myQueriedBlocks...)
+			mtx.Unlock()
+
+			return nil
+		})
+	}
+
+	// Wait until all client requests complete.
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return sets, queriedBlocks, nil
+}
+
 func (q *blocksStoreQuerier) fetchLabelValuesFromStore(
 	ctx context.Context,
 	name string,
@@ -1164,6 +1474,34 @@ func createLabelNamesRequest(minT, maxT int64, blockIDs []ulid.ULID, matchers []
 	return req, nil
 }
 
+func createExemplarsRequest(minT, maxT int64, blockIDs []ulid.ULID, matchers []storepb.ExemplarMatchers) (*storepb.ExemplarsRequest, error) {
+	req := &storepb.ExemplarsRequest{
+		Start:    minT,
+		End:      maxT,
+		Matchers: matchers,
+	}
+
+	// Selectively query only specific blocks.
+	hints := &hintspb.ExemplarsRequestHints{
+		BlockMatchers: []storepb.LabelMatcher{
+			{
+				Type:  storepb.LabelMatcher_RE,
+				Name:  block.BlockIDLabel,
+				Value: strings.Join(convertULIDsToString(blockIDs), "|"),
+			},
+		},
+	}
+
+	anyHints, err := types.MarshalAny(hints)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal exemplars request hints")
+	}
+
+	req.Hints = anyHints
+
+	return req, nil
+}
+
 func createLabelValuesRequest(minT, maxT int64, label string, blockIDs []ulid.ULID, matchers ...*labels.Matcher) (*storepb.LabelValuesRequest, error) {
 	req := &storepb.LabelValuesRequest{
 		Start:    minT,
@@ -81,6 +81,7 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 		limits            BlocksStoreLimits
 		queryLimiter      *limiter.QueryLimiter
 		expectedSeries    []seriesResult
+		expectedWarnings  []string
 		expectedErr       error
 		expectedMetrics   string
 		queryShardID      string
@@ -382,6 +383,54 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 			queryLimiter: noOpQueryLimiter,
 			expectedErr:  newStoreConsistencyCheckFailedError([]ulid.ULID{block3, block4}),
 		},
+		"multiple store-gateway instances have some missing blocks (consistency check failed) and partial responses are enabled": {
+			finderResult: bucketindex.Blocks{
+				{ID: block1, MinTime: 0, MaxTime: 2 * time.Hour.Milliseconds()},
+				{ID: block2, MinTime: 0, MaxTime: 2 * time.Hour.Milliseconds()},
+				{ID: block3, MinTime: 2 * time.Hour.Milliseconds(), MaxTime: 4 * time.Hour.Milliseconds()},
+				{ID: block4, MinTime: 4 * time.Hour.Milliseconds(), MaxTime: 6 * time.Hour.Milliseconds()},
+			},
+			storeSetResponses: []interface{}{
+				// First attempt returns a client whose response does not include all expected blocks.
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedSeriesResponses: []*storepb.SeriesResponse{
+						mockSeriesResponse(metricNameLabel, minT+1, 2),
+						mockHintsResponse(block1),
+					}}: {block1},
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedSeriesResponses: []*storepb.SeriesResponse{
+						mockSeriesResponse(metricNameLabel, minT+1, 2),
+						mockHintsResponse(block2),
+					}}: {block2},
+				},
+				// Second attempt returns an error because there are no other store-gateways left.
+				errors.New("no store-gateway remaining after exclude"),
+			},
+			limits:       &blocksStoreLimitsMock{storeGatewayPartialResponseEnabled: true},
+			queryLimiter: noOpQueryLimiter,
+			expectedSeries: []seriesResult{
+				{
+					lbls:   metricNameLabel,
+					values: []valueResult{{t: minT + 1, v: 2}},
+				},
+			},
+			expectedWarnings: []string{
+				"partial response: failed to fetch some blocks from the store-gateways, the results are missing the samples in the time ranges 1970-01-01T02:00:00Z to 1970-01-01T06:00:00Z (err-mimir-store-gateway-partial-response)",
+			},
+		},
+		"error while getting clients to query the store-gateway and partial responses are enabled": {
+			finderResult: bucketindex.Blocks{
+				{ID: block1, MinTime: 0, MaxTime: 2 * time.Hour.Milliseconds()},
+				{ID: block2, MinTime: 4 * time.Hour.Milliseconds(), MaxTime: 6 * time.Hour.Milliseconds()},
+			},
+			storeSetResponses: []interface{}{
+				errors.New("no client found"),
+			},
+			limits:       &blocksStoreLimitsMock{storeGatewayPartialResponseEnabled: true},
+			queryLimiter: noOpQueryLimiter,
+			expectedWarnings: []string{
+				"partial response: failed to fetch some blocks from the store-gateways, the results are missing the samples in the time ranges 1970-01-01T00:00:00Z to 1970-01-01T02:00:00Z, 1970-01-01T04:00:00Z to 1970-01-01T06:00:00Z (err-mimir-store-gateway-partial-response)",
+			},
+		},
 		"multiple store-gateway instances have some missing blocks but queried from a replica during subsequent attempts": {
 			finderResult: bucketindex.Blocks{
 				{ID: block1},
@@ -901,7 +950,11 @@ func TestBlocksStoreQuerier_Select(t *testing.T) {
 					}
 
 					require.NoError(t, set.Err())
-					assert.Len(t, set.Warnings(), 0)
+					var actualWarnings []string
+					for _, w := range set.Warnings() {
+						actualWarnings = append(actualWarnings, w.Error())
+					}
+					assert.ElementsMatch(t, testData.expectedWarnings, actualWarnings)
 
 					// Read all returned series and their values.
 					var actualSeries []seriesResult
@@ -2036,6 +2089,8 @@ type storeGatewayClientMock struct {
 	mockedLabelNamesErr       error
 	mockedLabelValuesResponse *storepb.LabelValuesResponse
 	mockedLabelValuesErr      error
+	mockedExemplarsResponse   *storepb.ExemplarsResponse
+	mockedExemplarsErr        error
 }
 
 func (m *storeGatewayClientMock) Series(ctx context.Context, _ *storepb.SeriesRequest, _ ...grpc.CallOption) (storegatewaypb.StoreGateway_SeriesClient, error) {
@@ -2055,6 +2110,10 @@ func (m *storeGatewayClientMock) LabelValues(context.Context, *storepb.LabelValu
 	return m.mockedLabelValuesResponse, m.mockedLabelValuesErr
 }
 
+func (m *storeGatewayClientMock) Exemplars(context.Context, *storepb.ExemplarsRequest, ...grpc.CallOption) (*storepb.ExemplarsResponse, error) {
+	return m.mockedExemplarsResponse, m.mockedExemplarsErr
+}
+
 func (m *storeGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
@@ -2131,14 +2190,20 @@ func (m *cancelerStoreGatewayClientMock) LabelValues(ctx context.Context, _ *sto
 	return nil, ctx.Err()
 }
 
+func (m *cancelerStoreGatewayClientMock) Exemplars(ctx context.Context, _ *storepb.ExemplarsRequest, _ ...grpc.CallOption) (*storepb.ExemplarsResponse, error) {
+	m.cancel()
+	return nil, ctx.Err()
+}
+
 func (m *cancelerStoreGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
 
 type blocksStoreLimitsMock struct {
-	maxLabelsQueryLength        time.Duration
-	maxChunksPerQuery           int
-	storeGatewayTenantShardSize int
+	maxLabelsQueryLength               time.Duration
+	maxChunksPerQuery                  int
+	storeGatewayTenantShardSize        int
+	storeGatewayPartialResponseEnabled bool
 }
 
 func (m *blocksStoreLimitsMock) MaxLabelsQueryLength(_ string) time.Duration {
@@ -2153,6 +2218,10 @@ func (m *blocksStoreLimitsMock) StoreGatewayTenantShardSize(_ string) int {
 	return m.storeGatewayTenantShardSize
 }
 
+func (m *blocksStoreLimitsMock) StoreGatewayPartialResponseEnabled(_ string) bool {
+	return m.storeGatewayPartialResponseEnabled
+}
+
 func (m *blocksStoreLimitsMock) S3SSEType(_ string) string {
 	return ""
 }
@@ -2252,6 +2321,20 @@ func mockNamesHints(ids ...ulid.ULID) *types.Any {
 	return marshalled
 }
 
+func mockExemplarsHints(ids ...ulid.ULID) *types.Any {
+	hints := &hintspb.ExemplarsResponseHints{}
+	for _, id := range ids {
+		hints.AddQueriedBlock(id)
+	}
+
+	marshalled, err := types.MarshalAny(hints)
+	if err != nil {
+		panic(err)
+	}
+
+	return marshalled
+}
+
 func mockValuesHints(ids ...ulid.ULID) *types.Any {
 	hints := &hintspb.LabelValuesResponseHints{}
 	for _, id := range ids {
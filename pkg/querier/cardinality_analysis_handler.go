@@ -9,7 +9,9 @@ import (
 
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/tenant"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/mimir/pkg/cardinality"
 	ingester_client "github.com/grafana/mimir/pkg/ingester/client"
@@ -78,6 +80,116 @@ func LabelValuesCardinalityHandler(distributor Distributor, limits *validation.O
 	})
 }
 
+// TopMetricsCardinalityHandler creates handler for top metrics cardinality endpoint.
+func TopMetricsCardinalityHandler(distributor Distributor, limits *validation.Overrides) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		// Guarantee request's context is for a single tenant id
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !limits.CardinalityAnalysisEnabled(tenantID) {
+			http.Error(w, fmt.Sprintf("cardinality analysis is disabled for the tenant: %v", tenantID), http.StatusBadRequest)
+			return
+		}
+
+		topMetricsRequest, err := cardinality.DecodeTopMetricsRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		breakdown, err := distributor.ActiveSeriesBreakdown(ctx, topMetricsRequest.Matchers, string(topMetricsRequest.LabelName))
+		if err != nil {
+			respondFromError(err, w)
+			return
+		}
+
+		util.WriteJSONResponse(w, toTopMetricsResponse(breakdown, topMetricsRequest))
+	})
+}
+
+// activeSeriesResponseFlushSizeBytes is the size in bytes of the buffered active series response
+// after which the response is flushed to the client.
+const activeSeriesResponseFlushSizeBytes = 1 * 1024 * 1024
+
+// ActiveSeriesCardinalityHandler creates handler for active series endpoint.
+func ActiveSeriesCardinalityHandler(distributor Distributor, limits *validation.Overrides) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		// Guarantee request's context is for a single tenant id
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !limits.CardinalityAnalysisEnabled(tenantID) {
+			http.Error(w, fmt.Sprintf("cardinality analysis is disabled for the tenant: %v", tenantID), http.StatusBadRequest)
+			return
+		}
+
+		activeSeriesRequest, err := cardinality.DecodeActiveSeriesRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		series, err := distributor.ActiveSeries(ctx, activeSeriesRequest.Matchers)
+		if err != nil {
+			respondFromError(err, w)
+			return
+		}
+
+		writeActiveSeriesResponse(w, series)
+	})
+}
+
+// writeActiveSeriesResponse writes the series as JSON, flushing the response to the client
+// every activeSeriesResponseFlushSizeBytes, so that the encoded response is not buffered in memory
+// on top of the series, which are held by the distributor to deduplicate them.
+func writeActiveSeriesResponse(w http.ResponseWriter, series []labels.Labels) {
+	w.Header().Set("Content-Type", "application/json")
+
+	flusher, _ := w.(http.Flusher)
+	stream := jsoniter.ConfigFastest.BorrowStream(w)
+	defer jsoniter.ConfigFastest.ReturnStream(stream)
+
+	stream.WriteObjectStart()
+	stream.WriteObjectField("data")
+	stream.WriteArrayStart()
+	for i, lbls := range series {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectStart()
+		first := true
+		lbls.Range(func(l labels.Label) {
+			if !first {
+				stream.WriteMore()
+			}
+			first = false
+			stream.WriteObjectField(l.Name)
+			stream.WriteString(l.Value)
+		})
+		stream.WriteObjectEnd()
+
+		if stream.Buffered() >= activeSeriesResponseFlushSizeBytes {
+			// The status code has already been sent with the first flush, so errors can't be reported to the client.
+			if stream.Flush() != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	stream.WriteArrayEnd()
+	stream.WriteObjectEnd()
+	_ = stream.Flush()
+}
+
 func respondFromError(err error, w http.ResponseWriter) {
 	httpResp, ok := httpgrpc.HTTPResponseFromError(errors.Cause(err))
 	if !ok {
@@ -189,6 +301,79 @@ func limitLabelValuesCardinality(labelValuesCardinality []labelValuesCardinality
 	return labelValuesCardinality[:limit]
 }
 
+// toTopMetricsResponse converts the active series breakdown to topMetricsResponse, keeping only the top items
+// sorted by the requested sort order.
+func toTopMetricsResponse(breakdown *ingester_client.ActiveSeriesBreakdownResponse, req *cardinality.TopMetricsRequest) *topMetricsResponse {
+	resp := &topMetricsResponse{
+		LabelName: string(req.LabelName),
+		Metrics:   make([]topMetricsItem, 0, len(breakdown.Items)),
+	}
+
+	for _, item := range breakdown.Items {
+		resp.SeriesCountTotal += item.SeriesCount
+		resp.NativeHistogramBucketsTotal += item.NativeHistogramBuckets
+
+		metric := topMetricsItem{
+			MetricName:             item.MetricName,
+			SeriesCount:            item.SeriesCount,
+			NativeHistogramBuckets: item.NativeHistogramBuckets,
+		}
+		if req.LabelName != "" {
+			labelValue := item.LabelValue
+			metric.LabelValue = &labelValue
+		}
+		resp.Metrics = append(resp.Metrics, metric)
+	}
+
+	sortTopMetrics(resp.Metrics, req.SortBy)
+	if len(resp.Metrics) > req.Limit {
+		resp.Metrics = resp.Metrics[:req.Limit]
+	}
+	return resp
+}
+
+// sortTopMetrics sorts topMetricsItem array in DESC order by the sortBy count, and ASC order by MetricName and LabelValue.
+func sortTopMetrics(items []topMetricsItem, sortBy cardinality.SortBy) {
+	count := func(item topMetricsItem) uint64 {
+		if sortBy == cardinality.SortByNativeHistogramBuckets {
+			return item.NativeHistogramBuckets
+		}
+		return item.SeriesCount
+	}
+	labelValue := func(item topMetricsItem) string {
+		if item.LabelValue == nil {
+			return ""
+		}
+		return *item.LabelValue
+	}
+
+	sort.Slice(items, func(l, r int) bool {
+		left, right := items[l], items[r]
+		if count(left) != count(right) {
+			return count(left) > count(right)
+		}
+		if left.MetricName != right.MetricName {
+			return left.MetricName < right.MetricName
+		}
+		return labelValue(left) < labelValue(right)
+	})
+}
+
+type topMetricsItem struct {
+	MetricName string `json:"metric_name"`
+	// LabelValue is nil if no label name is requested.
+	LabelValue             *string `json:"label_value,omitempty"`
+	SeriesCount            uint64  `json:"series_count"`
+	NativeHistogramBuckets uint64  `json:"native_histogram_buckets"`
+}
+
+type topMetricsResponse struct {
+	SeriesCountTotal            uint64           `json:"series_count_total"`
+	NativeHistogramBucketsTotal uint64           `json:"native_histogram_buckets_total"`
+	LabelName                   string           `json:"label_name,omitempty"`
+	Metrics                     []topMetricsItem `json:"metrics"`
+}
+
 type labelValuesCardinality struct {
 	LabelValue  string `json:"label_value"`
 	SeriesCount uint64 `json:"series_count"`
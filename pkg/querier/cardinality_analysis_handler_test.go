@@ -786,6 +786,294 @@ func TestLabelValuesCardinalityHandler_DistributorError(t *testing.T) {
 	}
 }
 
+func TestActiveSeriesCardinalityHandler(t *testing.T) {
+	const activeSeriesURL = "/active_series?selector=" + `{__name__="metric"}`
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "metric")}
+
+	// Generate enough series to have the response flushed multiple times.
+	manySeries := make([]labels.Labels, 0, 20000)
+	manySeriesResponse := make([]map[string]string, 0, 20000)
+	for i := 0; i < cap(manySeries); i++ {
+		value := fmt.Sprintf("%0100d", i)
+		manySeries = append(manySeries, labels.FromStrings(labels.MetricName, "metric", "value", value))
+		manySeriesResponse = append(manySeriesResponse, map[string]string{labels.MetricName: "metric", "value": value})
+	}
+
+	tests := map[string]struct {
+		series           []labels.Labels
+		distributorError error
+		expectedStatus   int
+		expectedResponse activeSeriesTestResponse
+		expectedBody     string
+	}{
+		"should return the active series": {
+			series: []labels.Labels{
+				labels.FromStrings(labels.MetricName, "metric", "job", "a"),
+				labels.FromStrings(labels.MetricName, "metric", "job", "b"),
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: activeSeriesTestResponse{Data: []map[string]string{
+				{labels.MetricName: "metric", "job": "a"},
+				{labels.MetricName: "metric", "job": "b"},
+			}},
+		},
+		"should return an empty list if there are no active series": {
+			series:           []labels.Labels{},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: activeSeriesTestResponse{Data: []map[string]string{}},
+		},
+		"should return the active series when the response is flushed multiple times": {
+			series:           manySeries,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: activeSeriesTestResponse{Data: manySeriesResponse},
+		},
+		"should return bad request if the distributor returns the size limit error": {
+			series:           []labels.Labels{},
+			distributorError: httpgrpc.Errorf(http.StatusBadRequest, "size of distinct active series is greater than 10 bytes"),
+			expectedStatus:   http.StatusBadRequest,
+			expectedBody:     "size of distinct active series is greater than 10 bytes",
+		},
+		"should return internal server error if the distributor returns a non httpgrpc error": {
+			series:           []labels.Labels{},
+			distributorError: fmt.Errorf("unexpected error"),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedBody:     "unexpected error\n",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			distributor := &mockDistributor{}
+			distributor.On("ActiveSeries", mock.Anything, matchers).Return(testData.series, testData.distributorError)
+			handler := createEnabledHandler(t, ActiveSeriesCardinalityHandler, distributor)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, createRequest(activeSeriesURL, "test"))
+
+			require.Equal(t, testData.expectedStatus, recorder.Result().StatusCode)
+
+			body := recorder.Result().Body
+			defer func() { _ = body.Close() }()
+			bodyContent, err := io.ReadAll(body)
+			require.NoError(t, err)
+
+			if testData.expectedStatus != http.StatusOK {
+				require.Equal(t, testData.expectedBody, string(bodyContent))
+				return
+			}
+
+			require.Equal(t, "application/json", recorder.Result().Header.Get("Content-Type"))
+			responseBody := activeSeriesTestResponse{}
+			require.NoError(t, json.Unmarshal(bodyContent, &responseBody))
+			require.Equal(t, testData.expectedResponse, responseBody)
+		})
+	}
+}
+
+func TestActiveSeriesCardinalityHandler_ParseError(t *testing.T) {
+	handler := createEnabledHandler(t, ActiveSeriesCardinalityHandler, &mockDistributor{})
+
+	tests := map[string]struct {
+		request              *http.Request
+		expectedErrorMessage string
+	}{
+		"no tenant id is provided": {
+			request:              createRequest("/active_series?selector=metric", ""),
+			expectedErrorMessage: "no org id",
+		},
+		"selector param is empty": {
+			request:              createRequest("/active_series", "test"),
+			expectedErrorMessage: "'selector' param is required",
+		},
+		"selector param is invalid": {
+			request:              createRequest("/active_series?selector=%7B", "test"),
+			expectedErrorMessage: "failed to parse selector",
+		},
+	}
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testData.request)
+
+			require.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+
+			body := recorder.Result().Body
+			defer func() { _ = body.Close() }()
+
+			bytes, err := io.ReadAll(body)
+			require.NoError(t, err)
+			require.Contains(t, string(bytes), testData.expectedErrorMessage)
+		})
+	}
+
+	t.Run("cardinality analysis is disabled", func(t *testing.T) {
+		overrides, err := validation.NewOverrides(validation.Limits{CardinalityAnalysisEnabled: false}, nil)
+		require.NoError(t, err)
+		handler := ActiveSeriesCardinalityHandler(&mockDistributor{}, overrides)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, createRequest("/active_series?selector=metric", "team-a"))
+
+		require.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+		bytes, err := io.ReadAll(recorder.Result().Body)
+		require.NoError(t, err)
+		require.Equal(t, "cardinality analysis is disabled for the tenant: team-a\n", string(bytes))
+	})
+}
+
+func TestTopMetricsCardinalityHandler(t *testing.T) {
+	items := []*client.ActiveSeriesBreakdownItem{
+		{MetricName: "metric_b", SeriesCount: 10},
+		{MetricName: "metric_a", SeriesCount: 10},
+		{MetricName: "histogram_a", SeriesCount: 2, NativeHistogramBuckets: 40},
+		{MetricName: "histogram_b", SeriesCount: 5, NativeHistogramBuckets: 20},
+	}
+	labelItems := []*client.ActiveSeriesBreakdownItem{
+		{MetricName: "metric_a", LabelValue: "200", SeriesCount: 7},
+		{MetricName: "metric_a", LabelValue: "", SeriesCount: 3},
+		{MetricName: "histogram_a", LabelValue: "500", SeriesCount: 2, NativeHistogramBuckets: 40},
+	}
+	labelValue := func(v string) *string { return &v }
+
+	tests := map[string]struct {
+		url               string
+		items             []*client.ActiveSeriesBreakdownItem
+		expectedMatchers  []*labels.Matcher
+		expectedLabelName string
+		expectedResponse  topMetricsResponse
+	}{
+		"should return the metrics sorted by series count": {
+			url:   "/top_metrics",
+			items: items,
+			expectedResponse: topMetricsResponse{
+				SeriesCountTotal:            27,
+				NativeHistogramBucketsTotal: 60,
+				Metrics: []topMetricsItem{
+					{MetricName: "metric_a", SeriesCount: 10},
+					{MetricName: "metric_b", SeriesCount: 10},
+					{MetricName: "histogram_b", SeriesCount: 5, NativeHistogramBuckets: 20},
+					{MetricName: "histogram_a", SeriesCount: 2, NativeHistogramBuckets: 40},
+				},
+			},
+		},
+		"should return the top metrics sorted by native histogram buckets": {
+			url:   "/top_metrics?sort_by=native_histogram_buckets&limit=3",
+			items: items,
+			expectedResponse: topMetricsResponse{
+				SeriesCountTotal:            27,
+				NativeHistogramBucketsTotal: 60,
+				Metrics: []topMetricsItem{
+					{MetricName: "histogram_a", SeriesCount: 2, NativeHistogramBuckets: 40},
+					{MetricName: "histogram_b", SeriesCount: 5, NativeHistogramBuckets: 20},
+					{MetricName: "metric_a", SeriesCount: 10},
+				},
+			},
+		},
+		"should return the metrics broken down by label value": {
+			url:               "/top_metrics?selector={job=\"a\"}&label_name=status",
+			items:             labelItems,
+			expectedMatchers:  []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "job", "a")},
+			expectedLabelName: "status",
+			expectedResponse: topMetricsResponse{
+				SeriesCountTotal:            12,
+				NativeHistogramBucketsTotal: 40,
+				LabelName:                   "status",
+				Metrics: []topMetricsItem{
+					{MetricName: "metric_a", LabelValue: labelValue("200"), SeriesCount: 7},
+					{MetricName: "metric_a", LabelValue: labelValue(""), SeriesCount: 3},
+					{MetricName: "histogram_a", LabelValue: labelValue("500"), SeriesCount: 2, NativeHistogramBuckets: 40},
+				},
+			},
+		},
+		"should return no metrics if there are no active series": {
+			url:              "/top_metrics",
+			items:            nil,
+			expectedResponse: topMetricsResponse{Metrics: []topMetricsItem{}},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			distributor := &mockDistributor{}
+			distributor.On("ActiveSeriesBreakdown", mock.Anything, testData.expectedMatchers, testData.expectedLabelName).Return(&client.ActiveSeriesBreakdownResponse{Items: testData.items}, nil)
+			handler := createEnabledHandler(t, TopMetricsCardinalityHandler, distributor)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, createRequest(testData.url, "test"))
+
+			require.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+
+			body := recorder.Result().Body
+			defer func() { _ = body.Close() }()
+			bodyContent, err := io.ReadAll(body)
+			require.NoError(t, err)
+
+			responseBody := topMetricsResponse{}
+			require.NoError(t, json.Unmarshal(bodyContent, &responseBody))
+			require.Equal(t, testData.expectedResponse, responseBody)
+		})
+	}
+}
+
+func TestTopMetricsCardinalityHandler_Errors(t *testing.T) {
+	tests := map[string]struct {
+		request                    *http.Request
+		cardinalityAnalysisEnabled bool
+		distributorError           error
+		expectedStatusCode         int
+		expectedBody               string
+	}{
+		"should return bad request if no tenant id is provided": {
+			request:                    createRequest("/top_metrics", ""),
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+			expectedBody:               "no org id\n",
+		},
+		"should return bad request if the cardinality analysis is disabled": {
+			request:            createRequest("/top_metrics", "team-a"),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "cardinality analysis is disabled for the tenant: team-a\n",
+		},
+		"should return bad request if the sort_by param is invalid": {
+			request:                    createRequest("/top_metrics?sort_by=foo", "team-a"),
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+			expectedBody:               "invalid 'sort_by' param 'foo'. valid options are: [series_count,native_histogram_buckets]\n",
+		},
+		"should return internal server error if the distributor returns a non httpgrpc error": {
+			request:                    createRequest("/top_metrics", "team-a"),
+			cardinalityAnalysisEnabled: true,
+			distributorError:           fmt.Errorf("non httpgrpc error"),
+			expectedStatusCode:         http.StatusInternalServerError,
+			expectedBody:               "non httpgrpc error\n",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			distributor := &mockDistributor{}
+			distributor.On("ActiveSeriesBreakdown", mock.Anything, mock.Anything, mock.Anything).Return(&client.ActiveSeriesBreakdownResponse{}, testData.distributorError)
+
+			overrides, err := validation.NewOverrides(validation.Limits{CardinalityAnalysisEnabled: testData.cardinalityAnalysisEnabled}, nil)
+			require.NoError(t, err)
+			handler := TopMetricsCardinalityHandler(distributor, overrides)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testData.request)
+
+			require.Equal(t, testData.expectedStatusCode, recorder.Result().StatusCode)
+
+			bodyContent, err := io.ReadAll(recorder.Result().Body)
+			require.NoError(t, err)
+			require.Equal(t, testData.expectedBody, string(bodyContent))
+		})
+	}
+}
+
+type activeSeriesTestResponse struct {
+	Data []map[string]string `json:"data"`
+}
+
 // createEnabledHandler creates a cardinalityHandler that can be either a LabelNamesCardinalityHandler or a LabelValuesCardinalityHandler
 func createEnabledHandler(t *testing.T, cardinalityHandler func(Distributor, *validation.Overrides) http.Handler, distributor *mockDistributor) http.Handler {
 	limits := validation.Limits{CardinalityAnalysisEnabled: true}
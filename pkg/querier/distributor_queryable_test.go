@@ -707,6 +707,16 @@ func (m *mockDistributor) LabelValuesCardinality(ctx context.Context, labelNames
 	return args.Get(0).(uint64), args.Get(1).(*client.LabelValuesCardinalityResponse), args.Error(2)
 }
 
+func (m *mockDistributor) ActiveSeries(ctx context.Context, matchers []*labels.Matcher) ([]labels.Labels, error) {
+	args := m.Called(ctx, matchers)
+	return args.Get(0).([]labels.Labels), args.Error(1)
+}
+
+func (m *mockDistributor) ActiveSeriesBreakdown(ctx context.Context, matchers []*labels.Matcher, labelName string) (*client.ActiveSeriesBreakdownResponse, error) {
+	args := m.Called(ctx, matchers, labelName)
+	return args.Get(0).(*client.ActiveSeriesBreakdownResponse), args.Error(1)
+}
+
 type mockConfigProvider struct {
 	queryIngestersWithin time.Duration
 	seenUserIDs          []string
@@ -3,17 +3,37 @@
 package engine
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
 
 	"github.com/grafana/mimir/pkg/util/activitytracker" //lint:ignore faillint activitytracker is fine
 )
 
+const (
+	// PrometheusEngine is the upstream Prometheus PromQL engine.
+	PrometheusEngine = "prometheus"
+
+	// StreamingEngine is the Mimir streaming PromQL engine, which evaluates the queries series by series
+	// and enforces a limit on the estimated memory used by each query.
+	StreamingEngine = "streaming"
+)
+
+// QueryEngine is the interface implemented by the PromQL engines supported by Mimir. It matches the
+// interface required by the Prometheus API.
+type QueryEngine interface {
+	SetQueryLogger(l promql.QueryLogger)
+	NewInstantQuery(ctx context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, ts time.Time) (promql.Query, error)
+	NewRangeQuery(ctx context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, start, end time.Time, interval time.Duration) (promql.Query, error)
+}
+
 // Config holds the PromQL engine config exposed by Mimir.
 type Config struct {
 	MaxConcurrent int           `yaml:"max_concurrent"`
@@ -28,6 +48,9 @@ type Config struct {
 	// LookbackDelta determines the time since the last sample after which a time
 	// series is considered stale.
 	LookbackDelta time.Duration `yaml:"lookback_delta" category:"advanced"`
+
+	PromQLEngine               string `yaml:"promql_engine" category:"experimental"`
+	EnablePromQLEngineFallback bool   `yaml:"enable_promql_engine_fallback" category:"experimental"`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
@@ -44,6 +67,16 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxSamples, "querier.max-samples", 50e6, sharedWithQueryFrontend("Maximum number of samples a single query can load into memory."))
 	f.DurationVar(&cfg.DefaultEvaluationInterval, "querier.default-evaluation-interval", time.Minute, sharedWithQueryFrontend("The default evaluation interval or step size for subqueries."))
 	f.DurationVar(&cfg.LookbackDelta, "querier.lookback-delta", 5*time.Minute, sharedWithQueryFrontend("Time since the last sample after which a time series is considered stale and ignored by expression evaluations."))
+	f.StringVar(&cfg.PromQLEngine, "querier.promql-engine", PrometheusEngine, fmt.Sprintf("PromQL engine to use in the querier and ruler, either '%s' or '%s'. The '%s' engine enforces the -querier.max-estimated-memory-per-query limit.", PrometheusEngine, StreamingEngine, StreamingEngine))
+	f.BoolVar(&cfg.EnablePromQLEngineFallback, "querier.enable-promql-engine-fallback", true, fmt.Sprintf("If set to true and the '%s' engine is used, queries which aren't supported by it are evaluated by the '%s' engine.", StreamingEngine, PrometheusEngine))
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.PromQLEngine != PrometheusEngine && cfg.PromQLEngine != StreamingEngine {
+		return fmt.Errorf("unknown PromQL engine '%s'", cfg.PromQLEngine)
+	}
+
+	return nil
 }
 
 // NewPromQLEngineOptions returns the PromQL engine options based on the provided config.
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+)
+
+// mergeExemplarQueryable is a storage.ExemplarQueryable which queries all the input queryables
+// and merges their results, removing duplicated exemplars.
+type mergeExemplarQueryable struct {
+	queryables []storage.ExemplarQueryable
+}
+
+func newMergeExemplarQueryable(queryables ...storage.ExemplarQueryable) storage.ExemplarQueryable {
+	return &mergeExemplarQueryable{queryables: queryables}
+}
+
+func (m *mergeExemplarQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	queriers := make([]storage.ExemplarQuerier, 0, len(m.queryables))
+	for _, q := range m.queryables {
+		querier, err := q.ExemplarQuerier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queriers = append(queriers, querier)
+	}
+
+	return &mergeExemplarQuerier{ctx: ctx, queriers: queriers}, nil
+}
+
+type mergeExemplarQuerier struct {
+	ctx      context.Context
+	queriers []storage.ExemplarQuerier
+}
+
+// Select implements storage.ExemplarQuerier interface.
+func (m *mergeExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	sets := make([][]mimirpb.TimeSeries, len(m.queriers))
+
+	g, _ := errgroup.WithContext(m.ctx)
+	for i, q := range m.queriers {
+		i, q := i, q
+
+		g.Go(func() error {
+			results, err := q.Select(start, end, matchers...)
+			if err != nil {
+				return err
+			}
+
+			sets[i] = fromQueryResultsToExemplarsTimeSeries(results)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return fromExemplarsTimeSeriesToQueryResults(block.MergeExemplars(sets...)), nil
+}
+
+func fromQueryResultsToExemplarsTimeSeries(results []exemplar.QueryResult) []mimirpb.TimeSeries {
+	series := make([]mimirpb.TimeSeries, 0, len(results))
+	for _, r := range results {
+		series = append(series, mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(r.SeriesLabels),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(r.Exemplars),
+		})
+	}
+	return series
+}
+
+func fromExemplarsTimeSeriesToQueryResults(series []mimirpb.TimeSeries) []exemplar.QueryResult {
+	results := make([]exemplar.QueryResult, 0, len(series))
+	for _, s := range series {
+		results = append(results, exemplar.QueryResult{
+			SeriesLabels: mimirpb.FromLabelAdaptersToLabels(s.Labels),
+			Exemplars:    mimirpb.FromExemplarProtosToExemplars(s.Exemplars),
+		})
+	}
+	return results
+}
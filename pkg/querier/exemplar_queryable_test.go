@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+func TestMergeExemplarQueryable(t *testing.T) {
+	first := &exemplarQueryableMock{results: []exemplar.QueryResult{
+		exemplarsQueryResult(labels.FromStrings("__name__", "up"), 10, 20),
+		exemplarsQueryResult(labels.FromStrings("__name__", "down"), 10),
+	}}
+	second := &exemplarQueryableMock{results: []exemplar.QueryResult{
+		exemplarsQueryResult(labels.FromStrings("__name__", "up"), 20, 30),
+	}}
+
+	q, err := newMergeExemplarQueryable(first, second).ExemplarQuerier(context.Background())
+	require.NoError(t, err)
+
+	results, err := q.Select(0, 100, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "__name__", ".+")})
+	require.NoError(t, err)
+	assert.Equal(t, []exemplar.QueryResult{
+		exemplarsQueryResult(labels.FromStrings("__name__", "down"), 10),
+		exemplarsQueryResult(labels.FromStrings("__name__", "up"), 10, 20, 30),
+	}, results)
+
+	// An error from any queryable is returned.
+	second.err = errors.New("failed to query exemplars")
+	_, err = q.Select(0, 100, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "__name__", ".+")})
+	require.EqualError(t, err, "failed to query exemplars")
+}
+
+func TestBlocksStoreQuerier_Exemplars(t *testing.T) {
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	var (
+		block1  = ulid.MustNew(1, nil)
+		block2  = ulid.MustNew(2, nil)
+		series1 = labels.FromStrings(labels.MetricName, "series_1")
+		series2 = labels.FromStrings(labels.MetricName, "series_2")
+	)
+
+	tests := map[string]struct {
+		finderResult      bucketindex.Blocks
+		storeSetResponses []interface{}
+		expectedSeries    []mimirpb.TimeSeries
+		expectedErr       string
+	}{
+		"no block in the storage matching the query time range": {
+			finderResult:   nil,
+			expectedSeries: []mimirpb.TimeSeries{},
+		},
+		"a single store-gateway instance holds the required blocks": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: &storepb.ExemplarsResponse{
+						Timeseries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10, 15)},
+						Hints:      mockExemplarsHints(block1, block2),
+					}}: {block1, block2},
+				},
+			},
+			expectedSeries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10, 15)},
+		},
+		"multiple store-gateway instances hold the required blocks": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: &storepb.ExemplarsResponse{
+						Timeseries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10), exemplarsTimeSeries(series2, 12)},
+						Hints:      mockExemplarsHints(block1),
+					}}: {block1},
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: &storepb.ExemplarsResponse{
+						Timeseries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 15)},
+						Hints:      mockExemplarsHints(block2),
+					}}: {block2},
+				},
+			},
+			expectedSeries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10, 15), exemplarsTimeSeries(series2, 12)},
+		},
+		"a store-gateway instance fails and the blocks are queried from another one": {
+			finderResult: bucketindex.Blocks{{ID: block1}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsErr: errors.New("failed to receive from store-gateway")}: {block1},
+				},
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: &storepb.ExemplarsResponse{
+						Timeseries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10)},
+						Hints:      mockExemplarsHints(block1),
+					}}: {block1},
+				},
+			},
+			expectedSeries: []mimirpb.TimeSeries{exemplarsTimeSeries(series1, 10)},
+		},
+		"a store-gateway instance doesn't query all the requested blocks": {
+			finderResult: bucketindex.Blocks{{ID: block1}, {ID: block2}},
+			storeSetResponses: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: &storepb.ExemplarsResponse{
+						Hints: mockExemplarsHints(block1),
+					}}: {block1, block2},
+				},
+				errors.New("no store-gateway remaining after exclude"),
+			},
+			expectedErr: newStoreConsistencyCheckFailedError([]ulid.ULID{block2}).Error(),
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+			finder := &blocksFinderMock{}
+			finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT).Return(testData.finderResult, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+			q := &blocksStoreQuerier{
+				minT:        minT,
+				maxT:        maxT,
+				finder:      finder,
+				stores:      &blocksStoreSetMock{mockedResponses: testData.storeSetResponses},
+				consistency: NewBlocksConsistencyChecker(0, 0, log.NewNopLogger(), nil),
+				logger:      log.NewNopLogger(),
+				metrics:     newBlocksStoreQueryableMetrics(prometheus.NewPedanticRegistry()),
+				limits:      &blocksStoreLimitsMock{},
+			}
+
+			series, err := q.Exemplars(ctx, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+")})
+			if testData.expectedErr != "" {
+				require.EqualError(t, err, testData.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedSeries, series)
+		})
+	}
+}
+
+type exemplarQueryableMock struct {
+	results []exemplar.QueryResult
+	err     error
+}
+
+func (m *exemplarQueryableMock) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return m, nil
+}
+
+func (m *exemplarQueryableMock) Select(int64, int64, ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	return m.results, m.err
+}
+
+func exemplarsTimeSeries(lbls labels.Labels, timestamps ...int64) mimirpb.TimeSeries {
+	return fromQueryResultsToExemplarsTimeSeries([]exemplar.QueryResult{exemplarsQueryResult(lbls, timestamps...)})[0]
+}
+
+func exemplarsQueryResult(lbls labels.Labels, timestamps ...int64) exemplar.QueryResult {
+	res := exemplar.QueryResult{SeriesLabels: lbls}
+	for _, ts := range timestamps {
+		res.Exemplars = append(res.Exemplars, exemplar.Exemplar{
+			Labels: labels.FromStrings("trace_id", "abc"),
+			Value:  float64(ts),
+			Ts:     ts,
+		})
+	}
+	return res
+}
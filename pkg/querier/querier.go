@@ -30,6 +30,7 @@ import (
 	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/storage/chunk"
 	"github.com/grafana/mimir/pkg/storage/lazyquery"
+	"github.com/grafana/mimir/pkg/streamingpromql"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/activitytracker"
 	"github.com/grafana/mimir/pkg/util/limiter"
@@ -58,6 +59,8 @@ type Config struct {
 	StreamingChunksPerStoreGatewaySeriesBufferSize uint64        `yaml:"streaming_chunks_per_store_gateway_series_buffer_size" category:"experimental"`
 	MinimizeIngesterRequests                       bool          `yaml:"minimize_ingester_requests" category:"experimental"`
 	MinimiseIngesterRequestsHedgingDelay           time.Duration `yaml:"minimize_ingester_requests_hedging_delay" category:"experimental"`
+	SeriesDeletionRequestsSyncInterval             time.Duration `yaml:"series_deletion_requests_sync_interval" category:"experimental"`
+	QueryStoreForExemplars                         bool          `yaml:"query_store_for_exemplars" category:"experimental"`
 
 	// PromQL engine config.
 	EngineConfig engine.Config `yaml:",inline"`
@@ -93,6 +96,10 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.MinimizeIngesterRequests, minimiseIngesterRequestsFlagName, false, "If true, when querying ingesters, only the minimum required ingesters required to reach quorum will be queried initially, with other ingesters queried only if needed due to failures from the initial set of ingesters. Enabling this option reduces resource consumption for the happy path at the cost of increased latency for the unhappy path.")
 	f.DurationVar(&cfg.MinimiseIngesterRequestsHedgingDelay, minimiseIngesterRequestsFlagName+"-hedging-delay", 3*time.Second, "Delay before initiating requests to further ingesters when request minimization is enabled and the initially selected set of ingesters have not all responded. Ignored if -"+minimiseIngesterRequestsFlagName+" is not enabled.")
 
+	f.DurationVar(&cfg.SeriesDeletionRequestsSyncInterval, "querier.series-deletion-requests-sync-interval", 0, "How frequently the series deletion requests of a tenant are read from the storage, in order to filter out the deleted samples from the blocks queried from the store-gateways. 0 to disable.")
+
+	f.BoolVar(&cfg.QueryStoreForExemplars, "querier.query-store-for-exemplars", false, "If true, exemplars are also queried from the blocks in the long-term storage via the store-gateways, in addition to the ingesters. The exemplars are stored in the blocks only if -blocks-storage.tsdb.exemplars-in-blocks-enabled is enabled in the ingesters.")
+
 	// Why 256 series / ingester/store-gateway?
 	// Based on our testing, 256 series / ingester was a good balance between memory consumption and the CPU overhead of managing a batch of series.
 	f.Uint64Var(&cfg.StreamingChunksPerIngesterSeriesBufferSize, "querier.streaming-chunks-per-ingester-buffer-size", 256, "Number of series to buffer per ingester when streaming chunks from ingesters.")
@@ -107,7 +114,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 }
 
 func (cfg *Config) Validate() error {
-	return nil
+	return cfg.EngineConfig.Validate()
 }
 
 func (cfg *Config) ValidateLimits(limits validation.Limits) error {
@@ -153,7 +160,7 @@ func ShouldQueryBlockStore(queryStoreAfter time.Duration, now time.Time, queryMi
 }
 
 // New builds a queryable and promql engine.
-func New(cfg Config, limits *validation.Overrides, distributor Distributor, storeQueryable storage.Queryable, reg prometheus.Registerer, logger log.Logger, tracker *activitytracker.ActivityTracker) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, *promql.Engine) {
+func New(cfg Config, limits *validation.Overrides, distributor Distributor, storeQueryable storage.Queryable, reg prometheus.Registerer, logger log.Logger, tracker *activitytracker.ActivityTracker) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, engine.QueryEngine) {
 	iteratorFunc := getChunksIteratorFunction(cfg)
 	queryMetrics := stats.NewQueryMetrics(reg)
 
@@ -161,6 +168,9 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 
 	queryable := newQueryable(distributorQueryable, storeQueryable, iteratorFunc, cfg, limits, queryMetrics, logger)
 	exemplarQueryable := newDistributorExemplarQueryable(distributor, logger)
+	if storeExemplarQueryable, ok := storeQueryable.(storage.ExemplarQueryable); ok && cfg.QueryStoreForExemplars {
+		exemplarQueryable = newMergeExemplarQueryable(exemplarQueryable, storeExemplarQueryable)
+	}
 
 	lazyQueryable := storage.QueryableFunc(func(minT int64, maxT int64) (storage.Querier, error) {
 		querier, err := queryable.Querier(minT, maxT)
@@ -170,8 +180,22 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 		return lazyquery.NewLazyQuerier(querier), nil
 	})
 
-	engine := promql.NewEngine(engine.NewPromQLEngineOptions(cfg.EngineConfig, tracker, logger, reg))
-	return NewSampleAndChunkQueryable(lazyQueryable), exemplarQueryable, engine
+	return NewSampleAndChunkQueryable(lazyQueryable), exemplarQueryable, newQueryEngine(cfg.EngineConfig, limits, tracker, logger, reg)
+}
+
+// newQueryEngine returns the PromQL engine configured in cfg.
+func newQueryEngine(cfg engine.Config, limits *validation.Overrides, tracker *activitytracker.ActivityTracker, logger log.Logger, reg prometheus.Registerer) engine.QueryEngine {
+	opts := engine.NewPromQLEngineOptions(cfg, tracker, logger, reg)
+	if cfg.PromQLEngine != engine.StreamingEngine {
+		return promql.NewEngine(opts)
+	}
+
+	streamingEngine := streamingpromql.NewEngine(opts, limits, logger)
+	if !cfg.EnablePromQLEngineFallback {
+		return streamingEngine
+	}
+
+	return streamingpromql.NewEngineWithFallback(streamingEngine, promql.NewEngine(opts), reg, logger)
 }
 
 // NewSampleAndChunkQueryable creates a SampleAndChunkQueryable from a Queryable.
@@ -1049,6 +1049,14 @@ func (m *errDistributor) LabelValuesCardinality(context.Context, []model.LabelNa
 	return 0, nil, errDistributorError
 }
 
+func (m *errDistributor) ActiveSeries(context.Context, []*labels.Matcher) ([]labels.Labels, error) {
+	return nil, errDistributorError
+}
+
+func (m *errDistributor) ActiveSeriesBreakdown(context.Context, []*labels.Matcher, string) (*client.ActiveSeriesBreakdownResponse, error) {
+	return nil, errDistributorError
+}
+
 type emptyDistributor struct{}
 
 func (d *emptyDistributor) LabelNamesAndValues(_ context.Context, _ []*labels.Matcher) (*client.LabelNamesAndValuesResponse, error) {
@@ -1083,6 +1091,14 @@ func (d *emptyDistributor) LabelValuesCardinality(context.Context, []model.Label
 	return 0, nil, nil
 }
 
+func (d *emptyDistributor) ActiveSeries(context.Context, []*labels.Matcher) ([]labels.Labels, error) {
+	return nil, nil
+}
+
+func (d *emptyDistributor) ActiveSeriesBreakdown(context.Context, []*labels.Matcher, string) (*client.ActiveSeriesBreakdownResponse, error) {
+	return nil, nil
+}
+
 func TestQuerier_QueryStoreAfterConfig(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/objstore"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+// seriesDeletion holds the parsed selectors and time range of a series deletion request.
+type seriesDeletion struct {
+	matchers [][]*labels.Matcher
+	interval tombstones.Interval
+}
+
+// matches returns whether the series matches at least one of the deletion selectors.
+func (d seriesDeletion) matches(lbls labels.Labels) bool {
+	for _, matchers := range d.matchers {
+		matched := true
+		for _, m := range matchers {
+			if !m.Matches(lbls.Get(m.Name)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+type cachedSeriesDeletions struct {
+	deletions []seriesDeletion
+	fetchedAt time.Time
+}
+
+// seriesDeletionRequestsLoader loads the series deletion requests of the tenants from the storage,
+// caching them for the configured sync interval.
+type seriesDeletionRequestsLoader struct {
+	bkt          objstore.BucketReader
+	syncInterval time.Duration
+	logger       log.Logger
+
+	mtx     sync.Mutex
+	tenants map[string]cachedSeriesDeletions
+}
+
+func newSeriesDeletionRequestsLoader(bkt objstore.BucketReader, syncInterval time.Duration, logger log.Logger) *seriesDeletionRequestsLoader {
+	return &seriesDeletionRequestsLoader{
+		bkt:          bkt,
+		syncInterval: syncInterval,
+		logger:       logger,
+		tenants:      map[string]cachedSeriesDeletions{},
+	}
+}
+
+// getDeletions returns the series deletions of the tenant overlapping the input time range.
+// Input minT and maxT are both inclusive.
+func (l *seriesDeletionRequestsLoader) getDeletions(ctx context.Context, userID string, minT, maxT int64) ([]seriesDeletion, error) {
+	all, err := l.getAllDeletions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []seriesDeletion
+	for _, d := range all {
+		if d.interval.Mint <= maxT && minT <= d.interval.Maxt {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (l *seriesDeletionRequestsLoader) getAllDeletions(ctx context.Context, userID string) ([]seriesDeletion, error) {
+	l.mtx.Lock()
+	cached, ok := l.tenants[userID]
+	l.mtx.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < l.syncInterval {
+		return cached.deletions, nil
+	}
+
+	requests, err := mimir_tsdb.ListSeriesDeletionRequests(ctx, l.bkt, userID)
+	if err != nil {
+		// Keep using the previously loaded requests, if any: a stale view is better than not filtering at all.
+		if ok {
+			level.Warn(l.logger).Log("msg", "failed to reload series deletion requests, using the previously loaded ones", "user", userID, "err", err)
+			return cached.deletions, nil
+		}
+		return nil, err
+	}
+
+	deletions := make([]seriesDeletion, 0, len(requests))
+	for _, req := range requests {
+		// The series of processed requests have already been removed from all blocks.
+		if req.State == mimir_tsdb.SeriesDeletionRequestProcessed {
+			continue
+		}
+
+		matchers, err := req.Matchers()
+		if err != nil {
+			level.Warn(l.logger).Log("msg", "skipped invalid series deletion request", "user", userID, "request_id", req.RequestID, "err", err)
+			continue
+		}
+
+		deletions = append(deletions, seriesDeletion{
+			matchers: matchers,
+			interval: tombstones.Interval{Mint: req.StartTime, Maxt: req.EndTime},
+		})
+	}
+
+	l.mtx.Lock()
+	l.tenants[userID] = cachedSeriesDeletions{deletions: deletions, fetchedAt: time.Now()}
+	l.mtx.Unlock()
+
+	return deletions, nil
+}
+
+// deletedIntervals returns the deleted intervals of the series with the input labels.
+func deletedIntervals(lbls labels.Labels, deletions []seriesDeletion) tombstones.Intervals {
+	var intervals tombstones.Intervals
+	for _, d := range deletions {
+		if d.matches(lbls) {
+			intervals = intervals.Add(d.interval)
+		}
+	}
+	return intervals
+}
+
+// seriesDeletionSeriesSet filters out the deleted samples from the series of the wrapped set, and the series
+// whose samples are all deleted.
+type seriesDeletionSeriesSet struct {
+	storage.SeriesSet
+	deletions []seriesDeletion
+	curr      storage.Series
+}
+
+func newSeriesDeletionSeriesSet(set storage.SeriesSet, deletions []seriesDeletion) storage.SeriesSet {
+	return &seriesDeletionSeriesSet{SeriesSet: set, deletions: deletions}
+}
+
+func (s *seriesDeletionSeriesSet) Next() bool {
+	for s.SeriesSet.Next() {
+		series := s.SeriesSet.At()
+
+		intervals := deletedIntervals(series.Labels(), s.deletions)
+		if len(intervals) == 0 {
+			s.curr = series
+			return true
+		}
+
+		s.curr = &seriesDeletionSeries{Series: series, intervals: intervals}
+		if !isSeriesEmpty(s.curr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *seriesDeletionSeriesSet) At() storage.Series {
+	return s.curr
+}
+
+// isSeriesEmpty returns whether the series has no samples. A series failing to be read isn't considered empty,
+// so that the error is returned when iterating its samples.
+func isSeriesEmpty(series storage.Series) bool {
+	it := series.Iterator(nil)
+	return it.Next() == chunkenc.ValNone && it.Err() == nil
+}
+
+// deletedSeriesFilterSeriesSet filters out the series in the deleted set. It's used for the series without
+// samples, like the ones returned to the series API, which can't be checked for deleted samples.
+type deletedSeriesFilterSeriesSet struct {
+	storage.SeriesSet
+	deleted map[string]labels.Labels
+}
+
+func (s *deletedSeriesFilterSeriesSet) Next() bool {
+	for s.SeriesSet.Next() {
+		if _, ok := s.deleted[s.SeriesSet.At().Labels().String()]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findDeletedSeries returns the series matching the input matchers whose samples within [minT, maxT] are all
+// deleted, along with their labels. The querier must not filter out the deleted series.
+func findDeletedSeries(ctx context.Context, q storage.Querier, minT, maxT int64, matchers []*labels.Matcher, deletions []seriesDeletion) (map[string]labels.Labels, error) {
+	deleted := map[string]labels.Labels{}
+
+	// Only the series matching a deletion can be deleted, so there's no need to query all the series.
+	for _, d := range deletions {
+		for _, deletionMatchers := range d.matchers {
+			set := q.Select(ctx, true, &storage.SelectHints{Start: minT, End: maxT}, append(slices.Clone(matchers), deletionMatchers...)...)
+			for set.Next() {
+				series := set.At()
+				key := series.Labels().String()
+				if _, ok := deleted[key]; ok {
+					continue
+				}
+
+				if isSeriesEmpty(&seriesDeletionSeries{Series: series, intervals: deletedIntervals(series.Labels(), deletions)}) {
+					deleted[key] = series.Labels()
+				}
+			}
+			if err := set.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
+// removeLabelValuesOfDeletedSeries returns the input values of the label without the ones only the deleted
+// series have. The querier must not filter out the deleted series.
+func removeLabelValuesOfDeletedSeries(ctx context.Context, q storage.Querier, minT, maxT int64, name string, values []string, matchers []*labels.Matcher, deleted map[string]labels.Labels) ([]string, error) {
+	candidates := map[string]struct{}{}
+	for _, lbls := range deleted {
+		if v := lbls.Get(name); v != "" {
+			candidates[v] = struct{}{}
+		}
+	}
+	if len(candidates) == 0 {
+		return values, nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := candidates[v]; ok {
+			valueMatchers := append(slices.Clone(matchers), labels.MustNewMatcher(labels.MatchEqual, name, v))
+			onlyDeleted, err := onlyDeletedSeries(ctx, q, minT, maxT, valueMatchers, deleted)
+			if err != nil {
+				return nil, err
+			}
+			if onlyDeleted {
+				continue
+			}
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// removeLabelNamesOfDeletedSeries returns the input label names without the ones only the deleted series have.
+// The querier must not filter out the deleted series.
+func removeLabelNamesOfDeletedSeries(ctx context.Context, q storage.Querier, minT, maxT int64, names []string, matchers []*labels.Matcher, deleted map[string]labels.Labels) ([]string, error) {
+	candidates := map[string]struct{}{}
+	for _, lbls := range deleted {
+		lbls.Range(func(l labels.Label) {
+			candidates[l.Name] = struct{}{}
+		})
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := candidates[name]; ok {
+			values, _, err := q.LabelValues(ctx, name, matchers...)
+			if err != nil {
+				return nil, err
+			}
+			values, err = removeLabelValuesOfDeletedSeries(ctx, q, minT, maxT, name, values, matchers, deleted)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) == 0 {
+				continue
+			}
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// onlyDeletedSeries returns whether all the series matching the input matchers are in the deleted set.
+func onlyDeletedSeries(ctx context.Context, q storage.Querier, minT, maxT int64, matchers []*labels.Matcher, deleted map[string]labels.Labels) (bool, error) {
+	set := q.Select(ctx, true, &storage.SelectHints{Start: minT, End: maxT, Func: "series"}, matchers...)
+	for set.Next() {
+		if _, ok := deleted[set.At().Labels().String()]; !ok {
+			return false, nil
+		}
+	}
+	return true, set.Err()
+}
+
+type seriesDeletionSeries struct {
+	storage.Series
+	intervals tombstones.Intervals
+}
+
+func (s *seriesDeletionSeries) Iterator(it chunkenc.Iterator) chunkenc.Iterator {
+	// Reuse the wrapped iterator, if any.
+	if prev, ok := it.(*seriesDeletionIterator); ok {
+		it = prev.Iterator
+	}
+	return &seriesDeletionIterator{Iterator: s.Series.Iterator(it), intervals: s.intervals}
+}
+
+// seriesDeletionIterator skips the samples within the deleted intervals.
+type seriesDeletionIterator struct {
+	chunkenc.Iterator
+	intervals tombstones.Intervals
+}
+
+func (it *seriesDeletionIterator) Next() chunkenc.ValueType {
+	for {
+		valueType := it.Iterator.Next()
+		if valueType == chunkenc.ValNone || !it.isDeleted(it.Iterator.AtT()) {
+			return valueType
+		}
+	}
+}
+
+func (it *seriesDeletionIterator) Seek(t int64) chunkenc.ValueType {
+	valueType := it.Iterator.Seek(t)
+	if valueType == chunkenc.ValNone || !it.isDeleted(it.Iterator.AtT()) {
+		return valueType
+	}
+	return it.Next()
+}
+
+func (it *seriesDeletionIterator) isDeleted(t int64) bool {
+	for _, interval := range it.intervals {
+		if interval.InBounds(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/prometheus/prometheus/util/teststorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/series"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func TestSeriesDeletionSeriesSet(t *testing.T) {
+	samples := []model.SamplePair{{Timestamp: 10, Value: 1}, {Timestamp: 20, Value: 2}, {Timestamp: 30, Value: 3}, {Timestamp: 40, Value: 4}, {Timestamp: 50, Value: 5}}
+
+	set := series.NewConcreteSeriesSetFromSortedSeries([]storage.Series{
+		series.NewConcreteSeries(labels.FromStrings("__name__", "down"), samples, nil),
+		series.NewConcreteSeries(labels.FromStrings("__name__", "gone", "job", "test"), samples[1:3], nil),
+		series.NewConcreteSeries(labels.FromStrings("__name__", "up", "job", "other"), samples, nil),
+		series.NewConcreteSeries(labels.FromStrings("__name__", "up", "job", "test"), samples, nil),
+	})
+
+	set = newSeriesDeletionSeriesSet(set, []seriesDeletion{
+		{
+			matchers: [][]*labels.Matcher{{labels.MustNewMatcher(labels.MatchEqual, "job", "test")}},
+			interval: tombstones.Interval{Mint: 20, Maxt: 30},
+		}, {
+			matchers: [][]*labels.Matcher{
+				{labels.MustNewMatcher(labels.MatchEqual, "__name__", "unknown")},
+				{labels.MustNewMatcher(labels.MatchEqual, "__name__", "up")},
+			},
+			interval: tombstones.Interval{Mint: 50, Maxt: 60},
+		},
+	})
+
+	expected := map[string][]int64{
+		`{__name__="down"}`:            {10, 20, 30, 40, 50},
+		`{__name__="up", job="other"}`: {10, 20, 30, 40},
+		`{__name__="up", job="test"}`:  {10, 40},
+	}
+
+	actual := map[string][]int64{}
+	for set.Next() {
+		s := set.At()
+
+		var timestamps []int64
+		it := s.Iterator(nil)
+		for it.Next() != chunkenc.ValNone {
+			ts, _ := it.At()
+			timestamps = append(timestamps, ts)
+		}
+		require.NoError(t, it.Err())
+
+		actual[s.Labels().String()] = timestamps
+	}
+	require.NoError(t, set.Err())
+	assert.Equal(t, expected, actual)
+}
+
+func TestSeriesDeletion_SeriesAndLabels(t *testing.T) {
+	ctx := context.Background()
+	db := teststorage.New(t)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	app := db.Appender(ctx)
+	for _, lbls := range []labels.Labels{
+		labels.FromStrings("__name__", "up", "job", "test", "email", "deleted@example.com"),
+		labels.FromStrings("__name__", "up", "job", "test", "email", "kept@example.com"),
+		labels.FromStrings("__name__", "secret", "pii", "deleted"),
+		labels.FromStrings("__name__", "partial", "pii_partial", "kept"),
+	} {
+		for ts := int64(10); ts <= 50; ts += 10 {
+			_, err := app.Append(0, lbls, ts, float64(ts))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, app.Commit())
+
+	q, err := db.Querier(0, 100)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, q.Close()) })
+
+	deletions := []seriesDeletion{
+		{
+			matchers: [][]*labels.Matcher{{labels.MustNewMatcher(labels.MatchEqual, "email", "deleted@example.com")}},
+			interval: tombstones.Interval{Mint: 0, Maxt: 100},
+		}, {
+			matchers: [][]*labels.Matcher{
+				{labels.MustNewMatcher(labels.MatchEqual, "pii", "deleted")},
+				{labels.MustNewMatcher(labels.MatchEqual, "pii_partial", "kept")},
+			},
+			interval: tombstones.Interval{Mint: 0, Maxt: 30},
+		}, {
+			matchers: [][]*labels.Matcher{{labels.MustNewMatcher(labels.MatchEqual, "pii", "deleted")}},
+			interval: tombstones.Interval{Mint: 31, Maxt: 100},
+		},
+	}
+
+	// The series with some samples left aren't deleted.
+	deleted, err := findDeletedSeries(ctx, q, 0, 100, nil, deletions)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	assert.Contains(t, deleted, `{__name__="secret", pii="deleted"}`)
+	assert.Contains(t, deleted, `{__name__="up", email="deleted@example.com", job="test"}`)
+
+	// The deleted series aren't returned by the series API.
+	set := &deletedSeriesFilterSeriesSet{
+		SeriesSet: q.Select(ctx, true, &storage.SelectHints{Start: 0, End: 100, Func: "series"}, labels.MustNewMatcher(labels.MatchRegexp, "__name__", ".+")),
+		deleted:   deleted,
+	}
+	var actualSeries []string
+	for set.Next() {
+		actualSeries = append(actualSeries, set.At().Labels().String())
+	}
+	require.NoError(t, set.Err())
+	assert.Equal(t, []string{`{__name__="partial", pii_partial="kept"}`, `{__name__="up", email="kept@example.com", job="test"}`}, actualSeries)
+
+	// The label values only the deleted series have are filtered out.
+	values, err := removeLabelValuesOfDeletedSeries(ctx, q, 0, 100, "email", []string{"deleted@example.com", "kept@example.com"}, nil, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept@example.com"}, values)
+
+	values, err = removeLabelValuesOfDeletedSeries(ctx, q, 0, 100, "__name__", []string{"partial", "secret", "up"}, nil, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partial", "up"}, values)
+
+	values, err = removeLabelValuesOfDeletedSeries(ctx, q, 0, 100, "job", []string{"test"}, nil, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test"}, values)
+
+	// The label names only the deleted series have are filtered out.
+	names, err := removeLabelNamesOfDeletedSeries(ctx, q, 0, 100, []string{"__name__", "email", "job", "pii", "pii_partial"}, nil, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"__name__", "email", "job", "pii_partial"}, names)
+
+	names, err = removeLabelNamesOfDeletedSeries(ctx, q, 0, 100, []string{"__name__", "email", "job"}, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "email", "deleted@example.com")}, deleted)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestSeriesDeletionIterator_Seek(t *testing.T) {
+	samples := []model.SamplePair{{Timestamp: 10, Value: 1}, {Timestamp: 20, Value: 2}, {Timestamp: 30, Value: 3}, {Timestamp: 40, Value: 4}}
+	s := &seriesDeletionSeries{
+		Series:    series.NewConcreteSeries(labels.FromStrings("__name__", "up"), samples, nil),
+		intervals: tombstones.Intervals{{Mint: 15, Maxt: 35}},
+	}
+
+	it := s.Iterator(nil)
+	require.Equal(t, chunkenc.ValFloat, it.Seek(20))
+	ts, _ := it.At()
+	assert.Equal(t, int64(40), ts)
+	require.Equal(t, chunkenc.ValNone, it.Next())
+
+	// The iterator can be reused.
+	it = s.Iterator(it)
+	require.Equal(t, chunkenc.ValFloat, it.Seek(5))
+	ts, _ = it.At()
+	assert.Equal(t, int64(10), ts)
+	require.Equal(t, chunkenc.ValNone, it.Seek(45))
+}
+
+func TestSeriesDeletionRequestsLoader(t *testing.T) {
+	const userID = "user"
+
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	first, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`up`}, 10, 20, time.Unix(1000, 0))
+	require.NoError(t, err)
+	second, err := mimir_tsdb.NewSeriesDeletionRequest([]string{`down`, `{job="test"}`}, 30, 40, time.Unix(2000, 0))
+	require.NoError(t, err)
+
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bkt, userID, nil, first))
+
+	loader := newSeriesDeletionRequestsLoader(bkt, time.Hour, log.NewNopLogger())
+
+	deletions, err := loader.getDeletions(ctx, userID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, tombstones.Interval{Mint: 10, Maxt: 20}, deletions[0].interval)
+
+	// The requests are cached until the sync interval elapses.
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bkt, userID, nil, second))
+
+	deletions, err = loader.getDeletions(ctx, userID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+
+	loader.syncInterval = 0
+
+	deletions, err = loader.getDeletions(ctx, userID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deletions, 2)
+	assert.Len(t, deletions[1].matchers, 2)
+
+	// Only the requests overlapping the time range are returned.
+	deletions, err = loader.getDeletions(ctx, userID, 21, 29)
+	require.NoError(t, err)
+	assert.Empty(t, deletions)
+
+	deletions, err = loader.getDeletions(ctx, userID, 40, 50)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, tombstones.Interval{Mint: 30, Maxt: 40}, deletions[0].interval)
+
+	// The processed requests are skipped, given their series have been removed from all blocks.
+	second.State = mimir_tsdb.SeriesDeletionRequestProcessed
+	require.NoError(t, mimir_tsdb.WriteSeriesDeletionRequest(ctx, bkt, userID, nil, second))
+
+	deletions, err = loader.getDeletions(ctx, userID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, tombstones.Interval{Mint: 10, Maxt: 20}, deletions[0].interval)
+
+	// Tenants without requests have no deletions.
+	deletions, err = loader.getDeletions(ctx, "other", 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, deletions)
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/querier"
+	"github.com/grafana/mimir/pkg/querier/engine"
 	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
 	util_log "github.com/grafana/mimir/pkg/util/log"
 )
@@ -147,6 +148,36 @@ type RulesLimits interface {
 	RulerSyncRulesOnChangesEnabled(userID string) bool
 }
 
+// EngineQueryFunc returns a new query function that executes instant queries against the given engine,
+// converting the scalar results into vector ones. It's the same as rules.EngineQueryFunc, but works with
+// any PromQL engine supported by Mimir.
+func EngineQueryFunc(eng engine.QueryEngine, q storage.Queryable) rules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		query, err := eng.NewInstantQuery(ctx, q, nil, qs, t)
+		if err != nil {
+			return nil, err
+		}
+		defer query.Close()
+
+		res := query.Exec(ctx)
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		switch v := res.Value.(type) {
+		case promql.Vector:
+			return v, nil
+		case promql.Scalar:
+			return promql.Vector{promql.Sample{
+				T:      v.T,
+				F:      v.V,
+				Metric: labels.Labels{},
+			}}, nil
+		default:
+			return nil, errors.New("rule result is not a vector or scalar")
+		}
+	}
+}
+
 func MetricsQueryFunc(qf rules.QueryFunc, queries, failedQueries prometheus.Counter) rules.QueryFunc {
 	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
 		queries.Inc()
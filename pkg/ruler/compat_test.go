@@ -344,6 +344,29 @@ func TestMetricsQueryFuncErrors(t *testing.T) {
 	}
 }
 
+func TestEngineQueryFunc(t *testing.T) {
+	queryable := promql.LoadedStorage(t, `
+		load 1m
+			some_metric{env="prod"} 1 2 3
+	`)
+	t.Cleanup(func() { require.NoError(t, queryable.Close()) })
+
+	qf := EngineQueryFunc(promql.NewEngine(promql.EngineOpts{Timeout: time.Minute, MaxSamples: 100}), queryable)
+	ts := time.Unix(120, 0)
+
+	vector, err := qf(context.Background(), "some_metric", ts)
+	require.NoError(t, err)
+	require.Equal(t, promql.Vector{{Metric: labels.FromStrings(labels.MetricName, "some_metric", "env", "prod"), T: ts.UnixMilli(), F: 3}}, vector)
+
+	// Scalar results are converted to vectors.
+	vector, err = qf(context.Background(), "scalar(some_metric) * 2", ts)
+	require.NoError(t, err)
+	require.Equal(t, promql.Vector{{Metric: labels.EmptyLabels(), T: ts.UnixMilli(), F: 6}}, vector)
+
+	_, err = qf(context.Background(), `"string"`, ts)
+	require.EqualError(t, err, "rule result is not a vector or scalar")
+}
+
 func TestRecordAndReportRuleQueryMetrics(t *testing.T) {
 	queryTime := promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"})
 	zeroFetchedSeriesCount := promauto.With(nil).NewCounterVec(prometheus.CounterOpts{}, []string{"user"})
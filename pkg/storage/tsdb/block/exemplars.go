@@ -0,0 +1,412 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+const (
+	// ExemplarsFilename is the known file storing the exemplars of the block series, if any.
+	ExemplarsFilename = "exemplars"
+
+	exemplarsFileMagic   = uint32(0x4558454D)
+	exemplarsFileVersion = byte(1)
+)
+
+// HasExemplars returns whether the exemplars file has been uploaded along with the block.
+func (m *Meta) HasExemplars() bool {
+	for _, f := range m.Thanos.Files {
+		if f.RelPath == ExemplarsFilename {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteExemplarsFile writes the exemplars of the input series to the exemplars file in the block directory.
+// The samples and histograms of the input series are ignored. If there's no exemplar, the file is not written.
+//
+// The file starts with a magic number and a version, followed by the series, each one encoded as a
+// length-prefixed mimirpb.TimeSeries. Series are sorted by labels.
+func WriteExemplarsFile(blockDir string, series []mimirpb.TimeSeries) (returnErr error) {
+	w, err := NewExemplarsWriter(blockDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); returnErr == nil {
+			returnErr = err
+		}
+	}()
+
+	for _, s := range MergeExemplars(series) {
+		if err := w.Write(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExemplarsWriter writes the exemplars file of a block one series at a time.
+type ExemplarsWriter struct {
+	path string
+	f    *os.File
+	bw   *bufio.Writer
+	buf  []byte
+
+	written bool
+	err     error
+}
+
+// NewExemplarsWriter returns a writer of the exemplars file in the block directory. The file is written to
+// a temporary location, and moved to the final one on Close(), only if it contains any exemplar.
+func NewExemplarsWriter(blockDir string) (*ExemplarsWriter, error) {
+	path := filepath.Join(blockDir, ExemplarsFilename)
+
+	// Write to a temporary file first, so that a partially written file is never found.
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, errors.Wrap(err, "create exemplars file")
+	}
+
+	w := &ExemplarsWriter{path: path, f: f, bw: bufio.NewWriter(f)}
+	_ = binary.Write(w.bw, binary.BigEndian, exemplarsFileMagic)
+	_ = w.bw.WriteByte(exemplarsFileVersion)
+	return w, nil
+}
+
+// Write appends the exemplars of the series to the file. Series must be written sorted by labels, and the
+// exemplars of each series sorted by timestamp. The samples and histograms of the series are ignored.
+func (w *ExemplarsWriter) Write(s mimirpb.TimeSeries) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(s.Exemplars) == 0 {
+		return nil
+	}
+
+	data, err := (&mimirpb.TimeSeries{Labels: s.Labels, Exemplars: s.Exemplars}).Marshal()
+	if err != nil {
+		w.err = errors.Wrap(err, "encode exemplars")
+		return w.err
+	}
+
+	var sizeBuf [binary.MaxVarintLen64]byte
+	w.buf = append(append(w.buf[:0], sizeBuf[:binary.PutUvarint(sizeBuf[:], uint64(len(data)))]...), data...)
+	if _, err := w.bw.Write(w.buf); err != nil {
+		w.err = errors.Wrap(err, "write exemplars file")
+		return w.err
+	}
+
+	w.written = true
+	return nil
+}
+
+// Close finalizes the exemplars file. If no exemplar has been written, or if any write failed, the file
+// is not created.
+func (w *ExemplarsWriter) Close() error {
+	tmp := w.f.Name()
+
+	err := w.err
+	if err == nil && w.written {
+		err = errors.Wrap(w.bw.Flush(), "write exemplars file")
+	}
+	if closeErr := w.f.Close(); err == nil && w.written {
+		err = errors.Wrap(closeErr, "close exemplars file")
+	}
+
+	if err != nil || !w.written {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return errors.Wrap(os.Rename(tmp, w.path), "rename exemplars file")
+}
+
+// ReadExemplarsFile reads the exemplars file in the block directory. If the file doesn't exist, it returns
+// no series and no error.
+func ReadExemplarsFile(blockDir string) ([]mimirpb.TimeSeries, error) {
+	r, err := OpenExemplarsFile(blockDir)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return readAllExemplars(r)
+}
+
+// ReadExemplars decodes the content of an exemplars file.
+func ReadExemplars(r io.Reader) ([]mimirpb.TimeSeries, error) {
+	er, err := NewExemplarsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return readAllExemplars(er)
+}
+
+func readAllExemplars(r *ExemplarsReader) ([]mimirpb.TimeSeries, error) {
+	var series []mimirpb.TimeSeries
+	for r.Next() {
+		series = append(series, r.At())
+	}
+	return series, r.Err()
+}
+
+// ExemplarsReader reads the series of an exemplars file one at a time, in the order they're stored.
+type ExemplarsReader struct {
+	br     *bufio.Reader
+	closer io.Closer
+	data   []byte
+
+	curr mimirpb.TimeSeries
+	err  error
+}
+
+// OpenExemplarsFile returns a reader of the exemplars file in the block directory. If the file doesn't exist,
+// the returned reader has no series.
+func OpenExemplarsFile(blockDir string) (*ExemplarsReader, error) {
+	f, err := os.Open(filepath.Join(blockDir, ExemplarsFilename))
+	if os.IsNotExist(err) {
+		return &ExemplarsReader{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open exemplars file")
+	}
+
+	r, err := NewExemplarsReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewExemplarsReader returns a reader of the content of an exemplars file. It checks the file header.
+func NewExemplarsReader(r io.Reader) (*ExemplarsReader, error) {
+	br := bufio.NewReader(r)
+
+	var magic uint32
+	if err := binary.Read(br, binary.BigEndian, &magic); err != nil {
+		return nil, errors.Wrap(err, "read exemplars file magic number")
+	}
+	if magic != exemplarsFileMagic {
+		return nil, errors.Errorf("invalid exemplars file magic number %x", magic)
+	}
+
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "read exemplars file version")
+	}
+	if version != exemplarsFileVersion {
+		return nil, errors.Errorf("unsupported exemplars file version %d", version)
+	}
+
+	return &ExemplarsReader{br: br}, nil
+}
+
+// Next decodes the next series, and returns false once there are no more series or an error occurred.
+func (r *ExemplarsReader) Next() bool {
+	if r.br == nil || r.err != nil {
+		return false
+	}
+
+	size, err := binary.ReadUvarint(r.br)
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		r.err = errors.Wrap(err, "read exemplars series size")
+		return false
+	}
+
+	if uint64(cap(r.data)) < size {
+		r.data = make([]byte, size)
+	}
+	r.data = r.data[:size]
+
+	if _, err := io.ReadFull(r.br, r.data); err != nil {
+		r.err = errors.Wrap(err, "read exemplars series")
+		return false
+	}
+
+	// The decoded series must not reference the reused buffer, so we decode into a fresh copy.
+	r.curr = mimirpb.TimeSeries{}
+	if err := r.curr.Unmarshal(append([]byte(nil), r.data...)); err != nil {
+		r.err = errors.Wrap(err, "decode exemplars series")
+		return false
+	}
+	return true
+}
+
+// At returns the current series.
+func (r *ExemplarsReader) At() mimirpb.TimeSeries {
+	return r.curr
+}
+
+// Err returns the error occurred while reading the series, if any.
+func (r *ExemplarsReader) Err() error {
+	return r.err
+}
+
+// Close closes the underlying file, if the reader has been opened with OpenExemplarsFile().
+func (r *ExemplarsReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// MergeExemplarsReaders merges the series read from the input readers, which must be sorted by labels, calling
+// fn for each merged series in labels order. Only one series per reader is kept in memory at a time.
+func MergeExemplarsReaders(readers []*ExemplarsReader, fn func(mimirpb.TimeSeries) error) error {
+	type head struct {
+		r      *ExemplarsReader
+		labels labels.Labels
+	}
+
+	heads := make([]*head, 0, len(readers))
+	for _, r := range readers {
+		if r.Next() {
+			heads = append(heads, &head{r: r, labels: mimirpb.FromLabelAdaptersToLabels(r.At().Labels)})
+		} else if err := r.Err(); err != nil {
+			return err
+		}
+	}
+
+	for len(heads) > 0 {
+		// Find the series with the lowest labels, and all the readers currently at it.
+		minLabels := heads[0].labels
+		for _, h := range heads[1:] {
+			if labels.Compare(h.labels, minLabels) < 0 {
+				minLabels = h.labels
+			}
+		}
+
+		var (
+			same []mimirpb.TimeSeries
+			next = heads[:0]
+		)
+		for _, h := range heads {
+			if labels.Compare(h.labels, minLabels) != 0 {
+				next = append(next, h)
+				continue
+			}
+
+			same = append(same, h.r.At())
+			if h.r.Next() {
+				h.labels = mimirpb.FromLabelAdaptersToLabels(h.r.At().Labels)
+				next = append(next, h)
+			} else if err := h.r.Err(); err != nil {
+				return err
+			}
+		}
+		heads = next
+
+		for _, s := range MergeExemplars(same) {
+			if err := fn(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MergeExemplars merges the exemplars of the same series from the input sets. The returned series are sorted by
+// labels, and their exemplars are sorted by timestamp, keeping only one exemplar for each timestamp. Series without
+// exemplars are removed.
+func MergeExemplars(sets ...[]mimirpb.TimeSeries) []mimirpb.TimeSeries {
+	type seriesExemplars struct {
+		labels    labels.Labels
+		exemplars []mimirpb.Exemplar
+	}
+
+	bySeries := map[string]*seriesExemplars{}
+	for _, set := range sets {
+		for _, s := range set {
+			if len(s.Exemplars) == 0 {
+				continue
+			}
+
+			lbls := mimirpb.FromLabelAdaptersToLabels(s.Labels)
+			key := string(lbls.Bytes(nil))
+
+			entry, ok := bySeries[key]
+			if !ok {
+				entry = &seriesExemplars{labels: lbls}
+				bySeries[key] = entry
+			}
+			entry.exemplars = append(entry.exemplars, s.Exemplars...)
+		}
+	}
+
+	result := make([]mimirpb.TimeSeries, 0, len(bySeries))
+	for _, entry := range bySeries {
+		sort.SliceStable(entry.exemplars, func(i, j int) bool {
+			return entry.exemplars[i].TimestampMs < entry.exemplars[j].TimestampMs
+		})
+
+		// Remove the exemplars with the same timestamp, keeping the first one.
+		deduped := entry.exemplars[:0]
+		for i, e := range entry.exemplars {
+			if i > 0 && e.TimestampMs == deduped[len(deduped)-1].TimestampMs {
+				continue
+			}
+			deduped = append(deduped, e)
+		}
+
+		result = append(result, mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(entry.labels),
+			Exemplars: deduped,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return labels.Compare(mimirpb.FromLabelAdaptersToLabels(result[i].Labels), mimirpb.FromLabelAdaptersToLabels(result[j].Labels)) < 0
+	})
+
+	return result
+}
+
+// FilterExemplars returns the exemplars of the series selected by the input function, with timestamp between
+// minT and maxT (both inclusive). Series without exemplars after the filtering are removed.
+func FilterExemplars(series []mimirpb.TimeSeries, minT, maxT int64, selected func(labels.Labels) bool) []mimirpb.TimeSeries {
+	var result []mimirpb.TimeSeries
+
+	for _, s := range series {
+		if selected != nil && !selected(mimirpb.FromLabelAdaptersToLabels(s.Labels)) {
+			continue
+		}
+
+		var exemplars []mimirpb.Exemplar
+		for _, e := range s.Exemplars {
+			if e.TimestampMs >= minT && e.TimestampMs <= maxT {
+				exemplars = append(exemplars, e)
+			}
+		}
+
+		if len(exemplars) > 0 {
+			result = append(result, mimirpb.TimeSeries{Labels: s.Labels, Exemplars: exemplars})
+		}
+	}
+
+	return result
+}
+
+func hasExemplarsFile(blockDir string) bool {
+	_, err := os.Stat(filepath.Join(blockDir, ExemplarsFilename))
+	return err == nil
+}
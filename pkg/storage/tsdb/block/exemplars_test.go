@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package block
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+func TestWriteReadExemplarsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Reading a non existing file returns no error.
+	series, err := ReadExemplarsFile(dir)
+	require.NoError(t, err)
+	assert.Empty(t, series)
+
+	// Writing no exemplars doesn't create the file.
+	require.NoError(t, WriteExemplarsFile(dir, []mimirpb.TimeSeries{exemplarsSeries(labels.FromStrings("__name__", "up"))}))
+	_, err = os.Stat(filepath.Join(dir, ExemplarsFilename))
+	require.True(t, os.IsNotExist(err))
+
+	input := []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up", "job", "b"), 30, 10),
+		exemplarsSeries(labels.FromStrings("__name__", "up", "job", "a"), 20),
+		exemplarsSeries(labels.FromStrings("__name__", "down")),
+	}
+	require.NoError(t, WriteExemplarsFile(dir, input))
+
+	series, err = ReadExemplarsFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up", "job", "a"), 20),
+		exemplarsSeries(labels.FromStrings("__name__", "up", "job", "b"), 10, 30),
+	}, series)
+}
+
+func TestReadExemplars_InvalidFile(t *testing.T) {
+	_, err := ReadExemplars(bytes.NewReader([]byte{1, 2, 3, 4, 1}))
+	require.ErrorContains(t, err, "invalid exemplars file magic number")
+
+	_, err = ReadExemplars(bytes.NewReader([]byte{0x45, 0x58, 0x45, 0x4D, 2}))
+	require.ErrorContains(t, err, "unsupported exemplars file version 2")
+}
+
+func TestMergeExemplars(t *testing.T) {
+	first := []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 10, 30),
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 10),
+	}
+	second := []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 20, 30),
+		exemplarsSeries(labels.FromStrings("__name__", "other")),
+	}
+
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 10),
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 10, 20, 30),
+	}, MergeExemplars(first, second))
+}
+
+func TestMergeExemplarsReaders(t *testing.T) {
+	firstDir, secondDir, emptyDir := t.TempDir(), t.TempDir(), t.TempDir()
+
+	require.NoError(t, WriteExemplarsFile(firstDir, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 10, 30),
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 10),
+	}))
+	require.NoError(t, WriteExemplarsFile(secondDir, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 20, 30),
+		exemplarsSeries(labels.FromStrings("__name__", "other"), 40),
+	}))
+
+	var readers []*ExemplarsReader
+	for _, dir := range []string{firstDir, secondDir, emptyDir} {
+		r, err := OpenExemplarsFile(dir)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, r.Close()) })
+		readers = append(readers, r)
+	}
+
+	var merged []mimirpb.TimeSeries
+	require.NoError(t, MergeExemplarsReaders(readers, func(s mimirpb.TimeSeries) error {
+		merged = append(merged, s)
+		return nil
+	}))
+
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 10),
+		exemplarsSeries(labels.FromStrings("__name__", "other"), 40),
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 10, 20, 30),
+	}, merged)
+}
+
+func TestFilterExemplars(t *testing.T) {
+	series := []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 10, 20),
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 10, 20, 30, 40),
+	}
+
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "down"), 20),
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 20, 30),
+	}, FilterExemplars(series, 20, 30, nil))
+
+	assert.Equal(t, []mimirpb.TimeSeries{
+		exemplarsSeries(labels.FromStrings("__name__", "up"), 30, 40),
+	}, FilterExemplars(series, 25, 50, func(lbls labels.Labels) bool {
+		return lbls.Get("__name__") == "up"
+	}))
+}
+
+func TestUpload_ShouldUploadExemplarsFile(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	bkt := objstore.NewInMemBucket()
+
+	b1, err := CreateBlock(ctx, tmpDir, fiveLabels, 100, 0, 1000, labels.FromStrings("ext1", "val1"))
+	require.NoError(t, err)
+
+	input := []mimirpb.TimeSeries{exemplarsSeries(labels.FromStrings("a", "1"), 10, 20)}
+	require.NoError(t, WriteExemplarsFile(filepath.Join(tmpDir, b1.String()), input))
+	require.NoError(t, Upload(ctx, log.NewNopLogger(), bkt, filepath.Join(tmpDir, b1.String()), nil))
+
+	meta, err := DownloadMeta(ctx, log.NewNopLogger(), bkt, b1)
+	require.NoError(t, err)
+	assert.True(t, meta.HasExemplars())
+
+	r, err := bkt.Get(ctx, path.Join(b1.String(), ExemplarsFilename))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	series, err := ReadExemplars(r)
+	require.NoError(t, err)
+	assert.Equal(t, input, series)
+}
+
+func exemplarsSeries(lbls labels.Labels, timestamps ...int64) mimirpb.TimeSeries {
+	s := mimirpb.TimeSeries{Labels: mimirpb.FromLabelsToLabelAdapters(lbls)}
+	for _, ts := range timestamps {
+		s.Exemplars = append(s.Exemplars, mimirpb.Exemplar{
+			Labels:      []mimirpb.LabelAdapter{{Name: "trace_id", Value: "abc"}},
+			Value:       float64(ts),
+			TimestampMs: ts,
+		})
+	}
+	return s
+}
@@ -28,8 +28,10 @@ const (
 	ReceiveSource         SourceType = "receive"
 	CompactorSource       SourceType = "compactor"
 	CompactorRepairSource SourceType = "compactor.repair"
-	BucketRepairSource    SourceType = "bucket.repair"
-	TestSource            SourceType = "test"
+	// CompactorSeriesDeletionSource is the source of blocks rewritten by the compactor to delete series.
+	CompactorSeriesDeletionSource SourceType = "compactor.series-deletion"
+	BucketRepairSource            SourceType = "bucket.repair"
+	TestSource                    SourceType = "test"
 )
 
 const (
@@ -37,6 +37,8 @@ type ChunksCacheConfig struct {
 	AttributesTTL              time.Duration `yaml:"attributes_ttl" category:"advanced"`
 	AttributesInMemoryMaxItems int           `yaml:"attributes_in_memory_max_items" category:"advanced"`
 	SubrangeTTL                time.Duration `yaml:"subrange_ttl" category:"advanced"`
+	ExemplarsTTL               time.Duration `yaml:"exemplars_ttl" category:"experimental"`
+	ExemplarsMaxSize           int           `yaml:"exemplars_max_size_bytes" category:"experimental"`
 }
 
 func (cfg *ChunksCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
@@ -49,6 +51,8 @@ func (cfg *ChunksCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix st
 	f.DurationVar(&cfg.AttributesTTL, prefix+"attributes-ttl", 168*time.Hour, "TTL for caching object attributes for chunks. If the metadata cache is configured, attributes will be stored under this cache backend, otherwise attributes are stored in the chunks cache backend.")
 	f.IntVar(&cfg.AttributesInMemoryMaxItems, prefix+"attributes-in-memory-max-items", 50000, "Maximum number of object attribute items to keep in a first level in-memory LRU cache. Metadata will be stored and fetched in-memory before hitting the cache backend. 0 to disable the in-memory cache.")
 	f.DurationVar(&cfg.SubrangeTTL, prefix+"subrange-ttl", 24*time.Hour, "TTL for caching individual chunks subranges.")
+	f.DurationVar(&cfg.ExemplarsTTL, prefix+"exemplars-ttl", 24*time.Hour, "TTL for caching the exemplars files of the blocks.")
+	f.IntVar(&cfg.ExemplarsMaxSize, prefix+"exemplars-max-size-bytes", 1*1024*1024, "Maximum size of the exemplars file of a block to cache in bytes. Caching will be skipped if the file exceeds this size.")
 }
 
 func (cfg *ChunksCacheConfig) Validate() error {
@@ -137,6 +141,10 @@ func CreateCachingBucket(chunksCache cache.Cache, chunksConfig ChunksCacheConfig
 			}
 		}
 		cfg.CacheGetRange("chunks", chunksCache, isTSDBChunkFile, subrangeSize, attributesCache, chunksConfig.AttributesTTL, chunksConfig.SubrangeTTL, chunksConfig.MaxGetRangeRequests)
+
+		// Blocks are immutable, and the exemplars file is only read when the block meta lists it,
+		// so there's no need to cache whether the file exists.
+		cfg.CacheGet("exemplars", chunksCache, isExemplarsFile, chunksConfig.ExemplarsMaxSize, chunksConfig.ExemplarsTTL, 0, 0)
 	}
 
 	if !cachingConfigured {
@@ -155,6 +163,16 @@ var chunksMatcher = regexp.MustCompile(`^.*/chunks/\d+$`)
 
 func isTSDBChunkFile(name string) bool { return chunksMatcher.MatchString(name) }
 
+func isExemplarsFile(name string) bool {
+	// Ensure the path ends with "<block id>/<exemplars filename>".
+	if !strings.HasSuffix(name, "/"+block.ExemplarsFilename) {
+		return false
+	}
+
+	_, err := ulid.Parse(filepath.Base(filepath.Dir(name)))
+	return err == nil
+}
+
 func isMetaFile(name string) bool {
 	return strings.HasSuffix(name, "/"+block.MetaFilename) || strings.HasSuffix(name, "/"+block.DeletionMarkFilename) || strings.HasSuffix(name, "/"+TenantDeletionMarkPath)
 }
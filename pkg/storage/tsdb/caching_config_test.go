@@ -39,3 +39,14 @@ func TestIsBlockIndexFile(t *testing.T) {
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("%s/index", blockID.String())))
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("/%s/index", blockID.String())))
 }
+
+func TestIsExemplarsFile(t *testing.T) {
+	blockID := ulid.MustNew(1, nil)
+
+	assert.False(t, isExemplarsFile(""))
+	assert.False(t, isExemplarsFile("/exemplars"))
+	assert.False(t, isExemplarsFile("test/exemplars"))
+	assert.False(t, isExemplarsFile(fmt.Sprintf("%s/index", blockID.String())))
+	assert.True(t, isExemplarsFile(fmt.Sprintf("%s/exemplars", blockID.String())))
+	assert.True(t, isExemplarsFile(fmt.Sprintf("user/%s/exemplars", blockID.String())))
+}
@@ -223,6 +223,9 @@ type TSDBConfig struct {
 	// For experimental out of order metrics support.
 	OutOfOrderCapacityMax int `yaml:"out_of_order_capacity_max" category:"experimental"`
 
+	// ExemplarsInBlocksEnabled enables persisting the in-memory exemplars into the blocks shipped to the storage.
+	ExemplarsInBlocksEnabled bool `yaml:"exemplars_in_blocks_enabled" category:"experimental"`
+
 	// HeadPostingsForMatchersCacheTTL is the TTL of the postings for matchers cache in the Head.
 	// If it's 0, the cache will only deduplicate in-flight requests, deleting the results once the first request has finished.
 	HeadPostingsForMatchersCacheTTL time.Duration `yaml:"head_postings_for_matchers_cache_ttl" category:"experimental"`
@@ -295,6 +298,7 @@ func (cfg *TSDBConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.MemorySnapshotOnShutdown, "blocks-storage.tsdb.memory-snapshot-on-shutdown", false, "True to enable snapshotting of in-memory TSDB data on disk when shutting down.")
 	f.IntVar(&cfg.HeadChunksWriteQueueSize, "blocks-storage.tsdb.head-chunks-write-queue-size", 1000000, headChunksWriteQueueSizeHelp)
 	f.IntVar(&cfg.OutOfOrderCapacityMax, "blocks-storage.tsdb.out-of-order-capacity-max", 32, "Maximum capacity for out of order chunks, in samples between 1 and 255.")
+	f.BoolVar(&cfg.ExemplarsInBlocksEnabled, "blocks-storage.tsdb.exemplars-in-blocks-enabled", false, "True to persist the exemplars stored in memory into the blocks uploaded to the storage, so that they can be queried from the store-gateways. Exemplars evicted from memory before the block is uploaded are not persisted.")
 	f.DurationVar(&cfg.HeadPostingsForMatchersCacheTTL, "blocks-storage.tsdb.head-postings-for-matchers-cache-ttl", tsdb.DefaultPostingsForMatchersCacheTTL, "How long to cache postings for matchers in the Head and OOOHead. 0 disables the cache and just deduplicates the in-flight calls.")
 	f.IntVar(&cfg.HeadPostingsForMatchersCacheMaxItems, "blocks-storage.tsdb.head-postings-for-matchers-cache-size", tsdb.DefaultPostingsForMatchersCacheMaxItems, "Maximum number of entries in the cache for postings for matchers in the Head and OOOHead when TTL is greater than 0.")
 	f.Int64Var(&cfg.HeadPostingsForMatchersCacheMaxBytes, "blocks-storage.tsdb.head-postings-for-matchers-cache-max-bytes", tsdb.DefaultPostingsForMatchersCacheMaxBytes, "Maximum size in bytes of the cache for postings for matchers in the Head and OOOHead when TTL is greater than 0.")
@@ -415,6 +419,7 @@ type BucketStoreConfig struct {
 
 	StreamingBatchSize          int    `yaml:"streaming_series_batch_size" category:"advanced"`
 	SeriesSelectionStrategyName string `yaml:"series_selection_strategy" category:"experimental"`
+	MaxExemplarsPerRequest      int    `yaml:"max_exemplars_per_request" category:"experimental"`
 	SelectionStrategies         struct {
 		WorstCaseSeriesPreference float64 `yaml:"worst_case_series_preference" category:"experimental"`
 	} `yaml:"series_selection_strategies"`
@@ -461,6 +466,7 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.Uint64Var(&cfg.PartitionerMaxGapBytes, "blocks-storage.bucket-store.partitioner-max-gap-bytes", DefaultPartitionerMaxGapSize, "Max size - in bytes - of a gap for which the partitioner aggregates together two bucket GET object requests.")
 	f.IntVar(&cfg.StreamingBatchSize, "blocks-storage.bucket-store.batch-series-size", 5000, "This option controls how many series to fetch per batch. The batch size must be greater than 0.")
 	f.StringVar(&cfg.SeriesSelectionStrategyName, seriesSelectionStrategyFlag, WorstCasePostingsStrategy, "This option controls the strategy to selection of series and deferring application of matchers. A more aggressive strategy will fetch less posting lists at the cost of more series. This is useful when querying large blocks in which many series share the same label name and value. Supported values (most aggressive to least aggressive): "+strings.Join(validSeriesSelectionStrategies, ", ")+".")
+	f.IntVar(&cfg.MaxExemplarsPerRequest, "blocks-storage.bucket-store.max-exemplars-per-request", 100000, "Maximum number of exemplars a single exemplars request can return from the blocks of a store-gateway. Requests exceeding the limit fail. 0 to disable the limit.")
 	f.Float64Var(&cfg.SelectionStrategies.WorstCaseSeriesPreference, "blocks-storage.bucket-store.series-selection-strategies.worst-case-series-preference", 0.75, "This option is only used when "+seriesSelectionStrategyFlag+"="+WorstCasePostingsStrategy+". Increasing the series preference results in fetching more series than postings. Must be a positive floating point number.")
 }
 
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+// Relative to user-specific prefix.
+const SeriesDeletionRequestsPath = "markers/series-deletion-requests"
+
+type SeriesDeletionRequestState string
+
+const (
+	// SeriesDeletionRequestPending is the state of a request whose series may still be stored in some blocks.
+	SeriesDeletionRequestPending SeriesDeletionRequestState = "pending"
+
+	// SeriesDeletionRequestProcessed is the state of a request whose series have been removed from all blocks.
+	SeriesDeletionRequestProcessed SeriesDeletionRequestState = "processed"
+)
+
+var (
+	errSeriesDeletionRequestNoSelectors  = errors.New("at least one series selector must be provided")
+	errSeriesDeletionRequestInvalidRange = errors.New("the end time must be greater than or equal to the start time")
+)
+
+// SeriesDeletionRequest is a tenant's request to delete the samples of all series matching at least one
+// of the selectors, within the time range.
+type SeriesDeletionRequest struct {
+	// ID of the request, computed from its selectors and time range.
+	RequestID string `json:"request_id"`
+
+	// Series selectors, in the PromQL format.
+	Selectors []string `json:"selectors"`
+
+	// StartTime and EndTime specify the time range of the samples to delete (millis precision, both inclusive).
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+
+	// Unix timestamp when the request was created.
+	CreatedAt int64 `json:"created_at"`
+
+	State SeriesDeletionRequestState `json:"state"`
+
+	// Unix timestamp when the request was processed.
+	ProcessedAt int64 `json:"processed_at,omitempty"`
+
+	// Blocks written while processing the request, which don't contain the deleted series anymore.
+	RewrittenBlocks []ulid.ULID `json:"rewritten_blocks,omitempty"`
+}
+
+// NewSeriesDeletionRequest creates a pending request, after validating the input selectors and time range.
+func NewSeriesDeletionRequest(selectors []string, startTime, endTime int64, now time.Time) (*SeriesDeletionRequest, error) {
+	if len(selectors) == 0 {
+		return nil, errSeriesDeletionRequestNoSelectors
+	}
+	if endTime < startTime {
+		return nil, errSeriesDeletionRequestInvalidRange
+	}
+
+	sorted := append([]string(nil), selectors...)
+	sort.Strings(sorted)
+
+	req := &SeriesDeletionRequest{
+		Selectors: sorted,
+		StartTime: startTime,
+		EndTime:   endTime,
+		CreatedAt: now.Unix(),
+		State:     SeriesDeletionRequestPending,
+	}
+
+	if _, err := req.Matchers(); err != nil {
+		return nil, err
+	}
+
+	// The ID is computed from the request content, so that submitting the same request
+	// twice doesn't create a new one.
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%d:%d:%s", startTime, endTime, strings.Join(sorted, "\x00"))))
+	req.RequestID = fmt.Sprintf("%016x", h.Sum64())
+
+	return req, nil
+}
+
+// Matchers returns the parsed matchers of each selector of the request.
+func (r *SeriesDeletionRequest) Matchers() ([][]*labels.Matcher, error) {
+	result := make([][]*labels.Matcher, 0, len(r.Selectors))
+
+	for _, selector := range r.Selectors {
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid series selector %q", selector)
+		}
+		result = append(result, matchers)
+	}
+
+	return result, nil
+}
+
+// Overlaps returns whether the request time range overlaps the input one. Input minT and maxT are both inclusive.
+func (r *SeriesDeletionRequest) Overlaps(minT, maxT int64) bool {
+	return r.StartTime <= maxT && minT <= r.EndTime
+}
+
+func (r *SeriesDeletionRequest) GetCreatedAt() time.Time {
+	return time.Unix(r.CreatedAt, 0)
+}
+
+// GetProcessedAt returns the time when the request was processed, or the zero time if it's still pending.
+func (r *SeriesDeletionRequest) GetProcessedAt() time.Time {
+	if r.ProcessedAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(r.ProcessedAt, 0)
+}
+
+// IsBlockRewritten returns whether the block has been written while processing the request.
+func (r *SeriesDeletionRequest) IsBlockRewritten(id ulid.ULID) bool {
+	for _, rewritten := range r.RewrittenBlocks {
+		if rewritten == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SeriesDeletionRequestFilepath returns the path, relative to the tenant's bucket location,
+// of the series deletion request with the given ID.
+func SeriesDeletionRequestFilepath(requestID string) string {
+	return path.Join(SeriesDeletionRequestsPath, requestID+".json")
+}
+
+// WriteSeriesDeletionRequest uploads the series deletion request to the tenant location in the bucket,
+// overwriting the existing request with the same ID, if any.
+func WriteSeriesDeletionRequest(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, req *SeriesDeletionRequest) error {
+	bkt = bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "serialize series deletion request")
+	}
+
+	return errors.Wrap(bkt.Upload(ctx, SeriesDeletionRequestFilepath(req.RequestID), bytes.NewReader(data)), "upload series deletion request")
+}
+
+// DeleteSeriesDeletionRequest deletes the series deletion request with the given ID from the tenant location
+// in the bucket. Deleting a request which doesn't exist isn't an error.
+func DeleteSeriesDeletionRequest(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, requestID string) error {
+	bkt = bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	err := bkt.Delete(ctx, SeriesDeletionRequestFilepath(requestID))
+	if bkt.IsObjNotFoundErr(err) {
+		return nil
+	}
+	return errors.Wrap(err, "delete series deletion request")
+}
+
+// ListSeriesDeletionRequests returns all the series deletion requests of the tenant, sorted by creation time.
+func ListSeriesDeletionRequests(ctx context.Context, bkt objstore.BucketReader, userID string) ([]*SeriesDeletionRequest, error) {
+	var names []string
+
+	err := bkt.Iter(ctx, path.Join(userID, SeriesDeletionRequestsPath)+"/", func(name string) error {
+		if strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list series deletion requests")
+	}
+
+	requests := make([]*SeriesDeletionRequest, 0, len(names))
+	for _, name := range names {
+		req, err := readSeriesDeletionRequest(ctx, bkt, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// The request may have been deleted in the meanwhile.
+		if req != nil {
+			requests = append(requests, req)
+		}
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].CreatedAt != requests[j].CreatedAt {
+			return requests[i].CreatedAt < requests[j].CreatedAt
+		}
+		return requests[i].RequestID < requests[j].RequestID
+	})
+
+	return requests, nil
+}
+
+// ReadSeriesDeletionRequest returns the series deletion request with the given ID. If it doesn't exist, returns nil request, and no error.
+func ReadSeriesDeletionRequest(ctx context.Context, bkt objstore.BucketReader, userID, requestID string) (*SeriesDeletionRequest, error) {
+	return readSeriesDeletionRequest(ctx, bkt, path.Join(userID, SeriesDeletionRequestFilepath(requestID)))
+}
+
+func readSeriesDeletionRequest(ctx context.Context, bkt objstore.BucketReader, name string) (*SeriesDeletionRequest, error) {
+	r, err := bkt.Get(ctx, name)
+	if err != nil {
+		if bkt.IsObjNotFoundErr(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read series deletion request object: %s", name)
+	}
+
+	req := &SeriesDeletionRequest{}
+	err = json.NewDecoder(r).Decode(req)
+
+	// Close reader before dealing with decode error.
+	if closeErr := r.Close(); closeErr != nil {
+		level.Warn(util_log.Logger).Log("msg", "failed to close bucket reader", "err", closeErr)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode series deletion request object: %s", name)
+	}
+
+	return req, nil
+}
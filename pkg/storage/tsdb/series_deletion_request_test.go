@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestNewSeriesDeletionRequest(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	for name, tc := range map[string]struct {
+		selectors   []string
+		start, end  int64
+		expectedErr string
+	}{
+		"valid request": {
+			selectors: []string{`up{job="test"}`, `{__name__=~"foo.*"}`},
+			start:     10,
+			end:       20,
+		},
+		"same start and end time": {
+			selectors: []string{`up`},
+			start:     10,
+			end:       10,
+		},
+		"no selectors": {
+			start:       10,
+			end:         20,
+			expectedErr: errSeriesDeletionRequestNoSelectors.Error(),
+		},
+		"end time before start time": {
+			selectors:   []string{`up`},
+			start:       20,
+			end:         10,
+			expectedErr: errSeriesDeletionRequestInvalidRange.Error(),
+		},
+		"invalid selector": {
+			selectors:   []string{`up{`},
+			start:       10,
+			end:         20,
+			expectedErr: `invalid series selector "up{"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := NewSeriesDeletionRequest(tc.selectors, tc.start, tc.end, now)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, req.RequestID)
+			assert.Equal(t, SeriesDeletionRequestPending, req.State)
+			assert.Equal(t, now.Unix(), req.CreatedAt)
+
+			matchers, err := req.Matchers()
+			require.NoError(t, err)
+			assert.Len(t, matchers, len(tc.selectors))
+		})
+	}
+}
+
+func TestNewSeriesDeletionRequest_ShouldComputeIDFromContent(t *testing.T) {
+	first, err := NewSeriesDeletionRequest([]string{`a`, `b`}, 10, 20, time.Unix(1000, 0))
+	require.NoError(t, err)
+
+	// The order of the selectors and the creation time don't affect the ID.
+	second, err := NewSeriesDeletionRequest([]string{`b`, `a`}, 10, 20, time.Unix(2000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, first.RequestID, second.RequestID)
+
+	third, err := NewSeriesDeletionRequest([]string{`a`, `b`}, 10, 21, time.Unix(1000, 0))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RequestID, third.RequestID)
+}
+
+func TestSeriesDeletionRequest_Overlaps(t *testing.T) {
+	req, err := NewSeriesDeletionRequest([]string{`up`}, 10, 20, time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, req.Overlaps(0, 10))
+	assert.True(t, req.Overlaps(15, 16))
+	assert.True(t, req.Overlaps(20, 30))
+	assert.False(t, req.Overlaps(0, 9))
+	assert.False(t, req.Overlaps(21, 30))
+}
+
+func TestSeriesDeletionRequests_WriteListRead(t *testing.T) {
+	const userID = "user"
+
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	// Reading a non existing request returns no error.
+	req, err := ReadSeriesDeletionRequest(ctx, bkt, userID, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, req)
+
+	first, err := NewSeriesDeletionRequest([]string{`up`}, 10, 20, time.Unix(2000, 0))
+	require.NoError(t, err)
+	second, err := NewSeriesDeletionRequest([]string{`down`}, 10, 20, time.Unix(1000, 0))
+	require.NoError(t, err)
+	other, err := NewSeriesDeletionRequest([]string{`up`}, 10, 20, time.Unix(1000, 0))
+	require.NoError(t, err)
+
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, userID, nil, first))
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, userID, nil, second))
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, "other", nil, other))
+
+	requests, err := ListSeriesDeletionRequests(ctx, bkt, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []*SeriesDeletionRequest{second, first}, requests)
+
+	// Update a request.
+	first.State = SeriesDeletionRequestProcessed
+	first.ProcessedAt = 3000
+	require.NoError(t, WriteSeriesDeletionRequest(ctx, bkt, userID, nil, first))
+
+	req, err = ReadSeriesDeletionRequest(ctx, bkt, userID, first.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, first, req)
+
+	// Delete a request.
+	require.NoError(t, DeleteSeriesDeletionRequest(ctx, bkt, userID, nil, first.RequestID))
+	requests, err = ListSeriesDeletionRequests(ctx, bkt, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []*SeriesDeletionRequest{second}, requests)
+
+	// Deleting a non existing request returns no error.
+	require.NoError(t, DeleteSeriesDeletionRequest(ctx, bkt, userID, nil, first.RequestID))
+}
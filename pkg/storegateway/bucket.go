@@ -113,6 +113,9 @@ type BucketStore struct {
 	seriesLimiterFactory SeriesLimiterFactory
 	partitioners         blockPartitioners
 
+	// Maximum number of exemplars returned by each Exemplars() call. 0 to disable the limit.
+	maxExemplarsPerRequest int
+
 	// Every how many posting offset entry we pool in heap memory. Default in Prometheus is 32.
 	postingOffsetsInMemSampling int
 
@@ -228,6 +231,7 @@ func NewBucketStore(
 		userID:                      userID,
 		maxSeriesPerBatch:           bucketStoreConfig.StreamingBatchSize,
 		postingsStrategy:            postingsStrategy,
+		maxExemplarsPerRequest:      bucketStoreConfig.MaxExemplarsPerRequest,
 	}
 
 	for _, option := range options {
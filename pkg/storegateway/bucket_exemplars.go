@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/runutil"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storegateway/hintspb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+const (
+	// maxConcurrentExemplarsBlocks is the maximum number of blocks whose exemplars are read concurrently by each
+	// Exemplars() call.
+	maxConcurrentExemplarsBlocks = 16
+
+	maxExemplarsHitMsgFormat = "the exemplars request exceeded the maximum number of exemplars (limit: %d exemplars), narrow down the time range or the series selectors"
+)
+
+// Exemplars returns the exemplars stored in the blocks for the requested series selectors and time range.
+func (s *BucketStore) Exemplars(ctx context.Context, req *storepb.ExemplarsRequest) (*storepb.ExemplarsResponse, error) {
+	reqSeriesMatchers := make([][]*labels.Matcher, 0, len(req.Matchers))
+	for _, m := range req.Matchers {
+		matchers, err := storepb.MatchersToPromMatchers(m.Matchers...)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request labels matchers").Error())
+		}
+		reqSeriesMatchers = append(reqSeriesMatchers, matchers)
+	}
+
+	resHints := &hintspb.ExemplarsResponseHints{}
+
+	var reqBlockMatchers []*labels.Matcher
+	if req.Hints != nil {
+		reqHints := &hintspb.ExemplarsRequestHints{}
+		err := types.UnmarshalAny(req.Hints, reqHints)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "unmarshal exemplars request hints").Error())
+		}
+
+		reqBlockMatchers, err = storepb.MatchersToPromMatchers(reqHints.BlockMatchers...)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request hints labels matchers").Error())
+		}
+	}
+
+	selected := func(lbls labels.Labels) bool {
+		for _, matchers := range reqSeriesMatchers {
+			if matchesAll(lbls, matchers) {
+				return true
+			}
+		}
+		return len(reqSeriesMatchers) == 0
+	}
+
+	s.blocksMx.RLock()
+
+	var blocks []*bucketBlock
+	for _, b := range s.blocks {
+		if !b.overlapsClosedInterval(req.Start, req.End) {
+			continue
+		}
+		if len(reqBlockMatchers) > 0 && !b.matchLabels(reqBlockMatchers) {
+			continue
+		}
+
+		resHints.AddQueriedBlock(b.meta.ULID)
+
+		// Blocks uploaded without exemplars don't have the exemplars file.
+		if b.meta.HasExemplars() {
+			blocks = append(blocks, b)
+		}
+	}
+
+	s.blocksMx.RUnlock()
+
+	// Reading the exemplars files is as expensive as a series request, so it's subject to the same query gate.
+	span, spanCtx := opentracing.StartSpanFromContext(ctx, "store_query_gate_ismyturn")
+	err := s.queryGate.Start(spanCtx)
+	span.Finish()
+	if err != nil {
+		return nil, status.Error(codes.Aborted, errors.Wrap(err, "failed to wait for turn").Error())
+	}
+	defer s.queryGate.Done()
+
+	limiter := NewLimiter(uint64(s.maxExemplarsPerRequest), s.metrics.queriesDropped.WithLabelValues("exemplars"), maxExemplarsHitMsgFormat)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentExemplarsBlocks)
+
+	var mtx sync.Mutex
+	var sets [][]mimirpb.TimeSeries
+
+	for _, b := range blocks {
+		b := b
+
+		g.Go(func() error {
+			result, err := blockExemplars(gctx, b, req.Start, req.End, selected, limiter)
+			if err != nil {
+				return errors.Wrapf(err, "block %s", b.meta.ULID)
+			}
+
+			if len(result) > 0 {
+				mtx.Lock()
+				sets = append(sets, result)
+				mtx.Unlock()
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, status.Error(codes.Canceled, err.Error())
+		}
+		// The limit errors are returned as is, so that the querier doesn't retry them on other store-gateways.
+		if _, ok := httpgrpc.HTTPResponseFromError(errors.Cause(err)); ok {
+			return nil, errors.Cause(err)
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	anyHints, err := types.MarshalAny(resHints)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, errors.Wrap(err, "marshal exemplars response hints").Error())
+	}
+
+	return &storepb.ExemplarsResponse{
+		Timeseries: block.MergeExemplars(sets...),
+		Hints:      anyHints,
+	}, nil
+}
+
+// blockExemplars streams the exemplars file of the block, returning the exemplars of the selected series between
+// minT and maxT. The returned exemplars are reserved from the limiter.
+func blockExemplars(ctx context.Context, b *bucketBlock, minT, maxT int64, selected func(labels.Labels) bool, limiter *Limiter) ([]mimirpb.TimeSeries, error) {
+	r, err := b.bkt.Get(ctx, path.Join(b.meta.ULID.String(), block.ExemplarsFilename))
+	if err != nil {
+		return nil, errors.Wrap(err, "get exemplars file")
+	}
+	defer runutil.CloseWithLogOnErr(b.logger, r, "close exemplars file reader")
+
+	er, err := block.NewExemplarsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []mimirpb.TimeSeries
+	for er.Next() {
+		filtered := block.FilterExemplars([]mimirpb.TimeSeries{er.At()}, minT, maxT, selected)
+		for _, s := range filtered {
+			if err := limiter.Reserve(uint64(len(s.Exemplars))); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, filtered...)
+	}
+
+	return result, er.Err()
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/types"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpc_metadata "google.golang.org/grpc/metadata"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/bucket/filesystem"
+	"github.com/grafana/mimir/pkg/storage/tsdb/block"
+	"github.com/grafana/mimir/pkg/storegateway/hintspb"
+	"github.com/grafana/mimir/pkg/storegateway/storepb"
+)
+
+func TestBucketStores_Exemplars(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	storageDir := t.TempDir()
+
+	// Generate two blocks, and store exemplars only in the first one.
+	generateStorageBlock(t, storageDir, userID, "series_1", 0, 100, 10)
+	generateStorageBlock(t, storageDir, userID, "series_2", 200, 300, 10)
+
+	blocks := openPromBlocks(t, filepath.Join(storageDir, userID))
+	require.Len(t, blocks, 2)
+
+	withExemplars, withoutExemplars := blocks[0].Meta(), blocks[1].Meta()
+	if withExemplars.MinTime > withoutExemplars.MinTime {
+		withExemplars, withoutExemplars = withoutExemplars, withExemplars
+	}
+
+	blockDir := filepath.Join(storageDir, userID, withExemplars.ULID.String())
+	require.NoError(t, block.WriteExemplarsFile(blockDir, []mimirpb.TimeSeries{
+		exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "a"), 10, 50, 90),
+		exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "b"), 20),
+	}))
+
+	// Track the exemplars file in the block meta.json, like the block upload does.
+	meta, err := block.ReadMetaFromDir(blockDir)
+	require.NoError(t, err)
+	meta.Thanos.Files, err = block.GatherFileStats(blockDir)
+	require.NoError(t, err)
+	require.NoError(t, meta.WriteToDir(log.NewNopLogger(), blockDir))
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	stores, err := NewBucketStores(cfg, newNoShardingStrategy(), bucket, defaultLimitsOverrides(t), log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	userCtx := grpc_metadata.NewIncomingContext(ctx, grpc_metadata.Pairs(GrpcContextMetadataTenantID, userID))
+
+	tests := map[string]struct {
+		start, end            int64
+		matchers              []storepb.ExemplarMatchers
+		expectedSeries        []mimirpb.TimeSeries
+		expectedQueriedBlocks int
+	}{
+		"query all exemplars": {
+			start:                 0,
+			end:                   1000,
+			matchers:              []storepb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}}},
+			expectedQueriedBlocks: 2,
+			expectedSeries: []mimirpb.TimeSeries{
+				exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "a"), 10, 50, 90),
+				exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "b"), 20),
+			},
+		},
+		"query a time range": {
+			start:                 15,
+			end:                   50,
+			matchers:              []storepb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_1"}}}},
+			expectedQueriedBlocks: 1,
+			expectedSeries: []mimirpb.TimeSeries{
+				exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "a"), 50),
+				exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "b"), 20),
+			},
+		},
+		"query multiple series selectors": {
+			start: 0,
+			end:   1000,
+			matchers: []storepb.ExemplarMatchers{
+				{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: "job", Value: "b"}}},
+				{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: "job", Value: "unknown"}}},
+			},
+			expectedQueriedBlocks: 2,
+			expectedSeries: []mimirpb.TimeSeries{
+				exemplarsTestSeries(labels.FromStrings(labels.MetricName, "series_1", "job", "b"), 20),
+			},
+		},
+		"query a block without exemplars": {
+			start:                 200,
+			end:                   300,
+			matchers:              []storepb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}}},
+			expectedQueriedBlocks: 1,
+			expectedSeries:        []mimirpb.TimeSeries{},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			resp, err := stores.Exemplars(userCtx, &storepb.ExemplarsRequest{
+				Start:    testData.start,
+				End:      testData.end,
+				Matchers: testData.matchers,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedSeries, resp.Timeseries)
+
+			hints := hintspb.ExemplarsResponseHints{}
+			require.NoError(t, types.UnmarshalAny(resp.Hints, &hints))
+			assert.Len(t, hints.QueriedBlocks, testData.expectedQueriedBlocks)
+		})
+	}
+
+	t.Run("block matchers in the request hints", func(t *testing.T) {
+		reqHints, err := types.MarshalAny(&hintspb.ExemplarsRequestHints{
+			BlockMatchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: block.BlockIDLabel, Value: withoutExemplars.ULID.String()}},
+		})
+		require.NoError(t, err)
+
+		resp, err := stores.Exemplars(userCtx, &storepb.ExemplarsRequest{
+			Start:    0,
+			End:      1000,
+			Matchers: []storepb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}}},
+			Hints:    reqHints,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Timeseries)
+
+		hints := hintspb.ExemplarsResponseHints{}
+		require.NoError(t, types.UnmarshalAny(resp.Hints, &hints))
+		assert.Equal(t, []hintspb.Block{{Id: withoutExemplars.ULID.String()}}, hints.QueriedBlocks)
+	})
+
+	t.Run("max exemplars per request", func(t *testing.T) {
+		limitedCfg := cfg
+		limitedCfg.BucketStore.SyncDir = t.TempDir()
+		limitedCfg.BucketStore.MaxExemplarsPerRequest = 3
+
+		reg := prometheus.NewPedanticRegistry()
+		limitedStores, err := NewBucketStores(limitedCfg, newNoShardingStrategy(), bucket, defaultLimitsOverrides(t), log.NewNopLogger(), reg)
+		require.NoError(t, err)
+		require.NoError(t, limitedStores.InitialSync(ctx))
+
+		matchers := []storepb.ExemplarMatchers{{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_1"}}}}
+
+		// The request within the limit succeeds.
+		resp, err := limitedStores.Exemplars(userCtx, &storepb.ExemplarsRequest{Start: 15, End: 50, Matchers: matchers})
+		require.NoError(t, err)
+		assert.Len(t, resp.Timeseries, 2)
+
+		// The request exceeding the limit fails.
+		_, err = limitedStores.Exemplars(userCtx, &storepb.ExemplarsRequest{Start: 0, End: 1000, Matchers: matchers})
+		require.Error(t, err)
+		res, ok := httpgrpc.HTTPResponseFromError(err)
+		require.True(t, ok)
+		assert.Equal(t, int32(http.StatusUnprocessableEntity), res.Code)
+		assert.Contains(t, string(res.Body), "the exemplars request exceeded the maximum number of exemplars (limit: 3 exemplars)")
+
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_bucket_store_queries_dropped_total Number of queries that were dropped due to the max chunks per query limit.
+			# TYPE cortex_bucket_store_queries_dropped_total counter
+			cortex_bucket_store_queries_dropped_total{reason="exemplars"} 1
+		`), "cortex_bucket_store_queries_dropped_total"))
+	})
+
+	t.Run("tenant without blocks", func(t *testing.T) {
+		otherCtx := grpc_metadata.NewIncomingContext(ctx, grpc_metadata.Pairs(GrpcContextMetadataTenantID, "other"))
+		resp, err := stores.Exemplars(otherCtx, &storepb.ExemplarsRequest{Start: 0, End: 1000})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Timeseries)
+	})
+}
+
+func exemplarsTestSeries(lbls labels.Labels, timestamps ...int64) mimirpb.TimeSeries {
+	s := mimirpb.TimeSeries{Labels: mimirpb.FromLabelsToLabelAdapters(lbls)}
+	for _, ts := range timestamps {
+		s.Exemplars = append(s.Exemplars, mimirpb.Exemplar{
+			Labels:      []mimirpb.LabelAdapter{{Name: "trace_id", Value: "abc"}},
+			Value:       float64(ts),
+			TimestampMs: ts,
+		})
+	}
+	return s
+}
@@ -348,6 +348,24 @@ func (u *BucketStores) LabelNames(ctx context.Context, req *storepb.LabelNamesRe
 	return store.LabelNames(ctx, req)
 }
 
+// Exemplars returns the exemplars stored in the blocks of the tenant.
+func (u *BucketStores) Exemplars(ctx context.Context, req *storepb.ExemplarsRequest) (*storepb.ExemplarsResponse, error) {
+	spanLog, spanCtx := spanlogger.NewWithLogger(ctx, u.logger, "BucketStores.Exemplars")
+	defer spanLog.Span.Finish()
+
+	userID := getUserIDFromGRPCContext(spanCtx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	store := u.getStore(userID)
+	if store == nil {
+		return &storepb.ExemplarsResponse{}, nil
+	}
+
+	return store.Exemplars(ctx, req)
+}
+
 // LabelValues implements the storepb.StoreServer interface.
 func (u *BucketStores) LabelValues(ctx context.Context, req *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error) {
 	spanLog, spanCtx := spanlogger.NewWithLogger(ctx, u.logger, "BucketStores.LabelValues")
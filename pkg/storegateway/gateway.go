@@ -340,6 +340,16 @@ func (g *StoreGateway) LabelValues(ctx context.Context, req *storepb.LabelValues
 	return g.stores.LabelValues(ctx, req)
 }
 
+// Exemplars implements the storegatewaypb.StoreGatewayServer interface.
+func (g *StoreGateway) Exemplars(ctx context.Context, req *storepb.ExemplarsRequest) (*storepb.ExemplarsResponse, error) {
+	ix := g.tracker.Insert(func() string {
+		return requestActivity(ctx, "StoreGateway/Exemplars", req)
+	})
+	defer g.tracker.Delete(ix)
+
+	return g.stores.Exemplars(ctx, req)
+}
+
 func requestActivity(ctx context.Context, name string, req interface{}) string {
 	user := getUserIDFromGRPCContext(ctx)
 	traceID, _ := tracing.ExtractSampledTraceID(ctx)
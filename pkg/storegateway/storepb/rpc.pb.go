@@ -9,6 +9,7 @@ import (
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
 	types "github.com/gogo/protobuf/types"
+	mimirpb "github.com/grafana/mimir/pkg/mimirpb"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -440,6 +441,128 @@ func (m *LabelValuesResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_LabelValuesResponse proto.InternalMessageInfo
 
+type ExemplarsRequest struct {
+	Start int64 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   int64 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	/// matchers is the list of series selectors. A series is selected if it matches all the label matchers
+	/// of at least one selector.
+	Matchers []ExemplarMatchers `protobuf:"bytes,3,rep,name=matchers,proto3" json:"matchers"`
+	// hints is an opaque data structure that can be used to carry additional information.
+	// The content of this field and whether it's supported depends on the
+	// implementation of a specific store.
+	Hints *types.Any `protobuf:"bytes,4,opt,name=hints,proto3" json:"hints,omitempty"`
+}
+
+func (m *ExemplarsRequest) Reset()      { *m = ExemplarsRequest{} }
+func (*ExemplarsRequest) ProtoMessage() {}
+func (*ExemplarsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{7}
+}
+func (m *ExemplarsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExemplarsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExemplarsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExemplarsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExemplarsRequest.Merge(m, src)
+}
+func (m *ExemplarsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExemplarsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExemplarsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExemplarsRequest proto.InternalMessageInfo
+
+type ExemplarMatchers struct {
+	Matchers []LabelMatcher `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers"`
+}
+
+func (m *ExemplarMatchers) Reset()      { *m = ExemplarMatchers{} }
+func (*ExemplarMatchers) ProtoMessage() {}
+func (*ExemplarMatchers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{8}
+}
+func (m *ExemplarMatchers) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExemplarMatchers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExemplarMatchers.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExemplarMatchers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExemplarMatchers.Merge(m, src)
+}
+func (m *ExemplarMatchers) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExemplarMatchers) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExemplarMatchers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExemplarMatchers proto.InternalMessageInfo
+
+type ExemplarsResponse struct {
+	/// timeseries contains the labels and the exemplars of each series. Samples and histograms are never set.
+	Timeseries []mimirpb.TimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries"`
+	Warnings   []string             `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	/// hints is an opaque data structure that can be used to carry additional information from
+	/// the store. The content of this field and whether it's supported depends on the
+	/// implementation of a specific store.
+	Hints *types.Any `protobuf:"bytes,3,opt,name=hints,proto3" json:"hints,omitempty"`
+}
+
+func (m *ExemplarsResponse) Reset()      { *m = ExemplarsResponse{} }
+func (*ExemplarsResponse) ProtoMessage() {}
+func (*ExemplarsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{9}
+}
+func (m *ExemplarsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExemplarsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExemplarsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExemplarsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExemplarsResponse.Merge(m, src)
+}
+func (m *ExemplarsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExemplarsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExemplarsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExemplarsResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*SeriesRequest)(nil), "thanos.SeriesRequest")
 	proto.RegisterType((*Stats)(nil), "thanos.Stats")
@@ -448,63 +571,72 @@ func init() {
 	proto.RegisterType((*LabelNamesResponse)(nil), "thanos.LabelNamesResponse")
 	proto.RegisterType((*LabelValuesRequest)(nil), "thanos.LabelValuesRequest")
 	proto.RegisterType((*LabelValuesResponse)(nil), "thanos.LabelValuesResponse")
+	proto.RegisterType((*ExemplarsRequest)(nil), "thanos.ExemplarsRequest")
+	proto.RegisterType((*ExemplarMatchers)(nil), "thanos.ExemplarMatchers")
+	proto.RegisterType((*ExemplarsResponse)(nil), "thanos.ExemplarsResponse")
 }
 
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor_77a6da22d6a3feb1) }
 
 var fileDescriptor_77a6da22d6a3feb1 = []byte{
-	// 803 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x94, 0x41, 0x6f, 0xe2, 0x46,
-	0x14, 0xc7, 0x3d, 0x78, 0x6c, 0x86, 0x21, 0xa4, 0xce, 0x24, 0x4d, 0x8d, 0x53, 0x39, 0x08, 0xa9,
-	0x12, 0xaa, 0x5a, 0x52, 0xa5, 0x52, 0xab, 0x1e, 0x7a, 0x08, 0x51, 0x2b, 0x62, 0xb5, 0x3d, 0x38,
-	0x55, 0x0f, 0x95, 0x2a, 0x64, 0x60, 0x02, 0x56, 0xb0, 0x4d, 0x3d, 0x43, 0x1b, 0x72, 0xea, 0x47,
-	0xe8, 0xc7, 0x58, 0xed, 0x7e, 0x82, 0xbd, 0xee, 0x29, 0xb7, 0xcd, 0x31, 0xa7, 0xd5, 0x42, 0x2e,
-	0x7b, 0xcc, 0x47, 0x58, 0x79, 0x3c, 0x60, 0xbc, 0x4b, 0x94, 0x8d, 0xb4, 0x37, 0xde, 0xff, 0xff,
-	0xfc, 0xe6, 0xcd, 0xef, 0xbd, 0x01, 0x97, 0xe2, 0x71, 0xaf, 0x39, 0x8e, 0x23, 0x1e, 0x11, 0x9d,
-	0x0f, 0xbd, 0x30, 0x62, 0x56, 0x99, 0x4f, 0xc7, 0x94, 0xa5, 0xa2, 0xf5, 0xf5, 0xc0, 0xe7, 0xc3,
-	0x49, 0xb7, 0xd9, 0x8b, 0x82, 0x83, 0x41, 0x34, 0x88, 0x0e, 0x84, 0xdc, 0x9d, 0x9c, 0x89, 0x48,
-	0x04, 0xe2, 0x97, 0x4c, 0xaf, 0x0e, 0xa2, 0x68, 0x30, 0xa2, 0x59, 0x96, 0x17, 0x4e, 0x53, 0xab,
-	0xfe, 0xbc, 0x80, 0x2b, 0xa7, 0x34, 0xf6, 0x29, 0x73, 0xe9, 0xdf, 0x13, 0xca, 0x38, 0xa9, 0x62,
-	0x14, 0xf8, 0x61, 0x87, 0xfb, 0x01, 0x35, 0x41, 0x0d, 0x34, 0x54, 0xb7, 0x18, 0xf8, 0xe1, 0xef,
-	0x7e, 0x40, 0x85, 0xe5, 0x5d, 0xa4, 0x56, 0x41, 0x5a, 0xde, 0x85, 0xb0, 0xbe, 0x4b, 0x2c, 0xde,
-	0x1b, 0xd2, 0x98, 0x99, 0x6a, 0x4d, 0x6d, 0x94, 0x0f, 0x77, 0x9a, 0x69, 0xe7, 0xcd, 0x5f, 0xbc,
-	0x2e, 0x1d, 0xfd, 0x9a, 0x9a, 0x2d, 0x78, 0xf5, 0x6a, 0x5f, 0x71, 0x97, 0xb9, 0x64, 0x1f, 0x97,
-	0xd9, 0xb9, 0x3f, 0xee, 0xf4, 0x86, 0x93, 0xf0, 0x9c, 0x99, 0xa8, 0x06, 0x1a, 0xc8, 0xc5, 0x89,
-	0x74, 0x2c, 0x14, 0xf2, 0x25, 0xd6, 0x86, 0x7e, 0xc8, 0x99, 0x59, 0xaa, 0x01, 0x51, 0x35, 0xbd,
-	0x4b, 0x73, 0x71, 0x97, 0xe6, 0x51, 0x38, 0x75, 0xd3, 0x14, 0xf2, 0x23, 0xde, 0x63, 0x3c, 0xa6,
-	0x5e, 0xe0, 0x87, 0x03, 0x59, 0xb1, 0xd3, 0x4d, 0x4e, 0xea, 0x30, 0xff, 0x92, 0x9a, 0xfd, 0x1a,
-	0x68, 0x40, 0xd7, 0x5c, 0xa6, 0xa4, 0x27, 0xb4, 0x92, 0x84, 0x53, 0xff, 0x92, 0x3a, 0x10, 0x41,
-	0x43, 0x73, 0x20, 0xd2, 0x0c, 0xdd, 0x81, 0x48, 0x37, 0x8a, 0x0e, 0x44, 0x45, 0x03, 0x39, 0x10,
-	0x61, 0xa3, 0xec, 0x40, 0x54, 0x36, 0x36, 0x1c, 0x88, 0x36, 0x8c, 0x8a, 0x03, 0x51, 0xc5, 0xd8,
-	0xac, 0x7f, 0x8f, 0xb5, 0x53, 0xee, 0x71, 0x46, 0x9a, 0x78, 0xfb, 0x8c, 0x26, 0x17, 0xea, 0x77,
-	0xfc, 0xb0, 0x4f, 0x2f, 0x3a, 0xdd, 0x29, 0xa7, 0x4c, 0xd0, 0x83, 0xee, 0x96, 0xb4, 0x4e, 0x12,
-	0xa7, 0x95, 0x18, 0xf5, 0xa7, 0x2a, 0xde, 0x5c, 0x40, 0x67, 0xe3, 0x28, 0x64, 0x94, 0x34, 0xb0,
-	0xce, 0x84, 0x22, 0xbe, 0x2a, 0x1f, 0x6e, 0x2e, 0xe8, 0xa5, 0x79, 0x6d, 0xc5, 0x95, 0x3e, 0xb1,
-	0x70, 0xf1, 0x5f, 0x2f, 0x0e, 0xfd, 0x70, 0x20, 0x66, 0x50, 0x6a, 0x2b, 0xee, 0x42, 0x20, 0x5f,
-	0x2d, 0x60, 0xa9, 0xf7, 0xc3, 0x6a, 0x2b, 0x0b, 0x5c, 0x5f, 0x60, 0x8d, 0x25, 0xfd, 0x9b, 0x50,
-	0x64, 0x57, 0x96, 0x47, 0x26, 0x62, 0x92, 0x26, 0x5c, 0x72, 0x82, 0x8d, 0x8c, 0xaa, 0x6c, 0x52,
-	0x13, 0x5f, 0x7c, 0x9e, 0x7d, 0x21, 0xfd, 0xb4, 0x5b, 0x81, 0xb4, 0xad, 0xb8, 0x9f, 0xb0, 0xbc,
-	0x9e, 0x2f, 0x25, 0x47, 0xae, 0xdf, 0x53, 0x6a, 0x65, 0x3a, 0xb9, 0x52, 0x72, 0x2f, 0xfe, 0xc2,
-	0xd5, 0xf7, 0x66, 0x4d, 0x19, 0xf7, 0x03, 0x8f, 0x53, 0xb3, 0x28, 0x6a, 0xee, 0xdf, 0x53, 0xf3,
-	0x27, 0x99, 0xd6, 0x56, 0xdc, 0xcf, 0xd8, 0x7a, 0xab, 0x85, 0xb0, 0x1e, 0x53, 0x36, 0x19, 0xf1,
-	0xfa, 0x33, 0x80, 0xb7, 0xc4, 0x0a, 0xff, 0xe6, 0x05, 0xd9, 0x2b, 0xd9, 0x11, 0xec, 0x62, 0x2e,
-	0x48, 0xab, 0x6e, 0x1a, 0x10, 0x03, 0xab, 0x34, 0xec, 0x0b, 0x9e, 0xaa, 0x9b, 0xfc, 0xcc, 0xd6,
-	0x57, 0x7b, 0x78, 0x7d, 0x57, 0xdf, 0x90, 0xfe, 0xe1, 0x6f, 0xc8, 0x81, 0x08, 0x18, 0x05, 0x07,
-	0xa2, 0x82, 0xa1, 0xd6, 0x63, 0x4c, 0x56, 0x9b, 0x95, 0xdb, 0xb5, 0x83, 0xb5, 0x30, 0x11, 0x4c,
-	0x50, 0x53, 0x1b, 0x25, 0x37, 0x0d, 0x88, 0x85, 0x91, 0x5c, 0x1c, 0x66, 0x16, 0x84, 0xb1, 0x8c,
-	0xb3, 0xbe, 0xd5, 0x07, 0xfb, 0xae, 0xbf, 0x00, 0xf2, 0xd0, 0x3f, 0xbc, 0xd1, 0x24, 0x87, 0x68,
-	0x94, 0xa8, 0x62, 0xa3, 0x4b, 0x6e, 0x1a, 0x64, 0xe0, 0xe0, 0x1a, 0x70, 0xda, 0x1a, 0x70, 0xfa,
-	0xe3, 0xc0, 0x15, 0x1f, 0x05, 0xae, 0x60, 0xa8, 0x0e, 0x44, 0xaa, 0x01, 0xeb, 0x13, 0xbc, 0x9d,
-	0xbb, 0x83, 0x24, 0xb7, 0x8b, 0xf5, 0x7f, 0x84, 0x22, 0xd1, 0xc9, 0xe8, 0x63, 0xb1, 0x3b, 0x7c,
-	0x09, 0x92, 0x3f, 0x91, 0x28, 0xa6, 0xe4, 0x07, 0xac, 0xcb, 0x57, 0xf2, 0x69, 0xfe, 0xed, 0x4b,
-	0x9e, 0xd6, 0xee, 0xbb, 0x72, 0xda, 0xe2, 0x37, 0x80, 0x1c, 0x63, 0x9c, 0x0d, 0x9d, 0x54, 0x73,
-	0x77, 0x5f, 0xdd, 0x5a, 0xcb, 0x5a, 0x67, 0xc9, 0x9b, 0xfe, 0x8c, 0xcb, 0x2b, 0x00, 0x48, 0x3e,
-	0x35, 0x37, 0x59, 0x6b, 0x6f, 0xad, 0x97, 0xd6, 0x69, 0x1d, 0x5d, 0xcd, 0x6c, 0xe5, 0x7a, 0x66,
-	0x2b, 0x37, 0x33, 0x5b, 0xb9, 0x9b, 0xd9, 0xe0, 0xbf, 0xb9, 0x0d, 0x9e, 0xcc, 0x6d, 0x70, 0x35,
-	0xb7, 0xc1, 0xf5, 0xdc, 0x06, 0xaf, 0xe7, 0x36, 0x78, 0x33, 0xb7, 0x95, 0xbb, 0xb9, 0x0d, 0xfe,
-	0xbf, 0xb5, 0x95, 0xeb, 0x5b, 0x5b, 0xb9, 0xb9, 0xb5, 0x95, 0x3f, 0x8b, 0x2c, 0x01, 0x31, 0xee,
-	0x76, 0x75, 0x41, 0xea, 0xdb, 0xb7, 0x01, 0x00, 0x00, 0xff, 0xff, 0x50, 0x01, 0x08, 0x1e, 0x07,
-	0x07, 0x00, 0x00,
+	// 912 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x95, 0xcf, 0x6f, 0x1b, 0x45,
+	0x14, 0xc7, 0x77, 0xbc, 0xb3, 0xeb, 0xf1, 0xb8, 0x09, 0x9b, 0x69, 0x28, 0x1b, 0x17, 0x6d, 0x2c,
+	0x4b, 0x48, 0x16, 0x02, 0xa7, 0x0a, 0x12, 0x88, 0x4a, 0x1c, 0xea, 0xaa, 0xc8, 0x5d, 0x01, 0x87,
+	0x0d, 0xe2, 0x80, 0x84, 0xac, 0x5d, 0x67, 0x62, 0xaf, 0xe2, 0xfd, 0xc1, 0xce, 0x18, 0x9c, 0x9e,
+	0xf8, 0x13, 0xb8, 0x71, 0xe7, 0x84, 0xe0, 0x2f, 0xe0, 0xca, 0x29, 0x37, 0x72, 0xec, 0x09, 0x11,
+	0xe7, 0xc2, 0xb1, 0x7f, 0x02, 0x9a, 0x1f, 0xf6, 0x7a, 0x1b, 0x47, 0x69, 0xa5, 0x9e, 0xbc, 0xf3,
+	0xbe, 0x6f, 0xde, 0xbc, 0xf7, 0x99, 0x37, 0xcf, 0xb8, 0x51, 0xe4, 0xa3, 0x5e, 0x5e, 0x64, 0x3c,
+	0x23, 0x36, 0x9f, 0x84, 0x69, 0xc6, 0x5a, 0x4d, 0x7e, 0x96, 0x53, 0xa6, 0x8c, 0xad, 0x0f, 0xc7,
+	0x31, 0x9f, 0xcc, 0xa2, 0xde, 0x28, 0x4b, 0x0e, 0xc6, 0xd9, 0x38, 0x3b, 0x90, 0xe6, 0x68, 0x76,
+	0x22, 0x57, 0x72, 0x21, 0xbf, 0xb4, 0xfb, 0xde, 0x38, 0xcb, 0xc6, 0x53, 0x5a, 0x7a, 0x85, 0xe9,
+	0x99, 0x96, 0x1e, 0xac, 0x47, 0x2a, 0xc2, 0x93, 0x30, 0x0d, 0x0f, 0x92, 0x38, 0x89, 0x8b, 0x83,
+	0xfc, 0x74, 0xac, 0xbe, 0xf2, 0x48, 0xfd, 0xaa, 0x1d, 0x9d, 0x3f, 0x6b, 0x78, 0xeb, 0x88, 0x16,
+	0x31, 0x65, 0x01, 0xfd, 0x7e, 0x46, 0x19, 0x27, 0x7b, 0x18, 0x25, 0x71, 0x3a, 0xe4, 0x71, 0x42,
+	0x5d, 0xd0, 0x06, 0x5d, 0x33, 0xa8, 0x27, 0x71, 0xfa, 0x75, 0x9c, 0x50, 0x29, 0x85, 0x73, 0x25,
+	0xd5, 0xb4, 0x14, 0xce, 0xa5, 0xf4, 0xb1, 0x90, 0xf8, 0x68, 0x42, 0x0b, 0xe6, 0x9a, 0x6d, 0xb3,
+	0xdb, 0x3c, 0xdc, 0xed, 0xa9, 0x5a, 0x7b, 0x5f, 0x84, 0x11, 0x9d, 0x7e, 0xa9, 0xc4, 0x3e, 0x3c,
+	0xff, 0x67, 0xdf, 0x08, 0x56, 0xbe, 0x64, 0x1f, 0x37, 0xd9, 0x69, 0x9c, 0x0f, 0x47, 0x93, 0x59,
+	0x7a, 0xca, 0x5c, 0xd4, 0x06, 0x5d, 0x14, 0x60, 0x61, 0x7a, 0x2c, 0x2d, 0xe4, 0x7d, 0x6c, 0x4d,
+	0xe2, 0x94, 0x33, 0xb7, 0xd1, 0x06, 0x32, 0xaa, 0xaa, 0xbe, 0xb7, 0xac, 0xbe, 0xf7, 0x28, 0x3d,
+	0x0b, 0x94, 0x0b, 0xf9, 0x0c, 0xdf, 0x67, 0xbc, 0xa0, 0x61, 0x12, 0xa7, 0x63, 0x1d, 0x71, 0x18,
+	0x89, 0x93, 0x86, 0x2c, 0x7e, 0x46, 0xdd, 0xe3, 0x36, 0xe8, 0xc2, 0xc0, 0x5d, 0xb9, 0xa8, 0x13,
+	0xfa, 0xc2, 0xe1, 0x28, 0x7e, 0x46, 0x7d, 0x88, 0xa0, 0x63, 0xf9, 0x10, 0x59, 0x8e, 0xed, 0x43,
+	0x64, 0x3b, 0x75, 0x1f, 0xa2, 0xba, 0x83, 0x7c, 0x88, 0xb0, 0xd3, 0xf4, 0x21, 0x6a, 0x3a, 0x77,
+	0x7c, 0x88, 0xee, 0x38, 0x5b, 0x3e, 0x44, 0x5b, 0xce, 0x76, 0xe7, 0x13, 0x6c, 0x1d, 0xf1, 0x90,
+	0x33, 0xd2, 0xc3, 0x77, 0x4f, 0xa8, 0x28, 0xe8, 0x78, 0x18, 0xa7, 0xc7, 0x74, 0x3e, 0x8c, 0xce,
+	0x38, 0x65, 0x92, 0x1e, 0x0c, 0x76, 0xb4, 0xf4, 0x54, 0x28, 0x7d, 0x21, 0x74, 0x7e, 0x37, 0xf1,
+	0xf6, 0x12, 0x3a, 0xcb, 0xb3, 0x94, 0x51, 0xd2, 0xc5, 0x36, 0x93, 0x16, 0xb9, 0xab, 0x79, 0xb8,
+	0xbd, 0xa4, 0xa7, 0xfc, 0x06, 0x46, 0xa0, 0x75, 0xd2, 0xc2, 0xf5, 0x1f, 0xc3, 0x22, 0x8d, 0xd3,
+	0xb1, 0xbc, 0x83, 0xc6, 0xc0, 0x08, 0x96, 0x06, 0xf2, 0xc1, 0x12, 0x96, 0x79, 0x33, 0xac, 0x81,
+	0xb1, 0xc4, 0xf5, 0x1e, 0xb6, 0x98, 0xc8, 0xdf, 0x85, 0xd2, 0x7b, 0x6b, 0x75, 0xa4, 0x30, 0x0a,
+	0x37, 0xa9, 0x92, 0xa7, 0xd8, 0x29, 0xa9, 0xea, 0x24, 0x2d, 0xb9, 0xe3, 0xdd, 0x72, 0x87, 0xd6,
+	0x55, 0xb6, 0x12, 0xe9, 0xc0, 0x08, 0xde, 0x62, 0x55, 0x7b, 0x35, 0x94, 0xbe, 0x72, 0xfb, 0x86,
+	0x50, 0x6b, 0xb7, 0x53, 0x09, 0xa5, 0xfb, 0xe2, 0x3b, 0xbc, 0x77, 0xed, 0xae, 0x29, 0xe3, 0x71,
+	0x12, 0x72, 0xea, 0xd6, 0x65, 0xcc, 0xfd, 0x1b, 0x62, 0x3e, 0xd1, 0x6e, 0x03, 0x23, 0x78, 0x87,
+	0x6d, 0x96, 0xfa, 0x08, 0xdb, 0x05, 0x65, 0xb3, 0x29, 0xef, 0xfc, 0x01, 0xf0, 0x8e, 0x6c, 0xe1,
+	0xaf, 0xc2, 0xa4, 0x7c, 0x25, 0xbb, 0x92, 0x5d, 0xc1, 0x25, 0x69, 0x33, 0x50, 0x0b, 0xe2, 0x60,
+	0x93, 0xa6, 0xc7, 0x92, 0xa7, 0x19, 0x88, 0xcf, 0xb2, 0x7d, 0xad, 0xdb, 0xdb, 0x77, 0xfd, 0x0d,
+	0xd9, 0xaf, 0xfe, 0x86, 0x7c, 0x88, 0x80, 0x53, 0xf3, 0x21, 0xaa, 0x39, 0x66, 0xa7, 0xc0, 0x64,
+	0x3d, 0x59, 0xdd, 0x5d, 0xbb, 0xd8, 0x4a, 0x85, 0xc1, 0x05, 0x6d, 0xb3, 0xdb, 0x08, 0xd4, 0x82,
+	0xb4, 0x30, 0xd2, 0x8d, 0xc3, 0xdc, 0x9a, 0x14, 0x56, 0xeb, 0x32, 0x6f, 0xf3, 0xd6, 0xbc, 0x3b,
+	0x7f, 0x01, 0x7d, 0xe8, 0x37, 0xe1, 0x74, 0x56, 0x41, 0x34, 0x15, 0x56, 0xd9, 0xd1, 0x8d, 0x40,
+	0x2d, 0x4a, 0x70, 0x70, 0x03, 0x38, 0x6b, 0x03, 0x38, 0xfb, 0xf5, 0xc0, 0xd5, 0x5f, 0x0b, 0x5c,
+	0xcd, 0x31, 0x7d, 0x88, 0x4c, 0x07, 0x76, 0x66, 0xf8, 0x6e, 0xa5, 0x06, 0x4d, 0xee, 0x1e, 0xb6,
+	0x7f, 0x90, 0x16, 0x8d, 0x4e, 0xaf, 0xde, 0x18, 0xbb, 0x5f, 0x01, 0x76, 0x9e, 0xcc, 0x69, 0x92,
+	0x4f, 0xc3, 0xe2, 0x7a, 0x73, 0x81, 0x0d, 0x8c, 0x6a, 0x25, 0xa3, 0x87, 0xd7, 0x86, 0xae, 0xbb,
+	0xac, 0x7b, 0x19, 0x53, 0x97, 0xce, 0xae, 0x0d, 0xde, 0x55, 0x92, 0xf0, 0xf6, 0x24, 0x7d, 0xec,
+	0xbc, 0x1c, 0xaf, 0xc2, 0x1c, 0xbc, 0x3a, 0xf3, 0xce, 0x2f, 0x00, 0xef, 0xac, 0x15, 0xac, 0x31,
+	0x3f, 0xc4, 0x58, 0xfc, 0xab, 0xac, 0x46, 0xa0, 0x8a, 0x37, 0xca, 0x0a, 0x4e, 0xe7, 0x79, 0xd4,
+	0x13, 0x7f, 0x31, 0x7a, 0xb4, 0xa8, 0x78, 0x6b, 0xde, 0x6f, 0xea, 0x2a, 0x0e, 0xff, 0x06, 0x62,
+	0x9e, 0x67, 0x05, 0x25, 0x9f, 0x62, 0x5b, 0x0f, 0xac, 0xb7, 0xab, 0x63, 0x58, 0x5f, 0x50, 0xeb,
+	0xde, 0xcb, 0x66, 0x55, 0xc6, 0x03, 0x40, 0x1e, 0x63, 0x5c, 0xbe, 0x3f, 0xb2, 0x57, 0x41, 0xb2,
+	0x3e, 0x40, 0x5a, 0xad, 0x4d, 0x92, 0xa6, 0xf1, 0x39, 0x6e, 0xae, 0xf5, 0x22, 0xa9, 0xba, 0x56,
+	0x1e, 0x59, 0xeb, 0xfe, 0x46, 0x4d, 0xc5, 0xe9, 0x3f, 0x3a, 0xbf, 0xf4, 0x8c, 0x8b, 0x4b, 0xcf,
+	0x78, 0x7e, 0xe9, 0x19, 0x2f, 0x2e, 0x3d, 0xf0, 0xd3, 0xc2, 0x03, 0xbf, 0x2d, 0x3c, 0x70, 0xbe,
+	0xf0, 0xc0, 0xc5, 0xc2, 0x03, 0xff, 0x2e, 0x3c, 0xf0, 0xdf, 0xc2, 0x33, 0x5e, 0x2c, 0x3c, 0xf0,
+	0xf3, 0x95, 0x67, 0x5c, 0x5c, 0x79, 0xc6, 0xf3, 0x2b, 0xcf, 0xf8, 0xb6, 0xce, 0x04, 0x88, 0x3c,
+	0x8a, 0x6c, 0x49, 0xea, 0xa3, 0xff, 0x07, 0x00, 0x1f, 0xcd, 0x34, 0x25, 0xc4, 0x08, 0x00, 0x00,
 }
 
 func (this *SeriesRequest) Equal(that interface{}) bool {
@@ -932,6 +1064,113 @@ func (this *LabelValuesResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *ExemplarsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ExemplarsRequest)
+	if !ok {
+		that2, ok := that.(ExemplarsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Start != that1.Start {
+		return false
+	}
+	if this.End != that1.End {
+		return false
+	}
+	if len(this.Matchers) != len(that1.Matchers) {
+		return false
+	}
+	for i := range this.Matchers {
+		if !this.Matchers[i].Equal(&that1.Matchers[i]) {
+			return false
+		}
+	}
+	if !this.Hints.Equal(that1.Hints) {
+		return false
+	}
+	return true
+}
+func (this *ExemplarMatchers) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ExemplarMatchers)
+	if !ok {
+		that2, ok := that.(ExemplarMatchers)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Matchers) != len(that1.Matchers) {
+		return false
+	}
+	for i := range this.Matchers {
+		if !this.Matchers[i].Equal(&that1.Matchers[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ExemplarsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ExemplarsResponse)
+	if !ok {
+		that2, ok := that.(ExemplarsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Timeseries) != len(that1.Timeseries) {
+		return false
+	}
+	for i := range this.Timeseries {
+		if !this.Timeseries[i].Equal(&that1.Timeseries[i]) {
+			return false
+		}
+	}
+	if len(this.Warnings) != len(that1.Warnings) {
+		return false
+	}
+	for i := range this.Warnings {
+		if this.Warnings[i] != that1.Warnings[i] {
+			return false
+		}
+	}
+	if !this.Hints.Equal(that1.Hints) {
+		return false
+	}
+	return true
+}
 func (this *SeriesRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -1104,6 +1343,63 @@ func (this *LabelValuesResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *ExemplarsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&storepb.ExemplarsRequest{")
+	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
+	s = append(s, "End: "+fmt.Sprintf("%#v", this.End)+",\n")
+	if this.Matchers != nil {
+		vs := make([]*ExemplarMatchers, len(this.Matchers))
+		for i := range vs {
+			vs[i] = &this.Matchers[i]
+		}
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.Hints != nil {
+		s = append(s, "Hints: "+fmt.Sprintf("%#v", this.Hints)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ExemplarMatchers) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&storepb.ExemplarMatchers{")
+	if this.Matchers != nil {
+		vs := make([]*LabelMatcher, len(this.Matchers))
+		for i := range vs {
+			vs[i] = &this.Matchers[i]
+		}
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ExemplarsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&storepb.ExemplarsResponse{")
+	if this.Timeseries != nil {
+		vs := make([]*mimirpb.TimeSeries, len(this.Timeseries))
+		for i := range vs {
+			vs[i] = &this.Timeseries[i]
+		}
+		s = append(s, "Timeseries: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
+	if this.Hints != nil {
+		s = append(s, "Hints: "+fmt.Sprintf("%#v", this.Hints)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringRpc(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1815,33 +2111,187 @@ func (m *LabelValuesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintRpc(dAtA []byte, offset int, v uint64) int {
-	offset -= sovRpc(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *ExemplarsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *SeriesRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *ExemplarsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExemplarsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.MinTime != 0 {
-		n += 1 + sovRpc(uint64(m.MinTime))
-	}
-	if m.MaxTime != 0 {
-		n += 1 + sovRpc(uint64(m.MaxTime))
+	if m.Hints != nil {
+		{
+			size, err := m.Hints.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRpc(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
 	}
 	if len(m.Matchers) > 0 {
-		for _, e := range m.Matchers {
-			l = e.Size()
-			n += 1 + l + sovRpc(uint64(l))
+		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Matchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRpc(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.End != 0 {
+		i = encodeVarintRpc(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Start != 0 {
+		i = encodeVarintRpc(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExemplarMatchers) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExemplarMatchers) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExemplarMatchers) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Matchers) > 0 {
+		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Matchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRpc(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExemplarsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExemplarsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExemplarsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Hints != nil {
+		{
+			size, err := m.Hints.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRpc(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintRpc(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Timeseries) > 0 {
+		for iNdEx := len(m.Timeseries) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Timeseries[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRpc(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintRpc(dAtA []byte, offset int, v uint64) int {
+	offset -= sovRpc(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *SeriesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MinTime != 0 {
+		n += 1 + sovRpc(uint64(m.MinTime))
+	}
+	if m.MaxTime != 0 {
+		n += 1 + sovRpc(uint64(m.MaxTime))
+	}
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
+			l = e.Size()
+			n += 1 + l + sovRpc(uint64(l))
 		}
 	}
 	if m.SkipChunks {
@@ -2067,6 +2517,71 @@ func (m *LabelValuesResponse) Size() (n int) {
 	return n
 }
 
+func (m *ExemplarsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Start != 0 {
+		n += 1 + sovRpc(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovRpc(uint64(m.End))
+	}
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
+			l = e.Size()
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	if m.Hints != nil {
+		l = m.Hints.Size()
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	return n
+}
+
+func (m *ExemplarMatchers) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
+			l = e.Size()
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ExemplarsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Timeseries) > 0 {
+		for _, e := range m.Timeseries {
+			l = e.Size()
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovRpc(uint64(l))
+		}
+	}
+	if m.Hints != nil {
+		l = m.Hints.Size()
+		n += 1 + l + sovRpc(uint64(l))
+	}
+	return n
+}
+
 func sovRpc(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -2244,6 +2759,56 @@ func (this *LabelValuesResponse) String() string {
 	}, "")
 	return s
 }
+func (this *ExemplarsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMatchers := "[]ExemplarMatchers{"
+	for _, f := range this.Matchers {
+		repeatedStringForMatchers += strings.Replace(strings.Replace(f.String(), "ExemplarMatchers", "ExemplarMatchers", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForMatchers += "}"
+	s := strings.Join([]string{`&ExemplarsRequest{`,
+		`Start:` + fmt.Sprintf("%v", this.Start) + `,`,
+		`End:` + fmt.Sprintf("%v", this.End) + `,`,
+		`Matchers:` + repeatedStringForMatchers + `,`,
+		`Hints:` + strings.Replace(fmt.Sprintf("%v", this.Hints), "Any", "types.Any", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ExemplarMatchers) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMatchers := "[]LabelMatcher{"
+	for _, f := range this.Matchers {
+		repeatedStringForMatchers += fmt.Sprintf("%v", f) + ","
+	}
+	repeatedStringForMatchers += "}"
+	s := strings.Join([]string{`&ExemplarMatchers{`,
+		`Matchers:` + repeatedStringForMatchers + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ExemplarsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForTimeseries := "[]TimeSeries{"
+	for _, f := range this.Timeseries {
+		repeatedStringForTimeseries += fmt.Sprintf("%v", f) + ","
+	}
+	repeatedStringForTimeseries += "}"
+	s := strings.Join([]string{`&ExemplarsResponse{`,
+		`Timeseries:` + repeatedStringForTimeseries + `,`,
+		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
+		`Hints:` + strings.Replace(fmt.Sprintf("%v", this.Hints), "Any", "types.Any", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringRpc(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -3479,6 +4044,409 @@ func (m *LabelValuesResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ExemplarsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExemplarsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExemplarsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = append(m.Matchers, ExemplarMatchers{})
+			if err := m.Matchers[len(m.Matchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Hints == nil {
+				m.Hints = &types.Any{}
+			}
+			if err := m.Hints.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExemplarMatchers) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExemplarMatchers: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExemplarMatchers: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = append(m.Matchers, LabelMatcher{})
+			if err := m.Matchers[len(m.Matchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExemplarsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExemplarsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExemplarsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeseries", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Timeseries = append(m.Timeseries, mimirpb.TimeSeries{})
+			if err := m.Timeseries[len(m.Timeseries)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Hints == nil {
+				m.Hints = &types.Any{}
+			}
+			if err := m.Hints.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRpc(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// aggregation evaluates the sum, count, min and max aggregations. The output series of a group is returned
+// as soon as all the series of the group have been read from the inner operator, so that only the groups
+// with some, but not all, of their series already read are held in memory.
+type aggregation struct {
+	inner     InstantVectorOperator
+	op        parser.ItemType
+	grouping  []string
+	without   bool
+	timeRange timeRange
+	tracker   *MemoryConsumptionTracker
+
+	// groupsByInnerSeries is the group of each of the inner series not read yet, in the order they're read.
+	groupsByInnerSeries []*group
+	// remainingGroups are the groups not returned yet, in the order they're returned.
+	remainingGroups []*group
+}
+
+type group struct {
+	labels labels.Labels
+
+	// lastInnerSeriesIndex is the index of the last inner series of the group, which determines when
+	// the group can be returned.
+	lastInnerSeriesIndex int
+	remainingInnerSeries int
+
+	// values and present hold the aggregated value at each step. They're allocated when the first series
+	// of the group is read.
+	values  []float64
+	present []bool
+}
+
+var _ InstantVectorOperator = &aggregation{}
+
+func (a *aggregation) SeriesMetadata(ctx context.Context) ([]labels.Labels, error) {
+	innerMetadata, err := a.inner.SeriesMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*group{}
+	a.groupsByInnerSeries = make([]*group, 0, len(innerMetadata))
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	buf := make([]byte, 0, 1024)
+
+	for i, l := range innerMetadata {
+		lb.Reset(l)
+		if a.without {
+			lb.Del(a.grouping...)
+			lb.Del(labels.MetricName)
+		} else {
+			lb.Keep(a.grouping...)
+		}
+		groupLabels := lb.Labels()
+
+		buf = groupLabels.Bytes(buf)
+		g, ok := groups[string(buf)]
+		if !ok {
+			if err := a.tracker.IncreaseMemoryConsumption(labelsSize(groupLabels)); err != nil {
+				return nil, err
+			}
+
+			g = &group{labels: groupLabels}
+			groups[string(buf)] = g
+			a.remainingGroups = append(a.remainingGroups, g)
+		}
+
+		g.lastInnerSeriesIndex = i
+		g.remainingInnerSeries++
+		a.groupsByInnerSeries = append(a.groupsByInnerSeries, g)
+	}
+
+	slices.SortFunc(a.remainingGroups, func(a, b *group) int {
+		return a.lastInnerSeriesIndex - b.lastInnerSeriesIndex
+	})
+
+	metadata := make([]labels.Labels, 0, len(a.remainingGroups))
+	for _, g := range a.remainingGroups {
+		metadata = append(metadata, g.labels)
+	}
+
+	return metadata, nil
+}
+
+func (a *aggregation) NextSeries(ctx context.Context) ([]promql.FPoint, error) {
+	if len(a.remainingGroups) == 0 {
+		return nil, errEOS
+	}
+
+	// Read the inner series until all the series of the next group have been read.
+	next := a.remainingGroups[0]
+	for next.remainingInnerSeries > 0 {
+		points, err := a.inner.NextSeries(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		g := a.groupsByInnerSeries[0]
+		a.groupsByInnerSeries = a.groupsByInnerSeries[1:]
+		err = a.accumulate(g, points)
+		putFPointSlice(points, a.tracker)
+		if err != nil {
+			return nil, err
+		}
+
+		g.remainingInnerSeries--
+	}
+
+	a.remainingGroups = a.remainingGroups[1:]
+	defer a.releaseGroup(next)
+
+	count := 0
+	for _, p := range next.present {
+		if p {
+			count++
+		}
+	}
+
+	points, err := getFPointSlice(count, a.tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range next.present {
+		if p {
+			points = append(points, promql.FPoint{T: a.timeRange.start + int64(i)*a.timeRange.interval, F: next.values[i]})
+		}
+	}
+
+	return points, nil
+}
+
+func (a *aggregation) accumulate(g *group, points []promql.FPoint) error {
+	if g.values == nil {
+		steps := uint64(a.timeRange.steps)
+		if err := a.tracker.IncreaseMemoryConsumption(steps * (float64Size + boolSize)); err != nil {
+			return err
+		}
+
+		g.values = make([]float64, steps)
+		g.present = make([]bool, steps)
+	}
+
+	for _, p := range points {
+		i := a.timeRange.stepIndex(p.T)
+
+		if !g.present[i] {
+			g.present[i] = true
+			if a.op == parser.COUNT {
+				g.values[i] = 1
+			} else {
+				g.values[i] = p.F
+			}
+			continue
+		}
+
+		switch a.op {
+		case parser.SUM:
+			g.values[i] += p.F
+		case parser.COUNT:
+			g.values[i]++
+		case parser.MIN:
+			if g.values[i] > p.F || math.IsNaN(g.values[i]) {
+				g.values[i] = p.F
+			}
+		case parser.MAX:
+			if g.values[i] < p.F || math.IsNaN(g.values[i]) {
+				g.values[i] = p.F
+			}
+		}
+	}
+
+	return nil
+}
+
+// releaseGroup releases the memory of a group which has been returned.
+func (a *aggregation) releaseGroup(g *group) {
+	a.tracker.DecreaseMemoryConsumption(labelsSize(g.labels))
+	if g.values != nil {
+		a.tracker.DecreaseMemoryConsumption(uint64(a.timeRange.steps) * (float64Size + boolSize))
+		g.values, g.present = nil, nil
+	}
+}
+
+func (a *aggregation) Close() {
+	for _, g := range a.remainingGroups {
+		a.releaseGroup(g)
+	}
+	a.remainingGroups = nil
+
+	a.inner.Close()
+}
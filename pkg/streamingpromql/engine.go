@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/mimir/pkg/querier/engine"
+)
+
+const defaultLookbackDelta = 5 * time.Minute // This should be the same value as github.com/prometheus/prometheus/promql.defaultLookbackDelta.
+
+// Limits contains the per-tenant limits enforced by the streaming engine.
+type Limits interface {
+	MaxEstimatedMemoryPerQuery(userID string) uint64
+}
+
+// Engine is a PromQL engine which evaluates the queries series by series, rather than loading all the selected
+// series in memory, and which tracks the estimated memory used by each query to enforce a per-tenant limit on it.
+// It supports a subset of PromQL: the queries using unsupported features fail with a NotSupportedError, and can be
+// evaluated by the Prometheus engine instead using NewEngineWithFallback.
+type Engine struct {
+	timeout             time.Duration
+	maxSamples          int
+	lookbackDelta       time.Duration
+	activeQueryTracker  promql.QueryTracker
+	limits              Limits
+	logger              log.Logger
+	estimatedPeakMemory prometheus.Histogram
+}
+
+var _ engine.QueryEngine = &Engine{}
+
+// NewEngine returns the streaming engine. The @ modifier and negative offsets are always enabled, as they're
+// in the Prometheus engine used by Mimir. The max samples limit is enforced on the points the query has loaded
+// in memory at once, counted by the capacity of the points slices, so it's slightly more conservative than
+// in the Prometheus engine.
+func NewEngine(opts promql.EngineOpts, limits Limits, logger log.Logger) *Engine {
+	lookbackDelta := opts.LookbackDelta
+	if lookbackDelta == 0 {
+		lookbackDelta = defaultLookbackDelta
+	}
+
+	return &Engine{
+		timeout:            opts.Timeout,
+		maxSamples:         opts.MaxSamples,
+		lookbackDelta:      lookbackDelta,
+		activeQueryTracker: opts.ActiveQueryTracker,
+		limits:             limits,
+		logger:             logger,
+		estimatedPeakMemory: promauto.With(opts.Reg).NewHistogram(prometheus.HistogramOpts{
+			Name:                        "cortex_mimir_query_engine_estimated_query_peak_memory_consumption",
+			Help:                        "Estimated peak memory consumption, in bytes, of each query evaluated by the streaming engine.",
+			NativeHistogramBucketFactor: 1.1,
+		}),
+	}
+}
+
+// SetQueryLogger is a no-op, as the streaming engine doesn't support logging the queries.
+func (e *Engine) SetQueryLogger(promql.QueryLogger) {}
+
+func (e *Engine) NewInstantQuery(_ context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, ts time.Time) (promql.Query, error) {
+	return newQuery(e, q, opts, qs, ts, ts, 0)
+}
+
+func (e *Engine) NewRangeQuery(_ context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, start, end time.Time, interval time.Duration) (promql.Query, error) {
+	return newQuery(e, q, opts, qs, start, end, interval)
+}
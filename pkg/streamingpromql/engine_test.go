@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const testData = `
+load 1m
+	some_metric{env="prod", cluster="eu"} 0+1x10
+	some_metric{env="prod", cluster="us"} 0+2x10
+	some_metric{env="test", cluster="eu"} 0+3x5 _x4 5
+	some_metric{env="test", cluster="us"} 0+4x3 stale
+	other_metric{env="prod"} 10 5 0 20 15 10 _ _ _ 30 40
+`
+
+type staticLimits uint64
+
+func (l staticLimits) MaxEstimatedMemoryPerQuery(string) uint64 {
+	return uint64(l)
+}
+
+func newTestEngine(limits Limits) *Engine {
+	return NewEngine(promql.EngineOpts{Timeout: time.Minute, MaxSamples: 1e6}, limits, log.NewNopLogger())
+}
+
+func TestEngine_ComparedToPrometheus(t *testing.T) {
+	storage := promql.LoadedStorage(t, testData)
+	t.Cleanup(func() { require.NoError(t, storage.Close()) })
+
+	queries := []string{
+		`some_metric`,
+		`some_metric{env="prod"}`,
+		`some_metric offset 2m`,
+		`other_metric`,
+		`rate(some_metric[3m])`,
+		`increase(other_metric[5m])`,
+		`rate(some_metric[3m] offset 1m)`,
+		`sum(some_metric)`,
+		`sum by (env) (some_metric)`,
+		`count without (cluster) (some_metric)`,
+		`min by (cluster) (rate(some_metric[5m]))`,
+		`max(some_metric{env="test"})`,
+		`(sum by (env) ((some_metric)))`,
+		`sum(nonexistent_metric)`,
+	}
+
+	prometheusEngine := promql.NewEngine(promql.EngineOpts{Timeout: time.Minute, MaxSamples: 1e6})
+	streamingEngine := newTestEngine(staticLimits(0))
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	for _, qs := range queries {
+		t.Run(qs, func(t *testing.T) {
+			t.Run("instant query", func(t *testing.T) {
+				for _, ts := range []time.Time{time.Unix(0, 0), time.Unix(150, 0), time.Unix(480, 0), time.Unix(1200, 0)} {
+					expected, err := prometheusEngine.NewInstantQuery(ctx, storage, nil, qs, ts)
+					require.NoError(t, err)
+					defer expected.Close()
+
+					actual, err := streamingEngine.NewInstantQuery(ctx, storage, nil, qs, ts)
+					require.NoError(t, err)
+					defer actual.Close()
+
+					requireEqualResults(t, expected.Exec(ctx), actual.Exec(ctx))
+				}
+			})
+
+			t.Run("range query", func(t *testing.T) {
+				expected, err := prometheusEngine.NewRangeQuery(ctx, storage, nil, qs, time.Unix(0, 0), time.Unix(900, 0), 30*time.Second)
+				require.NoError(t, err)
+				defer expected.Close()
+
+				actual, err := streamingEngine.NewRangeQuery(ctx, storage, nil, qs, time.Unix(0, 0), time.Unix(900, 0), 30*time.Second)
+				require.NoError(t, err)
+				defer actual.Close()
+
+				requireEqualResults(t, expected.Exec(ctx), actual.Exec(ctx))
+			})
+		})
+	}
+}
+
+func requireEqualResults(t *testing.T, expected, actual *promql.Result) {
+	require.NoError(t, expected.Err)
+	require.NoError(t, actual.Err)
+
+	switch expectedValue := expected.Value.(type) {
+	case promql.Vector:
+		actualValue, ok := actual.Value.(promql.Vector)
+		require.True(t, ok, "expected a vector, got %T", actual.Value)
+		require.ElementsMatch(t, expectedValue, actualValue)
+	case promql.Matrix:
+		actualValue, ok := actual.Value.(promql.Matrix)
+		require.True(t, ok, "expected a matrix, got %T", actual.Value)
+		require.Len(t, actualValue, len(expectedValue))
+		for i := range expectedValue {
+			require.Equal(t, expectedValue[i].Metric, actualValue[i].Metric)
+			require.Len(t, actualValue[i].Floats, len(expectedValue[i].Floats), expectedValue[i].Metric.String())
+			for j := range expectedValue[i].Floats {
+				require.Equal(t, expectedValue[i].Floats[j].T, actualValue[i].Floats[j].T)
+				require.InEpsilon(t, expectedValue[i].Floats[j].F+1, actualValue[i].Floats[j].F+1, 1e-9)
+			}
+		}
+	default:
+		require.Failf(t, "unexpected result type", "%T", expected.Value)
+	}
+}
+
+func TestEngine_NotSupportedQueries(t *testing.T) {
+	streamingEngine := newTestEngine(staticLimits(0))
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	for qs, expectedReason := range map[string]string{
+		`avg(some_metric)`:                   "'avg' aggregation",
+		`abs(some_metric)`:                   "'abs' function",
+		`rate(some_metric[5m:1m])`:           "'rate' function over a subquery",
+		`some_metric @ 10`:                   "'@' modifier",
+		`some_metric + 1`:                    "PromQL expression type *parser.BinaryExpr",
+		`some_metric[5m]`:                    "range vector result type",
+		`scalar(some_metric)`:                "scalar result type",
+		`sum by (env) (some_metric @ end())`: "'@' modifier",
+	} {
+		t.Run(qs, func(t *testing.T) {
+			_, err := streamingEngine.NewInstantQuery(ctx, storage.Queryable(nil), nil, qs, time.Unix(0, 0))
+			reason, ok := isNotSupportedError(err)
+			require.True(t, ok, "expected a NotSupportedError, got %v", err)
+			require.Equal(t, expectedReason, reason)
+		})
+	}
+}
+
+func TestEngine_MaxEstimatedMemoryPerQuery(t *testing.T) {
+	storage := promql.LoadedStorage(t, testData)
+	t.Cleanup(func() { require.NoError(t, storage.Close()) })
+
+	const qs = `sum by (env) (rate(some_metric[5m]))`
+	start, end, step := time.Unix(0, 0), time.Unix(600, 0), 10*time.Second
+
+	// Find out the peak memory of the query, to set the limits around it.
+	unlimited := newTestEngine(staticLimits(0))
+	ctx := user.InjectOrgID(context.Background(), "test")
+	q, err := unlimited.NewRangeQuery(ctx, storage, nil, qs, start, end, step)
+	require.NoError(t, err)
+	require.NoError(t, q.Exec(ctx).Err)
+	peak := q.(*query).tracker.PeakEstimatedMemoryConsumptionBytes
+	require.NotZero(t, peak)
+	q.Close()
+	require.Zero(t, q.(*query).tracker.CurrentEstimatedMemoryConsumptionBytes)
+
+	tests := map[string]struct {
+		limits        map[string]uint64
+		tenants       string
+		expectedError bool
+	}{
+		"limit above the peak memory": {
+			limits:  map[string]uint64{"test": peak},
+			tenants: "test",
+		},
+		"limit below the peak memory": {
+			limits:        map[string]uint64{"test": peak - 1},
+			tenants:       "test",
+			expectedError: true,
+		},
+		"the smallest limit of the tenants is enforced": {
+			limits:        map[string]uint64{"a": 0, "b": peak - 1, "c": peak},
+			tenants:       "a|b|c",
+			expectedError: true,
+		},
+		"unlimited tenants": {
+			limits:  map[string]uint64{"a": 0, "b": 0},
+			tenants: "a|b",
+		},
+	}
+
+	// Resolve the multiple tenants of federated queries.
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+	t.Cleanup(func() { tenant.WithDefaultResolver(tenant.NewSingleResolver()) })
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			eng := NewEngine(promql.EngineOpts{Timeout: time.Minute, Reg: reg}, tenantLimits(testData.limits), log.NewNopLogger())
+			ctx := user.InjectOrgID(context.Background(), testData.tenants)
+
+			q, err := eng.NewRangeQuery(ctx, storage, nil, qs, start, end, step)
+			require.NoError(t, err)
+			res := q.Exec(ctx)
+			q.Close()
+
+			require.Zero(t, q.(*query).tracker.CurrentEstimatedMemoryConsumptionBytes)
+			require.Equal(t, 1, testutil.CollectAndCount(reg, "cortex_mimir_query_engine_estimated_query_peak_memory_consumption"))
+
+			if !testData.expectedError {
+				require.NoError(t, res.Err)
+				return
+			}
+
+			require.Error(t, res.Err)
+			require.ErrorContains(t, res.Err, "err-mimir-max-estimated-memory-per-query")
+			var limitErr validation.LimitError
+			require.ErrorAs(t, res.Err, &limitErr)
+		})
+	}
+}
+
+func TestEngine_MaxSamples(t *testing.T) {
+	storage := promql.LoadedStorage(t, testData)
+	t.Cleanup(func() { require.NoError(t, storage.Close()) })
+
+	const qs = `sum by (env) (rate(some_metric[5m]))`
+	start, end, step := time.Unix(0, 0), time.Unix(600, 0), 10*time.Second
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	for name, testData := range map[string]struct {
+		maxSamples    int
+		expectedError bool
+	}{
+		"unlimited": {
+			maxSamples: 0,
+		},
+		"limit above the samples of the query": {
+			maxSamples: 1e6,
+		},
+		"limit below the samples of the query": {
+			maxSamples:    1,
+			expectedError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			eng := NewEngine(promql.EngineOpts{Timeout: time.Minute, MaxSamples: testData.maxSamples}, staticLimits(0), log.NewNopLogger())
+
+			q, err := eng.NewRangeQuery(ctx, storage, nil, qs, start, end, step)
+			require.NoError(t, err)
+			res := q.Exec(ctx)
+			q.Close()
+
+			require.Zero(t, q.(*query).tracker.CurrentSamples)
+
+			if !testData.expectedError {
+				require.NoError(t, res.Err)
+				return
+			}
+
+			var samplesErr promql.ErrTooManySamples
+			require.ErrorAs(t, res.Err, &samplesErr)
+		})
+	}
+}
+
+type tenantLimits map[string]uint64
+
+func (l tenantLimits) MaxEstimatedMemoryPerQuery(userID string) uint64 {
+	return l[userID]
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errEOS is returned by operators when they have no more series to return.
+var errEOS = errors.New("operator stream exhausted")
+
+// NotSupportedError is returned when the query uses a feature not supported by the streaming engine.
+type NotSupportedError struct {
+	reason string
+}
+
+func NewNotSupportedError(reason string) error {
+	return NotSupportedError{reason: reason}
+}
+
+func (e NotSupportedError) Error() string {
+	return fmt.Sprintf("not supported by streaming engine: %s", e.reason)
+}
+
+// isNotSupportedError returns the reason of the NotSupportedError wrapped by err, if any.
+func isNotSupportedError(err error) (string, bool) {
+	var notSupportedErr NotSupportedError
+	if errors.As(err, &notSupportedErr) {
+		return notSupportedErr.reason, true
+	}
+	return "", false
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/mimir/pkg/querier/engine"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+)
+
+// EngineWithFallback evaluates the queries with the preferred engine, and falls back to the fallback engine
+// for the queries the preferred engine doesn't support. The fallback happens when the query is created or,
+// for the features which are only detected while evaluating the query (e.g. native histograms), when it's executed.
+// In the latter case the query is evaluated twice: the preferred engine stops at the first unsupported series,
+// but the series it selected until then are queried again from the storage by the fallback engine.
+type EngineWithFallback struct {
+	preferred engine.QueryEngine
+	fallback  engine.QueryEngine
+
+	supportedQueries   prometheus.Counter
+	unsupportedQueries *prometheus.CounterVec
+
+	logger log.Logger
+}
+
+var _ engine.QueryEngine = &EngineWithFallback{}
+
+func NewEngineWithFallback(preferred, fallback engine.QueryEngine, reg prometheus.Registerer, logger log.Logger) *EngineWithFallback {
+	return &EngineWithFallback{
+		preferred: preferred,
+		fallback:  fallback,
+
+		supportedQueries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_mimir_query_engine_supported_queries_total",
+			Help: "Total number of queries evaluated by the streaming engine.",
+		}),
+		unsupportedQueries: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_mimir_query_engine_unsupported_queries_total",
+			Help: "Total number of queries not supported by the streaming engine, and evaluated by the fallback engine.",
+		}, []string{"reason"}),
+
+		logger: logger,
+	}
+}
+
+// SetQueryLogger sets the query logger of both the engines.
+func (e *EngineWithFallback) SetQueryLogger(l promql.QueryLogger) {
+	e.preferred.SetQueryLogger(l)
+	e.fallback.SetQueryLogger(l)
+}
+
+func (e *EngineWithFallback) NewInstantQuery(ctx context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, ts time.Time) (promql.Query, error) {
+	return e.newQuery(ctx, qs, func(eng engine.QueryEngine) (promql.Query, error) {
+		return eng.NewInstantQuery(ctx, q, opts, qs, ts)
+	})
+}
+
+func (e *EngineWithFallback) NewRangeQuery(ctx context.Context, q storage.Queryable, opts promql.QueryOpts, qs string, start, end time.Time, interval time.Duration) (promql.Query, error) {
+	return e.newQuery(ctx, qs, func(eng engine.QueryEngine) (promql.Query, error) {
+		return eng.NewRangeQuery(ctx, q, opts, qs, start, end, interval)
+	})
+}
+
+func (e *EngineWithFallback) newQuery(ctx context.Context, qs string, create func(engine.QueryEngine) (promql.Query, error)) (promql.Query, error) {
+	query, err := create(e.preferred)
+	if err == nil {
+		return &queryWithFallback{Query: query, engine: e, createFallback: func() (promql.Query, error) {
+			return create(e.fallback)
+		}}, nil
+	}
+
+	reason, ok := isNotSupportedError(err)
+	if !ok {
+		return nil, err
+	}
+
+	e.recordFallback(ctx, qs, reason)
+	return create(e.fallback)
+}
+
+func (e *EngineWithFallback) recordFallback(ctx context.Context, qs, reason string) {
+	logger := spanlogger.FromContext(ctx, e.logger)
+	level.Info(logger).Log("msg", "falling back to the fallback engine", "reason", reason, "expr", qs)
+	e.unsupportedQueries.WithLabelValues(reason).Inc()
+}
+
+// queryWithFallback is a query created by the preferred engine, which is evaluated again from scratch by the
+// fallback engine if it turns out to be not supported while executing it. The work done by the preferred engine
+// until then, including the storage selects, is discarded.
+type queryWithFallback struct {
+	promql.Query // The query being evaluated, by either engine.
+
+	engine         *EngineWithFallback
+	createFallback func() (promql.Query, error)
+}
+
+func (q *queryWithFallback) Exec(ctx context.Context) *promql.Result {
+	res := q.Query.Exec(ctx)
+
+	reason, ok := isNotSupportedError(res.Err)
+	if !ok {
+		q.engine.supportedQueries.Inc()
+		return res
+	}
+
+	q.engine.recordFallback(ctx, q.Query.String(), reason)
+
+	fallback, err := q.createFallback()
+	if err != nil {
+		return &promql.Result{Err: err}
+	}
+
+	q.Query.Close()
+	q.Query = fallback
+	return q.Query.Exec(ctx)
+}
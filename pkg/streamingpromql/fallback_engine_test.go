@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineWithFallback(t *testing.T) {
+	storage := promql.LoadedStorage(t, `
+		load 1m
+			float_metric{env="prod"} 0+1x10
+			float_metric{env="test"} 0+2x10
+			histogram_metric{env="prod"} {{schema:0 sum:5 count:4 buckets:[1 2 1]}}x10
+	`)
+	t.Cleanup(func() { require.NoError(t, storage.Close()) })
+
+	tests := map[string]struct {
+		query             string
+		expectedSupported int
+		expectedReason    string
+		expectedSelects   int
+	}{
+		"supported query": {
+			query:             `sum by (env) (rate(float_metric[5m]))`,
+			expectedSupported: 1,
+			expectedSelects:   1,
+		},
+		"query not supported when created": {
+			query:           `avg by (env) (rate(float_metric[5m]))`,
+			expectedReason:  "'avg' aggregation",
+			expectedSelects: 1,
+		},
+		"query not supported when executed": {
+			query:          `sum(histogram_metric)`,
+			expectedReason: "native histograms",
+			// The query is evaluated by both the engines.
+			expectedSelects: 2,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			opts := promql.EngineOpts{Timeout: time.Minute, MaxSamples: 1e6, EnableNegativeOffset: true, EnableAtModifier: true}
+			prometheusEngine := promql.NewEngine(opts)
+			eng := NewEngineWithFallback(NewEngine(opts, staticLimits(0), log.NewNopLogger()), prometheusEngine, reg, log.NewNopLogger())
+			ctx := user.InjectOrgID(context.Background(), "test")
+
+			expected, err := prometheusEngine.NewRangeQuery(ctx, storage, nil, testData.query, time.Unix(0, 0), time.Unix(600, 0), time.Minute)
+			require.NoError(t, err)
+			defer expected.Close()
+
+			queryable := &selectCountingQueryable{Queryable: storage}
+			actual, err := eng.NewRangeQuery(ctx, queryable, nil, testData.query, time.Unix(0, 0), time.Unix(600, 0), time.Minute)
+			require.NoError(t, err)
+			defer actual.Close()
+
+			expectedRes, actualRes := expected.Exec(ctx), actual.Exec(ctx)
+			require.NoError(t, actualRes.Err)
+			require.Equal(t, expectedRes.Value.String(), actualRes.Value.String())
+			require.Equal(t, testData.expectedSelects, queryable.selects)
+
+			expectedMetrics := `
+				# HELP cortex_mimir_query_engine_supported_queries_total Total number of queries evaluated by the streaming engine.
+				# TYPE cortex_mimir_query_engine_supported_queries_total counter
+				cortex_mimir_query_engine_supported_queries_total ` + strconv.Itoa(testData.expectedSupported) + `
+			`
+			if testData.expectedReason != "" {
+				expectedMetrics += `
+					# HELP cortex_mimir_query_engine_unsupported_queries_total Total number of queries not supported by the streaming engine, and evaluated by the fallback engine.
+					# TYPE cortex_mimir_query_engine_unsupported_queries_total counter
+					cortex_mimir_query_engine_unsupported_queries_total{reason="` + testData.expectedReason + `"} 1
+				`
+			}
+			require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expectedMetrics),
+				"cortex_mimir_query_engine_supported_queries_total", "cortex_mimir_query_engine_unsupported_queries_total"))
+		})
+	}
+}
+
+// selectCountingQueryable counts the selects done on the queriers it returns.
+type selectCountingQueryable struct {
+	storage.Queryable
+	selects int
+}
+
+func (q *selectCountingQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
+	querier, err := q.Queryable.Querier(mint, maxt)
+	if err != nil {
+		return nil, err
+	}
+	return &selectCountingQuerier{Querier: querier, queryable: q}, nil
+}
+
+type selectCountingQuerier struct {
+	storage.Querier
+	queryable *selectCountingQueryable
+}
+
+func (q *selectCountingQuerier) Select(ctx context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	q.queryable.selects++
+	return q.Querier.Select(ctx, sortSeries, hints, matchers...)
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"fmt"
+	"math/bits"
+	"unsafe"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/util/zeropool"
+
+	"github.com/grafana/mimir/pkg/util/globalerror"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	fPointSize  = uint64(unsafe.Sizeof(promql.FPoint{}))
+	float64Size = uint64(unsafe.Sizeof(float64(0)))
+	boolSize    = uint64(unsafe.Sizeof(false))
+
+	// estimatedLabelBytes is the estimated memory of a label, in addition to the length of its name and value.
+	estimatedLabelBytes = uint64(2 * unsafe.Sizeof(""))
+
+	// maxPointsSliceSizeBits is the log2 of the max capacity of the points slices kept in the pools.
+	maxPointsSliceSizeBits = 13
+)
+
+var (
+	MaxEstimatedMemoryPerQueryMsgFormat = globalerror.MaxEstimatedMemoryPerQuery.MessageWithStrategyAndPerTenantLimitConfig(
+		"the query exceeded the maximum estimated memory consumption (limit: %d bytes)",
+		"Consider reducing the time range and/or number of series selected by the query. One way to reduce the number of selected series is to add more label matchers to the query",
+		validation.MaxEstimatedMemoryPerQueryFlag,
+	)
+
+	// fPointPools holds the points slices by capacity: the slices in fPointPools[i] have a capacity of 2^i.
+	// Rounding up the capacity to a power of two keeps the estimated memory of a query deterministic.
+	fPointPools [maxPointsSliceSizeBits + 1]zeropool.Pool[[]promql.FPoint]
+)
+
+// MemoryConsumptionTracker tracks the estimated memory used by a query, and enforces the limit on it.
+// It also tracks the number of points loaded in memory by the query, and enforces the max samples limit
+// of the engine on it, like the Prometheus engine does.
+// It's not safe for concurrent use, as each query is evaluated by a single goroutine.
+type MemoryConsumptionTracker struct {
+	// maxEstimatedMemoryConsumptionBytes is the limit, 0 if unlimited.
+	maxEstimatedMemoryConsumptionBytes uint64
+
+	// maxSamples is the limit on the points loaded in memory, 0 if unlimited.
+	maxSamples int
+
+	CurrentEstimatedMemoryConsumptionBytes uint64
+	PeakEstimatedMemoryConsumptionBytes    uint64
+
+	// CurrentSamples is the number of points the points slices in use can hold, which is the
+	// number of points the query may have loaded in memory.
+	CurrentSamples int
+}
+
+func NewMemoryConsumptionTracker(maxEstimatedMemoryConsumptionBytes uint64, maxSamples int) *MemoryConsumptionTracker {
+	return &MemoryConsumptionTracker{maxEstimatedMemoryConsumptionBytes: maxEstimatedMemoryConsumptionBytes, maxSamples: maxSamples}
+}
+
+// IncreaseMemoryConsumption records the allocation of b bytes. It returns an error, without recording
+// the allocation, if it would exceed the limit.
+func (t *MemoryConsumptionTracker) IncreaseMemoryConsumption(b uint64) error {
+	if t.maxEstimatedMemoryConsumptionBytes > 0 && t.CurrentEstimatedMemoryConsumptionBytes+b > t.maxEstimatedMemoryConsumptionBytes {
+		return validation.LimitError(fmt.Sprintf(MaxEstimatedMemoryPerQueryMsgFormat, t.maxEstimatedMemoryConsumptionBytes))
+	}
+
+	t.CurrentEstimatedMemoryConsumptionBytes += b
+	t.PeakEstimatedMemoryConsumptionBytes = max(t.PeakEstimatedMemoryConsumptionBytes, t.CurrentEstimatedMemoryConsumptionBytes)
+	return nil
+}
+
+// DecreaseMemoryConsumption records the release of b bytes.
+func (t *MemoryConsumptionTracker) DecreaseMemoryConsumption(b uint64) {
+	if b > t.CurrentEstimatedMemoryConsumptionBytes {
+		panic(fmt.Sprintf("attempted to release %d bytes, but only %d bytes are recorded as in use", b, t.CurrentEstimatedMemoryConsumptionBytes))
+	}
+
+	t.CurrentEstimatedMemoryConsumptionBytes -= b
+}
+
+// increaseSamples records n more points loaded in memory. It returns an error, without recording
+// them, if they would exceed the max samples limit.
+func (t *MemoryConsumptionTracker) increaseSamples(n int) error {
+	if t.maxSamples > 0 && t.CurrentSamples+n > t.maxSamples {
+		return promql.ErrTooManySamples("query execution")
+	}
+
+	t.CurrentSamples += n
+	return nil
+}
+
+// decreaseSamples records the release of n points.
+func (t *MemoryConsumptionTracker) decreaseSamples(n int) {
+	t.CurrentSamples -= n
+}
+
+// getFPointSlice returns an empty points slice with capacity of at least size, recording its memory
+// and its capacity as loaded samples.
+func getFPointSlice(size int, tracker *MemoryConsumptionTracker) ([]promql.FPoint, error) {
+	var s []promql.FPoint
+	if bucket := fPointPoolBucket(size); bucket < len(fPointPools) {
+		s = fPointPools[bucket].Get()
+		if s == nil {
+			s = make([]promql.FPoint, 0, 1<<bucket)
+		}
+	} else {
+		s = make([]promql.FPoint, 0, size)
+	}
+
+	if err := tracker.increaseSamples(cap(s)); err != nil {
+		putFPointSliceUntracked(s)
+		return nil, err
+	}
+
+	if err := tracker.IncreaseMemoryConsumption(uint64(cap(s)) * fPointSize); err != nil {
+		tracker.decreaseSamples(cap(s))
+		putFPointSliceUntracked(s)
+		return nil, err
+	}
+
+	return s[:0], nil
+}
+
+// putFPointSlice returns a points slice obtained from getFPointSlice, releasing its memory.
+func putFPointSlice(s []promql.FPoint, tracker *MemoryConsumptionTracker) {
+	if s == nil {
+		return
+	}
+
+	tracker.DecreaseMemoryConsumption(uint64(cap(s)) * fPointSize)
+	tracker.decreaseSamples(cap(s))
+	putFPointSliceUntracked(s)
+}
+
+func putFPointSliceUntracked(s []promql.FPoint) {
+	// Only the slices obtained from the pools have a capacity which is a power of two.
+	if bucket := fPointPoolBucket(cap(s)); bucket < len(fPointPools) && cap(s) == 1<<bucket {
+		fPointPools[bucket].Put(s[:0])
+	}
+}
+
+// fPointPoolBucket returns the index of the pool holding the slices with capacity of at least size.
+func fPointPoolBucket(size int) int {
+	if size <= 1 {
+		return 0
+	}
+	return bits.Len(uint(size - 1))
+}
+
+// appendFPoint appends p to s, growing s through the pool if it's full.
+func appendFPoint(s []promql.FPoint, p promql.FPoint, tracker *MemoryConsumptionTracker) ([]promql.FPoint, error) {
+	if len(s) == cap(s) {
+		grown, err := getFPointSlice(max(2*cap(s), 16), tracker)
+		if err != nil {
+			return nil, err
+		}
+
+		grown = append(grown, s...)
+		putFPointSlice(s, tracker)
+		s = grown
+	}
+
+	return append(s, p), nil
+}
+
+// labelsSize returns the estimated memory of the labels.
+func labelsSize(l labels.Labels) uint64 {
+	size := uint64(0)
+	l.Range(func(l labels.Label) {
+		size += estimatedLabelBytes + uint64(len(l.Name)+len(l.Value))
+	})
+	return size
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/util/annotations"
+)
+
+// InstantVectorOperator evaluates an expression producing an instant vector. The series are returned one at a
+// time, each with its points over all the query steps, so that only the series being evaluated is held in memory.
+type InstantVectorOperator interface {
+	// SeriesMetadata returns the labels of the series returned by the operator, in the same order as NextSeries
+	// returns them. It must be called once, before NextSeries.
+	SeriesMetadata(ctx context.Context) ([]labels.Labels, error)
+
+	// NextSeries returns the points of the next series, at most one per step. The caller owns the returned slice
+	// and must release it with putFPointSlice. It returns errEOS once all the series have been returned.
+	NextSeries(ctx context.Context) ([]promql.FPoint, error)
+
+	// Close releases the resources held by the operator.
+	Close()
+}
+
+// timeRange is the range of the steps a query is evaluated at.
+type timeRange struct {
+	start, end int64 // Milliseconds, inclusive.
+	interval   int64 // Milliseconds, 1 for instant queries.
+	steps      int
+}
+
+func newTimeRange(start, end time.Time, interval time.Duration) timeRange {
+	r := timeRange{start: timeMilliseconds(start), end: timeMilliseconds(end), interval: interval.Milliseconds()}
+	if r.interval == 0 {
+		// Instant query.
+		r.interval = 1
+	}
+	r.steps = int((r.end-r.start)/r.interval) + 1
+	return r
+}
+
+// stepIndex returns the index of the step at timestamp t.
+func (r timeRange) stepIndex(t int64) int {
+	return int((t - r.start) / r.interval)
+}
+
+// selector selects the series of a vector or matrix selector, for all the query steps.
+type selector struct {
+	queryable storage.Queryable
+	timeRange timeRange
+	offset    int64 // Milliseconds.
+	// lookback is the range, in milliseconds, before each step which is selected: the lookback delta for
+	// vector selectors, or the range for matrix selectors.
+	lookback int64
+	// function is the name of the function the selected series are passed to, if any, used as query hint.
+	function string
+	matchers []*labels.Matcher
+	tracker  *MemoryConsumptionTracker
+	annos    *annotations.Annotations
+
+	querier     storage.Querier
+	series      []storage.Series
+	labelsBytes uint64
+}
+
+// seriesMetadata selects the series and returns their labels.
+func (s *selector) seriesMetadata(ctx context.Context) ([]labels.Labels, error) {
+	if s.series != nil {
+		panic("seriesMetadata called twice")
+	}
+
+	// The first step selects the samples in the lookback before it.
+	mint := s.timeRange.start - s.offset - s.lookback
+	maxt := s.timeRange.end - s.offset
+
+	var err error
+	s.querier, err = s.queryable.Querier(mint, maxt)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := &storage.SelectHints{
+		Start: mint,
+		End:   maxt,
+		Step:  s.timeRange.interval,
+		Range: s.lookback,
+		Func:  s.function,
+	}
+
+	ss := s.querier.Select(ctx, true, hints, s.matchers...)
+	metadata := []labels.Labels{}
+	for ss.Next() {
+		series := ss.At()
+		size := labelsSize(series.Labels())
+		if err := s.tracker.IncreaseMemoryConsumption(size); err != nil {
+			return nil, err
+		}
+		s.labelsBytes += size
+
+		s.series = append(s.series, series)
+		metadata = append(metadata, series.Labels())
+	}
+	if err := ss.Err(); err != nil {
+		return nil, err
+	}
+	s.annos.Merge(ss.Warnings())
+
+	if s.series == nil {
+		s.series = []storage.Series{}
+	}
+
+	return metadata, nil
+}
+
+// next returns the iterator over the samples of the next series, or errEOS.
+func (s *selector) next(it chunkenc.Iterator) (chunkenc.Iterator, error) {
+	if len(s.series) == 0 {
+		return nil, errEOS
+	}
+
+	series := s.series[0]
+	s.series = s.series[1:]
+	return series.Iterator(it), nil
+}
+
+func (s *selector) close() {
+	s.tracker.DecreaseMemoryConsumption(s.labelsBytes)
+	s.labelsBytes = 0
+	s.series = nil
+
+	if s.querier != nil {
+		_ = s.querier.Close()
+		s.querier = nil
+	}
+}
+
+// instantVectorSelector evaluates a vector selector: at each step, the value of each series is its latest sample
+// in the lookback delta before the step, unless it's a staleness marker.
+type instantVectorSelector struct {
+	selector *selector
+	it       chunkenc.Iterator
+}
+
+var _ InstantVectorOperator = &instantVectorSelector{}
+
+func (v *instantVectorSelector) SeriesMetadata(ctx context.Context) ([]labels.Labels, error) {
+	return v.selector.seriesMetadata(ctx)
+}
+
+func (v *instantVectorSelector) NextSeries(ctx context.Context) ([]promql.FPoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	v.it, err = v.selector.next(v.it)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := getFPointSlice(v.selector.timeRange.steps, v.selector.tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		r         = v.selector.timeRange
+		valueType = v.it.Next()
+		lastT     int64
+		lastF     float64
+		hasLast   bool
+	)
+
+	for ts := r.start; ts <= r.end; ts += r.interval {
+		refT := ts - v.selector.offset
+
+		// Consume all the samples up to the step.
+	samples:
+		for ; valueType != chunkenc.ValNone; valueType = v.it.Next() {
+			switch valueType {
+			case chunkenc.ValFloat:
+				if v.it.AtT() > refT {
+					break samples
+				}
+				lastT, lastF = v.it.At()
+				hasLast = true
+			default:
+				putFPointSlice(points, v.selector.tracker)
+				return nil, NewNotSupportedError("native histograms")
+			}
+		}
+
+		if hasLast && lastT >= refT-v.selector.lookback && !value.IsStaleNaN(lastF) {
+			points = append(points, promql.FPoint{T: ts, F: lastF})
+		}
+	}
+
+	if err := v.it.Err(); err != nil {
+		putFPointSlice(points, v.selector.tracker)
+		return nil, err
+	}
+
+	return points, nil
+}
+
+func (v *instantVectorSelector) Close() {
+	v.selector.close()
+}
+
+func timeMilliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond/time.Nanosecond)
+}
@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package streamingpromql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+	"github.com/prometheus/prometheus/util/stats"
+)
+
+type query struct {
+	engine    *Engine
+	queryable storage.Queryable
+	qs        string
+	statement *parser.EvalStmt
+	timeRange timeRange
+	cancel    context.CancelFunc
+
+	// The following are set when the query is executed.
+	root    InstantVectorOperator
+	tracker *MemoryConsumptionTracker
+	annos   annotations.Annotations
+	matrix  promql.Matrix
+}
+
+var _ promql.Query = &query{}
+
+func newQuery(e *Engine, queryable storage.Queryable, opts promql.QueryOpts, qs string, start, end time.Time, interval time.Duration) (*query, error) {
+	expr, err := parser.ParseExpr(qs)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Type() != parser.ValueTypeVector {
+		return nil, NewNotSupportedError(fmt.Sprintf("%s result type", parser.DocumentedType(expr.Type())))
+	}
+
+	lookbackDelta := e.lookbackDelta
+	if opts != nil && opts.LookbackDelta() > 0 {
+		lookbackDelta = opts.LookbackDelta()
+	}
+
+	q := &query{
+		engine:    e,
+		queryable: queryable,
+		qs:        qs,
+		statement: &parser.EvalStmt{
+			Expr:          expr,
+			Start:         start,
+			End:           end,
+			Interval:      interval,
+			LookbackDelta: lookbackDelta,
+		},
+		timeRange: newTimeRange(start, end, interval),
+	}
+
+	// Check the query is supported before it's executed, so that it can fall back to another engine.
+	if _, err := q.convertToOperator(expr, &MemoryConsumptionTracker{}); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// convertToOperator returns the operator evaluating expr, or a NotSupportedError.
+func (q *query) convertToOperator(expr parser.Expr, tracker *MemoryConsumptionTracker) (InstantVectorOperator, error) {
+	switch e := expr.(type) {
+	case *parser.VectorSelector:
+		s, err := q.newSelector(e, q.statement.LookbackDelta, "", tracker)
+		if err != nil {
+			return nil, err
+		}
+		return &instantVectorSelector{selector: s}, nil
+
+	case *parser.Call:
+		if e.Func.Name != "rate" && e.Func.Name != "increase" {
+			return nil, NewNotSupportedError(fmt.Sprintf("'%s' function", e.Func.Name))
+		}
+
+		m, ok := e.Args[0].(*parser.MatrixSelector)
+		if !ok {
+			return nil, NewNotSupportedError(fmt.Sprintf("'%s' function over a subquery", e.Func.Name))
+		}
+
+		s, err := q.newSelector(m.VectorSelector.(*parser.VectorSelector), m.Range, e.Func.Name, tracker)
+		if err != nil {
+			return nil, err
+		}
+		return &rangeVectorFunction{selector: s, isRate: e.Func.Name == "rate"}, nil
+
+	case *parser.AggregateExpr:
+		if e.Op != parser.SUM && e.Op != parser.COUNT && e.Op != parser.MIN && e.Op != parser.MAX {
+			return nil, NewNotSupportedError(fmt.Sprintf("'%s' aggregation", e.Op))
+		}
+
+		inner, err := q.convertToOperator(e.Expr, tracker)
+		if err != nil {
+			return nil, err
+		}
+
+		return &aggregation{
+			inner:     inner,
+			op:        e.Op,
+			grouping:  e.Grouping,
+			without:   e.Without,
+			timeRange: q.timeRange,
+			tracker:   tracker,
+		}, nil
+
+	case *parser.ParenExpr:
+		return q.convertToOperator(e.Expr, tracker)
+
+	default:
+		return nil, NewNotSupportedError(fmt.Sprintf("PromQL expression type %T", e))
+	}
+}
+
+func (q *query) newSelector(vs *parser.VectorSelector, lookback time.Duration, function string, tracker *MemoryConsumptionTracker) (*selector, error) {
+	if vs.Timestamp != nil || vs.StartOrEnd != 0 {
+		return nil, NewNotSupportedError("'@' modifier")
+	}
+
+	return &selector{
+		queryable: q.queryable,
+		timeRange: q.timeRange,
+		offset:    vs.OriginalOffset.Milliseconds(),
+		lookback:  lookback.Milliseconds(),
+		function:  function,
+		matchers:  vs.LabelMatchers,
+		tracker:   tracker,
+		annos:     &q.annos,
+	}, nil
+}
+
+func (q *query) Exec(ctx context.Context) *promql.Result {
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+	defer cancel()
+
+	if q.engine.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, q.engine.timeout)
+		defer cancelTimeout()
+	}
+
+	if q.engine.activeQueryTracker != nil {
+		queryID, err := q.engine.activeQueryTracker.Insert(ctx, q.qs)
+		if err != nil {
+			return &promql.Result{Err: err}
+		}
+		defer q.engine.activeQueryTracker.Delete(queryID)
+	}
+
+	maxEstimatedMemory, err := q.maxEstimatedMemoryPerQuery(ctx)
+	if err != nil {
+		return &promql.Result{Err: err}
+	}
+
+	q.tracker = NewMemoryConsumptionTracker(maxEstimatedMemory, q.engine.maxSamples)
+	defer func() {
+		q.engine.estimatedPeakMemory.Observe(float64(q.tracker.PeakEstimatedMemoryConsumptionBytes))
+	}()
+
+	q.root, err = q.convertToOperator(q.statement.Expr, q.tracker)
+	if err != nil {
+		return &promql.Result{Err: err}
+	}
+	defer q.root.Close()
+
+	var value parser.Value
+	if q.statement.Interval == 0 {
+		value, err = q.populateVector(ctx)
+	} else {
+		value, err = q.populateMatrix(ctx)
+	}
+	if err != nil {
+		return &promql.Result{Err: contextErr(err, "expression evaluation"), Warnings: q.annos}
+	}
+
+	return &promql.Result{Value: value, Warnings: q.annos}
+}
+
+// maxEstimatedMemoryPerQuery returns the smallest non-zero limit of the query's tenants, or 0 if unlimited.
+func (q *query) maxEstimatedMemoryPerQuery(ctx context.Context) (uint64, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var limit uint64
+	for _, tenantID := range tenantIDs {
+		if l := q.engine.limits.MaxEstimatedMemoryPerQuery(tenantID); l > 0 && (limit == 0 || l < limit) {
+			limit = l
+		}
+	}
+
+	return limit, nil
+}
+
+func (q *query) populateVector(ctx context.Context) (promql.Vector, error) {
+	metadata, err := q.root.SeriesMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vector := make(promql.Vector, 0, len(metadata))
+	for _, l := range metadata {
+		points, err := q.root.NextSeries(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(points) > 0 {
+			vector = append(vector, promql.Sample{Metric: l, T: points[0].T, F: points[0].F})
+		}
+		putFPointSlice(points, q.tracker)
+	}
+
+	return vector, nil
+}
+
+func (q *query) populateMatrix(ctx context.Context) (promql.Matrix, error) {
+	metadata, err := q.root.SeriesMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q.matrix = make(promql.Matrix, 0, len(metadata))
+	for _, l := range metadata {
+		points, err := q.root.NextSeries(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(points) == 0 {
+			putFPointSlice(points, q.tracker)
+			continue
+		}
+
+		// The points are released when the query is closed.
+		q.matrix = append(q.matrix, promql.Series{Metric: l, Floats: points})
+	}
+
+	// Same as the Prometheus engine, the series of range queries are sorted by labels.
+	slices.SortFunc(q.matrix, func(a, b promql.Series) int {
+		return labels.Compare(a.Metric, b.Metric)
+	})
+
+	return q.matrix, nil
+}
+
+// Close releases the points of the query result.
+func (q *query) Close() {
+	for _, s := range q.matrix {
+		putFPointSlice(s.Floats, q.tracker)
+	}
+	q.matrix = nil
+}
+
+func (q *query) Statement() parser.Statement {
+	return q.statement
+}
+
+func (q *query) Stats() *stats.Statistics {
+	// Not supported by the streaming engine.
+	return &stats.Statistics{
+		Timers:  stats.NewQueryTimers(),
+		Samples: stats.NewQuerySamples(false),
+	}
+}
+
+func (q *query) Cancel() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+}
+
+func (q *query) String() string {
+	return q.qs
+}
+
+func contextErr(err error, env string) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return promql.ErrQueryCanceled(env)
+	case errors.Is(err, context.DeadlineExceeded):
+		return promql.ErrQueryTimeout(env)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+// Provenance-includes-location: https://github.com/prometheus/prometheus/blob/main/promql/functions.go
+// Provenance-includes-license: Apache-2.0
+// Provenance-includes-copyright: The Prometheus Authors.
+
+package streamingpromql
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+)
+
+// rangeVectorFunction evaluates rate() or increase() over a matrix selector: at each step, the function is
+// applied to the samples of each series in the range before the step. The samples of a series are read only
+// once, keeping in memory only the ones in the range of the current step.
+type rangeVectorFunction struct {
+	selector *selector
+	isRate   bool
+	it       chunkenc.Iterator
+}
+
+var _ InstantVectorOperator = &rangeVectorFunction{}
+
+func (f *rangeVectorFunction) SeriesMetadata(ctx context.Context) ([]labels.Labels, error) {
+	metadata, err := f.selector.seriesMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The functions drop the metric name. The series which end up with the same labels would need to be merged
+	// if they don't overlap in time, or rejected otherwise, which isn't supported.
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	seen := make(map[string]struct{}, len(metadata))
+	buf := make([]byte, 0, 1024)
+	for i, l := range metadata {
+		lb.Reset(l)
+		lb.Del(labels.MetricName)
+		metadata[i] = lb.Labels()
+
+		buf = metadata[i].Bytes(buf)
+		if _, ok := seen[string(buf)]; ok {
+			return nil, NewNotSupportedError("series with the same labels after dropping the metric name")
+		}
+		seen[string(buf)] = struct{}{}
+	}
+
+	return metadata, nil
+}
+
+func (f *rangeVectorFunction) NextSeries(ctx context.Context) ([]promql.FPoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	f.it, err = f.selector.next(f.it)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := f.selector.tracker
+	points, err := getFPointSlice(f.selector.timeRange.steps, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	// window holds the samples in the range of the current step.
+	var window []promql.FPoint
+	defer func() {
+		putFPointSlice(window, tracker)
+	}()
+
+	var (
+		r         = f.selector.timeRange
+		valueType = f.it.Next()
+	)
+
+	for ts := r.start; ts <= r.end; ts += r.interval {
+		rangeEnd := ts - f.selector.offset
+		rangeStart := rangeEnd - f.selector.lookback
+
+		// Drop the samples before the range...
+		drop := 0
+		for drop < len(window) && window[drop].T < rangeStart {
+			drop++
+		}
+		window = window[:copy(window, window[drop:])]
+
+		// ...and append the ones up to its end.
+	samples:
+		for ; valueType != chunkenc.ValNone; valueType = f.it.Next() {
+			switch valueType {
+			case chunkenc.ValFloat:
+				t, v := f.it.At()
+				if t > rangeEnd {
+					break samples
+				}
+				if t < rangeStart || value.IsStaleNaN(v) {
+					continue
+				}
+				if window, err = appendFPoint(window, promql.FPoint{T: t, F: v}, tracker); err != nil {
+					putFPointSlice(points, tracker)
+					return nil, err
+				}
+			default:
+				putFPointSlice(points, tracker)
+				return nil, NewNotSupportedError("native histograms")
+			}
+		}
+
+		if len(window) > 1 {
+			points = append(points, promql.FPoint{T: ts, F: extrapolatedRate(window, rangeStart, rangeEnd, f.isRate)})
+		}
+	}
+
+	if err := f.it.Err(); err != nil {
+		putFPointSlice(points, tracker)
+		return nil, err
+	}
+
+	return points, nil
+}
+
+func (f *rangeVectorFunction) Close() {
+	f.selector.close()
+}
+
+// extrapolatedRate calculates the increase, or the per-second rate, of the counter samples in the range
+// [rangeStart, rangeEnd], extrapolating it to the boundaries of the range. It requires at least two samples.
+func extrapolatedRate(samples []promql.FPoint, rangeStart, rangeEnd int64, isRate bool) float64 {
+	var (
+		numSamplesMinusOne = len(samples) - 1
+		firstT             = samples[0].T
+		lastT              = samples[numSamplesMinusOne].T
+		result             = samples[numSamplesMinusOne].F - samples[0].F
+	)
+
+	// Handle counter resets.
+	prevValue := samples[0].F
+	for _, currPoint := range samples[1:] {
+		if currPoint.F < prevValue {
+			result += prevValue
+		}
+		prevValue = currPoint.F
+	}
+
+	// Duration between first/last samples and boundary of range.
+	durationToStart := float64(firstT-rangeStart) / 1000
+	durationToEnd := float64(rangeEnd-lastT) / 1000
+
+	sampledInterval := float64(lastT-firstT) / 1000
+	averageDurationBetweenSamples := sampledInterval / float64(numSamplesMinusOne)
+
+	if result > 0 && samples[0].F >= 0 {
+		// Counters cannot be negative. If we have any slope at all (i.e. result went up), we can extrapolate
+		// the zero point of the counter. If the duration to the zero point is shorter than the durationToStart,
+		// we take the zero point as the start of the series, thereby avoiding extrapolation to negative
+		// counter values.
+		durationToZero := sampledInterval * (samples[0].F / result)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	// If the first/last samples are close to the boundaries of the range, extrapolate the result. This is as
+	// we expect that another sample will exist given the spacing between samples we've seen thus far, with an
+	// allowance for noise.
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	extrapolateToInterval := sampledInterval
+
+	if durationToStart < extrapolationThreshold {
+		extrapolateToInterval += durationToStart
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+	if durationToEnd < extrapolationThreshold {
+		extrapolateToInterval += durationToEnd
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+
+	factor := extrapolateToInterval / sampledInterval
+	if isRate {
+		factor /= float64(rangeEnd-rangeStart) / 1000
+	}
+
+	return result * factor
+}
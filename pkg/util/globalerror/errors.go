@@ -27,15 +27,19 @@ const (
 	MaxSeriesPerMetric            ID = "max-series-per-metric"
 	MaxMetadataPerMetric          ID = "max-metadata-per-metric"
 	MaxSeriesPerUser              ID = "max-series-per-user"
+	MaxSeriesPerLabelSet          ID = "max-series-per-label-set"
+	MaxHeadMemoryPerUser          ID = "max-head-memory-per-user"
 	MaxMetadataPerUser            ID = "max-metadata-per-user"
 	MaxChunksPerQuery             ID = "max-chunks-per-query"
 	MaxSeriesPerQuery             ID = "max-series-per-query"
 	MaxChunkBytesPerQuery         ID = "max-chunks-bytes-per-query"
 	MaxEstimatedChunksPerQuery    ID = "max-estimated-chunks-per-query"
+	MaxEstimatedMemoryPerQuery    ID = "max-estimated-memory-per-query"
 
 	DistributorMaxIngestionRate             ID = "distributor-max-ingestion-rate"
 	DistributorMaxInflightPushRequests      ID = "distributor-max-inflight-push-requests"
 	DistributorMaxInflightPushRequestsBytes ID = "distributor-max-inflight-push-requests-bytes"
+	LabelSetIngestionRateLimited            ID = "label-set-max-ingestion-rate"
 
 	IngesterMaxIngestionRate        ID = "ingester-max-ingestion-rate"
 	IngesterMaxTenants              ID = "ingester-max-tenants"
@@ -62,10 +66,12 @@ const (
 	SampleTimestampTooOld    ID = "sample-timestamp-too-old"
 	SampleOutOfOrder         ID = "sample-out-of-order"
 	SampleDuplicateTimestamp ID = "sample-duplicate-timestamp"
+	SampleIntervalTooShort   ID = "sample-interval-too-short"
 	ExemplarSeriesMissing    ID = "exemplar-series-missing"
 	ExemplarTooFarInFuture   ID = "exemplar-too-far-in-future"
 
 	StoreConsistencyCheckFailed ID = "store-consistency-check-failed"
+	StoreGatewayPartialResponse ID = "store-gateway-partial-response"
 	BucketIndexTooOld           ID = "bucket-index-too-old"
 
 	DistributorMaxWriteMessageSize ID = "distributor-max-write-message-size"
@@ -99,6 +105,11 @@ func (id ID) MessageWithStrategyAndPerTenantLimitConfig(msg, strategy, flag stri
 		msg, errPrefix, id, strategy, plural, flagsList)
 }
 
+// IsInMessage returns whether msg has been built by one of the methods of the error ID, e.g. Message.
+func (id ID) IsInMessage(msg string) bool {
+	return strings.Contains(msg, "("+errPrefix+string(id)+")")
+}
+
 // LabelValue returns the error ID converted to a form suitable for use as a Prometheus label value.
 func (id ID) LabelValue() string {
 	return strings.ReplaceAll(string(id), "-", "_")
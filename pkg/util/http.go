@@ -7,8 +7,10 @@ package util
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
@@ -16,12 +18,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/grafana/dskit/flagext"
+	"github.com/klauspost/compress/zstd"
 	"github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"gopkg.in/yaml.v3"
@@ -138,16 +142,36 @@ type CompressionType int
 const (
 	NoCompression CompressionType = iota
 	RawSnappy
+	Gzip
+	Zstd
 )
 
+// zstdDecoderPools pools zstd decoders, which are expensive to create, by the max decompressed size they allow.
+var zstdDecoderPools sync.Map // map[int]*sync.Pool
+
+// zstdDecoderPool returns the pool of zstd decoders whose memory is bounded by maxSize: the frames requiring
+// a window larger than maxSize are rejected before the decoder allocates it.
+func zstdDecoderPool(maxSize int) *sync.Pool {
+	if pool, ok := zstdDecoderPools.Load(maxSize); ok {
+		return pool.(*sync.Pool)
+	}
+
+	maxMemory := uint64(max(maxSize, zstd.MinWindowSize))
+	pool, _ := zstdDecoderPools.LoadOrStore(maxSize, &sync.Pool{
+		New: func() interface{} {
+			// The error can only be caused by invalid options.
+			dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxMemory), zstd.WithDecoderMaxWindow(maxMemory))
+			return dec
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
 // ParseProtoReader parses a compressed proto from an io.Reader.
 // You can pass in and receive back the decompression buffer for pooling, or pass in nil and ignore the return.
 func ParseProtoReader(ctx context.Context, reader io.Reader, expectedSize, maxSize int, dst []byte, req proto.Message, compression CompressionType) ([]byte, error) {
 	sp := opentracing.SpanFromContext(ctx)
-	if sp != nil {
-		sp.LogFields(otlog.Event("util.ParseProtoRequest[start reading]"))
-	}
-	body, err := decompressRequest(dst, reader, expectedSize, maxSize, compression, sp)
+	body, err := DecompressRequest(ctx, reader, expectedSize, maxSize, dst, compression)
 	if err != nil {
 		return nil, err
 	}
@@ -179,11 +203,26 @@ func ParseProtoReader(ctx context.Context, reader io.Reader, expectedSize, maxSi
 	return body, nil
 }
 
+// DecompressRequest reads and decompresses a request body from an io.Reader. It fails with MsgSizeTooLargeErr
+// if either the compressed or the decompressed body is larger than maxSize.
+// You can pass in and receive back the decompression buffer for pooling, or pass in nil and ignore the return.
+func DecompressRequest(ctx context.Context, reader io.Reader, expectedSize, maxSize int, dst []byte, compression CompressionType) ([]byte, error) {
+	sp := opentracing.SpanFromContext(ctx)
+	if sp != nil {
+		sp.LogFields(otlog.Event("util.ParseProtoRequest[start reading]"))
+	}
+	return decompressRequest(dst, reader, expectedSize, maxSize, compression, sp)
+}
+
 type MsgSizeTooLargeErr struct {
 	Actual, Limit int
 }
 
 func (e MsgSizeTooLargeErr) Error() string {
+	if e.Actual < 0 {
+		// The actual size is unknown because the decompression has been stopped as soon as the limit was exceeded.
+		return fmt.Sprintf("the request has been rejected because its decompressed size exceeds the limit of %d bytes", e.Limit)
+	}
 	return fmt.Sprintf("the request has been rejected because its size of %d bytes exceeds the limit of %d bytes", e.Actual, e.Limit)
 }
 
@@ -228,12 +267,12 @@ func decompressFromReader(dst []byte, reader io.Reader, expectedSize, maxSize in
 	case NoCompression:
 		_, err = buf.ReadFrom(reader)
 		body = buf.Bytes()
-	case RawSnappy:
+	case RawSnappy, Gzip, Zstd:
 		_, err = buf.ReadFrom(reader)
 		if err != nil {
 			return nil, err
 		}
-		body, err = decompressFromBuffer(dst, &buf, maxSize, RawSnappy, sp)
+		body, err = decompressFromBuffer(dst, &buf, maxSize, compression, sp)
 	}
 	return body, err
 }
@@ -262,10 +301,60 @@ func decompressFromBuffer(dst []byte, buffer *bytes.Buffer, maxSize int, compres
 			return nil, err
 		}
 		return body, nil
+	case Gzip, Zstd:
+		return decompressStream(dst, bytes.NewReader(buffer.Bytes()), maxSize, compression, sp)
 	}
 	return nil, nil
 }
 
+// decompressStream decompresses a gzip or zstd stream into dst, failing as soon as the decompressed body exceeds maxSize.
+func decompressStream(dst []byte, reader io.Reader, maxSize int, compression CompressionType, sp opentracing.Span) ([]byte, error) {
+	if sp != nil {
+		sp.LogFields(otlog.Event("util.ParseProtoRequest[decompress]"))
+	}
+
+	var decompressed io.Reader
+	switch compression {
+	case Gzip:
+		gr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		decompressed = gr
+	case Zstd:
+		pool := zstdDecoderPool(maxSize)
+		dec := pool.Get().(*zstd.Decoder)
+		defer func() {
+			// Release the reference to the input before returning the decoder to the pool.
+			_ = dec.Reset(nil)
+			pool.Put(dec)
+		}()
+		if err := dec.Reset(reader); err != nil {
+			return nil, zstdDecompressError(err, maxSize)
+		}
+		decompressed = dec
+	}
+
+	// Read up to maxSize+1 bytes, so we know if the decompressed body is over the limit without decompressing it all.
+	buf := bytes.NewBuffer(dst[:0])
+	if _, err := buf.ReadFrom(io.LimitReader(decompressed, int64(maxSize)+1)); err != nil {
+		return nil, zstdDecompressError(err, maxSize)
+	}
+	if buf.Len() > maxSize {
+		return nil, MsgSizeTooLargeErr{Actual: -1, Limit: maxSize}
+	}
+	return buf.Bytes(), nil
+}
+
+// zstdDecompressError returns MsgSizeTooLargeErr if err is caused by a zstd frame requiring more memory than maxSize.
+func zstdDecompressError(err error, maxSize int) error {
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return MsgSizeTooLargeErr{Actual: -1, Limit: maxSize}
+	}
+	return err
+}
+
 // tryBufferFromReader attempts to cast the reader to a `*bytes.Buffer` this is possible when using httpgrpc.
 // If it fails it will return nil and false.
 func tryBufferFromReader(reader io.Reader) (*bytes.Buffer, bool) {
@@ -289,6 +378,23 @@ func SerializeProtoResponse(w http.ResponseWriter, resp proto.Message, compressi
 	case NoCompression:
 	case RawSnappy:
 		data = snappy.Encode(nil, data)
+	case Gzip:
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(data); err != nil {
+			return fmt.Errorf("error compressing proto response: %v", err)
+		}
+		if err := gw.Close(); err != nil {
+			return fmt.Errorf("error compressing proto response: %v", err)
+		}
+		data = buf.Bytes()
+	case Zstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return fmt.Errorf("error compressing proto response: %v", err)
+		}
+		data = enc.EncodeAll(data, nil)
+		_ = enc.Close()
 	}
 
 	if _, err := w.Write(data); err != nil {
@@ -8,6 +8,7 @@ package util_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"html/template"
 	"io"
 	"math/rand"
@@ -211,6 +212,61 @@ func TestParseProtoReader(t *testing.T) {
 	}
 }
 
+func TestDecompressRequest(t *testing.T) {
+	// Highly compressible data, which gets much bigger once decompressed.
+	data := bytes.Repeat([]byte("mimir"), 2000)
+
+	for name, compression := range map[string]util.CompressionType{"gzip": util.Gzip, "zstd": util.Zstd} {
+		w := httptest.NewRecorder()
+		require.NoError(t, util.SerializeProtoResponse(w, &mimirpb.LabelPair{Name: data}, compression))
+		compressed := w.Body.Bytes()
+		require.Less(t, len(compressed), 1000)
+
+		for _, useBytesBuffer := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s bytesbuffer=%t", name, useBytesBuffer), func(t *testing.T) {
+				newReader := func() io.Reader {
+					if useBytesBuffer {
+						return bytesBuffered{Buffer: bytes.NewBuffer(compressed)}
+					}
+					return bytes.NewReader(compressed)
+				}
+
+				body, err := util.DecompressRequest(context.Background(), newReader(), len(compressed), 20000, nil, compression)
+				require.NoError(t, err)
+				var fromWire mimirpb.LabelPair
+				require.NoError(t, fromWire.Unmarshal(body))
+				assert.Equal(t, data, fromWire.Name)
+
+				_, err = util.DecompressRequest(context.Background(), newReader(), len(compressed), 1000, nil, compression)
+				assert.ErrorIs(t, err, util.MsgSizeTooLargeErr{})
+				assert.EqualError(t, err, "the request has been rejected because its decompressed size exceeds the limit of 1000 bytes")
+			})
+		}
+	}
+}
+
+func TestDecompressRequest_ZstdWindowLargerThanLimit(t *testing.T) {
+	// A frame declaring a window much larger than the limit, while its content is below the limit: the decoder
+	// must reject it because of the window size, rather than allocating the window. The encoder shrinks the
+	// window to the size of the content, so the frame is built by hand.
+	compressed := []byte{
+		0x28, 0xb5, 0x2f, 0xfd, // Magic number.
+		0x00,             // Frame header descriptor: no content size, no single segment, no checksum.
+		0x68,             // Window descriptor: 8MiB window (exponent 13, mantissa 0).
+		0x01, 0x20, 0x00, // Block header: last raw block of 1KiB.
+	}
+	compressed = append(compressed, make([]byte, 1<<10)...)
+
+	_, err := util.DecompressRequest(context.Background(), bytes.NewReader(compressed), len(compressed), 64<<10, nil, util.Zstd)
+	assert.ErrorIs(t, err, util.MsgSizeTooLargeErr{})
+	assert.EqualError(t, err, "the request has been rejected because its decompressed size exceeds the limit of 65536 bytes")
+
+	// The same frame is accepted when the limit is larger than the window.
+	body, err := util.DecompressRequest(context.Background(), bytes.NewReader(compressed), len(compressed), 8<<20, nil, util.Zstd)
+	require.NoError(t, err)
+	assert.Len(t, body, 1<<10)
+}
+
 type bytesBuffered struct {
 	*bytes.Buffer
 }
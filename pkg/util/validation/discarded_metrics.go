@@ -55,3 +55,27 @@ func DiscardedMetadataCounter(reg prometheus.Registerer, reason string) *prometh
 		},
 	}, []string{"user"})
 }
+
+// DryRunDiscardedSamplesCounter creates per-user counter vector for samples which would have been discarded for a given
+// reason, if the limits weren't in dry-run mode.
+func DryRunDiscardedSamplesCounter(reg prometheus.Registerer, reason string) *prometheus.CounterVec {
+	return promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "cortex_dry_run_discarded_samples_total",
+		Help: "The total number of samples that would have been discarded, if the limits weren't in dry-run mode.",
+		ConstLabels: map[string]string{
+			discardReasonLabel: reason,
+		},
+	}, []string{"user", "group"})
+}
+
+// DryRunDiscardedMetadataCounter creates per-user counter vector for metadata which would have been discarded for a
+// given reason, if the limits weren't in dry-run mode.
+func DryRunDiscardedMetadataCounter(reg prometheus.Registerer, reason string) *prometheus.CounterVec {
+	return promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "cortex_dry_run_discarded_metadata_total",
+		Help: "The total number of metadata that would have been discarded, if the limits weren't in dry-run mode.",
+		ConstLabels: map[string]string{
+			discardReasonLabel: reason,
+		},
+	}, []string{"user"})
+}
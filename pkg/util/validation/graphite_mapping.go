@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	GraphiteMatchTypeGlob  = "glob"
+	GraphiteMatchTypeRegex = "regex"
+)
+
+// GraphiteMappingRule maps Graphite metric names matching a pattern to a metric name and labels.
+// The name and labels can reference the matched glob wildcards or regex capture groups as $1, $2, ...
+type GraphiteMappingRule struct {
+	Match     string            `yaml:"match" json:"match"`
+	MatchType string            `yaml:"match_type" json:"match_type"`
+	Name      string            `yaml:"name" json:"name"`
+	Labels    map[string]string `yaml:"labels" json:"labels"`
+
+	regex *regexp.Regexp
+}
+
+// Regexp returns the compiled regular expression of the rule. Rules loaded from the
+// configuration are compiled when validated, other rules are compiled on each call.
+func (r *GraphiteMappingRule) Regexp() (*regexp.Regexp, error) {
+	if r.regex != nil {
+		return r.regex, nil
+	}
+	return r.compileRegexp()
+}
+
+func (r *GraphiteMappingRule) validate() error {
+	if r.Match == "" {
+		return fmt.Errorf("match is required")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required for match %q", r.Match)
+	}
+
+	regex, err := r.compileRegexp()
+	if err != nil {
+		return err
+	}
+	r.regex = regex
+	return nil
+}
+
+func (r *GraphiteMappingRule) compileRegexp() (*regexp.Regexp, error) {
+	var expr string
+	switch r.MatchType {
+	case "", GraphiteMatchTypeGlob:
+		// Each wildcard matches a single node of the dot-separated metric name.
+		parts := strings.Split(r.Match, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		expr = "^" + strings.Join(parts, "([^.]*)") + "$"
+	case GraphiteMatchTypeRegex:
+		expr = r.Match
+	default:
+		return nil, fmt.Errorf("unsupported match_type %q for match %q", r.MatchType, r.Match)
+	}
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match %q: %w", r.Match, err)
+	}
+	return regex, nil
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// LabelSetLimit configures the limits applied to the series of a tenant matching a series selector,
+// for example {namespace="payments"}. A series matching multiple selectors is subject to all their limits.
+type LabelSetLimit struct {
+	Selector           string  `yaml:"selector" json:"selector"`
+	MaxSeries          int     `yaml:"max_series" json:"max_series"`
+	IngestionRate      float64 `yaml:"ingestion_rate" json:"ingestion_rate"`
+	IngestionBurstSize int     `yaml:"ingestion_burst_size" json:"ingestion_burst_size"`
+
+	matchers []*labels.Matcher
+}
+
+// Matchers returns the parsed matchers of the selector. Limits loaded from the
+// configuration are parsed when validated, other limits are parsed on each call.
+func (l *LabelSetLimit) Matchers() ([]*labels.Matcher, error) {
+	if l.matchers != nil {
+		return l.matchers, nil
+	}
+	return parser.ParseMetricSelector(l.Selector)
+}
+
+// Matches returns whether the series with the input labels matches the selector.
+func (l *LabelSetLimit) Matches(lbls labels.Labels) bool {
+	matchers, err := l.Matchers()
+	if err != nil {
+		return false
+	}
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LabelSetLimit) validate() error {
+	if l.Selector == "" {
+		return fmt.Errorf("selector is required")
+	}
+	matchers, err := parser.ParseMetricSelector(l.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector %q: %w", l.Selector, err)
+	}
+	if l.MaxSeries < 0 {
+		return fmt.Errorf("max_series must be 0 or greater for selector %q", l.Selector)
+	}
+	if l.IngestionRate < 0 {
+		return fmt.Errorf("ingestion_rate must be 0 or greater for selector %q", l.Selector)
+	}
+	if l.IngestionRate > 0 && l.IngestionBurstSize <= 0 {
+		return fmt.Errorf("ingestion_burst_size must be greater than 0 when ingestion_rate is set for selector %q", l.Selector)
+	}
+
+	l.matchers = matchers
+	return nil
+}
@@ -35,6 +35,7 @@ const (
 	MaxChunkBytesPerQueryFlag                = "querier.max-fetched-chunk-bytes-per-query"
 	MaxSeriesPerQueryFlag                    = "querier.max-fetched-series-per-query"
 	MaxEstimatedChunksPerQueryMultiplierFlag = "querier.max-estimated-fetched-chunks-per-query-multiplier"
+	MaxEstimatedMemoryPerQueryFlag           = "querier.max-estimated-memory-per-query"
 	MaxLabelNamesPerSeriesFlag               = "validation.max-label-names-per-series"
 	MaxLabelNameLengthFlag                   = "validation.max-length-label-name"
 	MaxLabelValueLengthFlag                  = "validation.max-length-label-value"
@@ -52,6 +53,18 @@ const (
 	resultsCacheTTLFlag                      = "query-frontend.results-cache-ttl"
 	resultsCacheTTLForOutOfOrderWindowFlag   = "query-frontend.results-cache-ttl-for-out-of-order-time-window"
 	QueryIngestersWithinFlag                 = "querier.query-ingesters-within"
+	limitsEnforcementModeFlag                = "validation.limits-enforcement-mode"
+	costAttributionLabelFlag                 = "validation.cost-attribution-label"
+	MinSampleIntervalFlag                    = "ingester.min-sample-interval"
+	StaleSeriesEvictionIdleTimeoutFlag       = "ingester.stale-series-eviction-idle-timeout"
+	staleSeriesEvictionMinPercentageFlag     = "ingester.stale-series-eviction-min-percentage"
+	staleSeriesEvictionMinIntervalFlag       = "ingester.stale-series-eviction-min-interval"
+	MaxHeadMemoryBytesPerTenantFlag          = "ingester.max-head-memory-bytes-per-tenant"
+
+	// EnforcementModeEnforce rejects the data exceeding the limits.
+	EnforcementModeEnforce = "enforce"
+	// EnforcementModeDryRun only tracks the data which would have been rejected by the limits, and ingests it.
+	EnforcementModeDryRun = "dry-run"
 
 	// MinCompactorPartialBlockDeletionDelay is the minimum partial blocks deletion delay that can be configured in Mimir.
 	MinCompactorPartialBlockDeletionDelay = 4 * time.Hour
@@ -87,6 +100,27 @@ type Limits struct {
 	IngestionTenantShardSize                    int                 `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
 	MetricRelabelConfigs                        []*relabel.Config   `yaml:"metric_relabel_configs,omitempty" json:"metric_relabel_configs,omitempty" doc:"nocli|description=List of metric relabel configurations. Note that in most situations, it is more effective to use metrics relabeling directly in the Prometheus server, e.g. remote_write.write_relabel_configs. Labels available during the relabeling phase and cleaned afterwards: __meta_tenant_id" category:"experimental"`
 	ServiceOverloadStatusCodeOnRateLimitEnabled bool                `yaml:"service_overload_status_code_on_rate_limit_enabled" json:"service_overload_status_code_on_rate_limit_enabled" category:"experimental"`
+
+	// Graphite ingestion.
+	GraphiteMappingRules []*GraphiteMappingRule `yaml:"graphite_mapping_rules,omitempty" json:"graphite_mapping_rules,omitempty" doc:"nocli|description=List of rules used to map the dot-separated names of metrics ingested via the Graphite endpoint to metric names and labels. Each rule matches the Graphite name with a glob (default, where each * matches a single dot-separated node) or a regular expression (match_type: regex), and sets the metric name and labels, which can reference the matched values as $1, $2, etc. Rules are evaluated in order and the first matching rule is applied. Metrics not matching any rule are ingested with the Graphite name converted to a valid metric name." category:"experimental"`
+
+	// OTLP ingestion.
+	OTelPromoteResourceAttributes    flagext.StringSliceCSV `yaml:"otel_promote_resource_attributes" json:"otel_promote_resource_attributes" category:"experimental"`
+	OTelDisableTargetInfo            bool                   `yaml:"otel_disable_target_info" json:"otel_disable_target_info" category:"experimental"`
+	OTelConvertDeltaToCumulative     bool                   `yaml:"otel_convert_delta_to_cumulative" json:"otel_convert_delta_to_cumulative" category:"experimental"`
+	OTelDeltaToCumulativeMaxSeries   int                    `yaml:"otel_delta_to_cumulative_max_series" json:"otel_delta_to_cumulative_max_series" category:"experimental"`
+	OTelDeltaToCumulativeIdleTimeout model.Duration         `yaml:"otel_delta_to_cumulative_idle_timeout" json:"otel_delta_to_cumulative_idle_timeout" category:"experimental"`
+
+	// Limits enforcement.
+	LimitsEnforcementMode string `yaml:"limits_enforcement_mode" json:"limits_enforcement_mode" category:"experimental"`
+
+	// Per-label-set limits.
+	LabelSetLimits []*LabelSetLimit `yaml:"label_set_limits,omitempty" json:"label_set_limits,omitempty" doc:"nocli|description=List of limits applied to the series matching a selector, for example {namespace=\"payments\"}. For each selector, max_series is the maximum number of in-memory series across the cluster before replication, enforced by the ingesters, and ingestion_rate and ingestion_burst_size are the samples per second rate limit and its burst, enforced by the distributors across all distributors. A series matching multiple selectors is subject to all their limits. 0 disables a limit." category:"experimental"`
+
+	// Cost attribution.
+	CostAttributionLabel          string `yaml:"cost_attribution_label" json:"cost_attribution_label" category:"experimental"`
+	MaxCostAttributionCardinality int    `yaml:"max_cost_attribution_cardinality" json:"max_cost_attribution_cardinality" category:"experimental"`
+
 	// Ingester enforced limits.
 	// Series
 	MaxGlobalSeriesPerUser   int `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
@@ -97,12 +131,21 @@ type Limits struct {
 	// Exemplars
 	MaxGlobalExemplarsPerUser int `yaml:"max_global_exemplars_per_user" json:"max_global_exemplars_per_user" category:"experimental"`
 	// Native histograms
-	NativeHistogramsIngestionEnabled bool `yaml:"native_histograms_ingestion_enabled" json:"native_histograms_ingestion_enabled" category:"experimental"`
+	NativeHistogramsIngestionEnabled    bool `yaml:"native_histograms_ingestion_enabled" json:"native_histograms_ingestion_enabled" category:"experimental"`
+	OOONativeHistogramsIngestionEnabled bool `yaml:"ooo_native_histograms_ingestion_enabled" json:"ooo_native_histograms_ingestion_enabled" category:"experimental"`
 	// Active series custom trackers
 	ActiveSeriesCustomTrackersConfig activeseries.CustomTrackersConfig `yaml:"active_series_custom_trackers" json:"active_series_custom_trackers" doc:"description=Additional custom trackers for active metrics. If there are active series matching a provided matcher (map value), the count will be exposed in the custom trackers metric labeled using the tracker name (map key). Zero valued counts are not exposed (and removed when they go back to zero)." category:"advanced"`
 	// Max allowed time window for out-of-order samples.
 	OutOfOrderTimeWindow                 model.Duration `yaml:"out_of_order_time_window" json:"out_of_order_time_window" category:"experimental"`
 	OutOfOrderBlocksExternalLabelEnabled bool           `yaml:"out_of_order_blocks_external_label_enabled" json:"out_of_order_blocks_external_label_enabled" category:"experimental"`
+	// Min allowed interval between two samples of the same series.
+	MinSampleInterval model.Duration `yaml:"min_sample_interval" json:"min_sample_interval" category:"experimental"`
+	// Eviction of the stale series from the TSDB head.
+	StaleSeriesEvictionIdleTimeout   model.Duration `yaml:"stale_series_eviction_idle_timeout" json:"stale_series_eviction_idle_timeout" category:"experimental"`
+	StaleSeriesEvictionMinPercentage int            `yaml:"stale_series_eviction_min_percentage" json:"stale_series_eviction_min_percentage" category:"experimental"`
+	StaleSeriesEvictionMinInterval   model.Duration `yaml:"stale_series_eviction_min_interval" json:"stale_series_eviction_min_interval" category:"experimental"`
+	// Max estimated memory of the TSDB head in each ingester.
+	MaxHeadMemoryBytesPerTenant int64 `yaml:"max_head_memory_bytes_per_tenant" json:"max_head_memory_bytes_per_tenant" category:"experimental"`
 
 	// User defined label to give the option of subdividing specific metrics by another label
 	SeparateMetricsGroupLabel string `yaml:"separate_metrics_group_label" json:"separate_metrics_group_label" category:"experimental"`
@@ -112,6 +155,7 @@ type Limits struct {
 	MaxEstimatedChunksPerQueryMultiplier float64        `yaml:"max_estimated_fetched_chunks_per_query_multiplier" json:"max_estimated_fetched_chunks_per_query_multiplier" category:"experimental"`
 	MaxFetchedSeriesPerQuery             int            `yaml:"max_fetched_series_per_query" json:"max_fetched_series_per_query"`
 	MaxFetchedChunkBytesPerQuery         int            `yaml:"max_fetched_chunk_bytes_per_query" json:"max_fetched_chunk_bytes_per_query"`
+	MaxEstimatedMemoryPerQuery           uint64         `yaml:"max_estimated_memory_per_query" json:"max_estimated_memory_per_query" category:"experimental"`
 	MaxQueryLookback                     model.Duration `yaml:"max_query_lookback" json:"max_query_lookback"`
 	MaxPartialQueryLength                model.Duration `yaml:"max_partial_query_length" json:"max_partial_query_length"`
 	MaxQueryParallelism                  int            `yaml:"max_query_parallelism" json:"max_query_parallelism"`
@@ -123,6 +167,7 @@ type Limits struct {
 	QueryShardingMaxRegexpSizeBytes      int            `yaml:"query_sharding_max_regexp_size_bytes" json:"query_sharding_max_regexp_size_bytes"`
 	SplitInstantQueriesByInterval        model.Duration `yaml:"split_instant_queries_by_interval" json:"split_instant_queries_by_interval" category:"experimental"`
 	QueryIngestersWithin                 model.Duration `yaml:"query_ingesters_within" json:"query_ingesters_within" category:"advanced"`
+	StoreGatewayPartialResponseEnabled   bool           `yaml:"store_gateway_partial_response_enabled" json:"store_gateway_partial_response_enabled" category:"experimental"`
 
 	// Query-frontend limits.
 	MaxTotalQueryLength                    model.Duration  `yaml:"max_total_query_length" json:"max_total_query_length"`
@@ -138,6 +183,7 @@ type Limits struct {
 	CardinalityAnalysisEnabled                    bool `yaml:"cardinality_analysis_enabled" json:"cardinality_analysis_enabled"`
 	LabelNamesAndValuesResultsMaxSizeBytes        int  `yaml:"label_names_and_values_results_max_size_bytes" json:"label_names_and_values_results_max_size_bytes"`
 	LabelValuesMaxCardinalityLabelNamesPerRequest int  `yaml:"label_values_max_cardinality_label_names_per_request" json:"label_values_max_cardinality_label_names_per_request"`
+	ActiveSeriesResultsMaxSizeBytes               int  `yaml:"active_series_results_max_size_bytes" json:"active_series_results_max_size_bytes" category:"experimental"`
 
 	// Ruler defaults and limits.
 	RulerEvaluationDelay                 model.Duration `yaml:"ruler_evaluation_delay_duration" json:"ruler_evaluation_delay_duration"`
@@ -206,6 +252,17 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.CreationGracePeriod, CreationGracePeriodFlag, "Controls how far into the future incoming samples and exemplars are accepted compared to the wall clock. Any sample or exemplar will be rejected if its timestamp is greater than '(now + grace_period)'. This configuration is enforced in the distributor, ingester and query-frontend (to avoid querying too far into the future).")
 	f.BoolVar(&l.EnforceMetadataMetricName, "validation.enforce-metadata-metric-name", true, "Enforce every metadata has a metric name.")
 	f.BoolVar(&l.ServiceOverloadStatusCodeOnRateLimitEnabled, "distributor.service-overload-status-code-on-rate-limit-enabled", false, "If enabled, rate limit errors will be reported to the client with HTTP status code 529 (Service is overloaded). If disabled, status code 429 (Too Many Requests) is used.")
+	f.Var(&l.OTelPromoteResourceAttributes, "distributor.otel-promote-resource-attributes", "Comma-separated list of OTel resource attributes to promote to labels of the series ingested via OTLP. Attributes of the data points take precedence over the promoted resource attributes with the same name.")
+	f.BoolVar(&l.OTelDisableTargetInfo, "distributor.otel-disable-target-info", false, "If true, the target_info metric, which holds the OTel resource attributes, is not generated for the metrics ingested via OTLP.")
+	f.BoolVar(&l.OTelConvertDeltaToCumulative, "distributor.otel-convert-delta-to-cumulative", false, "If true, sums and histograms with delta temporality ingested via OTLP are converted to cumulative temporality. The distributor keeps the running totals in memory, so all the delta metrics of a series must be sent to the same distributor.")
+	f.IntVar(&l.OTelDeltaToCumulativeMaxSeries, "distributor.otel-delta-to-cumulative-max-series", 10000, "Maximum number of OTLP delta series whose running totals are tracked by each distributor for the conversion to cumulative temporality. Data points of new series exceeding the limit are discarded. 0 to disable the limit.")
+	_ = l.OTelDeltaToCumulativeIdleTimeout.Set("10m")
+	f.Var(&l.OTelDeltaToCumulativeIdleTimeout, "distributor.otel-delta-to-cumulative-idle-timeout", "How long the running totals of an OTLP delta series are kept after its last data point. A series receiving data points after the timeout restarts from zero.")
+
+	f.StringVar(&l.LimitsEnforcementMode, limitsEnforcementModeFlag, EnforcementModeEnforce, fmt.Sprintf("How the ingestion limits are enforced. Supported values: %s, %s. In %s mode, the series, samples and metadata exceeding the per-tenant limits validated by the distributors, the metric relabel configs and the ingesters' series, metadata and sample interval limits are ingested, and only tracked by the dry-run discarded metrics and sampled log lines.", EnforcementModeEnforce, EnforcementModeDryRun, EnforcementModeDryRun))
+
+	f.StringVar(&l.CostAttributionLabel, costAttributionLabelFlag, "", "Label used to attribute the received samples and the active series of the tenant, for example a team label. The distributors and the ingesters track the received samples and the active series per value of the label. Empty to disable the cost attribution.")
+	f.IntVar(&l.MaxCostAttributionCardinality, "validation.max-cost-attribution-cardinality", 100, "Maximum number of values of the cost attribution label tracked at the same time per tenant. Once reached, the data with a new value of the label is attributed to the __overflow__ value. 0 to disable the limit.")
 
 	f.IntVar(&l.MaxGlobalSeriesPerUser, MaxSeriesPerUserFlag, 150000, "The maximum number of in-memory series per tenant, across the cluster before replication. 0 to disable.")
 	f.IntVar(&l.MaxGlobalSeriesPerMetric, MaxSeriesPerMetricFlag, 0, "The maximum number of in-memory series per metric name, across the cluster before replication. 0 to disable.")
@@ -216,7 +273,14 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.ActiveSeriesCustomTrackersConfig, "ingester.active-series-custom-trackers", "Additional active series metrics, matching the provided matchers. Matchers should be in form <name>:<matcher>, like 'foobar:{foo=\"bar\"}'. Multiple matchers can be provided either providing the flag multiple times or providing multiple semicolon-separated values to a single flag.")
 	f.Var(&l.OutOfOrderTimeWindow, "ingester.out-of-order-time-window", fmt.Sprintf("Non-zero value enables out-of-order support for most recent samples that are within the time window in relation to the TSDB's maximum time, i.e., within [db.maxTime-timeWindow, db.maxTime]). The ingester will need more memory as a factor of rate of out-of-order samples being ingested and the number of series that are getting out-of-order samples. If query falls into this window, cached results will use value from -%s option to specify TTL for resulting cache entry.", resultsCacheTTLForOutOfOrderWindowFlag))
 	f.BoolVar(&l.NativeHistogramsIngestionEnabled, "ingester.native-histograms-ingestion-enabled", false, "Enable ingestion of native histogram samples. If false, native histogram samples are ignored without an error. To query native histograms with query-sharding enabled make sure to set -query-frontend.query-result-response-format to 'protobuf'.")
+	f.BoolVar(&l.OOONativeHistogramsIngestionEnabled, "ingester.ooo-native-histograms-ingestion-enabled", false, "Enable experimental out-of-order native histogram ingestion. This only takes effect if -ingester.out-of-order-time-window is greater than zero and if -ingester.native-histograms-ingestion-enabled is true.")
 	f.BoolVar(&l.OutOfOrderBlocksExternalLabelEnabled, "ingester.out-of-order-blocks-external-label-enabled", false, "Whether the shipper should label out-of-order blocks with an external label before uploading them. Setting this label will compact out-of-order blocks separately from non-out-of-order blocks")
+	f.Var(&l.MinSampleInterval, MinSampleIntervalFlag, "Minimum interval between two samples of the same series. Samples received closer than this interval to the previous sample of the same series are discarded by the ingester. 0 to disable.")
+	f.Var(&l.StaleSeriesEvictionIdleTimeout, StaleSeriesEvictionIdleTimeoutFlag, fmt.Sprintf("Series that have not received any sample for longer than this duration are evicted from the ingester's memory, and stop counting towards the in-memory series limits. The eviction compacts the whole TSDB head of the tenant up until this duration ago: the samples older than this duration ago of all the series, including the active ones, are persisted into a block, and after an eviction the ingester doesn't accept samples older than this duration ago (unless out-of-order ingestion is enabled). The series activity is tracked by the active series tracker, so this option requires -%s and must not be greater than -%s. The ingester checks whether an eviction is required every -blocks-storage.tsdb.head-compaction-interval. 0 to disable.", activeseries.EnabledFlag, activeseries.IdleTimeoutFlag))
+	f.IntVar(&l.StaleSeriesEvictionMinPercentage, staleSeriesEvictionMinPercentageFlag, 10, "When the stale series eviction is enabled, the eviction is triggered only if the stale series are at least the configured percentage (0-100) of the tenant's in-memory series.")
+	_ = l.StaleSeriesEvictionMinInterval.Set("6h")
+	f.Var(&l.StaleSeriesEvictionMinInterval, staleSeriesEvictionMinIntervalFlag, fmt.Sprintf("When the stale series eviction is enabled, the minimum interval between two evictions for the same tenant. Each eviction compacts the whole TSDB head of the tenant, so this interval must be at least twice -%s.", StaleSeriesEvictionIdleTimeoutFlag))
+	f.Int64Var(&l.MaxHeadMemoryBytesPerTenant, MaxHeadMemoryBytesPerTenantFlag, 0, "The maximum estimated memory, in bytes, of the tenant's TSDB head in each ingester, including series, chunks, postings and label strings. When the limit is reached, the ingester rejects samples that would create new series, while it keeps accepting samples for the existing series. The estimate is periodically updated every 30 seconds. 0 to disable.")
 
 	f.StringVar(&l.SeparateMetricsGroupLabel, "validation.separate-metrics-group-label", "", "Label used to define the group label for metrics separation. For each write request, the group is obtained from the first non-empty group label from the first timeseries in the incoming list of timeseries. Specific distributor and ingester metrics will be further separated adding a 'group' label with group label's value. Currently applies to the following metrics: cortex_discarded_samples_total")
 
@@ -224,6 +288,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&l.MaxEstimatedChunksPerQueryMultiplier, MaxEstimatedChunksPerQueryMultiplierFlag, 0, "Maximum number of chunks estimated to be fetched in a single query from ingesters and long-term storage, as a multiple of -"+MaxChunksPerQueryFlag+". This limit is enforced in the querier. Must be greater than or equal to 1, or 0 to disable.")
 	f.IntVar(&l.MaxFetchedSeriesPerQuery, MaxSeriesPerQueryFlag, 0, "The maximum number of unique series for which a query can fetch samples from each ingesters and storage. This limit is enforced in the querier, ruler and store-gateway. 0 to disable")
 	f.IntVar(&l.MaxFetchedChunkBytesPerQuery, MaxChunkBytesPerQueryFlag, 0, "The maximum size of all chunks in bytes that a query can fetch from each ingester and storage. This limit is enforced in the querier and ruler. 0 to disable.")
+	f.Uint64Var(&l.MaxEstimatedMemoryPerQuery, MaxEstimatedMemoryPerQueryFlag, 0, "Maximum estimated memory, in bytes, a single query can use while being evaluated. This limit is enforced in the querier and ruler, and only when the streaming PromQL engine is used. 0 to disable.")
 	f.Var(&l.MaxPartialQueryLength, maxPartialQueryLengthFlag, "Limit the time range for partial queries at the querier level.")
 	f.Var(&l.MaxQueryLookback, "querier.max-query-lookback", "Limit how long back data (series and metadata) can be queried, up until <lookback> duration ago. This limit is enforced in the query-frontend, querier and ruler. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
 	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 14, "Maximum number of split (by time) or partial (by shard) queries that will be scheduled in parallel by the query-frontend for a single input query. This limit is introduced to have a fairer query scheduling and avoid a single query over a large time range saturating all available queriers.")
@@ -231,6 +296,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.LabelNamesAndValuesResultsMaxSizeBytes, "querier.label-names-and-values-results-max-size-bytes", 400*1024*1024, "Maximum size in bytes of distinct label names and values. When querier receives response from ingester, it merges the response with responses from other ingesters. This maximum size limit is applied to the merged(distinct) results. If the limit is reached, an error is returned.")
 	f.BoolVar(&l.CardinalityAnalysisEnabled, "querier.cardinality-analysis-enabled", false, "Enables endpoints used for cardinality analysis.")
 	f.IntVar(&l.LabelValuesMaxCardinalityLabelNamesPerRequest, "querier.label-values-max-cardinality-label-names-per-request", 100, "Maximum number of label names allowed to be queried in a single /api/v1/cardinality/label_values API call.")
+	f.IntVar(&l.ActiveSeriesResultsMaxSizeBytes, "querier.active-series-results-max-size-bytes", 100*1024*1024, "Maximum size in bytes of the distinct active series returned by a single /api/v1/cardinality/active_series API call. When querier receives response from ingester, it merges the response with responses from other ingesters. This maximum size limit is applied to the merged(distinct) results, which are held in memory by the querier. If the limit is reached, an error is returned.")
 	_ = l.MaxCacheFreshness.Set("1m")
 	f.Var(&l.MaxCacheFreshness, "query-frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
 
@@ -241,6 +307,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.SplitInstantQueriesByInterval, "query-frontend.split-instant-queries-by-interval", "Split instant queries by an interval and execute in parallel. 0 to disable it.")
 	_ = l.QueryIngestersWithin.Set("13h")
 	f.Var(&l.QueryIngestersWithin, QueryIngestersWithinFlag, "Maximum lookback beyond which queries are not sent to ingester. 0 means all queries are sent to ingester.")
+	f.BoolVar(&l.StoreGatewayPartialResponseEnabled, "querier.store-gateway-partial-response-enabled", false, "If true, when some blocks can't be fetched from the store-gateways after all retries, queries return the data fetched from the other blocks, plus a warning listing the time ranges of the missing blocks, instead of failing. This applies to the queries executed by the querier and ruler. The query-frontend doesn't cache such partial responses.")
 
 	_ = l.RulerEvaluationDelay.Set("1m")
 	f.Var(&l.RulerEvaluationDelay, "ruler.evaluation-delay-duration", "Duration to delay the evaluation of rules to ensure the underlying metrics have been pushed.")
@@ -355,6 +422,46 @@ func (l *Limits) validate() error {
 		}
 	}
 
+	for _, rule := range l.GraphiteMappingRules {
+		if rule == nil {
+			return errors.New("invalid graphite_mapping_rules")
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid graphite_mapping_rules: %w", err)
+		}
+	}
+
+	seenSelectors := map[string]struct{}{}
+	for _, limit := range l.LabelSetLimits {
+		if limit == nil {
+			return errors.New("invalid label_set_limits")
+		}
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("invalid label_set_limits: %w", err)
+		}
+		if _, ok := seenSelectors[limit.Selector]; ok {
+			return fmt.Errorf("invalid label_set_limits: duplicate selector %q", limit.Selector)
+		}
+		seenSelectors[limit.Selector] = struct{}{}
+	}
+
+	// An empty enforcement mode, when the defaults haven't been registered, enforces the limits.
+	if l.LimitsEnforcementMode != "" && l.LimitsEnforcementMode != EnforcementModeEnforce && l.LimitsEnforcementMode != EnforcementModeDryRun {
+		return fmt.Errorf("invalid value for -%s: %q, supported values: %s, %s", limitsEnforcementModeFlag, l.LimitsEnforcementMode, EnforcementModeEnforce, EnforcementModeDryRun)
+	}
+
+	if l.StaleSeriesEvictionMinPercentage < 0 || l.StaleSeriesEvictionMinPercentage > 100 {
+		return fmt.Errorf("invalid value for -%s: must be a value between 0 and 100 (included)", staleSeriesEvictionMinPercentageFlag)
+	}
+
+	if l.StaleSeriesEvictionIdleTimeout > 0 && l.StaleSeriesEvictionMinInterval < 2*l.StaleSeriesEvictionIdleTimeout {
+		return fmt.Errorf("invalid value for -%s: must be at least twice -%s", staleSeriesEvictionMinIntervalFlag, StaleSeriesEvictionIdleTimeoutFlag)
+	}
+
+	if l.CostAttributionLabel != "" && !model.LabelName(l.CostAttributionLabel).IsValid() {
+		return fmt.Errorf("invalid value for -%s: %q is not a valid label name", costAttributionLabelFlag, l.CostAttributionLabel)
+	}
+
 	if l.MaxEstimatedChunksPerQueryMultiplier < 1 && l.MaxEstimatedChunksPerQueryMultiplier != 0 {
 		return errors.New("invalid value for -" + MaxEstimatedChunksPerQueryMultiplierFlag + ": must be 0 or greater than or equal to 1")
 	}
@@ -435,6 +542,11 @@ func (o *Overrides) LabelValuesMaxCardinalityLabelNamesPerRequest(userID string)
 	return o.getOverridesForUser(userID).LabelValuesMaxCardinalityLabelNamesPerRequest
 }
 
+// ActiveSeriesResultsMaxSizeBytes returns the maximum size in bytes of distinct active series returned by a cardinality request.
+func (o *Overrides) ActiveSeriesResultsMaxSizeBytes(userID string) int {
+	return o.getOverridesForUser(userID).ActiveSeriesResultsMaxSizeBytes
+}
+
 // IngestionBurstSize returns the burst size for ingestion rate.
 func (o *Overrides) IngestionBurstSize(userID string) int {
 	return o.getOverridesForUser(userID).IngestionBurstSize
@@ -504,6 +616,23 @@ func (o *Overrides) MaxGlobalSeriesPerUser(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalSeriesPerUser
 }
 
+// LabelSetLimits returns the limits applied to the series matching a selector for a given user.
+func (o *Overrides) LabelSetLimits(userID string) []*LabelSetLimit {
+	return o.getOverridesForUser(userID).LabelSetLimits
+}
+
+// CostAttributionLabel returns the label used to attribute the received samples and the active series of a given
+// user, or an empty string if the cost attribution is disabled.
+func (o *Overrides) CostAttributionLabel(userID string) string {
+	return o.getOverridesForUser(userID).CostAttributionLabel
+}
+
+// MaxCostAttributionCardinality returns the maximum number of values of the cost attribution label tracked at the
+// same time for a given user.
+func (o *Overrides) MaxCostAttributionCardinality(userID string) int {
+	return o.getOverridesForUser(userID).MaxCostAttributionCardinality
+}
+
 // MaxGlobalSeriesPerMetric returns the maximum number of series allowed per metric across the cluster.
 func (o *Overrides) MaxGlobalSeriesPerMetric(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalSeriesPerMetric
@@ -530,6 +659,12 @@ func (o *Overrides) MaxFetchedChunkBytesPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxFetchedChunkBytesPerQuery
 }
 
+// MaxEstimatedMemoryPerQuery returns the maximum estimated memory, in bytes, a single query can use while
+// being evaluated by the streaming PromQL engine.
+func (o *Overrides) MaxEstimatedMemoryPerQuery(userID string) uint64 {
+	return o.getOverridesForUser(userID).MaxEstimatedMemoryPerQuery
+}
+
 // MaxQueryLookback returns the max lookback period of queries.
 func (o *Overrides) MaxQueryLookback(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxQueryLookback)
@@ -608,6 +743,12 @@ func (o *Overrides) QueryIngestersWithin(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).QueryIngestersWithin)
 }
 
+// StoreGatewayPartialResponseEnabled returns whether queries return partial results, rather than failing,
+// when some blocks can't be fetched from the store-gateways.
+func (o *Overrides) StoreGatewayPartialResponseEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).StoreGatewayPartialResponseEnabled
+}
+
 // EnforceMetadataMetricName whether to enforce the presence of a metric name on metadata.
 func (o *Overrides) EnforceMetadataMetricName(userID string) bool {
 	return o.getOverridesForUser(userID).EnforceMetadataMetricName
@@ -642,6 +783,31 @@ func (o *Overrides) OutOfOrderBlocksExternalLabelEnabled(userID string) bool {
 	return o.getOverridesForUser(userID).OutOfOrderBlocksExternalLabelEnabled
 }
 
+// MinSampleInterval returns the minimum interval between two samples of the same series for the user.
+func (o *Overrides) MinSampleInterval(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).MinSampleInterval)
+}
+
+// MaxHeadMemoryBytesPerTenant returns the maximum estimated memory, in bytes, of the user's TSDB head in each ingester.
+func (o *Overrides) MaxHeadMemoryBytesPerTenant(userID string) int64 {
+	return o.getOverridesForUser(userID).MaxHeadMemoryBytesPerTenant
+}
+
+// StaleSeriesEvictionIdleTimeout returns the duration after which the inactive series are evicted from the TSDB head for the user.
+func (o *Overrides) StaleSeriesEvictionIdleTimeout(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).StaleSeriesEvictionIdleTimeout)
+}
+
+// StaleSeriesEvictionMinPercentage returns the minimum percentage of stale series in the TSDB head required to trigger their eviction for the user.
+func (o *Overrides) StaleSeriesEvictionMinPercentage(userID string) int {
+	return o.getOverridesForUser(userID).StaleSeriesEvictionMinPercentage
+}
+
+// StaleSeriesEvictionMinInterval returns the minimum interval between two evictions of the stale series from the TSDB head for the user.
+func (o *Overrides) StaleSeriesEvictionMinInterval(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).StaleSeriesEvictionMinInterval)
+}
+
 // SeparateMetricsGroupLabel returns the custom label used to separate specific metrics
 func (o *Overrides) SeparateMetricsGroupLabel(userID string) string {
 	return o.getOverridesForUser(userID).SeparateMetricsGroupLabel
@@ -716,11 +882,51 @@ func (o *Overrides) MetricRelabelConfigs(userID string) []*relabel.Config {
 	return o.getOverridesForUser(userID).MetricRelabelConfigs
 }
 
+// GraphiteMappingRules returns the rules used to map Graphite metric names for a given user.
+func (o *Overrides) GraphiteMappingRules(userID string) []*GraphiteMappingRule {
+	return o.getOverridesForUser(userID).GraphiteMappingRules
+}
+
+// OTelPromoteResourceAttributes returns the OTel resource attributes to promote to series labels for a given user.
+func (o *Overrides) OTelPromoteResourceAttributes(userID string) []string {
+	return o.getOverridesForUser(userID).OTelPromoteResourceAttributes
+}
+
+// OTelDisableTargetInfo returns whether the target_info metric is not generated when ingesting via OTLP for a given user.
+func (o *Overrides) OTelDisableTargetInfo(userID string) bool {
+	return o.getOverridesForUser(userID).OTelDisableTargetInfo
+}
+
+// LimitsDryRun returns whether the ingestion limits only track the data they would reject for a given user.
+func (o *Overrides) LimitsDryRun(userID string) bool {
+	return o.getOverridesForUser(userID).LimitsEnforcementMode == EnforcementModeDryRun
+}
+
+// OTelConvertDeltaToCumulative returns whether OTLP delta metrics are converted to cumulative for a given user.
+func (o *Overrides) OTelConvertDeltaToCumulative(userID string) bool {
+	return o.getOverridesForUser(userID).OTelConvertDeltaToCumulative
+}
+
+// OTelDeltaToCumulativeMaxSeries returns the maximum number of OTLP delta series tracked by a distributor for a given user.
+func (o *Overrides) OTelDeltaToCumulativeMaxSeries(userID string) int {
+	return o.getOverridesForUser(userID).OTelDeltaToCumulativeMaxSeries
+}
+
+// OTelDeltaToCumulativeIdleTimeout returns how long the running totals of an idle OTLP delta series are kept for a given user.
+func (o *Overrides) OTelDeltaToCumulativeIdleTimeout(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).OTelDeltaToCumulativeIdleTimeout)
+}
+
 // NativeHistogramsIngestionEnabled returns whether to ingest native histograms in the ingester
 func (o *Overrides) NativeHistogramsIngestionEnabled(userID string) bool {
 	return o.getOverridesForUser(userID).NativeHistogramsIngestionEnabled
 }
 
+// OOONativeHistogramsIngestionEnabled returns whether to ingest out-of-order native histograms in the ingester
+func (o *Overrides) OOONativeHistogramsIngestionEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).OOONativeHistogramsIngestionEnabled
+}
+
 // RulerTenantShardSize returns shard size (number of rulers) used by this tenant when using shuffle-sharding strategy.
 func (o *Overrides) RulerTenantShardSize(userID string) int {
 	return o.getOverridesForUser(userID).RulerTenantShardSize
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -880,3 +881,173 @@ func TestExtensionMarshalling(t *testing.T) {
 		require.Contains(t, string(val), `{"user":{"test_extension_struct":{"foo":42},"test_extension_string":"default string extension value","request_rate":0,"request_burst_size":0,`)
 	})
 }
+
+func TestGraphiteMappingRulesLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid rules", func(t *testing.T) {
+		inp := `
+graphite_mapping_rules:
+- match: servers.*.cpu
+  name: cpu_usage
+  labels:
+    server: $1
+- match: ^app\.(\w+)\.requests$
+  match_type: regex
+  name: ${1}_requests_total
+`
+		l := Limits{}
+		require.NoError(t, yaml.Unmarshal([]byte(inp), &l))
+		require.Len(t, l.GraphiteMappingRules, 2)
+
+		regex, err := l.GraphiteMappingRules[0].Regexp()
+		require.NoError(t, err)
+		assert.Equal(t, `^servers\.([^.]*)\.cpu$`, regex.String())
+		assert.Equal(t, map[string]string{"server": "$1"}, l.GraphiteMappingRules[0].Labels)
+
+		regex, err = l.GraphiteMappingRules[1].Regexp()
+		require.NoError(t, err)
+		assert.Equal(t, `^app\.(\w+)\.requests$`, regex.String())
+	})
+
+	for name, tc := range map[string]struct {
+		inp         string
+		expectedErr string
+	}{
+		"missing name": {
+			inp: `
+graphite_mapping_rules:
+- match: servers.*.cpu
+`,
+			expectedErr: `invalid graphite_mapping_rules: name is required for match "servers.*.cpu"`,
+		},
+		"invalid regex": {
+			inp: `
+graphite_mapping_rules:
+- match: app.(
+  match_type: regex
+  name: app
+`,
+			expectedErr: `invalid graphite_mapping_rules: invalid match "app.("`,
+		},
+		"unsupported match type": {
+			inp: `
+graphite_mapping_rules:
+- match: app.*
+  match_type: exact
+  name: app
+`,
+			expectedErr: `invalid graphite_mapping_rules: unsupported match_type "exact" for match "app.*"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := Limits{}
+			require.ErrorContains(t, yaml.Unmarshal([]byte(tc.inp), &l), tc.expectedErr)
+		})
+	}
+}
+
+func TestLabelSetLimitsLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid limits", func(t *testing.T) {
+		inp := `
+label_set_limits:
+- selector: '{namespace="payments"}'
+  max_series: 1000
+- selector: '{namespace=~"dev-.*", env!="prod"}'
+  ingestion_rate: 100
+  ingestion_burst_size: 200
+`
+		l := Limits{}
+		require.NoError(t, yaml.Unmarshal([]byte(inp), &l))
+		require.Len(t, l.LabelSetLimits, 2)
+		assert.Equal(t, 1000, l.LabelSetLimits[0].MaxSeries)
+		assert.Equal(t, 100.0, l.LabelSetLimits[1].IngestionRate)
+
+		matchers, err := l.LabelSetLimits[1].Matchers()
+		require.NoError(t, err)
+		assert.Len(t, matchers, 2)
+
+		assert.True(t, l.LabelSetLimits[0].Matches(labels.FromStrings("__name__", "up", "namespace", "payments")))
+		assert.False(t, l.LabelSetLimits[0].Matches(labels.FromStrings("__name__", "up", "namespace", "billing")))
+		assert.True(t, l.LabelSetLimits[1].Matches(labels.FromStrings("__name__", "up", "namespace", "dev-1")))
+		assert.False(t, l.LabelSetLimits[1].Matches(labels.FromStrings("__name__", "up", "namespace", "dev-1", "env", "prod")))
+	})
+
+	for name, tc := range map[string]struct {
+		inp         string
+		expectedErr string
+	}{
+		"missing selector": {
+			inp: `
+label_set_limits:
+- max_series: 10
+`,
+			expectedErr: `invalid label_set_limits: selector is required`,
+		},
+		"invalid selector": {
+			inp: `
+label_set_limits:
+- selector: '{namespace='
+`,
+			expectedErr: `invalid label_set_limits: invalid selector "{namespace="`,
+		},
+		"missing burst size": {
+			inp: `
+label_set_limits:
+- selector: '{namespace="payments"}'
+  ingestion_rate: 10
+`,
+			expectedErr: `invalid label_set_limits: ingestion_burst_size must be greater than 0 when ingestion_rate is set for selector "{namespace=\"payments\"}"`,
+		},
+		"duplicate selector": {
+			inp: `
+label_set_limits:
+- selector: '{namespace="payments"}'
+  max_series: 10
+- selector: '{namespace="payments"}'
+  max_series: 20
+`,
+			expectedErr: `invalid label_set_limits: duplicate selector "{namespace=\"payments\"}"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := Limits{}
+			require.ErrorContains(t, yaml.Unmarshal([]byte(tc.inp), &l), tc.expectedErr)
+		})
+	}
+}
+
+func TestCostAttributionLabelValidation(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	l := Limits{}
+	require.NoError(t, yaml.Unmarshal([]byte(`cost_attribution_label: team`), &l))
+	assert.Equal(t, "team", l.CostAttributionLabel)
+
+	l = Limits{}
+	require.ErrorContains(t, yaml.Unmarshal([]byte(`cost_attribution_label: "team-name"`), &l), `invalid value for -validation.cost-attribution-label: "team-name" is not a valid label name`)
+}
+
+func TestStaleSeriesEvictionMinPercentageValidation(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	l := Limits{}
+	require.NoError(t, yaml.Unmarshal([]byte(`stale_series_eviction_min_percentage: 100`), &l))
+	assert.Equal(t, 100, l.StaleSeriesEvictionMinPercentage)
+
+	l = Limits{}
+	require.ErrorContains(t, yaml.Unmarshal([]byte(`stale_series_eviction_min_percentage: 101`), &l), `invalid value for -ingester.stale-series-eviction-min-percentage: must be a value between 0 and 100 (included)`)
+}
+
+func TestStaleSeriesEvictionMinIntervalValidation(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	l := Limits{}
+	require.NoError(t, yaml.Unmarshal([]byte("stale_series_eviction_idle_timeout: 15m\nstale_series_eviction_min_interval: 30m"), &l))
+	assert.Equal(t, model.Duration(30*time.Minute), l.StaleSeriesEvictionMinInterval)
+
+	l = Limits{}
+	require.ErrorContains(t, yaml.Unmarshal([]byte("stale_series_eviction_idle_timeout: 15m\nstale_series_eviction_min_interval: 20m"), &l), `invalid value for -ingester.stale-series-eviction-min-interval: must be at least twice -ingester.stale-series-eviction-idle-timeout`)
+}
@@ -348,6 +348,10 @@ func getFieldCustomType(t reflect.Type) (string, bool) {
 		return "relabel_config...", true
 	case reflect.TypeOf([]*validation.BlockedQuery{}).String():
 		return "blocked_queries_config...", true
+	case reflect.TypeOf([]*validation.GraphiteMappingRule{}).String():
+		return "graphite_mapping_rules_config...", true
+	case reflect.TypeOf([]*validation.LabelSetLimit{}).String():
+		return "label_set_limits_config...", true
 	case reflect.TypeOf(activeseries.CustomTrackersConfig{}).String():
 		return "map of tracker name (string) to matcher (string)", true
 	default:
@@ -430,6 +434,10 @@ func getCustomFieldType(t reflect.Type) (string, bool) {
 		return "relabel_config...", true
 	case reflect.TypeOf([]*validation.BlockedQuery{}).String():
 		return "blocked_queries_config...", true
+	case reflect.TypeOf([]*validation.GraphiteMappingRule{}).String():
+		return "graphite_mapping_rules_config...", true
+	case reflect.TypeOf([]*validation.LabelSetLimit{}).String():
+		return "label_set_limits_config...", true
 	case reflect.TypeOf(activeseries.CustomTrackersConfig{}).String():
 		return "map of tracker name (string) to matcher (string)", true
 	default:
@@ -463,6 +471,10 @@ func ReflectType(typ string) reflect.Type {
 		return reflect.TypeOf([]*relabel.Config{})
 	case "blocked_queries_config...":
 		return reflect.TypeOf([]*validation.BlockedQuery{})
+	case "graphite_mapping_rules_config...":
+		return reflect.TypeOf([]*validation.GraphiteMappingRule{})
+	case "label_set_limits_config...":
+		return reflect.TypeOf([]*validation.LabelSetLimit{})
 	case "map of string to float64":
 		return reflect.TypeOf(map[string]float64{})
 	case "list of durations":